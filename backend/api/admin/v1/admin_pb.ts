@@ -0,0 +1,568 @@
+// @generated by protoc-gen-es v2.9.0 with parameter "target=ts"
+// @generated from file api/admin/v1/admin.proto (package admin.v1, syntax proto3)
+/* eslint-disable */
+
+import type { GenFile, GenMessage, GenService } from "@bufbuild/protobuf/codegenv2";
+import { fileDesc, messageDesc, serviceDesc } from "@bufbuild/protobuf/codegenv2";
+import type { Message } from "@bufbuild/protobuf";
+
+/**
+ * Describes the file api/admin/v1/admin.proto.
+ */
+export const file_api_admin_v1_admin: GenFile = /*@__PURE__*/
+  fileDesc("ChhhcGkvYWRtaW4vdjEvYWRtaW4ucHJvdG8SCGFkbWluLnYxIosBCgZJbnZpdGUSCgoCaWQYASABKAMSEgoKaW52aXRlcl9pZBgCIAEoAxIQCghtYXhfdXNlcxgDIAEoBRISCgp1c2VkX2NvdW50GAQgASgFEhMKC2ludml0ZWVfaWRzGAUgAygDEhIKCmV4cGlyZXNfYXQYBiABKAMSEgoKY3JlYXRlZF9hdBgHIAEoAyI8ChNDcmVhdGVJbnZpdGVSZXF1ZXN0EhAKCG1heF91c2VzGAEgASgFEhMKC3R0bF9zZWNvbmRzGAIgASgDIkYKFENyZWF0ZUludml0ZVJlc3BvbnNlEgwKBGNvZGUYASABKAkSIAoGaW52aXRlGAIgASgLMhAuYWRtaW4udjEuSW52aXRlIhQKEkxpc3RJbnZpdGVzUmVxdWVzdCI4ChNMaXN0SW52aXRlc1Jlc3BvbnNlEiEKB2ludml0ZXMYASADKAsyEC5hZG1pbi52MS5JbnZpdGUiUgoTV2ViaG9va1N1YnNjcmlwdGlvbhIKCgJpZBgBIAEoAxILCgN1cmwYAiABKAkSDgoGZXZlbnRzGAMgAygJEhIKCmNyZWF0ZWRfYXQYBCABKAMihwIKD1dlYmhvb2tEZWxpdmVyeRIKCgJpZBgBIAEoAxIXCg9zdWJzY3JpcHRpb25faWQYAiABKAMSEAoIZXZlbnRfaWQYAyABKAMSEgoKZXZlbnRfdHlwZRgEIAEoCRIOCgZzdGF0dXMYBSABKAkSEAoIYXR0ZW1wdHMYBiABKAUSFwoPbmV4dF9hdHRlbXB0X2F0GAcgASgDEhcKD2xhc3RfYXR0ZW1wdF9hdBgIIAEoAxIXCg9yZXNwb25zZV9zdGF0dXMYCSABKAUSEgoKbGFzdF9lcnJvchgKIAEoCRIUCgxkZWxpdmVyZWRfYXQYCyABKAMSEgoKY3JlYXRlZF9hdBgMIAEoAyJPCiBDcmVhdGVXZWJob29rU3Vic2NyaXB0aW9uUmVxdWVzdBILCgN1cmwYASABKAkSDgoGZXZlbnRzGAIgAygJEg4KBnNlY3JldBgDIAEoCSJoCiFDcmVhdGVXZWJob29rU3Vic2NyaXB0aW9uUmVzcG9uc2USMwoMc3Vic2NyaXB0aW9uGAEgASgLMh0uYWRtaW4udjEuV2ViaG9va1N1YnNjcmlwdGlvbhIOCgZzZWNyZXQYAiABKAkiIQofTGlzdFdlYmhvb2tTdWJzY3JpcHRpb25zUmVxdWVzdCJYCiBMaXN0V2ViaG9va1N1YnNjcmlwdGlvbnNSZXNwb25zZRI0Cg1zdWJzY3JpcHRpb25zGAEgAygLMh0uYWRtaW4udjEuV2ViaG9va1N1YnNjcmlwdGlvbiIuCiBEZWxldGVXZWJob29rU3Vic2NyaXB0aW9uUmVxdWVzdBIKCgJpZBgBIAEoAyIjCiFEZWxldGVXZWJob29rU3Vic2NyaXB0aW9uUmVzcG9uc2UiRgocTGlzdFdlYmhvb2tEZWxpdmVyaWVzUmVxdWVzdBIXCg9zdWJzY3JpcHRpb25faWQYASABKAMSDQoFbGltaXQYAiABKAUiTgodTGlzdFdlYmhvb2tEZWxpdmVyaWVzUmVzcG9uc2USLQoKZGVsaXZlcmllcxgBIAMoCzIZLmFkbWluLnYxLldlYmhvb2tEZWxpdmVyeSIuChdSZWRlbGl2ZXJXZWJob29rUmVxdWVzdBITCgtkZWxpdmVyeV9pZBgBIAEoAyJHChhSZWRlbGl2ZXJXZWJob29rUmVzcG9uc2USKwoIZGVsaXZlcnkYASABKAsyGS5hZG1pbi52MS5XZWJob29rRGVsaXZlcnkiRAofU2V0VXNlckNyZWRlbnRpYWxCYWNrZW5kUmVxdWVzdBIQCgh1c2VybmFtZRgBIAEoCRIPCgdiYWNrZW5kGAIgASgJIiIKIFNldFVzZXJDcmVkZW50aWFsQmFja2VuZFJlc3BvbnNlMtAGCgxBZG1pblNlcnZpY2USTwoMQ3JlYXRlSW52aXRlEh0uYWRtaW4udjEuQ3JlYXRlSW52aXRlUmVxdWVzdBoeLmFkbWluLnYxLkNyZWF0ZUludml0ZVJlc3BvbnNlIgASTAoLTGlzdEludml0ZXMSHC5hZG1pbi52MS5MaXN0SW52aXRlc1JlcXVlc3QaHS5hZG1pbi52MS5MaXN0SW52aXRlc1Jlc3BvbnNlIgASdgoZQ3JlYXRlV2ViaG9va1N1YnNjcmlwdGlvbhIqLmFkbWluLnYxLkNyZWF0ZVdlYmhvb2tTdWJzY3JpcHRpb25SZXF1ZXN0GisuYWRtaW4udjEuQ3JlYXRlV2ViaG9va1N1YnNjcmlwdGlvblJlc3BvbnNlIgAScwoYTGlzdFdlYmhvb2tTdWJzY3JpcHRpb25zEikuYWRtaW4udjEuTGlzdFdlYmhvb2tTdWJzY3JpcHRpb25zUmVxdWVzdBoqLmFkbWluLnYxLkxpc3RXZWJob29rU3Vic2NyaXB0aW9uc1Jlc3BvbnNlIgASdgoZRGVsZXRlV2ViaG9va1N1YnNjcmlwdGlvbhIqLmFkbWluLnYxLkRlbGV0ZVdlYmhvb2tTdWJzY3JpcHRpb25SZXF1ZXN0GisuYWRtaW4udjEuRGVsZXRlV2ViaG9va1N1YnNjcmlwdGlvblJlc3BvbnNlIgASagoVTGlzdFdlYmhvb2tEZWxpdmVyaWVzEiYuYWRtaW4udjEuTGlzdFdlYmhvb2tEZWxpdmVyaWVzUmVxdWVzdBonLmFkbWluLnYxLkxpc3RXZWJob29rRGVsaXZlcmllc1Jlc3BvbnNlIgASWwoQUmVkZWxpdmVyV2ViaG9vaxIhLmFkbWluLnYxLlJlZGVsaXZlcldlYmhvb2tSZXF1ZXN0GiIuYWRtaW4udjEuUmVkZWxpdmVyV2ViaG9va1Jlc3BvbnNlIgAScwoYU2V0VXNlckNyZWRlbnRpYWxCYWNrZW5kEikuYWRtaW4udjEuU2V0VXNlckNyZWRlbnRpYWxCYWNrZW5kUmVxdWVzdBoqLmFkbWluLnYxLlNldFVzZXJDcmVkZW50aWFsQmFja2VuZFJlc3BvbnNlIgBChAEKDGNvbS5hZG1pbi52MUIKQWRtaW5Qcm90b1ABWidjb25uZWN0LWdvLWV4YW1wbGUvYXBpL2FkbWluL3YxO2FkbWludjGiAgNBWFiqAghBZG1pbi5WMcoCCEFkbWluXFYx4gIUQWRtaW5cVjFcR1BCTWV0YWRhdGHqAglBZG1pbjo6VjFiBnByb3RvMw");
+
+/**
+ * 邀请码，记录邀请人、使用次数和受邀用户
+ *
+ * @generated from message admin.v1.Invite
+ */
+export type Invite = Message<"admin.v1.Invite"> & {
+  /**
+   * @generated from field: int64 id = 1;
+   */
+  id: bigint;
+
+  /**
+   * @generated from field: int64 inviter_id = 2;
+   */
+  inviterId: bigint;
+
+  /**
+   * @generated from field: int32 max_uses = 3;
+   */
+  maxUses: number;
+
+  /**
+   * @generated from field: int32 used_count = 4;
+   */
+  usedCount: number;
+
+  /**
+   * 通过该邀请码注册的用户
+   *
+   * @generated from field: repeated int64 invitee_ids = 5;
+   */
+  inviteeIds: bigint[];
+
+  /**
+   * @generated from field: int64 expires_at = 6;
+   */
+  expiresAt: bigint;
+
+  /**
+   * @generated from field: int64 created_at = 7;
+   */
+  createdAt: bigint;
+};
+
+/**
+ * Describes the message admin.v1.Invite.
+ * Use `create(InviteSchema)` to create a new message.
+ */
+export const InviteSchema: GenMessage<Invite> = /*@__PURE__*/
+  messageDesc(file_api_admin_v1_admin, 0);
+
+/**
+ * @generated from message admin.v1.CreateInviteRequest
+ */
+export type CreateInviteRequest = Message<"admin.v1.CreateInviteRequest"> & {
+  /**
+   * 可选，默认使用配置中的次数
+   *
+   * @generated from field: int32 max_uses = 1;
+   */
+  maxUses: number;
+
+  /**
+   * 可选，默认使用配置中的有效期
+   *
+   * @generated from field: int64 ttl_seconds = 2;
+   */
+  ttlSeconds: bigint;
+};
+
+/**
+ * Describes the message admin.v1.CreateInviteRequest.
+ * Use `create(CreateInviteRequestSchema)` to create a new message.
+ */
+export const CreateInviteRequestSchema: GenMessage<CreateInviteRequest> = /*@__PURE__*/
+  messageDesc(file_api_admin_v1_admin, 1);
+
+/**
+ * @generated from message admin.v1.CreateInviteResponse
+ */
+export type CreateInviteResponse = Message<"admin.v1.CreateInviteResponse"> & {
+  /**
+   * 交给受邀人，注册时填写
+   *
+   * @generated from field: string code = 1;
+   */
+  code: string;
+
+  /**
+   * @generated from field: admin.v1.Invite invite = 2;
+   */
+  invite?: Invite;
+};
+
+/**
+ * Describes the message admin.v1.CreateInviteResponse.
+ * Use `create(CreateInviteResponseSchema)` to create a new message.
+ */
+export const CreateInviteResponseSchema: GenMessage<CreateInviteResponse> = /*@__PURE__*/
+  messageDesc(file_api_admin_v1_admin, 2);
+
+/**
+ * @generated from message admin.v1.ListInvitesRequest
+ */
+export type ListInvitesRequest = Message<"admin.v1.ListInvitesRequest"> & {
+};
+
+/**
+ * Describes the message admin.v1.ListInvitesRequest.
+ * Use `create(ListInvitesRequestSchema)` to create a new message.
+ */
+export const ListInvitesRequestSchema: GenMessage<ListInvitesRequest> = /*@__PURE__*/
+  messageDesc(file_api_admin_v1_admin, 3);
+
+/**
+ * @generated from message admin.v1.ListInvitesResponse
+ */
+export type ListInvitesResponse = Message<"admin.v1.ListInvitesResponse"> & {
+  /**
+   * @generated from field: repeated admin.v1.Invite invites = 1;
+   */
+  invites: Invite[];
+};
+
+/**
+ * Describes the message admin.v1.ListInvitesResponse.
+ * Use `create(ListInvitesResponseSchema)` to create a new message.
+ */
+export const ListInvitesResponseSchema: GenMessage<ListInvitesResponse> = /*@__PURE__*/
+  messageDesc(file_api_admin_v1_admin, 4);
+
+/**
+ * Webhook 订阅，投递请求带有 Webhook-Timestamp 和 Webhook-Signature 请求头，
+ * 签名为 sha256=hex(HMAC-SHA256(secret, "<timestamp>.<body>"))
+ *
+ * @generated from message admin.v1.WebhookSubscription
+ */
+export type WebhookSubscription = Message<"admin.v1.WebhookSubscription"> & {
+  /**
+   * @generated from field: int64 id = 1;
+   */
+  id: bigint;
+
+  /**
+   * @generated from field: string url = 2;
+   */
+  url: string;
+
+  /**
+   * UserRegistered、UserLoggedIn、PasswordChanged、UserDisabled
+   *
+   * @generated from field: repeated string events = 3;
+   */
+  events: string[];
+
+  /**
+   * @generated from field: int64 created_at = 4;
+   */
+  createdAt: bigint;
+};
+
+/**
+ * Describes the message admin.v1.WebhookSubscription.
+ * Use `create(WebhookSubscriptionSchema)` to create a new message.
+ */
+export const WebhookSubscriptionSchema: GenMessage<WebhookSubscription> = /*@__PURE__*/
+  messageDesc(file_api_admin_v1_admin, 5);
+
+/**
+ * 一个事件到一个订阅的投递记录
+ *
+ * @generated from message admin.v1.WebhookDelivery
+ */
+export type WebhookDelivery = Message<"admin.v1.WebhookDelivery"> & {
+  /**
+   * @generated from field: int64 id = 1;
+   */
+  id: bigint;
+
+  /**
+   * @generated from field: int64 subscription_id = 2;
+   */
+  subscriptionId: bigint;
+
+  /**
+   * @generated from field: int64 event_id = 3;
+   */
+  eventId: bigint;
+
+  /**
+   * @generated from field: string event_type = 4;
+   */
+  eventType: string;
+
+  /**
+   * pending、succeeded 或 dead（重试次数用完）
+   *
+   * @generated from field: string status = 5;
+   */
+  status: string;
+
+  /**
+   * @generated from field: int32 attempts = 6;
+   */
+  attempts: number;
+
+  /**
+   * @generated from field: int64 next_attempt_at = 7;
+   */
+  nextAttemptAt: bigint;
+
+  /**
+   * 未尝试时为 0
+   *
+   * @generated from field: int64 last_attempt_at = 8;
+   */
+  lastAttemptAt: bigint;
+
+  /**
+   * 最近一次尝试的 HTTP 状态码，没有响应时为 0
+   *
+   * @generated from field: int32 response_status = 9;
+   */
+  responseStatus: number;
+
+  /**
+   * @generated from field: string last_error = 10;
+   */
+  lastError: string;
+
+  /**
+   * 未成功时为 0
+   *
+   * @generated from field: int64 delivered_at = 11;
+   */
+  deliveredAt: bigint;
+
+  /**
+   * @generated from field: int64 created_at = 12;
+   */
+  createdAt: bigint;
+};
+
+/**
+ * Describes the message admin.v1.WebhookDelivery.
+ * Use `create(WebhookDeliverySchema)` to create a new message.
+ */
+export const WebhookDeliverySchema: GenMessage<WebhookDelivery> = /*@__PURE__*/
+  messageDesc(file_api_admin_v1_admin, 6);
+
+/**
+ * @generated from message admin.v1.CreateWebhookSubscriptionRequest
+ */
+export type CreateWebhookSubscriptionRequest = Message<"admin.v1.CreateWebhookSubscriptionRequest"> & {
+  /**
+   * @generated from field: string url = 1;
+   */
+  url: string;
+
+  /**
+   * @generated from field: repeated string events = 2;
+   */
+  events: string[];
+
+  /**
+   * 可选，至少32个字符，为空时由服务端生成
+   *
+   * @generated from field: string secret = 3;
+   */
+  secret: string;
+};
+
+/**
+ * Describes the message admin.v1.CreateWebhookSubscriptionRequest.
+ * Use `create(CreateWebhookSubscriptionRequestSchema)` to create a new message.
+ */
+export const CreateWebhookSubscriptionRequestSchema: GenMessage<CreateWebhookSubscriptionRequest> = /*@__PURE__*/
+  messageDesc(file_api_admin_v1_admin, 7);
+
+/**
+ * @generated from message admin.v1.CreateWebhookSubscriptionResponse
+ */
+export type CreateWebhookSubscriptionResponse = Message<"admin.v1.CreateWebhookSubscriptionResponse"> & {
+  /**
+   * @generated from field: admin.v1.WebhookSubscription subscription = 1;
+   */
+  subscription?: WebhookSubscription;
+
+  /**
+   * 只在创建时返回
+   *
+   * @generated from field: string secret = 2;
+   */
+  secret: string;
+};
+
+/**
+ * Describes the message admin.v1.CreateWebhookSubscriptionResponse.
+ * Use `create(CreateWebhookSubscriptionResponseSchema)` to create a new message.
+ */
+export const CreateWebhookSubscriptionResponseSchema: GenMessage<CreateWebhookSubscriptionResponse> = /*@__PURE__*/
+  messageDesc(file_api_admin_v1_admin, 8);
+
+/**
+ * @generated from message admin.v1.ListWebhookSubscriptionsRequest
+ */
+export type ListWebhookSubscriptionsRequest = Message<"admin.v1.ListWebhookSubscriptionsRequest"> & {
+};
+
+/**
+ * Describes the message admin.v1.ListWebhookSubscriptionsRequest.
+ * Use `create(ListWebhookSubscriptionsRequestSchema)` to create a new message.
+ */
+export const ListWebhookSubscriptionsRequestSchema: GenMessage<ListWebhookSubscriptionsRequest> = /*@__PURE__*/
+  messageDesc(file_api_admin_v1_admin, 9);
+
+/**
+ * @generated from message admin.v1.ListWebhookSubscriptionsResponse
+ */
+export type ListWebhookSubscriptionsResponse = Message<"admin.v1.ListWebhookSubscriptionsResponse"> & {
+  /**
+   * @generated from field: repeated admin.v1.WebhookSubscription subscriptions = 1;
+   */
+  subscriptions: WebhookSubscription[];
+};
+
+/**
+ * Describes the message admin.v1.ListWebhookSubscriptionsResponse.
+ * Use `create(ListWebhookSubscriptionsResponseSchema)` to create a new message.
+ */
+export const ListWebhookSubscriptionsResponseSchema: GenMessage<ListWebhookSubscriptionsResponse> = /*@__PURE__*/
+  messageDesc(file_api_admin_v1_admin, 10);
+
+/**
+ * @generated from message admin.v1.DeleteWebhookSubscriptionRequest
+ */
+export type DeleteWebhookSubscriptionRequest = Message<"admin.v1.DeleteWebhookSubscriptionRequest"> & {
+  /**
+   * @generated from field: int64 id = 1;
+   */
+  id: bigint;
+};
+
+/**
+ * Describes the message admin.v1.DeleteWebhookSubscriptionRequest.
+ * Use `create(DeleteWebhookSubscriptionRequestSchema)` to create a new message.
+ */
+export const DeleteWebhookSubscriptionRequestSchema: GenMessage<DeleteWebhookSubscriptionRequest> = /*@__PURE__*/
+  messageDesc(file_api_admin_v1_admin, 11);
+
+/**
+ * @generated from message admin.v1.DeleteWebhookSubscriptionResponse
+ */
+export type DeleteWebhookSubscriptionResponse = Message<"admin.v1.DeleteWebhookSubscriptionResponse"> & {
+};
+
+/**
+ * Describes the message admin.v1.DeleteWebhookSubscriptionResponse.
+ * Use `create(DeleteWebhookSubscriptionResponseSchema)` to create a new message.
+ */
+export const DeleteWebhookSubscriptionResponseSchema: GenMessage<DeleteWebhookSubscriptionResponse> = /*@__PURE__*/
+  messageDesc(file_api_admin_v1_admin, 12);
+
+/**
+ * @generated from message admin.v1.ListWebhookDeliveriesRequest
+ */
+export type ListWebhookDeliveriesRequest = Message<"admin.v1.ListWebhookDeliveriesRequest"> & {
+  /**
+   * @generated from field: int64 subscription_id = 1;
+   */
+  subscriptionId: bigint;
+
+  /**
+   * 可选，默认50，最多200
+   *
+   * @generated from field: int32 limit = 2;
+   */
+  limit: number;
+};
+
+/**
+ * Describes the message admin.v1.ListWebhookDeliveriesRequest.
+ * Use `create(ListWebhookDeliveriesRequestSchema)` to create a new message.
+ */
+export const ListWebhookDeliveriesRequestSchema: GenMessage<ListWebhookDeliveriesRequest> = /*@__PURE__*/
+  messageDesc(file_api_admin_v1_admin, 13);
+
+/**
+ * @generated from message admin.v1.ListWebhookDeliveriesResponse
+ */
+export type ListWebhookDeliveriesResponse = Message<"admin.v1.ListWebhookDeliveriesResponse"> & {
+  /**
+   * 最新的在前
+   *
+   * @generated from field: repeated admin.v1.WebhookDelivery deliveries = 1;
+   */
+  deliveries: WebhookDelivery[];
+};
+
+/**
+ * Describes the message admin.v1.ListWebhookDeliveriesResponse.
+ * Use `create(ListWebhookDeliveriesResponseSchema)` to create a new message.
+ */
+export const ListWebhookDeliveriesResponseSchema: GenMessage<ListWebhookDeliveriesResponse> = /*@__PURE__*/
+  messageDesc(file_api_admin_v1_admin, 14);
+
+/**
+ * @generated from message admin.v1.RedeliverWebhookRequest
+ */
+export type RedeliverWebhookRequest = Message<"admin.v1.RedeliverWebhookRequest"> & {
+  /**
+   * @generated from field: int64 delivery_id = 1;
+   */
+  deliveryId: bigint;
+};
+
+/**
+ * Describes the message admin.v1.RedeliverWebhookRequest.
+ * Use `create(RedeliverWebhookRequestSchema)` to create a new message.
+ */
+export const RedeliverWebhookRequestSchema: GenMessage<RedeliverWebhookRequest> = /*@__PURE__*/
+  messageDesc(file_api_admin_v1_admin, 15);
+
+/**
+ * @generated from message admin.v1.RedeliverWebhookResponse
+ */
+export type RedeliverWebhookResponse = Message<"admin.v1.RedeliverWebhookResponse"> & {
+  /**
+   * @generated from field: admin.v1.WebhookDelivery delivery = 1;
+   */
+  delivery?: WebhookDelivery;
+};
+
+/**
+ * Describes the message admin.v1.RedeliverWebhookResponse.
+ * Use `create(RedeliverWebhookResponseSchema)` to create a new message.
+ */
+export const RedeliverWebhookResponseSchema: GenMessage<RedeliverWebhookResponse> = /*@__PURE__*/
+  messageDesc(file_api_admin_v1_admin, 16);
+
+/**
+ * @generated from message admin.v1.SetUserCredentialBackendRequest
+ */
+export type SetUserCredentialBackendRequest = Message<"admin.v1.SetUserCredentialBackendRequest"> & {
+  /**
+   * @generated from field: string username = 1;
+   */
+  username: string;
+
+  /**
+   * local 或配置中的后端标识，为空时使用租户默认后端
+   *
+   * @generated from field: string backend = 2;
+   */
+  backend: string;
+};
+
+/**
+ * Describes the message admin.v1.SetUserCredentialBackendRequest.
+ * Use `create(SetUserCredentialBackendRequestSchema)` to create a new message.
+ */
+export const SetUserCredentialBackendRequestSchema: GenMessage<SetUserCredentialBackendRequest> = /*@__PURE__*/
+  messageDesc(file_api_admin_v1_admin, 17);
+
+/**
+ * @generated from message admin.v1.SetUserCredentialBackendResponse
+ */
+export type SetUserCredentialBackendResponse = Message<"admin.v1.SetUserCredentialBackendResponse"> & {
+};
+
+/**
+ * Describes the message admin.v1.SetUserCredentialBackendResponse.
+ * Use `create(SetUserCredentialBackendResponseSchema)` to create a new message.
+ */
+export const SetUserCredentialBackendResponseSchema: GenMessage<SetUserCredentialBackendResponse> = /*@__PURE__*/
+  messageDesc(file_api_admin_v1_admin, 18);
+
+/**
+ * 管理接口，需要 admin 角色的 Bearer 令牌
+ *
+ * @generated from service admin.v1.AdminService
+ */
+export const AdminService: GenService<{
+  /**
+   * @generated from rpc admin.v1.AdminService.CreateInvite
+   */
+  createInvite: {
+    methodKind: "unary";
+    input: typeof CreateInviteRequestSchema;
+    output: typeof CreateInviteResponseSchema;
+  },
+  /**
+   * @generated from rpc admin.v1.AdminService.ListInvites
+   */
+  listInvites: {
+    methodKind: "unary";
+    input: typeof ListInvitesRequestSchema;
+    output: typeof ListInvitesResponseSchema;
+  },
+  /**
+   * @generated from rpc admin.v1.AdminService.CreateWebhookSubscription
+   */
+  createWebhookSubscription: {
+    methodKind: "unary";
+    input: typeof CreateWebhookSubscriptionRequestSchema;
+    output: typeof CreateWebhookSubscriptionResponseSchema;
+  },
+  /**
+   * @generated from rpc admin.v1.AdminService.ListWebhookSubscriptions
+   */
+  listWebhookSubscriptions: {
+    methodKind: "unary";
+    input: typeof ListWebhookSubscriptionsRequestSchema;
+    output: typeof ListWebhookSubscriptionsResponseSchema;
+  },
+  /**
+   * @generated from rpc admin.v1.AdminService.DeleteWebhookSubscription
+   */
+  deleteWebhookSubscription: {
+    methodKind: "unary";
+    input: typeof DeleteWebhookSubscriptionRequestSchema;
+    output: typeof DeleteWebhookSubscriptionResponseSchema;
+  },
+  /**
+   * @generated from rpc admin.v1.AdminService.ListWebhookDeliveries
+   */
+  listWebhookDeliveries: {
+    methodKind: "unary";
+    input: typeof ListWebhookDeliveriesRequestSchema;
+    output: typeof ListWebhookDeliveriesResponseSchema;
+  },
+  /**
+   * RedeliverWebhook 重新投递，包括已成功和进入 dead 状态的投递
+   *
+   * @generated from rpc admin.v1.AdminService.RedeliverWebhook
+   */
+  redeliverWebhook: {
+    methodKind: "unary";
+    input: typeof RedeliverWebhookRequestSchema;
+    output: typeof RedeliverWebhookResponseSchema;
+  },
+  /**
+   * SetUserCredentialBackend 指定用户校验凭证的后端，同名用户属于其他后端时目录登录会被拒绝
+   *
+   * @generated from rpc admin.v1.AdminService.SetUserCredentialBackend
+   */
+  setUserCredentialBackend: {
+    methodKind: "unary";
+    input: typeof SetUserCredentialBackendRequestSchema;
+    output: typeof SetUserCredentialBackendResponseSchema;
+  },
+}> = /*@__PURE__*/
+  serviceDesc(file_api_admin_v1_admin, 0);
+
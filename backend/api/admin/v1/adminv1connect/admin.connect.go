@@ -0,0 +1,317 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: api/admin/v1/admin.proto
+
+package adminv1connect
+
+import (
+	context "context"
+	errors "errors"
+	http "net/http"
+	strings "strings"
+
+	v1 "connect-go-example/api/admin/v1"
+	connect "connectrpc.com/connect"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// AdminServiceName is the fully-qualified name of the AdminService service.
+	AdminServiceName = "admin.v1.AdminService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// AdminServiceCreateInviteProcedure is the fully-qualified name of the AdminService's CreateInvite
+	// RPC.
+	AdminServiceCreateInviteProcedure = "/admin.v1.AdminService/CreateInvite"
+	// AdminServiceListInvitesProcedure is the fully-qualified name of the AdminService's ListInvites
+	// RPC.
+	AdminServiceListInvitesProcedure = "/admin.v1.AdminService/ListInvites"
+	// AdminServiceCreateWebhookSubscriptionProcedure is the fully-qualified name of the AdminService's
+	// CreateWebhookSubscription RPC.
+	AdminServiceCreateWebhookSubscriptionProcedure = "/admin.v1.AdminService/CreateWebhookSubscription"
+	// AdminServiceListWebhookSubscriptionsProcedure is the fully-qualified name of the AdminService's
+	// ListWebhookSubscriptions RPC.
+	AdminServiceListWebhookSubscriptionsProcedure = "/admin.v1.AdminService/ListWebhookSubscriptions"
+	// AdminServiceDeleteWebhookSubscriptionProcedure is the fully-qualified name of the AdminService's
+	// DeleteWebhookSubscription RPC.
+	AdminServiceDeleteWebhookSubscriptionProcedure = "/admin.v1.AdminService/DeleteWebhookSubscription"
+	// AdminServiceListWebhookDeliveriesProcedure is the fully-qualified name of the AdminService's
+	// ListWebhookDeliveries RPC.
+	AdminServiceListWebhookDeliveriesProcedure = "/admin.v1.AdminService/ListWebhookDeliveries"
+	// AdminServiceRedeliverWebhookProcedure is the fully-qualified name of the AdminService's
+	// RedeliverWebhook RPC.
+	AdminServiceRedeliverWebhookProcedure = "/admin.v1.AdminService/RedeliverWebhook"
+	// AdminServiceSetUserCredentialBackendProcedure is the fully-qualified name of the AdminService's
+	// SetUserCredentialBackend RPC.
+	AdminServiceSetUserCredentialBackendProcedure = "/admin.v1.AdminService/SetUserCredentialBackend"
+)
+
+// AdminServiceClient is a client for the admin.v1.AdminService service.
+type AdminServiceClient interface {
+	CreateInvite(context.Context, *connect.Request[v1.CreateInviteRequest]) (*connect.Response[v1.CreateInviteResponse], error)
+	ListInvites(context.Context, *connect.Request[v1.ListInvitesRequest]) (*connect.Response[v1.ListInvitesResponse], error)
+	CreateWebhookSubscription(context.Context, *connect.Request[v1.CreateWebhookSubscriptionRequest]) (*connect.Response[v1.CreateWebhookSubscriptionResponse], error)
+	ListWebhookSubscriptions(context.Context, *connect.Request[v1.ListWebhookSubscriptionsRequest]) (*connect.Response[v1.ListWebhookSubscriptionsResponse], error)
+	DeleteWebhookSubscription(context.Context, *connect.Request[v1.DeleteWebhookSubscriptionRequest]) (*connect.Response[v1.DeleteWebhookSubscriptionResponse], error)
+	ListWebhookDeliveries(context.Context, *connect.Request[v1.ListWebhookDeliveriesRequest]) (*connect.Response[v1.ListWebhookDeliveriesResponse], error)
+	// RedeliverWebhook 重新投递，包括已成功和进入 dead 状态的投递
+	RedeliverWebhook(context.Context, *connect.Request[v1.RedeliverWebhookRequest]) (*connect.Response[v1.RedeliverWebhookResponse], error)
+	// SetUserCredentialBackend 指定用户校验凭证的后端，同名用户属于其他后端时目录登录会被拒绝
+	SetUserCredentialBackend(context.Context, *connect.Request[v1.SetUserCredentialBackendRequest]) (*connect.Response[v1.SetUserCredentialBackendResponse], error)
+}
+
+// NewAdminServiceClient constructs a client for the admin.v1.AdminService service. By default, it
+// uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and sends
+// uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewAdminServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) AdminServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	adminServiceMethods := v1.File_api_admin_v1_admin_proto.Services().ByName("AdminService").Methods()
+	return &adminServiceClient{
+		createInvite: connect.NewClient[v1.CreateInviteRequest, v1.CreateInviteResponse](
+			httpClient,
+			baseURL+AdminServiceCreateInviteProcedure,
+			connect.WithSchema(adminServiceMethods.ByName("CreateInvite")),
+			connect.WithClientOptions(opts...),
+		),
+		listInvites: connect.NewClient[v1.ListInvitesRequest, v1.ListInvitesResponse](
+			httpClient,
+			baseURL+AdminServiceListInvitesProcedure,
+			connect.WithSchema(adminServiceMethods.ByName("ListInvites")),
+			connect.WithClientOptions(opts...),
+		),
+		createWebhookSubscription: connect.NewClient[v1.CreateWebhookSubscriptionRequest, v1.CreateWebhookSubscriptionResponse](
+			httpClient,
+			baseURL+AdminServiceCreateWebhookSubscriptionProcedure,
+			connect.WithSchema(adminServiceMethods.ByName("CreateWebhookSubscription")),
+			connect.WithClientOptions(opts...),
+		),
+		listWebhookSubscriptions: connect.NewClient[v1.ListWebhookSubscriptionsRequest, v1.ListWebhookSubscriptionsResponse](
+			httpClient,
+			baseURL+AdminServiceListWebhookSubscriptionsProcedure,
+			connect.WithSchema(adminServiceMethods.ByName("ListWebhookSubscriptions")),
+			connect.WithClientOptions(opts...),
+		),
+		deleteWebhookSubscription: connect.NewClient[v1.DeleteWebhookSubscriptionRequest, v1.DeleteWebhookSubscriptionResponse](
+			httpClient,
+			baseURL+AdminServiceDeleteWebhookSubscriptionProcedure,
+			connect.WithSchema(adminServiceMethods.ByName("DeleteWebhookSubscription")),
+			connect.WithClientOptions(opts...),
+		),
+		listWebhookDeliveries: connect.NewClient[v1.ListWebhookDeliveriesRequest, v1.ListWebhookDeliveriesResponse](
+			httpClient,
+			baseURL+AdminServiceListWebhookDeliveriesProcedure,
+			connect.WithSchema(adminServiceMethods.ByName("ListWebhookDeliveries")),
+			connect.WithClientOptions(opts...),
+		),
+		redeliverWebhook: connect.NewClient[v1.RedeliverWebhookRequest, v1.RedeliverWebhookResponse](
+			httpClient,
+			baseURL+AdminServiceRedeliverWebhookProcedure,
+			connect.WithSchema(adminServiceMethods.ByName("RedeliverWebhook")),
+			connect.WithClientOptions(opts...),
+		),
+		setUserCredentialBackend: connect.NewClient[v1.SetUserCredentialBackendRequest, v1.SetUserCredentialBackendResponse](
+			httpClient,
+			baseURL+AdminServiceSetUserCredentialBackendProcedure,
+			connect.WithSchema(adminServiceMethods.ByName("SetUserCredentialBackend")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// adminServiceClient implements AdminServiceClient.
+type adminServiceClient struct {
+	createInvite              *connect.Client[v1.CreateInviteRequest, v1.CreateInviteResponse]
+	listInvites               *connect.Client[v1.ListInvitesRequest, v1.ListInvitesResponse]
+	createWebhookSubscription *connect.Client[v1.CreateWebhookSubscriptionRequest, v1.CreateWebhookSubscriptionResponse]
+	listWebhookSubscriptions  *connect.Client[v1.ListWebhookSubscriptionsRequest, v1.ListWebhookSubscriptionsResponse]
+	deleteWebhookSubscription *connect.Client[v1.DeleteWebhookSubscriptionRequest, v1.DeleteWebhookSubscriptionResponse]
+	listWebhookDeliveries     *connect.Client[v1.ListWebhookDeliveriesRequest, v1.ListWebhookDeliveriesResponse]
+	redeliverWebhook          *connect.Client[v1.RedeliverWebhookRequest, v1.RedeliverWebhookResponse]
+	setUserCredentialBackend  *connect.Client[v1.SetUserCredentialBackendRequest, v1.SetUserCredentialBackendResponse]
+}
+
+// CreateInvite calls admin.v1.AdminService.CreateInvite.
+func (c *adminServiceClient) CreateInvite(ctx context.Context, req *connect.Request[v1.CreateInviteRequest]) (*connect.Response[v1.CreateInviteResponse], error) {
+	return c.createInvite.CallUnary(ctx, req)
+}
+
+// ListInvites calls admin.v1.AdminService.ListInvites.
+func (c *adminServiceClient) ListInvites(ctx context.Context, req *connect.Request[v1.ListInvitesRequest]) (*connect.Response[v1.ListInvitesResponse], error) {
+	return c.listInvites.CallUnary(ctx, req)
+}
+
+// CreateWebhookSubscription calls admin.v1.AdminService.CreateWebhookSubscription.
+func (c *adminServiceClient) CreateWebhookSubscription(ctx context.Context, req *connect.Request[v1.CreateWebhookSubscriptionRequest]) (*connect.Response[v1.CreateWebhookSubscriptionResponse], error) {
+	return c.createWebhookSubscription.CallUnary(ctx, req)
+}
+
+// ListWebhookSubscriptions calls admin.v1.AdminService.ListWebhookSubscriptions.
+func (c *adminServiceClient) ListWebhookSubscriptions(ctx context.Context, req *connect.Request[v1.ListWebhookSubscriptionsRequest]) (*connect.Response[v1.ListWebhookSubscriptionsResponse], error) {
+	return c.listWebhookSubscriptions.CallUnary(ctx, req)
+}
+
+// DeleteWebhookSubscription calls admin.v1.AdminService.DeleteWebhookSubscription.
+func (c *adminServiceClient) DeleteWebhookSubscription(ctx context.Context, req *connect.Request[v1.DeleteWebhookSubscriptionRequest]) (*connect.Response[v1.DeleteWebhookSubscriptionResponse], error) {
+	return c.deleteWebhookSubscription.CallUnary(ctx, req)
+}
+
+// ListWebhookDeliveries calls admin.v1.AdminService.ListWebhookDeliveries.
+func (c *adminServiceClient) ListWebhookDeliveries(ctx context.Context, req *connect.Request[v1.ListWebhookDeliveriesRequest]) (*connect.Response[v1.ListWebhookDeliveriesResponse], error) {
+	return c.listWebhookDeliveries.CallUnary(ctx, req)
+}
+
+// RedeliverWebhook calls admin.v1.AdminService.RedeliverWebhook.
+func (c *adminServiceClient) RedeliverWebhook(ctx context.Context, req *connect.Request[v1.RedeliverWebhookRequest]) (*connect.Response[v1.RedeliverWebhookResponse], error) {
+	return c.redeliverWebhook.CallUnary(ctx, req)
+}
+
+// SetUserCredentialBackend calls admin.v1.AdminService.SetUserCredentialBackend.
+func (c *adminServiceClient) SetUserCredentialBackend(ctx context.Context, req *connect.Request[v1.SetUserCredentialBackendRequest]) (*connect.Response[v1.SetUserCredentialBackendResponse], error) {
+	return c.setUserCredentialBackend.CallUnary(ctx, req)
+}
+
+// AdminServiceHandler is an implementation of the admin.v1.AdminService service.
+type AdminServiceHandler interface {
+	CreateInvite(context.Context, *connect.Request[v1.CreateInviteRequest]) (*connect.Response[v1.CreateInviteResponse], error)
+	ListInvites(context.Context, *connect.Request[v1.ListInvitesRequest]) (*connect.Response[v1.ListInvitesResponse], error)
+	CreateWebhookSubscription(context.Context, *connect.Request[v1.CreateWebhookSubscriptionRequest]) (*connect.Response[v1.CreateWebhookSubscriptionResponse], error)
+	ListWebhookSubscriptions(context.Context, *connect.Request[v1.ListWebhookSubscriptionsRequest]) (*connect.Response[v1.ListWebhookSubscriptionsResponse], error)
+	DeleteWebhookSubscription(context.Context, *connect.Request[v1.DeleteWebhookSubscriptionRequest]) (*connect.Response[v1.DeleteWebhookSubscriptionResponse], error)
+	ListWebhookDeliveries(context.Context, *connect.Request[v1.ListWebhookDeliveriesRequest]) (*connect.Response[v1.ListWebhookDeliveriesResponse], error)
+	// RedeliverWebhook 重新投递，包括已成功和进入 dead 状态的投递
+	RedeliverWebhook(context.Context, *connect.Request[v1.RedeliverWebhookRequest]) (*connect.Response[v1.RedeliverWebhookResponse], error)
+	// SetUserCredentialBackend 指定用户校验凭证的后端，同名用户属于其他后端时目录登录会被拒绝
+	SetUserCredentialBackend(context.Context, *connect.Request[v1.SetUserCredentialBackendRequest]) (*connect.Response[v1.SetUserCredentialBackendResponse], error)
+}
+
+// NewAdminServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewAdminServiceHandler(svc AdminServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	adminServiceMethods := v1.File_api_admin_v1_admin_proto.Services().ByName("AdminService").Methods()
+	adminServiceCreateInviteHandler := connect.NewUnaryHandler(
+		AdminServiceCreateInviteProcedure,
+		svc.CreateInvite,
+		connect.WithSchema(adminServiceMethods.ByName("CreateInvite")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminServiceListInvitesHandler := connect.NewUnaryHandler(
+		AdminServiceListInvitesProcedure,
+		svc.ListInvites,
+		connect.WithSchema(adminServiceMethods.ByName("ListInvites")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminServiceCreateWebhookSubscriptionHandler := connect.NewUnaryHandler(
+		AdminServiceCreateWebhookSubscriptionProcedure,
+		svc.CreateWebhookSubscription,
+		connect.WithSchema(adminServiceMethods.ByName("CreateWebhookSubscription")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminServiceListWebhookSubscriptionsHandler := connect.NewUnaryHandler(
+		AdminServiceListWebhookSubscriptionsProcedure,
+		svc.ListWebhookSubscriptions,
+		connect.WithSchema(adminServiceMethods.ByName("ListWebhookSubscriptions")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminServiceDeleteWebhookSubscriptionHandler := connect.NewUnaryHandler(
+		AdminServiceDeleteWebhookSubscriptionProcedure,
+		svc.DeleteWebhookSubscription,
+		connect.WithSchema(adminServiceMethods.ByName("DeleteWebhookSubscription")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminServiceListWebhookDeliveriesHandler := connect.NewUnaryHandler(
+		AdminServiceListWebhookDeliveriesProcedure,
+		svc.ListWebhookDeliveries,
+		connect.WithSchema(adminServiceMethods.ByName("ListWebhookDeliveries")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminServiceRedeliverWebhookHandler := connect.NewUnaryHandler(
+		AdminServiceRedeliverWebhookProcedure,
+		svc.RedeliverWebhook,
+		connect.WithSchema(adminServiceMethods.ByName("RedeliverWebhook")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminServiceSetUserCredentialBackendHandler := connect.NewUnaryHandler(
+		AdminServiceSetUserCredentialBackendProcedure,
+		svc.SetUserCredentialBackend,
+		connect.WithSchema(adminServiceMethods.ByName("SetUserCredentialBackend")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/admin.v1.AdminService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case AdminServiceCreateInviteProcedure:
+			adminServiceCreateInviteHandler.ServeHTTP(w, r)
+		case AdminServiceListInvitesProcedure:
+			adminServiceListInvitesHandler.ServeHTTP(w, r)
+		case AdminServiceCreateWebhookSubscriptionProcedure:
+			adminServiceCreateWebhookSubscriptionHandler.ServeHTTP(w, r)
+		case AdminServiceListWebhookSubscriptionsProcedure:
+			adminServiceListWebhookSubscriptionsHandler.ServeHTTP(w, r)
+		case AdminServiceDeleteWebhookSubscriptionProcedure:
+			adminServiceDeleteWebhookSubscriptionHandler.ServeHTTP(w, r)
+		case AdminServiceListWebhookDeliveriesProcedure:
+			adminServiceListWebhookDeliveriesHandler.ServeHTTP(w, r)
+		case AdminServiceRedeliverWebhookProcedure:
+			adminServiceRedeliverWebhookHandler.ServeHTTP(w, r)
+		case AdminServiceSetUserCredentialBackendProcedure:
+			adminServiceSetUserCredentialBackendHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedAdminServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedAdminServiceHandler struct{}
+
+func (UnimplementedAdminServiceHandler) CreateInvite(context.Context, *connect.Request[v1.CreateInviteRequest]) (*connect.Response[v1.CreateInviteResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("admin.v1.AdminService.CreateInvite is not implemented"))
+}
+
+func (UnimplementedAdminServiceHandler) ListInvites(context.Context, *connect.Request[v1.ListInvitesRequest]) (*connect.Response[v1.ListInvitesResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("admin.v1.AdminService.ListInvites is not implemented"))
+}
+
+func (UnimplementedAdminServiceHandler) CreateWebhookSubscription(context.Context, *connect.Request[v1.CreateWebhookSubscriptionRequest]) (*connect.Response[v1.CreateWebhookSubscriptionResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("admin.v1.AdminService.CreateWebhookSubscription is not implemented"))
+}
+
+func (UnimplementedAdminServiceHandler) ListWebhookSubscriptions(context.Context, *connect.Request[v1.ListWebhookSubscriptionsRequest]) (*connect.Response[v1.ListWebhookSubscriptionsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("admin.v1.AdminService.ListWebhookSubscriptions is not implemented"))
+}
+
+func (UnimplementedAdminServiceHandler) DeleteWebhookSubscription(context.Context, *connect.Request[v1.DeleteWebhookSubscriptionRequest]) (*connect.Response[v1.DeleteWebhookSubscriptionResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("admin.v1.AdminService.DeleteWebhookSubscription is not implemented"))
+}
+
+func (UnimplementedAdminServiceHandler) ListWebhookDeliveries(context.Context, *connect.Request[v1.ListWebhookDeliveriesRequest]) (*connect.Response[v1.ListWebhookDeliveriesResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("admin.v1.AdminService.ListWebhookDeliveries is not implemented"))
+}
+
+func (UnimplementedAdminServiceHandler) RedeliverWebhook(context.Context, *connect.Request[v1.RedeliverWebhookRequest]) (*connect.Response[v1.RedeliverWebhookResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("admin.v1.AdminService.RedeliverWebhook is not implemented"))
+}
+
+func (UnimplementedAdminServiceHandler) SetUserCredentialBackend(context.Context, *connect.Request[v1.SetUserCredentialBackendRequest]) (*connect.Response[v1.SetUserCredentialBackendResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("admin.v1.AdminService.SetUserCredentialBackend is not implemented"))
+}
@@ -22,31 +22,2011 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// 登录请求的状态
+type AuthRequestState int32
+
+const (
+	AuthRequestState_AUTH_REQUEST_STATE_UNSPECIFIED AuthRequestState = 0
+	AuthRequestState_AUTH_REQUEST_STATE_PENDING     AuthRequestState = 1 // 等待用户在浏览器中完成登录
+	AuthRequestState_AUTH_REQUEST_STATE_APPROVED    AuthRequestState = 2 // 登录成功，携带令牌
+	AuthRequestState_AUTH_REQUEST_STATE_DENIED      AuthRequestState = 3 // 用户拒绝了本次登录
+	AuthRequestState_AUTH_REQUEST_STATE_EXPIRED     AuthRequestState = 4 // 登录请求已过期
+)
+
+// Enum value maps for AuthRequestState.
+var (
+	AuthRequestState_name = map[int32]string{
+		0: "AUTH_REQUEST_STATE_UNSPECIFIED",
+		1: "AUTH_REQUEST_STATE_PENDING",
+		2: "AUTH_REQUEST_STATE_APPROVED",
+		3: "AUTH_REQUEST_STATE_DENIED",
+		4: "AUTH_REQUEST_STATE_EXPIRED",
+	}
+	AuthRequestState_value = map[string]int32{
+		"AUTH_REQUEST_STATE_UNSPECIFIED": 0,
+		"AUTH_REQUEST_STATE_PENDING":     1,
+		"AUTH_REQUEST_STATE_APPROVED":    2,
+		"AUTH_REQUEST_STATE_DENIED":      3,
+		"AUTH_REQUEST_STATE_EXPIRED":     4,
+	}
+)
+
+func (x AuthRequestState) Enum() *AuthRequestState {
+	p := new(AuthRequestState)
+	*p = x
+	return p
+}
+
+func (x AuthRequestState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AuthRequestState) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_greet_v1_greet_proto_enumTypes[0].Descriptor()
+}
+
+func (AuthRequestState) Type() protoreflect.EnumType {
+	return &file_api_greet_v1_greet_proto_enumTypes[0]
+}
+
+func (x AuthRequestState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AuthRequestState.Descriptor instead.
+func (AuthRequestState) EnumDescriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{0}
+}
+
+// 登录时提交的凭证
+type CredentialType int32
+
+const (
+	CredentialType_CREDENTIAL_TYPE_UNSPECIFIED CredentialType = 0
+	CredentialType_CREDENTIAL_TYPE_DERIVED     CredentialType = 1 // 按 kdf 参数派生后提交 hashed_credential
+	CredentialType_CREDENTIAL_TYPE_PASSWORD    CredentialType = 2 // 目录服务账号，提交 password 原文，由目录服务校验
+)
+
+// Enum value maps for CredentialType.
+var (
+	CredentialType_name = map[int32]string{
+		0: "CREDENTIAL_TYPE_UNSPECIFIED",
+		1: "CREDENTIAL_TYPE_DERIVED",
+		2: "CREDENTIAL_TYPE_PASSWORD",
+	}
+	CredentialType_value = map[string]int32{
+		"CREDENTIAL_TYPE_UNSPECIFIED": 0,
+		"CREDENTIAL_TYPE_DERIVED":     1,
+		"CREDENTIAL_TYPE_PASSWORD":    2,
+	}
+)
+
+func (x CredentialType) Enum() *CredentialType {
+	p := new(CredentialType)
+	*p = x
+	return p
+}
+
+func (x CredentialType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CredentialType) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_greet_v1_greet_proto_enumTypes[1].Descriptor()
+}
+
+func (CredentialType) Type() protoreflect.EnumType {
+	return &file_api_greet_v1_greet_proto_enumTypes[1]
+}
+
+func (x CredentialType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CredentialType.Descriptor instead.
+func (CredentialType) EnumDescriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{1}
+}
+
+// 需要工作量证明的操作
+type PowAction int32
+
+const (
+	PowAction_POW_ACTION_UNSPECIFIED    PowAction = 0
+	PowAction_POW_ACTION_REGISTER       PowAction = 1
+	PowAction_POW_ACTION_AUTH_CHALLENGE PowAction = 2
+)
+
+// Enum value maps for PowAction.
+var (
+	PowAction_name = map[int32]string{
+		0: "POW_ACTION_UNSPECIFIED",
+		1: "POW_ACTION_REGISTER",
+		2: "POW_ACTION_AUTH_CHALLENGE",
+	}
+	PowAction_value = map[string]int32{
+		"POW_ACTION_UNSPECIFIED":    0,
+		"POW_ACTION_REGISTER":       1,
+		"POW_ACTION_AUTH_CHALLENGE": 2,
+	}
+)
+
+func (x PowAction) Enum() *PowAction {
+	p := new(PowAction)
+	*p = x
+	return p
+}
+
+func (x PowAction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PowAction) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_greet_v1_greet_proto_enumTypes[2].Descriptor()
+}
+
+func (PowAction) Type() protoreflect.EnumType {
+	return &file_api_greet_v1_greet_proto_enumTypes[2]
+}
+
+func (x PowAction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PowAction.Descriptor instead.
+func (PowAction) EnumDescriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{2}
+}
+
+// 工作量证明的解：sha256(challenge + ":" + nonce) 的前导零比特数不少于题目难度
+type ProofOfWork struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Challenge     string                 `protobuf:"bytes,1,opt,name=challenge,proto3" json:"challenge,omitempty"` // GetPowChallenge 返回的题目
+	Nonce         string                 `protobuf:"bytes,2,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProofOfWork) Reset() {
+	*x = ProofOfWork{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProofOfWork) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProofOfWork) ProtoMessage() {}
+
+func (x *ProofOfWork) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProofOfWork.ProtoReflect.Descriptor instead.
+func (*ProofOfWork) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ProofOfWork) GetChallenge() string {
+	if x != nil {
+		return x.Challenge
+	}
+	return ""
+}
+
+func (x *ProofOfWork) GetNonce() string {
+	if x != nil {
+		return x.Nonce
+	}
+	return ""
+}
+
+// 客户端派生凭证使用的 KDF 参数，由服务端下发
+type KdfParams struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Algorithm     string                 `protobuf:"bytes,1,opt,name=algorithm,proto3" json:"algorithm,omitempty"` // argon2id；旧账号为 legacy，表示客户端自行选择的算法
+	Version       int32                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`    // 参数版本，服务端提高成本时递增
+	MemoryKib     uint32                 `protobuf:"varint,3,opt,name=memory_kib,json=memoryKib,proto3" json:"memory_kib,omitempty"`
+	Iterations    uint32                 `protobuf:"varint,4,opt,name=iterations,proto3" json:"iterations,omitempty"`
+	Parallelism   uint32                 `protobuf:"varint,5,opt,name=parallelism,proto3" json:"parallelism,omitempty"`
+	Salt          string                 `protobuf:"bytes,6,opt,name=salt,proto3" json:"salt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KdfParams) Reset() {
+	*x = KdfParams{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KdfParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KdfParams) ProtoMessage() {}
+
+func (x *KdfParams) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KdfParams.ProtoReflect.Descriptor instead.
+func (*KdfParams) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *KdfParams) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *KdfParams) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *KdfParams) GetMemoryKib() uint32 {
+	if x != nil {
+		return x.MemoryKib
+	}
+	return 0
+}
+
+func (x *KdfParams) GetIterations() uint32 {
+	if x != nil {
+		return x.Iterations
+	}
+	return 0
+}
+
+func (x *KdfParams) GetParallelism() uint32 {
+	if x != nil {
+		return x.Parallelism
+	}
+	return 0
+}
+
+func (x *KdfParams) GetSalt() string {
+	if x != nil {
+		return x.Salt
+	}
+	return ""
+}
+
+// 服务端签发的 KDF 票据，保证 salt 和参数由服务端生成
+type KdfTicket struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kdf           *KdfParams             `protobuf:"bytes,1,opt,name=kdf,proto3" json:"kdf,omitempty"`
+	Ticket        string                 `protobuf:"bytes,2,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	ExpiresAt     int64                  `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KdfTicket) Reset() {
+	*x = KdfTicket{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KdfTicket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KdfTicket) ProtoMessage() {}
+
+func (x *KdfTicket) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KdfTicket.ProtoReflect.Descriptor instead.
+func (*KdfTicket) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *KdfTicket) GetKdf() *KdfParams {
+	if x != nil {
+		return x.Kdf
+	}
+	return nil
+}
+
+func (x *KdfTicket) GetTicket() string {
+	if x != nil {
+		return x.Ticket
+	}
+	return ""
+}
+
+func (x *KdfTicket) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+type GetRegistrationParamsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRegistrationParamsRequest) Reset() {
+	*x = GetRegistrationParamsRequest{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRegistrationParamsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRegistrationParamsRequest) ProtoMessage() {}
+
+func (x *GetRegistrationParamsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRegistrationParamsRequest.ProtoReflect.Descriptor instead.
+func (*GetRegistrationParamsRequest) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{3}
+}
+
+type GetRegistrationParamsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Params        *KdfTicket             `protobuf:"bytes,1,opt,name=params,proto3" json:"params,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRegistrationParamsResponse) Reset() {
+	*x = GetRegistrationParamsResponse{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRegistrationParamsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRegistrationParamsResponse) ProtoMessage() {}
+
+func (x *GetRegistrationParamsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRegistrationParamsResponse.ProtoReflect.Descriptor instead.
+func (*GetRegistrationParamsResponse) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetRegistrationParamsResponse) GetParams() *KdfTicket {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
 type RegisterRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
-	PasswordHash  string                 `protobuf:"bytes,2,opt,name=password_hash,json=passwordHash,proto3" json:"password_hash,omitempty"`
-	Email         string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
-	Salt          string                 `protobuf:"bytes,4,opt,name=salt,proto3" json:"salt,omitempty"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	PasswordHash  string                 `protobuf:"bytes,2,opt,name=password_hash,json=passwordHash,proto3" json:"password_hash,omitempty"`
+	Email         string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	Salt          string                 `protobuf:"bytes,4,opt,name=salt,proto3" json:"salt,omitempty"`
+	InviteCode    string                 `protobuf:"bytes,5,opt,name=invite_code,json=inviteCode,proto3" json:"invite_code,omitempty"`    // 邀请注册模式下必填
+	Pow           *ProofOfWork           `protobuf:"bytes,6,opt,name=pow,proto3" json:"pow,omitempty"`                                    // 开启工作量证明时必填
+	KdfTicket     string                 `protobuf:"bytes,7,opt,name=kdf_ticket,json=kdfTicket,proto3" json:"kdf_ticket,omitempty"`       // GetRegistrationParams 返回的票据，提供后 salt 以票据为准
+	EmailTicket   string                 `protobuf:"bytes,8,opt,name=email_ticket,json=emailTicket,proto3" json:"email_ticket,omitempty"` // 限定邮箱域名注册时必填，RequestRegistrationEmail 发送到邮箱的票据
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterRequest) Reset() {
+	*x = RegisterRequest{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterRequest) ProtoMessage() {}
+
+func (x *RegisterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterRequest.ProtoReflect.Descriptor instead.
+func (*RegisterRequest) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RegisterRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetPasswordHash() string {
+	if x != nil {
+		return x.PasswordHash
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetSalt() string {
+	if x != nil {
+		return x.Salt
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetInviteCode() string {
+	if x != nil {
+		return x.InviteCode
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetPow() *ProofOfWork {
+	if x != nil {
+		return x.Pow
+	}
+	return nil
+}
+
+func (x *RegisterRequest) GetKdfTicket() string {
+	if x != nil {
+		return x.KdfTicket
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetEmailTicket() string {
+	if x != nil {
+		return x.EmailTicket
+	}
+	return ""
+}
+
+type RegisterResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterResponse) Reset() {
+	*x = RegisterResponse{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterResponse) ProtoMessage() {}
+
+func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterResponse.ProtoReflect.Descriptor instead.
+func (*RegisterResponse) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RegisterResponse) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type AuthChallengeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Pow           *ProofOfWork           `protobuf:"bytes,2,opt,name=pow,proto3" json:"pow,omitempty"` // 开启工作量证明时必填
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuthChallengeRequest) Reset() {
+	*x = AuthChallengeRequest{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuthChallengeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthChallengeRequest) ProtoMessage() {}
+
+func (x *AuthChallengeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthChallengeRequest.ProtoReflect.Descriptor instead.
+func (*AuthChallengeRequest) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AuthChallengeRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *AuthChallengeRequest) GetPow() *ProofOfWork {
+	if x != nil {
+		return x.Pow
+	}
+	return nil
+}
+
+type AuthChallengeResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Challenge      string                 `protobuf:"bytes,1,opt,name=challenge,proto3" json:"challenge,omitempty"`
+	Salt           string                 `protobuf:"bytes,2,opt,name=salt,proto3" json:"salt,omitempty"`
+	Kdf            *KdfParams             `protobuf:"bytes,3,opt,name=kdf,proto3" json:"kdf,omitempty"`         // 当前凭证使用的参数
+	Upgrade        *KdfTicket             `protobuf:"bytes,4,opt,name=upgrade,proto3" json:"upgrade,omitempty"` // 参数已过时时返回，客户端用新参数重新派生凭证并在 SubmitAuth 中提交
+	CredentialType CredentialType         `protobuf:"varint,5,opt,name=credential_type,json=credentialType,proto3,enum=greet.v1.CredentialType" json:"credential_type,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *AuthChallengeResponse) Reset() {
+	*x = AuthChallengeResponse{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuthChallengeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthChallengeResponse) ProtoMessage() {}
+
+func (x *AuthChallengeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthChallengeResponse.ProtoReflect.Descriptor instead.
+func (*AuthChallengeResponse) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AuthChallengeResponse) GetChallenge() string {
+	if x != nil {
+		return x.Challenge
+	}
+	return ""
+}
+
+func (x *AuthChallengeResponse) GetSalt() string {
+	if x != nil {
+		return x.Salt
+	}
+	return ""
+}
+
+func (x *AuthChallengeResponse) GetKdf() *KdfParams {
+	if x != nil {
+		return x.Kdf
+	}
+	return nil
+}
+
+func (x *AuthChallengeResponse) GetUpgrade() *KdfTicket {
+	if x != nil {
+		return x.Upgrade
+	}
+	return nil
+}
+
+func (x *AuthChallengeResponse) GetCredentialType() CredentialType {
+	if x != nil {
+		return x.CredentialType
+	}
+	return CredentialType_CREDENTIAL_TYPE_UNSPECIFIED
+}
+
+type SubmitAuthRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Username          string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	HashedCredential  string                 `protobuf:"bytes,2,opt,name=hashed_credential,json=hashedCredential,proto3" json:"hashed_credential,omitempty"`    // 客户端使用密码 + salt 哈希后的凭证
+	AuthRequestId     string                 `protobuf:"bytes,3,opt,name=auth_request_id,json=authRequestId,proto3" json:"auth_request_id,omitempty"`           // 可选，登录成功后批准对应的登录请求
+	ChallengeResponse string                 `protobuf:"bytes,4,opt,name=challenge_response,json=challengeResponse,proto3" json:"challenge_response,omitempty"` // 客户端对挑战的响应
+	UpgradeCredential string                 `protobuf:"bytes,5,opt,name=upgrade_credential,json=upgradeCredential,proto3" json:"upgrade_credential,omitempty"` // 可选，使用 upgrade 参数派生的新凭证
+	UpgradeTicket     string                 `protobuf:"bytes,6,opt,name=upgrade_ticket,json=upgradeTicket,proto3" json:"upgrade_ticket,omitempty"`             // 与 upgrade_credential 一起提交
+	DeviceId          string                 `protobuf:"bytes,7,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`                            // 可选，客户端持久保存的设备标识，用于识别常用设备
+	Password          string                 `protobuf:"bytes,8,opt,name=password,proto3" json:"password,omitempty"`                                            // credential_type 为 PASSWORD 时提交密码原文，hashed_credential 留空
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *SubmitAuthRequest) Reset() {
+	*x = SubmitAuthRequest{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitAuthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitAuthRequest) ProtoMessage() {}
+
+func (x *SubmitAuthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitAuthRequest.ProtoReflect.Descriptor instead.
+func (*SubmitAuthRequest) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SubmitAuthRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *SubmitAuthRequest) GetHashedCredential() string {
+	if x != nil {
+		return x.HashedCredential
+	}
+	return ""
+}
+
+func (x *SubmitAuthRequest) GetAuthRequestId() string {
+	if x != nil {
+		return x.AuthRequestId
+	}
+	return ""
+}
+
+func (x *SubmitAuthRequest) GetChallengeResponse() string {
+	if x != nil {
+		return x.ChallengeResponse
+	}
+	return ""
+}
+
+func (x *SubmitAuthRequest) GetUpgradeCredential() string {
+	if x != nil {
+		return x.UpgradeCredential
+	}
+	return ""
+}
+
+func (x *SubmitAuthRequest) GetUpgradeTicket() string {
+	if x != nil {
+		return x.UpgradeTicket
+	}
+	return ""
+}
+
+func (x *SubmitAuthRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *SubmitAuthRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type SubmitAuthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"` // success；风险较高时为 step_up_required
+	State         string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	AuthToken     string                 `protobuf:"bytes,3,opt,name=auth_token,json=authToken,proto3" json:"auth_token,omitempty"` // jwt令牌
+	StepUpId      string                 `protobuf:"bytes,4,opt,name=step_up_id,json=stepUpId,proto3" json:"step_up_id,omitempty"`  // 需要二次验证时返回，使用收到的验证码调用 VerifyStepUp
+	TokenType     string                 `protobuf:"bytes,5,opt,name=token_type,json=tokenType,proto3" json:"token_type,omitempty"` // 请求携带 DPoP 证明时为 DPoP，令牌只能配合同一密钥的证明使用，否则为 Bearer
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitAuthResponse) Reset() {
+	*x = SubmitAuthResponse{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitAuthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitAuthResponse) ProtoMessage() {}
+
+func (x *SubmitAuthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitAuthResponse.ProtoReflect.Descriptor instead.
+func (*SubmitAuthResponse) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SubmitAuthResponse) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *SubmitAuthResponse) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *SubmitAuthResponse) GetAuthToken() string {
+	if x != nil {
+		return x.AuthToken
+	}
+	return ""
+}
+
+func (x *SubmitAuthResponse) GetStepUpId() string {
+	if x != nil {
+		return x.StepUpId
+	}
+	return ""
+}
+
+func (x *SubmitAuthResponse) GetTokenType() string {
+	if x != nil {
+		return x.TokenType
+	}
+	return ""
+}
+
+// 使用当前令牌换取新令牌，请求携带 DPoP 证明时新令牌绑定到该密钥
+type RefreshTokenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshTokenRequest) Reset() {
+	*x = RefreshTokenRequest{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshTokenRequest) ProtoMessage() {}
+
+func (x *RefreshTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshTokenRequest.ProtoReflect.Descriptor instead.
+func (*RefreshTokenRequest) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{11}
+}
+
+type RefreshTokenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AuthToken     string                 `protobuf:"bytes,1,opt,name=auth_token,json=authToken,proto3" json:"auth_token,omitempty"`
+	TokenType     string                 `protobuf:"bytes,2,opt,name=token_type,json=tokenType,proto3" json:"token_type,omitempty"` // DPoP 或 Bearer
+	ExpiresAt     int64                  `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshTokenResponse) Reset() {
+	*x = RefreshTokenResponse{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshTokenResponse) ProtoMessage() {}
+
+func (x *RefreshTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshTokenResponse.ProtoReflect.Descriptor instead.
+func (*RefreshTokenResponse) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RefreshTokenResponse) GetAuthToken() string {
+	if x != nil {
+		return x.AuthToken
+	}
+	return ""
+}
+
+func (x *RefreshTokenResponse) GetTokenType() string {
+	if x != nil {
+		return x.TokenType
+	}
+	return ""
+}
+
+func (x *RefreshTokenResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+type LogoutRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogoutRequest) Reset() {
+	*x = LogoutRequest{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogoutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogoutRequest) ProtoMessage() {}
+
+func (x *LogoutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogoutRequest.ProtoReflect.Descriptor instead.
+func (*LogoutRequest) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{13}
+}
+
+type LogoutResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogoutResponse) Reset() {
+	*x = LogoutResponse{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogoutResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogoutResponse) ProtoMessage() {}
+
+func (x *LogoutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogoutResponse.ProtoReflect.Descriptor instead.
+func (*LogoutResponse) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{14}
+}
+
+type VerifyStepUpRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StepUpId      string                 `protobuf:"bytes,1,opt,name=step_up_id,json=stepUpId,proto3" json:"step_up_id,omitempty"`
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"` // 通知中的6位验证码
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyStepUpRequest) Reset() {
+	*x = VerifyStepUpRequest{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyStepUpRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyStepUpRequest) ProtoMessage() {}
+
+func (x *VerifyStepUpRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyStepUpRequest.ProtoReflect.Descriptor instead.
+func (*VerifyStepUpRequest) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *VerifyStepUpRequest) GetStepUpId() string {
+	if x != nil {
+		return x.StepUpId
+	}
+	return ""
+}
+
+func (x *VerifyStepUpRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type CreateAuthRequestRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientName    string                 `protobuf:"bytes,1,opt,name=client_name,json=clientName,proto3" json:"client_name,omitempty"` // 发起登录的客户端名称，例如 desktop、cli
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAuthRequestRequest) Reset() {
+	*x = CreateAuthRequestRequest{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAuthRequestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAuthRequestRequest) ProtoMessage() {}
+
+func (x *CreateAuthRequestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAuthRequestRequest.ProtoReflect.Descriptor instead.
+func (*CreateAuthRequestRequest) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CreateAuthRequestRequest) GetClientName() string {
+	if x != nil {
+		return x.ClientName
+	}
+	return ""
+}
+
+type CreateAuthRequestResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AuthRequestId string                 `protobuf:"bytes,1,opt,name=auth_request_id,json=authRequestId,proto3" json:"auth_request_id,omitempty"` // 交给浏览器，在 SubmitAuth 时回传
+	WatchToken    string                 `protobuf:"bytes,2,opt,name=watch_token,json=watchToken,proto3" json:"watch_token,omitempty"`            // 仅发起方持有，用于订阅登录结果
+	ExpiresAt     int64                  `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`              // 过期时间，Unix 秒
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAuthRequestResponse) Reset() {
+	*x = CreateAuthRequestResponse{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAuthRequestResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAuthRequestResponse) ProtoMessage() {}
+
+func (x *CreateAuthRequestResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAuthRequestResponse.ProtoReflect.Descriptor instead.
+func (*CreateAuthRequestResponse) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CreateAuthRequestResponse) GetAuthRequestId() string {
+	if x != nil {
+		return x.AuthRequestId
+	}
+	return ""
+}
+
+func (x *CreateAuthRequestResponse) GetWatchToken() string {
+	if x != nil {
+		return x.WatchToken
+	}
+	return ""
+}
+
+func (x *CreateAuthRequestResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+type WatchAuthRequestRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AuthRequestId string                 `protobuf:"bytes,1,opt,name=auth_request_id,json=authRequestId,proto3" json:"auth_request_id,omitempty"`
+	WatchToken    string                 `protobuf:"bytes,2,opt,name=watch_token,json=watchToken,proto3" json:"watch_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchAuthRequestRequest) Reset() {
+	*x = WatchAuthRequestRequest{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchAuthRequestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchAuthRequestRequest) ProtoMessage() {}
+
+func (x *WatchAuthRequestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchAuthRequestRequest.ProtoReflect.Descriptor instead.
+func (*WatchAuthRequestRequest) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *WatchAuthRequestRequest) GetAuthRequestId() string {
+	if x != nil {
+		return x.AuthRequestId
+	}
+	return ""
+}
+
+func (x *WatchAuthRequestRequest) GetWatchToken() string {
+	if x != nil {
+		return x.WatchToken
+	}
+	return ""
+}
+
+type WatchAuthRequestResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	State         AuthRequestState       `protobuf:"varint,1,opt,name=state,proto3,enum=greet.v1.AuthRequestState" json:"state,omitempty"`
+	AuthToken     string                 `protobuf:"bytes,2,opt,name=auth_token,json=authToken,proto3" json:"auth_token,omitempty"` // 仅在 APPROVED 时返回
+	ExpiresAt     int64                  `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchAuthRequestResponse) Reset() {
+	*x = WatchAuthRequestResponse{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchAuthRequestResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchAuthRequestResponse) ProtoMessage() {}
+
+func (x *WatchAuthRequestResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchAuthRequestResponse.ProtoReflect.Descriptor instead.
+func (*WatchAuthRequestResponse) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *WatchAuthRequestResponse) GetState() AuthRequestState {
+	if x != nil {
+		return x.State
+	}
+	return AuthRequestState_AUTH_REQUEST_STATE_UNSPECIFIED
+}
+
+func (x *WatchAuthRequestResponse) GetAuthToken() string {
+	if x != nil {
+		return x.AuthToken
+	}
+	return ""
+}
+
+func (x *WatchAuthRequestResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+// 发起方使用 watch_token 取消，浏览器中的用户需要先登录，携带令牌拒绝
+type DenyAuthRequestRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AuthRequestId string                 `protobuf:"bytes,1,opt,name=auth_request_id,json=authRequestId,proto3" json:"auth_request_id,omitempty"`
+	WatchToken    string                 `protobuf:"bytes,2,opt,name=watch_token,json=watchToken,proto3" json:"watch_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DenyAuthRequestRequest) Reset() {
+	*x = DenyAuthRequestRequest{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DenyAuthRequestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DenyAuthRequestRequest) ProtoMessage() {}
+
+func (x *DenyAuthRequestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DenyAuthRequestRequest.ProtoReflect.Descriptor instead.
+func (*DenyAuthRequestRequest) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *DenyAuthRequestRequest) GetAuthRequestId() string {
+	if x != nil {
+		return x.AuthRequestId
+	}
+	return ""
+}
+
+func (x *DenyAuthRequestRequest) GetWatchToken() string {
+	if x != nil {
+		return x.WatchToken
+	}
+	return ""
+}
+
+type DenyAuthRequestResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DenyAuthRequestResponse) Reset() {
+	*x = DenyAuthRequestResponse{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DenyAuthRequestResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DenyAuthRequestResponse) ProtoMessage() {}
+
+func (x *DenyAuthRequestResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DenyAuthRequestResponse.ProtoReflect.Descriptor instead.
+func (*DenyAuthRequestResponse) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{21}
+}
+
+type CreateCrossDeviceLoginRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientName    string                 `protobuf:"bytes,1,opt,name=client_name,json=clientName,proto3" json:"client_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateCrossDeviceLoginRequest) Reset() {
+	*x = CreateCrossDeviceLoginRequest{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCrossDeviceLoginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCrossDeviceLoginRequest) ProtoMessage() {}
+
+func (x *CreateCrossDeviceLoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCrossDeviceLoginRequest.ProtoReflect.Descriptor instead.
+func (*CreateCrossDeviceLoginRequest) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CreateCrossDeviceLoginRequest) GetClientName() string {
+	if x != nil {
+		return x.ClientName
+	}
+	return ""
+}
+
+type CreateCrossDeviceLoginResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`                               // 短码，展示在二维码旁供用户核对
+	ApproveUrl    string                 `protobuf:"bytes,2,opt,name=approve_url,json=approveUrl,proto3" json:"approve_url,omitempty"` // 编码到二维码中的地址
+	AuthRequestId string                 `protobuf:"bytes,3,opt,name=auth_request_id,json=authRequestId,proto3" json:"auth_request_id,omitempty"`
+	WatchToken    string                 `protobuf:"bytes,4,opt,name=watch_token,json=watchToken,proto3" json:"watch_token,omitempty"` // 通过 WatchAuthRequest 等待批准并获取令牌
+	ExpiresAt     int64                  `protobuf:"varint,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateCrossDeviceLoginResponse) Reset() {
+	*x = CreateCrossDeviceLoginResponse{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCrossDeviceLoginResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCrossDeviceLoginResponse) ProtoMessage() {}
+
+func (x *CreateCrossDeviceLoginResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCrossDeviceLoginResponse.ProtoReflect.Descriptor instead.
+func (*CreateCrossDeviceLoginResponse) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CreateCrossDeviceLoginResponse) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *CreateCrossDeviceLoginResponse) GetApproveUrl() string {
+	if x != nil {
+		return x.ApproveUrl
+	}
+	return ""
+}
+
+func (x *CreateCrossDeviceLoginResponse) GetAuthRequestId() string {
+	if x != nil {
+		return x.AuthRequestId
+	}
+	return ""
+}
+
+func (x *CreateCrossDeviceLoginResponse) GetWatchToken() string {
+	if x != nil {
+		return x.WatchToken
+	}
+	return ""
+}
+
+func (x *CreateCrossDeviceLoginResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+// 发起跨设备登录的设备信息，批准前展示给用户核对
+type CrossDeviceLoginRequester struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ip            string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	UserAgent     string                 `protobuf:"bytes,2,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	LocationHint  string                 `protobuf:"bytes,3,opt,name=location_hint,json=locationHint,proto3" json:"location_hint,omitempty"`
+	ClientName    string                 `protobuf:"bytes,4,opt,name=client_name,json=clientName,proto3" json:"client_name,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ExpiresAt     int64                  `protobuf:"varint,6,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CrossDeviceLoginRequester) Reset() {
+	*x = CrossDeviceLoginRequester{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CrossDeviceLoginRequester) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CrossDeviceLoginRequester) ProtoMessage() {}
+
+func (x *CrossDeviceLoginRequester) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CrossDeviceLoginRequester.ProtoReflect.Descriptor instead.
+func (*CrossDeviceLoginRequester) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *CrossDeviceLoginRequester) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *CrossDeviceLoginRequester) GetUserAgent() string {
+	if x != nil {
+		return x.UserAgent
+	}
+	return ""
+}
+
+func (x *CrossDeviceLoginRequester) GetLocationHint() string {
+	if x != nil {
+		return x.LocationHint
+	}
+	return ""
+}
+
+func (x *CrossDeviceLoginRequester) GetClientName() string {
+	if x != nil {
+		return x.ClientName
+	}
+	return ""
+}
+
+func (x *CrossDeviceLoginRequester) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *CrossDeviceLoginRequester) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+type GetCrossDeviceLoginRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCrossDeviceLoginRequest) Reset() {
+	*x = GetCrossDeviceLoginRequest{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCrossDeviceLoginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCrossDeviceLoginRequest) ProtoMessage() {}
+
+func (x *GetCrossDeviceLoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCrossDeviceLoginRequest.ProtoReflect.Descriptor instead.
+func (*GetCrossDeviceLoginRequest) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *GetCrossDeviceLoginRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type GetCrossDeviceLoginResponse struct {
+	state         protoimpl.MessageState     `protogen:"open.v1"`
+	Requester     *CrossDeviceLoginRequester `protobuf:"bytes,1,opt,name=requester,proto3" json:"requester,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCrossDeviceLoginResponse) Reset() {
+	*x = GetCrossDeviceLoginResponse{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCrossDeviceLoginResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCrossDeviceLoginResponse) ProtoMessage() {}
+
+func (x *GetCrossDeviceLoginResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCrossDeviceLoginResponse.ProtoReflect.Descriptor instead.
+func (*GetCrossDeviceLoginResponse) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *GetCrossDeviceLoginResponse) GetRequester() *CrossDeviceLoginRequester {
+	if x != nil {
+		return x.Requester
+	}
+	return nil
+}
+
+type ApproveCrossDeviceLoginRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Approve       bool                   `protobuf:"varint,2,opt,name=approve,proto3" json:"approve,omitempty"` // false 表示拒绝
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApproveCrossDeviceLoginRequest) Reset() {
+	*x = ApproveCrossDeviceLoginRequest{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApproveCrossDeviceLoginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApproveCrossDeviceLoginRequest) ProtoMessage() {}
+
+func (x *ApproveCrossDeviceLoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApproveCrossDeviceLoginRequest.ProtoReflect.Descriptor instead.
+func (*ApproveCrossDeviceLoginRequest) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ApproveCrossDeviceLoginRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ApproveCrossDeviceLoginRequest) GetApprove() bool {
+	if x != nil {
+		return x.Approve
+	}
+	return false
+}
+
+type ApproveCrossDeviceLoginResponse struct {
+	state         protoimpl.MessageState     `protogen:"open.v1"`
+	Requester     *CrossDeviceLoginRequester `protobuf:"bytes,1,opt,name=requester,proto3" json:"requester,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApproveCrossDeviceLoginResponse) Reset() {
+	*x = ApproveCrossDeviceLoginResponse{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApproveCrossDeviceLoginResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApproveCrossDeviceLoginResponse) ProtoMessage() {}
+
+func (x *ApproveCrossDeviceLoginResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApproveCrossDeviceLoginResponse.ProtoReflect.Descriptor instead.
+func (*ApproveCrossDeviceLoginResponse) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ApproveCrossDeviceLoginResponse) GetRequester() *CrossDeviceLoginRequester {
+	if x != nil {
+		return x.Requester
+	}
+	return nil
+}
+
+type RequestMagicLinkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestMagicLinkRequest) Reset() {
+	*x = RequestMagicLinkRequest{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestMagicLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestMagicLinkRequest) ProtoMessage() {}
+
+func (x *RequestMagicLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestMagicLinkRequest.ProtoReflect.Descriptor instead.
+func (*RequestMagicLinkRequest) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *RequestMagicLinkRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+type RequestMagicLinkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nonce         string                 `protobuf:"bytes,1,opt,name=nonce,proto3" json:"nonce,omitempty"` // 浏览器保存，兑换链接时一并提交，其他浏览器打开链接无法登录
+	ExpiresAt     int64                  `protobuf:"varint,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestMagicLinkResponse) Reset() {
+	*x = RequestMagicLinkResponse{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestMagicLinkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestMagicLinkResponse) ProtoMessage() {}
+
+func (x *RequestMagicLinkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestMagicLinkResponse.ProtoReflect.Descriptor instead.
+func (*RequestMagicLinkResponse) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *RequestMagicLinkResponse) GetNonce() string {
+	if x != nil {
+		return x.Nonce
+	}
+	return ""
+}
+
+func (x *RequestMagicLinkResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+type RequestRegistrationEmailRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestRegistrationEmailRequest) Reset() {
+	*x = RequestRegistrationEmailRequest{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestRegistrationEmailRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestRegistrationEmailRequest) ProtoMessage() {}
+
+func (x *RequestRegistrationEmailRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestRegistrationEmailRequest.ProtoReflect.Descriptor instead.
+func (*RequestRegistrationEmailRequest) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *RequestRegistrationEmailRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+type RequestRegistrationEmailResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExpiresAt     int64                  `protobuf:"varint,1,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // 邮件中票据的过期时间
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestRegistrationEmailResponse) Reset() {
+	*x = RequestRegistrationEmailResponse{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestRegistrationEmailResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestRegistrationEmailResponse) ProtoMessage() {}
+
+func (x *RequestRegistrationEmailResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestRegistrationEmailResponse.ProtoReflect.Descriptor instead.
+func (*RequestRegistrationEmailResponse) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *RequestRegistrationEmailResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+type ExchangeMagicLinkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"` // 邮件链接中的 token
+	Nonce         string                 `protobuf:"bytes,2,opt,name=nonce,proto3" json:"nonce,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RegisterRequest) Reset() {
-	*x = RegisterRequest{}
-	mi := &file_api_greet_v1_greet_proto_msgTypes[0]
+func (x *ExchangeMagicLinkRequest) Reset() {
+	*x = ExchangeMagicLinkRequest{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RegisterRequest) String() string {
+func (x *ExchangeMagicLinkRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RegisterRequest) ProtoMessage() {}
+func (*ExchangeMagicLinkRequest) ProtoMessage() {}
 
-func (x *RegisterRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_greet_v1_greet_proto_msgTypes[0]
+func (x *ExchangeMagicLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -57,61 +2037,98 @@ func (x *RegisterRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RegisterRequest.ProtoReflect.Descriptor instead.
-func (*RegisterRequest) Descriptor() ([]byte, []int) {
-	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use ExchangeMagicLinkRequest.ProtoReflect.Descriptor instead.
+func (*ExchangeMagicLinkRequest) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *RegisterRequest) GetUsername() string {
+func (x *ExchangeMagicLinkRequest) GetToken() string {
 	if x != nil {
-		return x.Username
+		return x.Token
 	}
 	return ""
 }
 
-func (x *RegisterRequest) GetPasswordHash() string {
+func (x *ExchangeMagicLinkRequest) GetNonce() string {
 	if x != nil {
-		return x.PasswordHash
+		return x.Nonce
 	}
 	return ""
 }
 
-func (x *RegisterRequest) GetEmail() string {
+type IdentityProvider struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DisplayName   string                 `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IdentityProvider) Reset() {
+	*x = IdentityProvider{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IdentityProvider) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IdentityProvider) ProtoMessage() {}
+
+func (x *IdentityProvider) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[34]
 	if x != nil {
-		return x.Email
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IdentityProvider.ProtoReflect.Descriptor instead.
+func (*IdentityProvider) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *IdentityProvider) GetId() string {
+	if x != nil {
+		return x.Id
 	}
 	return ""
 }
 
-func (x *RegisterRequest) GetSalt() string {
+func (x *IdentityProvider) GetDisplayName() string {
 	if x != nil {
-		return x.Salt
+		return x.DisplayName
 	}
 	return ""
 }
 
-type RegisterResponse struct {
+type ListIdentityProvidersRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RegisterResponse) Reset() {
-	*x = RegisterResponse{}
-	mi := &file_api_greet_v1_greet_proto_msgTypes[1]
+func (x *ListIdentityProvidersRequest) Reset() {
+	*x = ListIdentityProvidersRequest{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RegisterResponse) String() string {
+func (x *ListIdentityProvidersRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RegisterResponse) ProtoMessage() {}
+func (*ListIdentityProvidersRequest) ProtoMessage() {}
 
-func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_greet_v1_greet_proto_msgTypes[1]
+func (x *ListIdentityProvidersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -122,40 +2139,77 @@ func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RegisterResponse.ProtoReflect.Descriptor instead.
-func (*RegisterResponse) Descriptor() ([]byte, []int) {
-	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use ListIdentityProvidersRequest.ProtoReflect.Descriptor instead.
+func (*ListIdentityProvidersRequest) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{35}
 }
 
-func (x *RegisterResponse) GetUserId() string {
+type ListIdentityProvidersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Providers     []*IdentityProvider    `protobuf:"bytes,1,rep,name=providers,proto3" json:"providers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListIdentityProvidersResponse) Reset() {
+	*x = ListIdentityProvidersResponse{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListIdentityProvidersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListIdentityProvidersResponse) ProtoMessage() {}
+
+func (x *ListIdentityProvidersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[36]
 	if x != nil {
-		return x.UserId
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-type AuthChallengeRequest struct {
+// Deprecated: Use ListIdentityProvidersResponse.ProtoReflect.Descriptor instead.
+func (*ListIdentityProvidersResponse) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *ListIdentityProvidersResponse) GetProviders() []*IdentityProvider {
+	if x != nil {
+		return x.Providers
+	}
+	return nil
+}
+
+type BeginFederatedLoginRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Provider      string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AuthChallengeRequest) Reset() {
-	*x = AuthChallengeRequest{}
-	mi := &file_api_greet_v1_greet_proto_msgTypes[2]
+func (x *BeginFederatedLoginRequest) Reset() {
+	*x = BeginFederatedLoginRequest{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AuthChallengeRequest) String() string {
+func (x *BeginFederatedLoginRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AuthChallengeRequest) ProtoMessage() {}
+func (*BeginFederatedLoginRequest) ProtoMessage() {}
 
-func (x *AuthChallengeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_greet_v1_greet_proto_msgTypes[2]
+func (x *BeginFederatedLoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -166,41 +2220,42 @@ func (x *AuthChallengeRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AuthChallengeRequest.ProtoReflect.Descriptor instead.
-func (*AuthChallengeRequest) Descriptor() ([]byte, []int) {
-	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use BeginFederatedLoginRequest.ProtoReflect.Descriptor instead.
+func (*BeginFederatedLoginRequest) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{37}
 }
 
-func (x *AuthChallengeRequest) GetUsername() string {
+func (x *BeginFederatedLoginRequest) GetProvider() string {
 	if x != nil {
-		return x.Username
+		return x.Provider
 	}
 	return ""
 }
 
-type AuthChallengeResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Challenge     string                 `protobuf:"bytes,1,opt,name=challenge,proto3" json:"challenge,omitempty"`
-	Salt          string                 `protobuf:"bytes,2,opt,name=salt,proto3" json:"salt,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type BeginFederatedLoginResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	AuthorizationUrl string                 `protobuf:"bytes,1,opt,name=authorization_url,json=authorizationUrl,proto3" json:"authorization_url,omitempty"` // 浏览器跳转到 IdP 的地址
+	Binding          string                 `protobuf:"bytes,2,opt,name=binding,proto3" json:"binding,omitempty"`                                           // 浏览器保存，回调后一并提交，其他浏览器无法用同一个 state 完成登录
+	ExpiresAt        int64                  `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
-func (x *AuthChallengeResponse) Reset() {
-	*x = AuthChallengeResponse{}
-	mi := &file_api_greet_v1_greet_proto_msgTypes[3]
+func (x *BeginFederatedLoginResponse) Reset() {
+	*x = BeginFederatedLoginResponse{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AuthChallengeResponse) String() string {
+func (x *BeginFederatedLoginResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AuthChallengeResponse) ProtoMessage() {}
+func (*BeginFederatedLoginResponse) ProtoMessage() {}
 
-func (x *AuthChallengeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_greet_v1_greet_proto_msgTypes[3]
+func (x *BeginFederatedLoginResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -211,50 +2266,54 @@ func (x *AuthChallengeResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AuthChallengeResponse.ProtoReflect.Descriptor instead.
-func (*AuthChallengeResponse) Descriptor() ([]byte, []int) {
-	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use BeginFederatedLoginResponse.ProtoReflect.Descriptor instead.
+func (*BeginFederatedLoginResponse) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{38}
 }
 
-func (x *AuthChallengeResponse) GetChallenge() string {
+func (x *BeginFederatedLoginResponse) GetAuthorizationUrl() string {
 	if x != nil {
-		return x.Challenge
+		return x.AuthorizationUrl
 	}
 	return ""
 }
 
-func (x *AuthChallengeResponse) GetSalt() string {
+func (x *BeginFederatedLoginResponse) GetBinding() string {
 	if x != nil {
-		return x.Salt
+		return x.Binding
 	}
 	return ""
 }
 
-type SubmitAuthRequest struct {
-	state             protoimpl.MessageState `protogen:"open.v1"`
-	Username          string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
-	HashedCredential  string                 `protobuf:"bytes,2,opt,name=hashed_credential,json=hashedCredential,proto3" json:"hashed_credential,omitempty"`    // 客户端使用密码 + salt 哈希后的凭证
-	AuthRequestId     string                 `protobuf:"bytes,3,opt,name=auth_request_id,json=authRequestId,proto3" json:"auth_request_id,omitempty"`           // 链路追踪
-	ChallengeResponse string                 `protobuf:"bytes,4,opt,name=challenge_response,json=challengeResponse,proto3" json:"challenge_response,omitempty"` // 客户端对挑战的响应
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+func (x *BeginFederatedLoginResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
 }
 
-func (x *SubmitAuthRequest) Reset() {
-	*x = SubmitAuthRequest{}
-	mi := &file_api_greet_v1_greet_proto_msgTypes[4]
+type LinkFederatedIdentityRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Provider      string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LinkFederatedIdentityRequest) Reset() {
+	*x = LinkFederatedIdentityRequest{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SubmitAuthRequest) String() string {
+func (x *LinkFederatedIdentityRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SubmitAuthRequest) ProtoMessage() {}
+func (*LinkFederatedIdentityRequest) ProtoMessage() {}
 
-func (x *SubmitAuthRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_greet_v1_greet_proto_msgTypes[4]
+func (x *LinkFederatedIdentityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -265,63 +2324,100 @@ func (x *SubmitAuthRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SubmitAuthRequest.ProtoReflect.Descriptor instead.
-func (*SubmitAuthRequest) Descriptor() ([]byte, []int) {
-	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use LinkFederatedIdentityRequest.ProtoReflect.Descriptor instead.
+func (*LinkFederatedIdentityRequest) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{39}
 }
 
-func (x *SubmitAuthRequest) GetUsername() string {
+func (x *LinkFederatedIdentityRequest) GetProvider() string {
 	if x != nil {
-		return x.Username
+		return x.Provider
 	}
 	return ""
 }
 
-func (x *SubmitAuthRequest) GetHashedCredential() string {
+type CompleteFederatedLoginRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	State         string                 `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"` // IdP 回调地址中的 state
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`   // IdP 回调地址中的 code
+	Binding       string                 `protobuf:"bytes,3,opt,name=binding,proto3" json:"binding,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteFederatedLoginRequest) Reset() {
+	*x = CompleteFederatedLoginRequest{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteFederatedLoginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteFederatedLoginRequest) ProtoMessage() {}
+
+func (x *CompleteFederatedLoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[40]
 	if x != nil {
-		return x.HashedCredential
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteFederatedLoginRequest.ProtoReflect.Descriptor instead.
+func (*CompleteFederatedLoginRequest) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *CompleteFederatedLoginRequest) GetState() string {
+	if x != nil {
+		return x.State
 	}
 	return ""
 }
 
-func (x *SubmitAuthRequest) GetAuthRequestId() string {
+func (x *CompleteFederatedLoginRequest) GetCode() string {
 	if x != nil {
-		return x.AuthRequestId
+		return x.Code
 	}
 	return ""
 }
 
-func (x *SubmitAuthRequest) GetChallengeResponse() string {
+func (x *CompleteFederatedLoginRequest) GetBinding() string {
 	if x != nil {
-		return x.ChallengeResponse
+		return x.Binding
 	}
 	return ""
 }
 
-type SubmitAuthResponse struct {
+type GetPowChallengeRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
-	State         string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
-	AuthToken     string                 `protobuf:"bytes,3,opt,name=auth_token,json=authToken,proto3" json:"auth_token,omitempty"` // jwt令牌
+	Action        PowAction              `protobuf:"varint,1,opt,name=action,proto3,enum=greet.v1.PowAction" json:"action,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SubmitAuthResponse) Reset() {
-	*x = SubmitAuthResponse{}
-	mi := &file_api_greet_v1_greet_proto_msgTypes[5]
+func (x *GetPowChallengeRequest) Reset() {
+	*x = GetPowChallengeRequest{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SubmitAuthResponse) String() string {
+func (x *GetPowChallengeRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SubmitAuthResponse) ProtoMessage() {}
+func (*GetPowChallengeRequest) ProtoMessage() {}
 
-func (x *SubmitAuthResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_greet_v1_greet_proto_msgTypes[5]
+func (x *GetPowChallengeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -332,64 +2428,302 @@ func (x *SubmitAuthResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SubmitAuthResponse.ProtoReflect.Descriptor instead.
-func (*SubmitAuthResponse) Descriptor() ([]byte, []int) {
-	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use GetPowChallengeRequest.ProtoReflect.Descriptor instead.
+func (*GetPowChallengeRequest) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{41}
 }
 
-func (x *SubmitAuthResponse) GetCode() string {
+func (x *GetPowChallengeRequest) GetAction() PowAction {
 	if x != nil {
-		return x.Code
+		return x.Action
 	}
-	return ""
+	return PowAction_POW_ACTION_UNSPECIFIED
 }
 
-func (x *SubmitAuthResponse) GetState() string {
+type GetPowChallengeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Required      bool                   `protobuf:"varint,1,opt,name=required,proto3" json:"required,omitempty"` // 未开启时为 false，客户端可跳过
+	Challenge     string                 `protobuf:"bytes,2,opt,name=challenge,proto3" json:"challenge,omitempty"`
+	Difficulty    int32                  `protobuf:"varint,3,opt,name=difficulty,proto3" json:"difficulty,omitempty"` // 需要的前导零比特数
+	ExpiresAt     int64                  `protobuf:"varint,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPowChallengeResponse) Reset() {
+	*x = GetPowChallengeResponse{}
+	mi := &file_api_greet_v1_greet_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPowChallengeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPowChallengeResponse) ProtoMessage() {}
+
+func (x *GetPowChallengeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_greet_v1_greet_proto_msgTypes[42]
 	if x != nil {
-		return x.State
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *SubmitAuthResponse) GetAuthToken() string {
+// Deprecated: Use GetPowChallengeResponse.ProtoReflect.Descriptor instead.
+func (*GetPowChallengeResponse) Descriptor() ([]byte, []int) {
+	return file_api_greet_v1_greet_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *GetPowChallengeResponse) GetRequired() bool {
 	if x != nil {
-		return x.AuthToken
+		return x.Required
+	}
+	return false
+}
+
+func (x *GetPowChallengeResponse) GetChallenge() string {
+	if x != nil {
+		return x.Challenge
 	}
 	return ""
 }
 
+func (x *GetPowChallengeResponse) GetDifficulty() int32 {
+	if x != nil {
+		return x.Difficulty
+	}
+	return 0
+}
+
+func (x *GetPowChallengeResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
 var File_api_greet_v1_greet_proto protoreflect.FileDescriptor
 
 const file_api_greet_v1_greet_proto_rawDesc = "" +
 	"\n" +
-	"\x18api/greet/v1/greet.proto\x12\bgreet.v1\"|\n" +
+	"\x18api/greet/v1/greet.proto\x12\bgreet.v1\"A\n" +
+	"\vProofOfWork\x12\x1c\n" +
+	"\tchallenge\x18\x01 \x01(\tR\tchallenge\x12\x14\n" +
+	"\x05nonce\x18\x02 \x01(\tR\x05nonce\"\xb8\x01\n" +
+	"\tKdfParams\x12\x1c\n" +
+	"\talgorithm\x18\x01 \x01(\tR\talgorithm\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x05R\aversion\x12\x1d\n" +
+	"\n" +
+	"memory_kib\x18\x03 \x01(\rR\tmemoryKib\x12\x1e\n" +
+	"\n" +
+	"iterations\x18\x04 \x01(\rR\n" +
+	"iterations\x12 \n" +
+	"\vparallelism\x18\x05 \x01(\rR\vparallelism\x12\x12\n" +
+	"\x04salt\x18\x06 \x01(\tR\x04salt\"i\n" +
+	"\tKdfTicket\x12%\n" +
+	"\x03kdf\x18\x01 \x01(\v2\x13.greet.v1.KdfParamsR\x03kdf\x12\x16\n" +
+	"\x06ticket\x18\x02 \x01(\tR\x06ticket\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\x03R\texpiresAt\"\x1e\n" +
+	"\x1cGetRegistrationParamsRequest\"L\n" +
+	"\x1dGetRegistrationParamsResponse\x12+\n" +
+	"\x06params\x18\x01 \x01(\v2\x13.greet.v1.KdfTicketR\x06params\"\x88\x02\n" +
 	"\x0fRegisterRequest\x12\x1a\n" +
 	"\busername\x18\x01 \x01(\tR\busername\x12#\n" +
 	"\rpassword_hash\x18\x02 \x01(\tR\fpasswordHash\x12\x14\n" +
 	"\x05email\x18\x03 \x01(\tR\x05email\x12\x12\n" +
-	"\x04salt\x18\x04 \x01(\tR\x04salt\"+\n" +
+	"\x04salt\x18\x04 \x01(\tR\x04salt\x12\x1f\n" +
+	"\vinvite_code\x18\x05 \x01(\tR\n" +
+	"inviteCode\x12'\n" +
+	"\x03pow\x18\x06 \x01(\v2\x15.greet.v1.ProofOfWorkR\x03pow\x12\x1d\n" +
+	"\n" +
+	"kdf_ticket\x18\a \x01(\tR\tkdfTicket\x12!\n" +
+	"\femail_ticket\x18\b \x01(\tR\vemailTicket\"+\n" +
 	"\x10RegisterResponse\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\"2\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"[\n" +
 	"\x14AuthChallengeRequest\x12\x1a\n" +
-	"\busername\x18\x01 \x01(\tR\busername\"I\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x12'\n" +
+	"\x03pow\x18\x02 \x01(\v2\x15.greet.v1.ProofOfWorkR\x03pow\"\xe2\x01\n" +
 	"\x15AuthChallengeResponse\x12\x1c\n" +
 	"\tchallenge\x18\x01 \x01(\tR\tchallenge\x12\x12\n" +
-	"\x04salt\x18\x02 \x01(\tR\x04salt\"\xb3\x01\n" +
+	"\x04salt\x18\x02 \x01(\tR\x04salt\x12%\n" +
+	"\x03kdf\x18\x03 \x01(\v2\x13.greet.v1.KdfParamsR\x03kdf\x12-\n" +
+	"\aupgrade\x18\x04 \x01(\v2\x13.greet.v1.KdfTicketR\aupgrade\x12A\n" +
+	"\x0fcredential_type\x18\x05 \x01(\x0e2\x18.greet.v1.CredentialTypeR\x0ecredentialType\"\xc2\x02\n" +
 	"\x11SubmitAuthRequest\x12\x1a\n" +
 	"\busername\x18\x01 \x01(\tR\busername\x12+\n" +
 	"\x11hashed_credential\x18\x02 \x01(\tR\x10hashedCredential\x12&\n" +
 	"\x0fauth_request_id\x18\x03 \x01(\tR\rauthRequestId\x12-\n" +
-	"\x12challenge_response\x18\x04 \x01(\tR\x11challengeResponse\"]\n" +
+	"\x12challenge_response\x18\x04 \x01(\tR\x11challengeResponse\x12-\n" +
+	"\x12upgrade_credential\x18\x05 \x01(\tR\x11upgradeCredential\x12%\n" +
+	"\x0eupgrade_ticket\x18\x06 \x01(\tR\rupgradeTicket\x12\x1b\n" +
+	"\tdevice_id\x18\a \x01(\tR\bdeviceId\x12\x1a\n" +
+	"\bpassword\x18\b \x01(\tR\bpassword\"\x9a\x01\n" +
 	"\x12SubmitAuthResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\tR\x04code\x12\x14\n" +
 	"\x05state\x18\x02 \x01(\tR\x05state\x12\x1d\n" +
 	"\n" +
-	"auth_token\x18\x03 \x01(\tR\tauthToken2\xf5\x01\n" +
-	"\fGreetService\x12C\n" +
+	"auth_token\x18\x03 \x01(\tR\tauthToken\x12\x1c\n" +
+	"\n" +
+	"step_up_id\x18\x04 \x01(\tR\bstepUpId\x12\x1d\n" +
+	"\n" +
+	"token_type\x18\x05 \x01(\tR\ttokenType\"\x15\n" +
+	"\x13RefreshTokenRequest\"s\n" +
+	"\x14RefreshTokenResponse\x12\x1d\n" +
+	"\n" +
+	"auth_token\x18\x01 \x01(\tR\tauthToken\x12\x1d\n" +
+	"\n" +
+	"token_type\x18\x02 \x01(\tR\ttokenType\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\x03R\texpiresAt\"\x0f\n" +
+	"\rLogoutRequest\"\x10\n" +
+	"\x0eLogoutResponse\"G\n" +
+	"\x13VerifyStepUpRequest\x12\x1c\n" +
+	"\n" +
+	"step_up_id\x18\x01 \x01(\tR\bstepUpId\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\";\n" +
+	"\x18CreateAuthRequestRequest\x12\x1f\n" +
+	"\vclient_name\x18\x01 \x01(\tR\n" +
+	"clientName\"\x83\x01\n" +
+	"\x19CreateAuthRequestResponse\x12&\n" +
+	"\x0fauth_request_id\x18\x01 \x01(\tR\rauthRequestId\x12\x1f\n" +
+	"\vwatch_token\x18\x02 \x01(\tR\n" +
+	"watchToken\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\x03R\texpiresAt\"b\n" +
+	"\x17WatchAuthRequestRequest\x12&\n" +
+	"\x0fauth_request_id\x18\x01 \x01(\tR\rauthRequestId\x12\x1f\n" +
+	"\vwatch_token\x18\x02 \x01(\tR\n" +
+	"watchToken\"\x8a\x01\n" +
+	"\x18WatchAuthRequestResponse\x120\n" +
+	"\x05state\x18\x01 \x01(\x0e2\x1a.greet.v1.AuthRequestStateR\x05state\x12\x1d\n" +
+	"\n" +
+	"auth_token\x18\x02 \x01(\tR\tauthToken\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\x03R\texpiresAt\"a\n" +
+	"\x16DenyAuthRequestRequest\x12&\n" +
+	"\x0fauth_request_id\x18\x01 \x01(\tR\rauthRequestId\x12\x1f\n" +
+	"\vwatch_token\x18\x02 \x01(\tR\n" +
+	"watchToken\"\x19\n" +
+	"\x17DenyAuthRequestResponse\"@\n" +
+	"\x1dCreateCrossDeviceLoginRequest\x12\x1f\n" +
+	"\vclient_name\x18\x01 \x01(\tR\n" +
+	"clientName\"\xbd\x01\n" +
+	"\x1eCreateCrossDeviceLoginResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x1f\n" +
+	"\vapprove_url\x18\x02 \x01(\tR\n" +
+	"approveUrl\x12&\n" +
+	"\x0fauth_request_id\x18\x03 \x01(\tR\rauthRequestId\x12\x1f\n" +
+	"\vwatch_token\x18\x04 \x01(\tR\n" +
+	"watchToken\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\x03R\texpiresAt\"\xce\x01\n" +
+	"\x19CrossDeviceLoginRequester\x12\x0e\n" +
+	"\x02ip\x18\x01 \x01(\tR\x02ip\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\x02 \x01(\tR\tuserAgent\x12#\n" +
+	"\rlocation_hint\x18\x03 \x01(\tR\flocationHint\x12\x1f\n" +
+	"\vclient_name\x18\x04 \x01(\tR\n" +
+	"clientName\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x06 \x01(\x03R\texpiresAt\"0\n" +
+	"\x1aGetCrossDeviceLoginRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\"`\n" +
+	"\x1bGetCrossDeviceLoginResponse\x12A\n" +
+	"\trequester\x18\x01 \x01(\v2#.greet.v1.CrossDeviceLoginRequesterR\trequester\"N\n" +
+	"\x1eApproveCrossDeviceLoginRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
+	"\aapprove\x18\x02 \x01(\bR\aapprove\"d\n" +
+	"\x1fApproveCrossDeviceLoginResponse\x12A\n" +
+	"\trequester\x18\x01 \x01(\v2#.greet.v1.CrossDeviceLoginRequesterR\trequester\"/\n" +
+	"\x17RequestMagicLinkRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\"O\n" +
+	"\x18RequestMagicLinkResponse\x12\x14\n" +
+	"\x05nonce\x18\x01 \x01(\tR\x05nonce\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\x03R\texpiresAt\"7\n" +
+	"\x1fRequestRegistrationEmailRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\"A\n" +
+	" RequestRegistrationEmailResponse\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x01 \x01(\x03R\texpiresAt\"F\n" +
+	"\x18ExchangeMagicLinkRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x14\n" +
+	"\x05nonce\x18\x02 \x01(\tR\x05nonce\"E\n" +
+	"\x10IdentityProvider\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
+	"\fdisplay_name\x18\x02 \x01(\tR\vdisplayName\"\x1e\n" +
+	"\x1cListIdentityProvidersRequest\"Y\n" +
+	"\x1dListIdentityProvidersResponse\x128\n" +
+	"\tproviders\x18\x01 \x03(\v2\x1a.greet.v1.IdentityProviderR\tproviders\"8\n" +
+	"\x1aBeginFederatedLoginRequest\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\"\x83\x01\n" +
+	"\x1bBeginFederatedLoginResponse\x12+\n" +
+	"\x11authorization_url\x18\x01 \x01(\tR\x10authorizationUrl\x12\x18\n" +
+	"\abinding\x18\x02 \x01(\tR\abinding\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\x03R\texpiresAt\":\n" +
+	"\x1cLinkFederatedIdentityRequest\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\"c\n" +
+	"\x1dCompleteFederatedLoginRequest\x12\x14\n" +
+	"\x05state\x18\x01 \x01(\tR\x05state\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12\x18\n" +
+	"\abinding\x18\x03 \x01(\tR\abinding\"E\n" +
+	"\x16GetPowChallengeRequest\x12+\n" +
+	"\x06action\x18\x01 \x01(\x0e2\x13.greet.v1.PowActionR\x06action\"\x92\x01\n" +
+	"\x17GetPowChallengeResponse\x12\x1a\n" +
+	"\brequired\x18\x01 \x01(\bR\brequired\x12\x1c\n" +
+	"\tchallenge\x18\x02 \x01(\tR\tchallenge\x12\x1e\n" +
+	"\n" +
+	"difficulty\x18\x03 \x01(\x05R\n" +
+	"difficulty\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\x03R\texpiresAt*\xb6\x01\n" +
+	"\x10AuthRequestState\x12\"\n" +
+	"\x1eAUTH_REQUEST_STATE_UNSPECIFIED\x10\x00\x12\x1e\n" +
+	"\x1aAUTH_REQUEST_STATE_PENDING\x10\x01\x12\x1f\n" +
+	"\x1bAUTH_REQUEST_STATE_APPROVED\x10\x02\x12\x1d\n" +
+	"\x19AUTH_REQUEST_STATE_DENIED\x10\x03\x12\x1e\n" +
+	"\x1aAUTH_REQUEST_STATE_EXPIRED\x10\x04*l\n" +
+	"\x0eCredentialType\x12\x1f\n" +
+	"\x1bCREDENTIAL_TYPE_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17CREDENTIAL_TYPE_DERIVED\x10\x01\x12\x1c\n" +
+	"\x18CREDENTIAL_TYPE_PASSWORD\x10\x02*_\n" +
+	"\tPowAction\x12\x1a\n" +
+	"\x16POW_ACTION_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13POW_ACTION_REGISTER\x10\x01\x12\x1d\n" +
+	"\x19POW_ACTION_AUTH_CHALLENGE\x10\x022\xc4\x0f\n" +
+	"\fGreetService\x12X\n" +
+	"\x0fGetPowChallenge\x12 .greet.v1.GetPowChallengeRequest\x1a!.greet.v1.GetPowChallengeResponse\"\x00\x12j\n" +
+	"\x15GetRegistrationParams\x12&.greet.v1.GetRegistrationParamsRequest\x1a'.greet.v1.GetRegistrationParamsResponse\"\x00\x12C\n" +
 	"\bRegister\x12\x19.greet.v1.RegisterRequest\x1a\x1a.greet.v1.RegisterResponse\"\x00\x12U\n" +
 	"\x10GetAuthChallenge\x12\x1e.greet.v1.AuthChallengeRequest\x1a\x1f.greet.v1.AuthChallengeResponse\"\x00\x12I\n" +
 	"\n" +
-	"SubmitAuth\x12\x1b.greet.v1.SubmitAuthRequest\x1a\x1c.greet.v1.SubmitAuthResponse\"\x00B\x84\x01\n" +
+	"SubmitAuth\x12\x1b.greet.v1.SubmitAuthRequest\x1a\x1c.greet.v1.SubmitAuthResponse\"\x00\x12^\n" +
+	"\x11CreateAuthRequest\x12\".greet.v1.CreateAuthRequestRequest\x1a#.greet.v1.CreateAuthRequestResponse\"\x00\x12]\n" +
+	"\x10WatchAuthRequest\x12!.greet.v1.WatchAuthRequestRequest\x1a\".greet.v1.WatchAuthRequestResponse\"\x000\x01\x12X\n" +
+	"\x0fDenyAuthRequest\x12 .greet.v1.DenyAuthRequestRequest\x1a!.greet.v1.DenyAuthRequestResponse\"\x00\x12m\n" +
+	"\x16CreateCrossDeviceLogin\x12'.greet.v1.CreateCrossDeviceLoginRequest\x1a(.greet.v1.CreateCrossDeviceLoginResponse\"\x00\x12d\n" +
+	"\x13GetCrossDeviceLogin\x12$.greet.v1.GetCrossDeviceLoginRequest\x1a%.greet.v1.GetCrossDeviceLoginResponse\"\x00\x12p\n" +
+	"\x17ApproveCrossDeviceLogin\x12(.greet.v1.ApproveCrossDeviceLoginRequest\x1a).greet.v1.ApproveCrossDeviceLoginResponse\"\x00\x12[\n" +
+	"\x10RequestMagicLink\x12!.greet.v1.RequestMagicLinkRequest\x1a\".greet.v1.RequestMagicLinkResponse\"\x00\x12W\n" +
+	"\x11ExchangeMagicLink\x12\".greet.v1.ExchangeMagicLinkRequest\x1a\x1c.greet.v1.SubmitAuthResponse\"\x00\x12s\n" +
+	"\x18RequestRegistrationEmail\x12).greet.v1.RequestRegistrationEmailRequest\x1a*.greet.v1.RequestRegistrationEmailResponse\"\x00\x12M\n" +
+	"\fVerifyStepUp\x12\x1d.greet.v1.VerifyStepUpRequest\x1a\x1c.greet.v1.SubmitAuthResponse\"\x00\x12=\n" +
+	"\x06Logout\x12\x17.greet.v1.LogoutRequest\x1a\x18.greet.v1.LogoutResponse\"\x00\x12O\n" +
+	"\fRefreshToken\x12\x1d.greet.v1.RefreshTokenRequest\x1a\x1e.greet.v1.RefreshTokenResponse\"\x00\x12j\n" +
+	"\x15ListIdentityProviders\x12&.greet.v1.ListIdentityProvidersRequest\x1a'.greet.v1.ListIdentityProvidersResponse\"\x00\x12d\n" +
+	"\x13BeginFederatedLogin\x12$.greet.v1.BeginFederatedLoginRequest\x1a%.greet.v1.BeginFederatedLoginResponse\"\x00\x12a\n" +
+	"\x16CompleteFederatedLogin\x12'.greet.v1.CompleteFederatedLoginRequest\x1a\x1c.greet.v1.SubmitAuthResponse\"\x00\x12h\n" +
+	"\x15LinkFederatedIdentity\x12&.greet.v1.LinkFederatedIdentityRequest\x1a%.greet.v1.BeginFederatedLoginResponse\"\x00B\x84\x01\n" +
 	"\fcom.greet.v1B\n" +
 	"GreetProtoP\x01Z'connect-go-example/api/greet/v1;greetv1\xa2\x02\x03GXX\xaa\x02\bGreet.V1\xca\x02\bGreet\\V1\xe2\x02\x14Greet\\V1\\GPBMetadata\xea\x02\tGreet::V1b\x06proto3"
 
@@ -406,29 +2740,118 @@ func file_api_greet_v1_greet_proto_rawDescGZIP() []byte {
 }
 
 var (
-	file_api_greet_v1_greet_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
-	file_api_greet_v1_greet_proto_goTypes  = []any{
-		(*RegisterRequest)(nil),       // 0: greet.v1.RegisterRequest
-		(*RegisterResponse)(nil),      // 1: greet.v1.RegisterResponse
-		(*AuthChallengeRequest)(nil),  // 2: greet.v1.AuthChallengeRequest
-		(*AuthChallengeResponse)(nil), // 3: greet.v1.AuthChallengeResponse
-		(*SubmitAuthRequest)(nil),     // 4: greet.v1.SubmitAuthRequest
-		(*SubmitAuthResponse)(nil),    // 5: greet.v1.SubmitAuthResponse
+	file_api_greet_v1_greet_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+	file_api_greet_v1_greet_proto_msgTypes  = make([]protoimpl.MessageInfo, 43)
+	file_api_greet_v1_greet_proto_goTypes   = []any{
+		AuthRequestState(0),                      // 0: greet.v1.AuthRequestState
+		CredentialType(0),                        // 1: greet.v1.CredentialType
+		PowAction(0),                             // 2: greet.v1.PowAction
+		(*ProofOfWork)(nil),                      // 3: greet.v1.ProofOfWork
+		(*KdfParams)(nil),                        // 4: greet.v1.KdfParams
+		(*KdfTicket)(nil),                        // 5: greet.v1.KdfTicket
+		(*GetRegistrationParamsRequest)(nil),     // 6: greet.v1.GetRegistrationParamsRequest
+		(*GetRegistrationParamsResponse)(nil),    // 7: greet.v1.GetRegistrationParamsResponse
+		(*RegisterRequest)(nil),                  // 8: greet.v1.RegisterRequest
+		(*RegisterResponse)(nil),                 // 9: greet.v1.RegisterResponse
+		(*AuthChallengeRequest)(nil),             // 10: greet.v1.AuthChallengeRequest
+		(*AuthChallengeResponse)(nil),            // 11: greet.v1.AuthChallengeResponse
+		(*SubmitAuthRequest)(nil),                // 12: greet.v1.SubmitAuthRequest
+		(*SubmitAuthResponse)(nil),               // 13: greet.v1.SubmitAuthResponse
+		(*RefreshTokenRequest)(nil),              // 14: greet.v1.RefreshTokenRequest
+		(*RefreshTokenResponse)(nil),             // 15: greet.v1.RefreshTokenResponse
+		(*LogoutRequest)(nil),                    // 16: greet.v1.LogoutRequest
+		(*LogoutResponse)(nil),                   // 17: greet.v1.LogoutResponse
+		(*VerifyStepUpRequest)(nil),              // 18: greet.v1.VerifyStepUpRequest
+		(*CreateAuthRequestRequest)(nil),         // 19: greet.v1.CreateAuthRequestRequest
+		(*CreateAuthRequestResponse)(nil),        // 20: greet.v1.CreateAuthRequestResponse
+		(*WatchAuthRequestRequest)(nil),          // 21: greet.v1.WatchAuthRequestRequest
+		(*WatchAuthRequestResponse)(nil),         // 22: greet.v1.WatchAuthRequestResponse
+		(*DenyAuthRequestRequest)(nil),           // 23: greet.v1.DenyAuthRequestRequest
+		(*DenyAuthRequestResponse)(nil),          // 24: greet.v1.DenyAuthRequestResponse
+		(*CreateCrossDeviceLoginRequest)(nil),    // 25: greet.v1.CreateCrossDeviceLoginRequest
+		(*CreateCrossDeviceLoginResponse)(nil),   // 26: greet.v1.CreateCrossDeviceLoginResponse
+		(*CrossDeviceLoginRequester)(nil),        // 27: greet.v1.CrossDeviceLoginRequester
+		(*GetCrossDeviceLoginRequest)(nil),       // 28: greet.v1.GetCrossDeviceLoginRequest
+		(*GetCrossDeviceLoginResponse)(nil),      // 29: greet.v1.GetCrossDeviceLoginResponse
+		(*ApproveCrossDeviceLoginRequest)(nil),   // 30: greet.v1.ApproveCrossDeviceLoginRequest
+		(*ApproveCrossDeviceLoginResponse)(nil),  // 31: greet.v1.ApproveCrossDeviceLoginResponse
+		(*RequestMagicLinkRequest)(nil),          // 32: greet.v1.RequestMagicLinkRequest
+		(*RequestMagicLinkResponse)(nil),         // 33: greet.v1.RequestMagicLinkResponse
+		(*RequestRegistrationEmailRequest)(nil),  // 34: greet.v1.RequestRegistrationEmailRequest
+		(*RequestRegistrationEmailResponse)(nil), // 35: greet.v1.RequestRegistrationEmailResponse
+		(*ExchangeMagicLinkRequest)(nil),         // 36: greet.v1.ExchangeMagicLinkRequest
+		(*IdentityProvider)(nil),                 // 37: greet.v1.IdentityProvider
+		(*ListIdentityProvidersRequest)(nil),     // 38: greet.v1.ListIdentityProvidersRequest
+		(*ListIdentityProvidersResponse)(nil),    // 39: greet.v1.ListIdentityProvidersResponse
+		(*BeginFederatedLoginRequest)(nil),       // 40: greet.v1.BeginFederatedLoginRequest
+		(*BeginFederatedLoginResponse)(nil),      // 41: greet.v1.BeginFederatedLoginResponse
+		(*LinkFederatedIdentityRequest)(nil),     // 42: greet.v1.LinkFederatedIdentityRequest
+		(*CompleteFederatedLoginRequest)(nil),    // 43: greet.v1.CompleteFederatedLoginRequest
+		(*GetPowChallengeRequest)(nil),           // 44: greet.v1.GetPowChallengeRequest
+		(*GetPowChallengeResponse)(nil),          // 45: greet.v1.GetPowChallengeResponse
 	}
 )
 
 var file_api_greet_v1_greet_proto_depIdxs = []int32{
-	0, // 0: greet.v1.GreetService.Register:input_type -> greet.v1.RegisterRequest
-	2, // 1: greet.v1.GreetService.GetAuthChallenge:input_type -> greet.v1.AuthChallengeRequest
-	4, // 2: greet.v1.GreetService.SubmitAuth:input_type -> greet.v1.SubmitAuthRequest
-	1, // 3: greet.v1.GreetService.Register:output_type -> greet.v1.RegisterResponse
-	3, // 4: greet.v1.GreetService.GetAuthChallenge:output_type -> greet.v1.AuthChallengeResponse
-	5, // 5: greet.v1.GreetService.SubmitAuth:output_type -> greet.v1.SubmitAuthResponse
-	3, // [3:6] is the sub-list for method output_type
-	0, // [0:3] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	4,  // 0: greet.v1.KdfTicket.kdf:type_name -> greet.v1.KdfParams
+	5,  // 1: greet.v1.GetRegistrationParamsResponse.params:type_name -> greet.v1.KdfTicket
+	3,  // 2: greet.v1.RegisterRequest.pow:type_name -> greet.v1.ProofOfWork
+	3,  // 3: greet.v1.AuthChallengeRequest.pow:type_name -> greet.v1.ProofOfWork
+	4,  // 4: greet.v1.AuthChallengeResponse.kdf:type_name -> greet.v1.KdfParams
+	5,  // 5: greet.v1.AuthChallengeResponse.upgrade:type_name -> greet.v1.KdfTicket
+	1,  // 6: greet.v1.AuthChallengeResponse.credential_type:type_name -> greet.v1.CredentialType
+	0,  // 7: greet.v1.WatchAuthRequestResponse.state:type_name -> greet.v1.AuthRequestState
+	27, // 8: greet.v1.GetCrossDeviceLoginResponse.requester:type_name -> greet.v1.CrossDeviceLoginRequester
+	27, // 9: greet.v1.ApproveCrossDeviceLoginResponse.requester:type_name -> greet.v1.CrossDeviceLoginRequester
+	37, // 10: greet.v1.ListIdentityProvidersResponse.providers:type_name -> greet.v1.IdentityProvider
+	2,  // 11: greet.v1.GetPowChallengeRequest.action:type_name -> greet.v1.PowAction
+	44, // 12: greet.v1.GreetService.GetPowChallenge:input_type -> greet.v1.GetPowChallengeRequest
+	6,  // 13: greet.v1.GreetService.GetRegistrationParams:input_type -> greet.v1.GetRegistrationParamsRequest
+	8,  // 14: greet.v1.GreetService.Register:input_type -> greet.v1.RegisterRequest
+	10, // 15: greet.v1.GreetService.GetAuthChallenge:input_type -> greet.v1.AuthChallengeRequest
+	12, // 16: greet.v1.GreetService.SubmitAuth:input_type -> greet.v1.SubmitAuthRequest
+	19, // 17: greet.v1.GreetService.CreateAuthRequest:input_type -> greet.v1.CreateAuthRequestRequest
+	21, // 18: greet.v1.GreetService.WatchAuthRequest:input_type -> greet.v1.WatchAuthRequestRequest
+	23, // 19: greet.v1.GreetService.DenyAuthRequest:input_type -> greet.v1.DenyAuthRequestRequest
+	25, // 20: greet.v1.GreetService.CreateCrossDeviceLogin:input_type -> greet.v1.CreateCrossDeviceLoginRequest
+	28, // 21: greet.v1.GreetService.GetCrossDeviceLogin:input_type -> greet.v1.GetCrossDeviceLoginRequest
+	30, // 22: greet.v1.GreetService.ApproveCrossDeviceLogin:input_type -> greet.v1.ApproveCrossDeviceLoginRequest
+	32, // 23: greet.v1.GreetService.RequestMagicLink:input_type -> greet.v1.RequestMagicLinkRequest
+	36, // 24: greet.v1.GreetService.ExchangeMagicLink:input_type -> greet.v1.ExchangeMagicLinkRequest
+	34, // 25: greet.v1.GreetService.RequestRegistrationEmail:input_type -> greet.v1.RequestRegistrationEmailRequest
+	18, // 26: greet.v1.GreetService.VerifyStepUp:input_type -> greet.v1.VerifyStepUpRequest
+	16, // 27: greet.v1.GreetService.Logout:input_type -> greet.v1.LogoutRequest
+	14, // 28: greet.v1.GreetService.RefreshToken:input_type -> greet.v1.RefreshTokenRequest
+	38, // 29: greet.v1.GreetService.ListIdentityProviders:input_type -> greet.v1.ListIdentityProvidersRequest
+	40, // 30: greet.v1.GreetService.BeginFederatedLogin:input_type -> greet.v1.BeginFederatedLoginRequest
+	43, // 31: greet.v1.GreetService.CompleteFederatedLogin:input_type -> greet.v1.CompleteFederatedLoginRequest
+	42, // 32: greet.v1.GreetService.LinkFederatedIdentity:input_type -> greet.v1.LinkFederatedIdentityRequest
+	45, // 33: greet.v1.GreetService.GetPowChallenge:output_type -> greet.v1.GetPowChallengeResponse
+	7,  // 34: greet.v1.GreetService.GetRegistrationParams:output_type -> greet.v1.GetRegistrationParamsResponse
+	9,  // 35: greet.v1.GreetService.Register:output_type -> greet.v1.RegisterResponse
+	11, // 36: greet.v1.GreetService.GetAuthChallenge:output_type -> greet.v1.AuthChallengeResponse
+	13, // 37: greet.v1.GreetService.SubmitAuth:output_type -> greet.v1.SubmitAuthResponse
+	20, // 38: greet.v1.GreetService.CreateAuthRequest:output_type -> greet.v1.CreateAuthRequestResponse
+	22, // 39: greet.v1.GreetService.WatchAuthRequest:output_type -> greet.v1.WatchAuthRequestResponse
+	24, // 40: greet.v1.GreetService.DenyAuthRequest:output_type -> greet.v1.DenyAuthRequestResponse
+	26, // 41: greet.v1.GreetService.CreateCrossDeviceLogin:output_type -> greet.v1.CreateCrossDeviceLoginResponse
+	29, // 42: greet.v1.GreetService.GetCrossDeviceLogin:output_type -> greet.v1.GetCrossDeviceLoginResponse
+	31, // 43: greet.v1.GreetService.ApproveCrossDeviceLogin:output_type -> greet.v1.ApproveCrossDeviceLoginResponse
+	33, // 44: greet.v1.GreetService.RequestMagicLink:output_type -> greet.v1.RequestMagicLinkResponse
+	13, // 45: greet.v1.GreetService.ExchangeMagicLink:output_type -> greet.v1.SubmitAuthResponse
+	35, // 46: greet.v1.GreetService.RequestRegistrationEmail:output_type -> greet.v1.RequestRegistrationEmailResponse
+	13, // 47: greet.v1.GreetService.VerifyStepUp:output_type -> greet.v1.SubmitAuthResponse
+	17, // 48: greet.v1.GreetService.Logout:output_type -> greet.v1.LogoutResponse
+	15, // 49: greet.v1.GreetService.RefreshToken:output_type -> greet.v1.RefreshTokenResponse
+	39, // 50: greet.v1.GreetService.ListIdentityProviders:output_type -> greet.v1.ListIdentityProvidersResponse
+	41, // 51: greet.v1.GreetService.BeginFederatedLogin:output_type -> greet.v1.BeginFederatedLoginResponse
+	13, // 52: greet.v1.GreetService.CompleteFederatedLogin:output_type -> greet.v1.SubmitAuthResponse
+	41, // 53: greet.v1.GreetService.LinkFederatedIdentity:output_type -> greet.v1.BeginFederatedLoginResponse
+	33, // [33:54] is the sub-list for method output_type
+	12, // [12:33] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
 }
 
 func init() { file_api_greet_v1_greet_proto_init() }
@@ -441,13 +2864,14 @@ func file_api_greet_v1_greet_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_greet_v1_greet_proto_rawDesc), len(file_api_greet_v1_greet_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   6,
+			NumEnums:      3,
+			NumMessages:   43,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_api_greet_v1_greet_proto_goTypes,
 		DependencyIndexes: file_api_greet_v1_greet_proto_depIdxs,
+		EnumInfos:         file_api_greet_v1_greet_proto_enumTypes,
 		MessageInfos:      file_api_greet_v1_greet_proto_msgTypes,
 	}.Build()
 	File_api_greet_v1_greet_proto = out.File
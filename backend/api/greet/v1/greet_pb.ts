@@ -2,15 +2,148 @@
 // @generated from file api/greet/v1/greet.proto (package greet.v1, syntax proto3)
 /* eslint-disable */
 
-import type { GenFile, GenMessage, GenService } from "@bufbuild/protobuf/codegenv2";
-import { fileDesc, messageDesc, serviceDesc } from "@bufbuild/protobuf/codegenv2";
+import type { GenEnum, GenFile, GenMessage, GenService } from "@bufbuild/protobuf/codegenv2";
+import { enumDesc, fileDesc, messageDesc, serviceDesc } from "@bufbuild/protobuf/codegenv2";
 import type { Message } from "@bufbuild/protobuf";
 
 /**
  * Describes the file api/greet/v1/greet.proto.
  */
 export const file_api_greet_v1_greet: GenFile = /*@__PURE__*/
-  fileDesc("ChhhcGkvZ3JlZXQvdjEvZ3JlZXQucHJvdG8SCGdyZWV0LnYxIlcKD1JlZ2lzdGVyUmVxdWVzdBIQCgh1c2VybmFtZRgBIAEoCRIVCg1wYXNzd29yZF9oYXNoGAIgASgJEg0KBWVtYWlsGAMgASgJEgwKBHNhbHQYBCABKAkiIwoQUmVnaXN0ZXJSZXNwb25zZRIPCgd1c2VyX2lkGAEgASgJIigKFEF1dGhDaGFsbGVuZ2VSZXF1ZXN0EhAKCHVzZXJuYW1lGAEgASgJIjgKFUF1dGhDaGFsbGVuZ2VSZXNwb25zZRIRCgljaGFsbGVuZ2UYASABKAkSDAoEc2FsdBgCIAEoCSJ1ChFTdWJtaXRBdXRoUmVxdWVzdBIQCgh1c2VybmFtZRgBIAEoCRIZChFoYXNoZWRfY3JlZGVudGlhbBgCIAEoCRIXCg9hdXRoX3JlcXVlc3RfaWQYAyABKAkSGgoSY2hhbGxlbmdlX3Jlc3BvbnNlGAQgASgJIkUKElN1Ym1pdEF1dGhSZXNwb25zZRIMCgRjb2RlGAEgASgJEg0KBXN0YXRlGAIgASgJEhIKCmF1dGhfdG9rZW4YAyABKAky9QEKDEdyZWV0U2VydmljZRJDCghSZWdpc3RlchIZLmdyZWV0LnYxLlJlZ2lzdGVyUmVxdWVzdBoaLmdyZWV0LnYxLlJlZ2lzdGVyUmVzcG9uc2UiABJVChBHZXRBdXRoQ2hhbGxlbmdlEh4uZ3JlZXQudjEuQXV0aENoYWxsZW5nZVJlcXVlc3QaHy5ncmVldC52MS5BdXRoQ2hhbGxlbmdlUmVzcG9uc2UiABJJCgpTdWJtaXRBdXRoEhsuZ3JlZXQudjEuU3VibWl0QXV0aFJlcXVlc3QaHC5ncmVldC52MS5TdWJtaXRBdXRoUmVzcG9uc2UiAEKEAQoMY29tLmdyZWV0LnYxQgpHcmVldFByb3RvUAFaJ2Nvbm5lY3QtZ28tZXhhbXBsZS9hcGkvZ3JlZXQvdjE7Z3JlZXR2MaICA0dYWKoCCEdyZWV0LlYxygIIR3JlZXRcVjHiAhRHcmVldFxWMVxHUEJNZXRhZGF0YeoCCUdyZWV0OjpWMWIGcHJvdG8z");
+  fileDesc("ChhhcGkvZ3JlZXQvdjEvZ3JlZXQucHJvdG8SCGdyZWV0LnYxIi8KC1Byb29mT2ZXb3JrEhEKCWNoYWxsZW5nZRgBIAEoCRINCgVub25jZRgCIAEoCSJ6CglLZGZQYXJhbXMSEQoJYWxnb3JpdGhtGAEgASgJEg8KB3ZlcnNpb24YAiABKAUSEgoKbWVtb3J5X2tpYhgDIAEoDRISCgppdGVyYXRpb25zGAQgASgNEhMKC3BhcmFsbGVsaXNtGAUgASgNEgwKBHNhbHQYBiABKAkiUQoJS2RmVGlja2V0EiAKA2tkZhgBIAEoCzITLmdyZWV0LnYxLktkZlBhcmFtcxIOCgZ0aWNrZXQYAiABKAkSEgoKZXhwaXJlc19hdBgDIAEoAyIeChxHZXRSZWdpc3RyYXRpb25QYXJhbXNSZXF1ZXN0IkQKHUdldFJlZ2lzdHJhdGlvblBhcmFtc1Jlc3BvbnNlEiMKBnBhcmFtcxgBIAEoCzITLmdyZWV0LnYxLktkZlRpY2tldCK6AQoPUmVnaXN0ZXJSZXF1ZXN0EhAKCHVzZXJuYW1lGAEgASgJEhUKDXBhc3N3b3JkX2hhc2gYAiABKAkSDQoFZW1haWwYAyABKAkSDAoEc2FsdBgEIAEoCRITCgtpbnZpdGVfY29kZRgFIAEoCRIiCgNwb3cYBiABKAsyFS5ncmVldC52MS5Qcm9vZk9mV29yaxISCgprZGZfdGlja2V0GAcgASgJEhQKDGVtYWlsX3RpY2tldBgIIAEoCSIjChBSZWdpc3RlclJlc3BvbnNlEg8KB3VzZXJfaWQYASABKAkiTAoUQXV0aENoYWxsZW5nZVJlcXVlc3QSEAoIdXNlcm5hbWUYASABKAkSIgoDcG93GAIgASgLMhUuZ3JlZXQudjEuUHJvb2ZPZldvcmsiswEKFUF1dGhDaGFsbGVuZ2VSZXNwb25zZRIRCgljaGFsbGVuZ2UYASABKAkSDAoEc2FsdBgCIAEoCRIgCgNrZGYYAyABKAsyEy5ncmVldC52MS5LZGZQYXJhbXMSJAoHdXBncmFkZRgEIAEoCzITLmdyZWV0LnYxLktkZlRpY2tldBIxCg9jcmVkZW50aWFsX3R5cGUYBSABKA4yGC5ncmVldC52MS5DcmVkZW50aWFsVHlwZSLOAQoRU3VibWl0QXV0aFJlcXVlc3QSEAoIdXNlcm5hbWUYASABKAkSGQoRaGFzaGVkX2NyZWRlbnRpYWwYAiABKAkSFwoPYXV0aF9yZXF1ZXN0X2lkGAMgASgJEhoKEmNoYWxsZW5nZV9yZXNwb25zZRgEIAEoCRIaChJ1cGdyYWRlX2NyZWRlbnRpYWwYBSABKAkSFgoOdXBncmFkZV90aWNrZXQYBiABKAkSEQoJZGV2aWNlX2lkGAcgASgJEhAKCHBhc3N3b3JkGAggASgJIm0KElN1Ym1pdEF1dGhSZXNwb25zZRIMCgRjb2RlGAEgASgJEg0KBXN0YXRlGAIgASgJEhIKCmF1dGhfdG9rZW4YAyABKAkSEgoKc3RlcF91cF9pZBgEIAEoCRISCgp0b2tlbl90eXBlGAUgASgJIhUKE1JlZnJlc2hUb2tlblJlcXVlc3QiUgoUUmVmcmVzaFRva2VuUmVzcG9uc2USEgoKYXV0aF90b2tlbhgBIAEoCRISCgp0b2tlbl90eXBlGAIgASgJEhIKCmV4cGlyZXNfYXQYAyABKAMiDwoNTG9nb3V0UmVxdWVzdCIQCg5Mb2dvdXRSZXNwb25zZSI3ChNWZXJpZnlTdGVwVXBSZXF1ZXN0EhIKCnN0ZXBfdXBfaWQYASABKAkSDAoEY29kZRgCIAEoCSIvChhDcmVhdGVBdXRoUmVxdWVzdFJlcXVlc3QSEwoLY2xpZW50X25hbWUYASABKAkiXQoZQ3JlYXRlQXV0aFJlcXVlc3RSZXNwb25zZRIXCg9hdXRoX3JlcXVlc3RfaWQYASABKAkSEwoLd2F0Y2hfdG9rZW4YAiABKAkSEgoKZXhwaXJlc19hdBgDIAEoAyJHChdXYXRjaEF1dGhSZXF1ZXN0UmVxdWVzdBIXCg9hdXRoX3JlcXVlc3RfaWQYASABKAkSEwoLd2F0Y2hfdG9rZW4YAiABKAkibQoYV2F0Y2hBdXRoUmVxdWVzdFJlc3BvbnNlEikKBXN0YXRlGAEgASgOMhouZ3JlZXQudjEuQXV0aFJlcXVlc3RTdGF0ZRISCgphdXRoX3Rva2VuGAIgASgJEhIKCmV4cGlyZXNfYXQYAyABKAMiRgoWRGVueUF1dGhSZXF1ZXN0UmVxdWVzdBIXCg9hdXRoX3JlcXVlc3RfaWQYASABKAkSEwoLd2F0Y2hfdG9rZW4YAiABKAkiGQoXRGVueUF1dGhSZXF1ZXN0UmVzcG9uc2UiNAodQ3JlYXRlQ3Jvc3NEZXZpY2VMb2dpblJlcXVlc3QSEwoLY2xpZW50X25hbWUYASABKAkihQEKHkNyZWF0ZUNyb3NzRGV2aWNlTG9naW5SZXNwb25zZRIMCgRjb2RlGAEgASgJEhMKC2FwcHJvdmVfdXJsGAIgASgJEhcKD2F1dGhfcmVxdWVzdF9pZBgDIAEoCRITCgt3YXRjaF90b2tlbhgEIAEoCRISCgpleHBpcmVzX2F0GAUgASgDIo8BChlDcm9zc0RldmljZUxvZ2luUmVxdWVzdGVyEgoKAmlwGAEgASgJEhIKCnVzZXJfYWdlbnQYAiABKAkSFQoNbG9jYXRpb25faGludBgDIAEoCRITCgtjbGllbnRfbmFtZRgEIAEoCRISCgpjcmVhdGVkX2F0GAUgASgDEhIKCmV4cGlyZXNfYXQYBiABKAMiKgoaR2V0Q3Jvc3NEZXZpY2VMb2dpblJlcXVlc3QSDAoEY29kZRgBIAEoCSJVChtHZXRDcm9zc0RldmljZUxvZ2luUmVzcG9uc2USNgoJcmVxdWVzdGVyGAEgASgLMiMuZ3JlZXQudjEuQ3Jvc3NEZXZpY2VMb2dpblJlcXVlc3RlciI/Ch5BcHByb3ZlQ3Jvc3NEZXZpY2VMb2dpblJlcXVlc3QSDAoEY29kZRgBIAEoCRIPCgdhcHByb3ZlGAIgASgIIlkKH0FwcHJvdmVDcm9zc0RldmljZUxvZ2luUmVzcG9uc2USNgoJcmVxdWVzdGVyGAEgASgLMiMuZ3JlZXQudjEuQ3Jvc3NEZXZpY2VMb2dpblJlcXVlc3RlciIoChdSZXF1ZXN0TWFnaWNMaW5rUmVxdWVzdBINCgVlbWFpbBgBIAEoCSI9ChhSZXF1ZXN0TWFnaWNMaW5rUmVzcG9uc2USDQoFbm9uY2UYASABKAkSEgoKZXhwaXJlc19hdBgCIAEoAyIwCh9SZXF1ZXN0UmVnaXN0cmF0aW9uRW1haWxSZXF1ZXN0Eg0KBWVtYWlsGAEgASgJIjYKIFJlcXVlc3RSZWdpc3RyYXRpb25FbWFpbFJlc3BvbnNlEhIKCmV4cGlyZXNfYXQYASABKAMiOAoYRXhjaGFuZ2VNYWdpY0xpbmtSZXF1ZXN0Eg0KBXRva2VuGAEgASgJEg0KBW5vbmNlGAIgASgJIjQKEElkZW50aXR5UHJvdmlkZXISCgoCaWQYASABKAkSFAoMZGlzcGxheV9uYW1lGAIgASgJIh4KHExpc3RJZGVudGl0eVByb3ZpZGVyc1JlcXVlc3QiTgodTGlzdElkZW50aXR5UHJvdmlkZXJzUmVzcG9uc2USLQoJcHJvdmlkZXJzGAEgAygLMhouZ3JlZXQudjEuSWRlbnRpdHlQcm92aWRlciIuChpCZWdpbkZlZGVyYXRlZExvZ2luUmVxdWVzdBIQCghwcm92aWRlchgBIAEoCSJdChtCZWdpbkZlZGVyYXRlZExvZ2luUmVzcG9uc2USGQoRYXV0aG9yaXphdGlvbl91cmwYASABKAkSDwoHYmluZGluZxgCIAEoCRISCgpleHBpcmVzX2F0GAMgASgDIjAKHExpbmtGZWRlcmF0ZWRJZGVudGl0eVJlcXVlc3QSEAoIcHJvdmlkZXIYASABKAkiTQodQ29tcGxldGVGZWRlcmF0ZWRMb2dpblJlcXVlc3QSDQoFc3RhdGUYASABKAkSDAoEY29kZRgCIAEoCRIPCgdiaW5kaW5nGAMgASgJIj0KFkdldFBvd0NoYWxsZW5nZVJlcXVlc3QSIwoGYWN0aW9uGAEgASgOMhMuZ3JlZXQudjEuUG93QWN0aW9uImYKF0dldFBvd0NoYWxsZW5nZVJlc3BvbnNlEhAKCHJlcXVpcmVkGAEgASgIEhEKCWNoYWxsZW5nZRgCIAEoCRISCgpkaWZmaWN1bHR5GAMgASgFEhIKCmV4cGlyZXNfYXQYBCABKAMqtgEKEEF1dGhSZXF1ZXN0U3RhdGUSIgoeQVVUSF9SRVFVRVNUX1NUQVRFX1VOU1BFQ0lGSUVEEAASHgoaQVVUSF9SRVFVRVNUX1NUQVRFX1BFTkRJTkcQARIfChtBVVRIX1JFUVVFU1RfU1RBVEVfQVBQUk9WRUQQAhIdChlBVVRIX1JFUVVFU1RfU1RBVEVfREVOSUVEEAMSHgoaQVVUSF9SRVFVRVNUX1NUQVRFX0VYUElSRUQQBCpsCg5DcmVkZW50aWFsVHlwZRIfChtDUkVERU5USUFMX1RZUEVfVU5TUEVDSUZJRUQQABIbChdDUkVERU5USUFMX1RZUEVfREVSSVZFRBABEhwKGENSRURFTlRJQUxfVFlQRV9QQVNTV09SRBACKl8KCVBvd0FjdGlvbhIaChZQT1dfQUNUSU9OX1VOU1BFQ0lGSUVEEAASFwoTUE9XX0FDVElPTl9SRUdJU1RFUhABEh0KGVBPV19BQ1RJT05fQVVUSF9DSEFMTEVOR0UQAjLEDwoMR3JlZXRTZXJ2aWNlElgKD0dldFBvd0NoYWxsZW5nZRIgLmdyZWV0LnYxLkdldFBvd0NoYWxsZW5nZVJlcXVlc3QaIS5ncmVldC52MS5HZXRQb3dDaGFsbGVuZ2VSZXNwb25zZSIAEmoKFUdldFJlZ2lzdHJhdGlvblBhcmFtcxImLmdyZWV0LnYxLkdldFJlZ2lzdHJhdGlvblBhcmFtc1JlcXVlc3QaJy5ncmVldC52MS5HZXRSZWdpc3RyYXRpb25QYXJhbXNSZXNwb25zZSIAEkMKCFJlZ2lzdGVyEhkuZ3JlZXQudjEuUmVnaXN0ZXJSZXF1ZXN0GhouZ3JlZXQudjEuUmVnaXN0ZXJSZXNwb25zZSIAElUKEEdldEF1dGhDaGFsbGVuZ2USHi5ncmVldC52MS5BdXRoQ2hhbGxlbmdlUmVxdWVzdBofLmdyZWV0LnYxLkF1dGhDaGFsbGVuZ2VSZXNwb25zZSIAEkkKClN1Ym1pdEF1dGgSGy5ncmVldC52MS5TdWJtaXRBdXRoUmVxdWVzdBocLmdyZWV0LnYxLlN1Ym1pdEF1dGhSZXNwb25zZSIAEl4KEUNyZWF0ZUF1dGhSZXF1ZXN0EiIuZ3JlZXQudjEuQ3JlYXRlQXV0aFJlcXVlc3RSZXF1ZXN0GiMuZ3JlZXQudjEuQ3JlYXRlQXV0aFJlcXVlc3RSZXNwb25zZSIAEl0KEFdhdGNoQXV0aFJlcXVlc3QSIS5ncmVldC52MS5XYXRjaEF1dGhSZXF1ZXN0UmVxdWVzdBoiLmdyZWV0LnYxLldhdGNoQXV0aFJlcXVlc3RSZXNwb25zZSIAMAESWAoPRGVueUF1dGhSZXF1ZXN0EiAuZ3JlZXQudjEuRGVueUF1dGhSZXF1ZXN0UmVxdWVzdBohLmdyZWV0LnYxLkRlbnlBdXRoUmVxdWVzdFJlc3BvbnNlIgASbQoWQ3JlYXRlQ3Jvc3NEZXZpY2VMb2dpbhInLmdyZWV0LnYxLkNyZWF0ZUNyb3NzRGV2aWNlTG9naW5SZXF1ZXN0GiguZ3JlZXQudjEuQ3JlYXRlQ3Jvc3NEZXZpY2VMb2dpblJlc3BvbnNlIgASZAoTR2V0Q3Jvc3NEZXZpY2VMb2dpbhIkLmdyZWV0LnYxLkdldENyb3NzRGV2aWNlTG9naW5SZXF1ZXN0GiUuZ3JlZXQudjEuR2V0Q3Jvc3NEZXZpY2VMb2dpblJlc3BvbnNlIgAScAoXQXBwcm92ZUNyb3NzRGV2aWNlTG9naW4SKC5ncmVldC52MS5BcHByb3ZlQ3Jvc3NEZXZpY2VMb2dpblJlcXVlc3QaKS5ncmVldC52MS5BcHByb3ZlQ3Jvc3NEZXZpY2VMb2dpblJlc3BvbnNlIgASWwoQUmVxdWVzdE1hZ2ljTGluaxIhLmdyZWV0LnYxLlJlcXVlc3RNYWdpY0xpbmtSZXF1ZXN0GiIuZ3JlZXQudjEuUmVxdWVzdE1hZ2ljTGlua1Jlc3BvbnNlIgASVwoRRXhjaGFuZ2VNYWdpY0xpbmsSIi5ncmVldC52MS5FeGNoYW5nZU1hZ2ljTGlua1JlcXVlc3QaHC5ncmVldC52MS5TdWJtaXRBdXRoUmVzcG9uc2UiABJzChhSZXF1ZXN0UmVnaXN0cmF0aW9uRW1haWwSKS5ncmVldC52MS5SZXF1ZXN0UmVnaXN0cmF0aW9uRW1haWxSZXF1ZXN0GiouZ3JlZXQudjEuUmVxdWVzdFJlZ2lzdHJhdGlvbkVtYWlsUmVzcG9uc2UiABJNCgxWZXJpZnlTdGVwVXASHS5ncmVldC52MS5WZXJpZnlTdGVwVXBSZXF1ZXN0GhwuZ3JlZXQudjEuU3VibWl0QXV0aFJlc3BvbnNlIgASPQoGTG9nb3V0EhcuZ3JlZXQudjEuTG9nb3V0UmVxdWVzdBoYLmdyZWV0LnYxLkxvZ291dFJlc3BvbnNlIgASTwoMUmVmcmVzaFRva2VuEh0uZ3JlZXQudjEuUmVmcmVzaFRva2VuUmVxdWVzdBoeLmdyZWV0LnYxLlJlZnJlc2hUb2tlblJlc3BvbnNlIgASagoVTGlzdElkZW50aXR5UHJvdmlkZXJzEiYuZ3JlZXQudjEuTGlzdElkZW50aXR5UHJvdmlkZXJzUmVxdWVzdBonLmdyZWV0LnYxLkxpc3RJZGVudGl0eVByb3ZpZGVyc1Jlc3BvbnNlIgASZAoTQmVnaW5GZWRlcmF0ZWRMb2dpbhIkLmdyZWV0LnYxLkJlZ2luRmVkZXJhdGVkTG9naW5SZXF1ZXN0GiUuZ3JlZXQudjEuQmVnaW5GZWRlcmF0ZWRMb2dpblJlc3BvbnNlIgASYQoWQ29tcGxldGVGZWRlcmF0ZWRMb2dpbhInLmdyZWV0LnYxLkNvbXBsZXRlRmVkZXJhdGVkTG9naW5SZXF1ZXN0GhwuZ3JlZXQudjEuU3VibWl0QXV0aFJlc3BvbnNlIgASaAoVTGlua0ZlZGVyYXRlZElkZW50aXR5EiYuZ3JlZXQudjEuTGlua0ZlZGVyYXRlZElkZW50aXR5UmVxdWVzdBolLmdyZWV0LnYxLkJlZ2luRmVkZXJhdGVkTG9naW5SZXNwb25zZSIAQoQBCgxjb20uZ3JlZXQudjFCCkdyZWV0UHJvdG9QAVonY29ubmVjdC1nby1leGFtcGxlL2FwaS9ncmVldC92MTtncmVldHYxogIDR1hYqgIIR3JlZXQuVjHKAghHcmVldFxWMeICFEdyZWV0XFYxXEdQQk1ldGFkYXRh6gIJR3JlZXQ6OlYxYgZwcm90bzM");
+
+/**
+ * 工作量证明的解：sha256(challenge + ":" + nonce) 的前导零比特数不少于题目难度
+ *
+ * @generated from message greet.v1.ProofOfWork
+ */
+export type ProofOfWork = Message<"greet.v1.ProofOfWork"> & {
+  /**
+   * GetPowChallenge 返回的题目
+   *
+   * @generated from field: string challenge = 1;
+   */
+  challenge: string;
+
+  /**
+   * @generated from field: string nonce = 2;
+   */
+  nonce: string;
+};
+
+/**
+ * Describes the message greet.v1.ProofOfWork.
+ * Use `create(ProofOfWorkSchema)` to create a new message.
+ */
+export const ProofOfWorkSchema: GenMessage<ProofOfWork> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 0);
+
+/**
+ * 客户端派生凭证使用的 KDF 参数，由服务端下发
+ *
+ * @generated from message greet.v1.KdfParams
+ */
+export type KdfParams = Message<"greet.v1.KdfParams"> & {
+  /**
+   * argon2id；旧账号为 legacy，表示客户端自行选择的算法
+   *
+   * @generated from field: string algorithm = 1;
+   */
+  algorithm: string;
+
+  /**
+   * 参数版本，服务端提高成本时递增
+   *
+   * @generated from field: int32 version = 2;
+   */
+  version: number;
+
+  /**
+   * @generated from field: uint32 memory_kib = 3;
+   */
+  memoryKib: number;
+
+  /**
+   * @generated from field: uint32 iterations = 4;
+   */
+  iterations: number;
+
+  /**
+   * @generated from field: uint32 parallelism = 5;
+   */
+  parallelism: number;
+
+  /**
+   * @generated from field: string salt = 6;
+   */
+  salt: string;
+};
+
+/**
+ * Describes the message greet.v1.KdfParams.
+ * Use `create(KdfParamsSchema)` to create a new message.
+ */
+export const KdfParamsSchema: GenMessage<KdfParams> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 1);
+
+/**
+ * 服务端签发的 KDF 票据，保证 salt 和参数由服务端生成
+ *
+ * @generated from message greet.v1.KdfTicket
+ */
+export type KdfTicket = Message<"greet.v1.KdfTicket"> & {
+  /**
+   * @generated from field: greet.v1.KdfParams kdf = 1;
+   */
+  kdf?: KdfParams;
+
+  /**
+   * @generated from field: string ticket = 2;
+   */
+  ticket: string;
+
+  /**
+   * @generated from field: int64 expires_at = 3;
+   */
+  expiresAt: bigint;
+};
+
+/**
+ * Describes the message greet.v1.KdfTicket.
+ * Use `create(KdfTicketSchema)` to create a new message.
+ */
+export const KdfTicketSchema: GenMessage<KdfTicket> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 2);
+
+/**
+ * @generated from message greet.v1.GetRegistrationParamsRequest
+ */
+export type GetRegistrationParamsRequest = Message<"greet.v1.GetRegistrationParamsRequest"> & {
+};
+
+/**
+ * Describes the message greet.v1.GetRegistrationParamsRequest.
+ * Use `create(GetRegistrationParamsRequestSchema)` to create a new message.
+ */
+export const GetRegistrationParamsRequestSchema: GenMessage<GetRegistrationParamsRequest> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 3);
+
+/**
+ * @generated from message greet.v1.GetRegistrationParamsResponse
+ */
+export type GetRegistrationParamsResponse = Message<"greet.v1.GetRegistrationParamsResponse"> & {
+  /**
+   * @generated from field: greet.v1.KdfTicket params = 1;
+   */
+  params?: KdfTicket;
+};
+
+/**
+ * Describes the message greet.v1.GetRegistrationParamsResponse.
+ * Use `create(GetRegistrationParamsResponseSchema)` to create a new message.
+ */
+export const GetRegistrationParamsResponseSchema: GenMessage<GetRegistrationParamsResponse> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 4);
 
 /**
  * @generated from message greet.v1.RegisterRequest
@@ -35,6 +168,34 @@ export type RegisterRequest = Message<"greet.v1.RegisterRequest"> & {
    * @generated from field: string salt = 4;
    */
   salt: string;
+
+  /**
+   * 邀请注册模式下必填
+   *
+   * @generated from field: string invite_code = 5;
+   */
+  inviteCode: string;
+
+  /**
+   * 开启工作量证明时必填
+   *
+   * @generated from field: greet.v1.ProofOfWork pow = 6;
+   */
+  pow?: ProofOfWork;
+
+  /**
+   * GetRegistrationParams 返回的票据，提供后 salt 以票据为准
+   *
+   * @generated from field: string kdf_ticket = 7;
+   */
+  kdfTicket: string;
+
+  /**
+   * 限定邮箱域名注册时必填，RequestRegistrationEmail 发送到邮箱的票据
+   *
+   * @generated from field: string email_ticket = 8;
+   */
+  emailTicket: string;
 };
 
 /**
@@ -42,7 +203,7 @@ export type RegisterRequest = Message<"greet.v1.RegisterRequest"> & {
  * Use `create(RegisterRequestSchema)` to create a new message.
  */
 export const RegisterRequestSchema: GenMessage<RegisterRequest> = /*@__PURE__*/
-  messageDesc(file_api_greet_v1_greet, 0);
+  messageDesc(file_api_greet_v1_greet, 5);
 
 /**
  * @generated from message greet.v1.RegisterResponse
@@ -59,7 +220,7 @@ export type RegisterResponse = Message<"greet.v1.RegisterResponse"> & {
  * Use `create(RegisterResponseSchema)` to create a new message.
  */
 export const RegisterResponseSchema: GenMessage<RegisterResponse> = /*@__PURE__*/
-  messageDesc(file_api_greet_v1_greet, 1);
+  messageDesc(file_api_greet_v1_greet, 6);
 
 /**
  * @generated from message greet.v1.AuthChallengeRequest
@@ -69,6 +230,13 @@ export type AuthChallengeRequest = Message<"greet.v1.AuthChallengeRequest"> & {
    * @generated from field: string username = 1;
    */
   username: string;
+
+  /**
+   * 开启工作量证明时必填
+   *
+   * @generated from field: greet.v1.ProofOfWork pow = 2;
+   */
+  pow?: ProofOfWork;
 };
 
 /**
@@ -76,7 +244,7 @@ export type AuthChallengeRequest = Message<"greet.v1.AuthChallengeRequest"> & {
  * Use `create(AuthChallengeRequestSchema)` to create a new message.
  */
 export const AuthChallengeRequestSchema: GenMessage<AuthChallengeRequest> = /*@__PURE__*/
-  messageDesc(file_api_greet_v1_greet, 2);
+  messageDesc(file_api_greet_v1_greet, 7);
 
 /**
  * @generated from message greet.v1.AuthChallengeResponse
@@ -91,6 +259,25 @@ export type AuthChallengeResponse = Message<"greet.v1.AuthChallengeResponse"> &
    * @generated from field: string salt = 2;
    */
   salt: string;
+
+  /**
+   * 当前凭证使用的参数
+   *
+   * @generated from field: greet.v1.KdfParams kdf = 3;
+   */
+  kdf?: KdfParams;
+
+  /**
+   * 参数已过时时返回，客户端用新参数重新派生凭证并在 SubmitAuth 中提交
+   *
+   * @generated from field: greet.v1.KdfTicket upgrade = 4;
+   */
+  upgrade?: KdfTicket;
+
+  /**
+   * @generated from field: greet.v1.CredentialType credential_type = 5;
+   */
+  credentialType: CredentialType;
 };
 
 /**
@@ -98,7 +285,7 @@ export type AuthChallengeResponse = Message<"greet.v1.AuthChallengeResponse"> &
  * Use `create(AuthChallengeResponseSchema)` to create a new message.
  */
 export const AuthChallengeResponseSchema: GenMessage<AuthChallengeResponse> = /*@__PURE__*/
-  messageDesc(file_api_greet_v1_greet, 3);
+  messageDesc(file_api_greet_v1_greet, 8);
 
 /**
  * @generated from message greet.v1.SubmitAuthRequest
@@ -117,7 +304,7 @@ export type SubmitAuthRequest = Message<"greet.v1.SubmitAuthRequest"> & {
   hashedCredential: string;
 
   /**
-   * 链路追踪
+   * 可选，登录成功后批准对应的登录请求
    *
    * @generated from field: string auth_request_id = 3;
    */
@@ -129,6 +316,34 @@ export type SubmitAuthRequest = Message<"greet.v1.SubmitAuthRequest"> & {
    * @generated from field: string challenge_response = 4;
    */
   challengeResponse: string;
+
+  /**
+   * 可选，使用 upgrade 参数派生的新凭证
+   *
+   * @generated from field: string upgrade_credential = 5;
+   */
+  upgradeCredential: string;
+
+  /**
+   * 与 upgrade_credential 一起提交
+   *
+   * @generated from field: string upgrade_ticket = 6;
+   */
+  upgradeTicket: string;
+
+  /**
+   * 可选，客户端持久保存的设备标识，用于识别常用设备
+   *
+   * @generated from field: string device_id = 7;
+   */
+  deviceId: string;
+
+  /**
+   * credential_type 为 PASSWORD 时提交密码原文，hashed_credential 留空
+   *
+   * @generated from field: string password = 8;
+   */
+  password: string;
 };
 
 /**
@@ -136,13 +351,15 @@ export type SubmitAuthRequest = Message<"greet.v1.SubmitAuthRequest"> & {
  * Use `create(SubmitAuthRequestSchema)` to create a new message.
  */
 export const SubmitAuthRequestSchema: GenMessage<SubmitAuthRequest> = /*@__PURE__*/
-  messageDesc(file_api_greet_v1_greet, 4);
+  messageDesc(file_api_greet_v1_greet, 9);
 
 /**
  * @generated from message greet.v1.SubmitAuthResponse
  */
 export type SubmitAuthResponse = Message<"greet.v1.SubmitAuthResponse"> & {
   /**
+   * success；风险较高时为 step_up_required
+   *
    * @generated from field: string code = 1;
    */
   code: string;
@@ -158,6 +375,20 @@ export type SubmitAuthResponse = Message<"greet.v1.SubmitAuthResponse"> & {
    * @generated from field: string auth_token = 3;
    */
   authToken: string;
+
+  /**
+   * 需要二次验证时返回，使用收到的验证码调用 VerifyStepUp
+   *
+   * @generated from field: string step_up_id = 4;
+   */
+  stepUpId: string;
+
+  /**
+   * 请求携带 DPoP 证明时为 DPoP，令牌只能配合同一密钥的证明使用，否则为 Bearer
+   *
+   * @generated from field: string token_type = 5;
+   */
+  tokenType: string;
 };
 
 /**
@@ -165,35 +396,1020 @@ export type SubmitAuthResponse = Message<"greet.v1.SubmitAuthResponse"> & {
  * Use `create(SubmitAuthResponseSchema)` to create a new message.
  */
 export const SubmitAuthResponseSchema: GenMessage<SubmitAuthResponse> = /*@__PURE__*/
-  messageDesc(file_api_greet_v1_greet, 5);
+  messageDesc(file_api_greet_v1_greet, 10);
 
 /**
- * @generated from service greet.v1.GreetService
+ * 使用当前令牌换取新令牌，请求携带 DPoP 证明时新令牌绑定到该密钥
+ *
+ * @generated from message greet.v1.RefreshTokenRequest
  */
-export const GreetService: GenService<{
+export type RefreshTokenRequest = Message<"greet.v1.RefreshTokenRequest"> & {
+};
+
+/**
+ * Describes the message greet.v1.RefreshTokenRequest.
+ * Use `create(RefreshTokenRequestSchema)` to create a new message.
+ */
+export const RefreshTokenRequestSchema: GenMessage<RefreshTokenRequest> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 11);
+
+/**
+ * @generated from message greet.v1.RefreshTokenResponse
+ */
+export type RefreshTokenResponse = Message<"greet.v1.RefreshTokenResponse"> & {
   /**
-   * @generated from rpc greet.v1.GreetService.Register
+   * @generated from field: string auth_token = 1;
    */
-  register: {
-    methodKind: "unary";
-    input: typeof RegisterRequestSchema;
-    output: typeof RegisterResponseSchema;
-  },
+  authToken: string;
+
   /**
-   * @generated from rpc greet.v1.GreetService.GetAuthChallenge
+   * DPoP 或 Bearer
+   *
+   * @generated from field: string token_type = 2;
    */
-  getAuthChallenge: {
-    methodKind: "unary";
-    input: typeof AuthChallengeRequestSchema;
-    output: typeof AuthChallengeResponseSchema;
-  },
+  tokenType: string;
+
   /**
-   * @generated from rpc greet.v1.GreetService.SubmitAuth
+   * @generated from field: int64 expires_at = 3;
    */
-  submitAuth: {
-    methodKind: "unary";
-    input: typeof SubmitAuthRequestSchema;
-    output: typeof SubmitAuthResponseSchema;
+  expiresAt: bigint;
+};
+
+/**
+ * Describes the message greet.v1.RefreshTokenResponse.
+ * Use `create(RefreshTokenResponseSchema)` to create a new message.
+ */
+export const RefreshTokenResponseSchema: GenMessage<RefreshTokenResponse> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 12);
+
+/**
+ * @generated from message greet.v1.LogoutRequest
+ */
+export type LogoutRequest = Message<"greet.v1.LogoutRequest"> & {
+};
+
+/**
+ * Describes the message greet.v1.LogoutRequest.
+ * Use `create(LogoutRequestSchema)` to create a new message.
+ */
+export const LogoutRequestSchema: GenMessage<LogoutRequest> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 13);
+
+/**
+ * @generated from message greet.v1.LogoutResponse
+ */
+export type LogoutResponse = Message<"greet.v1.LogoutResponse"> & {
+};
+
+/**
+ * Describes the message greet.v1.LogoutResponse.
+ * Use `create(LogoutResponseSchema)` to create a new message.
+ */
+export const LogoutResponseSchema: GenMessage<LogoutResponse> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 14);
+
+/**
+ * @generated from message greet.v1.VerifyStepUpRequest
+ */
+export type VerifyStepUpRequest = Message<"greet.v1.VerifyStepUpRequest"> & {
+  /**
+   * @generated from field: string step_up_id = 1;
+   */
+  stepUpId: string;
+
+  /**
+   * 通知中的6位验证码
+   *
+   * @generated from field: string code = 2;
+   */
+  code: string;
+};
+
+/**
+ * Describes the message greet.v1.VerifyStepUpRequest.
+ * Use `create(VerifyStepUpRequestSchema)` to create a new message.
+ */
+export const VerifyStepUpRequestSchema: GenMessage<VerifyStepUpRequest> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 15);
+
+/**
+ * @generated from message greet.v1.CreateAuthRequestRequest
+ */
+export type CreateAuthRequestRequest = Message<"greet.v1.CreateAuthRequestRequest"> & {
+  /**
+   * 发起登录的客户端名称，例如 desktop、cli
+   *
+   * @generated from field: string client_name = 1;
+   */
+  clientName: string;
+};
+
+/**
+ * Describes the message greet.v1.CreateAuthRequestRequest.
+ * Use `create(CreateAuthRequestRequestSchema)` to create a new message.
+ */
+export const CreateAuthRequestRequestSchema: GenMessage<CreateAuthRequestRequest> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 16);
+
+/**
+ * @generated from message greet.v1.CreateAuthRequestResponse
+ */
+export type CreateAuthRequestResponse = Message<"greet.v1.CreateAuthRequestResponse"> & {
+  /**
+   * 交给浏览器，在 SubmitAuth 时回传
+   *
+   * @generated from field: string auth_request_id = 1;
+   */
+  authRequestId: string;
+
+  /**
+   * 仅发起方持有，用于订阅登录结果
+   *
+   * @generated from field: string watch_token = 2;
+   */
+  watchToken: string;
+
+  /**
+   * 过期时间，Unix 秒
+   *
+   * @generated from field: int64 expires_at = 3;
+   */
+  expiresAt: bigint;
+};
+
+/**
+ * Describes the message greet.v1.CreateAuthRequestResponse.
+ * Use `create(CreateAuthRequestResponseSchema)` to create a new message.
+ */
+export const CreateAuthRequestResponseSchema: GenMessage<CreateAuthRequestResponse> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 17);
+
+/**
+ * @generated from message greet.v1.WatchAuthRequestRequest
+ */
+export type WatchAuthRequestRequest = Message<"greet.v1.WatchAuthRequestRequest"> & {
+  /**
+   * @generated from field: string auth_request_id = 1;
+   */
+  authRequestId: string;
+
+  /**
+   * @generated from field: string watch_token = 2;
+   */
+  watchToken: string;
+};
+
+/**
+ * Describes the message greet.v1.WatchAuthRequestRequest.
+ * Use `create(WatchAuthRequestRequestSchema)` to create a new message.
+ */
+export const WatchAuthRequestRequestSchema: GenMessage<WatchAuthRequestRequest> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 18);
+
+/**
+ * @generated from message greet.v1.WatchAuthRequestResponse
+ */
+export type WatchAuthRequestResponse = Message<"greet.v1.WatchAuthRequestResponse"> & {
+  /**
+   * @generated from field: greet.v1.AuthRequestState state = 1;
+   */
+  state: AuthRequestState;
+
+  /**
+   * 仅在 APPROVED 时返回
+   *
+   * @generated from field: string auth_token = 2;
+   */
+  authToken: string;
+
+  /**
+   * @generated from field: int64 expires_at = 3;
+   */
+  expiresAt: bigint;
+};
+
+/**
+ * Describes the message greet.v1.WatchAuthRequestResponse.
+ * Use `create(WatchAuthRequestResponseSchema)` to create a new message.
+ */
+export const WatchAuthRequestResponseSchema: GenMessage<WatchAuthRequestResponse> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 19);
+
+/**
+ * 发起方使用 watch_token 取消，浏览器中的用户需要先登录，携带令牌拒绝
+ *
+ * @generated from message greet.v1.DenyAuthRequestRequest
+ */
+export type DenyAuthRequestRequest = Message<"greet.v1.DenyAuthRequestRequest"> & {
+  /**
+   * @generated from field: string auth_request_id = 1;
+   */
+  authRequestId: string;
+
+  /**
+   * @generated from field: string watch_token = 2;
+   */
+  watchToken: string;
+};
+
+/**
+ * Describes the message greet.v1.DenyAuthRequestRequest.
+ * Use `create(DenyAuthRequestRequestSchema)` to create a new message.
+ */
+export const DenyAuthRequestRequestSchema: GenMessage<DenyAuthRequestRequest> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 20);
+
+/**
+ * @generated from message greet.v1.DenyAuthRequestResponse
+ */
+export type DenyAuthRequestResponse = Message<"greet.v1.DenyAuthRequestResponse"> & {
+};
+
+/**
+ * Describes the message greet.v1.DenyAuthRequestResponse.
+ * Use `create(DenyAuthRequestResponseSchema)` to create a new message.
+ */
+export const DenyAuthRequestResponseSchema: GenMessage<DenyAuthRequestResponse> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 21);
+
+/**
+ * @generated from message greet.v1.CreateCrossDeviceLoginRequest
+ */
+export type CreateCrossDeviceLoginRequest = Message<"greet.v1.CreateCrossDeviceLoginRequest"> & {
+  /**
+   * @generated from field: string client_name = 1;
+   */
+  clientName: string;
+};
+
+/**
+ * Describes the message greet.v1.CreateCrossDeviceLoginRequest.
+ * Use `create(CreateCrossDeviceLoginRequestSchema)` to create a new message.
+ */
+export const CreateCrossDeviceLoginRequestSchema: GenMessage<CreateCrossDeviceLoginRequest> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 22);
+
+/**
+ * @generated from message greet.v1.CreateCrossDeviceLoginResponse
+ */
+export type CreateCrossDeviceLoginResponse = Message<"greet.v1.CreateCrossDeviceLoginResponse"> & {
+  /**
+   * 短码，展示在二维码旁供用户核对
+   *
+   * @generated from field: string code = 1;
+   */
+  code: string;
+
+  /**
+   * 编码到二维码中的地址
+   *
+   * @generated from field: string approve_url = 2;
+   */
+  approveUrl: string;
+
+  /**
+   * @generated from field: string auth_request_id = 3;
+   */
+  authRequestId: string;
+
+  /**
+   * 通过 WatchAuthRequest 等待批准并获取令牌
+   *
+   * @generated from field: string watch_token = 4;
+   */
+  watchToken: string;
+
+  /**
+   * @generated from field: int64 expires_at = 5;
+   */
+  expiresAt: bigint;
+};
+
+/**
+ * Describes the message greet.v1.CreateCrossDeviceLoginResponse.
+ * Use `create(CreateCrossDeviceLoginResponseSchema)` to create a new message.
+ */
+export const CreateCrossDeviceLoginResponseSchema: GenMessage<CreateCrossDeviceLoginResponse> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 23);
+
+/**
+ * 发起跨设备登录的设备信息，批准前展示给用户核对
+ *
+ * @generated from message greet.v1.CrossDeviceLoginRequester
+ */
+export type CrossDeviceLoginRequester = Message<"greet.v1.CrossDeviceLoginRequester"> & {
+  /**
+   * @generated from field: string ip = 1;
+   */
+  ip: string;
+
+  /**
+   * @generated from field: string user_agent = 2;
+   */
+  userAgent: string;
+
+  /**
+   * @generated from field: string location_hint = 3;
+   */
+  locationHint: string;
+
+  /**
+   * @generated from field: string client_name = 4;
+   */
+  clientName: string;
+
+  /**
+   * @generated from field: int64 created_at = 5;
+   */
+  createdAt: bigint;
+
+  /**
+   * @generated from field: int64 expires_at = 6;
+   */
+  expiresAt: bigint;
+};
+
+/**
+ * Describes the message greet.v1.CrossDeviceLoginRequester.
+ * Use `create(CrossDeviceLoginRequesterSchema)` to create a new message.
+ */
+export const CrossDeviceLoginRequesterSchema: GenMessage<CrossDeviceLoginRequester> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 24);
+
+/**
+ * @generated from message greet.v1.GetCrossDeviceLoginRequest
+ */
+export type GetCrossDeviceLoginRequest = Message<"greet.v1.GetCrossDeviceLoginRequest"> & {
+  /**
+   * @generated from field: string code = 1;
+   */
+  code: string;
+};
+
+/**
+ * Describes the message greet.v1.GetCrossDeviceLoginRequest.
+ * Use `create(GetCrossDeviceLoginRequestSchema)` to create a new message.
+ */
+export const GetCrossDeviceLoginRequestSchema: GenMessage<GetCrossDeviceLoginRequest> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 25);
+
+/**
+ * @generated from message greet.v1.GetCrossDeviceLoginResponse
+ */
+export type GetCrossDeviceLoginResponse = Message<"greet.v1.GetCrossDeviceLoginResponse"> & {
+  /**
+   * @generated from field: greet.v1.CrossDeviceLoginRequester requester = 1;
+   */
+  requester?: CrossDeviceLoginRequester;
+};
+
+/**
+ * Describes the message greet.v1.GetCrossDeviceLoginResponse.
+ * Use `create(GetCrossDeviceLoginResponseSchema)` to create a new message.
+ */
+export const GetCrossDeviceLoginResponseSchema: GenMessage<GetCrossDeviceLoginResponse> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 26);
+
+/**
+ * @generated from message greet.v1.ApproveCrossDeviceLoginRequest
+ */
+export type ApproveCrossDeviceLoginRequest = Message<"greet.v1.ApproveCrossDeviceLoginRequest"> & {
+  /**
+   * @generated from field: string code = 1;
+   */
+  code: string;
+
+  /**
+   * false 表示拒绝
+   *
+   * @generated from field: bool approve = 2;
+   */
+  approve: boolean;
+};
+
+/**
+ * Describes the message greet.v1.ApproveCrossDeviceLoginRequest.
+ * Use `create(ApproveCrossDeviceLoginRequestSchema)` to create a new message.
+ */
+export const ApproveCrossDeviceLoginRequestSchema: GenMessage<ApproveCrossDeviceLoginRequest> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 27);
+
+/**
+ * @generated from message greet.v1.ApproveCrossDeviceLoginResponse
+ */
+export type ApproveCrossDeviceLoginResponse = Message<"greet.v1.ApproveCrossDeviceLoginResponse"> & {
+  /**
+   * @generated from field: greet.v1.CrossDeviceLoginRequester requester = 1;
+   */
+  requester?: CrossDeviceLoginRequester;
+};
+
+/**
+ * Describes the message greet.v1.ApproveCrossDeviceLoginResponse.
+ * Use `create(ApproveCrossDeviceLoginResponseSchema)` to create a new message.
+ */
+export const ApproveCrossDeviceLoginResponseSchema: GenMessage<ApproveCrossDeviceLoginResponse> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 28);
+
+/**
+ * @generated from message greet.v1.RequestMagicLinkRequest
+ */
+export type RequestMagicLinkRequest = Message<"greet.v1.RequestMagicLinkRequest"> & {
+  /**
+   * @generated from field: string email = 1;
+   */
+  email: string;
+};
+
+/**
+ * Describes the message greet.v1.RequestMagicLinkRequest.
+ * Use `create(RequestMagicLinkRequestSchema)` to create a new message.
+ */
+export const RequestMagicLinkRequestSchema: GenMessage<RequestMagicLinkRequest> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 29);
+
+/**
+ * @generated from message greet.v1.RequestMagicLinkResponse
+ */
+export type RequestMagicLinkResponse = Message<"greet.v1.RequestMagicLinkResponse"> & {
+  /**
+   * 浏览器保存，兑换链接时一并提交，其他浏览器打开链接无法登录
+   *
+   * @generated from field: string nonce = 1;
+   */
+  nonce: string;
+
+  /**
+   * @generated from field: int64 expires_at = 2;
+   */
+  expiresAt: bigint;
+};
+
+/**
+ * Describes the message greet.v1.RequestMagicLinkResponse.
+ * Use `create(RequestMagicLinkResponseSchema)` to create a new message.
+ */
+export const RequestMagicLinkResponseSchema: GenMessage<RequestMagicLinkResponse> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 30);
+
+/**
+ * @generated from message greet.v1.RequestRegistrationEmailRequest
+ */
+export type RequestRegistrationEmailRequest = Message<"greet.v1.RequestRegistrationEmailRequest"> & {
+  /**
+   * @generated from field: string email = 1;
+   */
+  email: string;
+};
+
+/**
+ * Describes the message greet.v1.RequestRegistrationEmailRequest.
+ * Use `create(RequestRegistrationEmailRequestSchema)` to create a new message.
+ */
+export const RequestRegistrationEmailRequestSchema: GenMessage<RequestRegistrationEmailRequest> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 31);
+
+/**
+ * @generated from message greet.v1.RequestRegistrationEmailResponse
+ */
+export type RequestRegistrationEmailResponse = Message<"greet.v1.RequestRegistrationEmailResponse"> & {
+  /**
+   * 邮件中票据的过期时间
+   *
+   * @generated from field: int64 expires_at = 1;
+   */
+  expiresAt: bigint;
+};
+
+/**
+ * Describes the message greet.v1.RequestRegistrationEmailResponse.
+ * Use `create(RequestRegistrationEmailResponseSchema)` to create a new message.
+ */
+export const RequestRegistrationEmailResponseSchema: GenMessage<RequestRegistrationEmailResponse> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 32);
+
+/**
+ * @generated from message greet.v1.ExchangeMagicLinkRequest
+ */
+export type ExchangeMagicLinkRequest = Message<"greet.v1.ExchangeMagicLinkRequest"> & {
+  /**
+   * 邮件链接中的 token
+   *
+   * @generated from field: string token = 1;
+   */
+  token: string;
+
+  /**
+   * @generated from field: string nonce = 2;
+   */
+  nonce: string;
+};
+
+/**
+ * Describes the message greet.v1.ExchangeMagicLinkRequest.
+ * Use `create(ExchangeMagicLinkRequestSchema)` to create a new message.
+ */
+export const ExchangeMagicLinkRequestSchema: GenMessage<ExchangeMagicLinkRequest> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 33);
+
+/**
+ * @generated from message greet.v1.IdentityProvider
+ */
+export type IdentityProvider = Message<"greet.v1.IdentityProvider"> & {
+  /**
+   * @generated from field: string id = 1;
+   */
+  id: string;
+
+  /**
+   * @generated from field: string display_name = 2;
+   */
+  displayName: string;
+};
+
+/**
+ * Describes the message greet.v1.IdentityProvider.
+ * Use `create(IdentityProviderSchema)` to create a new message.
+ */
+export const IdentityProviderSchema: GenMessage<IdentityProvider> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 34);
+
+/**
+ * @generated from message greet.v1.ListIdentityProvidersRequest
+ */
+export type ListIdentityProvidersRequest = Message<"greet.v1.ListIdentityProvidersRequest"> & {
+};
+
+/**
+ * Describes the message greet.v1.ListIdentityProvidersRequest.
+ * Use `create(ListIdentityProvidersRequestSchema)` to create a new message.
+ */
+export const ListIdentityProvidersRequestSchema: GenMessage<ListIdentityProvidersRequest> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 35);
+
+/**
+ * @generated from message greet.v1.ListIdentityProvidersResponse
+ */
+export type ListIdentityProvidersResponse = Message<"greet.v1.ListIdentityProvidersResponse"> & {
+  /**
+   * @generated from field: repeated greet.v1.IdentityProvider providers = 1;
+   */
+  providers: IdentityProvider[];
+};
+
+/**
+ * Describes the message greet.v1.ListIdentityProvidersResponse.
+ * Use `create(ListIdentityProvidersResponseSchema)` to create a new message.
+ */
+export const ListIdentityProvidersResponseSchema: GenMessage<ListIdentityProvidersResponse> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 36);
+
+/**
+ * @generated from message greet.v1.BeginFederatedLoginRequest
+ */
+export type BeginFederatedLoginRequest = Message<"greet.v1.BeginFederatedLoginRequest"> & {
+  /**
+   * @generated from field: string provider = 1;
+   */
+  provider: string;
+};
+
+/**
+ * Describes the message greet.v1.BeginFederatedLoginRequest.
+ * Use `create(BeginFederatedLoginRequestSchema)` to create a new message.
+ */
+export const BeginFederatedLoginRequestSchema: GenMessage<BeginFederatedLoginRequest> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 37);
+
+/**
+ * @generated from message greet.v1.BeginFederatedLoginResponse
+ */
+export type BeginFederatedLoginResponse = Message<"greet.v1.BeginFederatedLoginResponse"> & {
+  /**
+   * 浏览器跳转到 IdP 的地址
+   *
+   * @generated from field: string authorization_url = 1;
+   */
+  authorizationUrl: string;
+
+  /**
+   * 浏览器保存，回调后一并提交，其他浏览器无法用同一个 state 完成登录
+   *
+   * @generated from field: string binding = 2;
+   */
+  binding: string;
+
+  /**
+   * @generated from field: int64 expires_at = 3;
+   */
+  expiresAt: bigint;
+};
+
+/**
+ * Describes the message greet.v1.BeginFederatedLoginResponse.
+ * Use `create(BeginFederatedLoginResponseSchema)` to create a new message.
+ */
+export const BeginFederatedLoginResponseSchema: GenMessage<BeginFederatedLoginResponse> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 38);
+
+/**
+ * @generated from message greet.v1.LinkFederatedIdentityRequest
+ */
+export type LinkFederatedIdentityRequest = Message<"greet.v1.LinkFederatedIdentityRequest"> & {
+  /**
+   * @generated from field: string provider = 1;
+   */
+  provider: string;
+};
+
+/**
+ * Describes the message greet.v1.LinkFederatedIdentityRequest.
+ * Use `create(LinkFederatedIdentityRequestSchema)` to create a new message.
+ */
+export const LinkFederatedIdentityRequestSchema: GenMessage<LinkFederatedIdentityRequest> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 39);
+
+/**
+ * @generated from message greet.v1.CompleteFederatedLoginRequest
+ */
+export type CompleteFederatedLoginRequest = Message<"greet.v1.CompleteFederatedLoginRequest"> & {
+  /**
+   * IdP 回调地址中的 state
+   *
+   * @generated from field: string state = 1;
+   */
+  state: string;
+
+  /**
+   * IdP 回调地址中的 code
+   *
+   * @generated from field: string code = 2;
+   */
+  code: string;
+
+  /**
+   * @generated from field: string binding = 3;
+   */
+  binding: string;
+};
+
+/**
+ * Describes the message greet.v1.CompleteFederatedLoginRequest.
+ * Use `create(CompleteFederatedLoginRequestSchema)` to create a new message.
+ */
+export const CompleteFederatedLoginRequestSchema: GenMessage<CompleteFederatedLoginRequest> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 40);
+
+/**
+ * @generated from message greet.v1.GetPowChallengeRequest
+ */
+export type GetPowChallengeRequest = Message<"greet.v1.GetPowChallengeRequest"> & {
+  /**
+   * @generated from field: greet.v1.PowAction action = 1;
+   */
+  action: PowAction;
+};
+
+/**
+ * Describes the message greet.v1.GetPowChallengeRequest.
+ * Use `create(GetPowChallengeRequestSchema)` to create a new message.
+ */
+export const GetPowChallengeRequestSchema: GenMessage<GetPowChallengeRequest> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 41);
+
+/**
+ * @generated from message greet.v1.GetPowChallengeResponse
+ */
+export type GetPowChallengeResponse = Message<"greet.v1.GetPowChallengeResponse"> & {
+  /**
+   * 未开启时为 false，客户端可跳过
+   *
+   * @generated from field: bool required = 1;
+   */
+  required: boolean;
+
+  /**
+   * @generated from field: string challenge = 2;
+   */
+  challenge: string;
+
+  /**
+   * 需要的前导零比特数
+   *
+   * @generated from field: int32 difficulty = 3;
+   */
+  difficulty: number;
+
+  /**
+   * @generated from field: int64 expires_at = 4;
+   */
+  expiresAt: bigint;
+};
+
+/**
+ * Describes the message greet.v1.GetPowChallengeResponse.
+ * Use `create(GetPowChallengeResponseSchema)` to create a new message.
+ */
+export const GetPowChallengeResponseSchema: GenMessage<GetPowChallengeResponse> = /*@__PURE__*/
+  messageDesc(file_api_greet_v1_greet, 42);
+
+/**
+ * 登录请求的状态
+ *
+ * @generated from enum greet.v1.AuthRequestState
+ */
+export enum AuthRequestState {
+  /**
+   * @generated from enum value: AUTH_REQUEST_STATE_UNSPECIFIED = 0;
+   */
+  UNSPECIFIED = 0,
+
+  /**
+   * 等待用户在浏览器中完成登录
+   *
+   * @generated from enum value: AUTH_REQUEST_STATE_PENDING = 1;
+   */
+  PENDING = 1,
+
+  /**
+   * 登录成功，携带令牌
+   *
+   * @generated from enum value: AUTH_REQUEST_STATE_APPROVED = 2;
+   */
+  APPROVED = 2,
+
+  /**
+   * 用户拒绝了本次登录
+   *
+   * @generated from enum value: AUTH_REQUEST_STATE_DENIED = 3;
+   */
+  DENIED = 3,
+
+  /**
+   * 登录请求已过期
+   *
+   * @generated from enum value: AUTH_REQUEST_STATE_EXPIRED = 4;
+   */
+  EXPIRED = 4,
+}
+
+/**
+ * Describes the enum greet.v1.AuthRequestState.
+ */
+export const AuthRequestStateSchema: GenEnum<AuthRequestState> = /*@__PURE__*/
+  enumDesc(file_api_greet_v1_greet, 0);
+
+/**
+ * 登录时提交的凭证
+ *
+ * @generated from enum greet.v1.CredentialType
+ */
+export enum CredentialType {
+  /**
+   * @generated from enum value: CREDENTIAL_TYPE_UNSPECIFIED = 0;
+   */
+  UNSPECIFIED = 0,
+
+  /**
+   * 按 kdf 参数派生后提交 hashed_credential
+   *
+   * @generated from enum value: CREDENTIAL_TYPE_DERIVED = 1;
+   */
+  DERIVED = 1,
+
+  /**
+   * 目录服务账号，提交 password 原文，由目录服务校验
+   *
+   * @generated from enum value: CREDENTIAL_TYPE_PASSWORD = 2;
+   */
+  PASSWORD = 2,
+}
+
+/**
+ * Describes the enum greet.v1.CredentialType.
+ */
+export const CredentialTypeSchema: GenEnum<CredentialType> = /*@__PURE__*/
+  enumDesc(file_api_greet_v1_greet, 1);
+
+/**
+ * 需要工作量证明的操作
+ *
+ * @generated from enum greet.v1.PowAction
+ */
+export enum PowAction {
+  /**
+   * @generated from enum value: POW_ACTION_UNSPECIFIED = 0;
+   */
+  UNSPECIFIED = 0,
+
+  /**
+   * @generated from enum value: POW_ACTION_REGISTER = 1;
+   */
+  REGISTER = 1,
+
+  /**
+   * @generated from enum value: POW_ACTION_AUTH_CHALLENGE = 2;
+   */
+  AUTH_CHALLENGE = 2,
+}
+
+/**
+ * Describes the enum greet.v1.PowAction.
+ */
+export const PowActionSchema: GenEnum<PowAction> = /*@__PURE__*/
+  enumDesc(file_api_greet_v1_greet, 2);
+
+/**
+ * @generated from service greet.v1.GreetService
+ */
+export const GreetService: GenService<{
+  /**
+   * 获取工作量证明题目，解出后随 Register 或 GetAuthChallenge 提交
+   *
+   * @generated from rpc greet.v1.GreetService.GetPowChallenge
+   */
+  getPowChallenge: {
+    methodKind: "unary";
+    input: typeof GetPowChallengeRequestSchema;
+    output: typeof GetPowChallengeResponseSchema;
+  },
+  /**
+   * 获取注册使用的 salt 和 KDF 参数
+   *
+   * @generated from rpc greet.v1.GreetService.GetRegistrationParams
+   */
+  getRegistrationParams: {
+    methodKind: "unary";
+    input: typeof GetRegistrationParamsRequestSchema;
+    output: typeof GetRegistrationParamsResponseSchema;
+  },
+  /**
+   * @generated from rpc greet.v1.GreetService.Register
+   */
+  register: {
+    methodKind: "unary";
+    input: typeof RegisterRequestSchema;
+    output: typeof RegisterResponseSchema;
+  },
+  /**
+   * @generated from rpc greet.v1.GreetService.GetAuthChallenge
+   */
+  getAuthChallenge: {
+    methodKind: "unary";
+    input: typeof AuthChallengeRequestSchema;
+    output: typeof AuthChallengeResponseSchema;
+  },
+  /**
+   * @generated from rpc greet.v1.GreetService.SubmitAuth
+   */
+  submitAuth: {
+    methodKind: "unary";
+    input: typeof SubmitAuthRequestSchema;
+    output: typeof SubmitAuthResponseSchema;
+  },
+  /**
+   * 创建登录请求，桌面端和 CLI 通过 WatchAuthRequest 等待浏览器完成登录
+   *
+   * @generated from rpc greet.v1.GreetService.CreateAuthRequest
+   */
+  createAuthRequest: {
+    methodKind: "unary";
+    input: typeof CreateAuthRequestRequestSchema;
+    output: typeof CreateAuthRequestResponseSchema;
+  },
+  /**
+   * @generated from rpc greet.v1.GreetService.WatchAuthRequest
+   */
+  watchAuthRequest: {
+    methodKind: "server_streaming";
+    input: typeof WatchAuthRequestRequestSchema;
+    output: typeof WatchAuthRequestResponseSchema;
+  },
+  /**
+   * @generated from rpc greet.v1.GreetService.DenyAuthRequest
+   */
+  denyAuthRequest: {
+    methodKind: "unary";
+    input: typeof DenyAuthRequestRequestSchema;
+    output: typeof DenyAuthRequestResponseSchema;
+  },
+  /**
+   * 扫码登录：未登录设备创建，已登录设备查看并批准（需要 Bearer 令牌）
+   *
+   * @generated from rpc greet.v1.GreetService.CreateCrossDeviceLogin
+   */
+  createCrossDeviceLogin: {
+    methodKind: "unary";
+    input: typeof CreateCrossDeviceLoginRequestSchema;
+    output: typeof CreateCrossDeviceLoginResponseSchema;
+  },
+  /**
+   * @generated from rpc greet.v1.GreetService.GetCrossDeviceLogin
+   */
+  getCrossDeviceLogin: {
+    methodKind: "unary";
+    input: typeof GetCrossDeviceLoginRequestSchema;
+    output: typeof GetCrossDeviceLoginResponseSchema;
+  },
+  /**
+   * @generated from rpc greet.v1.GreetService.ApproveCrossDeviceLogin
+   */
+  approveCrossDeviceLogin: {
+    methodKind: "unary";
+    input: typeof ApproveCrossDeviceLoginRequestSchema;
+    output: typeof ApproveCrossDeviceLoginResponseSchema;
+  },
+  /**
+   * 邮件免密登录：发送一次性登录链接，兑换后返回与 SubmitAuth 相同的令牌
+   *
+   * @generated from rpc greet.v1.GreetService.RequestMagicLink
+   */
+  requestMagicLink: {
+    methodKind: "unary";
+    input: typeof RequestMagicLinkRequestSchema;
+    output: typeof RequestMagicLinkResponseSchema;
+  },
+  /**
+   * @generated from rpc greet.v1.GreetService.ExchangeMagicLink
+   */
+  exchangeMagicLink: {
+    methodKind: "unary";
+    input: typeof ExchangeMagicLinkRequestSchema;
+    output: typeof SubmitAuthResponseSchema;
+  },
+  /**
+   * 限定邮箱域名注册时，先向邮箱发送票据证明邮箱属于注册者，再随 Register 提交
+   *
+   * @generated from rpc greet.v1.GreetService.RequestRegistrationEmail
+   */
+  requestRegistrationEmail: {
+    methodKind: "unary";
+    input: typeof RequestRegistrationEmailRequestSchema;
+    output: typeof RequestRegistrationEmailResponseSchema;
+  },
+  /**
+   * 登录风险较高时的二次验证，验证通过后返回与 SubmitAuth 相同的令牌
+   *
+   * @generated from rpc greet.v1.GreetService.VerifyStepUp
+   */
+  verifyStepUp: {
+    methodKind: "unary";
+    input: typeof VerifyStepUpRequestSchema;
+    output: typeof SubmitAuthResponseSchema;
+  },
+  /**
+   * 注销当前会话（需要 Bearer 令牌），同一会话签发的令牌随即失效
+   *
+   * @generated from rpc greet.v1.GreetService.Logout
+   */
+  logout: {
+    methodKind: "unary";
+    input: typeof LogoutRequestSchema;
+    output: typeof LogoutResponseSchema;
+  },
+  /**
+   * @generated from rpc greet.v1.GreetService.RefreshToken
+   */
+  refreshToken: {
+    methodKind: "unary";
+    input: typeof RefreshTokenRequestSchema;
+    output: typeof RefreshTokenResponseSchema;
+  },
+  /**
+   * 外部 OIDC 登录：跳转 IdP 完成授权后用回调中的 code 和 state 兑换，返回与 SubmitAuth 相同的令牌
+   *
+   * @generated from rpc greet.v1.GreetService.ListIdentityProviders
+   */
+  listIdentityProviders: {
+    methodKind: "unary";
+    input: typeof ListIdentityProvidersRequestSchema;
+    output: typeof ListIdentityProvidersResponseSchema;
+  },
+  /**
+   * @generated from rpc greet.v1.GreetService.BeginFederatedLogin
+   */
+  beginFederatedLogin: {
+    methodKind: "unary";
+    input: typeof BeginFederatedLoginRequestSchema;
+    output: typeof BeginFederatedLoginResponseSchema;
+  },
+  /**
+   * @generated from rpc greet.v1.GreetService.CompleteFederatedLogin
+   */
+  completeFederatedLogin: {
+    methodKind: "unary";
+    input: typeof CompleteFederatedLoginRequestSchema;
+    output: typeof SubmitAuthResponseSchema;
+  },
+  /**
+   * 已登录用户关联外部身份（需要 Bearer 令牌），之后同样通过 CompleteFederatedLogin 完成
+   *
+   * @generated from rpc greet.v1.GreetService.LinkFederatedIdentity
+   */
+  linkFederatedIdentity: {
+    methodKind: "unary";
+    input: typeof LinkFederatedIdentityRequestSchema;
+    output: typeof BeginFederatedLoginResponseSchema;
   },
 }> = /*@__PURE__*/
   serviceDesc(file_api_greet_v1_greet, 0);
@@ -34,6 +34,12 @@ const (
 // reflection-formatted method names, remove the leading slash and convert the remaining slash to a
 // period.
 const (
+	// GreetServiceGetPowChallengeProcedure is the fully-qualified name of the GreetService's
+	// GetPowChallenge RPC.
+	GreetServiceGetPowChallengeProcedure = "/greet.v1.GreetService/GetPowChallenge"
+	// GreetServiceGetRegistrationParamsProcedure is the fully-qualified name of the GreetService's
+	// GetRegistrationParams RPC.
+	GreetServiceGetRegistrationParamsProcedure = "/greet.v1.GreetService/GetRegistrationParams"
 	// GreetServiceRegisterProcedure is the fully-qualified name of the GreetService's Register RPC.
 	GreetServiceRegisterProcedure = "/greet.v1.GreetService/Register"
 	// GreetServiceGetAuthChallengeProcedure is the fully-qualified name of the GreetService's
@@ -41,13 +47,88 @@ const (
 	GreetServiceGetAuthChallengeProcedure = "/greet.v1.GreetService/GetAuthChallenge"
 	// GreetServiceSubmitAuthProcedure is the fully-qualified name of the GreetService's SubmitAuth RPC.
 	GreetServiceSubmitAuthProcedure = "/greet.v1.GreetService/SubmitAuth"
+	// GreetServiceCreateAuthRequestProcedure is the fully-qualified name of the GreetService's
+	// CreateAuthRequest RPC.
+	GreetServiceCreateAuthRequestProcedure = "/greet.v1.GreetService/CreateAuthRequest"
+	// GreetServiceWatchAuthRequestProcedure is the fully-qualified name of the GreetService's
+	// WatchAuthRequest RPC.
+	GreetServiceWatchAuthRequestProcedure = "/greet.v1.GreetService/WatchAuthRequest"
+	// GreetServiceDenyAuthRequestProcedure is the fully-qualified name of the GreetService's
+	// DenyAuthRequest RPC.
+	GreetServiceDenyAuthRequestProcedure = "/greet.v1.GreetService/DenyAuthRequest"
+	// GreetServiceCreateCrossDeviceLoginProcedure is the fully-qualified name of the GreetService's
+	// CreateCrossDeviceLogin RPC.
+	GreetServiceCreateCrossDeviceLoginProcedure = "/greet.v1.GreetService/CreateCrossDeviceLogin"
+	// GreetServiceGetCrossDeviceLoginProcedure is the fully-qualified name of the GreetService's
+	// GetCrossDeviceLogin RPC.
+	GreetServiceGetCrossDeviceLoginProcedure = "/greet.v1.GreetService/GetCrossDeviceLogin"
+	// GreetServiceApproveCrossDeviceLoginProcedure is the fully-qualified name of the GreetService's
+	// ApproveCrossDeviceLogin RPC.
+	GreetServiceApproveCrossDeviceLoginProcedure = "/greet.v1.GreetService/ApproveCrossDeviceLogin"
+	// GreetServiceRequestMagicLinkProcedure is the fully-qualified name of the GreetService's
+	// RequestMagicLink RPC.
+	GreetServiceRequestMagicLinkProcedure = "/greet.v1.GreetService/RequestMagicLink"
+	// GreetServiceExchangeMagicLinkProcedure is the fully-qualified name of the GreetService's
+	// ExchangeMagicLink RPC.
+	GreetServiceExchangeMagicLinkProcedure = "/greet.v1.GreetService/ExchangeMagicLink"
+	// GreetServiceRequestRegistrationEmailProcedure is the fully-qualified name of the GreetService's
+	// RequestRegistrationEmail RPC.
+	GreetServiceRequestRegistrationEmailProcedure = "/greet.v1.GreetService/RequestRegistrationEmail"
+	// GreetServiceVerifyStepUpProcedure is the fully-qualified name of the GreetService's VerifyStepUp
+	// RPC.
+	GreetServiceVerifyStepUpProcedure = "/greet.v1.GreetService/VerifyStepUp"
+	// GreetServiceLogoutProcedure is the fully-qualified name of the GreetService's Logout RPC.
+	GreetServiceLogoutProcedure = "/greet.v1.GreetService/Logout"
+	// GreetServiceRefreshTokenProcedure is the fully-qualified name of the GreetService's RefreshToken
+	// RPC.
+	GreetServiceRefreshTokenProcedure = "/greet.v1.GreetService/RefreshToken"
+	// GreetServiceListIdentityProvidersProcedure is the fully-qualified name of the GreetService's
+	// ListIdentityProviders RPC.
+	GreetServiceListIdentityProvidersProcedure = "/greet.v1.GreetService/ListIdentityProviders"
+	// GreetServiceBeginFederatedLoginProcedure is the fully-qualified name of the GreetService's
+	// BeginFederatedLogin RPC.
+	GreetServiceBeginFederatedLoginProcedure = "/greet.v1.GreetService/BeginFederatedLogin"
+	// GreetServiceCompleteFederatedLoginProcedure is the fully-qualified name of the GreetService's
+	// CompleteFederatedLogin RPC.
+	GreetServiceCompleteFederatedLoginProcedure = "/greet.v1.GreetService/CompleteFederatedLogin"
+	// GreetServiceLinkFederatedIdentityProcedure is the fully-qualified name of the GreetService's
+	// LinkFederatedIdentity RPC.
+	GreetServiceLinkFederatedIdentityProcedure = "/greet.v1.GreetService/LinkFederatedIdentity"
 )
 
 // GreetServiceClient is a client for the greet.v1.GreetService service.
 type GreetServiceClient interface {
+	// 获取工作量证明题目，解出后随 Register 或 GetAuthChallenge 提交
+	GetPowChallenge(context.Context, *connect.Request[v1.GetPowChallengeRequest]) (*connect.Response[v1.GetPowChallengeResponse], error)
+	// 获取注册使用的 salt 和 KDF 参数
+	GetRegistrationParams(context.Context, *connect.Request[v1.GetRegistrationParamsRequest]) (*connect.Response[v1.GetRegistrationParamsResponse], error)
 	Register(context.Context, *connect.Request[v1.RegisterRequest]) (*connect.Response[v1.RegisterResponse], error)
 	GetAuthChallenge(context.Context, *connect.Request[v1.AuthChallengeRequest]) (*connect.Response[v1.AuthChallengeResponse], error)
 	SubmitAuth(context.Context, *connect.Request[v1.SubmitAuthRequest]) (*connect.Response[v1.SubmitAuthResponse], error)
+	// 创建登录请求，桌面端和 CLI 通过 WatchAuthRequest 等待浏览器完成登录
+	CreateAuthRequest(context.Context, *connect.Request[v1.CreateAuthRequestRequest]) (*connect.Response[v1.CreateAuthRequestResponse], error)
+	WatchAuthRequest(context.Context, *connect.Request[v1.WatchAuthRequestRequest]) (*connect.ServerStreamForClient[v1.WatchAuthRequestResponse], error)
+	DenyAuthRequest(context.Context, *connect.Request[v1.DenyAuthRequestRequest]) (*connect.Response[v1.DenyAuthRequestResponse], error)
+	// 扫码登录：未登录设备创建，已登录设备查看并批准（需要 Bearer 令牌）
+	CreateCrossDeviceLogin(context.Context, *connect.Request[v1.CreateCrossDeviceLoginRequest]) (*connect.Response[v1.CreateCrossDeviceLoginResponse], error)
+	GetCrossDeviceLogin(context.Context, *connect.Request[v1.GetCrossDeviceLoginRequest]) (*connect.Response[v1.GetCrossDeviceLoginResponse], error)
+	ApproveCrossDeviceLogin(context.Context, *connect.Request[v1.ApproveCrossDeviceLoginRequest]) (*connect.Response[v1.ApproveCrossDeviceLoginResponse], error)
+	// 邮件免密登录：发送一次性登录链接，兑换后返回与 SubmitAuth 相同的令牌
+	RequestMagicLink(context.Context, *connect.Request[v1.RequestMagicLinkRequest]) (*connect.Response[v1.RequestMagicLinkResponse], error)
+	ExchangeMagicLink(context.Context, *connect.Request[v1.ExchangeMagicLinkRequest]) (*connect.Response[v1.SubmitAuthResponse], error)
+	// 限定邮箱域名注册时，先向邮箱发送票据证明邮箱属于注册者，再随 Register 提交
+	RequestRegistrationEmail(context.Context, *connect.Request[v1.RequestRegistrationEmailRequest]) (*connect.Response[v1.RequestRegistrationEmailResponse], error)
+	// 登录风险较高时的二次验证，验证通过后返回与 SubmitAuth 相同的令牌
+	VerifyStepUp(context.Context, *connect.Request[v1.VerifyStepUpRequest]) (*connect.Response[v1.SubmitAuthResponse], error)
+	// 注销当前会话（需要 Bearer 令牌），同一会话签发的令牌随即失效
+	Logout(context.Context, *connect.Request[v1.LogoutRequest]) (*connect.Response[v1.LogoutResponse], error)
+	RefreshToken(context.Context, *connect.Request[v1.RefreshTokenRequest]) (*connect.Response[v1.RefreshTokenResponse], error)
+	// 外部 OIDC 登录：跳转 IdP 完成授权后用回调中的 code 和 state 兑换，返回与 SubmitAuth 相同的令牌
+	ListIdentityProviders(context.Context, *connect.Request[v1.ListIdentityProvidersRequest]) (*connect.Response[v1.ListIdentityProvidersResponse], error)
+	BeginFederatedLogin(context.Context, *connect.Request[v1.BeginFederatedLoginRequest]) (*connect.Response[v1.BeginFederatedLoginResponse], error)
+	CompleteFederatedLogin(context.Context, *connect.Request[v1.CompleteFederatedLoginRequest]) (*connect.Response[v1.SubmitAuthResponse], error)
+	// 已登录用户关联外部身份（需要 Bearer 令牌），之后同样通过 CompleteFederatedLogin 完成
+	LinkFederatedIdentity(context.Context, *connect.Request[v1.LinkFederatedIdentityRequest]) (*connect.Response[v1.BeginFederatedLoginResponse], error)
 }
 
 // NewGreetServiceClient constructs a client for the greet.v1.GreetService service. By default, it
@@ -61,6 +142,18 @@ func NewGreetServiceClient(httpClient connect.HTTPClient, baseURL string, opts .
 	baseURL = strings.TrimRight(baseURL, "/")
 	greetServiceMethods := v1.File_api_greet_v1_greet_proto.Services().ByName("GreetService").Methods()
 	return &greetServiceClient{
+		getPowChallenge: connect.NewClient[v1.GetPowChallengeRequest, v1.GetPowChallengeResponse](
+			httpClient,
+			baseURL+GreetServiceGetPowChallengeProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("GetPowChallenge")),
+			connect.WithClientOptions(opts...),
+		),
+		getRegistrationParams: connect.NewClient[v1.GetRegistrationParamsRequest, v1.GetRegistrationParamsResponse](
+			httpClient,
+			baseURL+GreetServiceGetRegistrationParamsProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("GetRegistrationParams")),
+			connect.WithClientOptions(opts...),
+		),
 		register: connect.NewClient[v1.RegisterRequest, v1.RegisterResponse](
 			httpClient,
 			baseURL+GreetServiceRegisterProcedure,
@@ -79,14 +172,138 @@ func NewGreetServiceClient(httpClient connect.HTTPClient, baseURL string, opts .
 			connect.WithSchema(greetServiceMethods.ByName("SubmitAuth")),
 			connect.WithClientOptions(opts...),
 		),
+		createAuthRequest: connect.NewClient[v1.CreateAuthRequestRequest, v1.CreateAuthRequestResponse](
+			httpClient,
+			baseURL+GreetServiceCreateAuthRequestProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("CreateAuthRequest")),
+			connect.WithClientOptions(opts...),
+		),
+		watchAuthRequest: connect.NewClient[v1.WatchAuthRequestRequest, v1.WatchAuthRequestResponse](
+			httpClient,
+			baseURL+GreetServiceWatchAuthRequestProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("WatchAuthRequest")),
+			connect.WithClientOptions(opts...),
+		),
+		denyAuthRequest: connect.NewClient[v1.DenyAuthRequestRequest, v1.DenyAuthRequestResponse](
+			httpClient,
+			baseURL+GreetServiceDenyAuthRequestProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("DenyAuthRequest")),
+			connect.WithClientOptions(opts...),
+		),
+		createCrossDeviceLogin: connect.NewClient[v1.CreateCrossDeviceLoginRequest, v1.CreateCrossDeviceLoginResponse](
+			httpClient,
+			baseURL+GreetServiceCreateCrossDeviceLoginProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("CreateCrossDeviceLogin")),
+			connect.WithClientOptions(opts...),
+		),
+		getCrossDeviceLogin: connect.NewClient[v1.GetCrossDeviceLoginRequest, v1.GetCrossDeviceLoginResponse](
+			httpClient,
+			baseURL+GreetServiceGetCrossDeviceLoginProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("GetCrossDeviceLogin")),
+			connect.WithClientOptions(opts...),
+		),
+		approveCrossDeviceLogin: connect.NewClient[v1.ApproveCrossDeviceLoginRequest, v1.ApproveCrossDeviceLoginResponse](
+			httpClient,
+			baseURL+GreetServiceApproveCrossDeviceLoginProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("ApproveCrossDeviceLogin")),
+			connect.WithClientOptions(opts...),
+		),
+		requestMagicLink: connect.NewClient[v1.RequestMagicLinkRequest, v1.RequestMagicLinkResponse](
+			httpClient,
+			baseURL+GreetServiceRequestMagicLinkProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("RequestMagicLink")),
+			connect.WithClientOptions(opts...),
+		),
+		exchangeMagicLink: connect.NewClient[v1.ExchangeMagicLinkRequest, v1.SubmitAuthResponse](
+			httpClient,
+			baseURL+GreetServiceExchangeMagicLinkProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("ExchangeMagicLink")),
+			connect.WithClientOptions(opts...),
+		),
+		requestRegistrationEmail: connect.NewClient[v1.RequestRegistrationEmailRequest, v1.RequestRegistrationEmailResponse](
+			httpClient,
+			baseURL+GreetServiceRequestRegistrationEmailProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("RequestRegistrationEmail")),
+			connect.WithClientOptions(opts...),
+		),
+		verifyStepUp: connect.NewClient[v1.VerifyStepUpRequest, v1.SubmitAuthResponse](
+			httpClient,
+			baseURL+GreetServiceVerifyStepUpProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("VerifyStepUp")),
+			connect.WithClientOptions(opts...),
+		),
+		logout: connect.NewClient[v1.LogoutRequest, v1.LogoutResponse](
+			httpClient,
+			baseURL+GreetServiceLogoutProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("Logout")),
+			connect.WithClientOptions(opts...),
+		),
+		refreshToken: connect.NewClient[v1.RefreshTokenRequest, v1.RefreshTokenResponse](
+			httpClient,
+			baseURL+GreetServiceRefreshTokenProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("RefreshToken")),
+			connect.WithClientOptions(opts...),
+		),
+		listIdentityProviders: connect.NewClient[v1.ListIdentityProvidersRequest, v1.ListIdentityProvidersResponse](
+			httpClient,
+			baseURL+GreetServiceListIdentityProvidersProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("ListIdentityProviders")),
+			connect.WithClientOptions(opts...),
+		),
+		beginFederatedLogin: connect.NewClient[v1.BeginFederatedLoginRequest, v1.BeginFederatedLoginResponse](
+			httpClient,
+			baseURL+GreetServiceBeginFederatedLoginProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("BeginFederatedLogin")),
+			connect.WithClientOptions(opts...),
+		),
+		completeFederatedLogin: connect.NewClient[v1.CompleteFederatedLoginRequest, v1.SubmitAuthResponse](
+			httpClient,
+			baseURL+GreetServiceCompleteFederatedLoginProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("CompleteFederatedLogin")),
+			connect.WithClientOptions(opts...),
+		),
+		linkFederatedIdentity: connect.NewClient[v1.LinkFederatedIdentityRequest, v1.BeginFederatedLoginResponse](
+			httpClient,
+			baseURL+GreetServiceLinkFederatedIdentityProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("LinkFederatedIdentity")),
+			connect.WithClientOptions(opts...),
+		),
 	}
 }
 
 // greetServiceClient implements GreetServiceClient.
 type greetServiceClient struct {
-	register         *connect.Client[v1.RegisterRequest, v1.RegisterResponse]
-	getAuthChallenge *connect.Client[v1.AuthChallengeRequest, v1.AuthChallengeResponse]
-	submitAuth       *connect.Client[v1.SubmitAuthRequest, v1.SubmitAuthResponse]
+	getPowChallenge          *connect.Client[v1.GetPowChallengeRequest, v1.GetPowChallengeResponse]
+	getRegistrationParams    *connect.Client[v1.GetRegistrationParamsRequest, v1.GetRegistrationParamsResponse]
+	register                 *connect.Client[v1.RegisterRequest, v1.RegisterResponse]
+	getAuthChallenge         *connect.Client[v1.AuthChallengeRequest, v1.AuthChallengeResponse]
+	submitAuth               *connect.Client[v1.SubmitAuthRequest, v1.SubmitAuthResponse]
+	createAuthRequest        *connect.Client[v1.CreateAuthRequestRequest, v1.CreateAuthRequestResponse]
+	watchAuthRequest         *connect.Client[v1.WatchAuthRequestRequest, v1.WatchAuthRequestResponse]
+	denyAuthRequest          *connect.Client[v1.DenyAuthRequestRequest, v1.DenyAuthRequestResponse]
+	createCrossDeviceLogin   *connect.Client[v1.CreateCrossDeviceLoginRequest, v1.CreateCrossDeviceLoginResponse]
+	getCrossDeviceLogin      *connect.Client[v1.GetCrossDeviceLoginRequest, v1.GetCrossDeviceLoginResponse]
+	approveCrossDeviceLogin  *connect.Client[v1.ApproveCrossDeviceLoginRequest, v1.ApproveCrossDeviceLoginResponse]
+	requestMagicLink         *connect.Client[v1.RequestMagicLinkRequest, v1.RequestMagicLinkResponse]
+	exchangeMagicLink        *connect.Client[v1.ExchangeMagicLinkRequest, v1.SubmitAuthResponse]
+	requestRegistrationEmail *connect.Client[v1.RequestRegistrationEmailRequest, v1.RequestRegistrationEmailResponse]
+	verifyStepUp             *connect.Client[v1.VerifyStepUpRequest, v1.SubmitAuthResponse]
+	logout                   *connect.Client[v1.LogoutRequest, v1.LogoutResponse]
+	refreshToken             *connect.Client[v1.RefreshTokenRequest, v1.RefreshTokenResponse]
+	listIdentityProviders    *connect.Client[v1.ListIdentityProvidersRequest, v1.ListIdentityProvidersResponse]
+	beginFederatedLogin      *connect.Client[v1.BeginFederatedLoginRequest, v1.BeginFederatedLoginResponse]
+	completeFederatedLogin   *connect.Client[v1.CompleteFederatedLoginRequest, v1.SubmitAuthResponse]
+	linkFederatedIdentity    *connect.Client[v1.LinkFederatedIdentityRequest, v1.BeginFederatedLoginResponse]
+}
+
+// GetPowChallenge calls greet.v1.GreetService.GetPowChallenge.
+func (c *greetServiceClient) GetPowChallenge(ctx context.Context, req *connect.Request[v1.GetPowChallengeRequest]) (*connect.Response[v1.GetPowChallengeResponse], error) {
+	return c.getPowChallenge.CallUnary(ctx, req)
+}
+
+// GetRegistrationParams calls greet.v1.GreetService.GetRegistrationParams.
+func (c *greetServiceClient) GetRegistrationParams(ctx context.Context, req *connect.Request[v1.GetRegistrationParamsRequest]) (*connect.Response[v1.GetRegistrationParamsResponse], error) {
+	return c.getRegistrationParams.CallUnary(ctx, req)
 }
 
 // Register calls greet.v1.GreetService.Register.
@@ -104,11 +321,119 @@ func (c *greetServiceClient) SubmitAuth(ctx context.Context, req *connect.Reques
 	return c.submitAuth.CallUnary(ctx, req)
 }
 
+// CreateAuthRequest calls greet.v1.GreetService.CreateAuthRequest.
+func (c *greetServiceClient) CreateAuthRequest(ctx context.Context, req *connect.Request[v1.CreateAuthRequestRequest]) (*connect.Response[v1.CreateAuthRequestResponse], error) {
+	return c.createAuthRequest.CallUnary(ctx, req)
+}
+
+// WatchAuthRequest calls greet.v1.GreetService.WatchAuthRequest.
+func (c *greetServiceClient) WatchAuthRequest(ctx context.Context, req *connect.Request[v1.WatchAuthRequestRequest]) (*connect.ServerStreamForClient[v1.WatchAuthRequestResponse], error) {
+	return c.watchAuthRequest.CallServerStream(ctx, req)
+}
+
+// DenyAuthRequest calls greet.v1.GreetService.DenyAuthRequest.
+func (c *greetServiceClient) DenyAuthRequest(ctx context.Context, req *connect.Request[v1.DenyAuthRequestRequest]) (*connect.Response[v1.DenyAuthRequestResponse], error) {
+	return c.denyAuthRequest.CallUnary(ctx, req)
+}
+
+// CreateCrossDeviceLogin calls greet.v1.GreetService.CreateCrossDeviceLogin.
+func (c *greetServiceClient) CreateCrossDeviceLogin(ctx context.Context, req *connect.Request[v1.CreateCrossDeviceLoginRequest]) (*connect.Response[v1.CreateCrossDeviceLoginResponse], error) {
+	return c.createCrossDeviceLogin.CallUnary(ctx, req)
+}
+
+// GetCrossDeviceLogin calls greet.v1.GreetService.GetCrossDeviceLogin.
+func (c *greetServiceClient) GetCrossDeviceLogin(ctx context.Context, req *connect.Request[v1.GetCrossDeviceLoginRequest]) (*connect.Response[v1.GetCrossDeviceLoginResponse], error) {
+	return c.getCrossDeviceLogin.CallUnary(ctx, req)
+}
+
+// ApproveCrossDeviceLogin calls greet.v1.GreetService.ApproveCrossDeviceLogin.
+func (c *greetServiceClient) ApproveCrossDeviceLogin(ctx context.Context, req *connect.Request[v1.ApproveCrossDeviceLoginRequest]) (*connect.Response[v1.ApproveCrossDeviceLoginResponse], error) {
+	return c.approveCrossDeviceLogin.CallUnary(ctx, req)
+}
+
+// RequestMagicLink calls greet.v1.GreetService.RequestMagicLink.
+func (c *greetServiceClient) RequestMagicLink(ctx context.Context, req *connect.Request[v1.RequestMagicLinkRequest]) (*connect.Response[v1.RequestMagicLinkResponse], error) {
+	return c.requestMagicLink.CallUnary(ctx, req)
+}
+
+// ExchangeMagicLink calls greet.v1.GreetService.ExchangeMagicLink.
+func (c *greetServiceClient) ExchangeMagicLink(ctx context.Context, req *connect.Request[v1.ExchangeMagicLinkRequest]) (*connect.Response[v1.SubmitAuthResponse], error) {
+	return c.exchangeMagicLink.CallUnary(ctx, req)
+}
+
+// RequestRegistrationEmail calls greet.v1.GreetService.RequestRegistrationEmail.
+func (c *greetServiceClient) RequestRegistrationEmail(ctx context.Context, req *connect.Request[v1.RequestRegistrationEmailRequest]) (*connect.Response[v1.RequestRegistrationEmailResponse], error) {
+	return c.requestRegistrationEmail.CallUnary(ctx, req)
+}
+
+// VerifyStepUp calls greet.v1.GreetService.VerifyStepUp.
+func (c *greetServiceClient) VerifyStepUp(ctx context.Context, req *connect.Request[v1.VerifyStepUpRequest]) (*connect.Response[v1.SubmitAuthResponse], error) {
+	return c.verifyStepUp.CallUnary(ctx, req)
+}
+
+// Logout calls greet.v1.GreetService.Logout.
+func (c *greetServiceClient) Logout(ctx context.Context, req *connect.Request[v1.LogoutRequest]) (*connect.Response[v1.LogoutResponse], error) {
+	return c.logout.CallUnary(ctx, req)
+}
+
+// RefreshToken calls greet.v1.GreetService.RefreshToken.
+func (c *greetServiceClient) RefreshToken(ctx context.Context, req *connect.Request[v1.RefreshTokenRequest]) (*connect.Response[v1.RefreshTokenResponse], error) {
+	return c.refreshToken.CallUnary(ctx, req)
+}
+
+// ListIdentityProviders calls greet.v1.GreetService.ListIdentityProviders.
+func (c *greetServiceClient) ListIdentityProviders(ctx context.Context, req *connect.Request[v1.ListIdentityProvidersRequest]) (*connect.Response[v1.ListIdentityProvidersResponse], error) {
+	return c.listIdentityProviders.CallUnary(ctx, req)
+}
+
+// BeginFederatedLogin calls greet.v1.GreetService.BeginFederatedLogin.
+func (c *greetServiceClient) BeginFederatedLogin(ctx context.Context, req *connect.Request[v1.BeginFederatedLoginRequest]) (*connect.Response[v1.BeginFederatedLoginResponse], error) {
+	return c.beginFederatedLogin.CallUnary(ctx, req)
+}
+
+// CompleteFederatedLogin calls greet.v1.GreetService.CompleteFederatedLogin.
+func (c *greetServiceClient) CompleteFederatedLogin(ctx context.Context, req *connect.Request[v1.CompleteFederatedLoginRequest]) (*connect.Response[v1.SubmitAuthResponse], error) {
+	return c.completeFederatedLogin.CallUnary(ctx, req)
+}
+
+// LinkFederatedIdentity calls greet.v1.GreetService.LinkFederatedIdentity.
+func (c *greetServiceClient) LinkFederatedIdentity(ctx context.Context, req *connect.Request[v1.LinkFederatedIdentityRequest]) (*connect.Response[v1.BeginFederatedLoginResponse], error) {
+	return c.linkFederatedIdentity.CallUnary(ctx, req)
+}
+
 // GreetServiceHandler is an implementation of the greet.v1.GreetService service.
 type GreetServiceHandler interface {
+	// 获取工作量证明题目，解出后随 Register 或 GetAuthChallenge 提交
+	GetPowChallenge(context.Context, *connect.Request[v1.GetPowChallengeRequest]) (*connect.Response[v1.GetPowChallengeResponse], error)
+	// 获取注册使用的 salt 和 KDF 参数
+	GetRegistrationParams(context.Context, *connect.Request[v1.GetRegistrationParamsRequest]) (*connect.Response[v1.GetRegistrationParamsResponse], error)
 	Register(context.Context, *connect.Request[v1.RegisterRequest]) (*connect.Response[v1.RegisterResponse], error)
 	GetAuthChallenge(context.Context, *connect.Request[v1.AuthChallengeRequest]) (*connect.Response[v1.AuthChallengeResponse], error)
 	SubmitAuth(context.Context, *connect.Request[v1.SubmitAuthRequest]) (*connect.Response[v1.SubmitAuthResponse], error)
+	// 创建登录请求，桌面端和 CLI 通过 WatchAuthRequest 等待浏览器完成登录
+	CreateAuthRequest(context.Context, *connect.Request[v1.CreateAuthRequestRequest]) (*connect.Response[v1.CreateAuthRequestResponse], error)
+	WatchAuthRequest(context.Context, *connect.Request[v1.WatchAuthRequestRequest], *connect.ServerStream[v1.WatchAuthRequestResponse]) error
+	DenyAuthRequest(context.Context, *connect.Request[v1.DenyAuthRequestRequest]) (*connect.Response[v1.DenyAuthRequestResponse], error)
+	// 扫码登录：未登录设备创建，已登录设备查看并批准（需要 Bearer 令牌）
+	CreateCrossDeviceLogin(context.Context, *connect.Request[v1.CreateCrossDeviceLoginRequest]) (*connect.Response[v1.CreateCrossDeviceLoginResponse], error)
+	GetCrossDeviceLogin(context.Context, *connect.Request[v1.GetCrossDeviceLoginRequest]) (*connect.Response[v1.GetCrossDeviceLoginResponse], error)
+	ApproveCrossDeviceLogin(context.Context, *connect.Request[v1.ApproveCrossDeviceLoginRequest]) (*connect.Response[v1.ApproveCrossDeviceLoginResponse], error)
+	// 邮件免密登录：发送一次性登录链接，兑换后返回与 SubmitAuth 相同的令牌
+	RequestMagicLink(context.Context, *connect.Request[v1.RequestMagicLinkRequest]) (*connect.Response[v1.RequestMagicLinkResponse], error)
+	ExchangeMagicLink(context.Context, *connect.Request[v1.ExchangeMagicLinkRequest]) (*connect.Response[v1.SubmitAuthResponse], error)
+	// 限定邮箱域名注册时，先向邮箱发送票据证明邮箱属于注册者，再随 Register 提交
+	RequestRegistrationEmail(context.Context, *connect.Request[v1.RequestRegistrationEmailRequest]) (*connect.Response[v1.RequestRegistrationEmailResponse], error)
+	// 登录风险较高时的二次验证，验证通过后返回与 SubmitAuth 相同的令牌
+	VerifyStepUp(context.Context, *connect.Request[v1.VerifyStepUpRequest]) (*connect.Response[v1.SubmitAuthResponse], error)
+	// 注销当前会话（需要 Bearer 令牌），同一会话签发的令牌随即失效
+	Logout(context.Context, *connect.Request[v1.LogoutRequest]) (*connect.Response[v1.LogoutResponse], error)
+	RefreshToken(context.Context, *connect.Request[v1.RefreshTokenRequest]) (*connect.Response[v1.RefreshTokenResponse], error)
+	// 外部 OIDC 登录：跳转 IdP 完成授权后用回调中的 code 和 state 兑换，返回与 SubmitAuth 相同的令牌
+	ListIdentityProviders(context.Context, *connect.Request[v1.ListIdentityProvidersRequest]) (*connect.Response[v1.ListIdentityProvidersResponse], error)
+	BeginFederatedLogin(context.Context, *connect.Request[v1.BeginFederatedLoginRequest]) (*connect.Response[v1.BeginFederatedLoginResponse], error)
+	CompleteFederatedLogin(context.Context, *connect.Request[v1.CompleteFederatedLoginRequest]) (*connect.Response[v1.SubmitAuthResponse], error)
+	// 已登录用户关联外部身份（需要 Bearer 令牌），之后同样通过 CompleteFederatedLogin 完成
+	LinkFederatedIdentity(context.Context, *connect.Request[v1.LinkFederatedIdentityRequest]) (*connect.Response[v1.BeginFederatedLoginResponse], error)
 }
 
 // NewGreetServiceHandler builds an HTTP handler from the service implementation. It returns the
@@ -118,6 +443,18 @@ type GreetServiceHandler interface {
 // and JSON codecs. They also support gzip compression.
 func NewGreetServiceHandler(svc GreetServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
 	greetServiceMethods := v1.File_api_greet_v1_greet_proto.Services().ByName("GreetService").Methods()
+	greetServiceGetPowChallengeHandler := connect.NewUnaryHandler(
+		GreetServiceGetPowChallengeProcedure,
+		svc.GetPowChallenge,
+		connect.WithSchema(greetServiceMethods.ByName("GetPowChallenge")),
+		connect.WithHandlerOptions(opts...),
+	)
+	greetServiceGetRegistrationParamsHandler := connect.NewUnaryHandler(
+		GreetServiceGetRegistrationParamsProcedure,
+		svc.GetRegistrationParams,
+		connect.WithSchema(greetServiceMethods.ByName("GetRegistrationParams")),
+		connect.WithHandlerOptions(opts...),
+	)
 	greetServiceRegisterHandler := connect.NewUnaryHandler(
 		GreetServiceRegisterProcedure,
 		svc.Register,
@@ -136,14 +473,146 @@ func NewGreetServiceHandler(svc GreetServiceHandler, opts ...connect.HandlerOpti
 		connect.WithSchema(greetServiceMethods.ByName("SubmitAuth")),
 		connect.WithHandlerOptions(opts...),
 	)
+	greetServiceCreateAuthRequestHandler := connect.NewUnaryHandler(
+		GreetServiceCreateAuthRequestProcedure,
+		svc.CreateAuthRequest,
+		connect.WithSchema(greetServiceMethods.ByName("CreateAuthRequest")),
+		connect.WithHandlerOptions(opts...),
+	)
+	greetServiceWatchAuthRequestHandler := connect.NewServerStreamHandler(
+		GreetServiceWatchAuthRequestProcedure,
+		svc.WatchAuthRequest,
+		connect.WithSchema(greetServiceMethods.ByName("WatchAuthRequest")),
+		connect.WithHandlerOptions(opts...),
+	)
+	greetServiceDenyAuthRequestHandler := connect.NewUnaryHandler(
+		GreetServiceDenyAuthRequestProcedure,
+		svc.DenyAuthRequest,
+		connect.WithSchema(greetServiceMethods.ByName("DenyAuthRequest")),
+		connect.WithHandlerOptions(opts...),
+	)
+	greetServiceCreateCrossDeviceLoginHandler := connect.NewUnaryHandler(
+		GreetServiceCreateCrossDeviceLoginProcedure,
+		svc.CreateCrossDeviceLogin,
+		connect.WithSchema(greetServiceMethods.ByName("CreateCrossDeviceLogin")),
+		connect.WithHandlerOptions(opts...),
+	)
+	greetServiceGetCrossDeviceLoginHandler := connect.NewUnaryHandler(
+		GreetServiceGetCrossDeviceLoginProcedure,
+		svc.GetCrossDeviceLogin,
+		connect.WithSchema(greetServiceMethods.ByName("GetCrossDeviceLogin")),
+		connect.WithHandlerOptions(opts...),
+	)
+	greetServiceApproveCrossDeviceLoginHandler := connect.NewUnaryHandler(
+		GreetServiceApproveCrossDeviceLoginProcedure,
+		svc.ApproveCrossDeviceLogin,
+		connect.WithSchema(greetServiceMethods.ByName("ApproveCrossDeviceLogin")),
+		connect.WithHandlerOptions(opts...),
+	)
+	greetServiceRequestMagicLinkHandler := connect.NewUnaryHandler(
+		GreetServiceRequestMagicLinkProcedure,
+		svc.RequestMagicLink,
+		connect.WithSchema(greetServiceMethods.ByName("RequestMagicLink")),
+		connect.WithHandlerOptions(opts...),
+	)
+	greetServiceExchangeMagicLinkHandler := connect.NewUnaryHandler(
+		GreetServiceExchangeMagicLinkProcedure,
+		svc.ExchangeMagicLink,
+		connect.WithSchema(greetServiceMethods.ByName("ExchangeMagicLink")),
+		connect.WithHandlerOptions(opts...),
+	)
+	greetServiceRequestRegistrationEmailHandler := connect.NewUnaryHandler(
+		GreetServiceRequestRegistrationEmailProcedure,
+		svc.RequestRegistrationEmail,
+		connect.WithSchema(greetServiceMethods.ByName("RequestRegistrationEmail")),
+		connect.WithHandlerOptions(opts...),
+	)
+	greetServiceVerifyStepUpHandler := connect.NewUnaryHandler(
+		GreetServiceVerifyStepUpProcedure,
+		svc.VerifyStepUp,
+		connect.WithSchema(greetServiceMethods.ByName("VerifyStepUp")),
+		connect.WithHandlerOptions(opts...),
+	)
+	greetServiceLogoutHandler := connect.NewUnaryHandler(
+		GreetServiceLogoutProcedure,
+		svc.Logout,
+		connect.WithSchema(greetServiceMethods.ByName("Logout")),
+		connect.WithHandlerOptions(opts...),
+	)
+	greetServiceRefreshTokenHandler := connect.NewUnaryHandler(
+		GreetServiceRefreshTokenProcedure,
+		svc.RefreshToken,
+		connect.WithSchema(greetServiceMethods.ByName("RefreshToken")),
+		connect.WithHandlerOptions(opts...),
+	)
+	greetServiceListIdentityProvidersHandler := connect.NewUnaryHandler(
+		GreetServiceListIdentityProvidersProcedure,
+		svc.ListIdentityProviders,
+		connect.WithSchema(greetServiceMethods.ByName("ListIdentityProviders")),
+		connect.WithHandlerOptions(opts...),
+	)
+	greetServiceBeginFederatedLoginHandler := connect.NewUnaryHandler(
+		GreetServiceBeginFederatedLoginProcedure,
+		svc.BeginFederatedLogin,
+		connect.WithSchema(greetServiceMethods.ByName("BeginFederatedLogin")),
+		connect.WithHandlerOptions(opts...),
+	)
+	greetServiceCompleteFederatedLoginHandler := connect.NewUnaryHandler(
+		GreetServiceCompleteFederatedLoginProcedure,
+		svc.CompleteFederatedLogin,
+		connect.WithSchema(greetServiceMethods.ByName("CompleteFederatedLogin")),
+		connect.WithHandlerOptions(opts...),
+	)
+	greetServiceLinkFederatedIdentityHandler := connect.NewUnaryHandler(
+		GreetServiceLinkFederatedIdentityProcedure,
+		svc.LinkFederatedIdentity,
+		connect.WithSchema(greetServiceMethods.ByName("LinkFederatedIdentity")),
+		connect.WithHandlerOptions(opts...),
+	)
 	return "/greet.v1.GreetService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
+		case GreetServiceGetPowChallengeProcedure:
+			greetServiceGetPowChallengeHandler.ServeHTTP(w, r)
+		case GreetServiceGetRegistrationParamsProcedure:
+			greetServiceGetRegistrationParamsHandler.ServeHTTP(w, r)
 		case GreetServiceRegisterProcedure:
 			greetServiceRegisterHandler.ServeHTTP(w, r)
 		case GreetServiceGetAuthChallengeProcedure:
 			greetServiceGetAuthChallengeHandler.ServeHTTP(w, r)
 		case GreetServiceSubmitAuthProcedure:
 			greetServiceSubmitAuthHandler.ServeHTTP(w, r)
+		case GreetServiceCreateAuthRequestProcedure:
+			greetServiceCreateAuthRequestHandler.ServeHTTP(w, r)
+		case GreetServiceWatchAuthRequestProcedure:
+			greetServiceWatchAuthRequestHandler.ServeHTTP(w, r)
+		case GreetServiceDenyAuthRequestProcedure:
+			greetServiceDenyAuthRequestHandler.ServeHTTP(w, r)
+		case GreetServiceCreateCrossDeviceLoginProcedure:
+			greetServiceCreateCrossDeviceLoginHandler.ServeHTTP(w, r)
+		case GreetServiceGetCrossDeviceLoginProcedure:
+			greetServiceGetCrossDeviceLoginHandler.ServeHTTP(w, r)
+		case GreetServiceApproveCrossDeviceLoginProcedure:
+			greetServiceApproveCrossDeviceLoginHandler.ServeHTTP(w, r)
+		case GreetServiceRequestMagicLinkProcedure:
+			greetServiceRequestMagicLinkHandler.ServeHTTP(w, r)
+		case GreetServiceExchangeMagicLinkProcedure:
+			greetServiceExchangeMagicLinkHandler.ServeHTTP(w, r)
+		case GreetServiceRequestRegistrationEmailProcedure:
+			greetServiceRequestRegistrationEmailHandler.ServeHTTP(w, r)
+		case GreetServiceVerifyStepUpProcedure:
+			greetServiceVerifyStepUpHandler.ServeHTTP(w, r)
+		case GreetServiceLogoutProcedure:
+			greetServiceLogoutHandler.ServeHTTP(w, r)
+		case GreetServiceRefreshTokenProcedure:
+			greetServiceRefreshTokenHandler.ServeHTTP(w, r)
+		case GreetServiceListIdentityProvidersProcedure:
+			greetServiceListIdentityProvidersHandler.ServeHTTP(w, r)
+		case GreetServiceBeginFederatedLoginProcedure:
+			greetServiceBeginFederatedLoginHandler.ServeHTTP(w, r)
+		case GreetServiceCompleteFederatedLoginProcedure:
+			greetServiceCompleteFederatedLoginHandler.ServeHTTP(w, r)
+		case GreetServiceLinkFederatedIdentityProcedure:
+			greetServiceLinkFederatedIdentityHandler.ServeHTTP(w, r)
 		default:
 			http.NotFound(w, r)
 		}
@@ -153,6 +622,14 @@ func NewGreetServiceHandler(svc GreetServiceHandler, opts ...connect.HandlerOpti
 // UnimplementedGreetServiceHandler returns CodeUnimplemented from all methods.
 type UnimplementedGreetServiceHandler struct{}
 
+func (UnimplementedGreetServiceHandler) GetPowChallenge(context.Context, *connect.Request[v1.GetPowChallengeRequest]) (*connect.Response[v1.GetPowChallengeResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("greet.v1.GreetService.GetPowChallenge is not implemented"))
+}
+
+func (UnimplementedGreetServiceHandler) GetRegistrationParams(context.Context, *connect.Request[v1.GetRegistrationParamsRequest]) (*connect.Response[v1.GetRegistrationParamsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("greet.v1.GreetService.GetRegistrationParams is not implemented"))
+}
+
 func (UnimplementedGreetServiceHandler) Register(context.Context, *connect.Request[v1.RegisterRequest]) (*connect.Response[v1.RegisterResponse], error) {
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("greet.v1.GreetService.Register is not implemented"))
 }
@@ -164,3 +641,67 @@ func (UnimplementedGreetServiceHandler) GetAuthChallenge(context.Context, *conne
 func (UnimplementedGreetServiceHandler) SubmitAuth(context.Context, *connect.Request[v1.SubmitAuthRequest]) (*connect.Response[v1.SubmitAuthResponse], error) {
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("greet.v1.GreetService.SubmitAuth is not implemented"))
 }
+
+func (UnimplementedGreetServiceHandler) CreateAuthRequest(context.Context, *connect.Request[v1.CreateAuthRequestRequest]) (*connect.Response[v1.CreateAuthRequestResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("greet.v1.GreetService.CreateAuthRequest is not implemented"))
+}
+
+func (UnimplementedGreetServiceHandler) WatchAuthRequest(context.Context, *connect.Request[v1.WatchAuthRequestRequest], *connect.ServerStream[v1.WatchAuthRequestResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("greet.v1.GreetService.WatchAuthRequest is not implemented"))
+}
+
+func (UnimplementedGreetServiceHandler) DenyAuthRequest(context.Context, *connect.Request[v1.DenyAuthRequestRequest]) (*connect.Response[v1.DenyAuthRequestResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("greet.v1.GreetService.DenyAuthRequest is not implemented"))
+}
+
+func (UnimplementedGreetServiceHandler) CreateCrossDeviceLogin(context.Context, *connect.Request[v1.CreateCrossDeviceLoginRequest]) (*connect.Response[v1.CreateCrossDeviceLoginResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("greet.v1.GreetService.CreateCrossDeviceLogin is not implemented"))
+}
+
+func (UnimplementedGreetServiceHandler) GetCrossDeviceLogin(context.Context, *connect.Request[v1.GetCrossDeviceLoginRequest]) (*connect.Response[v1.GetCrossDeviceLoginResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("greet.v1.GreetService.GetCrossDeviceLogin is not implemented"))
+}
+
+func (UnimplementedGreetServiceHandler) ApproveCrossDeviceLogin(context.Context, *connect.Request[v1.ApproveCrossDeviceLoginRequest]) (*connect.Response[v1.ApproveCrossDeviceLoginResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("greet.v1.GreetService.ApproveCrossDeviceLogin is not implemented"))
+}
+
+func (UnimplementedGreetServiceHandler) RequestMagicLink(context.Context, *connect.Request[v1.RequestMagicLinkRequest]) (*connect.Response[v1.RequestMagicLinkResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("greet.v1.GreetService.RequestMagicLink is not implemented"))
+}
+
+func (UnimplementedGreetServiceHandler) ExchangeMagicLink(context.Context, *connect.Request[v1.ExchangeMagicLinkRequest]) (*connect.Response[v1.SubmitAuthResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("greet.v1.GreetService.ExchangeMagicLink is not implemented"))
+}
+
+func (UnimplementedGreetServiceHandler) RequestRegistrationEmail(context.Context, *connect.Request[v1.RequestRegistrationEmailRequest]) (*connect.Response[v1.RequestRegistrationEmailResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("greet.v1.GreetService.RequestRegistrationEmail is not implemented"))
+}
+
+func (UnimplementedGreetServiceHandler) VerifyStepUp(context.Context, *connect.Request[v1.VerifyStepUpRequest]) (*connect.Response[v1.SubmitAuthResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("greet.v1.GreetService.VerifyStepUp is not implemented"))
+}
+
+func (UnimplementedGreetServiceHandler) Logout(context.Context, *connect.Request[v1.LogoutRequest]) (*connect.Response[v1.LogoutResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("greet.v1.GreetService.Logout is not implemented"))
+}
+
+func (UnimplementedGreetServiceHandler) RefreshToken(context.Context, *connect.Request[v1.RefreshTokenRequest]) (*connect.Response[v1.RefreshTokenResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("greet.v1.GreetService.RefreshToken is not implemented"))
+}
+
+func (UnimplementedGreetServiceHandler) ListIdentityProviders(context.Context, *connect.Request[v1.ListIdentityProvidersRequest]) (*connect.Response[v1.ListIdentityProvidersResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("greet.v1.GreetService.ListIdentityProviders is not implemented"))
+}
+
+func (UnimplementedGreetServiceHandler) BeginFederatedLogin(context.Context, *connect.Request[v1.BeginFederatedLoginRequest]) (*connect.Response[v1.BeginFederatedLoginResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("greet.v1.GreetService.BeginFederatedLogin is not implemented"))
+}
+
+func (UnimplementedGreetServiceHandler) CompleteFederatedLogin(context.Context, *connect.Request[v1.CompleteFederatedLoginRequest]) (*connect.Response[v1.SubmitAuthResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("greet.v1.GreetService.CompleteFederatedLogin is not implemented"))
+}
+
+func (UnimplementedGreetServiceHandler) LinkFederatedIdentity(context.Context, *connect.Request[v1.LinkFederatedIdentityRequest]) (*connect.Response[v1.BeginFederatedLoginResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("greet.v1.GreetService.LinkFederatedIdentity is not implemented"))
+}
@@ -0,0 +1,296 @@
+// @generated by protoc-gen-es v2.9.0 with parameter "target=ts"
+// @generated from file api/oauth/v1/oauth.proto (package oauth.v1, syntax proto3)
+/* eslint-disable */
+
+import type { GenFile, GenMessage, GenService } from "@bufbuild/protobuf/codegenv2";
+import { fileDesc, messageDesc, serviceDesc } from "@bufbuild/protobuf/codegenv2";
+import type { Message } from "@bufbuild/protobuf";
+
+/**
+ * Describes the file api/oauth/v1/oauth.proto.
+ */
+export const file_api_oauth_v1_oauth: GenFile = /*@__PURE__*/
+  fileDesc("ChhhcGkvb2F1dGgvdjEvb2F1dGgucHJvdG8SCG9hdXRoLnYxIjsKEUludHJvc3BlY3RSZXF1ZXN0Eg0KBXRva2VuGAEgASgJEhcKD3Rva2VuX3R5cGVfaGludBgCIAEoCSKEAgoSSW50cm9zcGVjdFJlc3BvbnNlEg4KBmFjdGl2ZRgBIAEoCBINCgVzY29wZRgCIAEoCRIQCgh1c2VybmFtZRgDIAEoCRISCgp0b2tlbl90eXBlGAQgASgJEgsKA2V4cBgFIAEoAxILCgNpYXQYBiABKAMSCwoDc3ViGAcgASgJEgsKA2lzcxgIIAEoCRILCgNqdGkYCSABKAkSCwoDc2lkGAogASgJEgsKA3RpZBgLIAEoAxILCgNhdWQYDCABKAkSHAoDYWN0GA0gASgLMg8ub2F1dGgudjEuQWN0b3ISIwoDY25mGA4gASgLMhYub2F1dGgudjEuQ29uZmlybWF0aW9uIhsKDENvbmZpcm1hdGlvbhILCgNqa3QYASABKAkiMgoFQWN0b3ISCwoDc3ViGAEgASgJEhwKA2FjdBgCIAEoCzIPLm9hdXRoLnYxLkFjdG9yIogBChRFeGNoYW5nZVRva2VuUmVxdWVzdBIVCg1zdWJqZWN0X3Rva2VuGAEgASgJEhoKEnN1YmplY3RfdG9rZW5fdHlwZRgCIAEoCRIQCghhdWRpZW5jZRgDIAEoCRINCgVzY29wZRgEIAEoCRIcChRyZXF1ZXN0ZWRfdG9rZW5fdHlwZRgFIAEoCSJ/ChVFeGNoYW5nZVRva2VuUmVzcG9uc2USFAoMYWNjZXNzX3Rva2VuGAEgASgJEhkKEWlzc3VlZF90b2tlbl90eXBlGAIgASgJEhIKCnRva2VuX3R5cGUYAyABKAkSEgoKZXhwaXJlc19pbhgEIAEoAxINCgVzY29wZRgFIAEoCTKtAQoMT0F1dGhTZXJ2aWNlEkkKCkludHJvc3BlY3QSGy5vYXV0aC52MS5JbnRyb3NwZWN0UmVxdWVzdBocLm9hdXRoLnYxLkludHJvc3BlY3RSZXNwb25zZSIAElIKDUV4Y2hhbmdlVG9rZW4SHi5vYXV0aC52MS5FeGNoYW5nZVRva2VuUmVxdWVzdBofLm9hdXRoLnYxLkV4Y2hhbmdlVG9rZW5SZXNwb25zZSIAQoQBCgxjb20ub2F1dGgudjFCCk9hdXRoUHJvdG9QAVonY29ubmVjdC1nby1leGFtcGxlL2FwaS9vYXV0aC92MTtvYXV0aHYxogIDT1hYqgIIT2F1dGguVjHKAghPYXV0aFxWMeICFE9hdXRoXFYxXEdQQk1ldGFkYXRh6gIJT2F1dGg6OlYxYgZwcm90bzM");
+
+/**
+ * 令牌内省（RFC 7662），调用方使用 HTTP Basic 携带 oauth.clients 中的凭据
+ *
+ * @generated from message oauth.v1.IntrospectRequest
+ */
+export type IntrospectRequest = Message<"oauth.v1.IntrospectRequest"> & {
+  /**
+   * @generated from field: string token = 1;
+   */
+  token: string;
+
+  /**
+   * 可选，目前只支持 access_token
+   *
+   * @generated from field: string token_type_hint = 2;
+   */
+  tokenTypeHint: string;
+};
+
+/**
+ * Describes the message oauth.v1.IntrospectRequest.
+ * Use `create(IntrospectRequestSchema)` to create a new message.
+ */
+export const IntrospectRequestSchema: GenMessage<IntrospectRequest> = /*@__PURE__*/
+  messageDesc(file_api_oauth_v1_oauth, 0);
+
+/**
+ * 令牌无效、过期或已注销时只返回 active = false
+ *
+ * @generated from message oauth.v1.IntrospectResponse
+ */
+export type IntrospectResponse = Message<"oauth.v1.IntrospectResponse"> & {
+  /**
+   * @generated from field: bool active = 1;
+   */
+  active: boolean;
+
+  /**
+   * 空格分隔，用户登录令牌不限制 scope，为空
+   *
+   * @generated from field: string scope = 2;
+   */
+  scope: string;
+
+  /**
+   * @generated from field: string username = 3;
+   */
+  username: string;
+
+  /**
+   * Bearer 或 DPoP
+   *
+   * @generated from field: string token_type = 4;
+   */
+  tokenType: string;
+
+  /**
+   * @generated from field: int64 exp = 5;
+   */
+  exp: bigint;
+
+  /**
+   * @generated from field: int64 iat = 6;
+   */
+  iat: bigint;
+
+  /**
+   * 用户ID
+   *
+   * @generated from field: string sub = 7;
+   */
+  sub: string;
+
+  /**
+   * @generated from field: string iss = 8;
+   */
+  iss: string;
+
+  /**
+   * @generated from field: string jti = 9;
+   */
+  jti: string;
+
+  /**
+   * 登录会话ID，注销后同一会话的令牌全部失效
+   *
+   * @generated from field: string sid = 10;
+   */
+  sid: string;
+
+  /**
+   * 租户ID
+   *
+   * @generated from field: int64 tid = 11;
+   */
+  tid: bigint;
+
+  /**
+   * 令牌交换签发的令牌只能用于该服务
+   *
+   * @generated from field: string aud = 12;
+   */
+  aud: string;
+
+  /**
+   * 代表用户调用的服务
+   *
+   * @generated from field: oauth.v1.Actor act = 13;
+   */
+  act?: Actor;
+
+  /**
+   * 令牌绑定的 DPoP 密钥，此时 token_type 为 DPoP
+   *
+   * @generated from field: oauth.v1.Confirmation cnf = 14;
+   */
+  cnf?: Confirmation;
+};
+
+/**
+ * Describes the message oauth.v1.IntrospectResponse.
+ * Use `create(IntrospectResponseSchema)` to create a new message.
+ */
+export const IntrospectResponseSchema: GenMessage<IntrospectResponse> = /*@__PURE__*/
+  messageDesc(file_api_oauth_v1_oauth, 1);
+
+/**
+ * @generated from message oauth.v1.Confirmation
+ */
+export type Confirmation = Message<"oauth.v1.Confirmation"> & {
+  /**
+   * 公钥的 JWK SHA-256 指纹
+   *
+   * @generated from field: string jkt = 1;
+   */
+  jkt: string;
+};
+
+/**
+ * Describes the message oauth.v1.Confirmation.
+ * Use `create(ConfirmationSchema)` to create a new message.
+ */
+export const ConfirmationSchema: GenMessage<Confirmation> = /*@__PURE__*/
+  messageDesc(file_api_oauth_v1_oauth, 2);
+
+/**
+ * 委托链，act 为更早的一级调用方
+ *
+ * @generated from message oauth.v1.Actor
+ */
+export type Actor = Message<"oauth.v1.Actor"> & {
+  /**
+   * 调用方的 client_id
+   *
+   * @generated from field: string sub = 1;
+   */
+  sub: string;
+
+  /**
+   * @generated from field: oauth.v1.Actor act = 2;
+   */
+  act?: Actor;
+};
+
+/**
+ * Describes the message oauth.v1.Actor.
+ * Use `create(ActorSchema)` to create a new message.
+ */
+export const ActorSchema: GenMessage<Actor> = /*@__PURE__*/
+  messageDesc(file_api_oauth_v1_oauth, 3);
+
+/**
+ * 令牌交换（RFC 8693），调用方使用 HTTP Basic 认证，并作为 act 写入新令牌
+ *
+ * @generated from message oauth.v1.ExchangeTokenRequest
+ */
+export type ExchangeTokenRequest = Message<"oauth.v1.ExchangeTokenRequest"> & {
+  /**
+   * 用户的访问令牌
+   *
+   * @generated from field: string subject_token = 1;
+   */
+  subjectToken: string;
+
+  /**
+   * urn:ietf:params:oauth:token-type:access_token
+   *
+   * @generated from field: string subject_token_type = 2;
+   */
+  subjectTokenType: string;
+
+  /**
+   * 目标服务，必须在客户端的 allowed_audiences 中
+   *
+   * @generated from field: string audience = 3;
+   */
+  audience: string;
+
+  /**
+   * 空格分隔，必须在客户端的 allowed_scopes 中，且不能超出原令牌的 scope
+   *
+   * @generated from field: string scope = 4;
+   */
+  scope: string;
+
+  /**
+   * 可选，只支持 access_token
+   *
+   * @generated from field: string requested_token_type = 5;
+   */
+  requestedTokenType: string;
+};
+
+/**
+ * Describes the message oauth.v1.ExchangeTokenRequest.
+ * Use `create(ExchangeTokenRequestSchema)` to create a new message.
+ */
+export const ExchangeTokenRequestSchema: GenMessage<ExchangeTokenRequest> = /*@__PURE__*/
+  messageDesc(file_api_oauth_v1_oauth, 4);
+
+/**
+ * @generated from message oauth.v1.ExchangeTokenResponse
+ */
+export type ExchangeTokenResponse = Message<"oauth.v1.ExchangeTokenResponse"> & {
+  /**
+   * @generated from field: string access_token = 1;
+   */
+  accessToken: string;
+
+  /**
+   * @generated from field: string issued_token_type = 2;
+   */
+  issuedTokenType: string;
+
+  /**
+   * Bearer
+   *
+   * @generated from field: string token_type = 3;
+   */
+  tokenType: string;
+
+  /**
+   * @generated from field: int64 expires_in = 4;
+   */
+  expiresIn: bigint;
+
+  /**
+   * @generated from field: string scope = 5;
+   */
+  scope: string;
+};
+
+/**
+ * Describes the message oauth.v1.ExchangeTokenResponse.
+ * Use `create(ExchangeTokenResponseSchema)` to create a new message.
+ */
+export const ExchangeTokenResponseSchema: GenMessage<ExchangeTokenResponse> = /*@__PURE__*/
+  messageDesc(file_api_oauth_v1_oauth, 5);
+
+/**
+ * @generated from service oauth.v1.OAuthService
+ */
+export const OAuthService: GenService<{
+  /**
+   * @generated from rpc oauth.v1.OAuthService.Introspect
+   */
+  introspect: {
+    methodKind: "unary";
+    input: typeof IntrospectRequestSchema;
+    output: typeof IntrospectResponseSchema;
+  },
+  /**
+   * @generated from rpc oauth.v1.OAuthService.ExchangeToken
+   */
+  exchangeToken: {
+    methodKind: "unary";
+    input: typeof ExchangeTokenRequestSchema;
+    output: typeof ExchangeTokenResponseSchema;
+  },
+}> = /*@__PURE__*/
+  serviceDesc(file_api_oauth_v1_oauth, 0);
+
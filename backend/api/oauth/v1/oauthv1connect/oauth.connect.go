@@ -0,0 +1,138 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: api/oauth/v1/oauth.proto
+
+package oauthv1connect
+
+import (
+	context "context"
+	errors "errors"
+	http "net/http"
+	strings "strings"
+
+	v1 "connect-go-example/api/oauth/v1"
+	connect "connectrpc.com/connect"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// OAuthServiceName is the fully-qualified name of the OAuthService service.
+	OAuthServiceName = "oauth.v1.OAuthService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// OAuthServiceIntrospectProcedure is the fully-qualified name of the OAuthService's Introspect RPC.
+	OAuthServiceIntrospectProcedure = "/oauth.v1.OAuthService/Introspect"
+	// OAuthServiceExchangeTokenProcedure is the fully-qualified name of the OAuthService's
+	// ExchangeToken RPC.
+	OAuthServiceExchangeTokenProcedure = "/oauth.v1.OAuthService/ExchangeToken"
+)
+
+// OAuthServiceClient is a client for the oauth.v1.OAuthService service.
+type OAuthServiceClient interface {
+	Introspect(context.Context, *connect.Request[v1.IntrospectRequest]) (*connect.Response[v1.IntrospectResponse], error)
+	ExchangeToken(context.Context, *connect.Request[v1.ExchangeTokenRequest]) (*connect.Response[v1.ExchangeTokenResponse], error)
+}
+
+// NewOAuthServiceClient constructs a client for the oauth.v1.OAuthService service. By default, it
+// uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and sends
+// uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewOAuthServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) OAuthServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	oAuthServiceMethods := v1.File_api_oauth_v1_oauth_proto.Services().ByName("OAuthService").Methods()
+	return &oAuthServiceClient{
+		introspect: connect.NewClient[v1.IntrospectRequest, v1.IntrospectResponse](
+			httpClient,
+			baseURL+OAuthServiceIntrospectProcedure,
+			connect.WithSchema(oAuthServiceMethods.ByName("Introspect")),
+			connect.WithClientOptions(opts...),
+		),
+		exchangeToken: connect.NewClient[v1.ExchangeTokenRequest, v1.ExchangeTokenResponse](
+			httpClient,
+			baseURL+OAuthServiceExchangeTokenProcedure,
+			connect.WithSchema(oAuthServiceMethods.ByName("ExchangeToken")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// oAuthServiceClient implements OAuthServiceClient.
+type oAuthServiceClient struct {
+	introspect    *connect.Client[v1.IntrospectRequest, v1.IntrospectResponse]
+	exchangeToken *connect.Client[v1.ExchangeTokenRequest, v1.ExchangeTokenResponse]
+}
+
+// Introspect calls oauth.v1.OAuthService.Introspect.
+func (c *oAuthServiceClient) Introspect(ctx context.Context, req *connect.Request[v1.IntrospectRequest]) (*connect.Response[v1.IntrospectResponse], error) {
+	return c.introspect.CallUnary(ctx, req)
+}
+
+// ExchangeToken calls oauth.v1.OAuthService.ExchangeToken.
+func (c *oAuthServiceClient) ExchangeToken(ctx context.Context, req *connect.Request[v1.ExchangeTokenRequest]) (*connect.Response[v1.ExchangeTokenResponse], error) {
+	return c.exchangeToken.CallUnary(ctx, req)
+}
+
+// OAuthServiceHandler is an implementation of the oauth.v1.OAuthService service.
+type OAuthServiceHandler interface {
+	Introspect(context.Context, *connect.Request[v1.IntrospectRequest]) (*connect.Response[v1.IntrospectResponse], error)
+	ExchangeToken(context.Context, *connect.Request[v1.ExchangeTokenRequest]) (*connect.Response[v1.ExchangeTokenResponse], error)
+}
+
+// NewOAuthServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewOAuthServiceHandler(svc OAuthServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	oAuthServiceMethods := v1.File_api_oauth_v1_oauth_proto.Services().ByName("OAuthService").Methods()
+	oAuthServiceIntrospectHandler := connect.NewUnaryHandler(
+		OAuthServiceIntrospectProcedure,
+		svc.Introspect,
+		connect.WithSchema(oAuthServiceMethods.ByName("Introspect")),
+		connect.WithHandlerOptions(opts...),
+	)
+	oAuthServiceExchangeTokenHandler := connect.NewUnaryHandler(
+		OAuthServiceExchangeTokenProcedure,
+		svc.ExchangeToken,
+		connect.WithSchema(oAuthServiceMethods.ByName("ExchangeToken")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/oauth.v1.OAuthService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case OAuthServiceIntrospectProcedure:
+			oAuthServiceIntrospectHandler.ServeHTTP(w, r)
+		case OAuthServiceExchangeTokenProcedure:
+			oAuthServiceExchangeTokenHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedOAuthServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedOAuthServiceHandler struct{}
+
+func (UnimplementedOAuthServiceHandler) Introspect(context.Context, *connect.Request[v1.IntrospectRequest]) (*connect.Response[v1.IntrospectResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("oauth.v1.OAuthService.Introspect is not implemented"))
+}
+
+func (UnimplementedOAuthServiceHandler) ExchangeToken(context.Context, *connect.Request[v1.ExchangeTokenRequest]) (*connect.Response[v1.ExchangeTokenResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("oauth.v1.OAuthService.ExchangeToken is not implemented"))
+}
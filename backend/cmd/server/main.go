@@ -11,8 +11,12 @@ import (
 	"connect-go-example/internal/biz"
 	confv1 "connect-go-example/internal/conf/v1"
 	"connect-go-example/internal/data"
+	"connect-go-example/internal/pkg/broker"
 	"connect-go-example/internal/pkg/config"
 	logger "connect-go-example/internal/pkg/log"
+	"connect-go-example/internal/pkg/mail"
+	"connect-go-example/internal/pkg/notify"
+	"connect-go-example/internal/pkg/oidc"
 	"connect-go-example/internal/pkg/otel"
 	"connect-go-example/internal/pkg/registry"
 	"connect-go-example/internal/server"
@@ -29,6 +33,15 @@ type ServiceName string
 func main() {
 	flag.Parse()
 
+	// 数据库迁移子命令，执行完即退出
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(flag.Args()[1:]); err != nil {
+			log.Printf("Migrate failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	fxApp := NewApp()
 
 	// 启动应用
@@ -54,6 +67,10 @@ func NewApp() *fx.App {
 		config.Module,
 		logger.Module,
 		registry.Module,
+		mail.Module,
+		notify.Module,
+		oidc.Module,
+		broker.Module,
 
 		// 注入业务模块（按依赖顺序）
 		data.Module,
@@ -75,6 +92,13 @@ func NewApp() *fx.App {
 			// 注册应用到注册中心
 			func(_ *registry.ConsulRegistry) {},
 
+			// 启动领域事件和 Webhook 投递
+			func(_ *data.EventRelay) {},
+			func(_ *data.WebhookDispatcher) {},
+
+			// 启动个人数据字段的后台重新加密
+			func(_ *data.FieldReencryptor) {},
+
 			// 初始化并启动核心应用逻辑
 			func(lc fx.Lifecycle, conf *confv1.Bootstrap, logger *zap.Logger, srv *http.Server) {
 				// 初始化 Otel
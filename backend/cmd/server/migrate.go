@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	confv1 "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data"
+	"connect-go-example/internal/pkg/config"
+	logger "connect-go-example/internal/pkg/log"
+	"connect-go-example/internal/pkg/migrate"
+
+	"go.uber.org/fx"
+	"google.golang.org/protobuf/proto"
+)
+
+const migrateUsage = `usage: server migrate <command>
+
+commands:
+  up              apply all pending migrations
+  down [n]        revert the last n applied migrations (default 1)
+  baseline [v]    mark migrations up to version v (default 1) as applied without running them,
+                  for databases created from the old schema.sql
+  status          show applied and pending migrations
+  redo            revert and re-apply the last applied migration`
+
+// runMigrate 执行 migrate 子命令，只初始化配置、日志和数据库连接
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	steps := 1
+	version := int64(1)
+	switch args[0] {
+	case "up", "status", "redo":
+		if len(args) > 1 {
+			return errors.New(migrateUsage)
+		}
+	case "down":
+		if len(args) > 2 {
+			return errors.New(migrateUsage)
+		}
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid number of migrations %q", args[1])
+			}
+			steps = n
+		}
+	case "baseline":
+		if len(args) > 2 {
+			return errors.New(migrateUsage)
+		}
+		if len(args) == 2 {
+			v, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil || v <= 0 {
+				return fmt.Errorf("invalid migration version %q", args[1])
+			}
+			version = v
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	var migrator *migrate.Migrator
+	app := fx.New(
+		config.Module,
+		logger.Module,
+		fx.Decorate(withoutAutoMigrate),
+		fx.Provide(data.NewDB, data.NewMigrator),
+		fx.Invoke(requirePostgres),
+		fx.Populate(&migrator),
+		fx.NopLogger,
+	)
+	if err := app.Err(); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if err := app.Start(ctx); err != nil {
+		return err
+	}
+	defer func() { _ = app.Stop(ctx) }()
+
+	switch args[0] {
+	case "up":
+		n, err := migrator.Up(ctx)
+		fmt.Printf("%d migration(s) applied\n", n)
+		return err
+	case "down":
+		n, err := migrator.Down(ctx, steps)
+		fmt.Printf("%d migration(s) reverted\n", n)
+		return err
+	case "baseline":
+		n, err := migrator.Baseline(ctx, version)
+		fmt.Printf("%d migration(s) marked as applied\n", n)
+		return err
+	case "redo":
+		return migrator.Redo(ctx)
+	default:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return printMigrationStatus(os.Stdout, statuses)
+	}
+}
+
+// withoutAutoMigrate 子命令自己决定执行哪些迁移，不在建立连接时自动迁移
+func withoutAutoMigrate(conf *confv1.Bootstrap) *confv1.Bootstrap {
+	if conf.GetData().GetDatabase().GetAutoMigrate() {
+		conf = proto.Clone(conf).(*confv1.Bootstrap)
+		conf.Data.Database.AutoMigrate = false
+	}
+	return conf
+}
+
+// requirePostgres 迁移脚本只适用于 Postgres，sqlite 驱动启动时自行建表
+func requirePostgres(conf *confv1.Bootstrap) error {
+	if driver := conf.GetData().GetDriver(); driver != "" && driver != data.DriverPostgres {
+		return fmt.Errorf("migrate requires the %s data driver, got %q", data.DriverPostgres, driver)
+	}
+	return nil
+}
+
+func printMigrationStatus(w io.Writer, statuses []migrate.Status) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT\tNOTE")
+	for _, s := range statuses {
+		appliedAt, note := "pending", ""
+		if s.Applied {
+			appliedAt = s.AppliedAt.Local().Format(time.DateTime)
+		}
+		switch {
+		case s.Missing:
+			note = "missing script"
+		case s.Modified:
+			note = "modified after applied"
+		}
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", s.Version, s.Name, appliedAt, note)
+	}
+	return tw.Flush()
+}
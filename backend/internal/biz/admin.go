@@ -0,0 +1,137 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data"
+	"connect-go-example/internal/pkg/webhook"
+
+	"connectrpc.com/connect"
+	"go.uber.org/zap"
+)
+
+type AdminUseCase struct {
+	users        data.UserRepo
+	invites      data.InviteRepo
+	tokens       *TokenManager
+	registration *registrationPolicy
+	backends     *CredentialBackends
+	webhooks     data.WebhookRepo
+	// allowInsecureWebhooks 允许 http 订阅地址，只用于本地开发
+	allowInsecureWebhooks bool
+	// webhookPolicy 拒绝指向内网地址的订阅，投递时还会检查域名解析的结果
+	webhookPolicy *webhook.AddressPolicy
+	l             *zap.Logger
+}
+
+func NewAdminUseCase(users data.UserRepo, invites data.InviteRepo, webhooks data.WebhookRepo, tokens *TokenManager, backends *CredentialBackends, cfg *conf.Bootstrap, logger *zap.Logger) (model.AdminUseCase, error) {
+	registration, err := newRegistrationPolicy(cfg.Registration)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookPolicy, err := webhook.NewAddressPolicy(cfg.GetWebhooks().GetAllowedNetworks())
+	if err != nil {
+		return nil, err
+	}
+
+	return &AdminUseCase{
+		users:                 users,
+		invites:               invites,
+		tokens:                tokens,
+		registration:          registration,
+		backends:              backends,
+		webhooks:              webhooks,
+		allowInsecureWebhooks: cfg.GetWebhooks().GetAllowInsecureUrls(),
+		webhookPolicy:         webhookPolicy,
+		l:                     logger,
+	}, nil
+}
+
+func (uc *AdminUseCase) CreateInvite(ctx context.Context, admin *model.Principal, maxUses int32, ttl time.Duration) (*model.InviteTicket, error) {
+	if err := uc.requireAdmin(ctx, admin); err != nil {
+		return nil, err
+	}
+	if maxUses < 0 || ttl < 0 {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("max_uses and ttl must not be negative"))
+	}
+	if maxUses == 0 {
+		maxUses = uc.registration.inviteMaxUses
+	}
+	if ttl == 0 {
+		ttl = uc.registration.inviteTTL
+	}
+
+	invite := &model.Invite{
+		InviterID: admin.UserID,
+		MaxUses:   maxUses,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := uc.invites.CreateInvite(ctx, invite); err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	uc.l.Info("invite created",
+		zap.Int64("invite_id", invite.ID),
+		zap.Int64("inviter_id", admin.UserID),
+		zap.Int32("max_uses", maxUses),
+	)
+
+	return &model.InviteTicket{
+		Code:   inviteCode(uc.tokens, admin.TenantID, invite.ID),
+		Invite: invite,
+	}, nil
+}
+
+func (uc *AdminUseCase) ListInvites(ctx context.Context, admin *model.Principal) ([]*model.Invite, error) {
+	if err := uc.requireAdmin(ctx, admin); err != nil {
+		return nil, err
+	}
+
+	invites, err := uc.invites.ListInvites(ctx)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	return invites, nil
+}
+
+func (uc *AdminUseCase) SetUserCredentialBackend(ctx context.Context, admin *model.Principal, username, backend string) error {
+	if err := uc.requireAdmin(ctx, admin); err != nil {
+		return err
+	}
+	if username == "" {
+		return connect.NewError(connect.CodeInvalidArgument, errors.New("username is required"))
+	}
+	if !uc.backends.valid(backend) {
+		return connect.NewError(connect.CodeInvalidArgument, errors.New("unknown credential backend"))
+	}
+
+	if err := uc.users.SetCredentialBackend(ctx, username, backend); err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			return connect.NewError(connect.CodeNotFound, err)
+		}
+		return connect.NewError(connect.CodeInternal, err)
+	}
+	uc.l.Info("user credential backend changed",
+		zap.Int64("admin_id", admin.UserID),
+		zap.String("username", username),
+		zap.String("backend", backend),
+	)
+	return nil
+}
+
+// requireAdmin 每次从数据库读取角色，撤销管理员后立即生效
+func (uc *AdminUseCase) requireAdmin(ctx context.Context, admin *model.Principal) error {
+	roles, err := uc.users.GetUserRoles(ctx, admin.UserID)
+	if err != nil {
+		return connect.NewError(connect.CodeInternal, err)
+	}
+	if !slices.Contains(roles, model.RoleAdmin) {
+		return connect.NewError(connect.CodePermissionDenied, errors.New("admin role required"))
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+// AdminUseCaseTestSuite 是 AdminUseCase 的测试套件
+type AdminUseCaseTestSuite struct {
+	suite.Suite
+	userRepo   *MockUserRepo
+	inviteRepo *MockInviteRepo
+	webhooks   *MockWebhookRepo
+	tokens     *TokenManager
+	useCase    *AdminUseCase
+	admin      *model.Principal
+	ctx        context.Context
+}
+
+func (suite *AdminUseCaseTestSuite) SetupTest() {
+	suite.userRepo = new(MockUserRepo)
+	suite.inviteRepo = new(MockInviteRepo)
+	suite.webhooks = new(MockWebhookRepo)
+	suite.admin = &model.Principal{TenantID: 2, UserID: 1, Username: "admin"}
+	suite.ctx = model.NewTenantContext(context.Background(), &model.Tenant{ID: 2})
+	logger, _ := zap.NewDevelopment()
+
+	cfg := &conf.Bootstrap{
+		Auth: &conf.Auth{JwtSecret: "test-secret"},
+		Registration: &conf.Registration{
+			Mode:             RegistrationModeInvite,
+			InviteMaxUses:    2,
+			InviteTtlSeconds: 3600,
+		},
+	}
+	tokens, err := NewTokenManager(cfg, logger)
+	assert.NoError(suite.T(), err)
+	suite.tokens = tokens
+
+	backends := &CredentialBackends{backends: map[string]CredentialVerifier{"corp": &directoryVerifier{id: "corp"}}}
+	useCase, err := NewAdminUseCase(suite.userRepo, suite.inviteRepo, suite.webhooks, tokens, backends, cfg, logger)
+	assert.NoError(suite.T(), err)
+	suite.useCase = useCase.(*AdminUseCase)
+}
+
+func (suite *AdminUseCaseTestSuite) TestCreateInvite() {
+	suite.userRepo.On("GetUserRoles", suite.ctx, int64(1)).Return([]string{model.RoleAdmin}, nil)
+	suite.inviteRepo.On("CreateInvite", suite.ctx, mock.AnythingOfType("*model.Invite")).Run(func(args mock.Arguments) {
+		args.Get(1).(*model.Invite).ID = 5
+	}).Return(nil)
+
+	ticket, err := suite.useCase.CreateInvite(suite.ctx, suite.admin, 0, 0)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(1), ticket.Invite.InviterID)
+	assert.Equal(suite.T(), int32(2), ticket.Invite.MaxUses)
+	assert.WithinDuration(suite.T(), time.Now().Add(time.Hour), ticket.Invite.ExpiresAt, time.Minute)
+
+	// 邀请码可以在同一租户下注册时解析
+	id, ok := parseInviteCode(suite.ctx, suite.tokens, ticket.Code)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), int64(5), id)
+}
+
+func (suite *AdminUseCaseTestSuite) TestCreateInvite_NotAdmin() {
+	suite.userRepo.On("GetUserRoles", suite.ctx, int64(1)).Return([]string{}, nil)
+
+	_, err := suite.useCase.CreateInvite(suite.ctx, suite.admin, 1, time.Hour)
+
+	assert.Equal(suite.T(), connect.CodePermissionDenied, connect.CodeOf(err))
+	suite.inviteRepo.AssertNotCalled(suite.T(), "CreateInvite", mock.Anything, mock.Anything)
+}
+
+func (suite *AdminUseCaseTestSuite) TestCreateInvite_InvalidArgument() {
+	suite.userRepo.On("GetUserRoles", suite.ctx, int64(1)).Return([]string{model.RoleAdmin}, nil)
+
+	_, err := suite.useCase.CreateInvite(suite.ctx, suite.admin, -1, 0)
+
+	assert.Equal(suite.T(), connect.CodeInvalidArgument, connect.CodeOf(err))
+}
+
+func (suite *AdminUseCaseTestSuite) TestListInvites() {
+	invites := []*model.Invite{{ID: 5, InviterID: 1, UsedCount: 1, InviteeIDs: []int64{9}}}
+	suite.userRepo.On("GetUserRoles", suite.ctx, int64(1)).Return([]string{model.RoleAdmin}, nil)
+	suite.inviteRepo.On("ListInvites", suite.ctx).Return(invites, nil)
+
+	result, err := suite.useCase.ListInvites(suite.ctx, suite.admin)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), invites, result)
+}
+
+func (suite *AdminUseCaseTestSuite) TestListInvites_RoleLookupFailed() {
+	suite.userRepo.On("GetUserRoles", suite.ctx, int64(1)).Return(nil, errors.New("db down"))
+
+	_, err := suite.useCase.ListInvites(suite.ctx, suite.admin)
+
+	assert.Equal(suite.T(), connect.CodeInternal, connect.CodeOf(err))
+}
+
+func (suite *AdminUseCaseTestSuite) TestSetUserCredentialBackend() {
+	suite.userRepo.On("GetUserRoles", suite.ctx, int64(1)).Return([]string{model.RoleAdmin}, nil)
+	suite.userRepo.On("SetCredentialBackend", suite.ctx, "alice", "corp").Return(nil).Once()
+	suite.userRepo.On("SetCredentialBackend", suite.ctx, "alice", "").Return(nil).Once()
+	suite.userRepo.On("SetCredentialBackend", suite.ctx, "nobody", "local").Return(model.ErrUserNotFound).Once()
+
+	assert.NoError(suite.T(), suite.useCase.SetUserCredentialBackend(suite.ctx, suite.admin, "alice", "corp"))
+	// 恢复为租户默认后端
+	assert.NoError(suite.T(), suite.useCase.SetUserCredentialBackend(suite.ctx, suite.admin, "alice", ""))
+
+	err := suite.useCase.SetUserCredentialBackend(suite.ctx, suite.admin, "nobody", "local")
+	assert.Equal(suite.T(), connect.CodeNotFound, connect.CodeOf(err))
+	err = suite.useCase.SetUserCredentialBackend(suite.ctx, suite.admin, "alice", "partner")
+	assert.Equal(suite.T(), connect.CodeInvalidArgument, connect.CodeOf(err))
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+func (suite *AdminUseCaseTestSuite) TestSetUserCredentialBackend_NotAdmin() {
+	suite.userRepo.On("GetUserRoles", suite.ctx, int64(1)).Return([]string{}, nil)
+
+	err := suite.useCase.SetUserCredentialBackend(suite.ctx, suite.admin, "alice", "corp")
+
+	assert.Equal(suite.T(), connect.CodePermissionDenied, connect.CodeOf(err))
+	suite.userRepo.AssertNotCalled(suite.T(), "SetCredentialBackend", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAdminUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(AdminUseCaseTestSuite))
+}
@@ -0,0 +1,212 @@
+package biz
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type AuthRequestUseCase struct {
+	repo data.AuthRequestRepo
+	cfg  *conf.Auth
+	l    *zap.Logger
+}
+
+func NewAuthRequestUseCase(repo data.AuthRequestRepo, cfg *conf.Bootstrap, logger *zap.Logger) (model.AuthRequestUseCase, error) {
+	return &AuthRequestUseCase{
+		repo: repo,
+		cfg:  cfg.Auth,
+		l:    logger,
+	}, nil
+}
+
+func (uc *AuthRequestUseCase) CreateAuthRequest(ctx context.Context, clientName string) (*model.AuthRequestTicket, error) {
+	timeout := time.Duration(uc.cfg.AuthRequestTimeoutSeconds) * time.Second
+	if timeout == 0 {
+		timeout = 10 * time.Minute // 默认10分钟
+	}
+
+	req, watchToken, err := newAuthRequest(clientName, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.repo.CreateAuthRequest(ctx, req); err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	return &model.AuthRequestTicket{
+		ID:         req.ID,
+		WatchToken: watchToken,
+		ExpiresAt:  req.ExpiresAt,
+	}, nil
+}
+
+func (uc *AuthRequestUseCase) WatchAuthRequest(ctx context.Context, id, watchToken string, send func(*model.AuthRequest) error) error {
+	req, err := uc.repo.GetAuthRequest(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrAuthRequestNotFound) {
+			return connect.NewError(connect.CodeNotFound, err)
+		}
+		return connect.NewError(connect.CodeInternal, err)
+	}
+	if !constantTimeCompare(hashToken(watchToken), req.WatchTokenHash) {
+		return connect.NewError(connect.CodePermissionDenied, errors.New("invalid watch token"))
+	}
+
+	notify, closeSub, err := uc.repo.SubscribeAuthRequest(ctx, id)
+	if err != nil {
+		return connect.NewError(connect.CodeUnavailable, err)
+	}
+	defer func() {
+		if err := closeSub(); err != nil {
+			uc.l.Warn("close auth request subscription failed", zap.String("id", id), zap.Error(err))
+		}
+	}()
+
+	expireTimer := time.NewTimer(time.Until(req.ExpiresAt))
+	defer expireTimer.Stop()
+
+	last := model.AuthRequestStateUnspecified
+	for {
+		// 订阅建立后重新读取状态，避免错过订阅之前发生的变化
+		current, err := uc.repo.GetAuthRequest(ctx, id)
+		switch {
+		case errors.Is(err, model.ErrAuthRequestNotFound):
+			return send(expiredAuthRequest(req))
+		case err != nil:
+			return connect.NewError(connect.CodeInternal, err)
+		}
+
+		if current.State != last {
+			if err := send(current); err != nil {
+				return err
+			}
+			last = current.State
+		}
+		if current.State.Terminal() {
+			if current.State == model.AuthRequestStateApproved {
+				// 令牌只下发一次
+				if err := uc.repo.DeleteAuthRequest(ctx, id); err != nil {
+					uc.l.Warn("delete auth request failed", zap.String("id", id), zap.Error(err))
+				}
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-expireTimer.C:
+			return send(expiredAuthRequest(req))
+		case _, ok := <-notify:
+			if !ok {
+				return connect.NewError(connect.CodeUnavailable, errors.New("auth request subscription closed"))
+			}
+		}
+	}
+}
+
+func (uc *AuthRequestUseCase) DenyAuthRequest(ctx context.Context, id, watchToken string) error {
+	// 与批准一样需要证明身份：发起方持有 watch token，浏览器中的用户需要登录
+	principal, signedIn := model.PrincipalFromContext(ctx)
+	if watchToken == "" && !signedIn {
+		return connect.NewError(connect.CodeUnauthenticated, errors.New("watch token or sign-in required"))
+	}
+	req, err := uc.repo.GetAuthRequest(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrAuthRequestNotFound) {
+			return connect.NewError(connect.CodeNotFound, err)
+		}
+		return connect.NewError(connect.CodeInternal, err)
+	}
+	if watchToken != "" && !constantTimeCompare(hashToken(watchToken), req.WatchTokenHash) {
+		return connect.NewError(connect.CodePermissionDenied, errors.New("invalid watch token"))
+	}
+
+	ok, err := uc.repo.TransitAuthRequest(ctx, &model.AuthRequest{
+		ID:    id,
+		State: model.AuthRequestStateDenied,
+	}, model.AuthRequestStatePending)
+	if err != nil {
+		return connect.NewError(connect.CodeInternal, err)
+	}
+	if !ok {
+		return connect.NewError(connect.CodeFailedPrecondition, model.ErrAuthRequestSettled)
+	}
+	if signedIn && watchToken == "" {
+		uc.l.Info("auth request denied", zap.String("auth_request_id", id), zap.Int64("user_id", principal.UserID))
+	}
+	return nil
+}
+
+// approveAuthRequest 在登录成功后批准对应的登录请求，失败不影响本次登录
+func approveAuthRequest(ctx context.Context, repo data.AuthRequestRepo, logger *zap.Logger, id string, userID int64, token string) {
+	ok, err := repo.TransitAuthRequest(ctx, &model.AuthRequest{
+		ID:        id,
+		State:     model.AuthRequestStateApproved,
+		UserID:    userID,
+		AuthToken: token,
+	}, model.AuthRequestStatePending)
+	if err != nil {
+		logger.Warn("approve auth request failed", zap.String("auth_request_id", id), zap.Error(err))
+		return
+	}
+	if !ok {
+		logger.Info("auth request is not pending, skip approval", zap.String("auth_request_id", id))
+	}
+}
+
+// requesterToken 返回交给登录请求发起方的令牌。发起方持有自己的密钥，不能使用绑定到批准方 DPoP 密钥的令牌，
+// 因此另行签发未绑定的令牌，由发起方通过 RefreshToken 绑定
+func requesterToken(tokens *TokenManager, token, jkt string, tenantID, userID int64, username string) (string, error) {
+	if jkt == "" {
+		return token, nil
+	}
+	return tokens.Issue(tenantID, userID, username)
+}
+
+// newAuthRequest 生成待批准的登录请求和仅发起方持有的订阅令牌，服务端只保存其哈希
+func newAuthRequest(clientName string, timeout time.Duration) (*model.AuthRequest, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", fmt.Errorf("generate watch token failed: %v", err)
+	}
+	watchToken := base64.RawURLEncoding.EncodeToString(raw)
+
+	now := time.Now()
+	return &model.AuthRequest{
+		ID:             uuid.NewString(),
+		State:          model.AuthRequestStatePending,
+		ClientName:     clientName,
+		WatchTokenHash: hashToken(watchToken),
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(timeout),
+	}, watchToken, nil
+}
+
+func expiredAuthRequest(req *model.AuthRequest) *model.AuthRequest {
+	return &model.AuthRequest{
+		ID:        req.ID,
+		State:     model.AuthRequestStateExpired,
+		ExpiresAt: req.ExpiresAt,
+	}
+}
+
+// hashToken 服务端只保存一次性令牌的哈希
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
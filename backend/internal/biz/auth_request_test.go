@@ -0,0 +1,293 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+// AuthRequestUseCaseTestSuite 是 AuthRequestUseCase 的测试套件
+type AuthRequestUseCaseTestSuite struct {
+	suite.Suite
+	repo    *MockAuthRequestRepo
+	useCase *AuthRequestUseCase
+}
+
+func (suite *AuthRequestUseCaseTestSuite) SetupTest() {
+	suite.repo = new(MockAuthRequestRepo)
+	logger, _ := zap.NewDevelopment()
+
+	useCase, err := NewAuthRequestUseCase(suite.repo, &conf.Bootstrap{
+		Auth: &conf.Auth{AuthRequestTimeoutSeconds: 60},
+	}, logger)
+	assert.NoError(suite.T(), err)
+	suite.useCase = useCase.(*AuthRequestUseCase)
+}
+
+func (suite *AuthRequestUseCaseTestSuite) TestCreateAuthRequest() {
+	ctx := context.Background()
+	suite.repo.On("CreateAuthRequest", ctx, mock.AnythingOfType("*model.AuthRequest")).Return(nil)
+
+	ticket, err := suite.useCase.CreateAuthRequest(ctx, "desktop")
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), ticket.ID)
+	assert.NotEmpty(suite.T(), ticket.WatchToken)
+	suite.repo.AssertCalled(suite.T(), "CreateAuthRequest", ctx, mock.MatchedBy(func(req *model.AuthRequest) bool {
+		// 只保存订阅令牌的哈希
+		return req.ID == ticket.ID &&
+			req.State == model.AuthRequestStatePending &&
+			req.ClientName == "desktop" &&
+			req.WatchTokenHash == hashToken(ticket.WatchToken) &&
+			req.ExpiresAt.Sub(req.CreatedAt) == time.Minute
+	}))
+}
+
+func (suite *AuthRequestUseCaseTestSuite) TestWatchAuthRequest_InvalidWatchToken() {
+	ctx := context.Background()
+	suite.repo.On("GetAuthRequest", ctx, "req-1").Return(&model.AuthRequest{
+		ID:             "req-1",
+		State:          model.AuthRequestStatePending,
+		WatchTokenHash: hashToken("right"),
+		ExpiresAt:      time.Now().Add(time.Minute),
+	}, nil)
+
+	err := suite.useCase.WatchAuthRequest(ctx, "req-1", "wrong", func(*model.AuthRequest) error { return nil })
+
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), connect.CodePermissionDenied, connect.CodeOf(err))
+	suite.repo.AssertNotCalled(suite.T(), "SubscribeAuthRequest", mock.Anything, mock.Anything)
+}
+
+func (suite *AuthRequestUseCaseTestSuite) TestWatchAuthRequest_NotFound() {
+	ctx := context.Background()
+	suite.repo.On("GetAuthRequest", ctx, "missing").Return(nil, model.ErrAuthRequestNotFound)
+
+	err := suite.useCase.WatchAuthRequest(ctx, "missing", "token", func(*model.AuthRequest) error { return nil })
+
+	assert.Equal(suite.T(), connect.CodeNotFound, connect.CodeOf(err))
+}
+
+func (suite *AuthRequestUseCaseTestSuite) TestWatchAuthRequest_PendingThenApproved() {
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Minute)
+	pending := &model.AuthRequest{
+		ID:             "req-1",
+		State:          model.AuthRequestStatePending,
+		WatchTokenHash: hashToken("watch"),
+		ExpiresAt:      expiresAt,
+	}
+	approved := &model.AuthRequest{
+		ID:             "req-1",
+		State:          model.AuthRequestStateApproved,
+		WatchTokenHash: hashToken("watch"),
+		AuthToken:      "jwt.token.here",
+		ExpiresAt:      expiresAt,
+	}
+
+	notify := make(chan struct{}, 1)
+	closed := false
+	suite.repo.On("GetAuthRequest", ctx, "req-1").Return(pending, nil).Twice()
+	suite.repo.On("GetAuthRequest", ctx, "req-1").Return(approved, nil)
+	suite.repo.On("SubscribeAuthRequest", ctx, "req-1").Return((<-chan struct{})(notify), func() error {
+		closed = true
+		return nil
+	}, nil)
+	suite.repo.On("DeleteAuthRequest", ctx, "req-1").Return(nil)
+
+	var states []model.AuthRequestState
+	err := suite.useCase.WatchAuthRequest(ctx, "req-1", "watch", func(req *model.AuthRequest) error {
+		states = append(states, req.State)
+		if req.State == model.AuthRequestStatePending {
+			notify <- struct{}{}
+		}
+		if req.State == model.AuthRequestStateApproved {
+			assert.Equal(suite.T(), "jwt.token.here", req.AuthToken)
+		}
+		return nil
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []model.AuthRequestState{
+		model.AuthRequestStatePending,
+		model.AuthRequestStateApproved,
+	}, states)
+	assert.True(suite.T(), closed)
+	suite.repo.AssertCalled(suite.T(), "DeleteAuthRequest", ctx, "req-1")
+}
+
+func (suite *AuthRequestUseCaseTestSuite) TestWatchAuthRequest_Expired() {
+	ctx := context.Background()
+	pending := &model.AuthRequest{
+		ID:             "req-1",
+		State:          model.AuthRequestStatePending,
+		WatchTokenHash: hashToken("watch"),
+		ExpiresAt:      time.Now().Add(50 * time.Millisecond),
+	}
+	suite.repo.On("GetAuthRequest", ctx, "req-1").Return(pending, nil)
+	suite.repo.On("SubscribeAuthRequest", ctx, "req-1").Return((<-chan struct{})(make(chan struct{})), func() error { return nil }, nil)
+
+	var states []model.AuthRequestState
+	err := suite.useCase.WatchAuthRequest(ctx, "req-1", "watch", func(req *model.AuthRequest) error {
+		states = append(states, req.State)
+		return nil
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []model.AuthRequestState{
+		model.AuthRequestStatePending,
+		model.AuthRequestStateExpired,
+	}, states)
+}
+
+func (suite *AuthRequestUseCaseTestSuite) TestDenyAuthRequest() {
+	ctx := context.Background()
+	suite.repo.On("GetAuthRequest", ctx, "req-1").Return(&model.AuthRequest{
+		ID:             "req-1",
+		State:          model.AuthRequestStatePending,
+		WatchTokenHash: hashToken("watch"),
+	}, nil)
+	suite.repo.On("TransitAuthRequest", ctx, mock.MatchedBy(func(req *model.AuthRequest) bool {
+		return req.ID == "req-1" && req.State == model.AuthRequestStateDenied
+	}), model.AuthRequestStatePending).Return(true, nil)
+
+	err := suite.useCase.DenyAuthRequest(ctx, "req-1", "watch")
+
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *AuthRequestUseCaseTestSuite) TestDenyAuthRequest_SignedIn() {
+	ctx := model.NewPrincipalContext(context.Background(), &model.Principal{UserID: 7})
+	suite.repo.On("GetAuthRequest", ctx, "req-1").Return(&model.AuthRequest{
+		ID:             "req-1",
+		State:          model.AuthRequestStatePending,
+		WatchTokenHash: hashToken("watch"),
+	}, nil)
+	suite.repo.On("TransitAuthRequest", ctx, mock.Anything, model.AuthRequestStatePending).Return(true, nil)
+
+	err := suite.useCase.DenyAuthRequest(ctx, "req-1", "")
+
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *AuthRequestUseCaseTestSuite) TestDenyAuthRequest_Unauthorized() {
+	ctx := context.Background()
+	suite.repo.On("GetAuthRequest", ctx, "req-1").Return(&model.AuthRequest{
+		ID:             "req-1",
+		State:          model.AuthRequestStatePending,
+		WatchTokenHash: hashToken("watch"),
+	}, nil)
+
+	// 只知道请求ID的匿名调用方不能拒绝
+	err := suite.useCase.DenyAuthRequest(ctx, "req-1", "")
+	assert.Equal(suite.T(), connect.CodeUnauthenticated, connect.CodeOf(err))
+
+	err = suite.useCase.DenyAuthRequest(ctx, "req-1", "wrong")
+	assert.Equal(suite.T(), connect.CodePermissionDenied, connect.CodeOf(err))
+	suite.repo.AssertNotCalled(suite.T(), "TransitAuthRequest", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *AuthRequestUseCaseTestSuite) TestDenyAuthRequest_AlreadySettled() {
+	ctx := context.Background()
+	suite.repo.On("GetAuthRequest", ctx, "req-1").Return(&model.AuthRequest{
+		ID:             "req-1",
+		State:          model.AuthRequestStateApproved,
+		WatchTokenHash: hashToken("watch"),
+	}, nil)
+	suite.repo.On("TransitAuthRequest", ctx, mock.Anything, model.AuthRequestStatePending).Return(false, nil)
+
+	err := suite.useCase.DenyAuthRequest(ctx, "req-1", "watch")
+
+	assert.Equal(suite.T(), connect.CodeFailedPrecondition, connect.CodeOf(err))
+}
+
+func (suite *UserUseCaseTestSuite) TestSubmitAuth_ApprovesAuthRequest() {
+	ctx := context.Background()
+
+	suite.userRepo.On("GetAuthChallenge", ctx, "testuser").Return("challenge", nil)
+	storedHash, err := suite.useCase.hasher.Hash("hash")
+	assert.NoError(suite.T(), err)
+	suite.userRepo.On("GetUserByName", ctx, "testuser").Return(&model.User{
+		ID:           7,
+		Username:     "testuser",
+		PasswordHash: storedHash,
+	}, nil)
+	suite.authRequestRepo.On("TransitAuthRequest", ctx, mock.MatchedBy(func(req *model.AuthRequest) bool {
+		return req.ID == "req-1" &&
+			req.State == model.AuthRequestStateApproved &&
+			req.UserID == 7 &&
+			req.AuthToken != ""
+	}), model.AuthRequestStatePending).Return(true, nil)
+
+	result, err := suite.useCase.SubmitAuth(ctx, &model.SubmitAuthRequest{Username: "testuser", HashedCredential: "hash", AuthRequestID: "req-1", ChallengeResponse: computeChallengeResponse("challenge", "testuser")})
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), result.AuthToken)
+	suite.authRequestRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserUseCaseTestSuite) TestSubmitAuth_DPoPBound() {
+	ctx := model.NewProofKeyContext(context.Background(), "jkt-1")
+
+	suite.userRepo.On("GetAuthChallenge", ctx, "testuser").Return("challenge", nil)
+	storedHash, err := suite.useCase.hasher.Hash("hash")
+	assert.NoError(suite.T(), err)
+	suite.userRepo.On("GetUserByName", ctx, "testuser").Return(&model.User{
+		ID:           7,
+		TenantID:     2,
+		Username:     "testuser",
+		PasswordHash: storedHash,
+	}, nil)
+	var requestToken string
+	suite.authRequestRepo.On("TransitAuthRequest", ctx, mock.MatchedBy(func(req *model.AuthRequest) bool {
+		requestToken = req.AuthToken
+		return req.ID == "req-1"
+	}), model.AuthRequestStatePending).Return(true, nil)
+
+	result, err := suite.useCase.SubmitAuth(ctx, &model.SubmitAuthRequest{Username: "testuser", HashedCredential: "hash", AuthRequestID: "req-1", ChallengeResponse: computeChallengeResponse("challenge", "testuser")})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.TokenTypeDPoP, result.TokenType)
+	principal, err := suite.useCase.tokens.VerifyToken(ctx, result.AuthToken)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "jkt-1", principal.ProofKey)
+
+	// 发起方收到的令牌不绑定批准方的密钥
+	requester, err := suite.useCase.tokens.VerifyToken(ctx, requestToken)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), requester.ProofKey)
+	assert.NotEqual(suite.T(), principal.SessionID, requester.SessionID)
+}
+
+func (suite *UserUseCaseTestSuite) TestSubmitAuth_ApproveFailureDoesNotFailLogin() {
+	ctx := context.Background()
+
+	suite.userRepo.On("GetAuthChallenge", ctx, "testuser").Return("challenge", nil)
+	storedHash, err := suite.useCase.hasher.Hash("hash")
+	assert.NoError(suite.T(), err)
+	suite.userRepo.On("GetUserByName", ctx, "testuser").Return(&model.User{
+		ID:           7,
+		Username:     "testuser",
+		PasswordHash: storedHash,
+	}, nil)
+	suite.authRequestRepo.On("TransitAuthRequest", ctx, mock.Anything, model.AuthRequestStatePending).Return(false, errors.New("redis down"))
+
+	result, err := suite.useCase.SubmitAuth(ctx, &model.SubmitAuthRequest{Username: "testuser", HashedCredential: "hash", AuthRequestID: "req-1", ChallengeResponse: computeChallengeResponse("challenge", "testuser")})
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), result.AuthToken)
+}
+
+func TestAuthRequestUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthRequestUseCaseTestSuite))
+}
@@ -1,8 +1,27 @@
 package biz
 
-import "go.uber.org/fx"
+import (
+	"connect-go-example/internal/biz/model"
+
+	"go.uber.org/fx"
+)
 
 var Module = fx.Module("biz",
+	fx.Provide(NewTokenManager),
+	fx.Provide(NewDomainEvents),
+	fx.Provide(fx.Annotate(NewDPoPVerifier, fx.As(new(model.DPoPVerifier)))),
+	fx.Provide(fx.Annotate(NewSessionManager, fx.As(new(model.TokenVerifier)), fx.As(new(model.SessionUseCase)))),
+	fx.Provide(fx.Annotate(NewProofOfWork, fx.As(fx.Self()), fx.As(new(model.ProofOfWorkUseCase)))),
+	fx.Provide(NewCredentialHasher),
+	fx.Provide(NewCredentialBackends),
+	fx.Provide(fx.Annotate(NewLoginRisk, fx.As(fx.Self()), fx.As(new(model.LoginRiskUseCase)))),
 	fx.Provide(NewUserUseCase),
 	fx.Provide(NewCheckUseCase),
+	fx.Provide(NewAuthRequestUseCase),
+	fx.Provide(NewCrossDeviceLoginUseCase),
+	fx.Provide(NewMagicLinkUseCase),
+	fx.Provide(NewTenantUseCase),
+	fx.Provide(NewAdminUseCase),
+	fx.Provide(NewScimUseCase),
+	fx.Provide(NewFederationUseCase),
 )
@@ -8,6 +8,7 @@ import (
 
 	"connect-go-example/internal/biz/model"
 	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data"
 
 	"connectrpc.com/connect"
 	"github.com/golang-jwt/jwt/v5"
@@ -30,11 +31,50 @@ func (m *MockUserRepo) GetUserByName(ctx context.Context, username string) (*mod
 	return args.Get(0).(*model.User), args.Error(1)
 }
 
+func (m *MockUserRepo) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
 func (m *MockUserRepo) CreateUser(ctx context.Context, user *model.User) (int64, error) {
 	args := m.Called(ctx, user)
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockUserRepo) MarkEmailVerified(ctx context.Context, user *model.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) SyncDirectoryUser(ctx context.Context, user *model.DirectoryUser) (*model.User, error) {
+	args := m.Called(ctx, user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockUserRepo) GetUserRoles(ctx context.Context, userID int64) ([]string, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockUserRepo) UpdateCredential(ctx context.Context, user *model.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) SetCredentialBackend(ctx context.Context, username, backend string) error {
+	args := m.Called(ctx, username, backend)
+	return args.Error(0)
+}
+
 func (m *MockUserRepo) StoreAuthChallenge(ctx context.Context, username, challenge string, timeout time.Duration) error {
 	args := m.Called(ctx, username, challenge, timeout)
 	return args.Error(0)
@@ -45,6 +85,76 @@ func (m *MockUserRepo) GetAuthChallenge(ctx context.Context, username string) (s
 	return args.String(0), args.Error(1)
 }
 
+// MockTransactor 直接执行 fn，不开启事务
+type MockTransactor struct {
+	calls int
+}
+
+func (m *MockTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.calls++
+	return fn(ctx)
+}
+
+// MockOutboxRepo 记录写入发件箱的事件
+type MockOutboxRepo struct {
+	data.OutboxRepo
+	events []*model.DomainEvent
+	err    error
+}
+
+func (m *MockOutboxRepo) Append(_ context.Context, event *model.DomainEvent) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.events = append(m.events, event)
+	return nil
+}
+
+// types 按写入顺序返回事件类型
+func (m *MockOutboxRepo) types() []string {
+	var types []string
+	for _, event := range m.events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+// MockAuthRequestRepo 是 AuthRequestRepo 的模拟实现
+type MockAuthRequestRepo struct {
+	mock.Mock
+}
+
+func (m *MockAuthRequestRepo) CreateAuthRequest(ctx context.Context, req *model.AuthRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockAuthRequestRepo) GetAuthRequest(ctx context.Context, id string) (*model.AuthRequest, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AuthRequest), args.Error(1)
+}
+
+func (m *MockAuthRequestRepo) TransitAuthRequest(ctx context.Context, req *model.AuthRequest, from model.AuthRequestState) (bool, error) {
+	args := m.Called(ctx, req, from)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAuthRequestRepo) DeleteAuthRequest(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAuthRequestRepo) SubscribeAuthRequest(ctx context.Context, id string) (<-chan struct{}, func() error, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(<-chan struct{}), args.Get(1).(func() error), args.Error(2)
+}
+
 // MockCheckRepo 是 CheckRepo 的模拟实现
 type MockCheckRepo struct {
 	mock.Mock
@@ -58,13 +168,19 @@ func (m *MockCheckRepo) Ready(ctx context.Context, req model.HealthCheckReq) (mo
 // UserUseCaseTestSuite 是 UserUseCase 的测试套件
 type UserUseCaseTestSuite struct {
 	suite.Suite
-	userRepo *MockUserRepo
-	useCase  *UserUseCase
-	logger   *zap.Logger
+	userRepo        *MockUserRepo
+	authRequestRepo *MockAuthRequestRepo
+	inviteRepo      *MockInviteRepo
+	outbox          *MockOutboxRepo
+	useCase         *UserUseCase
+	logger          *zap.Logger
 }
 
 func (suite *UserUseCaseTestSuite) SetupTest() {
 	suite.userRepo = new(MockUserRepo)
+	suite.authRequestRepo = new(MockAuthRequestRepo)
+	suite.inviteRepo = new(MockInviteRepo)
+	suite.outbox = new(MockOutboxRepo)
 	suite.logger, _ = zap.NewDevelopment()
 
 	cfg := &conf.Bootstrap{
@@ -75,29 +191,42 @@ func (suite *UserUseCaseTestSuite) SetupTest() {
 		},
 	}
 
-	useCaseInterface, err := NewUserUseCase(suite.userRepo, cfg, suite.logger)
+	tokens, err := NewTokenManager(cfg, suite.logger)
+	assert.NoError(suite.T(), err)
+
+	pow, err := NewProofOfWork(new(MockPowRepo), tokens, cfg, suite.logger)
+	assert.NoError(suite.T(), err)
+
+	useCaseInterface, err := NewUserUseCase(suite.userRepo, suite.authRequestRepo, suite.inviteRepo, new(MockTransactor), tokens, newTestHasher(suite.T(), "pepper"), new(CredentialBackends), pow, newDisabledLoginRisk(), NewDomainEvents(suite.outbox, zap.NewNop()), cfg, suite.logger)
 	assert.NoError(suite.T(), err)
 	suite.useCase = useCaseInterface.(*UserUseCase)
 }
 
 func (suite *UserUseCaseTestSuite) TestNewUserUseCase() {
 	// 测试正常创建
-	useCase, err := NewUserUseCase(suite.userRepo, &conf.Bootstrap{
+	cfg := &conf.Bootstrap{
 		Auth: &conf.Auth{
 			JwtSecret: "test-secret",
 		},
-	}, suite.logger)
+	}
+	tokens, err := NewTokenManager(cfg, suite.logger)
+	assert.NoError(suite.T(), err)
+
+	pow, err := NewProofOfWork(new(MockPowRepo), tokens, cfg, suite.logger)
+	assert.NoError(suite.T(), err)
+
+	useCase, err := NewUserUseCase(suite.userRepo, suite.authRequestRepo, suite.inviteRepo, new(MockTransactor), tokens, newTestHasher(suite.T(), "pepper"), new(CredentialBackends), pow, newDisabledLoginRisk(), NewDomainEvents(new(MockOutboxRepo), zap.NewNop()), cfg, suite.logger)
 
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), useCase)
 
 	// 测试自动生成密钥
-	useCase2, err := NewUserUseCase(suite.userRepo, &conf.Bootstrap{
+	tokens2, err := NewTokenManager(&conf.Bootstrap{
 		Auth: &conf.Auth{},
 	}, suite.logger)
 
 	assert.NoError(suite.T(), err)
-	assert.NotNil(suite.T(), useCase2)
+	assert.Len(suite.T(), tokens2.secret, 32)
 }
 
 func (suite *UserUseCaseTestSuite) TestRegister_UserAlreadyExists() {
@@ -106,7 +235,7 @@ func (suite *UserUseCaseTestSuite) TestRegister_UserAlreadyExists() {
 	// 模拟用户已存在
 	suite.userRepo.On("GetUserByName", ctx, "existinguser").Return(&model.User{Username: "existinguser"}, nil)
 
-	userID, err := suite.useCase.Register(ctx, "existinguser", "hash", "email@test.com", "salt")
+	userID, err := suite.useCase.Register(ctx, &model.RegisterRequest{Username: "existinguser", PasswordHash: "hash", Email: "email@test.com", Salt: "salt"})
 
 	assert.Equal(suite.T(), "", userID)
 	assert.Error(suite.T(), err)
@@ -122,13 +251,31 @@ func (suite *UserUseCaseTestSuite) TestRegister_Success() {
 	suite.userRepo.On("GetUserByName", ctx, "newuser").Return(nil, errors.New("not found"))
 	suite.userRepo.On("CreateUser", ctx, mock.AnythingOfType("*model.User")).Return(int64(123), nil)
 
-	userID, err := suite.useCase.Register(ctx, "newuser", "passwordhash", "email@test.com", "salt")
+	userID, err := suite.useCase.Register(ctx, &model.RegisterRequest{Username: "newuser", PasswordHash: "passwordhash", Email: "email@test.com", Salt: "salt"})
 
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), "123", userID)
+	// 保存的是服务端哈希而不是客户端凭证
 	suite.userRepo.AssertCalled(suite.T(), "CreateUser", ctx, mock.MatchedBy(func(user *model.User) bool {
-		return user.Username == "newuser" && user.PasswordHash == "passwordhash"
+		ok, _ := suite.useCase.hasher.Verify("passwordhash", user.PasswordHash)
+		return user.Username == "newuser" && user.PasswordHash != "passwordhash" && ok
 	}))
+	assert.Equal(suite.T(), []string{model.EventUserRegistered}, suite.outbox.types())
+	assert.Equal(suite.T(), "123", suite.outbox.events[0].AggregateID)
+	// 事件中不包含邮箱
+	assert.JSONEq(suite.T(), `{"tenantId":0,"userId":123,"username":"newuser","source":"password"}`, string(suite.outbox.events[0].Payload))
+}
+
+func (suite *UserUseCaseTestSuite) TestRegister_OutboxFailure() {
+	ctx := context.Background()
+	suite.outbox.err = errors.New("outbox unavailable")
+	suite.userRepo.On("GetUserByName", ctx, "newuser").Return(nil, errors.New("not found"))
+	suite.userRepo.On("CreateUser", ctx, mock.AnythingOfType("*model.User")).Return(int64(123), nil)
+
+	// 事件写入失败时注册失败，事务回滚
+	_, err := suite.useCase.Register(ctx, &model.RegisterRequest{Username: "newuser", PasswordHash: "passwordhash", Salt: "salt"})
+
+	assert.Equal(suite.T(), connect.CodeInternal, connect.CodeOf(err))
 }
 
 func (suite *UserUseCaseTestSuite) TestGetAuthChallenge_UserNotFound() {
@@ -137,7 +284,7 @@ func (suite *UserUseCaseTestSuite) TestGetAuthChallenge_UserNotFound() {
 	// 模拟用户不存在
 	suite.userRepo.On("GetUserByName", ctx, "nonexistent").Return(nil, errors.New("not found"))
 
-	challenge, err := suite.useCase.GetAuthChallenge(ctx, "nonexistent")
+	challenge, err := suite.useCase.GetAuthChallenge(ctx, "nonexistent", nil)
 
 	assert.Nil(suite.T(), challenge)
 	assert.Error(suite.T(), err)
@@ -154,7 +301,7 @@ func (suite *UserUseCaseTestSuite) TestGetAuthChallenge_Success() {
 	}, nil)
 	suite.userRepo.On("StoreAuthChallenge", ctx, "testuser", mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil)
 
-	challenge, err := suite.useCase.GetAuthChallenge(ctx, "testuser")
+	challenge, err := suite.useCase.GetAuthChallenge(ctx, "testuser", nil)
 
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), challenge)
@@ -170,15 +317,65 @@ func (suite *UserUseCaseTestSuite) TestSubmitAuth_InvalidChallenge() {
 	// 模拟挑战不存在
 	suite.userRepo.On("GetAuthChallenge", ctx, "testuser").Return("", errors.New("not found"))
 
-	result, err := suite.useCase.SubmitAuth(ctx, "testuser", "hash", "req123", "response")
+	result, err := suite.useCase.SubmitAuth(ctx, &model.SubmitAuthRequest{Username: "testuser", HashedCredential: "hash", AuthRequestID: "req123", ChallengeResponse: "response"})
 
 	assert.Nil(suite.T(), result)
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), "invalid or expired challenge", err.Error())
 }
 
+func (suite *UserUseCaseTestSuite) TestSubmitAuth_UpgradesLegacyHash() {
+	ctx := context.Background()
+
+	// 旧数据直接保存客户端凭证，登录成功后升级为服务端哈希
+	suite.userRepo.On("GetAuthChallenge", ctx, "testuser").Return("challenge", nil)
+	suite.userRepo.On("GetUserByName", ctx, "testuser").Return(&model.User{ID: 7, Username: "testuser", PasswordHash: "hash", Salt: "salt"}, nil)
+	suite.userRepo.On("UpdateCredential", ctx, mock.AnythingOfType("*model.User")).Return(nil)
+
+	result, err := suite.useCase.SubmitAuth(ctx, &model.SubmitAuthRequest{Username: "testuser", HashedCredential: "hash", ChallengeResponse: computeChallengeResponse("challenge", "testuser")})
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), result.AuthToken)
+	suite.userRepo.AssertCalled(suite.T(), "UpdateCredential", ctx, mock.MatchedBy(func(user *model.User) bool {
+		ok, needsRehash := suite.useCase.hasher.Verify("hash", user.PasswordHash)
+		return ok && !needsRehash && user.ID == 7 && user.Salt == "salt" && user.KdfVersion == 0
+	}))
+	assert.Equal(suite.T(), []string{model.EventPasswordChanged, model.EventUserLoggedIn}, suite.outbox.types())
+}
+
+func (suite *UserUseCaseTestSuite) TestSubmitAuth_WrongCredential() {
+	ctx := context.Background()
+	storedHash, err := suite.useCase.hasher.Hash("hash")
+	assert.NoError(suite.T(), err)
+
+	suite.userRepo.On("GetAuthChallenge", ctx, "testuser").Return("challenge", nil)
+	suite.userRepo.On("GetUserByName", ctx, "testuser").Return(&model.User{ID: 7, Username: "testuser", PasswordHash: storedHash}, nil)
+
+	// 数据库中的哈希值本身不能用于登录
+	result, err := suite.useCase.SubmitAuth(ctx, &model.SubmitAuthRequest{Username: "testuser", HashedCredential: storedHash, ChallengeResponse: computeChallengeResponse("challenge", "testuser")})
+
+	assert.Nil(suite.T(), result)
+	assert.EqualError(suite.T(), err, "authentication failed")
+	suite.userRepo.AssertNotCalled(suite.T(), "UpdateCredential", mock.Anything, mock.Anything)
+}
+
+func (suite *UserUseCaseTestSuite) TestSubmitAuth_DisabledUser() {
+	ctx := context.Background()
+	storedHash, err := suite.useCase.hasher.Hash("hash")
+	assert.NoError(suite.T(), err)
+
+	// SCIM 停用的用户凭证正确也不能登录
+	suite.userRepo.On("GetAuthChallenge", ctx, "testuser").Return("challenge", nil)
+	suite.userRepo.On("GetUserByName", ctx, "testuser").Return(&model.User{ID: 7, Username: "testuser", PasswordHash: storedHash, Disabled: true}, nil)
+
+	result, err := suite.useCase.SubmitAuth(ctx, &model.SubmitAuthRequest{Username: "testuser", HashedCredential: "hash", ChallengeResponse: computeChallengeResponse("challenge", "testuser")})
+
+	assert.Nil(suite.T(), result)
+	assert.EqualError(suite.T(), err, "authentication failed")
+}
+
 func (suite *UserUseCaseTestSuite) TestGenerateJWT() {
-	token, err := suite.useCase.generateJWT(123, "testuser")
+	token, err := suite.useCase.generateJWT(2, 123, "testuser", "")
 
 	assert.NoError(suite.T(), err)
 	assert.NotEmpty(suite.T(), token)
@@ -193,6 +390,7 @@ func (suite *UserUseCaseTestSuite) TestGenerateJWT() {
 
 	claims := parsedToken.Claims.(jwt.MapClaims)
 	assert.Equal(suite.T(), float64(123), claims["sub"])
+	assert.Equal(suite.T(), float64(2), claims["tid"])
 	assert.Equal(suite.T(), "testuser", claims["usr"])
 }
 
@@ -0,0 +1,144 @@
+package biz
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	conf "connect-go-example/internal/conf/v1"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/argon2"
+)
+
+const argon2Prefix = "$argon2id$"
+
+// CredentialHasher 在服务端对客户端提交的凭证做 pepper + argon2id 哈希，
+// 数据库泄露后无法直接用其中的值登录
+type CredentialHasher struct {
+	pepper      []byte
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	dummy       string // 用户不存在时用于校验的哈希，保持耗时一致
+	l           *zap.Logger
+}
+
+func NewCredentialHasher(cfg *conf.Bootstrap, logger *zap.Logger) (*CredentialHasher, error) {
+	pepper, err := loadPepper(cfg.Auth)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &CredentialHasher{
+		pepper:      pepper,
+		memory:      64 * 1024, // 默认 64MiB
+		iterations:  3,
+		parallelism: 2,
+		l:           logger,
+	}
+	if cfg.Auth.Argon2MemoryKib > 0 {
+		h.memory = cfg.Auth.Argon2MemoryKib
+	}
+	if cfg.Auth.Argon2Iterations > 0 {
+		h.iterations = cfg.Auth.Argon2Iterations
+	}
+	if cfg.Auth.Argon2Parallelism > 0 {
+		if cfg.Auth.Argon2Parallelism > 255 {
+			return nil, fmt.Errorf("invalid auth.argon2_parallelism: %d", cfg.Auth.Argon2Parallelism)
+		}
+		h.parallelism = uint8(cfg.Auth.Argon2Parallelism)
+	}
+	if h.dummy, err = h.Hash("dummy"); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func loadPepper(cfg *conf.Auth) ([]byte, error) {
+	if cfg.CredentialPepperFile != "" {
+		pepper, err := os.ReadFile(cfg.CredentialPepperFile)
+		if err != nil {
+			return nil, fmt.Errorf("read auth.credential_pepper_file failed: %v", err)
+		}
+		return []byte(strings.TrimSpace(string(pepper))), nil
+	}
+	if pepper := os.Getenv("CREDENTIAL_PEPPER"); pepper != "" {
+		return []byte(pepper), nil
+	}
+	if cfg.CredentialPepper != "" {
+		return []byte(cfg.CredentialPepper), nil
+	}
+	// pepper 丢失后所有凭证都无法校验，不能像 JWT 密钥一样自动生成
+	return nil, errors.New("credential pepper is required: set auth.credential_pepper_file, CREDENTIAL_PEPPER or auth.credential_pepper")
+}
+
+// Hash 返回 PHC 格式的哈希：$argon2id$v=19$m=<内存>,t=<迭代>,p=<并行度>$<盐>$<哈希>
+func (h *CredentialHasher) Hash(credential string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generate credential salt failed: %v", err)
+	}
+	key := argon2.IDKey(h.peppered(credential), salt, h.iterations, h.memory, h.parallelism, 32)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2Prefix, argon2.Version, h.memory, h.iterations, h.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify 校验凭证，needsRehash 表示存储值是旧格式或参数已变化，需要在登录成功后升级
+func (h *CredentialHasher) Verify(credential, stored string) (ok, needsRehash bool) {
+	if stored == "" {
+		// SCIM 下发的用户没有凭证
+		h.VerifyDummy(credential)
+		return false, false
+	}
+	if !strings.HasPrefix(stored, argon2Prefix) {
+		// 旧数据直接存放客户端凭证
+		return constantTimeCompare(credential, stored), true
+	}
+
+	var version int
+	var memory, iterations uint32
+	var parallelism uint8
+	parts := strings.Split(stored, "$")
+	if len(parts) != 6 {
+		return false, false
+	}
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, false
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &parallelism); err != nil {
+		return false, false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false
+	}
+	expected, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, false
+	}
+
+	key := argon2.IDKey(h.peppered(credential), salt, iterations, memory, parallelism, uint32(len(expected)))
+	if subtle.ConstantTimeCompare(key, expected) != 1 {
+		return false, false
+	}
+	return true, memory != h.memory || iterations != h.iterations || parallelism != h.parallelism
+}
+
+// VerifyDummy 用户不存在时调用，避免通过响应时间判断用户是否存在
+func (h *CredentialHasher) VerifyDummy(credential string) {
+	h.Verify(credential, h.dummy)
+}
+
+// peppered 先用 pepper 做 HMAC，argon2 的输入不包含客户端凭证原文
+func (h *CredentialHasher) peppered(credential string) []byte {
+	mac := hmac.New(sha256.New, h.pepper)
+	mac.Write([]byte(credential))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,104 @@
+package biz
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	conf "connect-go-example/internal/conf/v1"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// newTestHasher 使用较低的参数，避免测试过慢
+func newTestHasher(t *testing.T, pepper string) *CredentialHasher {
+	logger, _ := zap.NewDevelopment()
+	hasher, err := NewCredentialHasher(&conf.Bootstrap{Auth: &conf.Auth{
+		CredentialPepper:  pepper,
+		Argon2MemoryKib:   64,
+		Argon2Iterations:  1,
+		Argon2Parallelism: 1,
+	}}, logger)
+	assert.NoError(t, err)
+	return hasher
+}
+
+func TestCredentialHasher(t *testing.T) {
+	hasher := newTestHasher(t, "pepper")
+
+	stored, err := hasher.Hash("client-credential")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(stored, "$argon2id$v=19$m=64,t=1,p=1$"))
+	assert.NotContains(t, stored, "client-credential")
+
+	ok, needsRehash := hasher.Verify("client-credential", stored)
+	assert.True(t, ok)
+	assert.False(t, needsRehash)
+
+	ok, _ = hasher.Verify("wrong", stored)
+	assert.False(t, ok)
+
+	// 相同凭证每次哈希结果不同
+	other, err := hasher.Hash("client-credential")
+	assert.NoError(t, err)
+	assert.NotEqual(t, stored, other)
+
+	// pepper 不同无法校验
+	ok, _ = newTestHasher(t, "other-pepper").Verify("client-credential", stored)
+	assert.False(t, ok)
+
+	// 格式错误
+	ok, _ = hasher.Verify("client-credential", "$argon2id$v=19$broken")
+	assert.False(t, ok)
+
+	// SCIM 下发的用户没有凭证，空凭证也不能通过
+	ok, needsRehash = hasher.Verify("", "")
+	assert.False(t, ok)
+	assert.False(t, needsRehash)
+}
+
+func TestCredentialHasher_NeedsRehash(t *testing.T) {
+	hasher := newTestHasher(t, "pepper")
+
+	// 旧数据为客户端凭证原文
+	ok, needsRehash := hasher.Verify("client-credential", "client-credential")
+	assert.True(t, ok)
+	assert.True(t, needsRehash)
+
+	// 参数提高后需要升级
+	logger, _ := zap.NewDevelopment()
+	stronger, err := NewCredentialHasher(&conf.Bootstrap{Auth: &conf.Auth{
+		CredentialPepper:  "pepper",
+		Argon2MemoryKib:   128,
+		Argon2Iterations:  1,
+		Argon2Parallelism: 1,
+	}}, logger)
+	assert.NoError(t, err)
+	stored, err := hasher.Hash("client-credential")
+	assert.NoError(t, err)
+	ok, needsRehash = stronger.Verify("client-credential", stored)
+	assert.True(t, ok)
+	assert.True(t, needsRehash)
+}
+
+func TestLoadPepper(t *testing.T) {
+	_, err := loadPepper(&conf.Auth{})
+	assert.Error(t, err)
+
+	pepper, err := loadPepper(&conf.Auth{CredentialPepper: "from-config"})
+	assert.NoError(t, err)
+	assert.Equal(t, "from-config", string(pepper))
+
+	t.Setenv("CREDENTIAL_PEPPER", "from-env")
+	pepper, err = loadPepper(&conf.Auth{CredentialPepper: "from-config"})
+	assert.NoError(t, err)
+	assert.Equal(t, "from-env", string(pepper))
+
+	file := filepath.Join(t.TempDir(), "pepper")
+	assert.NoError(t, os.WriteFile(file, []byte("from-file\n"), 0o600))
+	pepper, err = loadPepper(&conf.Auth{CredentialPepperFile: file, CredentialPepper: "from-config"})
+	assert.NoError(t, err)
+	assert.Equal(t, "from-file", string(pepper))
+}
@@ -0,0 +1,171 @@
+package biz
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data"
+
+	"connectrpc.com/connect"
+	"go.uber.org/zap"
+)
+
+// crossDeviceCodeAlphabet 去掉了容易混淆的 0/O、1/I，长度为32避免取模偏差
+const crossDeviceCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const crossDeviceCodeLength = 8
+
+type CrossDeviceLoginUseCase struct {
+	repo         data.CrossDeviceLoginRepo
+	authRequests data.AuthRequestRepo
+	tokens       *TokenManager
+	cfg          *conf.Auth
+	l            *zap.Logger
+}
+
+func NewCrossDeviceLoginUseCase(repo data.CrossDeviceLoginRepo, authRequests data.AuthRequestRepo, tokens *TokenManager, cfg *conf.Bootstrap, logger *zap.Logger) (model.CrossDeviceLoginUseCase, error) {
+	if cfg.Auth.CrossDeviceApproveUrl != "" {
+		if _, err := url.Parse(cfg.Auth.CrossDeviceApproveUrl); err != nil {
+			return nil, fmt.Errorf("invalid auth.cross_device_approve_url: %v", err)
+		}
+	}
+
+	return &CrossDeviceLoginUseCase{
+		repo:         repo,
+		authRequests: authRequests,
+		tokens:       tokens,
+		cfg:          cfg.Auth,
+		l:            logger,
+	}, nil
+}
+
+func (uc *CrossDeviceLoginUseCase) CreateCrossDeviceLogin(ctx context.Context, clientName string, client model.ClientInfo) (*model.CrossDeviceLoginTicket, error) {
+	timeout := time.Duration(uc.cfg.CrossDeviceLoginTimeoutSeconds) * time.Second
+	if timeout == 0 {
+		timeout = 2 * time.Minute // 默认2分钟
+	}
+
+	authReq, watchToken, err := newAuthRequest(clientName, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.authRequests.CreateAuthRequest(ctx, authReq); err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	login := &model.CrossDeviceLogin{
+		AuthRequestID: authReq.ID,
+		ClientName:    clientName,
+		Requester:     client,
+		CreatedAt:     authReq.CreatedAt,
+		ExpiresAt:     authReq.ExpiresAt,
+	}
+	// 短码空间较小，冲突时重新生成
+	for attempt := 0; ; attempt++ {
+		if attempt == 5 {
+			return nil, connect.NewError(connect.CodeInternal, errors.New("allocate cross-device login code failed"))
+		}
+		if login.Code, err = newCrossDeviceCode(); err != nil {
+			return nil, err
+		}
+		created, err := uc.repo.CreateCrossDeviceLogin(ctx, login)
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInternal, err)
+		}
+		if created {
+			break
+		}
+	}
+
+	return &model.CrossDeviceLoginTicket{
+		Code:       login.Code,
+		ApproveURL: uc.approveURL(login.Code),
+		AuthRequest: model.AuthRequestTicket{
+			ID:         authReq.ID,
+			WatchToken: watchToken,
+			ExpiresAt:  authReq.ExpiresAt,
+		},
+	}, nil
+}
+
+func (uc *CrossDeviceLoginUseCase) GetCrossDeviceLogin(ctx context.Context, code string) (*model.CrossDeviceLogin, error) {
+	login, err := uc.repo.GetCrossDeviceLogin(ctx, code)
+	if err != nil {
+		if errors.Is(err, model.ErrCrossDeviceLoginNotFound) {
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		}
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	return login, nil
+}
+
+func (uc *CrossDeviceLoginUseCase) ApproveCrossDeviceLogin(ctx context.Context, approver *model.Principal, code string, approve bool) (*model.CrossDeviceLogin, error) {
+	login, err := uc.GetCrossDeviceLogin(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	next := &model.AuthRequest{
+		ID:    login.AuthRequestID,
+		State: model.AuthRequestStateDenied,
+	}
+	if approve {
+		token, err := uc.tokens.Issue(approver.TenantID, approver.UserID, approver.Username)
+		if err != nil {
+			return nil, fmt.Errorf("generate token failed: %v", err)
+		}
+		next.State = model.AuthRequestStateApproved
+		next.UserID = approver.UserID
+		next.AuthToken = token
+	}
+
+	ok, err := uc.authRequests.TransitAuthRequest(ctx, next, model.AuthRequestStatePending)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	if !ok {
+		return nil, connect.NewError(connect.CodeFailedPrecondition, model.ErrAuthRequestSettled)
+	}
+
+	// 短码只能使用一次
+	if err := uc.repo.DeleteCrossDeviceLogin(ctx, code); err != nil {
+		uc.l.Warn("delete cross-device login failed", zap.String("code", code), zap.Error(err))
+	}
+	uc.l.Info("cross-device login settled",
+		zap.Int64("approver_id", approver.UserID),
+		zap.Bool("approved", approve),
+		zap.String("requester_ip", login.Requester.IP),
+	)
+
+	return login, nil
+}
+
+// approveURL 生成二维码中的地址，未配置时由客户端自行拼接
+func (uc *CrossDeviceLoginUseCase) approveURL(code string) string {
+	if uc.cfg.CrossDeviceApproveUrl == "" {
+		return ""
+	}
+	u, _ := url.Parse(uc.cfg.CrossDeviceApproveUrl)
+	query := u.Query()
+	query.Set("code", code)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func newCrossDeviceCode() (string, error) {
+	raw := make([]byte, crossDeviceCodeLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("generate cross-device login code failed: %v", err)
+	}
+	code := make([]byte, crossDeviceCodeLength)
+	for i, b := range raw {
+		code[i] = crossDeviceCodeAlphabet[int(b)%len(crossDeviceCodeAlphabet)]
+	}
+	return string(code), nil
+}
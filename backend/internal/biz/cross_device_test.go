@@ -0,0 +1,198 @@
+package biz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+
+	"connectrpc.com/connect"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+// MockCrossDeviceLoginRepo 是 CrossDeviceLoginRepo 的模拟实现
+type MockCrossDeviceLoginRepo struct {
+	mock.Mock
+}
+
+func (m *MockCrossDeviceLoginRepo) CreateCrossDeviceLogin(ctx context.Context, login *model.CrossDeviceLogin) (bool, error) {
+	args := m.Called(ctx, login)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCrossDeviceLoginRepo) GetCrossDeviceLogin(ctx context.Context, code string) (*model.CrossDeviceLogin, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CrossDeviceLogin), args.Error(1)
+}
+
+func (m *MockCrossDeviceLoginRepo) DeleteCrossDeviceLogin(ctx context.Context, code string) error {
+	args := m.Called(ctx, code)
+	return args.Error(0)
+}
+
+// CrossDeviceLoginUseCaseTestSuite 是 CrossDeviceLoginUseCase 的测试套件
+type CrossDeviceLoginUseCaseTestSuite struct {
+	suite.Suite
+	repo         *MockCrossDeviceLoginRepo
+	authRequests *MockAuthRequestRepo
+	tokens       *TokenManager
+	useCase      *CrossDeviceLoginUseCase
+}
+
+func (suite *CrossDeviceLoginUseCaseTestSuite) SetupTest() {
+	suite.repo = new(MockCrossDeviceLoginRepo)
+	suite.authRequests = new(MockAuthRequestRepo)
+	logger, _ := zap.NewDevelopment()
+
+	cfg := &conf.Bootstrap{
+		Auth: &conf.Auth{
+			JwtSecret:                      "test-secret",
+			CrossDeviceLoginTimeoutSeconds: 60,
+			CrossDeviceApproveUrl:          "https://example.com/approve",
+		},
+	}
+	tokens, err := NewTokenManager(cfg, logger)
+	assert.NoError(suite.T(), err)
+	suite.tokens = tokens
+
+	useCase, err := NewCrossDeviceLoginUseCase(suite.repo, suite.authRequests, tokens, cfg, logger)
+	assert.NoError(suite.T(), err)
+	suite.useCase = useCase.(*CrossDeviceLoginUseCase)
+}
+
+func (suite *CrossDeviceLoginUseCaseTestSuite) TestCreateCrossDeviceLogin() {
+	ctx := context.Background()
+	client := model.ClientInfo{IP: "203.0.113.7", UserAgent: "desktop/1.0", LocationHint: "CN"}
+
+	suite.authRequests.On("CreateAuthRequest", ctx, mock.AnythingOfType("*model.AuthRequest")).Return(nil)
+	// 第一次短码冲突，第二次成功
+	suite.repo.On("CreateCrossDeviceLogin", ctx, mock.AnythingOfType("*model.CrossDeviceLogin")).Return(false, nil).Once()
+	suite.repo.On("CreateCrossDeviceLogin", ctx, mock.AnythingOfType("*model.CrossDeviceLogin")).Return(true, nil).Once()
+
+	ticket, err := suite.useCase.CreateCrossDeviceLogin(ctx, "desktop", client)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), ticket.Code, crossDeviceCodeLength)
+	assert.Equal(suite.T(), "https://example.com/approve?code="+ticket.Code, ticket.ApproveURL)
+	assert.NotEmpty(suite.T(), ticket.AuthRequest.WatchToken)
+	suite.repo.AssertNumberOfCalls(suite.T(), "CreateCrossDeviceLogin", 2)
+	suite.repo.AssertCalled(suite.T(), "CreateCrossDeviceLogin", ctx, mock.MatchedBy(func(login *model.CrossDeviceLogin) bool {
+		return login.Code == ticket.Code &&
+			login.AuthRequestID == ticket.AuthRequest.ID &&
+			login.Requester == client &&
+			login.ExpiresAt.Sub(login.CreatedAt) == time.Minute
+	}))
+}
+
+func (suite *CrossDeviceLoginUseCaseTestSuite) TestGetCrossDeviceLogin_NotFound() {
+	ctx := context.Background()
+	suite.repo.On("GetCrossDeviceLogin", ctx, "MISSING1").Return(nil, model.ErrCrossDeviceLoginNotFound)
+
+	_, err := suite.useCase.GetCrossDeviceLogin(ctx, "MISSING1")
+
+	assert.Equal(suite.T(), connect.CodeNotFound, connect.CodeOf(err))
+}
+
+func (suite *CrossDeviceLoginUseCaseTestSuite) TestApproveCrossDeviceLogin() {
+	ctx := context.Background()
+	approver := &model.Principal{TenantID: 2, UserID: 7, Username: "testuser"}
+	suite.repo.On("GetCrossDeviceLogin", ctx, "ABCD2345").Return(&model.CrossDeviceLogin{
+		Code:          "ABCD2345",
+		AuthRequestID: "req-1",
+	}, nil)
+	suite.authRequests.On("TransitAuthRequest", ctx, mock.MatchedBy(func(req *model.AuthRequest) bool {
+		if req.ID != "req-1" || req.State != model.AuthRequestStateApproved || req.UserID != 7 {
+			return false
+		}
+		// 发起设备拿到的是批准人的令牌
+		principal, err := suite.tokens.VerifyToken(ctx, req.AuthToken)
+		return err == nil && principal.TenantID == 2 && principal.UserID == 7 && principal.Username == "testuser"
+	}), model.AuthRequestStatePending).Return(true, nil)
+	suite.repo.On("DeleteCrossDeviceLogin", ctx, "ABCD2345").Return(nil)
+
+	login, err := suite.useCase.ApproveCrossDeviceLogin(ctx, approver, "ABCD2345", true)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "req-1", login.AuthRequestID)
+	suite.authRequests.AssertExpectations(suite.T())
+	suite.repo.AssertCalled(suite.T(), "DeleteCrossDeviceLogin", ctx, "ABCD2345")
+}
+
+func (suite *CrossDeviceLoginUseCaseTestSuite) TestApproveCrossDeviceLogin_Deny() {
+	ctx := context.Background()
+	suite.repo.On("GetCrossDeviceLogin", ctx, "ABCD2345").Return(&model.CrossDeviceLogin{
+		Code:          "ABCD2345",
+		AuthRequestID: "req-1",
+	}, nil)
+	suite.authRequests.On("TransitAuthRequest", ctx, mock.MatchedBy(func(req *model.AuthRequest) bool {
+		return req.State == model.AuthRequestStateDenied && req.AuthToken == ""
+	}), model.AuthRequestStatePending).Return(true, nil)
+	suite.repo.On("DeleteCrossDeviceLogin", ctx, "ABCD2345").Return(nil)
+
+	_, err := suite.useCase.ApproveCrossDeviceLogin(ctx, &model.Principal{UserID: 7}, "ABCD2345", false)
+
+	assert.NoError(suite.T(), err)
+	suite.authRequests.AssertExpectations(suite.T())
+}
+
+func (suite *CrossDeviceLoginUseCaseTestSuite) TestApproveCrossDeviceLogin_AlreadySettled() {
+	ctx := context.Background()
+	suite.repo.On("GetCrossDeviceLogin", ctx, "ABCD2345").Return(&model.CrossDeviceLogin{
+		Code:          "ABCD2345",
+		AuthRequestID: "req-1",
+	}, nil)
+	suite.authRequests.On("TransitAuthRequest", ctx, mock.Anything, model.AuthRequestStatePending).Return(false, nil)
+
+	_, err := suite.useCase.ApproveCrossDeviceLogin(ctx, &model.Principal{UserID: 7}, "ABCD2345", true)
+
+	assert.Equal(suite.T(), connect.CodeFailedPrecondition, connect.CodeOf(err))
+	suite.repo.AssertNotCalled(suite.T(), "DeleteCrossDeviceLogin", mock.Anything, mock.Anything)
+}
+
+func TestTokenManager_VerifyToken(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	tokens, err := NewTokenManager(&conf.Bootstrap{Auth: &conf.Auth{JwtSecret: "test-secret"}}, logger)
+	assert.NoError(t, err)
+
+	token, err := tokens.Issue(2, 7, "testuser")
+	assert.NoError(t, err)
+
+	principal, err := tokens.VerifyToken(context.Background(), token)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), principal.TenantID)
+	assert.Equal(t, int64(7), principal.UserID)
+	assert.Equal(t, "testuser", principal.Username)
+	assert.True(t, principal.ExpiresAt.After(time.Now()))
+	assert.NotEmpty(t, principal.TokenID)
+	assert.NotEmpty(t, principal.SessionID)
+	assert.Empty(t, principal.ProofKey)
+
+	// 其他密钥签发的令牌无效
+	other, err := NewTokenManager(&conf.Bootstrap{Auth: &conf.Auth{JwtSecret: "other-secret"}}, logger)
+	assert.NoError(t, err)
+	_, err = other.VerifyToken(context.Background(), token)
+	assert.ErrorIs(t, err, errInvalidToken)
+
+	// 不带租户的令牌无效
+	noTenant, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": 7,
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("test-secret"))
+	assert.NoError(t, err)
+	_, err = tokens.VerifyToken(context.Background(), noTenant)
+	assert.ErrorIs(t, err, errInvalidToken)
+}
+
+func TestCrossDeviceLoginUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(CrossDeviceLoginUseCaseTestSuite))
+}
@@ -0,0 +1,142 @@
+package biz
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data"
+	"connect-go-example/internal/pkg/jwk"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// dpopClockSkew 允许客户端时钟超前的时间
+const dpopClockSkew = 5 * time.Second
+
+// dpopAlgorithms 支持的证明签名算法，只接受非对称算法
+var dpopAlgorithms = []string{"ES256", "EdDSA", "RS256"}
+
+// DPoPVerifier 校验 DPoP 证明（RFC 9449）
+type DPoPVerifier struct {
+	repo      data.DPoPRepo
+	maxAge    time.Duration
+	publicURL *url.URL
+	l         *zap.Logger
+}
+
+var _ model.DPoPVerifier = (*DPoPVerifier)(nil)
+
+func NewDPoPVerifier(repo data.DPoPRepo, cfg *conf.Bootstrap, logger *zap.Logger) (*DPoPVerifier, error) {
+	v := &DPoPVerifier{
+		repo:   repo,
+		maxAge: time.Minute, // 默认60秒
+		l:      logger,
+	}
+
+	if cfg.Dpop != nil {
+		if cfg.Dpop.ProofMaxAgeSeconds > 0 {
+			v.maxAge = time.Duration(cfg.Dpop.ProofMaxAgeSeconds) * time.Second
+		}
+		if cfg.Dpop.PublicUrl != "" {
+			u, err := url.Parse(cfg.Dpop.PublicUrl)
+			if err != nil || u.Scheme == "" || u.Host == "" {
+				return nil, fmt.Errorf("invalid dpop public_url %q", cfg.Dpop.PublicUrl)
+			}
+			v.publicURL = u
+		}
+	}
+	return v, nil
+}
+
+func (v *DPoPVerifier) VerifyProof(ctx context.Context, proof *model.DPoPProof) (string, error) {
+	jkt, claims, err := parseDPoPProof(proof.Proof)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", model.ErrInvalidDPoPProof, err)
+	}
+
+	if htm, _ := claims["htm"].(string); htm != proof.Method {
+		return "", fmt.Errorf("%w: htm mismatch", model.ErrInvalidDPoPProof)
+	}
+	if htu, _ := claims["htu"].(string); !v.matchURI(htu, proof.Path) {
+		return "", fmt.Errorf("%w: htu mismatch", model.ErrInvalidDPoPProof)
+	}
+
+	iat, err := claims.GetIssuedAt()
+	if err != nil || iat == nil {
+		return "", fmt.Errorf("%w: missing iat", model.ErrInvalidDPoPProof)
+	}
+	now := time.Now()
+	if iat.Before(now.Add(-v.maxAge)) || iat.After(now.Add(dpopClockSkew)) {
+		return "", fmt.Errorf("%w: iat out of range", model.ErrInvalidDPoPProof)
+	}
+
+	// 携带访问令牌时证明必须包含令牌的哈希，防止证明被用于其他令牌
+	if proof.AccessToken != "" {
+		sum := sha256.Sum256([]byte(proof.AccessToken))
+		if ath, _ := claims["ath"].(string); ath != base64.RawURLEncoding.EncodeToString(sum[:]) {
+			return "", fmt.Errorf("%w: ath mismatch", model.ErrInvalidDPoPProof)
+		}
+	}
+
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return "", fmt.Errorf("%w: missing jti", model.ErrInvalidDPoPProof)
+	}
+	// 证明过了时效就会被拒绝，记录保留到那时即可
+	first, err := v.repo.UseProofID(ctx, hashToken(jkt+":"+jti), v.maxAge+dpopClockSkew)
+	if err != nil {
+		return "", err
+	}
+	if !first {
+		return "", fmt.Errorf("%w: replayed", model.ErrInvalidDPoPProof)
+	}
+	return jkt, nil
+}
+
+// matchURI 比较 htu 与请求地址，忽略查询参数和片段
+func (v *DPoPVerifier) matchURI(htu, path string) bool {
+	u, err := url.Parse(htu)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if v.publicURL == nil {
+		return u.Path == path
+	}
+	return strings.EqualFold(u.Scheme, v.publicURL.Scheme) &&
+		strings.EqualFold(u.Host, v.publicURL.Host) &&
+		u.Path == strings.TrimSuffix(v.publicURL.Path, "/")+path
+}
+
+// parseDPoPProof 使用证明头部携带的公钥校验签名，返回公钥指纹和声明
+func parseDPoPProof(proof string) (string, jwt.MapClaims, error) {
+	var jkt string
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(proof, claims, func(token *jwt.Token) (interface{}, error) {
+		if typ, _ := token.Header["typ"].(string); typ != "dpop+jwt" {
+			return nil, errors.New("typ must be dpop+jwt")
+		}
+		header, ok := token.Header["jwk"].(map[string]any)
+		if !ok {
+			return nil, errors.New("missing jwk")
+		}
+		key, thumbprint, err := jwk.Parse(header)
+		if err != nil {
+			return nil, err
+		}
+		jkt = thumbprint
+		return key, nil
+	}, jwt.WithValidMethods(dpopAlgorithms))
+	if err != nil {
+		return "", nil, err
+	}
+	return jkt, claims, nil
+}
@@ -0,0 +1,234 @@
+package biz
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/pkg/jwk"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+// MockDPoPRepo 是 DPoPRepo 的模拟实现
+type MockDPoPRepo struct {
+	mock.Mock
+}
+
+func (m *MockDPoPRepo) UseProofID(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	args := m.Called(ctx, id, ttl)
+	return args.Bool(0), args.Error(1)
+}
+
+// dpopKey 测试用的客户端密钥
+type dpopKey struct {
+	method jwt.SigningMethod
+	signer crypto.Signer
+	jwk    map[string]any
+}
+
+func newES256Key(t *testing.T) *dpopKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	raw, err := key.PublicKey.Bytes()
+	assert.NoError(t, err)
+	return &dpopKey{
+		method: jwt.SigningMethodES256,
+		signer: key,
+		jwk: map[string]any{
+			"kty": "EC",
+			"crv": "P-256",
+			"x":   base64.RawURLEncoding.EncodeToString(raw[1:33]),
+			"y":   base64.RawURLEncoding.EncodeToString(raw[33:]),
+		},
+	}
+}
+
+func newEdDSAKey(t *testing.T) *dpopKey {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	return &dpopKey{
+		method: jwt.SigningMethodEdDSA,
+		signer: priv,
+		jwk: map[string]any{
+			"kty": "OKP",
+			"crv": "Ed25519",
+			"x":   base64.RawURLEncoding.EncodeToString(pub),
+		},
+	}
+}
+
+// proof 签发证明，overrides 覆盖默认声明
+func (k *dpopKey) proof(t *testing.T, method, htu string, overrides jwt.MapClaims) string {
+	claims := jwt.MapClaims{
+		"jti": rand.Text(),
+		"htm": method,
+		"htu": htu,
+		"iat": time.Now().Unix(),
+	}
+	for name, value := range overrides {
+		claims[name] = value
+	}
+	token := jwt.NewWithClaims(k.method, claims)
+	token.Header["typ"] = "dpop+jwt"
+	token.Header["jwk"] = k.jwk
+	proof, err := token.SignedString(k.signer)
+	assert.NoError(t, err)
+	return proof
+}
+
+func ath(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// DPoPVerifierTestSuite 是 DPoPVerifier 的测试套件
+type DPoPVerifierTestSuite struct {
+	suite.Suite
+	repo     *MockDPoPRepo
+	verifier *DPoPVerifier
+	key      *dpopKey
+	ctx      context.Context
+}
+
+const dpopTestPath = "/greet.v1.GreetService/SubmitAuth"
+
+func (suite *DPoPVerifierTestSuite) SetupTest() {
+	suite.repo = new(MockDPoPRepo)
+	suite.ctx = context.Background()
+	suite.key = newES256Key(suite.T())
+	logger, _ := zap.NewDevelopment()
+
+	verifier, err := NewDPoPVerifier(suite.repo, &conf.Bootstrap{
+		Dpop: &conf.DPoP{ProofMaxAgeSeconds: 30, PublicUrl: "https://api.example.com/"},
+	}, logger)
+	assert.NoError(suite.T(), err)
+	suite.verifier = verifier
+}
+
+func (suite *DPoPVerifierTestSuite) TestNewDPoPVerifier_InvalidPublicURL() {
+	logger, _ := zap.NewDevelopment()
+
+	_, err := NewDPoPVerifier(suite.repo, &conf.Bootstrap{Dpop: &conf.DPoP{PublicUrl: "api.example.com"}}, logger)
+
+	assert.Error(suite.T(), err)
+}
+
+func (suite *DPoPVerifierTestSuite) TestVerifyProof() {
+	suite.repo.On("UseProofID", suite.ctx, mock.Anything, 35*time.Second).Return(true, nil).Once()
+	suite.repo.On("UseProofID", suite.ctx, mock.Anything, 35*time.Second).Return(false, nil).Once()
+	proof := &model.DPoPProof{
+		Proof:  suite.key.proof(suite.T(), "POST", "https://api.example.com"+dpopTestPath+"?ignored", nil),
+		Method: "POST",
+		Path:   dpopTestPath,
+	}
+
+	jkt, err := suite.verifier.VerifyProof(suite.ctx, proof)
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), jkt)
+
+	// 同一密钥的指纹不变
+	_, expected, err := jwk.Parse(suite.key.jwk)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), expected, jkt)
+
+	// 同一个证明只能使用一次
+	_, err = suite.verifier.VerifyProof(suite.ctx, proof)
+	assert.ErrorIs(suite.T(), err, model.ErrInvalidDPoPProof)
+}
+
+func (suite *DPoPVerifierTestSuite) TestVerifyProof_AccessToken() {
+	suite.repo.On("UseProofID", suite.ctx, mock.Anything, mock.Anything).Return(true, nil)
+	key := newEdDSAKey(suite.T())
+	htu := "https://api.example.com" + dpopTestPath
+
+	_, err := suite.verifier.VerifyProof(suite.ctx, &model.DPoPProof{
+		Proof:       key.proof(suite.T(), "POST", htu, jwt.MapClaims{"ath": ath("access-token")}),
+		Method:      "POST",
+		Path:        dpopTestPath,
+		AccessToken: "access-token",
+	})
+	assert.NoError(suite.T(), err)
+
+	// 证明绑定的是其他令牌
+	_, err = suite.verifier.VerifyProof(suite.ctx, &model.DPoPProof{
+		Proof:       key.proof(suite.T(), "POST", htu, jwt.MapClaims{"ath": ath("other-token")}),
+		Method:      "POST",
+		Path:        dpopTestPath,
+		AccessToken: "access-token",
+	})
+	assert.ErrorIs(suite.T(), err, model.ErrInvalidDPoPProof)
+}
+
+func (suite *DPoPVerifierTestSuite) TestVerifyProof_Rejected() {
+	htu := "https://api.example.com" + dpopTestPath
+	hs256 := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"jti": "1", "htm": "POST", "htu": htu, "iat": time.Now().Unix()})
+	hs256.Header["typ"] = "dpop+jwt"
+	hs256.Header["jwk"] = map[string]any{"kty": "oct", "k": "c2VjcmV0"}
+	symmetric, err := hs256.SignedString([]byte("secret"))
+	assert.NoError(suite.T(), err)
+
+	noTyp := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{"jti": "1", "htm": "POST", "htu": htu, "iat": time.Now().Unix()})
+	noTyp.Header["jwk"] = suite.key.jwk
+	untyped, err := noTyp.SignedString(suite.key.signer)
+	assert.NoError(suite.T(), err)
+
+	other := newES256Key(suite.T())
+	withOtherKey := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{"jti": "1", "htm": "POST", "htu": htu, "iat": time.Now().Unix()})
+	withOtherKey.Header["typ"] = "dpop+jwt"
+	withOtherKey.Header["jwk"] = suite.key.jwk
+	mismatched, err := withOtherKey.SignedString(other.signer)
+	assert.NoError(suite.T(), err)
+
+	for name, proof := range map[string]string{
+		"symmetric":   symmetric,
+		"missing typ": untyped,
+		"wrong key":   mismatched,
+		"wrong htm":   suite.key.proof(suite.T(), "GET", htu, nil),
+		"wrong path":  suite.key.proof(suite.T(), "POST", "https://api.example.com/greet.v1.GreetService/Register", nil),
+		"wrong host":  suite.key.proof(suite.T(), "POST", "https://evil.example.com"+dpopTestPath, nil),
+		"stale":       suite.key.proof(suite.T(), "POST", htu, jwt.MapClaims{"iat": time.Now().Add(-time.Minute).Unix()}),
+		"future":      suite.key.proof(suite.T(), "POST", htu, jwt.MapClaims{"iat": time.Now().Add(time.Minute).Unix()}),
+		"missing jti": suite.key.proof(suite.T(), "POST", htu, jwt.MapClaims{"jti": ""}),
+		"no ath":      suite.key.proof(suite.T(), "POST", htu, nil),
+	} {
+		accessToken := ""
+		if name == "no ath" {
+			accessToken = "access-token"
+		}
+		_, err := suite.verifier.VerifyProof(suite.ctx, &model.DPoPProof{Proof: proof, Method: "POST", Path: dpopTestPath, AccessToken: accessToken})
+		assert.ErrorIs(suite.T(), err, model.ErrInvalidDPoPProof, name)
+	}
+	suite.repo.AssertNotCalled(suite.T(), "UseProofID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *DPoPVerifierTestSuite) TestVerifyProof_RepoError() {
+	suite.repo.On("UseProofID", suite.ctx, mock.Anything, mock.Anything).Return(false, errors.New("redis down"))
+
+	_, err := suite.verifier.VerifyProof(suite.ctx, &model.DPoPProof{
+		Proof:  suite.key.proof(suite.T(), "POST", "https://api.example.com"+dpopTestPath, nil),
+		Method: "POST",
+		Path:   dpopTestPath,
+	})
+
+	assert.Error(suite.T(), err)
+	assert.NotErrorIs(suite.T(), err, model.ErrInvalidDPoPProof)
+}
+
+func TestDPoPVerifierTestSuite(t *testing.T) {
+	suite.Run(t, new(DPoPVerifierTestSuite))
+}
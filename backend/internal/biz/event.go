@@ -0,0 +1,44 @@
+package biz
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"connect-go-example/internal/biz/model"
+	"connect-go-example/internal/data"
+
+	"go.uber.org/zap"
+)
+
+// DomainEvents 把领域事件写入发件箱，由 data.EventRelay 投递到消息代理
+type DomainEvents struct {
+	outbox data.OutboxRepo
+	l      *zap.Logger
+}
+
+func NewDomainEvents(outbox data.OutboxRepo, logger *zap.Logger) *DomainEvents {
+	return &DomainEvents{outbox: outbox, l: logger}
+}
+
+// recordUser 写入用户事件，应与修改用户的操作在同一事务中调用
+func (e *DomainEvents) recordUser(ctx context.Context, eventType string, event *model.UserEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return e.outbox.Append(ctx, &model.DomainEvent{
+		TenantID:      event.TenantID,
+		AggregateType: model.AggregateUser,
+		AggregateID:   strconv.FormatInt(event.UserID, 10),
+		Type:          eventType,
+		Payload:       payload,
+	})
+}
+
+// userLoggedIn 登录不修改用户数据，事件写入失败只记录日志，不影响登录
+func (e *DomainEvents) userLoggedIn(ctx context.Context, event *model.UserEvent) {
+	if err := e.recordUser(ctx, model.EventUserLoggedIn, event); err != nil {
+		e.l.Warn("record user logged in event failed", zap.Int64("user_id", event.UserID), zap.Error(err))
+	}
+}
@@ -0,0 +1,289 @@
+package biz
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data"
+	"connect-go-example/internal/pkg/oidc"
+
+	"connectrpc.com/connect"
+	"go.uber.org/zap"
+)
+
+var errInvalidFederatedState = errors.New("invalid or expired federated login state")
+
+type FederationUseCase struct {
+	providers *oidc.Providers
+	repo      data.IdentityRepo
+	users     data.UserRepo
+	tx        data.Transactor
+	tokens    *TokenManager
+	events    *DomainEvents
+	stateTTL  time.Duration
+	l         *zap.Logger
+}
+
+func NewFederationUseCase(providers *oidc.Providers, repo data.IdentityRepo, users data.UserRepo, tx data.Transactor, tokens *TokenManager, events *DomainEvents, cfg *conf.Bootstrap, logger *zap.Logger) model.FederationUseCase {
+	uc := &FederationUseCase{
+		providers: providers,
+		repo:      repo,
+		users:     users,
+		tx:        tx,
+		tokens:    tokens,
+		events:    events,
+		stateTTL:  10 * time.Minute, // 默认10分钟
+		l:         logger,
+	}
+	if cfg.Federation != nil && cfg.Federation.StateTtlSeconds > 0 {
+		uc.stateTTL = time.Duration(cfg.Federation.StateTtlSeconds) * time.Second
+	}
+	return uc
+}
+
+func (uc *FederationUseCase) ListIdentityProviders(ctx context.Context) []*model.IdentityProvider {
+	list := make([]*model.IdentityProvider, 0, len(uc.providers.List()))
+	for _, p := range uc.providers.List() {
+		list = append(list, &model.IdentityProvider{ID: p.ID(), DisplayName: p.DisplayName()})
+	}
+	return list
+}
+
+func (uc *FederationUseCase) BeginFederatedLogin(ctx context.Context, provider string, link *model.Principal) (*model.FederatedLoginTicket, error) {
+	p, err := uc.providers.Get(provider)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeNotFound, err)
+	}
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	// state 和 nonce 防止 CSRF 和 ID Token 重放，code_verifier 防止授权码被截获后使用（PKCE）
+	state, nonce, verifier, binding := randomToken(), randomToken(), randomToken(), randomToken()
+	challenge := sha256.Sum256([]byte(verifier))
+	authURL, err := p.AuthCodeURL(ctx, state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		uc.l.Warn("Build federated authorization url failed", zap.String("provider", provider), zap.Error(err))
+		return nil, connect.NewError(connect.CodeUnavailable, errors.New("identity provider is unavailable"))
+	}
+
+	loginState := &model.FederatedLoginState{
+		StateHash:    hashToken(state),
+		TenantID:     tenantID,
+		Provider:     p.ID(),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		BindingHash:  hashToken(binding),
+		ExpiresAt:    time.Now().Add(uc.stateTTL),
+	}
+	if link != nil {
+		loginState.LinkUserID = link.UserID
+	}
+	if err := uc.repo.SaveFederatedLoginState(ctx, loginState); err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	return &model.FederatedLoginTicket{
+		AuthorizationURL: authURL,
+		Binding:          binding,
+		ExpiresAt:        loginState.ExpiresAt,
+	}, nil
+}
+
+func (uc *FederationUseCase) CompleteFederatedLogin(ctx context.Context, state, code, binding string) (*model.AuthResult, error) {
+	if state == "" || code == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("state and code are required"))
+	}
+
+	// 先取出 state，授权码兑换失败时同样作废，避免重试
+	loginState, err := uc.repo.TakeFederatedLoginState(ctx, hashToken(state))
+	if err != nil {
+		if errors.Is(err, model.ErrFederatedStateNotFound) {
+			return nil, connect.NewError(connect.CodeUnauthenticated, errInvalidFederatedState)
+		}
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	if tenantID, err := model.TenantIDFromContext(ctx); err != nil || tenantID != loginState.TenantID {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errInvalidFederatedState)
+	}
+	if !constantTimeCompare(hashToken(binding), loginState.BindingHash) {
+		return nil, connect.NewError(connect.CodePermissionDenied, errors.New("federated login must be completed in the requesting browser"))
+	}
+
+	p, err := uc.providers.Get(loginState.Provider)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errInvalidFederatedState)
+	}
+	claims, err := p.Exchange(ctx, code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		uc.l.Warn("Federated code exchange failed", zap.String("provider", p.ID()), zap.Error(err))
+		if errors.Is(err, oidc.ErrInvalidIDToken) {
+			return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("invalid id token"))
+		}
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("authorization code exchange failed"))
+	}
+
+	if loginState.LinkUserID != 0 {
+		return uc.link(ctx, p, loginState.LinkUserID, claims)
+	}
+	return uc.login(ctx, p, claims)
+}
+
+// link 把外部身份关联到发起请求的用户
+func (uc *FederationUseCase) link(ctx context.Context, p *oidc.Provider, userID int64, claims *oidc.Claims) (*model.AuthResult, error) {
+	identity := &model.UserIdentity{UserID: userID, Provider: p.ID(), Subject: claims.Subject, Email: claims.Email}
+	if err := uc.repo.CreateUserIdentity(ctx, identity); err != nil {
+		if errors.Is(err, model.ErrIdentityConflict) {
+			return nil, connect.NewError(connect.CodeAlreadyExists, errors.New("identity is already linked"))
+		}
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	linked, err := uc.repo.GetUserIdentity(data.WithPrimary(ctx), p.ID(), claims.Subject)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	if linked.Disabled {
+		return nil, connect.NewError(connect.CodePermissionDenied, errors.New("user is disabled"))
+	}
+	uc.l.Info("Federated identity linked", zap.Int64("user_id", userID), zap.String("provider", p.ID()))
+	return uc.authenticate(ctx, linked)
+}
+
+// login 已关联的身份直接登录，首次登录时按配置关联已有用户或创建用户
+func (uc *FederationUseCase) login(ctx context.Context, p *oidc.Provider, claims *oidc.Claims) (*model.AuthResult, error) {
+	identity, err := uc.repo.GetUserIdentity(ctx, p.ID(), claims.Subject)
+	if err == nil {
+		if identity.Disabled {
+			return nil, connect.NewError(connect.CodePermissionDenied, errors.New("user is disabled"))
+		}
+		identity.Email = claims.Email
+		if err := uc.repo.TouchUserIdentity(ctx, identity); err != nil {
+			uc.l.Warn("Touch user identity failed", zap.Int64("user_id", identity.UserID), zap.Error(err))
+		}
+		return uc.authenticate(ctx, identity)
+	}
+	if !errors.Is(err, model.ErrIdentityNotFound) {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	// 未验证的邮箱不可信，既不用于关联，也不保存到新用户
+	email := ""
+	if claims.EmailVerified {
+		email = strings.ToLower(strings.TrimSpace(claims.Email))
+	}
+
+	var existing *model.User
+	if email != "" {
+		existing, err = uc.users.GetUserByEmail(ctx, email)
+		if err != nil && !errors.Is(err, model.ErrUserNotFound) {
+			return nil, connect.NewError(connect.CodeInternal, err)
+		}
+	}
+
+	switch {
+	case existing != nil && p.LinkByEmail():
+		if existing.Disabled {
+			return nil, connect.NewError(connect.CodePermissionDenied, errors.New("user is disabled"))
+		}
+		// 本地邮箱未验证时可能是他人预先注册占用的，不能据此关联，需要登录后主动关联
+		if !existing.EmailVerified {
+			return nil, connect.NewError(connect.CodeFailedPrecondition, errors.New("the local email is not verified, sign in and link the identity"))
+		}
+		return uc.link(ctx, p, existing.ID, claims)
+	case existing != nil && p.AutoProvision():
+		// 不能创建同邮箱的用户，需要登录已有账号后主动关联
+		return nil, connect.NewError(connect.CodeFailedPrecondition, errors.New("an account with this email already exists, sign in and link the identity"))
+	case p.AutoProvision():
+		return uc.provision(ctx, p, claims, email)
+	default:
+		return nil, connect.NewError(connect.CodePermissionDenied, errors.New("identity is not linked to any user"))
+	}
+}
+
+// provision 创建没有本地凭证的用户，只能通过外部身份或免密方式登录
+func (uc *FederationUseCase) provision(ctx context.Context, p *oidc.Provider, claims *oidc.Claims, email string) (*model.AuthResult, error) {
+	username := ""
+	for _, candidate := range []string{claims.PreferredUsername, email, p.ID() + "-" + claims.Subject} {
+		if candidate == "" {
+			continue
+		}
+		if existing, err := uc.users.GetUserByName(ctx, candidate); err != nil || existing == nil {
+			username = candidate
+			break
+		}
+	}
+	if username == "" {
+		return nil, connect.NewError(connect.CodeAlreadyExists, errors.New("no available username for the identity"))
+	}
+
+	// 创建用户和写入注册事件在同一事务中
+	tenantID, _ := model.TenantIDFromContext(ctx)
+	var userID int64
+	err := uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		id, err := uc.repo.CreateFederatedUser(ctx, &model.FederatedUser{
+			Username:    username,
+			Email:       email,
+			DisplayName: claims.Name,
+			Provider:    p.ID(),
+			Subject:     claims.Subject,
+		})
+		if err != nil {
+			return err
+		}
+		userID = id
+		return uc.events.recordUser(ctx, model.EventUserRegistered, &model.UserEvent{
+			TenantID: tenantID,
+			UserID:   userID,
+			Username: username,
+			Source:   model.EventSourceFederation,
+		})
+	})
+	if err != nil {
+		if errors.Is(err, model.ErrIdentityConflict) {
+			return nil, connect.NewError(connect.CodeAlreadyExists, errors.New("user already exists"))
+		}
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	uc.l.Info("Federated user provisioned", zap.Int64("user_id", userID), zap.String("provider", p.ID()))
+	return uc.authenticate(ctx, &model.UserIdentity{TenantID: tenantID, UserID: userID, Username: username})
+}
+
+// authenticate 签发令牌，请求携带 DPoP 证明时令牌绑定该密钥
+func (uc *FederationUseCase) authenticate(ctx context.Context, identity *model.UserIdentity) (*model.AuthResult, error) {
+	jkt := model.ProofKeyFromContext(ctx)
+	authToken, err := uc.tokens.IssueBound(identity.TenantID, identity.UserID, identity.Username, jkt)
+	if err != nil {
+		return nil, fmt.Errorf("generate token failed: %v", err)
+	}
+	uc.events.userLoggedIn(ctx, &model.UserEvent{
+		TenantID: identity.TenantID,
+		UserID:   identity.UserID,
+		Username: identity.Username,
+		Source:   model.EventSourceFederation,
+	})
+
+	return &model.AuthResult{
+		Code:      "success",
+		State:     "authenticated",
+		AuthToken: authToken,
+		TokenType: model.TokenType(jkt),
+	}, nil
+}
+
+// randomToken 32字节随机数的 base64url 编码
+func randomToken() string {
+	raw := make([]byte, 32)
+	_, _ = rand.Read(raw) // crypto/rand.Read 不会返回错误
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
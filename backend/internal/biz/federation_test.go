@@ -0,0 +1,337 @@
+package biz
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data"
+	"connect-go-example/internal/pkg/oidc"
+	"connect-go-example/internal/pkg/oidc/oidctest"
+
+	"connectrpc.com/connect"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+// MockIdentityRepo 是 IdentityRepo 的模拟实现
+type MockIdentityRepo struct {
+	mock.Mock
+}
+
+func (m *MockIdentityRepo) GetUserIdentity(ctx context.Context, provider, subject string) (*model.UserIdentity, error) {
+	args := m.Called(ctx, provider, subject)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.UserIdentity), args.Error(1)
+}
+
+func (m *MockIdentityRepo) CreateUserIdentity(ctx context.Context, identity *model.UserIdentity) error {
+	args := m.Called(ctx, identity)
+	return args.Error(0)
+}
+
+func (m *MockIdentityRepo) CreateFederatedUser(ctx context.Context, user *model.FederatedUser) (int64, error) {
+	args := m.Called(ctx, user)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockIdentityRepo) TouchUserIdentity(ctx context.Context, identity *model.UserIdentity) error {
+	args := m.Called(ctx, identity)
+	return args.Error(0)
+}
+
+func (m *MockIdentityRepo) SaveFederatedLoginState(ctx context.Context, state *model.FederatedLoginState) error {
+	args := m.Called(ctx, state)
+	return args.Error(0)
+}
+
+func (m *MockIdentityRepo) TakeFederatedLoginState(ctx context.Context, stateHash string) (*model.FederatedLoginState, error) {
+	args := m.Called(ctx, stateHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.FederatedLoginState), args.Error(1)
+}
+
+// FederationUseCaseTestSuite 是 FederationUseCase 的测试套件，上游使用本地的模拟 IdP
+type FederationUseCaseTestSuite struct {
+	suite.Suite
+	idp      *oidctest.Server
+	repo     *MockIdentityRepo
+	userRepo *MockUserRepo
+	tokens   *TokenManager
+	provider *conf.Federation_Provider
+	useCase  *FederationUseCase
+	ctx      context.Context
+}
+
+func (suite *FederationUseCaseTestSuite) SetupTest() {
+	suite.idp = oidctest.NewServer("example-app", "example-secret")
+	suite.idp.SetUser(oidctest.User{Subject: "248289761001", Email: "alice@example.com", EmailVerified: true, PreferredUsername: "alice"})
+	suite.repo = new(MockIdentityRepo)
+	suite.userRepo = new(MockUserRepo)
+	suite.ctx = model.NewTenantContext(context.Background(), &model.Tenant{ID: 2})
+
+	suite.provider = &conf.Federation_Provider{
+		Id:           "stub",
+		Issuer:       suite.idp.Issuer(),
+		ClientId:     "example-app",
+		ClientSecret: "example-secret",
+		RedirectUrl:  "https://app.example.com/login/callback",
+	}
+	suite.newUseCase()
+}
+
+func (suite *FederationUseCaseTestSuite) TearDownTest() {
+	suite.idp.Close()
+}
+
+// newUseCase 修改提供方配置后重新创建用例
+func (suite *FederationUseCaseTestSuite) newUseCase() {
+	logger, _ := zap.NewDevelopment()
+	cfg := &conf.Bootstrap{
+		Auth:       &conf.Auth{JwtSecret: "test-secret"},
+		Federation: &conf.Federation{Providers: []*conf.Federation_Provider{suite.provider}},
+	}
+	tokens, err := NewTokenManager(cfg, logger)
+	assert.NoError(suite.T(), err)
+	suite.tokens = tokens
+
+	providers, err := oidc.NewProviders(cfg, logger)
+	assert.NoError(suite.T(), err)
+	suite.useCase = NewFederationUseCase(providers, suite.repo, suite.userRepo, new(MockTransactor), tokens, NewDomainEvents(new(MockOutboxRepo), zap.NewNop()), cfg, logger).(*FederationUseCase)
+}
+
+// begin 发起登录并在模拟 IdP 完成授权，返回回调中的 state、code 和浏览器绑定凭据
+func (suite *FederationUseCaseTestSuite) begin(link *model.Principal) (string, string, string) {
+	var saved *model.FederatedLoginState
+	suite.repo.On("SaveFederatedLoginState", suite.ctx, mock.AnythingOfType("*model.FederatedLoginState")).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*model.FederatedLoginState)
+	}).Return(nil).Once()
+
+	ticket, err := suite.useCase.BeginFederatedLogin(suite.ctx, "stub", link)
+	assert.NoError(suite.T(), err)
+
+	callback, err := suite.idp.Login(ticket.AuthorizationURL)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "app.example.com", callback.Host)
+
+	state := callback.Query().Get("state")
+	assert.Equal(suite.T(), hashToken(state), saved.StateHash)
+	suite.repo.On("TakeFederatedLoginState", suite.ctx, saved.StateHash).Return(saved, nil).Once()
+	return state, callback.Query().Get("code"), ticket.Binding
+}
+
+func (suite *FederationUseCaseTestSuite) assertToken(result *model.AuthResult, userID int64, username string) {
+	principal, err := suite.tokens.VerifyToken(suite.ctx, result.AuthToken)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), principal.TenantID)
+	assert.Equal(suite.T(), userID, principal.UserID)
+	assert.Equal(suite.T(), username, principal.Username)
+}
+
+func (suite *FederationUseCaseTestSuite) TestBeginFederatedLogin() {
+	suite.repo.On("SaveFederatedLoginState", suite.ctx, mock.AnythingOfType("*model.FederatedLoginState")).Return(nil)
+
+	ticket, err := suite.useCase.BeginFederatedLogin(suite.ctx, "stub", nil)
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), ticket.Binding)
+	u, err := url.Parse(ticket.AuthorizationURL)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), suite.idp.Issuer()+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	assert.Equal(suite.T(), "example-app", u.Query().Get("client_id"))
+	assert.Equal(suite.T(), "openid email profile", u.Query().Get("scope"))
+	assert.Equal(suite.T(), "S256", u.Query().Get("code_challenge_method"))
+	assert.NotEmpty(suite.T(), u.Query().Get("nonce"))
+}
+
+func (suite *FederationUseCaseTestSuite) TestBeginFederatedLogin_UnknownProvider() {
+	_, err := suite.useCase.BeginFederatedLogin(suite.ctx, "missing", nil)
+
+	assert.Equal(suite.T(), connect.CodeNotFound, connect.CodeOf(err))
+}
+
+func (suite *FederationUseCaseTestSuite) TestCompleteFederatedLogin_LinkedIdentity() {
+	state, code, binding := suite.begin(nil)
+	identity := &model.UserIdentity{TenantID: 2, UserID: 7, Username: "alice", Provider: "stub", Subject: "248289761001"}
+	suite.repo.On("GetUserIdentity", suite.ctx, "stub", "248289761001").Return(identity, nil)
+	suite.repo.On("TouchUserIdentity", suite.ctx, identity).Return(nil)
+
+	result, err := suite.useCase.CompleteFederatedLogin(suite.ctx, state, code, binding)
+
+	assert.NoError(suite.T(), err)
+	suite.assertToken(result, 7, "alice")
+	assert.Equal(suite.T(), "alice@example.com", identity.Email)
+
+	// state 只能使用一次
+	suite.repo.On("TakeFederatedLoginState", suite.ctx, hashToken(state)).Return(nil, model.ErrFederatedStateNotFound)
+	_, err = suite.useCase.CompleteFederatedLogin(suite.ctx, state, code, binding)
+	assert.Equal(suite.T(), connect.CodeUnauthenticated, connect.CodeOf(err))
+}
+
+func (suite *FederationUseCaseTestSuite) TestCompleteFederatedLogin_DisabledUser() {
+	state, code, binding := suite.begin(nil)
+	suite.repo.On("GetUserIdentity", suite.ctx, "stub", "248289761001").Return(&model.UserIdentity{TenantID: 2, UserID: 7, Username: "alice", Disabled: true}, nil)
+
+	_, err := suite.useCase.CompleteFederatedLogin(suite.ctx, state, code, binding)
+
+	assert.Equal(suite.T(), connect.CodePermissionDenied, connect.CodeOf(err))
+}
+
+func (suite *FederationUseCaseTestSuite) TestCompleteFederatedLogin_WrongBinding() {
+	state, code, _ := suite.begin(nil)
+
+	_, err := suite.useCase.CompleteFederatedLogin(suite.ctx, state, code, "another-browser")
+
+	assert.Equal(suite.T(), connect.CodePermissionDenied, connect.CodeOf(err))
+	suite.repo.AssertNotCalled(suite.T(), "GetUserIdentity", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *FederationUseCaseTestSuite) TestCompleteFederatedLogin_OtherTenant() {
+	state, code, binding := suite.begin(nil)
+	otherCtx := model.NewTenantContext(context.Background(), &model.Tenant{ID: 3})
+	suite.repo.On("TakeFederatedLoginState", otherCtx, hashToken(state)).Return(suite.repo.Calls[0].Arguments.Get(1), nil)
+
+	_, err := suite.useCase.CompleteFederatedLogin(otherCtx, state, code, binding)
+
+	assert.Equal(suite.T(), connect.CodeUnauthenticated, connect.CodeOf(err))
+}
+
+func (suite *FederationUseCaseTestSuite) TestCompleteFederatedLogin_NonceMismatch() {
+	suite.idp.Mutate = func(claims jwt.MapClaims) { claims["nonce"] = "replayed" }
+	state, code, binding := suite.begin(nil)
+
+	_, err := suite.useCase.CompleteFederatedLogin(suite.ctx, state, code, binding)
+
+	assert.Equal(suite.T(), connect.CodeUnauthenticated, connect.CodeOf(err))
+	suite.repo.AssertNotCalled(suite.T(), "GetUserIdentity", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *FederationUseCaseTestSuite) TestCompleteFederatedLogin_WrongAudience() {
+	suite.idp.Mutate = func(claims jwt.MapClaims) { claims["aud"] = "another-app" }
+	state, code, binding := suite.begin(nil)
+
+	_, err := suite.useCase.CompleteFederatedLogin(suite.ctx, state, code, binding)
+
+	assert.Equal(suite.T(), connect.CodeUnauthenticated, connect.CodeOf(err))
+}
+
+func (suite *FederationUseCaseTestSuite) TestCompleteFederatedLogin_NotLinked() {
+	state, code, binding := suite.begin(nil)
+	suite.repo.On("GetUserIdentity", suite.ctx, "stub", "248289761001").Return(nil, model.ErrIdentityNotFound)
+	suite.userRepo.On("GetUserByEmail", suite.ctx, "alice@example.com").Return(nil, model.ErrUserNotFound)
+
+	_, err := suite.useCase.CompleteFederatedLogin(suite.ctx, state, code, binding)
+
+	assert.Equal(suite.T(), connect.CodePermissionDenied, connect.CodeOf(err))
+}
+
+func (suite *FederationUseCaseTestSuite) TestCompleteFederatedLogin_LinkByEmail() {
+	suite.provider.LinkByEmail = true
+	suite.newUseCase()
+	state, code, binding := suite.begin(nil)
+	suite.repo.On("GetUserIdentity", suite.ctx, "stub", "248289761001").Return(nil, model.ErrIdentityNotFound).Once()
+	suite.userRepo.On("GetUserByEmail", suite.ctx, "alice@example.com").Return(&model.User{ID: 7, TenantID: 2, Username: "alice", EmailVerified: true}, nil)
+	suite.repo.On("CreateUserIdentity", suite.ctx, &model.UserIdentity{UserID: 7, Provider: "stub", Subject: "248289761001", Email: "alice@example.com"}).Return(nil)
+	suite.repo.On("GetUserIdentity", data.WithPrimary(suite.ctx), "stub", "248289761001").Return(&model.UserIdentity{TenantID: 2, UserID: 7, Username: "alice"}, nil)
+
+	result, err := suite.useCase.CompleteFederatedLogin(suite.ctx, state, code, binding)
+
+	assert.NoError(suite.T(), err)
+	suite.assertToken(result, 7, "alice")
+}
+
+func (suite *FederationUseCaseTestSuite) TestCompleteFederatedLogin_LocalEmailUnverified() {
+	suite.provider.LinkByEmail = true
+	suite.newUseCase()
+	state, code, binding := suite.begin(nil)
+	suite.repo.On("GetUserIdentity", suite.ctx, "stub", "248289761001").Return(nil, model.ErrIdentityNotFound)
+	suite.userRepo.On("GetUserByEmail", suite.ctx, "alice@example.com").Return(&model.User{ID: 7, TenantID: 2, Username: "alice"}, nil)
+
+	_, err := suite.useCase.CompleteFederatedLogin(suite.ctx, state, code, binding)
+
+	// 本地邮箱可能是他人注册时填写的，需要登录后调用 LinkFederatedIdentity
+	assert.Equal(suite.T(), connect.CodeFailedPrecondition, connect.CodeOf(err))
+	suite.repo.AssertNotCalled(suite.T(), "CreateUserIdentity", mock.Anything, mock.Anything)
+}
+
+func (suite *FederationUseCaseTestSuite) TestCompleteFederatedLogin_UnverifiedEmailNotLinked() {
+	suite.provider.LinkByEmail = true
+	suite.newUseCase()
+	suite.idp.SetUser(oidctest.User{Subject: "248289761001", Email: "alice@example.com"})
+	state, code, binding := suite.begin(nil)
+	suite.repo.On("GetUserIdentity", suite.ctx, "stub", "248289761001").Return(nil, model.ErrIdentityNotFound)
+
+	_, err := suite.useCase.CompleteFederatedLogin(suite.ctx, state, code, binding)
+
+	assert.Equal(suite.T(), connect.CodePermissionDenied, connect.CodeOf(err))
+	suite.userRepo.AssertNotCalled(suite.T(), "GetUserByEmail", mock.Anything, mock.Anything)
+}
+
+func (suite *FederationUseCaseTestSuite) TestCompleteFederatedLogin_AutoProvision() {
+	suite.provider.AutoProvision = true
+	suite.newUseCase()
+	state, code, binding := suite.begin(nil)
+	suite.repo.On("GetUserIdentity", suite.ctx, "stub", "248289761001").Return(nil, model.ErrIdentityNotFound)
+	suite.userRepo.On("GetUserByEmail", suite.ctx, "alice@example.com").Return(nil, model.ErrUserNotFound)
+	// 用户名 alice 已被占用时改用邮箱
+	suite.userRepo.On("GetUserByName", suite.ctx, "alice").Return(&model.User{ID: 3, Username: "alice"}, nil)
+	suite.userRepo.On("GetUserByName", suite.ctx, "alice@example.com").Return(nil, model.ErrUserNotFound)
+	suite.repo.On("CreateFederatedUser", suite.ctx, &model.FederatedUser{
+		Username: "alice@example.com",
+		Email:    "alice@example.com",
+		Provider: "stub",
+		Subject:  "248289761001",
+	}).Return(int64(9), nil)
+
+	result, err := suite.useCase.CompleteFederatedLogin(suite.ctx, state, code, binding)
+
+	assert.NoError(suite.T(), err)
+	suite.assertToken(result, 9, "alice@example.com")
+}
+
+func (suite *FederationUseCaseTestSuite) TestCompleteFederatedLogin_AutoProvisionEmailTaken() {
+	suite.provider.AutoProvision = true
+	suite.newUseCase()
+	state, code, binding := suite.begin(nil)
+	suite.repo.On("GetUserIdentity", suite.ctx, "stub", "248289761001").Return(nil, model.ErrIdentityNotFound)
+	suite.userRepo.On("GetUserByEmail", suite.ctx, "alice@example.com").Return(&model.User{ID: 7, TenantID: 2, Username: "alice"}, nil)
+
+	_, err := suite.useCase.CompleteFederatedLogin(suite.ctx, state, code, binding)
+
+	assert.Equal(suite.T(), connect.CodeFailedPrecondition, connect.CodeOf(err))
+	suite.repo.AssertNotCalled(suite.T(), "CreateFederatedUser", mock.Anything, mock.Anything)
+}
+
+func (suite *FederationUseCaseTestSuite) TestLinkFederatedIdentity() {
+	state, code, binding := suite.begin(&model.Principal{TenantID: 2, UserID: 7, Username: "alice"})
+	suite.repo.On("CreateUserIdentity", suite.ctx, &model.UserIdentity{UserID: 7, Provider: "stub", Subject: "248289761001", Email: "alice@example.com"}).Return(nil)
+	// 刚写入的关联从主库读取
+	suite.repo.On("GetUserIdentity", data.WithPrimary(suite.ctx), "stub", "248289761001").Return(&model.UserIdentity{TenantID: 2, UserID: 7, Username: "alice"}, nil)
+
+	result, err := suite.useCase.CompleteFederatedLogin(suite.ctx, state, code, binding)
+
+	assert.NoError(suite.T(), err)
+	suite.assertToken(result, 7, "alice")
+}
+
+func (suite *FederationUseCaseTestSuite) TestLinkFederatedIdentity_AlreadyLinked() {
+	state, code, binding := suite.begin(&model.Principal{TenantID: 2, UserID: 7, Username: "alice"})
+	suite.repo.On("CreateUserIdentity", suite.ctx, mock.AnythingOfType("*model.UserIdentity")).Return(model.ErrIdentityConflict)
+
+	_, err := suite.useCase.CompleteFederatedLogin(suite.ctx, state, code, binding)
+
+	assert.Equal(suite.T(), connect.CodeAlreadyExists, connect.CodeOf(err))
+}
+
+func TestFederationUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(FederationUseCaseTestSuite))
+}
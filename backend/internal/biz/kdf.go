@@ -0,0 +1,132 @@
+package biz
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+)
+
+// 客户端 KDF 算法
+const (
+	KdfAlgorithmArgon2id = "argon2id"
+	KdfAlgorithmLegacy   = "legacy" // 引入服务端参数之前注册的账号，由客户端自行决定
+)
+
+const kdfPurpose = "kdf"
+
+// kdfPolicy 客户端 KDF 参数，由 client_kdf 配置解析而来
+type kdfPolicy struct {
+	profiles      map[int32]model.KdfParams
+	current       int32
+	requireTicket bool
+	ticketTTL     time.Duration
+}
+
+func newKdfPolicy(cfg *conf.ClientKdf) (*kdfPolicy, error) {
+	p := &kdfPolicy{
+		profiles:  make(map[int32]model.KdfParams),
+		ticketTTL: 10 * time.Minute, // 默认10分钟
+	}
+	var profiles []*conf.ClientKdf_Profile
+	if cfg != nil {
+		profiles = cfg.Profiles
+		p.requireTicket = cfg.RequireTicket
+		if cfg.TicketTtlSeconds > 0 {
+			p.ticketTTL = time.Duration(cfg.TicketTtlSeconds) * time.Second
+		}
+	}
+	if len(profiles) == 0 {
+		profiles = []*conf.ClientKdf_Profile{{Version: 1, MemoryKib: 64 * 1024, Iterations: 3, Parallelism: 1}}
+	}
+
+	for _, profile := range profiles {
+		if profile.Version <= 0 || profile.MemoryKib == 0 || profile.Iterations == 0 || profile.Parallelism == 0 {
+			return nil, fmt.Errorf("invalid client_kdf profile: version %d", profile.Version)
+		}
+		if _, ok := p.profiles[profile.Version]; ok {
+			return nil, fmt.Errorf("duplicate client_kdf profile version: %d", profile.Version)
+		}
+		p.profiles[profile.Version] = model.KdfParams{
+			Algorithm:   KdfAlgorithmArgon2id,
+			Version:     profile.Version,
+			MemoryKiB:   profile.MemoryKib,
+			Iterations:  profile.Iterations,
+			Parallelism: profile.Parallelism,
+		}
+		p.current = max(p.current, profile.Version)
+	}
+	return p, nil
+}
+
+// params 返回指定版本的参数，版本0为旧凭证
+func (p *kdfPolicy) params(version int32, salt string) (model.KdfParams, error) {
+	if version == 0 {
+		return model.KdfParams{Algorithm: KdfAlgorithmLegacy, Salt: salt}, nil
+	}
+	params, ok := p.profiles[version]
+	if !ok {
+		return model.KdfParams{}, fmt.Errorf("client_kdf profile version %d is not configured", version)
+	}
+	params.Salt = salt
+	return params, nil
+}
+
+// issue 使用当前版本和新生成的盐签发票据：<租户>.<版本>.<盐>.<过期时间>.<签名>
+func (p *kdfPolicy) issue(ctx context.Context, tokens *TokenManager) (*model.KdfTicket, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("generate kdf salt failed: %v", err)
+	}
+
+	params := p.profiles[p.current]
+	params.Salt = base64.RawURLEncoding.EncodeToString(raw)
+	expiresAt := time.Now().Add(p.ticketTTL)
+	payload := strings.Join([]string{
+		strconv.FormatInt(tenantID, 10),
+		strconv.FormatInt(int64(params.Version), 10),
+		params.Salt,
+		strconv.FormatInt(expiresAt.Unix(), 10),
+	}, ".")
+
+	return &model.KdfTicket{
+		Params:    params,
+		Ticket:    payload + "." + tokens.sign(kdfPurpose, payload),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// parse 校验票据，返回其中的参数
+func (p *kdfPolicy) parse(ctx context.Context, tokens *TokenManager, ticket string) (model.KdfParams, bool) {
+	parts := strings.Split(ticket, ".")
+	if len(parts) != 5 || !tokens.verifySignature(kdfPurpose, strings.Join(parts[:4], "."), parts[4]) {
+		return model.KdfParams{}, false
+	}
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil || parts[0] != strconv.FormatInt(tenantID, 10) {
+		return model.KdfParams{}, false
+	}
+	exp, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return model.KdfParams{}, false
+	}
+	version, err := strconv.ParseInt(parts[1], 10, 32)
+	if err != nil {
+		return model.KdfParams{}, false
+	}
+	params, err := p.params(int32(version), parts[2])
+	if err != nil || params.Version == 0 {
+		return model.KdfParams{}, false
+	}
+	return params, true
+}
@@ -0,0 +1,180 @@
+package biz
+
+import (
+	"context"
+	"testing"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+// KdfTestSuite 测试服务端下发的客户端 KDF 参数
+type KdfTestSuite struct {
+	suite.Suite
+	userRepo *MockUserRepo
+	useCase  *UserUseCase
+	ctx      context.Context
+}
+
+func (suite *KdfTestSuite) SetupTest() {
+	suite.userRepo = new(MockUserRepo)
+	suite.ctx = model.NewTenantContext(context.Background(), &model.Tenant{ID: 2})
+	suite.useCase = suite.newUseCase(&conf.ClientKdf{
+		Profiles: []*conf.ClientKdf_Profile{
+			{Version: 1, MemoryKib: 19 * 1024, Iterations: 2, Parallelism: 1},
+			{Version: 2, MemoryKib: 64 * 1024, Iterations: 3, Parallelism: 1},
+		},
+	})
+}
+
+func (suite *KdfTestSuite) newUseCase(kdf *conf.ClientKdf) *UserUseCase {
+	logger, _ := zap.NewDevelopment()
+	cfg := &conf.Bootstrap{Auth: &conf.Auth{JwtSecret: "test-secret"}, ClientKdf: kdf}
+	tokens, err := NewTokenManager(cfg, logger)
+	assert.NoError(suite.T(), err)
+	pow, err := NewProofOfWork(new(MockPowRepo), tokens, cfg, logger)
+	assert.NoError(suite.T(), err)
+
+	useCase, err := NewUserUseCase(suite.userRepo, new(MockAuthRequestRepo), new(MockInviteRepo), new(MockTransactor), tokens, newTestHasher(suite.T(), "pepper"), new(CredentialBackends), pow, newDisabledLoginRisk(), NewDomainEvents(new(MockOutboxRepo), zap.NewNop()), cfg, logger)
+	assert.NoError(suite.T(), err)
+	return useCase.(*UserUseCase)
+}
+
+func (suite *KdfTestSuite) TestNewKdfPolicy() {
+	policy, err := newKdfPolicy(nil)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int32(1), policy.current)
+	assert.Equal(suite.T(), uint32(64*1024), policy.profiles[1].MemoryKiB)
+
+	_, err = newKdfPolicy(&conf.ClientKdf{Profiles: []*conf.ClientKdf_Profile{
+		{Version: 1, MemoryKib: 1024, Iterations: 1, Parallelism: 1},
+		{Version: 1, MemoryKib: 2048, Iterations: 1, Parallelism: 1},
+	}})
+	assert.Error(suite.T(), err)
+
+	_, err = newKdfPolicy(&conf.ClientKdf{Profiles: []*conf.ClientKdf_Profile{{Version: 0, MemoryKib: 1024, Iterations: 1, Parallelism: 1}}})
+	assert.Error(suite.T(), err)
+}
+
+func (suite *KdfTestSuite) TestTicket() {
+	ticket, err := suite.useCase.GetRegistrationParams(suite.ctx)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int32(2), ticket.Params.Version)
+	assert.Equal(suite.T(), KdfAlgorithmArgon2id, ticket.Params.Algorithm)
+	assert.Len(suite.T(), ticket.Params.Salt, 22)
+
+	params, ok := suite.useCase.kdf.parse(suite.ctx, suite.useCase.tokens, ticket.Ticket)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), ticket.Params, params)
+
+	// 其他租户、被篡改的票据无效
+	other := model.NewTenantContext(context.Background(), &model.Tenant{ID: 3})
+	_, ok = suite.useCase.kdf.parse(other, suite.useCase.tokens, ticket.Ticket)
+	assert.False(suite.T(), ok)
+	_, ok = suite.useCase.kdf.parse(suite.ctx, suite.useCase.tokens, "2.2.c2FsdA."+ticket.Ticket[len("2.2.")+23:])
+	assert.False(suite.T(), ok)
+
+	_, err = suite.useCase.GetRegistrationParams(context.Background())
+	assert.Equal(suite.T(), connect.CodeInvalidArgument, connect.CodeOf(err))
+}
+
+func (suite *KdfTestSuite) TestRegister_WithTicket() {
+	ticket, err := suite.useCase.GetRegistrationParams(suite.ctx)
+	assert.NoError(suite.T(), err)
+	suite.userRepo.On("GetUserByName", suite.ctx, "newuser").Return(nil, model.ErrUserNotFound)
+	suite.userRepo.On("CreateUser", suite.ctx, mock.AnythingOfType("*model.User")).Return(int64(12), nil)
+
+	// 客户端传入的 salt 被忽略
+	_, err = suite.useCase.Register(suite.ctx, &model.RegisterRequest{Username: "newuser", PasswordHash: "hash", Salt: "client-salt", KdfTicket: ticket.Ticket})
+
+	assert.NoError(suite.T(), err)
+	suite.userRepo.AssertCalled(suite.T(), "CreateUser", suite.ctx, mock.MatchedBy(func(user *model.User) bool {
+		return user.Salt == ticket.Params.Salt && user.KdfVersion == 2
+	}))
+
+	_, err = suite.useCase.Register(suite.ctx, &model.RegisterRequest{Username: "newuser", PasswordHash: "hash", KdfTicket: "bogus"})
+	assert.Equal(suite.T(), connect.CodeInvalidArgument, connect.CodeOf(err))
+}
+
+func (suite *KdfTestSuite) TestRegister_RequireTicket() {
+	useCase := suite.newUseCase(&conf.ClientKdf{RequireTicket: true})
+
+	_, err := useCase.Register(suite.ctx, &model.RegisterRequest{Username: "newuser", PasswordHash: "hash", Salt: "salt"})
+
+	assert.Equal(suite.T(), connect.CodeFailedPrecondition, connect.CodeOf(err))
+	suite.userRepo.AssertNotCalled(suite.T(), "CreateUser", mock.Anything, mock.Anything)
+}
+
+func (suite *KdfTestSuite) TestGetAuthChallenge_OutdatedParams() {
+	suite.userRepo.On("GetUserByName", suite.ctx, "olduser").Return(&model.User{ID: 7, Username: "olduser", Salt: "s1", KdfVersion: 1}, nil)
+	suite.userRepo.On("GetUserByName", suite.ctx, "newuser").Return(&model.User{ID: 8, Username: "newuser", Salt: "s2", KdfVersion: 2}, nil)
+	suite.userRepo.On("StoreAuthChallenge", suite.ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	challenge, err := suite.useCase.GetAuthChallenge(suite.ctx, "olduser", nil)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int32(1), challenge.Kdf.Version)
+	assert.Equal(suite.T(), "s1", challenge.Kdf.Salt)
+	assert.NotNil(suite.T(), challenge.Upgrade)
+	assert.Equal(suite.T(), int32(2), challenge.Upgrade.Params.Version)
+
+	challenge, err = suite.useCase.GetAuthChallenge(suite.ctx, "newuser", nil)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), uint32(64*1024), challenge.Kdf.MemoryKiB)
+	assert.Nil(suite.T(), challenge.Upgrade)
+}
+
+func (suite *KdfTestSuite) TestSubmitAuth_UpgradesKdf() {
+	storedHash, err := suite.useCase.hasher.Hash("old-cred")
+	assert.NoError(suite.T(), err)
+	upgrade, err := suite.useCase.kdf.issue(suite.ctx, suite.useCase.tokens)
+	assert.NoError(suite.T(), err)
+
+	suite.userRepo.On("GetAuthChallenge", suite.ctx, "testuser").Return("challenge", nil)
+	suite.userRepo.On("GetUserByName", suite.ctx, "testuser").Return(&model.User{ID: 7, Username: "testuser", PasswordHash: storedHash, Salt: "s1", KdfVersion: 1}, nil)
+	suite.userRepo.On("UpdateCredential", suite.ctx, mock.AnythingOfType("*model.User")).Return(nil)
+
+	result, err := suite.useCase.SubmitAuth(suite.ctx, &model.SubmitAuthRequest{
+		Username:          "testuser",
+		HashedCredential:  "old-cred",
+		ChallengeResponse: computeChallengeResponse("challenge", "testuser"),
+		UpgradeCredential: "new-cred",
+		UpgradeTicket:     upgrade.Ticket,
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), result.AuthToken)
+	suite.userRepo.AssertCalled(suite.T(), "UpdateCredential", suite.ctx, mock.MatchedBy(func(user *model.User) bool {
+		ok, _ := suite.useCase.hasher.Verify("new-cred", user.PasswordHash)
+		return ok && user.ID == 7 && user.Salt == upgrade.Params.Salt && user.KdfVersion == 2
+	}))
+}
+
+func (suite *KdfTestSuite) TestSubmitAuth_IgnoresInvalidUpgradeTicket() {
+	storedHash, err := suite.useCase.hasher.Hash("old-cred")
+	assert.NoError(suite.T(), err)
+
+	suite.userRepo.On("GetAuthChallenge", suite.ctx, "testuser").Return("challenge", nil)
+	suite.userRepo.On("GetUserByName", suite.ctx, "testuser").Return(&model.User{ID: 7, Username: "testuser", PasswordHash: storedHash, Salt: "s1", KdfVersion: 1}, nil)
+
+	result, err := suite.useCase.SubmitAuth(suite.ctx, &model.SubmitAuthRequest{
+		Username:          "testuser",
+		HashedCredential:  "old-cred",
+		ChallengeResponse: computeChallengeResponse("challenge", "testuser"),
+		UpgradeCredential: "new-cred",
+		UpgradeTicket:     "bogus",
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), result.AuthToken)
+	suite.userRepo.AssertNotCalled(suite.T(), "UpdateCredential", mock.Anything, mock.Anything)
+}
+
+func TestKdfTestSuite(t *testing.T) {
+	suite.Run(t, new(KdfTestSuite))
+}
@@ -0,0 +1,432 @@
+package biz
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"slices"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data"
+	"connect-go-example/internal/pkg/notify"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// AuthResult.Code：需要二次验证
+const authResultStepUp = "step_up_required"
+
+// 无法向用户发送验证码时的处理方式
+const (
+	StepUpUnreachableAllow = "allow"
+	StepUpUnreachableDeny  = "deny"
+)
+
+var errInvalidStepUp = errors.New("invalid or expired step-up verification")
+
+// LoginRisk 根据设备指纹、网段和登录时段评估登录风险，陌生设备登录时通知用户
+type LoginRisk struct {
+	users        data.UserRepo
+	devices      data.DeviceRepo
+	stepUps      data.StepUpRepo
+	authRequests data.AuthRequestRepo
+	tokens       *TokenManager
+	notifier     notify.Notifier
+	events       *DomainEvents
+
+	enabled          bool
+	ipv4Bits         int
+	ipv6Bits         int
+	newDeviceScore   int32
+	newNetworkScore  int32
+	unusualHourScore int32
+	minLogins        int
+	history          time.Duration
+	stepUpThreshold  int32
+	stepUpTTL        time.Duration
+	maxAttempts      int64
+	allowUnreachable bool
+	l                *zap.Logger
+}
+
+// loginAssessment 一次登录的风险评估结果
+type loginAssessment struct {
+	device      model.Device
+	score       int32
+	reasons     []string
+	firstDevice bool // 用户还没有任何设备记录，只记录不提醒
+}
+
+func NewLoginRisk(users data.UserRepo, devices data.DeviceRepo, stepUps data.StepUpRepo, authRequests data.AuthRequestRepo, tokens *TokenManager, notifier notify.Notifier, events *DomainEvents, cfg *conf.Bootstrap, logger *zap.Logger) (*LoginRisk, error) {
+	r := &LoginRisk{
+		users:            users,
+		devices:          devices,
+		stepUps:          stepUps,
+		authRequests:     authRequests,
+		tokens:           tokens,
+		notifier:         notifier,
+		events:           events,
+		ipv4Bits:         24,
+		ipv6Bits:         48,
+		newDeviceScore:   50,
+		newNetworkScore:  20,
+		unusualHourScore: 30,
+		minLogins:        10,
+		history:          30 * 24 * time.Hour, // 默认30天
+		stepUpTTL:        10 * time.Minute,    // 默认10分钟
+		maxAttempts:      5,
+		l:                logger,
+	}
+
+	c := cfg.LoginRisk
+	if c == nil {
+		return r, nil
+	}
+	if c.Ipv4PrefixBits < 0 || c.Ipv4PrefixBits > 32 || c.Ipv6PrefixBits < 0 || c.Ipv6PrefixBits > 128 {
+		return nil, fmt.Errorf("invalid login_risk prefix bits: ipv4 %d, ipv6 %d", c.Ipv4PrefixBits, c.Ipv6PrefixBits)
+	}
+	if c.StepUpThreshold < 0 {
+		return nil, fmt.Errorf("invalid login_risk.step_up_threshold: %d", c.StepUpThreshold)
+	}
+	// 未配置时拒绝，没有邮箱的用户（包括早期注册的用户）不能借此跳过二次验证
+	switch c.StepUpUnreachable {
+	case "", StepUpUnreachableDeny:
+	case StepUpUnreachableAllow:
+		r.allowUnreachable = true
+	default:
+		return nil, fmt.Errorf("invalid login_risk.step_up_unreachable: %s", c.StepUpUnreachable)
+	}
+
+	r.enabled = c.Enabled
+	r.stepUpThreshold = c.StepUpThreshold
+	if c.Ipv4PrefixBits > 0 {
+		r.ipv4Bits = int(c.Ipv4PrefixBits)
+	}
+	if c.Ipv6PrefixBits > 0 {
+		r.ipv6Bits = int(c.Ipv6PrefixBits)
+	}
+	if c.NewDeviceScore > 0 {
+		r.newDeviceScore = c.NewDeviceScore
+	}
+	if c.NewNetworkScore > 0 {
+		r.newNetworkScore = c.NewNetworkScore
+	}
+	if c.UnusualHourScore > 0 {
+		r.unusualHourScore = c.UnusualHourScore
+	}
+	if c.UsualHoursMinLogins > 0 {
+		r.minLogins = int(c.UsualHoursMinLogins)
+	}
+	if c.HistoryDays > 0 {
+		r.history = time.Duration(c.HistoryDays) * 24 * time.Hour
+	}
+	if c.StepUpTtlSeconds > 0 {
+		r.stepUpTTL = time.Duration(c.StepUpTtlSeconds) * time.Second
+	}
+	if c.StepUpMaxAttempts > 0 {
+		r.maxAttempts = int64(c.StepUpMaxAttempts)
+	}
+	return r, nil
+}
+
+// assess 评估本次登录。查询设备失败时：开启二次验证则按达到阈值处理，否则按无风险处理，不影响登录
+func (r *LoginRisk) assess(ctx context.Context, user *model.User, client model.ClientInfo, deviceID string) *loginAssessment {
+	prefix := ipPrefix(client.IP, r.ipv4Bits, r.ipv6Bits)
+	a := &loginAssessment{
+		device: model.Device{
+			UserID:       user.ID,
+			Fingerprint:  deviceFingerprint(deviceID, client.UserAgent, prefix),
+			DeviceID:     deviceID,
+			UserAgent:    client.UserAgent,
+			IPPrefix:     prefix,
+			LocationHint: client.LocationHint,
+		},
+	}
+	if !r.enabled {
+		return a
+	}
+
+	devices, err := r.devices.ListDevices(ctx, user.ID)
+	if err != nil {
+		r.l.Warn("list user devices failed", zap.Int64("user_id", user.ID), zap.Error(err))
+		if r.stepUpThreshold > 0 {
+			a.add(model.LoginRiskUnavailable, r.stepUpThreshold)
+		}
+		return a
+	}
+	if len(devices) == 0 {
+		a.firstDevice = true
+		return a
+	}
+	events, err := r.devices.ListLoginEvents(ctx, user.ID, time.Now().Add(-r.history))
+	if err != nil {
+		r.l.Warn("list login events failed", zap.Int64("user_id", user.ID), zap.Error(err))
+	}
+
+	known := slices.ContainsFunc(devices, func(d *model.Device) bool { return d.Fingerprint == a.device.Fingerprint })
+	if !known {
+		a.add(model.LoginRiskNewDevice, r.newDeviceScore)
+	} else if prefix != "" && !seenIPPrefix(prefix, devices, events) {
+		a.add(model.LoginRiskNewNetwork, r.newNetworkScore)
+	}
+	if r.unusualHour(events, time.Now()) {
+		a.add(model.LoginRiskUnusualHour, r.unusualHourScore)
+	}
+	return a
+}
+
+func (a *loginAssessment) add(reason string, score int32) {
+	a.reasons = append(a.reasons, reason)
+	a.score += score
+}
+
+// requireStepUp 风险分达到阈值时需要二次验证
+func (r *LoginRisk) requireStepUp(a *loginAssessment) bool {
+	return r.enabled && r.stepUpThreshold > 0 && a.score >= r.stepUpThreshold
+}
+
+// unusualHour 历史登录足够多，且前后一小时内（UTC）都没有登录过
+func (r *LoginRisk) unusualHour(events []*model.LoginEvent, now time.Time) bool {
+	if len(events) < r.minLogins {
+		return false
+	}
+	hour := now.UTC().Hour()
+	for _, event := range events {
+		diff := (event.CreatedAt.UTC().Hour() - hour + 24) % 24
+		if diff <= 1 || diff == 23 {
+			return false
+		}
+	}
+	return true
+}
+
+// stepUpNotification 发送验证码的通知，code 为空时只用于判断能否送达
+func (r *LoginRisk) stepUpNotification(user *model.User, code string) *notify.Notification {
+	return &notify.Notification{
+		Kind:     notify.KindStepUp,
+		TenantID: user.TenantID,
+		UserID:   user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Subject:  "Your sign-in verification code",
+		Body: fmt.Sprintf("We noticed an unusual sign-in to %s. Enter this code to continue: %s\n\nThe code expires in %d minutes. If this was not you, change your password.\n",
+			user.Username, code, int(r.stepUpTTL.Minutes())),
+	}
+}
+
+// beginStepUp 发送验证码，登录在 VerifyStepUp 通过后才完成。
+// 用户没有可用的联系方式时不创建验证：按配置拒绝登录，或写审计日志后跳过，返回 nil 由调用方继续完成登录
+func (r *LoginRisk) beginStepUp(ctx context.Context, user *model.User, a *loginAssessment, authRequestID string) (*model.AuthResult, error) {
+	if !r.notifier.Reachable(r.stepUpNotification(user, "")) {
+		if r.allowUnreachable {
+			r.l.Warn("step-up skipped, code cannot be delivered", zap.Int64("user_id", user.ID), zap.Int32("risk_score", a.score), zap.Strings("reasons", a.reasons))
+			a.reasons = append(a.reasons, model.LoginRiskStepUpSkipped)
+			return nil, nil
+		}
+		r.l.Warn("login denied, step-up code cannot be delivered", zap.Int64("user_id", user.ID), zap.Int32("risk_score", a.score), zap.Strings("reasons", a.reasons))
+		return nil, connect.NewError(connect.CodePermissionDenied, errors.New("sign-in verification is not available for this account, contact your administrator"))
+	}
+
+	code, err := stepUpCode()
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	now := time.Now()
+	stepUp := &model.StepUp{
+		ID:            uuid.NewString(),
+		TenantID:      user.TenantID,
+		UserID:        user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		AuthRequestID: authRequestID,
+		Device:        a.device,
+		RiskScore:     a.score,
+		Reasons:       a.reasons,
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(r.stepUpTTL),
+	}
+	stepUp.CodeHash = hashToken(stepUp.ID + ":" + code)
+	if err := r.stepUps.CreateStepUp(ctx, stepUp); err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	if err := r.notifier.Notify(ctx, r.stepUpNotification(user, code)); err != nil {
+		// 发送失败可以重试登录，删除收不到验证码的验证
+		if _, delErr := r.stepUps.DeleteStepUp(ctx, stepUp.ID); delErr != nil {
+			r.l.Warn("delete step-up failed", zap.String("step_up_id", stepUp.ID), zap.Error(delErr))
+		}
+		return nil, connect.NewError(connect.CodeUnavailable, fmt.Errorf("send step-up code failed: %v", err))
+	}
+
+	r.l.Info("login requires step-up verification", zap.Int64("user_id", user.ID), zap.Int32("risk_score", a.score), zap.Strings("reasons", a.reasons))
+	return &model.AuthResult{
+		Code:     authResultStepUp,
+		State:    "step_up",
+		StepUpID: stepUp.ID,
+	}, nil
+}
+
+func (r *LoginRisk) VerifyStepUp(ctx context.Context, id, code string) (*model.AuthResult, error) {
+	stepUp, err := r.stepUps.GetStepUp(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrStepUpNotFound) {
+			return nil, connect.NewError(connect.CodeUnauthenticated, errInvalidStepUp)
+		}
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	// 只能在发起登录的租户下验证
+	if tenantID, err := model.TenantIDFromContext(ctx); err != nil || tenantID != stepUp.TenantID {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errInvalidStepUp)
+	}
+
+	attempts, err := r.stepUps.CountStepUpAttempt(ctx, id, max(time.Until(stepUp.ExpiresAt), time.Second))
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	if attempts > r.maxAttempts {
+		if _, err := r.stepUps.DeleteStepUp(ctx, id); err != nil {
+			r.l.Warn("delete step-up failed", zap.String("step_up_id", id), zap.Error(err))
+		}
+		return nil, connect.NewError(connect.CodeResourceExhausted, errors.New("too many verification attempts"))
+	}
+	if !constantTimeCompare(hashToken(id+":"+code), stepUp.CodeHash) {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("invalid verification code"))
+	}
+
+	// 并发验证时只有一个请求能删除成功
+	deleted, err := r.stepUps.DeleteStepUp(ctx, id)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	if !deleted {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errInvalidStepUp)
+	}
+
+	// 等待验证期间用户可能已被停用，与 SubmitAuth 一样拒绝
+	user, err := r.users.GetUserByName(ctx, stepUp.Username)
+	if err != nil && !errors.Is(err, model.ErrUserNotFound) {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	if err != nil || user.ID != stepUp.UserID || user.Disabled {
+		r.l.Warn("step-up rejected, user is disabled or removed", zap.Int64("user_id", stepUp.UserID))
+		return nil, errors.New("authentication failed")
+	}
+
+	jkt := model.ProofKeyFromContext(ctx)
+	token, err := r.tokens.IssueBound(stepUp.TenantID, stepUp.UserID, stepUp.Username, jkt)
+	if err != nil {
+		return nil, fmt.Errorf("generate token failed: %v", err)
+	}
+
+	r.recordLogin(ctx, user, &loginAssessment{device: stepUp.Device, score: stepUp.RiskScore, reasons: stepUp.Reasons}, true)
+	r.events.userLoggedIn(ctx, &model.UserEvent{
+		TenantID: user.TenantID,
+		UserID:   user.ID,
+		Username: user.Username,
+		StepUp:   true,
+	})
+	if stepUp.AuthRequestID != "" {
+		if requestToken, err := requesterToken(r.tokens, token, jkt, stepUp.TenantID, stepUp.UserID, stepUp.Username); err != nil {
+			r.l.Warn("generate auth request token failed", zap.String("auth_request_id", stepUp.AuthRequestID), zap.Error(err))
+		} else {
+			approveAuthRequest(ctx, r.authRequests, r.l, stepUp.AuthRequestID, stepUp.UserID, requestToken)
+		}
+	}
+
+	return &model.AuthResult{
+		Code:      "success",
+		State:     "authenticated",
+		AuthToken: token,
+		TokenType: model.TokenType(jkt),
+	}, nil
+}
+
+// recordLogin 记录设备和登录，陌生设备登录时通知用户，失败不影响登录
+func (r *LoginRisk) recordLogin(ctx context.Context, user *model.User, a *loginAssessment, stepUp bool) {
+	if !r.enabled {
+		return
+	}
+
+	device := a.device
+	if err := r.devices.UpsertDevice(ctx, &device); err != nil {
+		r.l.Warn("record user device failed", zap.Int64("user_id", user.ID), zap.Error(err))
+	}
+	if err := r.devices.CreateLoginEvent(ctx, &model.LoginEvent{
+		UserID:      user.ID,
+		Fingerprint: device.Fingerprint,
+		IPPrefix:    device.IPPrefix,
+		RiskScore:   a.score,
+		Reasons:     a.reasons,
+		StepUp:      stepUp,
+	}); err != nil {
+		r.l.Warn("record login event failed", zap.Int64("user_id", user.ID), zap.Error(err))
+	}
+
+	if a.firstDevice || !slices.Contains(a.reasons, model.LoginRiskNewDevice) {
+		return
+	}
+	if err := r.notifier.Notify(ctx, &notify.Notification{
+		Kind:     notify.KindNewSignIn,
+		TenantID: user.TenantID,
+		UserID:   user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Subject:  "New sign-in to your account",
+		Body: fmt.Sprintf("Your account %s was signed in from a new device.\n\nDevice: %s\nNetwork: %s\nLocation: %s\nTime: %s\n\nIf this was not you, change your password.\n",
+			user.Username, device.UserAgent, device.IPPrefix, device.LocationHint, time.Now().UTC().Format(time.RFC1123)),
+	}); err != nil {
+		r.l.Warn("send new sign-in notification failed", zap.Int64("user_id", user.ID), zap.Error(err))
+	}
+}
+
+// deviceFingerprint 客户端提供设备ID时不计入网段，切换网络不会被识别为新设备
+func deviceFingerprint(deviceID, userAgent, ipPrefix string) string {
+	input := "id:" + deviceID + "\x00ua:" + userAgent
+	if deviceID == "" {
+		input = "ua:" + userAgent + "\x00net:" + ipPrefix
+	}
+	sum := sha256.Sum256([]byte(input))
+	return hex.EncodeToString(sum[:])
+}
+
+// ipPrefix 返回 IP 所在网段，无法解析时返回空
+func ipPrefix(ip string, ipv4Bits, ipv6Bits int) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+	bits := ipv6Bits
+	if addr.Is4() {
+		bits = ipv4Bits
+	}
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return ""
+	}
+	return prefix.String()
+}
+
+func seenIPPrefix(prefix string, devices []*model.Device, events []*model.LoginEvent) bool {
+	return slices.ContainsFunc(devices, func(d *model.Device) bool { return d.IPPrefix == prefix }) ||
+		slices.ContainsFunc(events, func(e *model.LoginEvent) bool { return e.IPPrefix == prefix })
+}
+
+// stepUpCode 生成6位数字验证码
+func stepUpCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", fmt.Errorf("generate step-up code failed: %v", err)
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
@@ -0,0 +1,423 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/pkg/notify"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+// MockDeviceRepo 是 DeviceRepo 的模拟实现
+type MockDeviceRepo struct {
+	mock.Mock
+}
+
+func (m *MockDeviceRepo) ListDevices(ctx context.Context, userID int64) ([]*model.Device, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Device), args.Error(1)
+}
+
+func (m *MockDeviceRepo) UpsertDevice(ctx context.Context, device *model.Device) error {
+	args := m.Called(ctx, device)
+	return args.Error(0)
+}
+
+func (m *MockDeviceRepo) CreateLoginEvent(ctx context.Context, event *model.LoginEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockDeviceRepo) ListLoginEvents(ctx context.Context, userID int64, since time.Time) ([]*model.LoginEvent, error) {
+	args := m.Called(ctx, userID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.LoginEvent), args.Error(1)
+}
+
+// MockStepUpRepo 是 StepUpRepo 的模拟实现
+type MockStepUpRepo struct {
+	mock.Mock
+}
+
+func (m *MockStepUpRepo) CreateStepUp(ctx context.Context, stepUp *model.StepUp) error {
+	args := m.Called(ctx, stepUp)
+	return args.Error(0)
+}
+
+func (m *MockStepUpRepo) GetStepUp(ctx context.Context, id string) (*model.StepUp, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.StepUp), args.Error(1)
+}
+
+func (m *MockStepUpRepo) DeleteStepUp(ctx context.Context, id string) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockStepUpRepo) CountStepUpAttempt(ctx context.Context, id string, ttl time.Duration) (int64, error) {
+	args := m.Called(ctx, id, ttl)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockNotifier 是 notify.Notifier 的模拟实现
+type MockNotifier struct {
+	mock.Mock
+	unreachable bool
+}
+
+func (m *MockNotifier) Notify(ctx context.Context, n *notify.Notification) error {
+	args := m.Called(ctx, n)
+	return args.Error(0)
+}
+
+func (m *MockNotifier) Reachable(*notify.Notification) bool {
+	return !m.unreachable
+}
+
+// newDisabledLoginRisk 未开启登录风险识别，供其他用例的测试使用
+func newDisabledLoginRisk() *LoginRisk {
+	risk, _ := NewLoginRisk(nil, nil, nil, nil, nil, nil, nil, &conf.Bootstrap{}, zap.NewNop())
+	return risk
+}
+
+// LoginRiskTestSuite 是 LoginRisk 的测试套件
+type LoginRiskTestSuite struct {
+	suite.Suite
+	devices  *MockDeviceRepo
+	stepUps  *MockStepUpRepo
+	notifier *MockNotifier
+	userRepo *MockUserRepo
+	tokens   *TokenManager
+	hasher   *CredentialHasher
+	ctx      context.Context
+	client   model.ClientInfo
+}
+
+func (suite *LoginRiskTestSuite) SetupTest() {
+	suite.devices = new(MockDeviceRepo)
+	suite.stepUps = new(MockStepUpRepo)
+	suite.notifier = new(MockNotifier)
+	suite.userRepo = new(MockUserRepo)
+	suite.ctx = model.NewTenantContext(context.Background(), &model.Tenant{ID: 2})
+	suite.client = model.ClientInfo{IP: "203.0.113.7", UserAgent: "desktop/1.0", LocationHint: "CN"}
+
+	logger, _ := zap.NewDevelopment()
+	tokens, err := NewTokenManager(&conf.Bootstrap{Auth: &conf.Auth{JwtSecret: "test-secret"}}, logger)
+	assert.NoError(suite.T(), err)
+	suite.tokens = tokens
+	suite.hasher = newTestHasher(suite.T(), "pepper")
+}
+
+func (suite *LoginRiskTestSuite) newLoginRisk(cfg *conf.LoginRisk) *LoginRisk {
+	risk, err := NewLoginRisk(suite.userRepo, suite.devices, suite.stepUps, new(MockAuthRequestRepo), suite.tokens, suite.notifier, NewDomainEvents(new(MockOutboxRepo), zap.NewNop()), &conf.Bootstrap{LoginRisk: cfg}, zap.NewNop())
+	assert.NoError(suite.T(), err)
+	return risk
+}
+
+func (suite *LoginRiskTestSuite) newUseCase(risk *LoginRisk) *UserUseCase {
+	cfg := &conf.Bootstrap{Auth: &conf.Auth{}}
+	pow, err := NewProofOfWork(new(MockPowRepo), suite.tokens, cfg, zap.NewNop())
+	assert.NoError(suite.T(), err)
+	useCase, err := NewUserUseCase(suite.userRepo, new(MockAuthRequestRepo), new(MockInviteRepo), new(MockTransactor), suite.tokens, suite.hasher, new(CredentialBackends), pow, risk, NewDomainEvents(new(MockOutboxRepo), zap.NewNop()), cfg, zap.NewNop())
+	assert.NoError(suite.T(), err)
+	return useCase.(*UserUseCase)
+}
+
+// login 模拟一次凭证正确的 SubmitAuth
+func (suite *LoginRiskTestSuite) login(useCase *UserUseCase) (*model.AuthResult, error) {
+	storedHash, err := suite.hasher.Hash("hash")
+	assert.NoError(suite.T(), err)
+	suite.userRepo.On("GetAuthChallenge", suite.ctx, "testuser").Return("challenge", nil)
+	suite.userRepo.On("GetUserByName", suite.ctx, "testuser").Return(&model.User{
+		ID:           7,
+		TenantID:     2,
+		Username:     "testuser",
+		Email:        "test@example.com",
+		PasswordHash: storedHash,
+		KdfVersion:   1,
+	}, nil)
+
+	return useCase.SubmitAuth(suite.ctx, &model.SubmitAuthRequest{
+		Username:          "testuser",
+		HashedCredential:  "hash",
+		ChallengeResponse: computeChallengeResponse("challenge", "testuser"),
+		Client:            suite.client,
+	})
+}
+
+func (suite *LoginRiskTestSuite) TestNewLoginRisk_InvalidConfig() {
+	_, err := NewLoginRisk(nil, nil, nil, nil, nil, nil, nil, &conf.Bootstrap{LoginRisk: &conf.LoginRisk{Ipv4PrefixBits: 33}}, zap.NewNop())
+	assert.Error(suite.T(), err)
+
+	_, err = NewLoginRisk(nil, nil, nil, nil, nil, nil, nil, &conf.Bootstrap{LoginRisk: &conf.LoginRisk{StepUpThreshold: -1}}, zap.NewNop())
+	assert.Error(suite.T(), err)
+
+	_, err = NewLoginRisk(nil, nil, nil, nil, nil, nil, nil, &conf.Bootstrap{LoginRisk: &conf.LoginRisk{StepUpUnreachable: "ignore"}}, zap.NewNop())
+	assert.Error(suite.T(), err)
+}
+
+func (suite *LoginRiskTestSuite) TestFingerprint() {
+	assert.Equal(suite.T(), "203.0.113.0/24", ipPrefix("203.0.113.7", 24, 48))
+	assert.Equal(suite.T(), "2001:db8:1::/48", ipPrefix("2001:db8:1:2::1", 24, 48))
+	assert.Equal(suite.T(), "203.0.113.0/24", ipPrefix("::ffff:203.0.113.7", 24, 48))
+	assert.Equal(suite.T(), "", ipPrefix("not-an-ip", 24, 48))
+
+	// 有设备ID时切换网络仍是同一设备
+	assert.Equal(suite.T(), deviceFingerprint("dev-1", "ua", "203.0.113.0/24"), deviceFingerprint("dev-1", "ua", "198.51.100.0/24"))
+	assert.NotEqual(suite.T(), deviceFingerprint("", "ua", "203.0.113.0/24"), deviceFingerprint("", "ua", "198.51.100.0/24"))
+}
+
+func (suite *LoginRiskTestSuite) TestUnusualHour() {
+	risk := suite.newLoginRisk(&conf.LoginRisk{Enabled: true, UsualHoursMinLogins: 2})
+	at := func(hour int) *model.LoginEvent {
+		return &model.LoginEvent{CreatedAt: time.Date(2024, 1, 1, hour, 30, 0, 0, time.UTC)}
+	}
+	now := time.Date(2024, 2, 1, 3, 0, 0, 0, time.UTC)
+
+	// 历史不足时不判断
+	assert.False(suite.T(), risk.unusualHour([]*model.LoginEvent{at(12)}, now))
+	assert.True(suite.T(), risk.unusualHour([]*model.LoginEvent{at(12), at(13)}, now))
+	assert.False(suite.T(), risk.unusualHour([]*model.LoginEvent{at(12), at(2)}, now))
+	// 跨零点
+	assert.False(suite.T(), risk.unusualHour([]*model.LoginEvent{at(12), at(23)}, time.Date(2024, 2, 1, 0, 10, 0, 0, time.UTC)))
+}
+
+func (suite *LoginRiskTestSuite) TestSubmitAuth_FirstDevice() {
+	useCase := suite.newUseCase(suite.newLoginRisk(&conf.LoginRisk{Enabled: true, StepUpThreshold: 50}))
+	suite.devices.On("ListDevices", suite.ctx, int64(7)).Return([]*model.Device{}, nil)
+	suite.devices.On("UpsertDevice", suite.ctx, mock.AnythingOfType("*model.Device")).Return(nil)
+	suite.devices.On("CreateLoginEvent", suite.ctx, mock.AnythingOfType("*model.LoginEvent")).Return(nil)
+
+	result, err := suite.login(useCase)
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), result.AuthToken)
+	suite.devices.AssertCalled(suite.T(), "UpsertDevice", suite.ctx, mock.MatchedBy(func(device *model.Device) bool {
+		return device.UserID == 7 && device.IPPrefix == "203.0.113.0/24" && device.UserAgent == "desktop/1.0"
+	}))
+	suite.notifier.AssertNotCalled(suite.T(), "Notify", mock.Anything, mock.Anything)
+}
+
+func (suite *LoginRiskTestSuite) TestSubmitAuth_NewDeviceNotifies() {
+	useCase := suite.newUseCase(suite.newLoginRisk(&conf.LoginRisk{Enabled: true}))
+	suite.devices.On("ListDevices", suite.ctx, int64(7)).Return([]*model.Device{{Fingerprint: "other", IPPrefix: "198.51.100.0/24"}}, nil)
+	suite.devices.On("ListLoginEvents", suite.ctx, int64(7), mock.AnythingOfType("time.Time")).Return([]*model.LoginEvent{}, nil)
+	suite.devices.On("UpsertDevice", suite.ctx, mock.AnythingOfType("*model.Device")).Return(nil)
+	suite.devices.On("CreateLoginEvent", suite.ctx, mock.AnythingOfType("*model.LoginEvent")).Return(nil)
+	suite.notifier.On("Notify", suite.ctx, mock.AnythingOfType("*notify.Notification")).Return(nil)
+
+	result, err := suite.login(useCase)
+
+	// 未配置阈值时只提醒
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), result.AuthToken)
+	suite.devices.AssertCalled(suite.T(), "CreateLoginEvent", suite.ctx, mock.MatchedBy(func(event *model.LoginEvent) bool {
+		return event.RiskScore == 50 && assert.ObjectsAreEqual([]string{model.LoginRiskNewDevice}, event.Reasons) && !event.StepUp
+	}))
+	suite.notifier.AssertCalled(suite.T(), "Notify", suite.ctx, mock.MatchedBy(func(n *notify.Notification) bool {
+		return n.Kind == notify.KindNewSignIn && n.Email == "test@example.com" && n.UserID == 7
+	}))
+}
+
+func (suite *LoginRiskTestSuite) TestSubmitAuth_KnownDeviceNewNetwork() {
+	useCase := suite.newUseCase(suite.newLoginRisk(&conf.LoginRisk{Enabled: true, StepUpThreshold: 50}))
+	fingerprint := deviceFingerprint("", "desktop/1.0", "203.0.113.0/24")
+	// 指纹相同但记录的网段不同（例如网段配置调整后）
+	suite.devices.On("ListDevices", suite.ctx, int64(7)).Return([]*model.Device{{Fingerprint: fingerprint, IPPrefix: "198.51.100.0/24"}}, nil)
+	suite.devices.On("ListLoginEvents", suite.ctx, int64(7), mock.AnythingOfType("time.Time")).Return([]*model.LoginEvent{}, nil)
+	suite.devices.On("UpsertDevice", suite.ctx, mock.AnythingOfType("*model.Device")).Return(nil)
+	suite.devices.On("CreateLoginEvent", suite.ctx, mock.AnythingOfType("*model.LoginEvent")).Return(nil)
+
+	result, err := suite.login(useCase)
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), result.AuthToken)
+	suite.devices.AssertCalled(suite.T(), "CreateLoginEvent", suite.ctx, mock.MatchedBy(func(event *model.LoginEvent) bool {
+		return event.RiskScore == 20 && assert.ObjectsAreEqual([]string{model.LoginRiskNewNetwork}, event.Reasons)
+	}))
+	suite.notifier.AssertNotCalled(suite.T(), "Notify", mock.Anything, mock.Anything)
+}
+
+func (suite *LoginRiskTestSuite) TestStepUp() {
+	risk := suite.newLoginRisk(&conf.LoginRisk{Enabled: true, StepUpThreshold: 50})
+	useCase := suite.newUseCase(risk)
+	suite.devices.On("ListDevices", suite.ctx, int64(7)).Return([]*model.Device{{Fingerprint: "other"}}, nil)
+	suite.devices.On("ListLoginEvents", suite.ctx, int64(7), mock.AnythingOfType("time.Time")).Return([]*model.LoginEvent{}, nil)
+
+	var stepUp *model.StepUp
+	suite.stepUps.On("CreateStepUp", suite.ctx, mock.AnythingOfType("*model.StepUp")).Run(func(args mock.Arguments) {
+		stepUp = args.Get(1).(*model.StepUp)
+	}).Return(nil)
+	var code string
+	suite.notifier.On("Notify", suite.ctx, mock.MatchedBy(func(n *notify.Notification) bool {
+		return n.Kind == notify.KindStepUp
+	})).Run(func(args mock.Arguments) {
+		code = regexp.MustCompile(`\b\d{6}\b`).FindString(args.Get(1).(*notify.Notification).Body)
+	}).Return(nil)
+
+	result, err := suite.login(useCase)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), authResultStepUp, result.Code)
+	assert.Empty(suite.T(), result.AuthToken)
+	assert.Equal(suite.T(), stepUp.ID, result.StepUpID)
+	assert.Len(suite.T(), code, 6)
+	assert.Equal(suite.T(), "test@example.com", stepUp.Email)
+	suite.devices.AssertNotCalled(suite.T(), "UpsertDevice", mock.Anything, mock.Anything)
+
+	// 验证码正确后完成登录并提醒新设备
+	suite.stepUps.On("GetStepUp", suite.ctx, stepUp.ID).Return(stepUp, nil)
+	suite.stepUps.On("CountStepUpAttempt", suite.ctx, stepUp.ID, mock.AnythingOfType("time.Duration")).Return(int64(1), nil)
+	suite.stepUps.On("DeleteStepUp", suite.ctx, stepUp.ID).Return(true, nil)
+	suite.devices.On("UpsertDevice", suite.ctx, mock.AnythingOfType("*model.Device")).Return(nil)
+	suite.devices.On("CreateLoginEvent", suite.ctx, mock.AnythingOfType("*model.LoginEvent")).Return(nil)
+	suite.notifier.On("Notify", suite.ctx, mock.MatchedBy(func(n *notify.Notification) bool {
+		return n.Kind == notify.KindNewSignIn
+	})).Return(nil)
+
+	result, err = risk.VerifyStepUp(suite.ctx, stepUp.ID, code)
+
+	assert.NoError(suite.T(), err)
+	principal, err := suite.tokens.VerifyToken(suite.ctx, result.AuthToken)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(7), principal.UserID)
+	suite.devices.AssertCalled(suite.T(), "CreateLoginEvent", suite.ctx, mock.MatchedBy(func(event *model.LoginEvent) bool {
+		return event.StepUp && event.RiskScore == 50
+	}))
+	suite.notifier.AssertNumberOfCalls(suite.T(), "Notify", 2)
+}
+
+func (suite *LoginRiskTestSuite) TestStepUp_Unreachable() {
+	suite.notifier.unreachable = true
+	suite.devices.On("ListDevices", suite.ctx, int64(7)).Return([]*model.Device{{Fingerprint: "other"}}, nil)
+	suite.devices.On("ListLoginEvents", suite.ctx, int64(7), mock.AnythingOfType("time.Time")).Return([]*model.LoginEvent{}, nil)
+	suite.devices.On("UpsertDevice", suite.ctx, mock.AnythingOfType("*model.Device")).Return(nil)
+	suite.devices.On("CreateLoginEvent", suite.ctx, mock.AnythingOfType("*model.LoginEvent")).Return(nil)
+	suite.notifier.On("Notify", suite.ctx, mock.Anything).Return(notify.ErrNoRecipient)
+
+	// 默认拒绝登录
+	_, err := suite.login(suite.newUseCase(suite.newLoginRisk(&conf.LoginRisk{Enabled: true, StepUpThreshold: 50})))
+
+	assert.Equal(suite.T(), connect.CodePermissionDenied, connect.CodeOf(err))
+	suite.stepUps.AssertNotCalled(suite.T(), "CreateStepUp", mock.Anything, mock.Anything)
+
+	// 配置为 allow 时跳过二次验证，登录记录中注明
+	result, err := suite.login(suite.newUseCase(suite.newLoginRisk(&conf.LoginRisk{Enabled: true, StepUpThreshold: 50, StepUpUnreachable: StepUpUnreachableAllow})))
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), result.AuthToken)
+	suite.stepUps.AssertNotCalled(suite.T(), "CreateStepUp", mock.Anything, mock.Anything)
+	suite.devices.AssertCalled(suite.T(), "CreateLoginEvent", suite.ctx, mock.MatchedBy(func(event *model.LoginEvent) bool {
+		return !event.StepUp && assert.ObjectsAreEqual([]string{model.LoginRiskNewDevice, model.LoginRiskStepUpSkipped}, event.Reasons)
+	}))
+}
+
+func (suite *LoginRiskTestSuite) TestStepUp_NotifyFailed() {
+	useCase := suite.newUseCase(suite.newLoginRisk(&conf.LoginRisk{Enabled: true, StepUpThreshold: 50}))
+	suite.devices.On("ListDevices", suite.ctx, int64(7)).Return([]*model.Device{{Fingerprint: "other"}}, nil)
+	suite.devices.On("ListLoginEvents", suite.ctx, int64(7), mock.AnythingOfType("time.Time")).Return([]*model.LoginEvent{}, nil)
+	var stepUp *model.StepUp
+	suite.stepUps.On("CreateStepUp", suite.ctx, mock.AnythingOfType("*model.StepUp")).Run(func(args mock.Arguments) {
+		stepUp = args.Get(1).(*model.StepUp)
+	}).Return(nil)
+	suite.stepUps.On("DeleteStepUp", suite.ctx, mock.Anything).Return(true, nil)
+	suite.notifier.On("Notify", suite.ctx, mock.Anything).Return(errors.New("smtp down"))
+
+	_, err := suite.login(useCase)
+
+	assert.Equal(suite.T(), connect.CodeUnavailable, connect.CodeOf(err))
+	suite.stepUps.AssertCalled(suite.T(), "DeleteStepUp", suite.ctx, stepUp.ID)
+}
+
+func (suite *LoginRiskTestSuite) TestStepUp_DeviceLookupFailed() {
+	useCase := suite.newUseCase(suite.newLoginRisk(&conf.LoginRisk{Enabled: true, StepUpThreshold: 60}))
+	suite.devices.On("ListDevices", suite.ctx, int64(7)).Return(nil, errors.New("connection reset"))
+	suite.stepUps.On("CreateStepUp", suite.ctx, mock.AnythingOfType("*model.StepUp")).Return(nil)
+	suite.notifier.On("Notify", suite.ctx, mock.Anything).Return(nil)
+
+	// 开启二次验证时无法评估按高风险处理
+	result, err := suite.login(useCase)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), authResultStepUp, result.Code)
+	suite.stepUps.AssertCalled(suite.T(), "CreateStepUp", suite.ctx, mock.MatchedBy(func(stepUp *model.StepUp) bool {
+		return stepUp.RiskScore == 60 && assert.ObjectsAreEqual([]string{model.LoginRiskUnavailable}, stepUp.Reasons)
+	}))
+}
+
+func (suite *LoginRiskTestSuite) TestVerifyStepUp_Rejected() {
+	risk := suite.newLoginRisk(&conf.LoginRisk{Enabled: true, StepUpThreshold: 50, StepUpMaxAttempts: 3})
+	stepUp := &model.StepUp{
+		ID:        "step-1",
+		TenantID:  2,
+		UserID:    7,
+		CodeHash:  hashToken("step-1:123456"),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	suite.stepUps.On("GetStepUp", mock.Anything, "step-1").Return(stepUp, nil)
+	suite.stepUps.On("GetStepUp", mock.Anything, "missing").Return(nil, model.ErrStepUpNotFound)
+	suite.stepUps.On("CountStepUpAttempt", suite.ctx, "step-1", mock.AnythingOfType("time.Duration")).Return(int64(1), nil).Once()
+	suite.stepUps.On("CountStepUpAttempt", suite.ctx, "step-1", mock.AnythingOfType("time.Duration")).Return(int64(4), nil).Once()
+	suite.stepUps.On("DeleteStepUp", suite.ctx, "step-1").Return(true, nil)
+
+	_, err := risk.VerifyStepUp(suite.ctx, "missing", "123456")
+	assert.Equal(suite.T(), connect.CodeUnauthenticated, connect.CodeOf(err))
+
+	// 其他租户不能验证
+	other := model.NewTenantContext(context.Background(), &model.Tenant{ID: 3})
+	_, err = risk.VerifyStepUp(other, "step-1", "123456")
+	assert.Equal(suite.T(), connect.CodeUnauthenticated, connect.CodeOf(err))
+
+	_, err = risk.VerifyStepUp(suite.ctx, "step-1", "000000")
+	assert.Equal(suite.T(), connect.CodeUnauthenticated, connect.CodeOf(err))
+
+	// 超过尝试次数后作废
+	_, err = risk.VerifyStepUp(suite.ctx, "step-1", "123456")
+	assert.Equal(suite.T(), connect.CodeResourceExhausted, connect.CodeOf(err))
+	suite.stepUps.AssertCalled(suite.T(), "DeleteStepUp", suite.ctx, "step-1")
+}
+
+func (suite *LoginRiskTestSuite) TestVerifyStepUp_DisabledUser() {
+	risk := suite.newLoginRisk(&conf.LoginRisk{Enabled: true, StepUpThreshold: 50})
+	stepUp := &model.StepUp{
+		ID:        "step-1",
+		TenantID:  2,
+		UserID:    7,
+		Username:  "testuser",
+		CodeHash:  hashToken("step-1:123456"),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	suite.stepUps.On("GetStepUp", suite.ctx, "step-1").Return(stepUp, nil)
+	suite.stepUps.On("CountStepUpAttempt", suite.ctx, "step-1", mock.AnythingOfType("time.Duration")).Return(int64(1), nil)
+	suite.stepUps.On("DeleteStepUp", suite.ctx, "step-1").Return(true, nil)
+	// 等待验证期间被 SCIM 停用
+	suite.userRepo.On("GetUserByName", suite.ctx, "testuser").Return(&model.User{ID: 7, TenantID: 2, Username: "testuser", Disabled: true}, nil)
+
+	result, err := risk.VerifyStepUp(suite.ctx, "step-1", "123456")
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	suite.devices.AssertNotCalled(suite.T(), "CreateLoginEvent", mock.Anything, mock.Anything)
+}
+
+func TestLoginRiskTestSuite(t *testing.T) {
+	suite.Run(t, new(LoginRiskTestSuite))
+}
@@ -0,0 +1,308 @@
+package biz
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	netmail "net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data"
+	"connect-go-example/internal/pkg/mail"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const magicLinkPurpose = "magic-link"
+
+var errInvalidMagicLink = errors.New("invalid or used magic link")
+
+type MagicLinkUseCase struct {
+	repo   data.MagicLinkRepo
+	users  data.UserRepo
+	tokens *TokenManager
+	mailer mail.Mailer
+	events *DomainEvents
+	cfg    *conf.Auth
+	l      *zap.Logger
+	// registration domain 模式下发送注册票据
+	registration *registrationPolicy
+	verifyURL    string
+	// sending 后台发送中的邮件，测试中等待发送完成
+	sending sync.WaitGroup
+}
+
+// magicLinkSendTimeout 后台发送邮件的超时时间
+const magicLinkSendTimeout = 30 * time.Second
+
+func NewMagicLinkUseCase(repo data.MagicLinkRepo, users data.UserRepo, tokens *TokenManager, mailer mail.Mailer, events *DomainEvents, cfg *conf.Bootstrap, logger *zap.Logger) (model.MagicLinkUseCase, error) {
+	if cfg.Auth.MagicLinkUrl != "" {
+		if _, err := url.Parse(cfg.Auth.MagicLinkUrl); err != nil {
+			return nil, fmt.Errorf("invalid auth.magic_link_url: %v", err)
+		}
+	}
+	if cfg.GetRegistration().GetVerifyUrl() != "" {
+		if _, err := url.Parse(cfg.Registration.VerifyUrl); err != nil {
+			return nil, fmt.Errorf("invalid registration.verify_url: %v", err)
+		}
+	}
+	registration, err := newRegistrationPolicy(cfg.Registration)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MagicLinkUseCase{
+		repo:         repo,
+		users:        users,
+		tokens:       tokens,
+		mailer:       mailer,
+		events:       events,
+		cfg:          cfg.Auth,
+		l:            logger,
+		registration: registration,
+		verifyURL:    cfg.GetRegistration().GetVerifyUrl(),
+	}, nil
+}
+
+func (uc *MagicLinkUseCase) RequestMagicLink(ctx context.Context, email string) (*model.MagicLinkTicket, error) {
+	if uc.cfg.MagicLinkUrl == "" {
+		return nil, connect.NewError(connect.CodeFailedPrecondition, errors.New("magic link login is not configured"))
+	}
+
+	addr, err := netmail.ParseAddress(email)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid email address"))
+	}
+	email = strings.ToLower(addr.Address)
+
+	// 按邮箱限流，未注册的邮箱同样计数，避免通过限流结果判断用户是否存在
+	if err := uc.checkRate(ctx, email); err != nil {
+		return nil, err
+	}
+
+	timeout := time.Duration(uc.cfg.MagicLinkTimeoutSeconds) * time.Second
+	if timeout == 0 {
+		timeout = 15 * time.Minute // 默认15分钟
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("generate magic link nonce failed: %v", err)
+	}
+	now := time.Now()
+	ticket := &model.MagicLinkTicket{
+		Nonce:     base64.RawURLEncoding.EncodeToString(raw),
+		ExpiresAt: now.Add(timeout),
+	}
+
+	user, err := uc.users.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			uc.l.Debug("magic link requested for unknown email")
+			return ticket, nil
+		}
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	if user.Disabled {
+		uc.l.Debug("magic link requested for disabled user", zap.Int64("user_id", user.ID))
+		return ticket, nil
+	}
+
+	link := &model.MagicLink{
+		ID:        uuid.NewString(),
+		TenantID:  user.TenantID,
+		UserID:    user.ID,
+		Username:  user.Username,
+		Email:     email,
+		NonceHash: hashToken(ticket.Nonce),
+		CreatedAt: now,
+		ExpiresAt: ticket.ExpiresAt,
+	}
+	if err := uc.repo.CreateMagicLink(ctx, link); err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	uc.sendAsync(ctx, &mail.Message{
+		To:      email,
+		Subject: "Your sign-in link",
+		Body: fmt.Sprintf("Open the link below to sign in. It expires in %d minutes and only works once, in the browser where you requested it.\n\n%s\n\nIf you did not request this email, you can ignore it.\n",
+			int(timeout.Minutes()), uc.linkURL(uc.magicLinkToken(link))),
+	})
+
+	return ticket, nil
+}
+
+func (uc *MagicLinkUseCase) RequestRegistrationEmail(ctx context.Context, email string) (time.Time, error) {
+	if uc.registration.mode != RegistrationModeDomain {
+		return time.Time{}, connect.NewError(connect.CodeFailedPrecondition, errors.New("email verification is only used in domain registration mode"))
+	}
+	email, err := uc.registration.allowedEmail(email)
+	if err != nil {
+		return time.Time{}, err
+	}
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return time.Time{}, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	// 与登录链接共用按邮箱的限流，避免向他人邮箱大量发送邮件
+	if err := uc.checkRate(ctx, email); err != nil {
+		return time.Time{}, err
+	}
+
+	expiresAt := time.Now().Add(uc.registration.emailTicketTTL)
+	ticket := emailTicket(uc.tokens, tenantID, email, expiresAt)
+	body := fmt.Sprintf("Use the code below to finish creating your account. It expires in %d minutes.\n\n%s\n\nIf you did not try to register, you can ignore this email.\n",
+		int(uc.registration.emailTicketTTL.Minutes()), ticket)
+	if uc.verifyURL != "" {
+		u, _ := url.Parse(uc.verifyURL)
+		query := u.Query()
+		query.Set("email", email)
+		query.Set("email_ticket", ticket)
+		u.RawQuery = query.Encode()
+		body = fmt.Sprintf("Open the link below to finish creating your account. It expires in %d minutes.\n\n%s\n\nIf you did not try to register, you can ignore this email.\n",
+			int(uc.registration.emailTicketTTL.Minutes()), u.String())
+	}
+	uc.sendAsync(ctx, &mail.Message{To: email, Subject: "Confirm your email address", Body: body})
+
+	return expiresAt, nil
+}
+
+// checkRate 按邮箱限制发送邮件的次数
+func (uc *MagicLinkUseCase) checkRate(ctx context.Context, email string) error {
+	window := time.Duration(uc.cfg.MagicLinkRateWindowSeconds) * time.Second
+	if window == 0 {
+		window = time.Hour // 默认1小时
+	}
+	maxRequests := uc.cfg.MagicLinkMaxRequests
+	if maxRequests == 0 {
+		maxRequests = 5 // 默认每小时5次
+	}
+	count, err := uc.repo.CountMagicLinkRequest(ctx, email, window)
+	if err != nil {
+		return connect.NewError(connect.CodeInternal, err)
+	}
+	if count > maxRequests {
+		return connect.NewError(connect.CodeResourceExhausted, errors.New("too many magic link requests"))
+	}
+	return nil
+}
+
+// sendAsync 在后台发送邮件，响应时间不取决于邮箱是否已注册；发送失败只记录日志，用户可以重新请求
+func (uc *MagicLinkUseCase) sendAsync(ctx context.Context, msg *mail.Message) {
+	ctx = context.WithoutCancel(ctx)
+	uc.sending.Go(func() {
+		ctx, cancel := context.WithTimeout(ctx, magicLinkSendTimeout)
+		defer cancel()
+		if err := uc.mailer.Send(ctx, msg); err != nil {
+			uc.l.Warn("send magic link failed", zap.Error(err))
+		}
+	})
+}
+
+func (uc *MagicLinkUseCase) ExchangeMagicLink(ctx context.Context, token, nonce string) (*model.AuthResult, error) {
+	id, expiresAt, ok := uc.parseMagicLinkToken(token)
+	if !ok {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errInvalidMagicLink)
+	}
+	if time.Now().After(expiresAt) {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("magic link expired"))
+	}
+
+	link, err := uc.repo.GetMagicLink(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrMagicLinkNotFound) {
+			return nil, connect.NewError(connect.CodeUnauthenticated, errInvalidMagicLink)
+		}
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	// 链接只能在发出它的租户下兑换
+	if tenantID, err := model.TenantIDFromContext(ctx); err != nil || tenantID != link.TenantID {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errInvalidMagicLink)
+	}
+	if !constantTimeCompare(hashToken(nonce), link.NonceHash) {
+		return nil, connect.NewError(connect.CodePermissionDenied, errors.New("magic link must be opened in the requesting browser"))
+	}
+
+	// 并发兑换时只有一个请求能删除成功
+	consumed, err := uc.repo.ConsumeMagicLink(ctx, id)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	if !consumed {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errInvalidMagicLink)
+	}
+
+	// 链接发出后用户可能已被停用，与 SubmitAuth 一样拒绝
+	user, err := uc.users.GetUserByName(ctx, link.Username)
+	if err != nil && !errors.Is(err, model.ErrUserNotFound) {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	if err != nil || user.ID != link.UserID || user.Disabled {
+		uc.l.Warn("magic link rejected, user is disabled or removed", zap.Int64("user_id", link.UserID))
+		return nil, errors.New("authentication failed")
+	}
+
+	// 能打开邮件中的链接，说明邮箱归用户所有
+	if err := uc.users.MarkEmailVerified(ctx, &model.User{ID: user.ID, Username: user.Username, Email: link.Email}); err != nil {
+		uc.l.Warn("mark email verified failed", zap.Int64("user_id", link.UserID), zap.Error(err))
+	}
+
+	jkt := model.ProofKeyFromContext(ctx)
+	authToken, err := uc.tokens.IssueBound(link.TenantID, link.UserID, link.Username, jkt)
+	if err != nil {
+		return nil, fmt.Errorf("generate token failed: %v", err)
+	}
+	uc.events.userLoggedIn(ctx, &model.UserEvent{
+		TenantID: link.TenantID,
+		UserID:   link.UserID,
+		Username: link.Username,
+		Source:   model.EventSourceMagicLink,
+	})
+
+	return &model.AuthResult{
+		Code:      "success",
+		State:     "authenticated",
+		AuthToken: authToken,
+		TokenType: model.TokenType(jkt),
+	}, nil
+}
+
+// magicLinkToken 生成链接中的令牌：<id>.<过期时间>.<签名>
+func (uc *MagicLinkUseCase) magicLinkToken(link *model.MagicLink) string {
+	payload := link.ID + "." + strconv.FormatInt(link.ExpiresAt.Unix(), 10)
+	return payload + "." + uc.tokens.sign(magicLinkPurpose, payload)
+}
+
+func (uc *MagicLinkUseCase) parseMagicLinkToken(token string) (string, time.Time, bool) {
+	id, rest, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", time.Time{}, false
+	}
+	exp, signature, ok := strings.Cut(rest, ".")
+	if !ok || !uc.tokens.verifySignature(magicLinkPurpose, id+"."+exp, signature) {
+		return "", time.Time{}, false
+	}
+	expUnix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return id, time.Unix(expUnix, 0), true
+}
+
+func (uc *MagicLinkUseCase) linkURL(token string) string {
+	u, _ := url.Parse(uc.cfg.MagicLinkUrl)
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
@@ -0,0 +1,291 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/pkg/mail"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+// MockMagicLinkRepo 是 MagicLinkRepo 的模拟实现
+type MockMagicLinkRepo struct {
+	mock.Mock
+}
+
+func (m *MockMagicLinkRepo) CreateMagicLink(ctx context.Context, link *model.MagicLink) error {
+	args := m.Called(ctx, link)
+	return args.Error(0)
+}
+
+func (m *MockMagicLinkRepo) GetMagicLink(ctx context.Context, id string) (*model.MagicLink, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.MagicLink), args.Error(1)
+}
+
+func (m *MockMagicLinkRepo) ConsumeMagicLink(ctx context.Context, id string) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMagicLinkRepo) CountMagicLinkRequest(ctx context.Context, email string, window time.Duration) (int64, error) {
+	args := m.Called(ctx, email, window)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockMailer 是 Mailer 的模拟实现
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Send(ctx context.Context, msg *mail.Message) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+// MagicLinkUseCaseTestSuite 是 MagicLinkUseCase 的测试套件
+type MagicLinkUseCaseTestSuite struct {
+	suite.Suite
+	repo     *MockMagicLinkRepo
+	userRepo *MockUserRepo
+	mailer   *MockMailer
+	tokens   *TokenManager
+	useCase  *MagicLinkUseCase
+}
+
+func (suite *MagicLinkUseCaseTestSuite) SetupTest() {
+	suite.repo = new(MockMagicLinkRepo)
+	suite.userRepo = new(MockUserRepo)
+	suite.mailer = new(MockMailer)
+	logger, _ := zap.NewDevelopment()
+
+	cfg := &conf.Bootstrap{
+		Auth: &conf.Auth{
+			JwtSecret:                  "test-secret",
+			MagicLinkUrl:               "https://example.com/magic",
+			MagicLinkMaxRequests:       3,
+			MagicLinkRateWindowSeconds: 600,
+		},
+	}
+	tokens, err := NewTokenManager(cfg, logger)
+	assert.NoError(suite.T(), err)
+	suite.tokens = tokens
+
+	useCase, err := NewMagicLinkUseCase(suite.repo, suite.userRepo, tokens, suite.mailer, NewDomainEvents(new(MockOutboxRepo), zap.NewNop()), cfg, logger)
+	assert.NoError(suite.T(), err)
+	suite.useCase = useCase.(*MagicLinkUseCase)
+}
+
+// requestLink 请求一个登录链接并返回邮件中的 token
+func (suite *MagicLinkUseCaseTestSuite) requestLink(ctx context.Context) (*model.MagicLinkTicket, *model.MagicLink, string) {
+	var link *model.MagicLink
+	var sent *mail.Message
+	suite.repo.On("CountMagicLinkRequest", ctx, "alice@example.com", 10*time.Minute).Return(int64(1), nil)
+	suite.userRepo.On("GetUserByEmail", ctx, "alice@example.com").Return(&model.User{ID: 7, TenantID: 2, Username: "alice"}, nil)
+	suite.repo.On("CreateMagicLink", ctx, mock.AnythingOfType("*model.MagicLink")).Run(func(args mock.Arguments) {
+		link = args.Get(1).(*model.MagicLink)
+	}).Return(nil)
+	suite.mailer.On("Send", mock.Anything, mock.AnythingOfType("*mail.Message")).Run(func(args mock.Arguments) {
+		sent = args.Get(1).(*mail.Message)
+	}).Return(nil)
+
+	ticket, err := suite.useCase.RequestMagicLink(ctx, "Alice <Alice@Example.com>")
+	assert.NoError(suite.T(), err)
+	suite.useCase.sending.Wait()
+	assert.Equal(suite.T(), "alice@example.com", sent.To)
+
+	// 从邮件正文中取出链接
+	start := strings.Index(sent.Body, "https://")
+	end := strings.Index(sent.Body[start:], "\n")
+	u, err := url.Parse(sent.Body[start : start+end])
+	assert.NoError(suite.T(), err)
+
+	return ticket, link, u.Query().Get("token")
+}
+
+func (suite *MagicLinkUseCaseTestSuite) TestRequestMagicLink() {
+	ctx := model.NewTenantContext(context.Background(), &model.Tenant{ID: 2})
+
+	ticket, link, token := suite.requestLink(ctx)
+
+	assert.NotEmpty(suite.T(), ticket.Nonce)
+	assert.Equal(suite.T(), int64(7), link.UserID)
+	assert.Equal(suite.T(), hashToken(ticket.Nonce), link.NonceHash)
+	assert.True(suite.T(), strings.HasPrefix(token, link.ID+"."))
+}
+
+func (suite *MagicLinkUseCaseTestSuite) TestRequestMagicLink_UnknownEmail() {
+	ctx := model.NewTenantContext(context.Background(), &model.Tenant{ID: 2})
+	suite.repo.On("CountMagicLinkRequest", ctx, "nobody@example.com", 10*time.Minute).Return(int64(1), nil)
+	suite.userRepo.On("GetUserByEmail", ctx, "nobody@example.com").Return(nil, model.ErrUserNotFound)
+
+	ticket, err := suite.useCase.RequestMagicLink(ctx, "nobody@example.com")
+
+	// 与已注册邮箱的响应一致
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), ticket.Nonce)
+	suite.useCase.sending.Wait()
+	suite.mailer.AssertNotCalled(suite.T(), "Send", mock.Anything, mock.Anything)
+}
+
+func (suite *MagicLinkUseCaseTestSuite) TestRequestMagicLink_DoesNotWaitForMail() {
+	ctx := model.NewTenantContext(context.Background(), &model.Tenant{ID: 2})
+	suite.repo.On("CountMagicLinkRequest", ctx, "alice@example.com", 10*time.Minute).Return(int64(1), nil)
+	suite.userRepo.On("GetUserByEmail", ctx, "alice@example.com").Return(&model.User{ID: 7, TenantID: 2, Username: "alice"}, nil)
+	suite.repo.On("CreateMagicLink", ctx, mock.AnythingOfType("*model.MagicLink")).Return(nil)
+	release := make(chan struct{})
+	suite.mailer.On("Send", mock.Anything, mock.Anything).Run(func(mock.Arguments) { <-release }).Return(errors.New("smtp down"))
+
+	// 发送邮件不阻塞响应，失败也不返回给调用方
+	ticket, err := suite.useCase.RequestMagicLink(ctx, "alice@example.com")
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), ticket.Nonce)
+	close(release)
+	suite.useCase.sending.Wait()
+}
+
+func (suite *MagicLinkUseCaseTestSuite) TestRequestMagicLink_RateLimited() {
+	ctx := model.NewTenantContext(context.Background(), &model.Tenant{ID: 2})
+	suite.repo.On("CountMagicLinkRequest", ctx, "alice@example.com", 10*time.Minute).Return(int64(4), nil)
+
+	_, err := suite.useCase.RequestMagicLink(ctx, "alice@example.com")
+
+	assert.Equal(suite.T(), connect.CodeResourceExhausted, connect.CodeOf(err))
+	suite.userRepo.AssertNotCalled(suite.T(), "GetUserByEmail", mock.Anything, mock.Anything)
+}
+
+func (suite *MagicLinkUseCaseTestSuite) TestRequestMagicLink_InvalidEmail() {
+	_, err := suite.useCase.RequestMagicLink(context.Background(), "not-an-email")
+
+	assert.Equal(suite.T(), connect.CodeInvalidArgument, connect.CodeOf(err))
+}
+
+func (suite *MagicLinkUseCaseTestSuite) TestRequestRegistrationEmail() {
+	ctx := model.NewTenantContext(context.Background(), &model.Tenant{ID: 2})
+	cfg := &conf.Bootstrap{
+		Auth:         &conf.Auth{JwtSecret: "test-secret"},
+		Registration: &conf.Registration{Mode: RegistrationModeDomain, AllowedDomains: []string{"example.com"}, VerifyUrl: "https://example.com/register"},
+	}
+	useCase, err := NewMagicLinkUseCase(suite.repo, suite.userRepo, suite.tokens, suite.mailer, NewDomainEvents(new(MockOutboxRepo), zap.NewNop()), cfg, zap.NewNop())
+	suite.Require().NoError(err)
+	uc := useCase.(*MagicLinkUseCase)
+
+	suite.repo.On("CountMagicLinkRequest", ctx, "alice@example.com", time.Hour).Return(int64(1), nil)
+	var body string
+	suite.mailer.On("Send", mock.Anything, mock.AnythingOfType("*mail.Message")).Run(func(args mock.Arguments) {
+		body = args.Get(1).(*mail.Message).Body
+	}).Return(nil)
+
+	expiresAt, err := uc.RequestRegistrationEmail(ctx, "Alice@Example.com")
+	uc.sending.Wait()
+
+	assert.NoError(suite.T(), err)
+	assert.WithinDuration(suite.T(), time.Now().Add(time.Hour), expiresAt, time.Minute)
+	link, err := url.Parse(strings.TrimSpace(strings.Split(body, "\n\n")[1]))
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "alice@example.com", link.Query().Get("email"))
+	assert.True(suite.T(), verifyEmailTicket(ctx, suite.tokens, "alice@example.com", link.Query().Get("email_ticket")))
+	// 不查询用户表，不透露邮箱是否已注册
+	suite.userRepo.AssertNotCalled(suite.T(), "GetUserByEmail", mock.Anything, mock.Anything)
+
+	_, err = uc.RequestRegistrationEmail(ctx, "alice@evil.com")
+	assert.Equal(suite.T(), connect.CodePermissionDenied, connect.CodeOf(err))
+
+	// 非 domain 模式不需要邮箱票据
+	_, err = suite.useCase.RequestRegistrationEmail(ctx, "alice@example.com")
+	assert.Equal(suite.T(), connect.CodeFailedPrecondition, connect.CodeOf(err))
+}
+
+func (suite *MagicLinkUseCaseTestSuite) TestExchangeMagicLink() {
+	ctx := model.NewTenantContext(context.Background(), &model.Tenant{ID: 2})
+	ticket, link, token := suite.requestLink(ctx)
+	suite.repo.On("GetMagicLink", ctx, link.ID).Return(link, nil)
+	suite.repo.On("ConsumeMagicLink", ctx, link.ID).Return(true, nil).Once()
+	suite.userRepo.On("GetUserByName", ctx, "alice").Return(&model.User{ID: 7, TenantID: 2, Username: "alice"}, nil).Once()
+	suite.userRepo.On("MarkEmailVerified", ctx, &model.User{ID: 7, Username: "alice", Email: "alice@example.com"}).Return(nil).Once()
+
+	result, err := suite.useCase.ExchangeMagicLink(ctx, token, ticket.Nonce)
+
+	assert.NoError(suite.T(), err)
+	suite.userRepo.AssertExpectations(suite.T())
+	principal, err := suite.tokens.VerifyToken(ctx, result.AuthToken)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(7), principal.UserID)
+	assert.Equal(suite.T(), int64(2), principal.TenantID)
+	assert.Equal(suite.T(), "alice", principal.Username)
+
+	// 第二次兑换失败
+	suite.repo.On("ConsumeMagicLink", ctx, link.ID).Return(false, nil)
+	_, err = suite.useCase.ExchangeMagicLink(ctx, token, ticket.Nonce)
+	assert.Equal(suite.T(), connect.CodeUnauthenticated, connect.CodeOf(err))
+}
+
+func (suite *MagicLinkUseCaseTestSuite) TestExchangeMagicLink_DisabledUser() {
+	ctx := model.NewTenantContext(context.Background(), &model.Tenant{ID: 2})
+	ticket, link, token := suite.requestLink(ctx)
+	suite.repo.On("GetMagicLink", ctx, link.ID).Return(link, nil)
+	suite.repo.On("ConsumeMagicLink", ctx, link.ID).Return(true, nil).Once()
+	// 链接发出后用户经 SCIM 停用
+	suite.userRepo.On("GetUserByName", ctx, "alice").Return(&model.User{ID: 7, TenantID: 2, Username: "alice", Disabled: true}, nil).Once()
+
+	result, err := suite.useCase.ExchangeMagicLink(ctx, token, ticket.Nonce)
+
+	assert.Nil(suite.T(), result)
+	assert.EqualError(suite.T(), err, "authentication failed")
+	suite.userRepo.AssertNotCalled(suite.T(), "MarkEmailVerified", mock.Anything, mock.Anything)
+}
+
+func (suite *MagicLinkUseCaseTestSuite) TestExchangeMagicLink_WrongNonce() {
+	ctx := model.NewTenantContext(context.Background(), &model.Tenant{ID: 2})
+	_, link, token := suite.requestLink(ctx)
+	suite.repo.On("GetMagicLink", ctx, link.ID).Return(link, nil)
+
+	_, err := suite.useCase.ExchangeMagicLink(ctx, token, "another-browser")
+
+	assert.Equal(suite.T(), connect.CodePermissionDenied, connect.CodeOf(err))
+	suite.repo.AssertNotCalled(suite.T(), "ConsumeMagicLink", mock.Anything, mock.Anything)
+}
+
+func (suite *MagicLinkUseCaseTestSuite) TestExchangeMagicLink_OtherTenant() {
+	ctx := model.NewTenantContext(context.Background(), &model.Tenant{ID: 2})
+	ticket, link, token := suite.requestLink(ctx)
+	suite.repo.On("GetMagicLink", mock.Anything, link.ID).Return(link, nil)
+
+	// 其他租户下兑换失败
+	otherCtx := model.NewTenantContext(context.Background(), &model.Tenant{ID: 3})
+	_, err := suite.useCase.ExchangeMagicLink(otherCtx, token, ticket.Nonce)
+
+	assert.Equal(suite.T(), connect.CodeUnauthenticated, connect.CodeOf(err))
+	suite.repo.AssertNotCalled(suite.T(), "ConsumeMagicLink", mock.Anything, mock.Anything)
+}
+
+func (suite *MagicLinkUseCaseTestSuite) TestExchangeMagicLink_TamperedToken() {
+	ctx := model.NewTenantContext(context.Background(), &model.Tenant{ID: 2})
+	ticket, link, token := suite.requestLink(ctx)
+
+	// 修改过期时间后签名失效
+	parts := strings.Split(token, ".")
+	tampered := link.ID + ".9999999999." + parts[2]
+	_, err := suite.useCase.ExchangeMagicLink(ctx, tampered, ticket.Nonce)
+
+	assert.Equal(suite.T(), connect.CodeUnauthenticated, connect.CodeOf(err))
+	suite.repo.AssertNotCalled(suite.T(), "GetMagicLink", mock.Anything, mock.Anything)
+}
+
+func TestMagicLinkUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(MagicLinkUseCaseTestSuite))
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrAuthRequestNotFound = errors.New("auth request not found")
+	ErrAuthRequestSettled  = errors.New("auth request already settled")
+)
+
+// AuthRequestState 登录请求状态
+type AuthRequestState int32
+
+const (
+	AuthRequestStateUnspecified AuthRequestState = iota
+	AuthRequestStatePending
+	AuthRequestStateApproved
+	AuthRequestStateDenied
+	AuthRequestStateExpired
+)
+
+// Terminal 是否为终态，终态之后不会再有状态变化
+func (s AuthRequestState) Terminal() bool {
+	return s == AuthRequestStateApproved || s == AuthRequestStateDenied || s == AuthRequestStateExpired
+}
+
+// AuthRequest 登录请求，由桌面端或 CLI 创建，在浏览器中完成登录后批准
+type AuthRequest struct {
+	ID             string
+	State          AuthRequestState
+	ClientName     string
+	WatchTokenHash string
+	UserID         int64
+	AuthToken      string
+	CreatedAt      time.Time
+	ExpiresAt      time.Time
+}
+
+// AuthRequestTicket 创建登录请求后返回给发起方的凭据
+type AuthRequestTicket struct {
+	ID         string
+	WatchToken string
+	ExpiresAt  time.Time
+}
+
+// AuthRequestUseCase 登录请求用例接口
+type AuthRequestUseCase interface {
+	CreateAuthRequest(ctx context.Context, clientName string) (*AuthRequestTicket, error)
+	// WatchAuthRequest 推送登录请求的状态变化，直到进入终态或 ctx 结束
+	WatchAuthRequest(ctx context.Context, id, watchToken string, send func(*AuthRequest) error) error
+	// DenyAuthRequest 发起方凭 watchToken 取消，或由 ctx 中已登录的用户拒绝
+	DenyAuthRequest(ctx context.Context, id, watchToken string) error
+}
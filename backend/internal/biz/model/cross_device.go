@@ -0,0 +1,34 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrCrossDeviceLoginNotFound = errors.New("cross-device login not found")
+
+// CrossDeviceLogin 扫码登录记录，关联一个等待批准的登录请求
+type CrossDeviceLogin struct {
+	Code          string
+	AuthRequestID string
+	ClientName    string
+	Requester     ClientInfo
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+}
+
+// CrossDeviceLoginTicket 返回给发起设备的二维码信息和订阅凭据
+type CrossDeviceLoginTicket struct {
+	Code        string
+	ApproveURL  string
+	AuthRequest AuthRequestTicket
+}
+
+// CrossDeviceLoginUseCase 扫码登录用例接口
+type CrossDeviceLoginUseCase interface {
+	CreateCrossDeviceLogin(ctx context.Context, clientName string, client ClientInfo) (*CrossDeviceLoginTicket, error)
+	GetCrossDeviceLogin(ctx context.Context, code string) (*CrossDeviceLogin, error)
+	// ApproveCrossDeviceLogin 由已登录用户批准或拒绝，批准后发起设备以该用户身份登录
+	ApproveCrossDeviceLogin(ctx context.Context, approver *Principal, code string, approve bool) (*CrossDeviceLogin, error)
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	// TokenTypeBearer 未绑定密钥的令牌
+	TokenTypeBearer = "Bearer"
+	// TokenTypeDPoP 绑定到客户端密钥的令牌（RFC 9449），调用时需同时携带 DPoP 证明
+	TokenTypeDPoP = "DPoP"
+)
+
+var ErrInvalidDPoPProof = errors.New("invalid dpop proof")
+
+// DPoPProof 待校验的 DPoP 证明及其所在的请求
+type DPoPProof struct {
+	Proof       string // DPoP 头
+	Method      string // 请求方法，对应 htm
+	Path        string // 请求路径，对应 htu
+	AccessToken string // 同时携带的访问令牌，用于校验 ath，获取令牌时为空
+}
+
+// DPoPVerifier 校验 DPoP 证明，返回证明密钥的 JWK 指纹（jkt）
+type DPoPVerifier interface {
+	VerifyProof(ctx context.Context, proof *DPoPProof) (string, error)
+}
+
+type proofKeyKey struct{}
+
+// NewProofKeyContext 把已校验的 DPoP 密钥指纹写入 ctx，签发令牌时绑定到该密钥
+func NewProofKeyContext(ctx context.Context, jkt string) context.Context {
+	return context.WithValue(ctx, proofKeyKey{}, jkt)
+}
+
+// ProofKeyFromContext 从 ctx 中取出 DPoP 密钥指纹，请求未携带证明时返回空
+func ProofKeyFromContext(ctx context.Context) string {
+	jkt, _ := ctx.Value(proofKeyKey{}).(string)
+	return jkt
+}
+
+// TokenType 根据令牌是否绑定密钥返回令牌类型
+func TokenType(jkt string) string {
+	if jkt != "" {
+		return TokenTypeDPoP
+	}
+	return TokenTypeBearer
+}
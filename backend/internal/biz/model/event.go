@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// 领域事件类型
+const (
+	EventUserRegistered  = "UserRegistered"
+	EventUserLoggedIn    = "UserLoggedIn"
+	EventPasswordChanged = "PasswordChanged"
+	EventUserDisabled    = "UserDisabled"
+)
+
+// AggregateUser 用户事件的聚合类型，聚合ID为用户ID
+const AggregateUser = "user"
+
+// 触发用户事件的途径
+const (
+	EventSourcePassword   = "password"
+	EventSourceDirectory  = "directory"
+	EventSourceMagicLink  = "magic_link"
+	EventSourceFederation = "federation"
+	EventSourceScim       = "scim"
+	EventSourceKdfUpgrade = "kdf_upgrade"
+	EventSourceRehash     = "rehash"
+)
+
+// DomainEvent 写入发件箱的领域事件，同一聚合的事件按 ID 顺序投递
+type DomainEvent struct {
+	ID            int64
+	TenantID      int64
+	AggregateType string
+	AggregateID   string
+	Type          string
+	Payload       []byte // JSON
+	CreatedAt     time.Time
+}
+
+// UserEvent 用户事件的内容。事件会保存在发件箱和投递记录中并发送给外部系统，不包含邮箱等个人信息，
+// 需要时由订阅方按 userId 查询
+type UserEvent struct {
+	TenantID int64  `json:"tenantId"`
+	UserID   int64  `json:"userId"`
+	Username string `json:"username"`
+	Source   string `json:"source,omitempty"`
+	// StepUp 登录前完成了二次验证，只用于 UserLoggedIn
+	StepUp bool `json:"stepUp,omitempty"`
+}
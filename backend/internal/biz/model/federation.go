@@ -0,0 +1,68 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrIdentityNotFound = errors.New("user identity not found")
+	// ErrIdentityConflict 外部身份已关联其他用户，或用户已关联该提供方的其他身份，或自动创建的用户名、邮箱已被占用
+	ErrIdentityConflict       = errors.New("user identity conflict")
+	ErrFederatedStateNotFound = errors.New("federated login state not found")
+)
+
+// UserIdentity 外部身份关联的本地用户
+type UserIdentity struct {
+	TenantID int64
+	UserID   int64
+	Username string
+	Provider string
+	Subject  string
+	Email    string
+	Disabled bool
+}
+
+// FederatedUser 首次登录时自动创建的用户及其外部身份
+type FederatedUser struct {
+	Username    string
+	Email       string
+	DisplayName string
+	Provider    string
+	Subject     string
+}
+
+// FederatedLoginState 跳转 IdP 前保存的授权请求，以 state 的哈希为键，只能使用一次
+type FederatedLoginState struct {
+	StateHash    string
+	TenantID     int64
+	Provider     string
+	Nonce        string
+	CodeVerifier string
+	BindingHash  string
+	LinkUserID   int64 // 不为0时回调后把外部身份关联到该用户，而不是登录
+	ExpiresAt    time.Time
+}
+
+// FederatedLoginTicket 返回给浏览器的授权地址和绑定凭据
+type FederatedLoginTicket struct {
+	AuthorizationURL string
+	Binding          string
+	ExpiresAt        time.Time
+}
+
+// IdentityProvider 可用于登录的外部提供方
+type IdentityProvider struct {
+	ID          string
+	DisplayName string
+}
+
+// FederationUseCase 外部 OIDC 登录用例接口
+type FederationUseCase interface {
+	ListIdentityProviders(ctx context.Context) []*IdentityProvider
+	// BeginFederatedLogin link 不为空时回调后关联到该用户
+	BeginFederatedLogin(ctx context.Context, provider string, link *Principal) (*FederatedLoginTicket, error)
+	// CompleteFederatedLogin 兑换 IdP 回调中的 code，关联身份时同样返回该用户的新令牌
+	CompleteFederatedLogin(ctx context.Context, state, code, binding string) (*AuthResult, error)
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrInviteUnavailable 邀请码不存在、已过期或次数已用完
+var ErrInviteUnavailable = errors.New("invite is unavailable")
+
+// RoleAdmin 管理员角色，可以调用 AdminService
+const RoleAdmin = "admin"
+
+// Invite 邀请码记录
+type Invite struct {
+	ID         int64
+	TenantID   int64
+	InviterID  int64
+	MaxUses    int32
+	UsedCount  int32
+	InviteeIDs []int64
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+}
+
+// InviteTicket 创建邀请码的结果，Code 只在创建时返回
+type InviteTicket struct {
+	Code   string
+	Invite *Invite
+}
+
+// AdminUseCase 管理用例接口，调用方必须具有 admin 角色
+type AdminUseCase interface {
+	CreateInvite(ctx context.Context, admin *Principal, maxUses int32, ttl time.Duration) (*InviteTicket, error)
+	ListInvites(ctx context.Context, admin *Principal) ([]*Invite, error)
+	// CreateWebhookSubscription secret 为空时生成随机密钥，返回的订阅带有密钥
+	CreateWebhookSubscription(ctx context.Context, admin *Principal, url string, events []string, secret string) (*WebhookSubscription, error)
+	ListWebhookSubscriptions(ctx context.Context, admin *Principal) ([]*WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, admin *Principal, id int64) error
+	// ListWebhookDeliveries 返回订阅最近的投递记录，最新的在前
+	ListWebhookDeliveries(ctx context.Context, admin *Principal, subscriptionID int64, limit int32) ([]*WebhookDelivery, error)
+	// RedeliverWebhook 把投递重置为待投递，重新开始计算重试次数
+	RedeliverWebhook(ctx context.Context, admin *Principal, deliveryID int64) (*WebhookDelivery, error)
+	// SetUserCredentialBackend 指定用户的凭证校验后端，backend 为空时使用租户默认后端
+	SetUserCredentialBackend(ctx context.Context, admin *Principal, username, backend string) error
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrStepUpNotFound = errors.New("step-up verification not found")
+
+// 登录风险原因
+const (
+	LoginRiskNewDevice   = "new_device"
+	LoginRiskNewNetwork  = "new_network"
+	LoginRiskUnusualHour = "unusual_hour"
+	// LoginRiskUnavailable 查询设备失败，无法评估
+	LoginRiskUnavailable = "risk_unavailable"
+	// LoginRiskStepUpSkipped 需要二次验证但无法发送验证码，按配置跳过
+	LoginRiskStepUpSkipped = "step_up_skipped"
+)
+
+// Device 用户登录过的设备
+type Device struct {
+	ID           int64
+	UserID       int64
+	Fingerprint  string
+	DeviceID     string
+	UserAgent    string
+	IPPrefix     string
+	LocationHint string
+	FirstSeenAt  time.Time
+	LastSeenAt   time.Time
+}
+
+// LoginEvent 一次成功登录
+type LoginEvent struct {
+	UserID      int64
+	Fingerprint string
+	IPPrefix    string
+	RiskScore   int32
+	Reasons     []string
+	StepUp      bool
+	CreatedAt   time.Time
+}
+
+// StepUp 等待二次验证的登录，只保存验证码的哈希
+type StepUp struct {
+	ID            string
+	TenantID      int64
+	UserID        int64
+	Username      string
+	Email         string
+	CodeHash      string
+	AuthRequestID string
+	Device        Device
+	RiskScore     int32
+	Reasons       []string
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+}
+
+// LoginRiskUseCase 登录风险用例接口
+type LoginRiskUseCase interface {
+	VerifyStepUp(ctx context.Context, id, code string) (*AuthResult, error)
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrMagicLinkNotFound = errors.New("magic link not found")
+
+// MagicLink 已发送的一次性登录链接，只保存浏览器 nonce 的哈希
+type MagicLink struct {
+	ID        string
+	TenantID  int64
+	UserID    int64
+	Username  string
+	Email     string
+	NonceHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// MagicLinkTicket 返回给请求方浏览器的绑定凭据
+type MagicLinkTicket struct {
+	Nonce     string
+	ExpiresAt time.Time
+}
+
+// MagicLinkUseCase 邮件免密登录用例接口
+type MagicLinkUseCase interface {
+	// RequestMagicLink 邮箱未注册时同样返回成功，避免暴露用户是否存在
+	RequestMagicLink(ctx context.Context, email string) (*MagicLinkTicket, error)
+	ExchangeMagicLink(ctx context.Context, token, nonce string) (*AuthResult, error)
+	// RequestRegistrationEmail 限定邮箱域名注册时向邮箱发送注册票据，返回票据的过期时间
+	RequestRegistrationEmail(ctx context.Context, email string) (time.Time, error)
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// 需要工作量证明的操作
+const (
+	PowActionRegister      = "register"
+	PowActionAuthChallenge = "auth_challenge"
+)
+
+// 用于调整难度的事件
+const (
+	PowEventRegistration = "registration"
+	PowEventFailedLogin  = "failed_login"
+)
+
+// PowChallenge 工作量证明题目，Challenge 由服务端签名，任意副本都可以校验
+type PowChallenge struct {
+	Required   bool
+	Challenge  string
+	Difficulty int32
+	ExpiresAt  time.Time
+}
+
+// PowSolution 客户端提交的解
+type PowSolution struct {
+	Challenge string
+	Nonce     string
+}
+
+// ProofOfWorkUseCase 工作量证明用例接口
+type ProofOfWorkUseCase interface {
+	GetPowChallenge(ctx context.Context, action string) (*PowChallenge, error)
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// Principal 通过令牌认证的调用方
+type Principal struct {
+	TenantID  int64
+	UserID    int64
+	Username  string
+	TokenID   string   // jti
+	SessionID string   // sid，旧令牌没有会话ID，无法单独注销
+	Scopes    []string // 为空表示不限制
+	Audience  string   // 令牌交换签发的令牌只能用于该服务，不能访问本服务
+	Actor     *Actor   // 代表用户调用的服务
+	ProofKey  string   // cnf.jkt，不为空时令牌只能配合该密钥的 DPoP 证明使用
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// Actor 委托链中的调用方，Actor 为更早的一级
+type Actor struct {
+	Subject string
+	Actor   *Actor
+}
+
+// TokenVerifier 校验访问令牌并解析出调用方
+type TokenVerifier interface {
+	VerifyToken(ctx context.Context, token string) (*Principal, error)
+}
+
+type principalKey struct{}
+
+// NewPrincipalContext 把调用方写入 ctx
+func NewPrincipalContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext 从 ctx 中取出调用方，未认证时返回 false
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// ClientInfo 发起请求的客户端信息
+type ClientInfo struct {
+	IP           string
+	UserAgent    string
+	LocationHint string
+}
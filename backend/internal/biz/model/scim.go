@@ -0,0 +1,127 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrScimResourceNotFound = errors.New("resource not found")
+	ErrScimConflict         = errors.New("resource already exists")
+)
+
+// SCIM 错误类型（RFC 7644 3.12），客户端据此判断如何处理失败的请求
+const (
+	ScimErrInvalidFilter = "invalidFilter"
+	ScimErrInvalidPath   = "invalidPath"
+	ScimErrInvalidValue  = "invalidValue"
+	ScimErrInvalidSyntax = "invalidSyntax"
+	ScimErrNoTarget      = "noTarget"
+	ScimErrUniqueness    = "uniqueness"
+	ScimErrMutability    = "mutability"
+)
+
+// ScimError 带 scimType 的请求错误
+type ScimError struct {
+	Type   string
+	Detail string
+}
+
+func (e *ScimError) Error() string {
+	return e.Detail
+}
+
+// ScimUser SCIM 客户端管理的用户属性，只保存一个邮箱
+type ScimUser struct {
+	ID          int64
+	ExternalID  string
+	UserName    string
+	DisplayName string
+	GivenName   string
+	FamilyName  string
+	Email       string
+	Active      bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// ScimGroup 用户组
+type ScimGroup struct {
+	ID          int64
+	ExternalID  string
+	DisplayName string
+	Members     []*ScimMember
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// ScimMember 组成员
+type ScimMember struct {
+	UserID  int64
+	Display string // 成员的用户名
+}
+
+// ScimCondition 列表过滤条件，如 userName eq "alice"
+type ScimCondition struct {
+	Attribute string
+	Operator  string // eq、co、sw、ew、pr
+	Value     string
+}
+
+// ScimQuery 列表查询参数
+type ScimQuery struct {
+	Filter     string
+	StartIndex int32 // 从1开始
+	Count      int32
+}
+
+// ScimPage 解析后的分页和过滤条件
+type ScimPage struct {
+	Conditions []*ScimCondition // 同时满足
+	Offset     int32
+	Limit      int32
+}
+
+// ScimUserList 用户列表
+type ScimUserList struct {
+	TotalResults int64
+	StartIndex   int32
+	Users        []*ScimUser
+}
+
+// ScimGroupList 组列表
+type ScimGroupList struct {
+	TotalResults int64
+	StartIndex   int32
+	Groups       []*ScimGroup
+}
+
+// ScimPatchOperation PATCH 请求中的一个操作，Value 为 JSON 解码后的值
+type ScimPatchOperation struct {
+	Op    string
+	Path  string
+	Value any
+}
+
+// ScimUseCase SCIM 下发用例接口，除 Authenticate 外都限定在 ctx 中的租户内
+type ScimUseCase interface {
+	// Authenticate 校验下发客户端的 Bearer 令牌，返回其所属租户
+	Authenticate(ctx context.Context, token string) (*Tenant, error)
+	// MaxResults 每页最多返回的资源数
+	MaxResults() int32
+
+	CreateUser(ctx context.Context, user *ScimUser) (*ScimUser, error)
+	GetUser(ctx context.Context, id int64) (*ScimUser, error)
+	ReplaceUser(ctx context.Context, user *ScimUser) (*ScimUser, error)
+	PatchUser(ctx context.Context, id int64, ops []*ScimPatchOperation) (*ScimUser, error)
+	DeleteUser(ctx context.Context, id int64) error
+	ListUsers(ctx context.Context, query *ScimQuery) (*ScimUserList, error)
+
+	CreateGroup(ctx context.Context, group *ScimGroup) (*ScimGroup, error)
+	GetGroup(ctx context.Context, id int64) (*ScimGroup, error)
+	ReplaceGroup(ctx context.Context, group *ScimGroup) (*ScimGroup, error)
+	PatchGroup(ctx context.Context, id int64, ops []*ScimPatchOperation) (*ScimGroup, error)
+	DeleteGroup(ctx context.Context, id int64) error
+	ListGroups(ctx context.Context, query *ScimQuery) (*ScimGroupList, error)
+}
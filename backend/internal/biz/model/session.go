@@ -0,0 +1,64 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// TokenTypeAccessToken RFC 8693 中访问令牌的类型标识
+const TokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+
+var (
+	ErrInvalidTarget = errors.New("audience not allowed")
+	ErrInvalidScope  = errors.New("scope not allowed")
+	ErrInvalidGrant  = errors.New("subject token is invalid")
+)
+
+// ClientCredentials 资源服务的客户端凭据
+type ClientCredentials struct {
+	ID     string
+	Secret string
+}
+
+// Introspection 令牌内省结果，令牌无效时 Active 为 false 且 Principal 为空
+type Introspection struct {
+	Active    bool
+	Issuer    string
+	Principal *Principal
+}
+
+// TokenExchangeRequest 令牌交换请求（RFC 8693）
+type TokenExchangeRequest struct {
+	SubjectToken       string
+	SubjectTokenType   string
+	Audience           string
+	Scopes             []string
+	RequestedTokenType string
+}
+
+// TokenExchangeResult 令牌交换结果
+type TokenExchangeResult struct {
+	AccessToken string
+	Scopes      []string
+	ExpiresAt   time.Time
+}
+
+// IssuedToken 刷新得到的访问令牌
+type IssuedToken struct {
+	AccessToken string
+	TokenType   string
+	ExpiresAt   time.Time
+}
+
+// SessionUseCase 登录会话用例接口
+type SessionUseCase interface {
+	// Introspect 供资源服务查询令牌状态，client 必须是已配置的客户端
+	Introspect(ctx context.Context, client ClientCredentials, token string) (*Introspection, error)
+	// ExchangeToken 把用户令牌换成只能访问指定服务的短期令牌，client 成为新令牌的 act
+	ExchangeToken(ctx context.Context, client ClientCredentials, req *TokenExchangeRequest) (*TokenExchangeResult, error)
+	// RefreshToken 在调用方所在的会话中签发新令牌，请求携带 DPoP 证明时绑定到该密钥
+	RefreshToken(ctx context.Context, principal *Principal) (*IssuedToken, error)
+	// RevokeSession 注销调用方所在的会话
+	RevokeSession(ctx context.Context, principal *Principal) error
+}
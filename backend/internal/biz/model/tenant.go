@@ -0,0 +1,53 @@
+package model
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrTenantNotFound = errors.New("tenant not found")
+	ErrTenantRequired = errors.New("tenant is required")
+)
+
+// Tenant 租户，用户数据按租户隔离
+type Tenant struct {
+	ID   int64
+	Slug string
+	Name string
+}
+
+// TenantHint 请求中用于识别租户的信息
+type TenantHint struct {
+	Slug     string // 请求头中的租户标识
+	ClientID string
+	Host     string
+}
+
+// TenantUseCase 租户用例接口
+type TenantUseCase interface {
+	// ResolveTenant 按请求头、客户端ID、子域名的顺序识别租户，都没有时使用默认租户，未配置默认租户时返回 nil
+	ResolveTenant(ctx context.Context, hint TenantHint) (*Tenant, error)
+}
+
+type tenantKey struct{}
+
+// NewTenantContext 把当前请求的租户写入 ctx
+func NewTenantContext(ctx context.Context, t *Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, t)
+}
+
+// TenantFromContext 从 ctx 中取出租户
+func TenantFromContext(ctx context.Context) (*Tenant, bool) {
+	t, ok := ctx.Value(tenantKey{}).(*Tenant)
+	return t, ok && t != nil
+}
+
+// TenantIDFromContext 返回 ctx 中的租户ID，缺少租户时返回 ErrTenantRequired
+func TenantIDFromContext(ctx context.Context) (int64, error) {
+	t, ok := TenantFromContext(ctx)
+	if !ok {
+		return 0, ErrTenantRequired
+	}
+	return t.ID, nil
+}
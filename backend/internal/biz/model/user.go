@@ -3,25 +3,98 @@ package model
 import (
 	"context"
 	"errors"
+	"time"
 )
 
-var ErrUserAlreadyExists = errors.New("user Already Exists")
+var (
+	ErrUserAlreadyExists = errors.New("user Already Exists")
+	ErrUserNotFound      = errors.New("user not found")
+	// ErrUserConflict 用户名或邮箱已被其他用户占用
+	ErrUserConflict = errors.New("user conflict")
+)
+
+// 登录时客户端提交的凭证
+const (
+	CredentialTypeDerived  = "derived"  // 按 Kdf 参数派生的凭证
+	CredentialTypePassword = "password" // 密码原文，由目录服务校验
+)
 
 // User 业务层用户模型
 type User struct {
-	ID           int64
+	ID                int64
+	TenantID          int64
+	Username          string
+	PasswordHash      string
+	Salt              string
+	KdfVersion        int32 // 客户端 KDF 参数版本，0 表示旧凭证
+	Email             string
+	EmailVerified     bool   // 邮箱已证明归用户所有，才能用于按邮箱关联外部身份
+	Disabled          bool   // 已停用，不能登录
+	CredentialBackend string // 凭证校验后端，为空时使用租户默认后端
+	CreatedAt         string
+}
+
+// DirectoryUser 目录服务中的用户属性，登录时同步到本地
+type DirectoryUser struct {
+	Username    string
+	Email       string
+	DisplayName string
+	GivenName   string
+	FamilyName  string
+	Backend     string // 首次登录创建用户时记录的后端
+}
+
+// KdfParams 客户端派生凭证使用的参数
+type KdfParams struct {
+	Algorithm   string
+	Version     int32
+	MemoryKiB   uint32
+	Iterations  uint32
+	Parallelism uint32
+	Salt        string
+}
+
+// KdfTicket 服务端签发的 KDF 参数票据
+type KdfTicket struct {
+	Params    KdfParams
+	Ticket    string
+	ExpiresAt time.Time
+}
+
+// RegisterRequest 注册请求
+type RegisterRequest struct {
 	Username     string
 	PasswordHash string
-	Salt         string
 	Email        string
-	CreatedAt    string
+	Salt         string
+	InviteCode   string
+	KdfTicket    string
+	EmailTicket  string
+	Pow          *PowSolution
+}
+
+// SubmitAuthRequest 登录请求
+type SubmitAuthRequest struct {
+	Username          string
+	HashedCredential  string
+	AuthRequestID     string
+	ChallengeResponse string
+	// 使用 AuthChallenge.Upgrade 参数重新派生的凭证，可选
+	UpgradeCredential string
+	UpgradeTicket     string
+	DeviceID          string
+	Password          string // 目录账号提交的密码原文，与 HashedCredential 二选一
+	Client            ClientInfo
 }
 
 // AuthChallenge 认证挑战
 type AuthChallenge struct {
-	Username  string
-	Challenge string
-	Salt      string
+	Username       string
+	Challenge      string
+	Salt           string
+	Kdf            KdfParams
+	Upgrade        *KdfTicket // 参数过时时返回
+	CredentialType string     // 客户端应提交的凭证，为 password 时不返回 Salt 和 Kdf
 }
 
 // AuthResult 认证结果
@@ -29,11 +102,14 @@ type AuthResult struct {
 	Code      string
 	State     string
 	AuthToken string
+	TokenType string // Bearer 或 DPoP
+	StepUpID  string // Code 为 step_up_required 时返回
 }
 
 // UserUseCase 用户用例接口
 type UserUseCase interface {
-	Register(ctx context.Context, username, passwordHash, email, salt string) (string, error)
-	GetAuthChallenge(ctx context.Context, username string) (*AuthChallenge, error)
-	SubmitAuth(ctx context.Context, username, hashedCredential, authRequestID, challengeResponse string) (*AuthResult, error)
+	GetRegistrationParams(ctx context.Context) (*KdfTicket, error)
+	Register(ctx context.Context, req *RegisterRequest) (string, error)
+	GetAuthChallenge(ctx context.Context, username string, pow *PowSolution) (*AuthChallenge, error)
+	SubmitAuth(ctx context.Context, req *SubmitAuthRequest) (*AuthResult, error)
 }
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrWebhookSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrWebhookDeliveryNotFound     = errors.New("webhook delivery not found")
+)
+
+// Webhook 投递状态
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	// WebhookDeliveryDead 重试次数用完，只能手动重新投递
+	WebhookDeliveryDead = "dead"
+)
+
+// WebhookSubscription 租户的 Webhook 订阅，Secret 只在创建时返回
+type WebhookSubscription struct {
+	ID        int64
+	TenantID  int64
+	URL       string
+	Events    []string
+	Secret    string
+	CreatedAt time.Time
+}
+
+// WebhookDelivery 一个事件到一个订阅的投递记录
+type WebhookDelivery struct {
+	ID             int64
+	SubscriptionID int64
+	EventID        int64
+	EventType      string
+	Status         string
+	Attempts       int32
+	NextAttemptAt  time.Time
+	LastAttemptAt  time.Time // 未尝试时为零值
+	ResponseStatus int32     // 最近一次尝试的 HTTP 状态码，没有响应时为 0
+	LastError      string
+	DeliveredAt    time.Time
+	CreatedAt      time.Time
+}
@@ -0,0 +1,207 @@
+package biz
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data"
+
+	"connectrpc.com/connect"
+	"go.uber.org/zap"
+)
+
+const powPurpose = "pow"
+
+var (
+	errPowRequired = errors.New("proof of work required")
+	errInvalidPow  = errors.New("invalid or expired proof of work")
+)
+
+// ProofOfWork 签发和校验 hashcash 风格的题目，难度随注册和登录失败的频率升高
+type ProofOfWork struct {
+	repo           data.PowRepo
+	tokens         *TokenManager
+	enabled        bool
+	minDifficulty  int32
+	maxDifficulty  int32
+	ttl            time.Duration
+	window         time.Duration
+	regThreshold   int64
+	loginThreshold int64
+	l              *zap.Logger
+}
+
+var _ model.ProofOfWorkUseCase = (*ProofOfWork)(nil)
+
+func NewProofOfWork(repo data.PowRepo, tokens *TokenManager, cfg *conf.Bootstrap, logger *zap.Logger) (*ProofOfWork, error) {
+	p := &ProofOfWork{
+		repo:           repo,
+		tokens:         tokens,
+		minDifficulty:  16,               // 默认16比特，浏览器中约几十毫秒
+		maxDifficulty:  24,               // 默认24比特
+		ttl:            5 * time.Minute,  // 默认5分钟
+		window:         10 * time.Minute, // 默认10分钟
+		regThreshold:   20,
+		loginThreshold: 50,
+		l:              logger,
+	}
+	c := cfg.ProofOfWork
+	if c == nil {
+		return p, nil
+	}
+
+	p.enabled = c.Enabled
+	if c.MinDifficulty > 0 {
+		p.minDifficulty = c.MinDifficulty
+	}
+	if c.MaxDifficulty > 0 {
+		p.maxDifficulty = c.MaxDifficulty
+	}
+	if p.minDifficulty > p.maxDifficulty || p.maxDifficulty > 32 {
+		return nil, fmt.Errorf("invalid proof_of_work difficulty range: %d-%d", p.minDifficulty, p.maxDifficulty)
+	}
+	if c.TtlSeconds > 0 {
+		p.ttl = time.Duration(c.TtlSeconds) * time.Second
+	}
+	if c.RateWindowSeconds > 0 {
+		p.window = time.Duration(c.RateWindowSeconds) * time.Second
+	}
+	if c.RegistrationThreshold > 0 {
+		p.regThreshold = c.RegistrationThreshold
+	}
+	if c.FailedLoginThreshold > 0 {
+		p.loginThreshold = c.FailedLoginThreshold
+	}
+	return p, nil
+}
+
+func (p *ProofOfWork) GetPowChallenge(ctx context.Context, action string) (*model.PowChallenge, error) {
+	if action != model.PowActionRegister && action != model.PowActionAuthChallenge {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("unknown proof of work action"))
+	}
+	if !p.enabled {
+		return &model.PowChallenge{Required: false}, nil
+	}
+
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	raw := make([]byte, 12)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("generate proof of work id failed: %v", err)
+	}
+
+	difficulty := p.difficulty(ctx)
+	expiresAt := time.Now().Add(p.ttl)
+	// 题目格式：<操作>.<租户>.<难度>.<过期时间>.<随机ID>.<签名>
+	payload := strings.Join([]string{
+		action,
+		strconv.FormatInt(tenantID, 10),
+		strconv.FormatInt(int64(difficulty), 10),
+		strconv.FormatInt(expiresAt.Unix(), 10),
+		base64.RawURLEncoding.EncodeToString(raw),
+	}, ".")
+
+	return &model.PowChallenge{
+		Required:   true,
+		Challenge:  payload + "." + p.tokens.sign(powPurpose, payload),
+		Difficulty: difficulty,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// verify 校验解并标记题目已使用，未开启时直接通过
+func (p *ProofOfWork) verify(ctx context.Context, action string, solution *model.PowSolution) error {
+	if !p.enabled {
+		return nil
+	}
+	if solution == nil || solution.Challenge == "" {
+		return connect.NewError(connect.CodeFailedPrecondition, errPowRequired)
+	}
+
+	parts := strings.Split(solution.Challenge, ".")
+	if len(parts) != 6 || len(solution.Nonce) > 64 {
+		return connect.NewError(connect.CodePermissionDenied, errInvalidPow)
+	}
+	payload := strings.Join(parts[:5], ".")
+	if !p.tokens.verifySignature(powPurpose, payload, parts[5]) || parts[0] != action {
+		return connect.NewError(connect.CodePermissionDenied, errInvalidPow)
+	}
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil || parts[1] != strconv.FormatInt(tenantID, 10) {
+		return connect.NewError(connect.CodePermissionDenied, errInvalidPow)
+	}
+	difficulty, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return connect.NewError(connect.CodePermissionDenied, errInvalidPow)
+	}
+	exp, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return connect.NewError(connect.CodePermissionDenied, errInvalidPow)
+	}
+	if powLeadingZeros(solution.Challenge, solution.Nonce) < difficulty {
+		return connect.NewError(connect.CodePermissionDenied, errInvalidPow)
+	}
+
+	// 题目无状态签发，只在使用时记录一次，防止同一个解被重复提交
+	first, err := p.repo.UsePowChallenge(ctx, parts[4], time.Until(time.Unix(exp, 0))+time.Second)
+	if err != nil {
+		return connect.NewError(connect.CodeInternal, err)
+	}
+	if !first {
+		return connect.NewError(connect.CodePermissionDenied, errInvalidPow)
+	}
+	return nil
+}
+
+// record 记录用于调整难度的事件，失败只记录日志
+func (p *ProofOfWork) record(ctx context.Context, event string) {
+	if !p.enabled {
+		return
+	}
+	if err := p.repo.RecordPowEvent(ctx, event, p.window); err != nil {
+		p.l.Warn("record proof of work event failed", zap.String("event", event), zap.Error(err))
+	}
+}
+
+// difficulty 窗口内注册或登录失败次数每达到阈值的两倍，难度加1比特，计算量随之翻倍
+func (p *ProofOfWork) difficulty(ctx context.Context) int32 {
+	registrations, err := p.repo.CountPowEvent(ctx, model.PowEventRegistration)
+	if err != nil {
+		p.l.Warn("count registrations failed", zap.Error(err))
+	}
+	failedLogins, err := p.repo.CountPowEvent(ctx, model.PowEventFailedLogin)
+	if err != nil {
+		p.l.Warn("count failed logins failed", zap.Error(err))
+	}
+
+	extra := max(
+		bits.Len64(uint64(registrations/p.regThreshold)),
+		bits.Len64(uint64(failedLogins/p.loginThreshold)),
+	)
+	return min(p.minDifficulty+int32(extra), p.maxDifficulty)
+}
+
+// powLeadingZeros 计算 sha256(challenge + ":" + nonce) 的前导零比特数
+func powLeadingZeros(challenge, nonce string) int {
+	sum := sha256.Sum256([]byte(challenge + ":" + nonce))
+	zeros := 0
+	for _, b := range sum {
+		if b != 0 {
+			return zeros + bits.LeadingZeros8(b)
+		}
+		zeros += 8
+	}
+	return zeros
+}
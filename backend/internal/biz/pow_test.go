@@ -0,0 +1,196 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+// MockPowRepo 是 PowRepo 的模拟实现
+type MockPowRepo struct {
+	mock.Mock
+}
+
+func (m *MockPowRepo) RecordPowEvent(ctx context.Context, event string, window time.Duration) error {
+	args := m.Called(ctx, event, window)
+	return args.Error(0)
+}
+
+func (m *MockPowRepo) CountPowEvent(ctx context.Context, event string) (int64, error) {
+	args := m.Called(ctx, event)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPowRepo) UsePowChallenge(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	args := m.Called(ctx, id, ttl)
+	return args.Bool(0), args.Error(1)
+}
+
+// ProofOfWorkTestSuite 是 ProofOfWork 的测试套件
+type ProofOfWorkTestSuite struct {
+	suite.Suite
+	repo *MockPowRepo
+	pow  *ProofOfWork
+	ctx  context.Context
+}
+
+func (suite *ProofOfWorkTestSuite) SetupTest() {
+	suite.repo = new(MockPowRepo)
+	suite.ctx = model.NewTenantContext(context.Background(), &model.Tenant{ID: 2})
+	logger, _ := zap.NewDevelopment()
+
+	cfg := &conf.Bootstrap{
+		Auth: &conf.Auth{JwtSecret: "test-secret"},
+		ProofOfWork: &conf.ProofOfWork{
+			Enabled:               true,
+			MinDifficulty:         4,
+			MaxDifficulty:         8,
+			RegistrationThreshold: 10,
+			FailedLoginThreshold:  10,
+		},
+	}
+	tokens, err := NewTokenManager(cfg, logger)
+	assert.NoError(suite.T(), err)
+	pow, err := NewProofOfWork(suite.repo, tokens, cfg, logger)
+	assert.NoError(suite.T(), err)
+	suite.pow = pow
+}
+
+// solve 暴力求解，测试中难度很低
+func solve(challenge string, difficulty int32) string {
+	for i := 0; ; i++ {
+		nonce := strconv.Itoa(i)
+		if powLeadingZeros(challenge, nonce) >= int(difficulty) {
+			return nonce
+		}
+	}
+}
+
+func (suite *ProofOfWorkTestSuite) issue(action string) *model.PowChallenge {
+	challenge, err := suite.pow.GetPowChallenge(suite.ctx, action)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), challenge.Required)
+	return challenge
+}
+
+func (suite *ProofOfWorkTestSuite) TestVerify() {
+	suite.repo.On("CountPowEvent", suite.ctx, mock.Anything).Return(int64(0), nil)
+	suite.repo.On("UsePowChallenge", suite.ctx, mock.Anything, mock.Anything).Return(true, nil).Once()
+	suite.repo.On("UsePowChallenge", suite.ctx, mock.Anything, mock.Anything).Return(false, nil).Once()
+
+	challenge := suite.issue(model.PowActionRegister)
+	assert.Equal(suite.T(), int32(4), challenge.Difficulty)
+	solution := &model.PowSolution{Challenge: challenge.Challenge, Nonce: solve(challenge.Challenge, challenge.Difficulty)}
+
+	assert.NoError(suite.T(), suite.pow.verify(suite.ctx, model.PowActionRegister, solution))
+
+	// 同一个解只能使用一次
+	err := suite.pow.verify(suite.ctx, model.PowActionRegister, solution)
+	assert.Equal(suite.T(), connect.CodePermissionDenied, connect.CodeOf(err))
+}
+
+func (suite *ProofOfWorkTestSuite) TestVerify_Rejected() {
+	suite.repo.On("CountPowEvent", suite.ctx, mock.Anything).Return(int64(0), nil)
+	challenge := suite.issue(model.PowActionRegister)
+	nonce := solve(challenge.Challenge, challenge.Difficulty)
+
+	// 未提交
+	err := suite.pow.verify(suite.ctx, model.PowActionRegister, nil)
+	assert.Equal(suite.T(), connect.CodeFailedPrecondition, connect.CodeOf(err))
+
+	for name, tc := range map[string]struct {
+		ctx      context.Context
+		action   string
+		solution *model.PowSolution
+	}{
+		"other action": {suite.ctx, model.PowActionAuthChallenge, &model.PowSolution{Challenge: challenge.Challenge, Nonce: nonce}},
+		"other tenant": {model.NewTenantContext(context.Background(), &model.Tenant{ID: 3}), model.PowActionRegister, &model.PowSolution{Challenge: challenge.Challenge, Nonce: nonce}},
+		"tampered":     {suite.ctx, model.PowActionRegister, &model.PowSolution{Challenge: "register.2.0.9999999999.id.sig", Nonce: nonce}},
+	} {
+		err := suite.pow.verify(tc.ctx, tc.action, tc.solution)
+		assert.Equal(suite.T(), connect.CodePermissionDenied, connect.CodeOf(err), name)
+	}
+
+	// 错误的解
+	for i := 0; ; i++ {
+		if powLeadingZeros(challenge.Challenge, strconv.Itoa(i)) < int(challenge.Difficulty) {
+			err := suite.pow.verify(suite.ctx, model.PowActionRegister, &model.PowSolution{Challenge: challenge.Challenge, Nonce: strconv.Itoa(i)})
+			assert.Equal(suite.T(), connect.CodePermissionDenied, connect.CodeOf(err))
+			break
+		}
+	}
+	suite.repo.AssertNotCalled(suite.T(), "UsePowChallenge", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ProofOfWorkTestSuite) TestDifficulty_Adapts() {
+	suite.repo.On("CountPowEvent", suite.ctx, model.PowEventRegistration).Return(int64(25), nil)
+	suite.repo.On("CountPowEvent", suite.ctx, model.PowEventFailedLogin).Return(int64(5), nil)
+
+	// 注册次数为阈值的2倍以上，难度加2
+	assert.Equal(suite.T(), int32(6), suite.issue(model.PowActionAuthChallenge).Difficulty)
+}
+
+func (suite *ProofOfWorkTestSuite) TestDifficulty_Capped() {
+	suite.repo.On("CountPowEvent", suite.ctx, model.PowEventRegistration).Return(int64(0), errors.New("redis down"))
+	suite.repo.On("CountPowEvent", suite.ctx, model.PowEventFailedLogin).Return(int64(100000), nil)
+
+	assert.Equal(suite.T(), int32(8), suite.issue(model.PowActionAuthChallenge).Difficulty)
+}
+
+func (suite *ProofOfWorkTestSuite) TestRegister_RequiresPow() {
+	logger, _ := zap.NewDevelopment()
+	userRepo := new(MockUserRepo)
+	useCase, err := NewUserUseCase(userRepo, new(MockAuthRequestRepo), new(MockInviteRepo), new(MockTransactor), suite.pow.tokens, newTestHasher(suite.T(), "pepper"), new(CredentialBackends), suite.pow, newDisabledLoginRisk(), NewDomainEvents(new(MockOutboxRepo), zap.NewNop()), &conf.Bootstrap{Auth: &conf.Auth{}}, logger)
+	assert.NoError(suite.T(), err)
+
+	_, err = useCase.Register(suite.ctx, &model.RegisterRequest{Username: "newuser", PasswordHash: "hash", Salt: "salt"})
+	assert.Equal(suite.T(), connect.CodeFailedPrecondition, connect.CodeOf(err))
+
+	suite.repo.On("CountPowEvent", suite.ctx, mock.Anything).Return(int64(0), nil)
+	suite.repo.On("UsePowChallenge", suite.ctx, mock.Anything, mock.Anything).Return(true, nil)
+	suite.repo.On("RecordPowEvent", suite.ctx, model.PowEventRegistration, 10*time.Minute).Return(nil)
+	userRepo.On("GetUserByName", suite.ctx, "newuser").Return(nil, model.ErrUserNotFound)
+	userRepo.On("CreateUser", suite.ctx, mock.AnythingOfType("*model.User")).Return(int64(12), nil)
+
+	challenge := suite.issue(model.PowActionRegister)
+	_, err = useCase.Register(suite.ctx, &model.RegisterRequest{
+		Username:     "newuser",
+		PasswordHash: "hash",
+		Salt:         "salt",
+		Pow: &model.PowSolution{
+			Challenge: challenge.Challenge,
+			Nonce:     solve(challenge.Challenge, challenge.Difficulty),
+		},
+	})
+	assert.NoError(suite.T(), err)
+	suite.repo.AssertCalled(suite.T(), "RecordPowEvent", suite.ctx, model.PowEventRegistration, 10*time.Minute)
+}
+
+func (suite *ProofOfWorkTestSuite) TestDisabled() {
+	logger, _ := zap.NewDevelopment()
+	pow, err := NewProofOfWork(suite.repo, suite.pow.tokens, &conf.Bootstrap{}, logger)
+	assert.NoError(suite.T(), err)
+
+	challenge, err := pow.GetPowChallenge(suite.ctx, model.PowActionRegister)
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), challenge.Required)
+	assert.NoError(suite.T(), pow.verify(suite.ctx, model.PowActionRegister, nil))
+
+	_, err = pow.GetPowChallenge(suite.ctx, "unknown")
+	assert.Equal(suite.T(), connect.CodeInvalidArgument, connect.CodeOf(err))
+}
+
+func TestProofOfWorkTestSuite(t *testing.T) {
+	suite.Run(t, new(ProofOfWorkTestSuite))
+}
@@ -0,0 +1,158 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	netmail "net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+
+	"connectrpc.com/connect"
+)
+
+// 注册模式
+const (
+	RegistrationModeOpen   = "open"
+	RegistrationModeInvite = "invite"
+	RegistrationModeDomain = "domain"
+	RegistrationModeClosed = "closed"
+)
+
+const (
+	invitePurpose      = "invite"
+	emailTicketPurpose = "registration-email"
+)
+
+var (
+	errInvalidInvite    = errors.New("invalid or exhausted invite code")
+	errEmailNotVerified = errors.New("email verification required, call RequestRegistrationEmail first")
+)
+
+// registrationPolicy 注册策略，由 registration 配置解析而来
+type registrationPolicy struct {
+	mode          string
+	domains       map[string]struct{}
+	inviteMaxUses int32
+	inviteTTL     time.Duration
+	// emailTicketTTL domain 模式下邮箱票据的有效期
+	emailTicketTTL time.Duration
+}
+
+func newRegistrationPolicy(cfg *conf.Registration) (*registrationPolicy, error) {
+	p := &registrationPolicy{
+		mode:           RegistrationModeOpen,
+		domains:        make(map[string]struct{}),
+		inviteMaxUses:  1,                  // 默认只能使用1次
+		inviteTTL:      7 * 24 * time.Hour, // 默认7天
+		emailTicketTTL: time.Hour,          // 默认1小时
+	}
+	if cfg == nil {
+		return p, nil
+	}
+
+	switch cfg.Mode {
+	case "":
+	case RegistrationModeOpen, RegistrationModeInvite, RegistrationModeDomain, RegistrationModeClosed:
+		p.mode = cfg.Mode
+	default:
+		return nil, fmt.Errorf("invalid registration.mode: %q", cfg.Mode)
+	}
+	for _, domain := range cfg.AllowedDomains {
+		p.domains[strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@"))] = struct{}{}
+	}
+	if p.mode == RegistrationModeDomain && len(p.domains) == 0 {
+		return nil, errors.New("registration.allowed_domains is required in domain mode")
+	}
+	if cfg.InviteMaxUses > 0 {
+		p.inviteMaxUses = cfg.InviteMaxUses
+	}
+	if cfg.InviteTtlSeconds > 0 {
+		p.inviteTTL = time.Duration(cfg.InviteTtlSeconds) * time.Second
+	}
+	if cfg.EmailTicketTtlSeconds > 0 {
+		p.emailTicketTTL = time.Duration(cfg.EmailTicketTtlSeconds) * time.Second
+	}
+	return p, nil
+}
+
+// check 校验本次注册是否被允许，邀请模式下返回邀请码对应的记录ID。
+// domain 模式下邮箱由注册者自行填写，必须携带发送到该邮箱的票据，证明邮箱属于注册者
+func (p *registrationPolicy) check(ctx context.Context, tokens *TokenManager, email, code, emailTicket string) (int64, error) {
+	switch p.mode {
+	case RegistrationModeClosed:
+		return 0, connect.NewError(connect.CodePermissionDenied, errors.New("registration is closed"))
+	case RegistrationModeInvite:
+		id, ok := parseInviteCode(ctx, tokens, code)
+		if !ok {
+			return 0, connect.NewError(connect.CodePermissionDenied, errInvalidInvite)
+		}
+		return id, nil
+	case RegistrationModeDomain:
+		if _, err := p.allowedEmail(email); err != nil {
+			return 0, err
+		}
+		if !verifyEmailTicket(ctx, tokens, email, emailTicket) {
+			return 0, connect.NewError(connect.CodePermissionDenied, errEmailNotVerified)
+		}
+	}
+	return 0, nil
+}
+
+// allowedEmail 校验邮箱属于允许注册的域名，返回小写的地址
+func (p *registrationPolicy) allowedEmail(email string) (string, error) {
+	addr, err := netmail.ParseAddress(email)
+	if err != nil {
+		return "", connect.NewError(connect.CodeInvalidArgument, errors.New("a valid email address is required"))
+	}
+	address := strings.ToLower(addr.Address)
+	if _, ok := p.domains[address[strings.LastIndex(address, "@")+1:]]; !ok {
+		return "", connect.NewError(connect.CodePermissionDenied, errors.New("email domain is not allowed to register"))
+	}
+	return address, nil
+}
+
+// emailTicket 生成邮箱票据：<过期时间>.<签名>，签名绑定租户和邮箱
+func emailTicket(tokens *TokenManager, tenantID int64, email string, expiresAt time.Time) string {
+	exp := strconv.FormatInt(expiresAt.Unix(), 10)
+	return exp + "." + tokens.sign(emailTicketPurpose, strconv.FormatInt(tenantID, 10)+"."+email+"."+exp)
+}
+
+func verifyEmailTicket(ctx context.Context, tokens *TokenManager, email, ticket string) bool {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return false
+	}
+	exp, signature, ok := strings.Cut(strings.TrimSpace(ticket), ".")
+	if !ok || !tokens.verifySignature(emailTicketPurpose, strconv.FormatInt(tenantID, 10)+"."+email+"."+exp, signature) {
+		return false
+	}
+	expUnix, err := strconv.ParseInt(exp, 10, 64)
+	return err == nil && time.Now().Before(time.Unix(expUnix, 0))
+}
+
+// inviteCode 生成邀请码：<id>.<签名>，签名绑定租户，其他租户无法使用
+func inviteCode(tokens *TokenManager, tenantID, id int64) string {
+	payload := strconv.FormatInt(id, 10)
+	return payload + "." + tokens.sign(invitePurpose, strconv.FormatInt(tenantID, 10)+"."+payload)
+}
+
+func parseInviteCode(ctx context.Context, tokens *TokenManager, code string) (int64, bool) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return 0, false
+	}
+	payload, signature, ok := strings.Cut(strings.TrimSpace(code), ".")
+	if !ok || !tokens.verifySignature(invitePurpose, strconv.FormatInt(tenantID, 10)+"."+payload, signature) {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,216 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+// MockInviteRepo 是 InviteRepo 的模拟实现
+type MockInviteRepo struct {
+	mock.Mock
+}
+
+func (m *MockInviteRepo) CreateInvite(ctx context.Context, invite *model.Invite) error {
+	args := m.Called(ctx, invite)
+	return args.Error(0)
+}
+
+func (m *MockInviteRepo) RedeemInvite(ctx context.Context, id int64) (*model.Invite, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Invite), args.Error(1)
+}
+
+func (m *MockInviteRepo) CreateInviteRedemption(ctx context.Context, inviteID, userID int64) error {
+	args := m.Called(ctx, inviteID, userID)
+	return args.Error(0)
+}
+
+func (m *MockInviteRepo) ListInvites(ctx context.Context) ([]*model.Invite, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Invite), args.Error(1)
+}
+
+// RegistrationTestSuite 测试不同注册模式下的 Register
+type RegistrationTestSuite struct {
+	suite.Suite
+	userRepo   *MockUserRepo
+	inviteRepo *MockInviteRepo
+	tokens     *TokenManager
+	pow        *ProofOfWork
+	ctx        context.Context
+}
+
+func (suite *RegistrationTestSuite) SetupTest() {
+	suite.userRepo = new(MockUserRepo)
+	suite.inviteRepo = new(MockInviteRepo)
+	suite.ctx = model.NewTenantContext(context.Background(), &model.Tenant{ID: 2})
+
+	logger, _ := zap.NewDevelopment()
+	tokens, err := NewTokenManager(&conf.Bootstrap{Auth: &conf.Auth{JwtSecret: "test-secret"}}, logger)
+	assert.NoError(suite.T(), err)
+	suite.tokens = tokens
+
+	pow, err := NewProofOfWork(new(MockPowRepo), tokens, &conf.Bootstrap{}, logger)
+	assert.NoError(suite.T(), err)
+	suite.pow = pow
+}
+
+func (suite *RegistrationTestSuite) newUseCase(registration *conf.Registration) *UserUseCase {
+	logger, _ := zap.NewDevelopment()
+	useCase, err := NewUserUseCase(suite.userRepo, new(MockAuthRequestRepo), suite.inviteRepo, new(MockTransactor), suite.tokens, newTestHasher(suite.T(), "pepper"), new(CredentialBackends), suite.pow, newDisabledLoginRisk(), NewDomainEvents(new(MockOutboxRepo), zap.NewNop()), &conf.Bootstrap{
+		Auth:         &conf.Auth{},
+		Registration: registration,
+	}, logger)
+	assert.NoError(suite.T(), err)
+	return useCase.(*UserUseCase)
+}
+
+func (suite *RegistrationTestSuite) TestNewUserUseCase_InvalidConfig() {
+	logger, _ := zap.NewDevelopment()
+
+	_, err := NewUserUseCase(suite.userRepo, new(MockAuthRequestRepo), suite.inviteRepo, new(MockTransactor), suite.tokens, newTestHasher(suite.T(), "pepper"), new(CredentialBackends), suite.pow, newDisabledLoginRisk(), NewDomainEvents(new(MockOutboxRepo), zap.NewNop()), &conf.Bootstrap{
+		Auth:         &conf.Auth{},
+		Registration: &conf.Registration{Mode: "invite-only"},
+	}, logger)
+	assert.Error(suite.T(), err)
+
+	_, err = NewUserUseCase(suite.userRepo, new(MockAuthRequestRepo), suite.inviteRepo, new(MockTransactor), suite.tokens, newTestHasher(suite.T(), "pepper"), new(CredentialBackends), suite.pow, newDisabledLoginRisk(), NewDomainEvents(new(MockOutboxRepo), zap.NewNop()), &conf.Bootstrap{
+		Auth:         &conf.Auth{},
+		Registration: &conf.Registration{Mode: RegistrationModeDomain},
+	}, logger)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *RegistrationTestSuite) TestRegister_Closed() {
+	useCase := suite.newUseCase(&conf.Registration{Mode: RegistrationModeClosed})
+
+	_, err := useCase.Register(suite.ctx, &model.RegisterRequest{Username: "newuser", PasswordHash: "hash", Salt: "salt"})
+
+	assert.Equal(suite.T(), connect.CodePermissionDenied, connect.CodeOf(err))
+	suite.userRepo.AssertNotCalled(suite.T(), "CreateUser", mock.Anything, mock.Anything)
+}
+
+func (suite *RegistrationTestSuite) TestRegister_Domain() {
+	useCase := suite.newUseCase(&conf.Registration{
+		Mode:           RegistrationModeDomain,
+		AllowedDomains: []string{"Example.com", "@corp.example.com"},
+	})
+	suite.userRepo.On("GetUserByName", suite.ctx, mock.Anything).Return(nil, model.ErrUserNotFound)
+	suite.userRepo.On("CreateUser", suite.ctx, mock.AnythingOfType("*model.User")).Return(int64(10), nil)
+
+	_, err := useCase.Register(suite.ctx, &model.RegisterRequest{Username: "alice", PasswordHash: "hash", Email: "alice@evil.com", Salt: "salt"})
+	assert.Equal(suite.T(), connect.CodePermissionDenied, connect.CodeOf(err))
+
+	_, err = useCase.Register(suite.ctx, &model.RegisterRequest{Username: "alice", PasswordHash: "hash", Salt: "salt"})
+	assert.Equal(suite.T(), connect.CodeInvalidArgument, connect.CodeOf(err))
+
+	// 域名符合但没有证明能收到该邮箱的邮件
+	_, err = useCase.Register(suite.ctx, &model.RegisterRequest{Username: "alice", PasswordHash: "hash", Email: "alice@example.com", Salt: "salt"})
+	assert.Equal(suite.T(), connect.CodePermissionDenied, connect.CodeOf(err))
+
+	expiresAt := time.Now().Add(time.Hour)
+	ticket := emailTicket(suite.tokens, 2, "alice@example.com", expiresAt)
+	for _, req := range []*model.RegisterRequest{
+		{Email: "mallory@example.com", EmailTicket: ticket},
+		{Email: "alice@example.com", EmailTicket: emailTicket(suite.tokens, 3, "alice@example.com", expiresAt)},
+		{Email: "alice@example.com", EmailTicket: emailTicket(suite.tokens, 2, "alice@example.com", time.Now().Add(-time.Minute))},
+		{Email: "alice@example.com", EmailTicket: ticket + "x"},
+	} {
+		req.Username, req.PasswordHash, req.Salt = "alice", "hash", "salt"
+		_, err = useCase.Register(suite.ctx, req)
+		assert.Equal(suite.T(), connect.CodePermissionDenied, connect.CodeOf(err), req.EmailTicket)
+	}
+
+	userID, err := useCase.Register(suite.ctx, &model.RegisterRequest{Username: "alice", PasswordHash: "hash", Email: "Alice@EXAMPLE.com", Salt: "salt", EmailTicket: ticket})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "10", userID)
+
+	_, err = useCase.Register(suite.ctx, &model.RegisterRequest{
+		Username: "bob", PasswordHash: "hash", Email: "bob@corp.example.com", Salt: "salt",
+		EmailTicket: emailTicket(suite.tokens, 2, "bob@corp.example.com", expiresAt),
+	})
+	assert.NoError(suite.T(), err)
+	suite.userRepo.AssertNumberOfCalls(suite.T(), "CreateUser", 2)
+	suite.userRepo.AssertCalled(suite.T(), "CreateUser", suite.ctx, mock.MatchedBy(func(user *model.User) bool {
+		return user.Username == "alice" && user.EmailVerified
+	}))
+}
+
+func (suite *RegistrationTestSuite) TestRegister_Invite() {
+	useCase := suite.newUseCase(&conf.Registration{Mode: RegistrationModeInvite})
+	code := inviteCode(suite.tokens, 2, 5)
+	suite.userRepo.On("GetUserByName", suite.ctx, "newuser").Return(nil, model.ErrUserNotFound)
+	suite.inviteRepo.On("RedeemInvite", suite.ctx, int64(5)).Return(&model.Invite{ID: 5, InviterID: 1}, nil)
+	suite.userRepo.On("CreateUser", suite.ctx, mock.AnythingOfType("*model.User")).Return(int64(11), nil)
+	suite.inviteRepo.On("CreateInviteRedemption", suite.ctx, int64(5), int64(11)).Return(nil)
+
+	userID, err := useCase.Register(suite.ctx, &model.RegisterRequest{Username: "newuser", PasswordHash: "hash", Salt: "salt", InviteCode: code})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "11", userID)
+	suite.inviteRepo.AssertExpectations(suite.T())
+}
+
+func (suite *RegistrationTestSuite) TestRegister_InviteRedemptionFailed() {
+	useCase := suite.newUseCase(&conf.Registration{Mode: RegistrationModeInvite})
+	tx := new(MockTransactor)
+	useCase.tx = tx
+	suite.userRepo.On("GetUserByName", suite.ctx, "newuser").Return(nil, model.ErrUserNotFound)
+	suite.inviteRepo.On("RedeemInvite", suite.ctx, int64(5)).Return(&model.Invite{ID: 5, InviterID: 1}, nil)
+	suite.userRepo.On("CreateUser", suite.ctx, mock.AnythingOfType("*model.User")).Return(int64(11), nil)
+	suite.inviteRepo.On("CreateInviteRedemption", suite.ctx, int64(5), int64(11)).Return(errors.New("connection reset"))
+
+	_, err := useCase.Register(suite.ctx, &model.RegisterRequest{Username: "newuser", PasswordHash: "hash", Salt: "salt", InviteCode: inviteCode(suite.tokens, 2, 5)})
+
+	// 三步写入在同一事务中，记录失败时注册失败，用户和邀请码占用随事务回滚
+	assert.Equal(suite.T(), connect.CodeInternal, connect.CodeOf(err))
+	assert.Equal(suite.T(), 1, tx.calls)
+}
+
+func (suite *RegistrationTestSuite) TestRegister_InvalidInvite() {
+	useCase := suite.newUseCase(&conf.Registration{Mode: RegistrationModeInvite})
+
+	for _, code := range []string{
+		"",
+		"5",
+		"5.forged",
+		inviteCode(suite.tokens, 3, 5), // 其他租户的邀请码
+	} {
+		_, err := useCase.Register(suite.ctx, &model.RegisterRequest{Username: "newuser", PasswordHash: "hash", Salt: "salt", InviteCode: code})
+		assert.Equal(suite.T(), connect.CodePermissionDenied, connect.CodeOf(err), code)
+	}
+	suite.inviteRepo.AssertNotCalled(suite.T(), "RedeemInvite", mock.Anything, mock.Anything)
+}
+
+func (suite *RegistrationTestSuite) TestRegister_InviteExhausted() {
+	useCase := suite.newUseCase(&conf.Registration{Mode: RegistrationModeInvite})
+	suite.userRepo.On("GetUserByName", suite.ctx, "newuser").Return(nil, model.ErrUserNotFound)
+	suite.inviteRepo.On("RedeemInvite", suite.ctx, int64(5)).Return(nil, model.ErrInviteUnavailable)
+
+	_, err := useCase.Register(suite.ctx, &model.RegisterRequest{Username: "newuser", PasswordHash: "hash", Salt: "salt", InviteCode: inviteCode(suite.tokens, 2, 5)})
+
+	assert.Equal(suite.T(), connect.CodePermissionDenied, connect.CodeOf(err))
+	assert.True(suite.T(), errors.Is(err, errInvalidInvite))
+	suite.userRepo.AssertNotCalled(suite.T(), "CreateUser", mock.Anything, mock.Anything)
+}
+
+func TestRegistrationTestSuite(t *testing.T) {
+	suite.Run(t, new(RegistrationTestSuite))
+}
@@ -0,0 +1,695 @@
+package biz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data"
+
+	"connectrpc.com/connect"
+	"go.uber.org/zap"
+)
+
+const (
+	scimUserSchema  = "urn:ietf:params:scim:schemas:core:2.0:user:"
+	scimGroupSchema = "urn:ietf:params:scim:schemas:core:2.0:group:"
+)
+
+var (
+	// scimUserFilters 用户列表支持过滤的属性，键为小写
+	scimUserFilters = map[string]string{
+		"username":     "userName",
+		"externalid":   "externalId",
+		"emails":       "emails.value",
+		"emails.value": "emails.value",
+	}
+	// scimGroupFilters 组列表支持过滤的属性
+	scimGroupFilters = map[string]string{
+		"displayname": "displayName",
+		"externalid":  "externalId",
+	}
+	scimOperators = map[string]bool{"eq": true, "co": true, "sw": true, "ew": true, "pr": true}
+	// scimEncryptedFilters 加密保存的属性只能通过盲索引精确匹配
+	scimEncryptedFilters = map[string]bool{"emails.value": true}
+)
+
+type ScimUseCase struct {
+	repo       data.ScimRepo
+	tx         data.Transactor
+	events     *DomainEvents
+	clients    map[string]*conf.Scim_Client // 键为令牌的哈希
+	maxResults int32
+	l          *zap.Logger
+}
+
+func NewScimUseCase(repo data.ScimRepo, tx data.Transactor, events *DomainEvents, cfg *conf.Bootstrap, logger *zap.Logger) (model.ScimUseCase, error) {
+	uc := &ScimUseCase{
+		repo:       repo,
+		tx:         tx,
+		events:     events,
+		clients:    make(map[string]*conf.Scim_Client),
+		maxResults: 100, // 默认每页100个
+		l:          logger,
+	}
+
+	if cfg.Scim != nil {
+		if cfg.Scim.MaxResults > 0 {
+			uc.maxResults = cfg.Scim.MaxResults
+		}
+		for _, client := range cfg.Scim.Clients {
+			if len(client.Token) < 32 {
+				return nil, fmt.Errorf("scim client %q token must be at least 32 characters", client.Name)
+			}
+			uc.clients[hashToken(client.Token)] = client
+		}
+	}
+	return uc, nil
+}
+
+func (uc *ScimUseCase) Authenticate(ctx context.Context, token string) (*model.Tenant, error) {
+	// 按令牌哈希查找，比较的不是令牌原文
+	client, ok := uc.clients[hashToken(token)]
+	if token == "" || !ok {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("invalid scim token"))
+	}
+
+	tenantID := client.TenantId
+	if tenantID == 0 {
+		tenantID = 1
+	}
+	return &model.Tenant{ID: tenantID}, nil
+}
+
+func (uc *ScimUseCase) MaxResults() int32 {
+	return uc.maxResults
+}
+
+func (uc *ScimUseCase) CreateUser(ctx context.Context, user *model.ScimUser) (*model.ScimUser, error) {
+	if err := validateScimUser(user); err != nil {
+		return nil, err
+	}
+
+	// 创建用户和写入注册事件在同一事务中
+	var created *model.ScimUser
+	err := uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if created, err = uc.repo.CreateUser(ctx, user); err != nil {
+			return err
+		}
+		return uc.recordUser(ctx, model.EventUserRegistered, created)
+	})
+	if err != nil {
+		return nil, scimRepoError(err)
+	}
+	uc.l.Info("scim user created", zap.Int64("user_id", created.ID), zap.String("external_id", created.ExternalID))
+	return created, nil
+}
+
+func (uc *ScimUseCase) GetUser(ctx context.Context, id int64) (*model.ScimUser, error) {
+	user, err := uc.repo.GetUser(ctx, id)
+	if err != nil {
+		return nil, scimRepoError(err)
+	}
+	return user, nil
+}
+
+func (uc *ScimUseCase) ReplaceUser(ctx context.Context, user *model.ScimUser) (*model.ScimUser, error) {
+	if err := validateScimUser(user); err != nil {
+		return nil, err
+	}
+	return uc.updateUser(ctx, user)
+}
+
+func (uc *ScimUseCase) PatchUser(ctx context.Context, id int64, ops []*model.ScimPatchOperation) (*model.ScimUser, error) {
+	user, err := uc.repo.GetUser(ctx, id)
+	if err != nil {
+		return nil, scimRepoError(err)
+	}
+
+	for _, op := range ops {
+		if err := patchScimUser(user, op); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateScimUser(user); err != nil {
+		return nil, err
+	}
+	return uc.updateUser(ctx, user)
+}
+
+// updateUser 保存用户，用户由启用变为停用时在同一事务中写入 UserDisabled 事件
+func (uc *ScimUseCase) updateUser(ctx context.Context, user *model.ScimUser) (*model.ScimUser, error) {
+	var updated *model.ScimUser
+	err := uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := uc.repo.GetUser(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if updated, err = uc.repo.UpdateUser(ctx, user); err != nil {
+			return err
+		}
+		if current.Active && !updated.Active {
+			return uc.recordUser(ctx, model.EventUserDisabled, updated)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, scimRepoError(err)
+	}
+	uc.l.Info("scim user updated", zap.Int64("user_id", updated.ID), zap.Bool("active", updated.Active))
+	return updated, nil
+}
+
+func (uc *ScimUseCase) recordUser(ctx context.Context, eventType string, user *model.ScimUser) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	return uc.events.recordUser(ctx, eventType, &model.UserEvent{
+		TenantID: tenantID,
+		UserID:   user.ID,
+		Username: user.UserName,
+		Source:   model.EventSourceScim,
+	})
+}
+
+func (uc *ScimUseCase) DeleteUser(ctx context.Context, id int64) error {
+	if err := uc.repo.DeleteUser(ctx, id); err != nil {
+		return scimRepoError(err)
+	}
+	uc.l.Info("scim user deleted", zap.Int64("user_id", id))
+	return nil
+}
+
+func (uc *ScimUseCase) ListUsers(ctx context.Context, query *model.ScimQuery) (*model.ScimUserList, error) {
+	page, err := uc.page(query, scimUserFilters, scimUserSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	users, total, err := uc.repo.ListUsers(ctx, page)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	return &model.ScimUserList{TotalResults: total, StartIndex: page.Offset + 1, Users: users}, nil
+}
+
+func (uc *ScimUseCase) CreateGroup(ctx context.Context, group *model.ScimGroup) (*model.ScimGroup, error) {
+	if group.DisplayName == "" {
+		return nil, scimInvalid(model.ScimErrInvalidValue, "displayName is required")
+	}
+
+	created, err := uc.repo.CreateGroup(ctx, group)
+	if err != nil {
+		return nil, scimRepoError(err)
+	}
+	uc.l.Info("scim group created", zap.Int64("group_id", created.ID), zap.Int("members", len(created.Members)))
+	return created, nil
+}
+
+func (uc *ScimUseCase) GetGroup(ctx context.Context, id int64) (*model.ScimGroup, error) {
+	group, err := uc.repo.GetGroup(ctx, id)
+	if err != nil {
+		return nil, scimRepoError(err)
+	}
+	return group, nil
+}
+
+func (uc *ScimUseCase) ReplaceGroup(ctx context.Context, group *model.ScimGroup) (*model.ScimGroup, error) {
+	if group.DisplayName == "" {
+		return nil, scimInvalid(model.ScimErrInvalidValue, "displayName is required")
+	}
+
+	// 属性和成员一起更新，失败时组保持原样
+	err := uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := uc.repo.UpdateGroup(ctx, group); err != nil {
+			return scimRepoError(err)
+		}
+		if err := uc.repo.ReplaceGroupMembers(ctx, group.ID, memberIDs(group.Members)); err != nil {
+			return connect.NewError(connect.CodeInternal, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	uc.l.Info("scim group replaced", zap.Int64("group_id", group.ID), zap.Int("members", len(group.Members)))
+	// 副本可能还没有同步刚提交的修改
+	return uc.GetGroup(data.WithPrimary(ctx), group.ID)
+}
+
+func (uc *ScimUseCase) PatchGroup(ctx context.Context, id int64, ops []*model.ScimPatchOperation) (*model.ScimGroup, error) {
+	group, err := uc.repo.GetGroup(ctx, id)
+	if err != nil {
+		return nil, scimRepoError(err)
+	}
+
+	// 先校验全部操作，成员变更按顺序执行，属性最后一次写入
+	displayName, externalID := group.DisplayName, group.ExternalID
+	var changes []memberChange
+	for _, op := range ops {
+		opChanges, err := patchScimGroup(group, op)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, opChanges...)
+	}
+	if group.DisplayName == "" {
+		return nil, scimInvalid(model.ScimErrInvalidValue, "displayName is required")
+	}
+
+	// 所有操作在同一事务中执行，任一操作失败时都不生效
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		for _, c := range changes {
+			var err error
+			switch c.op {
+			case "add":
+				err = uc.repo.AddGroupMembers(ctx, id, c.userIDs)
+			case "remove":
+				err = uc.repo.RemoveGroupMembers(ctx, id, c.userIDs)
+			default:
+				err = uc.repo.ReplaceGroupMembers(ctx, id, c.userIDs)
+			}
+			if err != nil {
+				return connect.NewError(connect.CodeInternal, err)
+			}
+		}
+		if group.DisplayName != displayName || group.ExternalID != externalID {
+			if _, err := uc.repo.UpdateGroup(ctx, group); err != nil {
+				return scimRepoError(err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	uc.l.Info("scim group patched", zap.Int64("group_id", id), zap.Int("member_changes", len(changes)))
+	return uc.GetGroup(data.WithPrimary(ctx), id)
+}
+
+func (uc *ScimUseCase) DeleteGroup(ctx context.Context, id int64) error {
+	if err := uc.repo.DeleteGroup(ctx, id); err != nil {
+		return scimRepoError(err)
+	}
+	uc.l.Info("scim group deleted", zap.Int64("group_id", id))
+	return nil
+}
+
+func (uc *ScimUseCase) ListGroups(ctx context.Context, query *model.ScimQuery) (*model.ScimGroupList, error) {
+	page, err := uc.page(query, scimGroupFilters, scimGroupSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, total, err := uc.repo.ListGroups(ctx, page)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	return &model.ScimGroupList{TotalResults: total, StartIndex: page.Offset + 1, Groups: groups}, nil
+}
+
+// page 解析过滤条件，startIndex 小于1按1处理，count 不超过 maxResults（RFC 7644 3.4.2.4）
+func (uc *ScimUseCase) page(query *model.ScimQuery, attributes map[string]string, schema string) (*model.ScimPage, error) {
+	conditions, err := parseScimFilter(query.Filter, attributes, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.ScimPage{Conditions: conditions, Offset: max(query.StartIndex, 1) - 1, Limit: max(query.Count, 0)}
+	if page.Limit > uc.maxResults {
+		page.Limit = uc.maxResults
+	}
+	return page, nil
+}
+
+// parseScimFilter 支持 attr op "value" 和 attr pr，多个条件用 and 连接，每个属性只能出现一次
+func parseScimFilter(filter string, attributes map[string]string, schema string) ([]*model.ScimCondition, error) {
+	tokens, err := scimFilterTokens(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var conditions []*model.ScimCondition
+	seen := make(map[string]bool)
+	for i := 0; i < len(tokens); {
+		if len(conditions) > 0 {
+			if !strings.EqualFold(tokens[i], "and") {
+				return nil, scimInvalid(model.ScimErrInvalidFilter, "only 'and' is supported between filter expressions")
+			}
+			i++
+		}
+		if i+1 >= len(tokens) {
+			return nil, scimInvalid(model.ScimErrInvalidFilter, "incomplete filter expression")
+		}
+
+		name := strings.TrimPrefix(strings.ToLower(tokens[i]), schema)
+		attribute, ok := attributes[name]
+		if !ok {
+			return nil, scimInvalid(model.ScimErrInvalidFilter, "filtering on %q is not supported", tokens[i])
+		}
+		if seen[attribute] {
+			return nil, scimInvalid(model.ScimErrInvalidFilter, "%q appears more than once in filter", tokens[i])
+		}
+		seen[attribute] = true
+
+		operator := strings.ToLower(tokens[i+1])
+		if !scimOperators[operator] {
+			return nil, scimInvalid(model.ScimErrInvalidFilter, "operator %q is not supported", tokens[i+1])
+		}
+		if scimEncryptedFilters[attribute] && operator != "eq" && operator != "pr" {
+			return nil, scimInvalid(model.ScimErrInvalidFilter, "only eq and pr are supported for %q", tokens[i])
+		}
+		condition := &model.ScimCondition{Attribute: attribute, Operator: operator}
+		i += 2
+
+		if operator != "pr" {
+			if i >= len(tokens) || !strings.HasPrefix(tokens[i], `"`) {
+				return nil, scimInvalid(model.ScimErrInvalidFilter, "%s requires a string value", operator)
+			}
+			if err := json.Unmarshal([]byte(tokens[i]), &condition.Value); err != nil {
+				return nil, scimInvalid(model.ScimErrInvalidFilter, "invalid string value %s", tokens[i])
+			}
+			i++
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions, nil
+}
+
+// scimFilterTokens 按空白拆分过滤表达式，双引号内的内容作为一个词
+func scimFilterTokens(filter string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(filter); {
+		switch c := filter[i]; {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '"':
+			j := i + 1
+			for ; j < len(filter) && filter[j] != '"'; j++ {
+				if filter[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(filter) {
+				return nil, scimInvalid(model.ScimErrInvalidFilter, "unterminated string in filter")
+			}
+			tokens = append(tokens, filter[i:j+1])
+			i = j + 1
+		case c == '(' || c == ')' || c == '[' || c == ']':
+			return nil, scimInvalid(model.ScimErrInvalidFilter, "grouping and complex attribute filters are not supported")
+		default:
+			j := i
+			for j < len(filter) && !unicode.IsSpace(rune(filter[j])) && filter[j] != '"' {
+				j++
+			}
+			tokens = append(tokens, filter[i:j])
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+func validateScimUser(user *model.ScimUser) error {
+	if strings.TrimSpace(user.UserName) == "" {
+		return scimInvalid(model.ScimErrInvalidValue, "userName is required")
+	}
+	return nil
+}
+
+// patchScimUser 把一个 PATCH 操作应用到用户上，只保存一个邮箱，emails 的任何子路径都指向它
+func patchScimUser(user *model.ScimUser, op *model.ScimPatchOperation) error {
+	kind, err := scimPatchOp(op)
+	if err != nil {
+		return err
+	}
+	if op.Path == "" {
+		if kind == "remove" {
+			return scimInvalid(model.ScimErrNoTarget, "remove requires a path")
+		}
+		values, ok := op.Value.(map[string]any)
+		if !ok {
+			return scimInvalid(model.ScimErrInvalidValue, "value must be an object when path is omitted")
+		}
+		for path, value := range values {
+			if err := patchScimUser(user, &model.ScimPatchOperation{Op: kind, Path: path, Value: value}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	path := strings.TrimPrefix(strings.ToLower(op.Path), scimUserSchema)
+	if strings.HasPrefix(path, "emails[") {
+		path = "emails.value"
+	}
+	remove := kind == "remove"
+	switch path {
+	case "username":
+		if remove {
+			return scimInvalid(model.ScimErrMutability, "userName cannot be removed")
+		}
+		return scimString(op.Value, &user.UserName)
+	case "displayname":
+		return scimStringOrClear(op.Value, remove, &user.DisplayName)
+	case "externalid":
+		return scimStringOrClear(op.Value, remove, &user.ExternalID)
+	case "name.givenname":
+		return scimStringOrClear(op.Value, remove, &user.GivenName)
+	case "name.familyname":
+		return scimStringOrClear(op.Value, remove, &user.FamilyName)
+	case "name":
+		if remove {
+			user.GivenName, user.FamilyName = "", ""
+			return nil
+		}
+		name, ok := op.Value.(map[string]any)
+		if !ok {
+			return scimInvalid(model.ScimErrInvalidValue, "name must be an object")
+		}
+		for sub, value := range name {
+			if err := patchScimUser(user, &model.ScimPatchOperation{Op: kind, Path: "name." + sub, Value: value}); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "emails", "emails.value":
+		if remove {
+			user.Email = ""
+			return nil
+		}
+		email, ok := scimEmail(op.Value)
+		if !ok {
+			return scimInvalid(model.ScimErrInvalidValue, "invalid emails value")
+		}
+		user.Email = email
+		return nil
+	case "active":
+		if remove {
+			return scimInvalid(model.ScimErrMutability, "active cannot be removed")
+		}
+		active, ok := scimBool(op.Value)
+		if !ok {
+			return scimInvalid(model.ScimErrInvalidValue, "active must be a boolean")
+		}
+		user.Active = active
+		return nil
+	case "id", "schemas", "meta":
+		return nil
+	default:
+		return scimInvalid(model.ScimErrInvalidPath, "unsupported path %q", op.Path)
+	}
+}
+
+// memberChange 组成员的一次变更，op 为 add、remove 或 replace
+type memberChange struct {
+	op      string
+	userIDs []int64
+}
+
+// patchScimGroup 把属性变更应用到 group 上，返回需要执行的成员变更
+func patchScimGroup(group *model.ScimGroup, op *model.ScimPatchOperation) ([]memberChange, error) {
+	kind, err := scimPatchOp(op)
+	if err != nil {
+		return nil, err
+	}
+	if op.Path == "" {
+		if kind == "remove" {
+			return nil, scimInvalid(model.ScimErrNoTarget, "remove requires a path")
+		}
+		values, ok := op.Value.(map[string]any)
+		if !ok {
+			return nil, scimInvalid(model.ScimErrInvalidValue, "value must be an object when path is omitted")
+		}
+		var changes []memberChange
+		for path, value := range values {
+			c, err := patchScimGroup(group, &model.ScimPatchOperation{Op: kind, Path: path, Value: value})
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, c...)
+		}
+		return changes, nil
+	}
+
+	path := strings.TrimPrefix(strings.ToLower(op.Path), scimGroupSchema)
+	remove := kind == "remove"
+	switch {
+	case path == "displayname":
+		if remove {
+			return nil, scimInvalid(model.ScimErrMutability, "displayName cannot be removed")
+		}
+		return nil, scimString(op.Value, &group.DisplayName)
+	case path == "externalid":
+		return nil, scimStringOrClear(op.Value, remove, &group.ExternalID)
+	case path == "members":
+		var ids []int64
+		if op.Value != nil {
+			if ids, err = scimMemberIDs(op.Value); err != nil {
+				return nil, err
+			}
+		}
+		if remove && op.Value == nil {
+			// 未指定成员时移除全部成员
+			return []memberChange{{op: "replace"}}, nil
+		}
+		return []memberChange{{op: kind, userIDs: ids}}, nil
+	case strings.HasPrefix(path, "members[") && remove:
+		// members[value eq "2819c223"]
+		conditions, err := parseScimFilter(strings.TrimSuffix(path[len("members["):], "]"), map[string]string{"value": "value"}, "")
+		if err != nil || len(conditions) != 1 || conditions[0].Operator != "eq" {
+			return nil, scimInvalid(model.ScimErrInvalidPath, "unsupported member filter %q", op.Path)
+		}
+		id, err := strconv.ParseInt(conditions[0].Value, 10, 64)
+		if err != nil {
+			return nil, scimInvalid(model.ScimErrInvalidValue, "invalid member %q", conditions[0].Value)
+		}
+		return []memberChange{{op: "remove", userIDs: []int64{id}}}, nil
+	case path == "id" || path == "schemas" || path == "meta":
+		return nil, nil
+	default:
+		return nil, scimInvalid(model.ScimErrInvalidPath, "unsupported path %q", op.Path)
+	}
+}
+
+// scimPatchOp 返回小写的操作名，部分客户端会发送 Add、Replace
+func scimPatchOp(op *model.ScimPatchOperation) (string, error) {
+	kind := strings.ToLower(op.Op)
+	if kind != "add" && kind != "replace" && kind != "remove" {
+		return "", scimInvalid(model.ScimErrInvalidSyntax, "unsupported patch op %q", op.Op)
+	}
+	return kind, nil
+}
+
+func scimString(value any, target *string) error {
+	s, ok := value.(string)
+	if !ok || s == "" {
+		return scimInvalid(model.ScimErrInvalidValue, "value must be a non-empty string")
+	}
+	*target = s
+	return nil
+}
+
+func scimStringOrClear(value any, remove bool, target *string) error {
+	if remove || value == nil {
+		*target = ""
+		return nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return scimInvalid(model.ScimErrInvalidValue, "value must be a string")
+	}
+	*target = s
+	return nil
+}
+
+// scimBool 兼容以字符串 "True"、"False" 发送布尔值的客户端
+func scimBool(value any) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		b, err := strconv.ParseBool(strings.ToLower(v))
+		return b, err == nil
+	default:
+		return false, false
+	}
+}
+
+// scimEmail 取 primary 邮箱，没有时取第一个；值可以是字符串、单个对象或数组
+func scimEmail(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case map[string]any:
+		email, ok := v["value"].(string)
+		return email, ok
+	case []any:
+		var first string
+		for _, item := range v {
+			entry, ok := item.(map[string]any)
+			if !ok {
+				return "", false
+			}
+			email, _ := entry["value"].(string)
+			if primary, _ := entry["primary"].(bool); primary {
+				return email, true
+			}
+			if first == "" {
+				first = email
+			}
+		}
+		return first, true
+	default:
+		return "", false
+	}
+}
+
+// scimMemberIDs 解析 [{"value": "用户ID"}]
+func scimMemberIDs(value any) ([]int64, error) {
+	items, ok := value.([]any)
+	if !ok {
+		items = []any{value}
+	}
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		entry, _ := item.(map[string]any)
+		raw, _ := entry["value"].(string)
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, scimInvalid(model.ScimErrInvalidValue, "invalid member %q", raw)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func memberIDs(members []*model.ScimMember) []int64 {
+	ids := make([]int64, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.UserID)
+	}
+	return ids
+}
+
+func scimInvalid(scimType, format string, args ...any) error {
+	return connect.NewError(connect.CodeInvalidArgument, &model.ScimError{Type: scimType, Detail: fmt.Sprintf(format, args...)})
+}
+
+func scimRepoError(err error) error {
+	switch {
+	case errors.Is(err, model.ErrScimResourceNotFound):
+		return connect.NewError(connect.CodeNotFound, err)
+	case errors.Is(err, model.ErrScimConflict):
+		return connect.NewError(connect.CodeAlreadyExists, &model.ScimError{Type: model.ScimErrUniqueness, Detail: err.Error()})
+	default:
+		return connect.NewError(connect.CodeInternal, err)
+	}
+}
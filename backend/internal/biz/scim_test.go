@@ -0,0 +1,314 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+// MockScimRepo 是 ScimRepo 的模拟实现
+type MockScimRepo struct {
+	mock.Mock
+}
+
+func (m *MockScimRepo) CreateUser(ctx context.Context, user *model.ScimUser) (*model.ScimUser, error) {
+	args := m.Called(ctx, user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ScimUser), args.Error(1)
+}
+
+func (m *MockScimRepo) GetUser(ctx context.Context, id int64) (*model.ScimUser, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ScimUser), args.Error(1)
+}
+
+func (m *MockScimRepo) UpdateUser(ctx context.Context, user *model.ScimUser) (*model.ScimUser, error) {
+	args := m.Called(ctx, user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ScimUser), args.Error(1)
+}
+
+func (m *MockScimRepo) DeleteUser(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockScimRepo) ListUsers(ctx context.Context, page *model.ScimPage) ([]*model.ScimUser, int64, error) {
+	args := m.Called(ctx, page)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*model.ScimUser), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockScimRepo) CreateGroup(ctx context.Context, group *model.ScimGroup) (*model.ScimGroup, error) {
+	args := m.Called(ctx, group)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ScimGroup), args.Error(1)
+}
+
+func (m *MockScimRepo) GetGroup(ctx context.Context, id int64) (*model.ScimGroup, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ScimGroup), args.Error(1)
+}
+
+func (m *MockScimRepo) UpdateGroup(ctx context.Context, group *model.ScimGroup) (*model.ScimGroup, error) {
+	args := m.Called(ctx, group)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ScimGroup), args.Error(1)
+}
+
+func (m *MockScimRepo) DeleteGroup(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockScimRepo) ListGroups(ctx context.Context, page *model.ScimPage) ([]*model.ScimGroup, int64, error) {
+	args := m.Called(ctx, page)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*model.ScimGroup), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockScimRepo) AddGroupMembers(ctx context.Context, groupID int64, userIDs []int64) error {
+	args := m.Called(ctx, groupID, userIDs)
+	return args.Error(0)
+}
+
+func (m *MockScimRepo) RemoveGroupMembers(ctx context.Context, groupID int64, userIDs []int64) error {
+	args := m.Called(ctx, groupID, userIDs)
+	return args.Error(0)
+}
+
+func (m *MockScimRepo) ReplaceGroupMembers(ctx context.Context, groupID int64, userIDs []int64) error {
+	args := m.Called(ctx, groupID, userIDs)
+	return args.Error(0)
+}
+
+// ScimUseCaseTestSuite 是 ScimUseCase 的测试套件
+type ScimUseCaseTestSuite struct {
+	suite.Suite
+	repo   *MockScimRepo
+	outbox *MockOutboxRepo
+	uc     model.ScimUseCase
+	ctx    context.Context
+}
+
+const scimTestToken = "0123456789abcdef0123456789abcdef"
+
+func (suite *ScimUseCaseTestSuite) SetupTest() {
+	suite.repo = new(MockScimRepo)
+	suite.outbox = new(MockOutboxRepo)
+	suite.ctx = model.NewTenantContext(context.Background(), &model.Tenant{ID: 2})
+	logger, _ := zap.NewDevelopment()
+
+	uc, err := NewScimUseCase(suite.repo, new(MockTransactor), NewDomainEvents(suite.outbox, zap.NewNop()), &conf.Bootstrap{
+		Scim: &conf.Scim{
+			Clients:    []*conf.Scim_Client{{Name: "okta", Token: scimTestToken, TenantId: 2}},
+			MaxResults: 20,
+		},
+	}, logger)
+	assert.NoError(suite.T(), err)
+	suite.uc = uc
+}
+
+func (suite *ScimUseCaseTestSuite) TestNewScimUseCase_ShortToken() {
+	logger, _ := zap.NewDevelopment()
+
+	_, err := NewScimUseCase(suite.repo, new(MockTransactor), NewDomainEvents(new(MockOutboxRepo), zap.NewNop()), &conf.Bootstrap{
+		Scim: &conf.Scim{Clients: []*conf.Scim_Client{{Name: "okta", Token: "short"}}},
+	}, logger)
+
+	assert.Error(suite.T(), err)
+}
+
+func (suite *ScimUseCaseTestSuite) TestAuthenticate() {
+	tenant, err := suite.uc.Authenticate(suite.ctx, scimTestToken)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), tenant.ID)
+
+	for _, token := range []string{"", "wrong"} {
+		_, err = suite.uc.Authenticate(suite.ctx, token)
+		assert.Equal(suite.T(), connect.CodeUnauthenticated, connect.CodeOf(err))
+	}
+}
+
+func (suite *ScimUseCaseTestSuite) TestListUsers_Filter() {
+	suite.repo.On("ListUsers", suite.ctx, &model.ScimPage{
+		Conditions: []*model.ScimCondition{
+			{Attribute: "userName", Operator: "eq", Value: `bjensen "b" and co`},
+			{Attribute: "externalId", Operator: "pr"},
+		},
+		Offset: 10,
+		Limit:  20,
+	}).Return([]*model.ScimUser{{ID: 1}}, int64(31), nil)
+
+	list, err := suite.uc.ListUsers(suite.ctx, &model.ScimQuery{
+		Filter:     `UserName Eq "bjensen \"b\" and co" and urn:ietf:params:scim:schemas:core:2.0:User:externalId pr`,
+		StartIndex: 11,
+		Count:      500,
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(31), list.TotalResults)
+	assert.Equal(suite.T(), int32(11), list.StartIndex)
+	assert.Len(suite.T(), list.Users, 1)
+}
+
+func (suite *ScimUseCaseTestSuite) TestListUsers_InvalidFilter() {
+	for _, filter := range []string{
+		`userName eq "a" or userName eq "b"`,
+		`password eq "secret"`,
+		`userName gt "a"`,
+		`userName eq`,
+		`userName eq "a`,
+		`emails[type eq "work"]`,
+		`userName eq "a" and userName eq "b"`,
+		`userName eq 1`,
+		`emails co "example.com"`,
+		`displayName eq "Barbara"`,
+	} {
+		_, err := suite.uc.ListUsers(suite.ctx, &model.ScimQuery{Filter: filter, StartIndex: 1, Count: 10})
+
+		var scimErr *model.ScimError
+		assert.ErrorAs(suite.T(), err, &scimErr, filter)
+		assert.Equal(suite.T(), model.ScimErrInvalidFilter, scimErr.Type, filter)
+	}
+	suite.repo.AssertNotCalled(suite.T(), "ListUsers", mock.Anything, mock.Anything)
+}
+
+func (suite *ScimUseCaseTestSuite) TestPatchUser() {
+	// 修改前和保存时各读取一次，每次返回新的对象
+	for range 2 {
+		suite.repo.On("GetUser", suite.ctx, int64(5)).Return(&model.ScimUser{ID: 5, UserName: "alice", Active: true, Email: "old@example.com"}, nil).Once()
+	}
+	suite.repo.On("UpdateUser", suite.ctx, &model.ScimUser{
+		ID:         5,
+		UserName:   "alice",
+		GivenName:  "Alice",
+		FamilyName: "",
+		Email:      "alice@example.com",
+		Active:     false,
+	}).Return(&model.ScimUser{ID: 5}, nil)
+
+	_, err := suite.uc.PatchUser(suite.ctx, 5, []*model.ScimPatchOperation{
+		// 不带 path 的替换
+		{Op: "replace", Value: map[string]any{"active": "False", "name": map[string]any{"givenName": "Alice", "familyName": "Smith"}}},
+		{Op: "Replace", Path: `emails[type eq "work"].value`, Value: "alice@example.com"},
+		{Op: "remove", Path: "name.familyName"},
+	})
+
+	assert.NoError(suite.T(), err)
+	suite.repo.AssertExpectations(suite.T())
+	// 用户被停用
+	assert.Equal(suite.T(), []string{model.EventUserDisabled}, suite.outbox.types())
+	assert.Equal(suite.T(), int64(2), suite.outbox.events[0].TenantID)
+}
+
+func (suite *ScimUseCaseTestSuite) TestPatchUser_Invalid() {
+	suite.repo.On("GetUser", suite.ctx, int64(5)).Return(&model.ScimUser{ID: 5, UserName: "alice", Active: true}, nil)
+
+	for expected, op := range map[string]*model.ScimPatchOperation{
+		model.ScimErrInvalidSyntax: {Op: "move", Path: "userName", Value: "bob"},
+		model.ScimErrInvalidPath:   {Op: "replace", Path: "password", Value: "secret"},
+		model.ScimErrMutability:    {Op: "remove", Path: "userName"},
+		model.ScimErrNoTarget:      {Op: "remove"},
+		model.ScimErrInvalidValue:  {Op: "replace", Path: "active", Value: "maybe"},
+	} {
+		_, err := suite.uc.PatchUser(suite.ctx, 5, []*model.ScimPatchOperation{op})
+
+		var scimErr *model.ScimError
+		assert.ErrorAs(suite.T(), err, &scimErr, expected)
+		assert.Equal(suite.T(), expected, scimErr.Type)
+	}
+	suite.repo.AssertNotCalled(suite.T(), "UpdateUser", mock.Anything, mock.Anything)
+}
+
+func (suite *ScimUseCaseTestSuite) TestPatchGroup_Members() {
+	group := &model.ScimGroup{ID: 3, DisplayName: "Engineering"}
+	suite.repo.On("GetGroup", suite.ctx, int64(3)).Return(group, nil).Once()
+	// 修改后的组从主库读取，不受副本延迟影响
+	suite.repo.On("GetGroup", data.WithPrimary(suite.ctx), int64(3)).Return(group, nil).Once()
+	suite.repo.On("AddGroupMembers", suite.ctx, int64(3), []int64{7, 8}).Return(nil).Once()
+	suite.repo.On("RemoveGroupMembers", suite.ctx, int64(3), []int64{9}).Return(nil).Once()
+	suite.repo.On("RemoveGroupMembers", suite.ctx, int64(3), []int64{10}).Return(nil).Once()
+
+	_, err := suite.uc.PatchGroup(suite.ctx, 3, []*model.ScimPatchOperation{
+		{Op: "Add", Path: "members", Value: []any{map[string]any{"value": "7"}, map[string]any{"value": "8"}}},
+		{Op: "remove", Path: `members[value eq "9"]`},
+		{Op: "remove", Path: "members", Value: []any{map[string]any{"value": "10"}}},
+	})
+
+	assert.NoError(suite.T(), err)
+	suite.repo.AssertExpectations(suite.T())
+	// 属性没有变化时不更新组
+	suite.repo.AssertNotCalled(suite.T(), "UpdateGroup", mock.Anything, mock.Anything)
+}
+
+func (suite *ScimUseCaseTestSuite) TestPatchGroup_Rename() {
+	suite.repo.On("GetGroup", suite.ctx, int64(3)).Return(&model.ScimGroup{ID: 3, DisplayName: "Engineering"}, nil)
+	suite.repo.On("UpdateGroup", suite.ctx, &model.ScimGroup{ID: 3, DisplayName: "Platform"}).Return(nil, model.ErrScimConflict)
+
+	_, err := suite.uc.PatchGroup(suite.ctx, 3, []*model.ScimPatchOperation{
+		{Op: "replace", Value: map[string]any{"id": "3", "displayName": "Platform"}},
+	})
+
+	assert.Equal(suite.T(), connect.CodeAlreadyExists, connect.CodeOf(err))
+}
+
+func (suite *ScimUseCaseTestSuite) TestCreateUser_Conflict() {
+	suite.repo.On("CreateUser", suite.ctx, mock.Anything).Return(nil, model.ErrScimConflict)
+
+	_, err := suite.uc.CreateUser(suite.ctx, &model.ScimUser{UserName: "alice", Active: true})
+
+	var scimErr *model.ScimError
+	assert.ErrorAs(suite.T(), err, &scimErr)
+	assert.Equal(suite.T(), model.ScimErrUniqueness, scimErr.Type)
+	assert.Equal(suite.T(), connect.CodeAlreadyExists, connect.CodeOf(err))
+}
+
+func (suite *ScimUseCaseTestSuite) TestCreateUser_MissingUserName() {
+	_, err := suite.uc.CreateUser(suite.ctx, &model.ScimUser{Email: "a@example.com"})
+
+	assert.Equal(suite.T(), connect.CodeInvalidArgument, connect.CodeOf(err))
+	suite.repo.AssertNotCalled(suite.T(), "CreateUser", mock.Anything, mock.Anything)
+}
+
+func (suite *ScimUseCaseTestSuite) TestDeleteUser() {
+	suite.repo.On("DeleteUser", suite.ctx, int64(5)).Return(nil).Once()
+	suite.repo.On("DeleteUser", suite.ctx, int64(6)).Return(model.ErrScimResourceNotFound).Once()
+	suite.repo.On("DeleteUser", suite.ctx, int64(7)).Return(errors.New("db down")).Once()
+
+	assert.NoError(suite.T(), suite.uc.DeleteUser(suite.ctx, 5))
+	assert.Equal(suite.T(), connect.CodeNotFound, connect.CodeOf(suite.uc.DeleteUser(suite.ctx, 6)))
+	assert.Equal(suite.T(), connect.CodeInternal, connect.CodeOf(suite.uc.DeleteUser(suite.ctx, 7)))
+}
+
+func TestScimUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(ScimUseCaseTestSuite))
+}
@@ -0,0 +1,303 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var errInvalidClient = errors.New("invalid client credentials")
+
+const (
+	// sessionCacheSize 缓存条目上限，达到上限时先清理过期条目
+	sessionCacheSize = 10000
+	// maxActorDepth 委托链最大长度，防止服务之间循环交换
+	maxActorDepth = 5
+)
+
+// SessionManager 在令牌签名校验之外检查会话是否已注销，供认证拦截器和资源服务内省使用
+type SessionManager struct {
+	tokens      *TokenManager
+	repo        data.SessionRepo
+	clients     map[string]*conf.OAuth_Client
+	issuer      string
+	cacheTTL    time.Duration
+	exchangeTTL time.Duration
+	l           *zap.Logger
+
+	mu    sync.RWMutex
+	cache map[string]cachedSession
+}
+
+// cachedSession 按令牌哈希缓存的校验结果，principal 为空表示令牌所在会话已注销
+type cachedSession struct {
+	principal *model.Principal
+	expiresAt time.Time
+}
+
+var _ model.TokenVerifier = (*SessionManager)(nil)
+
+func NewSessionManager(tokens *TokenManager, repo data.SessionRepo, cfg *conf.Bootstrap, logger *zap.Logger) (*SessionManager, error) {
+	m := &SessionManager{
+		tokens:      tokens,
+		repo:        repo,
+		clients:     make(map[string]*conf.OAuth_Client),
+		cacheTTL:    10 * time.Second, // 默认10秒
+		exchangeTTL: 5 * time.Minute,  // 默认5分钟
+		l:           logger,
+		cache:       make(map[string]cachedSession),
+	}
+
+	if cfg.Oauth != nil {
+		for _, client := range cfg.Oauth.Clients {
+			if client.ClientId == "" || client.ClientSecret == "" {
+				return nil, errors.New("oauth client requires client_id and client_secret")
+			}
+			m.clients[client.ClientId] = client
+		}
+		m.issuer = cfg.Oauth.Issuer
+		if cfg.Oauth.IntrospectionCacheSeconds > 0 {
+			m.cacheTTL = time.Duration(cfg.Oauth.IntrospectionCacheSeconds) * time.Second
+		}
+		if cfg.Oauth.TokenExchangeTtlSeconds > 0 {
+			m.exchangeTTL = time.Duration(cfg.Oauth.TokenExchangeTtlSeconds) * time.Second
+		}
+	}
+	return m, nil
+}
+
+func (m *SessionManager) VerifyToken(ctx context.Context, token string) (*model.Principal, error) {
+	principal, err := m.inspect(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	// 令牌交换签发的令牌只能用于目标服务
+	if principal == nil || principal.Audience != "" {
+		return nil, errInvalidToken
+	}
+	return principal, nil
+}
+
+func (m *SessionManager) Introspect(ctx context.Context, client model.ClientCredentials, token string) (*model.Introspection, error) {
+	if _, err := m.authenticate(client); err != nil {
+		return nil, err
+	}
+
+	principal, err := m.inspect(ctx, token)
+	if err != nil && !errors.Is(err, errInvalidToken) {
+		return nil, connect.NewError(connect.CodeUnavailable, err)
+	}
+	if principal == nil {
+		return &model.Introspection{Active: false}, nil
+	}
+
+	m.l.Debug("token introspected", zap.String("client_id", client.ID), zap.String("jti", principal.TokenID))
+	return &model.Introspection{
+		Active:    true,
+		Issuer:    m.issuer,
+		Principal: principal,
+	}, nil
+}
+
+func (m *SessionManager) ExchangeToken(ctx context.Context, client model.ClientCredentials, req *model.TokenExchangeRequest) (*model.TokenExchangeResult, error) {
+	c, err := m.authenticate(client)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.SubjectTokenType != model.TokenTypeAccessToken {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("unsupported subject_token_type"))
+	}
+	if req.RequestedTokenType != "" && req.RequestedTokenType != model.TokenTypeAccessToken {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("unsupported requested_token_type"))
+	}
+	if req.Audience == "" || !slices.Contains(c.AllowedAudiences, req.Audience) {
+		return nil, connect.NewError(connect.CodeInvalidArgument, model.ErrInvalidTarget)
+	}
+	// 必须申请明确的 scope，不能换出不受限的令牌
+	if len(req.Scopes) == 0 {
+		return nil, connect.NewError(connect.CodeInvalidArgument, model.ErrInvalidScope)
+	}
+
+	subject, err := m.inspect(ctx, req.SubjectToken)
+	if err != nil && !errors.Is(err, errInvalidToken) {
+		return nil, connect.NewError(connect.CodeUnavailable, err)
+	}
+	if subject == nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, model.ErrInvalidGrant)
+	}
+	// 绑定密钥的令牌必须附带同一密钥的证明才能交换，否则窃取的令牌可以换成未绑定的令牌
+	if subject.ProofKey != "" && model.ProofKeyFromContext(ctx) != subject.ProofKey {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("%w: subject token is bound to another key", model.ErrInvalidDPoPProof))
+	}
+
+	// scope 只能逐级收窄
+	for _, scope := range req.Scopes {
+		if !slices.Contains(c.AllowedScopes, scope) || (len(subject.Scopes) > 0 && !slices.Contains(subject.Scopes, scope)) {
+			return nil, connect.NewError(connect.CodeInvalidArgument, model.ErrInvalidScope)
+		}
+	}
+
+	actor := &model.Actor{Subject: c.ClientId, Actor: subject.Actor}
+	depth := 0
+	for a := actor; a != nil; a = a.Actor {
+		depth++
+	}
+	if depth > maxActorDepth {
+		return nil, connect.NewError(connect.CodeInvalidArgument, model.ErrInvalidGrant)
+	}
+
+	token, expiresAt, err := m.tokens.IssueDelegated(subject, req.Audience, req.Scopes, actor, m.exchangeTTL)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	m.l.Info("token exchanged",
+		zap.Int64("tenant_id", subject.TenantID),
+		zap.Int64("user_id", subject.UserID),
+		zap.String("client_id", c.ClientId),
+		zap.String("audience", req.Audience),
+		zap.Strings("scopes", req.Scopes),
+		zap.Int("actor_depth", depth),
+		zap.String("sid", subject.SessionID),
+	)
+	return &model.TokenExchangeResult{
+		AccessToken: token,
+		Scopes:      req.Scopes,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+func (m *SessionManager) RefreshToken(ctx context.Context, principal *model.Principal) (*model.IssuedToken, error) {
+	// 已绑定的令牌由认证拦截器保证证明来自同一密钥，新令牌沿用原会话和绑定
+	next := &model.Principal{
+		TenantID:  principal.TenantID,
+		UserID:    principal.UserID,
+		Username:  principal.Username,
+		SessionID: principal.SessionID,
+		ProofKey:  principal.ProofKey,
+	}
+	// 首次绑定密钥时换成新会话，并注销旧会话，已经泄露的 Bearer 令牌随之失效
+	jkt := model.ProofKeyFromContext(ctx)
+	rebind := principal.ProofKey == "" && jkt != ""
+	if rebind {
+		next.SessionID = uuid.NewString()
+		next.ProofKey = jkt
+	}
+
+	token, expiresAt, err := m.tokens.Reissue(next)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	if rebind && principal.SessionID != "" {
+		if err := m.RevokeSession(ctx, principal); err != nil {
+			return nil, err
+		}
+	}
+
+	m.l.Info("token refreshed",
+		zap.Int64("user_id", principal.UserID),
+		zap.String("sid", next.SessionID),
+		zap.Bool("bound", next.ProofKey != ""),
+	)
+	return &model.IssuedToken{
+		AccessToken: token,
+		TokenType:   model.TokenType(next.ProofKey),
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+func (m *SessionManager) RevokeSession(ctx context.Context, principal *model.Principal) error {
+	if principal.SessionID == "" {
+		return connect.NewError(connect.CodeFailedPrecondition, errors.New("token has no session, wait for it to expire"))
+	}
+
+	// 会话中的令牌可能经过刷新，保留到最晚签发的令牌过期
+	if err := m.repo.RevokeSession(ctx, principal.SessionID, m.tokens.lifetime()); err != nil {
+		return connect.NewError(connect.CodeInternal, err)
+	}
+
+	// 本实例立即生效，其他实例最多在缓存过期后生效
+	m.mu.Lock()
+	for key, entry := range m.cache {
+		if entry.principal != nil && entry.principal.SessionID == principal.SessionID {
+			delete(m.cache, key)
+		}
+	}
+	m.mu.Unlock()
+
+	m.l.Info("session revoked", zap.Int64("user_id", principal.UserID), zap.String("sid", principal.SessionID))
+	return nil
+}
+
+// authenticate 校验客户端凭据
+func (m *SessionManager) authenticate(client model.ClientCredentials) (*conf.OAuth_Client, error) {
+	c, ok := m.clients[client.ID]
+	if !ok || !constantTimeCompare(client.Secret, c.ClientSecret) {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errInvalidClient)
+	}
+	return c, nil
+}
+
+// inspect 校验令牌并检查会话，令牌有效但会话已注销时返回 nil
+func (m *SessionManager) inspect(ctx context.Context, token string) (*model.Principal, error) {
+	key := hashToken(token)
+	now := time.Now()
+
+	m.mu.RLock()
+	entry, ok := m.cache[key]
+	m.mu.RUnlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.principal, nil
+	}
+
+	principal, err := m.tokens.VerifyToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if principal.SessionID != "" {
+		revoked, err := m.repo.IsSessionRevoked(ctx, principal.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			principal = nil
+		}
+	}
+
+	// 缓存不超过令牌的有效期
+	expiresAt := now.Add(m.cacheTTL)
+	if principal != nil && principal.ExpiresAt.Before(expiresAt) {
+		expiresAt = principal.ExpiresAt
+	}
+	m.mu.Lock()
+	if len(m.cache) >= sessionCacheSize {
+		m.evictExpired(now)
+	}
+	m.cache[key] = cachedSession{principal: principal, expiresAt: expiresAt}
+	m.mu.Unlock()
+	return principal, nil
+}
+
+// evictExpired 清理过期缓存，仍然超出上限时全部清空，调用方需持有写锁
+func (m *SessionManager) evictExpired(now time.Time) {
+	for key, entry := range m.cache {
+		if !now.Before(entry.expiresAt) {
+			delete(m.cache, key)
+		}
+	}
+	if len(m.cache) >= sessionCacheSize {
+		clear(m.cache)
+	}
+}
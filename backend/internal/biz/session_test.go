@@ -0,0 +1,307 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+// MockSessionRepo 是 SessionRepo 的模拟实现
+type MockSessionRepo struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepo) RevokeSession(ctx context.Context, sessionID string, ttl time.Duration) error {
+	args := m.Called(ctx, sessionID, ttl)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepo) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	args := m.Called(ctx, sessionID)
+	return args.Bool(0), args.Error(1)
+}
+
+// SessionManagerTestSuite 是 SessionManager 的测试套件
+type SessionManagerTestSuite struct {
+	suite.Suite
+	repo     *MockSessionRepo
+	tokens   *TokenManager
+	sessions *SessionManager
+	client   model.ClientCredentials
+	ctx      context.Context
+}
+
+func (suite *SessionManagerTestSuite) SetupTest() {
+	suite.repo = new(MockSessionRepo)
+	suite.ctx = context.Background()
+	suite.client = model.ClientCredentials{ID: "orders", Secret: "orders-secret"}
+	logger, _ := zap.NewDevelopment()
+
+	cfg := &conf.Bootstrap{
+		Auth: &conf.Auth{JwtSecret: "test-secret"},
+		Oauth: &conf.OAuth{
+			Clients: []*conf.OAuth_Client{
+				{ClientId: "orders", ClientSecret: "orders-secret", AllowedAudiences: []string{"payments"}, AllowedScopes: []string{"payments:read"}},
+				{ClientId: "gateway", ClientSecret: "gateway-secret", AllowedAudiences: []string{"orders"}, AllowedScopes: []string{"orders:read", "orders:write", "payments:read"}},
+			},
+			Issuer: "https://auth.example.com",
+		},
+	}
+	tokens, err := NewTokenManager(cfg, logger)
+	assert.NoError(suite.T(), err)
+	suite.tokens = tokens
+	sessions, err := NewSessionManager(tokens, suite.repo, cfg, logger)
+	assert.NoError(suite.T(), err)
+	suite.sessions = sessions
+}
+
+func (suite *SessionManagerTestSuite) issue() (string, *model.Principal) {
+	token, err := suite.tokens.Issue(2, 7, "testuser")
+	assert.NoError(suite.T(), err)
+	principal, err := suite.tokens.VerifyToken(suite.ctx, token)
+	assert.NoError(suite.T(), err)
+	return token, principal
+}
+
+func (suite *SessionManagerTestSuite) TestNewSessionManager_InvalidClient() {
+	logger, _ := zap.NewDevelopment()
+
+	_, err := NewSessionManager(suite.tokens, suite.repo, &conf.Bootstrap{
+		Oauth: &conf.OAuth{Clients: []*conf.OAuth_Client{{ClientId: "orders"}}},
+	}, logger)
+
+	assert.Error(suite.T(), err)
+}
+
+func (suite *SessionManagerTestSuite) TestIntrospect() {
+	token, principal := suite.issue()
+	suite.repo.On("IsSessionRevoked", suite.ctx, principal.SessionID).Return(false, nil).Once()
+
+	result, err := suite.sessions.Introspect(suite.ctx, suite.client, token)
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), result.Active)
+	assert.Equal(suite.T(), "https://auth.example.com", result.Issuer)
+	assert.Equal(suite.T(), int64(7), result.Principal.UserID)
+	assert.Equal(suite.T(), principal.TokenID, result.Principal.TokenID)
+
+	// 缓存期内不再查询存储
+	_, err = suite.sessions.VerifyToken(suite.ctx, token)
+	assert.NoError(suite.T(), err)
+	suite.repo.AssertNumberOfCalls(suite.T(), "IsSessionRevoked", 1)
+}
+
+func (suite *SessionManagerTestSuite) TestIntrospect_Inactive() {
+	token, principal := suite.issue()
+	suite.repo.On("IsSessionRevoked", suite.ctx, principal.SessionID).Return(true, nil)
+
+	for _, token := range []string{token, "not-a-token"} {
+		result, err := suite.sessions.Introspect(suite.ctx, suite.client, token)
+		assert.NoError(suite.T(), err)
+		assert.False(suite.T(), result.Active)
+		assert.Nil(suite.T(), result.Principal)
+	}
+}
+
+func (suite *SessionManagerTestSuite) TestIntrospect_InvalidClient() {
+	token, _ := suite.issue()
+
+	for _, client := range []model.ClientCredentials{
+		{},
+		{ID: "orders", Secret: "wrong"},
+		{ID: "unknown", Secret: "orders-secret"},
+	} {
+		_, err := suite.sessions.Introspect(suite.ctx, client, token)
+		assert.Equal(suite.T(), connect.CodeUnauthenticated, connect.CodeOf(err), client.ID)
+	}
+	suite.repo.AssertNotCalled(suite.T(), "IsSessionRevoked", mock.Anything, mock.Anything)
+}
+
+func (suite *SessionManagerTestSuite) TestIntrospect_RepoError() {
+	token, principal := suite.issue()
+	suite.repo.On("IsSessionRevoked", suite.ctx, principal.SessionID).Return(false, errors.New("redis down"))
+
+	_, err := suite.sessions.Introspect(suite.ctx, suite.client, token)
+
+	assert.Equal(suite.T(), connect.CodeUnavailable, connect.CodeOf(err))
+}
+
+func (suite *SessionManagerTestSuite) exchange(client model.ClientCredentials, token, audience string, scopes ...string) (*model.TokenExchangeResult, error) {
+	return suite.sessions.ExchangeToken(suite.ctx, client, &model.TokenExchangeRequest{
+		SubjectToken:     token,
+		SubjectTokenType: model.TokenTypeAccessToken,
+		Audience:         audience,
+		Scopes:           scopes,
+	})
+}
+
+func (suite *SessionManagerTestSuite) TestExchangeToken() {
+	token, principal := suite.issue()
+	suite.repo.On("IsSessionRevoked", suite.ctx, principal.SessionID).Return(false, nil)
+	gateway := model.ClientCredentials{ID: "gateway", Secret: "gateway-secret"}
+
+	result, err := suite.exchange(gateway, token, "orders", "orders:read", "payments:read")
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), result.ExpiresAt.Before(time.Now().Add(5*time.Minute+time.Second)))
+
+	delegated, err := suite.tokens.VerifyToken(suite.ctx, result.AccessToken)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(7), delegated.UserID)
+	assert.Equal(suite.T(), "orders", delegated.Audience)
+	assert.Equal(suite.T(), []string{"orders:read", "payments:read"}, delegated.Scopes)
+	assert.Equal(suite.T(), &model.Actor{Subject: "gateway"}, delegated.Actor)
+	// 沿用原会话，注销时一并失效
+	assert.Equal(suite.T(), principal.SessionID, delegated.SessionID)
+	assert.NotEqual(suite.T(), principal.TokenID, delegated.TokenID)
+
+	// 交换出的令牌不能访问本服务
+	_, err = suite.sessions.VerifyToken(suite.ctx, result.AccessToken)
+	assert.ErrorIs(suite.T(), err, errInvalidToken)
+
+	// orders 继续代表用户调用 payments，委托链逐级记录
+	result, err = suite.exchange(suite.client, result.AccessToken, "payments", "payments:read")
+	assert.NoError(suite.T(), err)
+	chained, err := suite.tokens.VerifyToken(suite.ctx, result.AccessToken)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), &model.Actor{Subject: "orders", Actor: &model.Actor{Subject: "gateway"}}, chained.Actor)
+}
+
+func (suite *SessionManagerTestSuite) TestExchangeToken_Rejected() {
+	token, principal := suite.issue()
+	suite.repo.On("IsSessionRevoked", suite.ctx, principal.SessionID).Return(false, nil)
+	gateway := model.ClientCredentials{ID: "gateway", Secret: "gateway-secret"}
+
+	_, err := suite.exchange(model.ClientCredentials{ID: "gateway", Secret: "wrong"}, token, "orders", "orders:read")
+	assert.Equal(suite.T(), connect.CodeUnauthenticated, connect.CodeOf(err))
+
+	_, err = suite.exchange(gateway, token, "payments", "payments:read")
+	assert.ErrorIs(suite.T(), err, model.ErrInvalidTarget)
+
+	_, err = suite.exchange(gateway, token, "orders")
+	assert.ErrorIs(suite.T(), err, model.ErrInvalidScope)
+
+	_, err = suite.exchange(gateway, token, "orders", "admin")
+	assert.ErrorIs(suite.T(), err, model.ErrInvalidScope)
+
+	_, err = suite.exchange(gateway, "not-a-token", "orders", "orders:read")
+	assert.ErrorIs(suite.T(), err, model.ErrInvalidGrant)
+
+	_, err = suite.sessions.ExchangeToken(suite.ctx, gateway, &model.TokenExchangeRequest{
+		SubjectToken:     token,
+		SubjectTokenType: "urn:ietf:params:oauth:token-type:id_token",
+		Audience:         "orders",
+		Scopes:           []string{"orders:read"},
+	})
+	assert.Equal(suite.T(), connect.CodeInvalidArgument, connect.CodeOf(err))
+
+	// scope 不能超出原令牌
+	result, err := suite.exchange(gateway, token, "orders", "payments:read")
+	assert.NoError(suite.T(), err)
+	_, err = suite.exchange(gateway, result.AccessToken, "orders", "orders:read")
+	assert.ErrorIs(suite.T(), err, model.ErrInvalidScope)
+}
+
+func (suite *SessionManagerTestSuite) TestExchangeToken_Bound() {
+	token, err := suite.tokens.IssueBound(2, 7, "testuser", "jkt-1")
+	assert.NoError(suite.T(), err)
+	principal, err := suite.tokens.VerifyToken(suite.ctx, token)
+	assert.NoError(suite.T(), err)
+	suite.repo.On("IsSessionRevoked", mock.Anything, principal.SessionID).Return(false, nil)
+	gateway := model.ClientCredentials{ID: "gateway", Secret: "gateway-secret"}
+	req := &model.TokenExchangeRequest{
+		SubjectToken:     token,
+		SubjectTokenType: model.TokenTypeAccessToken,
+		Audience:         "orders",
+		Scopes:           []string{"orders:read"},
+	}
+
+	// 没有证明或证明来自其他密钥时拒绝
+	_, err = suite.sessions.ExchangeToken(suite.ctx, gateway, req)
+	assert.ErrorIs(suite.T(), err, model.ErrInvalidDPoPProof)
+	_, err = suite.sessions.ExchangeToken(model.NewProofKeyContext(suite.ctx, "jkt-2"), gateway, req)
+	assert.ErrorIs(suite.T(), err, model.ErrInvalidDPoPProof)
+
+	result, err := suite.sessions.ExchangeToken(model.NewProofKeyContext(suite.ctx, "jkt-1"), gateway, req)
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), result.AccessToken)
+}
+
+func (suite *SessionManagerTestSuite) TestRefreshToken() {
+	_, principal := suite.issue()
+
+	result, err := suite.sessions.RefreshToken(suite.ctx, principal)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.TokenTypeBearer, result.TokenType)
+
+	// 沿用原会话
+	refreshed, err := suite.tokens.VerifyToken(suite.ctx, result.AccessToken)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), principal.SessionID, refreshed.SessionID)
+	assert.NotEqual(suite.T(), principal.TokenID, refreshed.TokenID)
+	assert.Empty(suite.T(), refreshed.ProofKey)
+	suite.repo.AssertNotCalled(suite.T(), "RevokeSession", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *SessionManagerTestSuite) TestRefreshToken_Bind() {
+	_, principal := suite.issue()
+	suite.repo.On("RevokeSession", mock.Anything, principal.SessionID, 24*time.Hour).Return(nil)
+	ctx := model.NewProofKeyContext(suite.ctx, "jkt-1")
+
+	result, err := suite.sessions.RefreshToken(ctx, principal)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.TokenTypeDPoP, result.TokenType)
+
+	// 首次绑定换成新会话，旧会话中的 Bearer 令牌失效
+	bound, err := suite.tokens.VerifyToken(suite.ctx, result.AccessToken)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "jkt-1", bound.ProofKey)
+	assert.NotEqual(suite.T(), principal.SessionID, bound.SessionID)
+	suite.repo.AssertExpectations(suite.T())
+
+	// 已绑定的令牌刷新后保持绑定
+	result, err = suite.sessions.RefreshToken(ctx, bound)
+	assert.NoError(suite.T(), err)
+	refreshed, err := suite.tokens.VerifyToken(suite.ctx, result.AccessToken)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "jkt-1", refreshed.ProofKey)
+	assert.Equal(suite.T(), bound.SessionID, refreshed.SessionID)
+	suite.repo.AssertNumberOfCalls(suite.T(), "RevokeSession", 1)
+}
+
+func (suite *SessionManagerTestSuite) TestRevokeSession() {
+	token, principal := suite.issue()
+	suite.repo.On("IsSessionRevoked", suite.ctx, principal.SessionID).Return(false, nil).Once()
+	suite.repo.On("RevokeSession", suite.ctx, principal.SessionID, mock.AnythingOfType("time.Duration")).Return(nil)
+	suite.repo.On("IsSessionRevoked", suite.ctx, principal.SessionID).Return(true, nil).Once()
+
+	_, err := suite.sessions.VerifyToken(suite.ctx, token)
+	assert.NoError(suite.T(), err)
+
+	assert.NoError(suite.T(), suite.sessions.RevokeSession(suite.ctx, principal))
+
+	// 注销后本实例的缓存立即失效
+	_, err = suite.sessions.VerifyToken(suite.ctx, token)
+	assert.ErrorIs(suite.T(), err, errInvalidToken)
+	suite.repo.AssertExpectations(suite.T())
+}
+
+func (suite *SessionManagerTestSuite) TestRevokeSession_NoSession() {
+	err := suite.sessions.RevokeSession(suite.ctx, &model.Principal{UserID: 7})
+
+	assert.Equal(suite.T(), connect.CodeFailedPrecondition, connect.CodeOf(err))
+	suite.repo.AssertNotCalled(suite.T(), "RevokeSession", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSessionManagerTestSuite(t *testing.T) {
+	suite.Run(t, new(SessionManagerTestSuite))
+}
@@ -0,0 +1,112 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data"
+
+	"connectrpc.com/connect"
+	"go.uber.org/zap"
+)
+
+// tenantCacheTTL 租户很少变化，缓存一段时间避免每个请求都查询数据库
+const tenantCacheTTL = time.Minute
+
+type TenantUseCase struct {
+	repo data.TenantRepo
+	cfg  *conf.Tenancy
+	l    *zap.Logger
+
+	mu    sync.RWMutex
+	cache map[string]cachedTenant
+}
+
+type cachedTenant struct {
+	tenant    *model.Tenant
+	expiresAt time.Time
+}
+
+func NewTenantUseCase(repo data.TenantRepo, cfg *conf.Bootstrap, logger *zap.Logger) (model.TenantUseCase, error) {
+	tenancy := cfg.Tenancy
+	if tenancy == nil {
+		tenancy = &conf.Tenancy{}
+	}
+
+	return &TenantUseCase{
+		repo:  repo,
+		cfg:   tenancy,
+		l:     logger,
+		cache: make(map[string]cachedTenant),
+	}, nil
+}
+
+func (uc *TenantUseCase) ResolveTenant(ctx context.Context, hint model.TenantHint) (*model.Tenant, error) {
+	switch {
+	case hint.Slug != "":
+		return uc.tenantBySlug(ctx, hint.Slug)
+	case hint.ClientID != "":
+		return uc.cached("client:"+hint.ClientID, func() (*model.Tenant, error) {
+			return uc.repo.GetTenantByClientID(ctx, hint.ClientID)
+		})
+	}
+
+	if slug := uc.subdomain(hint.Host); slug != "" {
+		return uc.tenantBySlug(ctx, slug)
+	}
+	if uc.cfg.DefaultTenant != "" {
+		return uc.tenantBySlug(ctx, uc.cfg.DefaultTenant)
+	}
+	return nil, nil
+}
+
+func (uc *TenantUseCase) tenantBySlug(ctx context.Context, slug string) (*model.Tenant, error) {
+	slug = strings.ToLower(slug)
+	return uc.cached("slug:"+slug, func() (*model.Tenant, error) {
+		return uc.repo.GetTenantBySlug(ctx, slug)
+	})
+}
+
+func (uc *TenantUseCase) cached(key string, load func() (*model.Tenant, error)) (*model.Tenant, error) {
+	uc.mu.RLock()
+	entry, ok := uc.cache[key]
+	uc.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.tenant, nil
+	}
+
+	tenant, err := load()
+	if err != nil {
+		if errors.Is(err, model.ErrTenantNotFound) {
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		}
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	uc.mu.Lock()
+	uc.cache[key] = cachedTenant{tenant: tenant, expiresAt: time.Now().Add(tenantCacheTTL)}
+	uc.mu.Unlock()
+	return tenant, nil
+}
+
+// subdomain 从 <tenant>.<base_domain> 形式的主机名中取出租户标识
+func (uc *TenantUseCase) subdomain(host string) string {
+	if uc.cfg.BaseDomain == "" || host == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	label, ok := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(uc.cfg.BaseDomain))
+	if !ok || label == "" || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
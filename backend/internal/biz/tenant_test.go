@@ -0,0 +1,122 @@
+package biz
+
+import (
+	"context"
+	"testing"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+// MockTenantRepo 是 TenantRepo 的模拟实现
+type MockTenantRepo struct {
+	mock.Mock
+}
+
+func (m *MockTenantRepo) GetTenantBySlug(ctx context.Context, slug string) (*model.Tenant, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Tenant), args.Error(1)
+}
+
+func (m *MockTenantRepo) GetTenantByClientID(ctx context.Context, clientID string) (*model.Tenant, error) {
+	args := m.Called(ctx, clientID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Tenant), args.Error(1)
+}
+
+// TenantUseCaseTestSuite 是 TenantUseCase 的测试套件
+type TenantUseCaseTestSuite struct {
+	suite.Suite
+	repo    *MockTenantRepo
+	useCase *TenantUseCase
+}
+
+func (suite *TenantUseCaseTestSuite) SetupTest() {
+	suite.repo = new(MockTenantRepo)
+	logger, _ := zap.NewDevelopment()
+
+	useCase, err := NewTenantUseCase(suite.repo, &conf.Bootstrap{
+		Tenancy: &conf.Tenancy{BaseDomain: "example.com"},
+	}, logger)
+	assert.NoError(suite.T(), err)
+	suite.useCase = useCase.(*TenantUseCase)
+}
+
+func (suite *TenantUseCaseTestSuite) TestResolveTenant_HeaderFirst() {
+	ctx := context.Background()
+	acme := &model.Tenant{ID: 2, Slug: "acme"}
+	suite.repo.On("GetTenantBySlug", ctx, "acme").Return(acme, nil).Once()
+
+	tenant, err := suite.useCase.ResolveTenant(ctx, model.TenantHint{Slug: "ACME", ClientID: "desktop", Host: "globex.example.com"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), acme, tenant)
+
+	// 第二次命中缓存
+	tenant, err = suite.useCase.ResolveTenant(ctx, model.TenantHint{Slug: "acme"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), acme, tenant)
+	suite.repo.AssertNumberOfCalls(suite.T(), "GetTenantBySlug", 1)
+	suite.repo.AssertNotCalled(suite.T(), "GetTenantByClientID", mock.Anything, mock.Anything)
+}
+
+func (suite *TenantUseCaseTestSuite) TestResolveTenant_ClientID() {
+	ctx := context.Background()
+	suite.repo.On("GetTenantByClientID", ctx, "desktop").Return(&model.Tenant{ID: 3, Slug: "globex"}, nil)
+
+	tenant, err := suite.useCase.ResolveTenant(ctx, model.TenantHint{ClientID: "desktop"})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(3), tenant.ID)
+}
+
+func (suite *TenantUseCaseTestSuite) TestResolveTenant_Subdomain() {
+	ctx := context.Background()
+	suite.repo.On("GetTenantBySlug", ctx, "globex").Return(&model.Tenant{ID: 3, Slug: "globex"}, nil)
+
+	tenant, err := suite.useCase.ResolveTenant(ctx, model.TenantHint{Host: "globex.example.com:443"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(3), tenant.ID)
+
+	// 多级子域名和其他域名不识别
+	tenant, err = suite.useCase.ResolveTenant(ctx, model.TenantHint{Host: "a.globex.example.com"})
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), tenant)
+	tenant, err = suite.useCase.ResolveTenant(ctx, model.TenantHint{Host: "globex.example.org"})
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), tenant)
+}
+
+func (suite *TenantUseCaseTestSuite) TestResolveTenant_Default() {
+	ctx := context.Background()
+	suite.useCase.cfg.DefaultTenant = "default"
+	suite.repo.On("GetTenantBySlug", ctx, "default").Return(&model.Tenant{ID: 1, Slug: "default"}, nil)
+
+	tenant, err := suite.useCase.ResolveTenant(ctx, model.TenantHint{})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(1), tenant.ID)
+}
+
+func (suite *TenantUseCaseTestSuite) TestResolveTenant_NotFound() {
+	ctx := context.Background()
+	suite.repo.On("GetTenantBySlug", ctx, "missing").Return(nil, model.ErrTenantNotFound)
+
+	_, err := suite.useCase.ResolveTenant(ctx, model.TenantHint{Slug: "missing"})
+
+	assert.Equal(suite.T(), connect.CodeNotFound, connect.CodeOf(err))
+}
+
+func TestTenantUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(TenantUseCaseTestSuite))
+}
@@ -0,0 +1,222 @@
+package biz
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var errInvalidToken = errors.New("invalid token")
+
+// TokenManager 负责签发和校验 JWT 访问令牌
+type TokenManager struct {
+	cfg    *conf.Auth
+	secret []byte
+}
+
+var _ model.TokenVerifier = (*TokenManager)(nil)
+
+func NewTokenManager(cfg *conf.Bootstrap, logger *zap.Logger) (*TokenManager, error) {
+	var secret []byte
+	if cfg.Auth.JwtSecret != "" {
+		secret = []byte(cfg.Auth.JwtSecret)
+	} else {
+		// 生成默认密钥
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("generate jwt secret failed: %v", err)
+		}
+		logger.Warn("WARNING: Using auto-generated JWT secret, set auth.jwt_secret in config for production")
+	}
+
+	return &TokenManager{
+		cfg:    cfg.Auth,
+		secret: secret,
+	}, nil
+}
+
+// Issue 为租户内的用户签发访问令牌
+func (m *TokenManager) Issue(tenantID, userID int64, username string) (string, error) {
+	return m.IssueBound(tenantID, userID, username, "")
+}
+
+// IssueBound 签发绑定到 DPoP 密钥的访问令牌，jkt 为空时与 Issue 相同
+func (m *TokenManager) IssueBound(tenantID, userID int64, username, jkt string) (string, error) {
+	// 每次登录是一个新会话，注销时按 sid 吊销
+	token, _, err := m.Reissue(&model.Principal{
+		TenantID:  tenantID,
+		UserID:    userID,
+		Username:  username,
+		SessionID: uuid.NewString(),
+		ProofKey:  jkt,
+	})
+	return token, err
+}
+
+// Reissue 在 principal 所在的会话中签发新令牌，保留密钥绑定
+func (m *TokenManager) Reissue(p *model.Principal) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(m.lifetime())
+	claims := jwt.MapClaims{
+		"jti": uuid.NewString(),
+		"sub": p.UserID,
+		"tid": p.TenantID,
+		"usr": p.Username,
+		"iat": now.Unix(),
+		"exp": expiresAt.Unix(),
+	}
+	if p.SessionID != "" {
+		claims["sid"] = p.SessionID
+	}
+	if p.ProofKey != "" {
+		claims["cnf"] = map[string]any{"jkt": p.ProofKey}
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, time.Unix(expiresAt.Unix(), 0), nil
+}
+
+// IssueDelegated 代表 subject 签发只能用于 audience 的令牌，沿用原会话，注销时一并失效
+func (m *TokenManager) IssueDelegated(subject *model.Principal, audience string, scopes []string, actor *model.Actor, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	if !subject.ExpiresAt.IsZero() && subject.ExpiresAt.Before(expiresAt) {
+		expiresAt = subject.ExpiresAt
+	}
+
+	claims := jwt.MapClaims{
+		"jti":   uuid.NewString(),
+		"sub":   subject.UserID,
+		"tid":   subject.TenantID,
+		"usr":   subject.Username,
+		"aud":   audience,
+		"scope": strings.Join(scopes, " "),
+		"iat":   now.Unix(),
+		"exp":   expiresAt.Unix(),
+	}
+	if subject.SessionID != "" {
+		claims["sid"] = subject.SessionID
+	}
+	if actor != nil {
+		claims["act"] = actorClaim(actor)
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, time.Unix(expiresAt.Unix(), 0), nil
+}
+
+func (m *TokenManager) VerifyToken(_ context.Context, tokenStr string) (*model.Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+		return m.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, errInvalidToken
+	}
+
+	sub, ok := claims["sub"].(float64)
+	if !ok {
+		return nil, errInvalidToken
+	}
+	// 不带租户的令牌不能访问任何租户的数据
+	tid, ok := claims["tid"].(float64)
+	if !ok {
+		return nil, errInvalidToken
+	}
+	username, _ := claims["usr"].(string)
+	tokenID, _ := claims["jti"].(string)
+	sessionID, _ := claims["sid"].(string)
+	scope, _ := claims["scope"].(string)
+	audience, _ := claims["aud"].(string)
+	var proofKey string
+	if cnf, ok := claims["cnf"].(map[string]any); ok {
+		proofKey, _ = cnf["jkt"].(string)
+	}
+	issuedAt, _ := claims.GetIssuedAt()
+	expiresAt, _ := claims.GetExpirationTime()
+
+	p := &model.Principal{
+		TenantID:  int64(tid),
+		UserID:    int64(sub),
+		Username:  username,
+		TokenID:   tokenID,
+		SessionID: sessionID,
+		Scopes:    strings.Fields(scope),
+		Audience:  audience,
+		Actor:     parseActorClaim(claims["act"]),
+		ProofKey:  proofKey,
+	}
+	if issuedAt != nil {
+		p.IssuedAt = issuedAt.Time
+	}
+	if expiresAt != nil {
+		p.ExpiresAt = expiresAt.Time
+	}
+	return p, nil
+}
+
+// lifetime 访问令牌的有效期
+func (m *TokenManager) lifetime() time.Duration {
+	expireHours := m.cfg.JwtExpireHours
+	if expireHours == 0 {
+		expireHours = 24 // 默认24小时
+	}
+	return time.Duration(expireHours) * time.Hour
+}
+
+// actorClaim 把委托链转换为 RFC 8693 的 act 声明
+func actorClaim(actor *model.Actor) map[string]any {
+	if actor == nil {
+		return nil
+	}
+	claim := map[string]any{"sub": actor.Subject}
+	if actor.Actor != nil {
+		claim["act"] = actorClaim(actor.Actor)
+	}
+	return claim
+}
+
+func parseActorClaim(v any) *model.Actor {
+	claim, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+	sub, _ := claim["sub"].(string)
+	return &model.Actor{Subject: sub, Actor: parseActorClaim(claim["act"])}
+}
+
+// sign 使用令牌密钥对数据签名，purpose 区分不同用途，避免签名被挪用
+func (m *TokenManager) sign(purpose, data string) string {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(purpose))
+	mac.Write([]byte{0})
+	mac.Write([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (m *TokenManager) verifySignature(purpose, data, signature string) bool {
+	return hmac.Equal([]byte(m.sign(purpose, data)), []byte(signature))
+}
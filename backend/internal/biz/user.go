@@ -8,6 +8,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"connect-go-example/internal/biz/model"
@@ -15,61 +16,169 @@ import (
 	"connect-go-example/internal/data"
 
 	"connectrpc.com/connect"
-	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
 )
 
 type UserUseCase struct {
-	repo   data.UserRepo
-	cfg    *conf.Auth
-	secret []byte
+	repo         data.UserRepo
+	authRequests data.AuthRequestRepo
+	invites      data.InviteRepo
+	tx           data.Transactor
+	tokens       *TokenManager
+	hasher       *CredentialHasher
+	local        CredentialVerifier
+	backends     *CredentialBackends
+	pow          *ProofOfWork
+	risk         *LoginRisk
+	events       *DomainEvents
+	registration *registrationPolicy
+	kdf          *kdfPolicy
+	cfg          *conf.Auth
+	l            *zap.Logger
 }
 
-func NewUserUseCase(repo data.UserRepo, cfg *conf.Bootstrap, logger *zap.Logger) (model.UserUseCase, error) {
-	var secret []byte
-	if cfg.Auth.JwtSecret != "" {
-		secret = []byte(cfg.Auth.JwtSecret)
-	} else {
-		// 生成默认密钥
-		secret = make([]byte, 32)
-		if _, err := rand.Read(secret); err != nil {
-			return nil, fmt.Errorf("generate jwt secret failed: %v", err)
-		}
-		logger.Warn("WARNING: Using auto-generated JWT secret, set auth.jwt_secret in config for production")
+func NewUserUseCase(repo data.UserRepo, authRequests data.AuthRequestRepo, invites data.InviteRepo, tx data.Transactor, tokens *TokenManager, hasher *CredentialHasher, backends *CredentialBackends, pow *ProofOfWork, risk *LoginRisk, events *DomainEvents, cfg *conf.Bootstrap, logger *zap.Logger) (model.UserUseCase, error) {
+	registration, err := newRegistrationPolicy(cfg.Registration)
+	if err != nil {
+		return nil, err
+	}
+	kdf, err := newKdfPolicy(cfg.ClientKdf)
+	if err != nil {
+		return nil, err
 	}
 
 	return &UserUseCase{
-		repo:   repo,
-		cfg:    cfg.Auth,
-		secret: secret,
+		repo:         repo,
+		authRequests: authRequests,
+		invites:      invites,
+		tx:           tx,
+		tokens:       tokens,
+		hasher:       hasher,
+		local:        &passwordHashVerifier{repo: repo, tx: tx, hasher: hasher, kdf: kdf, tokens: tokens, events: events, l: logger},
+		backends:     backends,
+		pow:          pow,
+		risk:         risk,
+		events:       events,
+		registration: registration,
+		kdf:          kdf,
+		cfg:          cfg.Auth,
+		l:            logger,
 	}, nil
 }
 
-func (uc *UserUseCase) Register(ctx context.Context, username, passwordHash, email, salt string) (string, error) {
+func (uc *UserUseCase) GetRegistrationParams(ctx context.Context) (*model.KdfTicket, error) {
+	ticket, err := uc.kdf.issue(ctx, uc.tokens)
+	if err != nil {
+		if errors.Is(err, model.ErrTenantRequired) {
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	return ticket, nil
+}
+
+func (uc *UserUseCase) Register(ctx context.Context, req *model.RegisterRequest) (string, error) {
+	// 校验工作量证明
+	if err := uc.pow.verify(ctx, model.PowActionRegister, req.Pow); err != nil {
+		return "", err
+	}
+
+	// 盐和 KDF 参数以服务端签发的票据为准，未携带票据时沿用客户端生成的盐
+	salt, kdfVersion := req.Salt, int32(0)
+	if req.KdfTicket != "" {
+		params, ok := uc.kdf.parse(ctx, uc.tokens, req.KdfTicket)
+		if !ok {
+			return "", connect.NewError(connect.CodeInvalidArgument, errors.New("invalid or expired kdf ticket"))
+		}
+		salt, kdfVersion = params.Salt, params.Version
+	} else if uc.kdf.requireTicket {
+		return "", connect.NewError(connect.CodeFailedPrecondition, errors.New("kdf ticket required, call GetRegistrationParams first"))
+	}
+
+	// 邮箱统一小写，与免密登录的查询保持一致
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	// 检查注册模式
+	inviteID, err := uc.registration.check(ctx, uc.tokens, email, req.InviteCode, req.EmailTicket)
+	if err != nil {
+		return "", err
+	}
+
 	// 检查用户是否已存在
-	existingUser, err := uc.repo.GetUserByName(ctx, username)
+	existingUser, err := uc.repo.GetUserByName(ctx, req.Username)
 	if err == nil && existingUser != nil {
 		return "", connect.NewError(connect.CodeAlreadyExists, errors.New("user already exists"))
 	}
 
-	// 创建用户
-	userID, err := uc.repo.CreateUser(ctx, &model.User{
-		Username:     username,
-		PasswordHash: passwordHash,
-		Email:        email,
-		Salt:         salt,
-	})
+	// 客户端凭证在服务端再做一次哈希后保存，哈希较慢，在事务外计算
+	storedHash, err := uc.hasher.Hash(req.PasswordHash)
 	if err != nil {
 		return "", connect.NewError(connect.CodeInternal, err)
 	}
 
+	// 占用邀请码、创建用户、记录受邀用户和写入注册事件在同一事务中，任一步失败都不会消耗邀请码
+	tenantID, _ := model.TenantIDFromContext(ctx)
+	var userID int64
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// 先占用邀请码再创建用户，并发注册不会超出可用次数
+		if inviteID != 0 {
+			if _, err := uc.invites.RedeemInvite(ctx, inviteID); err != nil {
+				if errors.Is(err, model.ErrInviteUnavailable) {
+					return connect.NewError(connect.CodePermissionDenied, errInvalidInvite)
+				}
+				return connect.NewError(connect.CodeInternal, err)
+			}
+		}
+
+		// 创建用户
+		id, err := uc.repo.CreateUser(ctx, &model.User{
+			Username:     req.Username,
+			PasswordHash: storedHash,
+			Email:        email,
+			Salt:         salt,
+			KdfVersion:   kdfVersion,
+			// 凭邮件中的票据注册，已证明能收到该邮箱的邮件
+			EmailVerified: email != "" && verifyEmailTicket(ctx, uc.tokens, email, req.EmailTicket),
+		})
+		if err != nil {
+			return connect.NewError(connect.CodeInternal, err)
+		}
+		userID = id
+
+		if inviteID != 0 {
+			if err := uc.invites.CreateInviteRedemption(ctx, inviteID, userID); err != nil {
+				return connect.NewError(connect.CodeInternal, fmt.Errorf("record invite redemption failed: %v", err))
+			}
+		}
+
+		if err := uc.events.recordUser(ctx, model.EventUserRegistered, &model.UserEvent{
+			TenantID: tenantID,
+			UserID:   userID,
+			Username: req.Username,
+			Source:   model.EventSourcePassword,
+		}); err != nil {
+			return connect.NewError(connect.CodeInternal, fmt.Errorf("record user registered event failed: %v", err))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	uc.pow.record(ctx, model.PowEventRegistration)
+
 	return fmt.Sprintf("%d", userID), nil
 }
 
-func (uc *UserUseCase) GetAuthChallenge(ctx context.Context, username string) (*model.AuthChallenge, error) {
-	// 获取用户信息
-	user, err := uc.repo.GetUserByName(ctx, username)
-	if err != nil {
+func (uc *UserUseCase) GetAuthChallenge(ctx context.Context, username string, pow *model.PowSolution) (*model.AuthChallenge, error) {
+	// 校验工作量证明
+	if err := uc.pow.verify(ctx, model.PowActionAuthChallenge, pow); err != nil {
+		return nil, err
+	}
+
+	// 获取用户信息，目录后端的用户首次登录时本地还不存在
+	user, verifier, err := uc.credentialVerifier(ctx, username)
+	if err != nil || (user == nil && verifier == uc.local) {
 		// 返回通用错误避免用户枚举
 		return nil, errors.New("authentication failed")
 	}
@@ -91,65 +200,131 @@ func (uc *UserUseCase) GetAuthChallenge(ctx context.Context, username string) (*
 		return nil, fmt.Errorf("store auth challenge failed: %v", err)
 	}
 
-	return &model.AuthChallenge{
-		Username:  username,
-		Challenge: challengeStr,
-		Salt:      user.Salt,
-	}, nil
+	// 目录后端校验密码原文，不需要 KDF 参数
+	if verifier.CredentialType() == model.CredentialTypePassword {
+		return &model.AuthChallenge{
+			Username:       username,
+			Challenge:      challengeStr,
+			CredentialType: model.CredentialTypePassword,
+		}, nil
+	}
+
+	kdf, err := uc.kdf.params(user.KdfVersion, user.Salt)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	result := &model.AuthChallenge{
+		Username:       username,
+		Challenge:      challengeStr,
+		Salt:           user.Salt,
+		Kdf:            kdf,
+		CredentialType: model.CredentialTypeDerived,
+	}
+
+	// 参数过时时附带新参数，客户端可在本次登录中一并提交新凭证
+	if user.KdfVersion < uc.kdf.current {
+		upgrade, err := uc.kdf.issue(ctx, uc.tokens)
+		if err != nil {
+			uc.l.Warn("issue kdf upgrade ticket failed", zap.Int64("user_id", user.ID), zap.Error(err))
+		} else {
+			result.Upgrade = upgrade
+		}
+	}
+	return result, nil
 }
 
-func (uc *UserUseCase) SubmitAuth(ctx context.Context, username, hashedCredential, authRequestID, challengeResponse string) (*model.AuthResult, error) {
+func (uc *UserUseCase) SubmitAuth(ctx context.Context, req *model.SubmitAuthRequest) (*model.AuthResult, error) {
+	username := req.Username
+
 	// 验证挑战响应
 	expectedChallenge, err := uc.repo.GetAuthChallenge(ctx, username)
 	if err != nil {
+		uc.pow.record(ctx, model.PowEventFailedLogin)
 		return nil, errors.New("invalid or expired challenge")
 	}
 
 	// 计算期望的挑战响应
 	expectedResponse := computeChallengeResponse(expectedChallenge, username)
-	if challengeResponse != expectedResponse {
+	if req.ChallengeResponse != expectedResponse {
+		uc.pow.record(ctx, model.PowEventFailedLogin)
 		return nil, errors.New("invalid challenge response")
 	}
 
-	// 获取用户信息
-	user, err := uc.repo.GetUserByName(ctx, username)
+	// 按用户或租户选择后端验证凭证
+	user, verifier, err := uc.credentialVerifier(ctx, username)
 	if err != nil {
+		uc.l.Error("select credential backend failed", zap.String("username", username), zap.Error(err))
+		uc.pow.record(ctx, model.PowEventFailedLogin)
 		return nil, errors.New("authentication failed")
 	}
-
-	// 验证凭证
-	if !constantTimeCompare(hashedCredential, user.PasswordHash) {
+	user, err = verifier.Verify(ctx, user, req)
+	if err != nil || user.Disabled {
+		uc.pow.record(ctx, model.PowEventFailedLogin)
+		// 后端不可用等错误已带有错误码
+		var connectErr *connect.Error
+		if errors.As(err, &connectErr) {
+			return nil, err
+		}
 		return nil, errors.New("authentication failed")
 	}
 
-	// 生成JWT令牌
-	token, err := uc.generateJWT(user.ID, username)
+	// 风险较高时先完成二次验证，登录请求在验证通过后再批准
+	assessment := uc.risk.assess(ctx, user, req.Client, req.DeviceID)
+	if uc.risk.requireStepUp(assessment) {
+		result, err := uc.risk.beginStepUp(ctx, user, assessment, req.AuthRequestID)
+		if err != nil || result != nil {
+			return result, err
+		}
+	}
+
+	// 生成JWT令牌，请求携带 DPoP 证明时绑定到该密钥
+	jkt := model.ProofKeyFromContext(ctx)
+	token, err := uc.generateJWT(user.TenantID, user.ID, username, jkt)
 	if err != nil {
 		return nil, fmt.Errorf("generate token failed: %v", err)
 	}
+	uc.risk.recordLogin(ctx, user, assessment, false)
+	source := model.EventSourcePassword
+	if verifier.CredentialType() == model.CredentialTypePassword {
+		source = model.EventSourceDirectory
+	}
+	uc.events.userLoggedIn(ctx, &model.UserEvent{
+		TenantID: user.TenantID,
+		UserID:   user.ID,
+		Username: user.Username,
+		Source:   source,
+	})
+
+	// 批准桌面端或 CLI 发起的登录请求，等待方会通过 WatchAuthRequest 收到令牌
+	if req.AuthRequestID != "" {
+		if requestToken, err := requesterToken(uc.tokens, token, jkt, user.TenantID, user.ID, username); err != nil {
+			uc.l.Warn("generate auth request token failed", zap.String("auth_request_id", req.AuthRequestID), zap.Error(err))
+		} else {
+			approveAuthRequest(ctx, uc.authRequests, uc.l, req.AuthRequestID, user.ID, requestToken)
+		}
+	}
 
 	return &model.AuthResult{
 		Code:      "success",
 		State:     "authenticated",
 		AuthToken: token,
+		TokenType: model.TokenType(jkt),
 	}, nil
 }
 
-func (uc *UserUseCase) generateJWT(userID int64, username string) (string, error) {
-	expireHours := uc.cfg.JwtExpireHours
-	if expireHours == 0 {
-		expireHours = 24 // 默认24小时
-	}
-
-	claims := jwt.MapClaims{
-		"sub": userID,
-		"usr": username,
-		"iat": time.Now().Unix(),
-		"exp": time.Now().Add(time.Duration(expireHours) * time.Hour).Unix(),
+// credentialVerifier 查找本地用户并选择校验凭证的后端，本地没有该用户时 user 为 nil
+func (uc *UserUseCase) credentialVerifier(ctx context.Context, username string) (*model.User, CredentialVerifier, error) {
+	user, err := uc.repo.GetUserByName(ctx, username)
+	if err != nil {
+		user = nil
 	}
+	tenantID, _ := model.TenantIDFromContext(ctx)
+	verifier, err := uc.backends.verifier(tenantID, user, uc.local)
+	return user, verifier, err
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(uc.secret)
+func (uc *UserUseCase) generateJWT(tenantID, userID int64, username, jkt string) (string, error) {
+	return uc.tokens.IssueBound(tenantID, userID, username, jkt)
 }
 
 func computeChallengeResponse(challenge, username string) string {
@@ -0,0 +1,217 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data"
+	"connect-go-example/internal/pkg/ldap"
+
+	"connectrpc.com/connect"
+	"go.uber.org/zap"
+)
+
+// localCredentialBackend 校验 users.password_hash 的后端标识
+const localCredentialBackend = "local"
+
+var errInvalidCredential = errors.New("invalid credential")
+
+// CredentialVerifier 校验登录时提交的凭证
+type CredentialVerifier interface {
+	// CredentialType 客户端应提交的凭证类型
+	CredentialType() string
+	// Verify 校验通过后返回登录的本地用户，user 为 nil 表示本地还没有该用户；凭证错误时返回 errInvalidCredential
+	Verify(ctx context.Context, user *model.User, req *model.SubmitAuthRequest) (*model.User, error)
+}
+
+// passwordHashVerifier 校验客户端派生的凭证与 users.password_hash，并在登录成功后升级凭证
+type passwordHashVerifier struct {
+	repo   data.UserRepo
+	tx     data.Transactor
+	hasher *CredentialHasher
+	kdf    *kdfPolicy
+	tokens *TokenManager
+	events *DomainEvents
+	l      *zap.Logger
+}
+
+func (v *passwordHashVerifier) CredentialType() string {
+	return model.CredentialTypeDerived
+}
+
+func (v *passwordHashVerifier) Verify(ctx context.Context, user *model.User, req *model.SubmitAuthRequest) (*model.User, error) {
+	if user == nil {
+		v.hasher.VerifyDummy(req.HashedCredential)
+		return nil, errInvalidCredential
+	}
+
+	ok, needsRehash := v.hasher.Verify(req.HashedCredential, user.PasswordHash)
+	if !ok {
+		return nil, errInvalidCredential
+	}
+	// 客户端按新参数重新派生了凭证，或服务端哈希需要升级，失败不影响本次登录；停用的用户由调用方拒绝
+	if !user.Disabled && !v.upgradeKdf(ctx, user, req) && needsRehash {
+		v.updateCredential(ctx, user, req.HashedCredential, user.Salt, user.KdfVersion, model.EventSourceRehash)
+	}
+	return user, nil
+}
+
+// upgradeKdf 保存按新 KDF 参数派生的凭证，票据无效时忽略
+func (v *passwordHashVerifier) upgradeKdf(ctx context.Context, user *model.User, req *model.SubmitAuthRequest) bool {
+	if req.UpgradeCredential == "" {
+		return false
+	}
+	params, ok := v.kdf.parse(ctx, v.tokens, req.UpgradeTicket)
+	if !ok || params.Version <= user.KdfVersion {
+		v.l.Warn("ignore kdf upgrade with invalid ticket", zap.Int64("user_id", user.ID))
+		return false
+	}
+	return v.updateCredential(ctx, user, req.UpgradeCredential, params.Salt, params.Version, model.EventSourceKdfUpgrade)
+}
+
+// updateCredential 保存新凭证并在同一事务中写入 PasswordChanged 事件，source 说明凭证更新的原因
+func (v *passwordHashVerifier) updateCredential(ctx context.Context, user *model.User, credential, salt string, kdfVersion int32, source string) bool {
+	storedHash, err := v.hasher.Hash(credential)
+	if err == nil {
+		err = v.tx.WithinTx(ctx, func(ctx context.Context) error {
+			if err := v.repo.UpdateCredential(ctx, &model.User{
+				ID:           user.ID,
+				Username:     user.Username,
+				PasswordHash: storedHash,
+				Salt:         salt,
+				KdfVersion:   kdfVersion,
+			}); err != nil {
+				return err
+			}
+			return v.events.recordUser(ctx, model.EventPasswordChanged, &model.UserEvent{
+				TenantID: user.TenantID,
+				UserID:   user.ID,
+				Username: user.Username,
+				Source:   source,
+			})
+		})
+	}
+	if err != nil {
+		v.l.Warn("update credential failed", zap.Int64("user_id", user.ID), zap.Error(err))
+		return false
+	}
+	v.l.Info("credential upgraded", zap.Int64("user_id", user.ID), zap.Int32("kdf_version", kdfVersion))
+	return true
+}
+
+// directoryVerifier 以用户名和密码原文绑定 LDAP 目录，成功后把属性同步到本地用户
+type directoryVerifier struct {
+	id        string
+	directory *ldap.Directory
+	repo      data.UserRepo
+	l         *zap.Logger
+}
+
+func (v *directoryVerifier) CredentialType() string {
+	return model.CredentialTypePassword
+}
+
+func (v *directoryVerifier) Verify(ctx context.Context, _ *model.User, req *model.SubmitAuthRequest) (*model.User, error) {
+	entry, err := v.directory.Authenticate(req.Username, req.Password)
+	if err != nil {
+		if errors.Is(err, ldap.ErrInvalidCredentials) {
+			return nil, errInvalidCredential
+		}
+		v.l.Error("Directory authentication failed", zap.String("backend", v.id), zap.Error(err))
+		return nil, connect.NewError(connect.CodeUnavailable, errors.New("credential backend is unavailable"))
+	}
+
+	directoryUser := &model.DirectoryUser{
+		Username:    req.Username,
+		Email:       entry.Email,
+		DisplayName: entry.DisplayName,
+		GivenName:   entry.GivenName,
+		FamilyName:  entry.FamilyName,
+		Backend:     v.id,
+	}
+	user, err := v.repo.SyncDirectoryUser(ctx, directoryUser)
+	if errors.Is(err, model.ErrUserConflict) && directoryUser.Email != "" {
+		// 可能是邮箱已被其他本地用户占用，保留原邮箱，其余属性照常同步
+		v.l.Warn("Directory email conflicts with another user", zap.String("backend", v.id), zap.String("username", req.Username))
+		directoryUser.Email = ""
+		user, err = v.repo.SyncDirectoryUser(ctx, directoryUser)
+	}
+	if errors.Is(err, model.ErrUserConflict) {
+		// 同名用户属于其他后端，目录登录不能接管，需要管理员指定该用户的后端
+		v.l.Warn("Directory user conflicts with a user of another credential backend", zap.String("backend", v.id), zap.String("username", req.Username))
+		return nil, errInvalidCredential
+	}
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("sync directory user failed: %v", err))
+	}
+	return user, nil
+}
+
+// CredentialBackends 已配置的凭证校验后端，零值表示所有用户都使用本地凭证
+type CredentialBackends struct {
+	backends map[string]CredentialVerifier
+	tenants  map[int64]string
+}
+
+func NewCredentialBackends(repo data.UserRepo, cfg *conf.Bootstrap, logger *zap.Logger) (*CredentialBackends, error) {
+	b := &CredentialBackends{
+		backends: make(map[string]CredentialVerifier),
+		tenants:  make(map[int64]string),
+	}
+	if cfg.Credential == nil {
+		return b, nil
+	}
+
+	for _, backend := range cfg.Credential.Backends {
+		if backend.Id == "" || backend.Id == localCredentialBackend {
+			return nil, fmt.Errorf("invalid credential backend id %q", backend.Id)
+		}
+		if _, ok := b.backends[backend.Id]; ok {
+			return nil, fmt.Errorf("duplicate credential backend %q", backend.Id)
+		}
+		if backend.Ldap == nil {
+			return nil, fmt.Errorf("credential backend %q requires ldap", backend.Id)
+		}
+		directory, err := ldap.NewDirectory(backend.Ldap)
+		if err != nil {
+			return nil, fmt.Errorf("credential backend %q: %v", backend.Id, err)
+		}
+		b.backends[backend.Id] = &directoryVerifier{id: backend.Id, directory: directory, repo: repo, l: logger}
+	}
+	for _, tenant := range cfg.Credential.Tenants {
+		if _, ok := b.backends[tenant.Backend]; !ok && tenant.Backend != localCredentialBackend {
+			return nil, fmt.Errorf("tenant %d uses unknown credential backend %q", tenant.TenantId, tenant.Backend)
+		}
+		b.tenants[tenant.TenantId] = tenant.Backend
+	}
+	return b, nil
+}
+
+// valid id 为空（租户默认后端）、local 或已配置的后端
+func (b *CredentialBackends) valid(id string) bool {
+	if id == "" || id == localCredentialBackend {
+		return true
+	}
+	_, ok := b.backends[id]
+	return ok
+}
+
+// verifier 用户指定的后端优先，其次是租户默认后端，都没有时使用 local
+func (b *CredentialBackends) verifier(tenantID int64, user *model.User, local CredentialVerifier) (CredentialVerifier, error) {
+	id := b.tenants[tenantID]
+	if user != nil && user.CredentialBackend != "" {
+		id = user.CredentialBackend
+	}
+	if id == "" || id == localCredentialBackend {
+		return local, nil
+	}
+
+	v, ok := b.backends[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown credential backend %q", id)
+	}
+	return v, nil
+}
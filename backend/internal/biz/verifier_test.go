@@ -0,0 +1,218 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/pkg/ldap/ldaptest"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+const directoryServiceDN = "cn=svc,dc=example,dc=com"
+
+// CredentialVerifierTestSuite 租户 2 使用 LDAP 后端 corp
+type CredentialVerifierTestSuite struct {
+	suite.Suite
+	directory *ldaptest.Server
+	userRepo  *MockUserRepo
+	backends  *CredentialBackends
+	useCase   *UserUseCase
+	ctx       context.Context
+}
+
+func (suite *CredentialVerifierTestSuite) SetupTest() {
+	suite.directory = ldaptest.NewServer(
+		&ldaptest.Entry{DN: directoryServiceDN, Password: "svc-secret"},
+		&ldaptest.Entry{
+			DN:       "uid=alice,ou=people,dc=example,dc=com",
+			Password: "wonderland",
+			Attributes: map[string][]string{
+				"uid":         {"alice"},
+				"mail":        {"alice@example.com"},
+				"displayName": {"Alice Liddell"},
+				"givenName":   {"Alice"},
+				"sn":          {"Liddell"},
+			},
+		},
+	)
+	suite.T().Cleanup(suite.directory.Close)
+	suite.userRepo = new(MockUserRepo)
+	suite.ctx = model.NewTenantContext(context.Background(), &model.Tenant{ID: 2})
+	logger := zap.NewNop()
+
+	cfg := &conf.Bootstrap{
+		Auth: &conf.Auth{JwtSecret: "test-secret-key-12345678901234567890"},
+		Credential: &conf.Credential{
+			Backends: []*conf.Credential_Backend{{
+				Id: "corp",
+				Ldap: &conf.Credential_Ldap{
+					Url:          suite.directory.URL,
+					BindDn:       directoryServiceDN,
+					BindPassword: "svc-secret",
+					BaseDn:       "dc=example,dc=com",
+				},
+			}},
+			Tenants: []*conf.Credential_Tenant{{TenantId: 2, Backend: "corp"}},
+		},
+	}
+	var err error
+	suite.backends, err = NewCredentialBackends(suite.userRepo, cfg, logger)
+	suite.Require().NoError(err)
+
+	tokens, err := NewTokenManager(cfg, logger)
+	suite.Require().NoError(err)
+	pow, err := NewProofOfWork(new(MockPowRepo), tokens, cfg, logger)
+	suite.Require().NoError(err)
+	useCase, err := NewUserUseCase(suite.userRepo, new(MockAuthRequestRepo), new(MockInviteRepo), new(MockTransactor), tokens, newTestHasher(suite.T(), "pepper"), suite.backends, pow, newDisabledLoginRisk(), NewDomainEvents(new(MockOutboxRepo), zap.NewNop()), cfg, logger)
+	suite.Require().NoError(err)
+	suite.useCase = useCase.(*UserUseCase)
+}
+
+func (suite *CredentialVerifierTestSuite) submit(username, password string) (*model.AuthResult, error) {
+	suite.userRepo.On("GetAuthChallenge", suite.ctx, username).Return("challenge", nil)
+	return suite.useCase.SubmitAuth(suite.ctx, &model.SubmitAuthRequest{
+		Username:          username,
+		Password:          password,
+		ChallengeResponse: computeChallengeResponse("challenge", username),
+	})
+}
+
+func (suite *CredentialVerifierTestSuite) TestNewCredentialBackends_Invalid() {
+	ldapCfg := &conf.Credential_Ldap{Url: suite.directory.URL, BaseDn: "dc=example,dc=com"}
+	tests := []struct {
+		name string
+		cfg  *conf.Credential
+	}{
+		{"local id", &conf.Credential{Backends: []*conf.Credential_Backend{{Id: "local", Ldap: ldapCfg}}}},
+		{"duplicate id", &conf.Credential{Backends: []*conf.Credential_Backend{{Id: "corp", Ldap: ldapCfg}, {Id: "corp", Ldap: ldapCfg}}}},
+		{"missing ldap", &conf.Credential{Backends: []*conf.Credential_Backend{{Id: "corp"}}}},
+		{"unknown tenant backend", &conf.Credential{Tenants: []*conf.Credential_Tenant{{TenantId: 2, Backend: "corp"}}}},
+	}
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			_, err := NewCredentialBackends(suite.userRepo, &conf.Bootstrap{Credential: tt.cfg}, zap.NewNop())
+
+			assert.Error(suite.T(), err)
+		})
+	}
+}
+
+func (suite *CredentialVerifierTestSuite) TestVerifierSelection() {
+	local := &passwordHashVerifier{}
+	corp := suite.backends.backends["corp"]
+
+	// 租户默认后端
+	v, err := suite.backends.verifier(2, nil, local)
+	assert.NoError(suite.T(), err)
+	assert.Same(suite.T(), corp, v)
+
+	// 用户指定的后端优先于租户默认
+	v, err = suite.backends.verifier(2, &model.User{CredentialBackend: "local"}, local)
+	assert.NoError(suite.T(), err)
+	assert.Same(suite.T(), local, v)
+	v, err = suite.backends.verifier(1, &model.User{CredentialBackend: "corp"}, local)
+	assert.NoError(suite.T(), err)
+	assert.Same(suite.T(), corp, v)
+
+	// 未配置的租户使用本地凭证
+	v, err = suite.backends.verifier(1, &model.User{}, local)
+	assert.NoError(suite.T(), err)
+	assert.Same(suite.T(), local, v)
+
+	_, err = suite.backends.verifier(1, &model.User{CredentialBackend: "removed"}, local)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *CredentialVerifierTestSuite) TestGetAuthChallenge_UnknownDirectoryUser() {
+	suite.userRepo.On("GetUserByName", suite.ctx, "alice").Return(nil, errors.New("not found"))
+	suite.userRepo.On("StoreAuthChallenge", suite.ctx, "alice", mock.Anything, mock.Anything).Return(nil)
+
+	challenge, err := suite.useCase.GetAuthChallenge(suite.ctx, "alice", nil)
+
+	// 首次登录的目录用户本地还不存在，要求提交密码原文
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.CredentialTypePassword, challenge.CredentialType)
+	assert.Empty(suite.T(), challenge.Kdf)
+	assert.Empty(suite.T(), challenge.Salt)
+}
+
+func (suite *CredentialVerifierTestSuite) TestSubmitAuth_SyncsDirectoryUser() {
+	suite.userRepo.On("GetUserByName", suite.ctx, "alice").Return(nil, errors.New("not found"))
+	suite.userRepo.On("SyncDirectoryUser", suite.ctx, &model.DirectoryUser{
+		Username:    "alice",
+		Email:       "alice@example.com",
+		DisplayName: "Alice Liddell",
+		GivenName:   "Alice",
+		FamilyName:  "Liddell",
+		Backend:     "corp",
+	}).Return(&model.User{ID: 11, TenantID: 2, Username: "alice"}, nil)
+
+	result, err := suite.submit("alice", "wonderland")
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), result.AuthToken)
+	principal, err := suite.useCase.tokens.VerifyToken(suite.ctx, result.AuthToken)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(11), principal.UserID)
+	suite.userRepo.AssertNotCalled(suite.T(), "UpdateCredential", mock.Anything, mock.Anything)
+}
+
+func (suite *CredentialVerifierTestSuite) TestSubmitAuth_WrongPassword() {
+	suite.userRepo.On("GetUserByName", suite.ctx, "alice").Return(&model.User{ID: 11, Username: "alice", CredentialBackend: "corp"}, nil)
+
+	result, err := suite.submit("alice", "queen-of-hearts")
+
+	assert.Nil(suite.T(), result)
+	assert.EqualError(suite.T(), err, "authentication failed")
+	suite.userRepo.AssertNotCalled(suite.T(), "SyncDirectoryUser", mock.Anything, mock.Anything)
+}
+
+func (suite *CredentialVerifierTestSuite) TestSubmitAuth_EmailConflict() {
+	suite.userRepo.On("GetUserByName", suite.ctx, "alice").Return(nil, errors.New("not found"))
+	suite.userRepo.On("SyncDirectoryUser", suite.ctx, mock.MatchedBy(func(u *model.DirectoryUser) bool {
+		return u.Email != ""
+	})).Return(nil, model.ErrUserConflict).Once()
+	suite.userRepo.On("SyncDirectoryUser", suite.ctx, mock.MatchedBy(func(u *model.DirectoryUser) bool {
+		return u.Email == "" && u.DisplayName == "Alice Liddell"
+	})).Return(&model.User{ID: 11, TenantID: 2, Username: "alice"}, nil).Once()
+
+	result, err := suite.submit("alice", "wonderland")
+
+	// 邮箱被占用时不同步邮箱，登录照常成功
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), result.AuthToken)
+	suite.userRepo.AssertNumberOfCalls(suite.T(), "SyncDirectoryUser", 2)
+}
+
+func (suite *CredentialVerifierTestSuite) TestSubmitAuth_BackendConflict() {
+	suite.userRepo.On("GetUserByName", suite.ctx, "alice").Return(nil, errors.New("not found"))
+	suite.userRepo.On("SyncDirectoryUser", suite.ctx, mock.AnythingOfType("*model.DirectoryUser")).Return(nil, model.ErrUserConflict)
+
+	result, err := suite.submit("alice", "wonderland")
+
+	// 同名用户属于其他后端时按认证失败处理，不接管该用户
+	assert.Nil(suite.T(), result)
+	assert.EqualError(suite.T(), err, "authentication failed")
+}
+
+func (suite *CredentialVerifierTestSuite) TestSubmitAuth_DirectoryUnavailable() {
+	suite.userRepo.On("GetUserByName", suite.ctx, "alice").Return(nil, errors.New("not found"))
+	suite.directory.Close()
+
+	result, err := suite.submit("alice", "wonderland")
+
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), connect.CodeUnavailable, connect.CodeOf(err))
+}
+
+func TestCredentialVerifierTestSuite(t *testing.T) {
+	suite.Run(t, new(CredentialVerifierTestSuite))
+}
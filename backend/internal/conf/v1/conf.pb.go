@@ -29,6 +29,21 @@ type Bootstrap struct {
 	Auth          *Auth                  `protobuf:"bytes,3,opt,name=auth,proto3" json:"auth,omitempty"`
 	Trace         *Trace                 `protobuf:"bytes,4,opt,name=trace,proto3" json:"trace,omitempty"`
 	Discovery     *Discovery             `protobuf:"bytes,5,opt,name=discovery,proto3" json:"discovery,omitempty"`
+	Mail          *Mail                  `protobuf:"bytes,6,opt,name=mail,proto3" json:"mail,omitempty"`
+	Tenancy       *Tenancy               `protobuf:"bytes,7,opt,name=tenancy,proto3" json:"tenancy,omitempty"`
+	Registration  *Registration          `protobuf:"bytes,8,opt,name=registration,proto3" json:"registration,omitempty"`
+	ProofOfWork   *ProofOfWork           `protobuf:"bytes,9,opt,name=proof_of_work,json=proofOfWork,proto3" json:"proof_of_work,omitempty"`
+	ClientKdf     *ClientKdf             `protobuf:"bytes,10,opt,name=client_kdf,json=clientKdf,proto3" json:"client_kdf,omitempty"`
+	LoginRisk     *LoginRisk             `protobuf:"bytes,11,opt,name=login_risk,json=loginRisk,proto3" json:"login_risk,omitempty"`
+	Notify        *Notify                `protobuf:"bytes,12,opt,name=notify,proto3" json:"notify,omitempty"`
+	Oauth         *OAuth                 `protobuf:"bytes,13,opt,name=oauth,proto3" json:"oauth,omitempty"`
+	Dpop          *DPoP                  `protobuf:"bytes,14,opt,name=dpop,proto3" json:"dpop,omitempty"`
+	Scim          *Scim                  `protobuf:"bytes,15,opt,name=scim,proto3" json:"scim,omitempty"`
+	Federation    *Federation            `protobuf:"bytes,16,opt,name=federation,proto3" json:"federation,omitempty"`
+	Credential    *Credential            `protobuf:"bytes,17,opt,name=credential,proto3" json:"credential,omitempty"`
+	Events        *Events                `protobuf:"bytes,18,opt,name=events,proto3" json:"events,omitempty"`
+	Webhooks      *Webhooks              `protobuf:"bytes,19,opt,name=webhooks,proto3" json:"webhooks,omitempty"`
+	Encryption    *Encryption            `protobuf:"bytes,20,opt,name=encryption,proto3" json:"encryption,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -98,6 +113,111 @@ func (x *Bootstrap) GetDiscovery() *Discovery {
 	return nil
 }
 
+func (x *Bootstrap) GetMail() *Mail {
+	if x != nil {
+		return x.Mail
+	}
+	return nil
+}
+
+func (x *Bootstrap) GetTenancy() *Tenancy {
+	if x != nil {
+		return x.Tenancy
+	}
+	return nil
+}
+
+func (x *Bootstrap) GetRegistration() *Registration {
+	if x != nil {
+		return x.Registration
+	}
+	return nil
+}
+
+func (x *Bootstrap) GetProofOfWork() *ProofOfWork {
+	if x != nil {
+		return x.ProofOfWork
+	}
+	return nil
+}
+
+func (x *Bootstrap) GetClientKdf() *ClientKdf {
+	if x != nil {
+		return x.ClientKdf
+	}
+	return nil
+}
+
+func (x *Bootstrap) GetLoginRisk() *LoginRisk {
+	if x != nil {
+		return x.LoginRisk
+	}
+	return nil
+}
+
+func (x *Bootstrap) GetNotify() *Notify {
+	if x != nil {
+		return x.Notify
+	}
+	return nil
+}
+
+func (x *Bootstrap) GetOauth() *OAuth {
+	if x != nil {
+		return x.Oauth
+	}
+	return nil
+}
+
+func (x *Bootstrap) GetDpop() *DPoP {
+	if x != nil {
+		return x.Dpop
+	}
+	return nil
+}
+
+func (x *Bootstrap) GetScim() *Scim {
+	if x != nil {
+		return x.Scim
+	}
+	return nil
+}
+
+func (x *Bootstrap) GetFederation() *Federation {
+	if x != nil {
+		return x.Federation
+	}
+	return nil
+}
+
+func (x *Bootstrap) GetCredential() *Credential {
+	if x != nil {
+		return x.Credential
+	}
+	return nil
+}
+
+func (x *Bootstrap) GetEvents() *Events {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *Bootstrap) GetWebhooks() *Webhooks {
+	if x != nil {
+		return x.Webhooks
+	}
+	return nil
+}
+
+func (x *Bootstrap) GetEncryption() *Encryption {
+	if x != nil {
+		return x.Encryption
+	}
+	return nil
+}
+
 type Server struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Http          *Server_HTTP           `protobuf:"bytes,1,opt,name=http,proto3" json:"http,omitempty"`
@@ -143,9 +263,15 @@ func (x *Server) GetHttp() *Server_HTTP {
 }
 
 type Data struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Database      *Data_Database         `protobuf:"bytes,1,opt,name=database,proto3" json:"database,omitempty"`
-	Redis         *Data_Redis            `protobuf:"bytes,2,opt,name=redis,proto3" json:"redis,omitempty"`
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Database *Data_Database         `protobuf:"bytes,1,opt,name=database,proto3" json:"database,omitempty"`
+	Redis    *Data_Redis            `protobuf:"bytes,2,opt,name=redis,proto3" json:"redis,omitempty"`
+	// postgres（默认）、memory 或 sqlite；memory 和 sqlite 供本地开发和 CI 使用，只实现用户、租户和健康检查，
+	// 不要求 Postgres 和 Redis 可用，依赖它们的其他功能调用时返回错误
+	Driver        string           `protobuf:"bytes,3,opt,name=driver,proto3" json:"driver,omitempty"`
+	Sqlite        *Data_Sqlite     `protobuf:"bytes,4,opt,name=sqlite,proto3" json:"sqlite,omitempty"`
+	StateStore    *Data_StateStore `protobuf:"bytes,5,opt,name=state_store,json=stateStore,proto3" json:"state_store,omitempty"`
+	UserCache     *Data_UserCache  `protobuf:"bytes,6,opt,name=user_cache,json=userCache,proto3" json:"user_cache,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -194,13 +320,55 @@ func (x *Data) GetRedis() *Data_Redis {
 	return nil
 }
 
+func (x *Data) GetDriver() string {
+	if x != nil {
+		return x.Driver
+	}
+	return ""
+}
+
+func (x *Data) GetSqlite() *Data_Sqlite {
+	if x != nil {
+		return x.Sqlite
+	}
+	return nil
+}
+
+func (x *Data) GetStateStore() *Data_StateStore {
+	if x != nil {
+		return x.StateStore
+	}
+	return nil
+}
+
+func (x *Data) GetUserCache() *Data_UserCache {
+	if x != nil {
+		return x.UserCache
+	}
+	return nil
+}
+
 type Auth struct {
-	state                   protoimpl.MessageState `protogen:"open.v1"`
-	JwtSecret               string                 `protobuf:"bytes,1,opt,name=jwt_secret,json=jwtSecret,proto3" json:"jwt_secret,omitempty"`
-	JwtExpireHours          int64                  `protobuf:"varint,2,opt,name=jwt_expire_hours,json=jwtExpireHours,proto3" json:"jwt_expire_hours,omitempty"`
-	ChallengeTimeoutSeconds int64                  `protobuf:"varint,3,opt,name=challenge_timeout_seconds,json=challengeTimeoutSeconds,proto3" json:"challenge_timeout_seconds,omitempty"`
-	unknownFields           protoimpl.UnknownFields
-	sizeCache               protoimpl.SizeCache
+	state                          protoimpl.MessageState `protogen:"open.v1"`
+	JwtSecret                      string                 `protobuf:"bytes,1,opt,name=jwt_secret,json=jwtSecret,proto3" json:"jwt_secret,omitempty"`
+	JwtExpireHours                 int64                  `protobuf:"varint,2,opt,name=jwt_expire_hours,json=jwtExpireHours,proto3" json:"jwt_expire_hours,omitempty"`
+	ChallengeTimeoutSeconds        int64                  `protobuf:"varint,3,opt,name=challenge_timeout_seconds,json=challengeTimeoutSeconds,proto3" json:"challenge_timeout_seconds,omitempty"`
+	AuthRequestTimeoutSeconds      int64                  `protobuf:"varint,4,opt,name=auth_request_timeout_seconds,json=authRequestTimeoutSeconds,proto3" json:"auth_request_timeout_seconds,omitempty"`
+	CrossDeviceLoginTimeoutSeconds int64                  `protobuf:"varint,5,opt,name=cross_device_login_timeout_seconds,json=crossDeviceLoginTimeoutSeconds,proto3" json:"cross_device_login_timeout_seconds,omitempty"`
+	CrossDeviceApproveUrl          string                 `protobuf:"bytes,6,opt,name=cross_device_approve_url,json=crossDeviceApproveUrl,proto3" json:"cross_device_approve_url,omitempty"` // 二维码中的批准页面地址，会附加 code 查询参数
+	MagicLinkUrl                   string                 `protobuf:"bytes,7,opt,name=magic_link_url,json=magicLinkUrl,proto3" json:"magic_link_url,omitempty"`                              // 邮件中的登录页面地址，会附加 token 查询参数
+	MagicLinkTimeoutSeconds        int64                  `protobuf:"varint,8,opt,name=magic_link_timeout_seconds,json=magicLinkTimeoutSeconds,proto3" json:"magic_link_timeout_seconds,omitempty"`
+	MagicLinkMaxRequests           int64                  `protobuf:"varint,9,opt,name=magic_link_max_requests,json=magicLinkMaxRequests,proto3" json:"magic_link_max_requests,omitempty"` // 每个邮箱在窗口内最多请求的次数
+	MagicLinkRateWindowSeconds     int64                  `protobuf:"varint,10,opt,name=magic_link_rate_window_seconds,json=magicLinkRateWindowSeconds,proto3" json:"magic_link_rate_window_seconds,omitempty"`
+	// 服务端对客户端凭证做 argon2id 哈希时混入的 pepper，不与数据库存放在一起
+	// 优先读取 credential_pepper_file，其次环境变量 CREDENTIAL_PEPPER，最后是 credential_pepper
+	CredentialPepper     string `protobuf:"bytes,11,opt,name=credential_pepper,json=credentialPepper,proto3" json:"credential_pepper,omitempty"`
+	CredentialPepperFile string `protobuf:"bytes,12,opt,name=credential_pepper_file,json=credentialPepperFile,proto3" json:"credential_pepper_file,omitempty"`
+	Argon2MemoryKib      uint32 `protobuf:"varint,13,opt,name=argon2_memory_kib,json=argon2MemoryKib,proto3" json:"argon2_memory_kib,omitempty"`     // 默认 64MiB
+	Argon2Iterations     uint32 `protobuf:"varint,14,opt,name=argon2_iterations,json=argon2Iterations,proto3" json:"argon2_iterations,omitempty"`    // 默认3
+	Argon2Parallelism    uint32 `protobuf:"varint,15,opt,name=argon2_parallelism,json=argon2Parallelism,proto3" json:"argon2_parallelism,omitempty"` // 默认2
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
 func (x *Auth) Reset() {
@@ -254,6 +422,90 @@ func (x *Auth) GetChallengeTimeoutSeconds() int64 {
 	return 0
 }
 
+func (x *Auth) GetAuthRequestTimeoutSeconds() int64 {
+	if x != nil {
+		return x.AuthRequestTimeoutSeconds
+	}
+	return 0
+}
+
+func (x *Auth) GetCrossDeviceLoginTimeoutSeconds() int64 {
+	if x != nil {
+		return x.CrossDeviceLoginTimeoutSeconds
+	}
+	return 0
+}
+
+func (x *Auth) GetCrossDeviceApproveUrl() string {
+	if x != nil {
+		return x.CrossDeviceApproveUrl
+	}
+	return ""
+}
+
+func (x *Auth) GetMagicLinkUrl() string {
+	if x != nil {
+		return x.MagicLinkUrl
+	}
+	return ""
+}
+
+func (x *Auth) GetMagicLinkTimeoutSeconds() int64 {
+	if x != nil {
+		return x.MagicLinkTimeoutSeconds
+	}
+	return 0
+}
+
+func (x *Auth) GetMagicLinkMaxRequests() int64 {
+	if x != nil {
+		return x.MagicLinkMaxRequests
+	}
+	return 0
+}
+
+func (x *Auth) GetMagicLinkRateWindowSeconds() int64 {
+	if x != nil {
+		return x.MagicLinkRateWindowSeconds
+	}
+	return 0
+}
+
+func (x *Auth) GetCredentialPepper() string {
+	if x != nil {
+		return x.CredentialPepper
+	}
+	return ""
+}
+
+func (x *Auth) GetCredentialPepperFile() string {
+	if x != nil {
+		return x.CredentialPepperFile
+	}
+	return ""
+}
+
+func (x *Auth) GetArgon2MemoryKib() uint32 {
+	if x != nil {
+		return x.Argon2MemoryKib
+	}
+	return 0
+}
+
+func (x *Auth) GetArgon2Iterations() uint32 {
+	if x != nil {
+		return x.Argon2Iterations
+	}
+	return 0
+}
+
+func (x *Auth) GetArgon2Parallelism() uint32 {
+	if x != nil {
+		return x.Argon2Parallelism
+	}
+	return 0
+}
+
 type Trace struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Endpoint      string                 `protobuf:"bytes,1,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
@@ -306,28 +558,2200 @@ func (x *Trace) GetInsecure() bool {
 	return false
 }
 
-type Discovery struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Consul        *Discovery_Consul      `protobuf:"bytes,1,opt,name=consul,proto3" json:"consul,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type Mail struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Driver        string                 `protobuf:"bytes,1,opt,name=driver,proto3" json:"driver,omitempty"` // file 或 smtp，默认 file
+	From          string                 `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	FileDir       string                 `protobuf:"bytes,3,opt,name=file_dir,json=fileDir,proto3" json:"file_dir,omitempty"` // file 驱动写入邮件的目录，用于本地开发
+	Smtp          *Mail_SMTP             `protobuf:"bytes,4,opt,name=smtp,proto3" json:"smtp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Mail) Reset() {
+	*x = Mail{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Mail) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Mail) ProtoMessage() {}
+
+func (x *Mail) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Mail.ProtoReflect.Descriptor instead.
+func (*Mail) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Mail) GetDriver() string {
+	if x != nil {
+		return x.Driver
+	}
+	return ""
+}
+
+func (x *Mail) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *Mail) GetFileDir() string {
+	if x != nil {
+		return x.FileDir
+	}
+	return ""
+}
+
+func (x *Mail) GetSmtp() *Mail_SMTP {
+	if x != nil {
+		return x.Smtp
+	}
+	return nil
+}
+
+type Tenancy struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Header         string                 `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`                                         // 携带租户标识的请求头，默认 X-Tenant-ID
+	ClientIdHeader string                 `protobuf:"bytes,2,opt,name=client_id_header,json=clientIdHeader,proto3" json:"client_id_header,omitempty"` // 携带客户端ID的请求头，默认 X-Client-ID
+	BaseDomain     string                 `protobuf:"bytes,3,opt,name=base_domain,json=baseDomain,proto3" json:"base_domain,omitempty"`               // 配置后按 <tenant>.<base_domain> 识别子域名
+	DefaultTenant  string                 `protobuf:"bytes,4,opt,name=default_tenant,json=defaultTenant,proto3" json:"default_tenant,omitempty"`      // 请求中没有租户信息时使用的租户标识，为空时拒绝请求
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Tenancy) Reset() {
+	*x = Tenancy{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Tenancy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tenancy) ProtoMessage() {}
+
+func (x *Tenancy) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tenancy.ProtoReflect.Descriptor instead.
+func (*Tenancy) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Tenancy) GetHeader() string {
+	if x != nil {
+		return x.Header
+	}
+	return ""
+}
+
+func (x *Tenancy) GetClientIdHeader() string {
+	if x != nil {
+		return x.ClientIdHeader
+	}
+	return ""
+}
+
+func (x *Tenancy) GetBaseDomain() string {
+	if x != nil {
+		return x.BaseDomain
+	}
+	return ""
+}
+
+func (x *Tenancy) GetDefaultTenant() string {
+	if x != nil {
+		return x.DefaultTenant
+	}
+	return ""
+}
+
+type Registration struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Mode                  string                 `protobuf:"bytes,1,opt,name=mode,proto3" json:"mode,omitempty"`                                                                     // open（默认）、invite、domain、closed
+	AllowedDomains        []string               `protobuf:"bytes,2,rep,name=allowed_domains,json=allowedDomains,proto3" json:"allowed_domains,omitempty"`                           // domain 模式下允许注册的邮箱域名
+	InviteMaxUses         int32                  `protobuf:"varint,3,opt,name=invite_max_uses,json=inviteMaxUses,proto3" json:"invite_max_uses,omitempty"`                           // 邀请码默认可用次数，默认1次
+	InviteTtlSeconds      int64                  `protobuf:"varint,4,opt,name=invite_ttl_seconds,json=inviteTtlSeconds,proto3" json:"invite_ttl_seconds,omitempty"`                  // 邀请码默认有效期，默认7天
+	VerifyUrl             string                 `protobuf:"bytes,5,opt,name=verify_url,json=verifyUrl,proto3" json:"verify_url,omitempty"`                                          // domain 模式下验证邮件中的注册页面地址，票据以 email_ticket 参数附加；为空时邮件中直接给出票据
+	EmailTicketTtlSeconds int64                  `protobuf:"varint,6,opt,name=email_ticket_ttl_seconds,json=emailTicketTtlSeconds,proto3" json:"email_ticket_ttl_seconds,omitempty"` // domain 模式下邮箱票据的有效期，默认1小时
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *Registration) Reset() {
+	*x = Registration{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Registration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Registration) ProtoMessage() {}
+
+func (x *Registration) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Registration.ProtoReflect.Descriptor instead.
+func (*Registration) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Registration) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *Registration) GetAllowedDomains() []string {
+	if x != nil {
+		return x.AllowedDomains
+	}
+	return nil
+}
+
+func (x *Registration) GetInviteMaxUses() int32 {
+	if x != nil {
+		return x.InviteMaxUses
+	}
+	return 0
+}
+
+func (x *Registration) GetInviteTtlSeconds() int64 {
+	if x != nil {
+		return x.InviteTtlSeconds
+	}
+	return 0
+}
+
+func (x *Registration) GetVerifyUrl() string {
+	if x != nil {
+		return x.VerifyUrl
+	}
+	return ""
+}
+
+func (x *Registration) GetEmailTicketTtlSeconds() int64 {
+	if x != nil {
+		return x.EmailTicketTtlSeconds
+	}
+	return 0
+}
+
+// 工作量证明，开启后 Register 和 GetAuthChallenge 需要先解题
+type ProofOfWork struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Enabled               bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	MinDifficulty         int32                  `protobuf:"varint,2,opt,name=min_difficulty,json=minDifficulty,proto3" json:"min_difficulty,omitempty"`                         // 前导零比特数，默认16
+	MaxDifficulty         int32                  `protobuf:"varint,3,opt,name=max_difficulty,json=maxDifficulty,proto3" json:"max_difficulty,omitempty"`                         // 默认24
+	TtlSeconds            int64                  `protobuf:"varint,4,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`                                  // 题目有效期，默认5分钟
+	RateWindowSeconds     int64                  `protobuf:"varint,5,opt,name=rate_window_seconds,json=rateWindowSeconds,proto3" json:"rate_window_seconds,omitempty"`           // 统计注册和登录失败次数的窗口，默认10分钟
+	RegistrationThreshold int64                  `protobuf:"varint,6,opt,name=registration_threshold,json=registrationThreshold,proto3" json:"registration_threshold,omitempty"` // 窗口内注册次数每超过一倍阈值难度加1，默认20
+	FailedLoginThreshold  int64                  `protobuf:"varint,7,opt,name=failed_login_threshold,json=failedLoginThreshold,proto3" json:"failed_login_threshold,omitempty"`  // 窗口内登录失败次数每超过一倍阈值难度加1，默认50
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *ProofOfWork) Reset() {
+	*x = ProofOfWork{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProofOfWork) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProofOfWork) ProtoMessage() {}
+
+func (x *ProofOfWork) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProofOfWork.ProtoReflect.Descriptor instead.
+func (*ProofOfWork) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ProofOfWork) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *ProofOfWork) GetMinDifficulty() int32 {
+	if x != nil {
+		return x.MinDifficulty
+	}
+	return 0
+}
+
+func (x *ProofOfWork) GetMaxDifficulty() int32 {
+	if x != nil {
+		return x.MaxDifficulty
+	}
+	return 0
+}
+
+func (x *ProofOfWork) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+func (x *ProofOfWork) GetRateWindowSeconds() int64 {
+	if x != nil {
+		return x.RateWindowSeconds
+	}
+	return 0
+}
+
+func (x *ProofOfWork) GetRegistrationThreshold() int64 {
+	if x != nil {
+		return x.RegistrationThreshold
+	}
+	return 0
+}
+
+func (x *ProofOfWork) GetFailedLoginThreshold() int64 {
+	if x != nil {
+		return x.FailedLoginThreshold
+	}
+	return 0
+}
+
+// 客户端派生凭证的 KDF 参数，按版本管理，已有用户仍在使用的版本不能删除
+type ClientKdf struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Profiles         []*ClientKdf_Profile   `protobuf:"bytes,1,rep,name=profiles,proto3" json:"profiles,omitempty"`                                            // 版本号最大的为当前版本，未配置时默认 v1：64MiB、3次、并行度1
+	RequireTicket    bool                   `protobuf:"varint,2,opt,name=require_ticket,json=requireTicket,proto3" json:"require_ticket,omitempty"`            // 为 true 时 Register 必须携带 GetRegistrationParams 的票据
+	TicketTtlSeconds int64                  `protobuf:"varint,3,opt,name=ticket_ttl_seconds,json=ticketTtlSeconds,proto3" json:"ticket_ttl_seconds,omitempty"` // 票据有效期，默认10分钟
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ClientKdf) Reset() {
+	*x = ClientKdf{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClientKdf) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientKdf) ProtoMessage() {}
+
+func (x *ClientKdf) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientKdf.ProtoReflect.Descriptor instead.
+func (*ClientKdf) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ClientKdf) GetProfiles() []*ClientKdf_Profile {
+	if x != nil {
+		return x.Profiles
+	}
+	return nil
+}
+
+func (x *ClientKdf) GetRequireTicket() bool {
+	if x != nil {
+		return x.RequireTicket
+	}
+	return false
+}
+
+func (x *ClientKdf) GetTicketTtlSeconds() int64 {
+	if x != nil {
+		return x.TicketTtlSeconds
+	}
+	return 0
+}
+
+// 登录风险识别：记录用户的常用设备，陌生设备和异常时段登录时提醒用户
+type LoginRisk struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Enabled             bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Ipv4PrefixBits      int32                  `protobuf:"varint,2,opt,name=ipv4_prefix_bits,json=ipv4PrefixBits,proto3" json:"ipv4_prefix_bits,omitempty"`                  // 计算网段使用的前缀长度，默认24
+	Ipv6PrefixBits      int32                  `protobuf:"varint,3,opt,name=ipv6_prefix_bits,json=ipv6PrefixBits,proto3" json:"ipv6_prefix_bits,omitempty"`                  // 默认48
+	NewDeviceScore      int32                  `protobuf:"varint,4,opt,name=new_device_score,json=newDeviceScore,proto3" json:"new_device_score,omitempty"`                  // 陌生设备的风险分，默认50
+	NewNetworkScore     int32                  `protobuf:"varint,5,opt,name=new_network_score,json=newNetworkScore,proto3" json:"new_network_score,omitempty"`               // 已知设备从陌生网段登录的风险分，默认20
+	UnusualHourScore    int32                  `protobuf:"varint,6,opt,name=unusual_hour_score,json=unusualHourScore,proto3" json:"unusual_hour_score,omitempty"`            // 在不常登录的时段（UTC）登录的风险分，默认30
+	UsualHoursMinLogins int32                  `protobuf:"varint,7,opt,name=usual_hours_min_logins,json=usualHoursMinLogins,proto3" json:"usual_hours_min_logins,omitempty"` // 历史登录次数达到该值才判断时段，默认10
+	HistoryDays         int64                  `protobuf:"varint,8,opt,name=history_days,json=historyDays,proto3" json:"history_days,omitempty"`                             // 统计历史登录的天数，默认30
+	StepUpThreshold     int32                  `protobuf:"varint,9,opt,name=step_up_threshold,json=stepUpThreshold,proto3" json:"step_up_threshold,omitempty"`               // 风险分达到该值时要求二次验证，0 表示只提醒不验证
+	StepUpTtlSeconds    int64                  `protobuf:"varint,10,opt,name=step_up_ttl_seconds,json=stepUpTtlSeconds,proto3" json:"step_up_ttl_seconds,omitempty"`         // 验证码有效期，默认10分钟
+	StepUpMaxAttempts   int32                  `protobuf:"varint,11,opt,name=step_up_max_attempts,json=stepUpMaxAttempts,proto3" json:"step_up_max_attempts,omitempty"`      // 验证码最多尝试次数，默认5
+	StepUpUnreachable   string                 `protobuf:"bytes,12,opt,name=step_up_unreachable,json=stepUpUnreachable,proto3" json:"step_up_unreachable,omitempty"`         // 无法向用户发送验证码（如没有邮箱）时：deny（默认，拒绝登录）或 allow（写审计日志后跳过二次验证）
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *LoginRisk) Reset() {
+	*x = LoginRisk{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginRisk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginRisk) ProtoMessage() {}
+
+func (x *LoginRisk) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginRisk.ProtoReflect.Descriptor instead.
+func (*LoginRisk) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *LoginRisk) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *LoginRisk) GetIpv4PrefixBits() int32 {
+	if x != nil {
+		return x.Ipv4PrefixBits
+	}
+	return 0
+}
+
+func (x *LoginRisk) GetIpv6PrefixBits() int32 {
+	if x != nil {
+		return x.Ipv6PrefixBits
+	}
+	return 0
+}
+
+func (x *LoginRisk) GetNewDeviceScore() int32 {
+	if x != nil {
+		return x.NewDeviceScore
+	}
+	return 0
+}
+
+func (x *LoginRisk) GetNewNetworkScore() int32 {
+	if x != nil {
+		return x.NewNetworkScore
+	}
+	return 0
+}
+
+func (x *LoginRisk) GetUnusualHourScore() int32 {
+	if x != nil {
+		return x.UnusualHourScore
+	}
+	return 0
+}
+
+func (x *LoginRisk) GetUsualHoursMinLogins() int32 {
+	if x != nil {
+		return x.UsualHoursMinLogins
+	}
+	return 0
+}
+
+func (x *LoginRisk) GetHistoryDays() int64 {
+	if x != nil {
+		return x.HistoryDays
+	}
+	return 0
+}
+
+func (x *LoginRisk) GetStepUpThreshold() int32 {
+	if x != nil {
+		return x.StepUpThreshold
+	}
+	return 0
+}
+
+func (x *LoginRisk) GetStepUpTtlSeconds() int64 {
+	if x != nil {
+		return x.StepUpTtlSeconds
+	}
+	return 0
+}
+
+func (x *LoginRisk) GetStepUpMaxAttempts() int32 {
+	if x != nil {
+		return x.StepUpMaxAttempts
+	}
+	return 0
+}
+
+func (x *LoginRisk) GetStepUpUnreachable() string {
+	if x != nil {
+		return x.StepUpUnreachable
+	}
+	return ""
+}
+
+type Notify struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Driver        string                 `protobuf:"bytes,1,opt,name=driver,proto3" json:"driver,omitempty"` // log（默认，仅写日志）或 mail（发送到用户邮箱）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Notify) Reset() {
+	*x = Notify{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Notify) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Notify) ProtoMessage() {}
+
+func (x *Notify) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Notify.ProtoReflect.Descriptor instead.
+func (*Notify) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Notify) GetDriver() string {
+	if x != nil {
+		return x.Driver
+	}
+	return ""
+}
+
+// 领域事件：与数据修改在同一事务中写入 outbox 表，由投递任务发送到消息代理，至少投递一次
+type Events struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Broker         string                 `protobuf:"bytes,1,opt,name=broker,proto3" json:"broker,omitempty"` // nats（JetStream，需要已有覆盖 topic 的 stream）、kafka、redis（Redis Streams）或 memory；为空时只投递给 Webhook
+	Topic          string                 `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`   // 主题、subject 或流的名称，默认 user.events；消息以聚合ID为键
+	Nats           *Events_Nats           `protobuf:"bytes,3,opt,name=nats,proto3" json:"nats,omitempty"`
+	Kafka          *Events_Kafka          `protobuf:"bytes,4,opt,name=kafka,proto3" json:"kafka,omitempty"`
+	Redis          *Events_RedisStream    `protobuf:"bytes,5,opt,name=redis,proto3" json:"redis,omitempty"`
+	BatchSize      int32                  `protobuf:"varint,6,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`                  // 每次投递的最大事件数，默认100
+	PollIntervalMs int64                  `protobuf:"varint,7,opt,name=poll_interval_ms,json=pollIntervalMs,proto3" json:"poll_interval_ms,omitempty"` // 没有待投递事件时的轮询间隔，默认1000
+	RetentionHours int64                  `protobuf:"varint,8,opt,name=retention_hours,json=retentionHours,proto3" json:"retention_hours,omitempty"`   // 已投递事件的保留时间，默认7天
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Events) Reset() {
+	*x = Events{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Events) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Events) ProtoMessage() {}
+
+func (x *Events) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Events.ProtoReflect.Descriptor instead.
+func (*Events) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Events) GetBroker() string {
+	if x != nil {
+		return x.Broker
+	}
+	return ""
+}
+
+func (x *Events) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *Events) GetNats() *Events_Nats {
+	if x != nil {
+		return x.Nats
+	}
+	return nil
+}
+
+func (x *Events) GetKafka() *Events_Kafka {
+	if x != nil {
+		return x.Kafka
+	}
+	return nil
+}
+
+func (x *Events) GetRedis() *Events_RedisStream {
+	if x != nil {
+		return x.Redis
+	}
+	return nil
+}
+
+func (x *Events) GetBatchSize() int32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+func (x *Events) GetPollIntervalMs() int64 {
+	if x != nil {
+		return x.PollIntervalMs
+	}
+	return 0
+}
+
+func (x *Events) GetRetentionHours() int64 {
+	if x != nil {
+		return x.RetentionHours
+	}
+	return 0
+}
+
+// 出站 Webhook：订阅由 AdminService 管理，outbox 中的事件按订阅投递，失败后按指数退避重试
+type Webhooks struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	MaxAttempts           int32                  `protobuf:"varint,1,opt,name=max_attempts,json=maxAttempts,proto3" json:"max_attempts,omitempty"`                                 // 最多尝试次数，默认8，用完后进入 dead 状态
+	InitialBackoffSeconds int64                  `protobuf:"varint,2,opt,name=initial_backoff_seconds,json=initialBackoffSeconds,proto3" json:"initial_backoff_seconds,omitempty"` // 第一次失败后的等待时间，之后每次翻倍，默认30秒
+	MaxBackoffSeconds     int64                  `protobuf:"varint,3,opt,name=max_backoff_seconds,json=maxBackoffSeconds,proto3" json:"max_backoff_seconds,omitempty"`             // 默认6小时
+	TimeoutSeconds        int64                  `protobuf:"varint,4,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`                        // 单次请求超时，默认10秒
+	BatchSize             int32                  `protobuf:"varint,5,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`                                       // 每次取出的投递数，默认20
+	PollIntervalMs        int64                  `protobuf:"varint,6,opt,name=poll_interval_ms,json=pollIntervalMs,proto3" json:"poll_interval_ms,omitempty"`                      // 默认1000
+	AllowInsecureUrls     bool                   `protobuf:"varint,7,opt,name=allow_insecure_urls,json=allowInsecureUrls,proto3" json:"allow_insecure_urls,omitempty"`             // 允许 http 地址，仅用于开发环境
+	// 默认只投递到公网地址；这里列出额外允许的内网地址或网段（CIDR），仅用于开发环境
+	AllowedNetworks []string `protobuf:"bytes,8,rep,name=allowed_networks,json=allowedNetworks,proto3" json:"allowed_networks,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Webhooks) Reset() {
+	*x = Webhooks{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Webhooks) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Webhooks) ProtoMessage() {}
+
+func (x *Webhooks) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Webhooks.ProtoReflect.Descriptor instead.
+func (*Webhooks) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Webhooks) GetMaxAttempts() int32 {
+	if x != nil {
+		return x.MaxAttempts
+	}
+	return 0
+}
+
+func (x *Webhooks) GetInitialBackoffSeconds() int64 {
+	if x != nil {
+		return x.InitialBackoffSeconds
+	}
+	return 0
+}
+
+func (x *Webhooks) GetMaxBackoffSeconds() int64 {
+	if x != nil {
+		return x.MaxBackoffSeconds
+	}
+	return 0
+}
+
+func (x *Webhooks) GetTimeoutSeconds() int64 {
+	if x != nil {
+		return x.TimeoutSeconds
+	}
+	return 0
+}
+
+func (x *Webhooks) GetBatchSize() int32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+func (x *Webhooks) GetPollIntervalMs() int64 {
+	if x != nil {
+		return x.PollIntervalMs
+	}
+	return 0
+}
+
+func (x *Webhooks) GetAllowInsecureUrls() bool {
+	if x != nil {
+		return x.AllowInsecureUrls
+	}
+	return false
+}
+
+func (x *Webhooks) GetAllowedNetworks() []string {
+	if x != nil {
+		return x.AllowedNetworks
+	}
+	return nil
+}
+
+// 个人信息字段（邮箱、姓名）的信封加密，postgres 驱动必须配置
+type Encryption struct {
+	state                    protoimpl.MessageState `protogen:"open.v1"`
+	Provider                 string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`                                                                    // KEK 来源，目前支持 keyfile；接入 KMS 时实现 fieldcrypt.KeyProvider
+	Keyfile                  string                 `protobuf:"bytes,2,opt,name=keyfile,proto3" json:"keyfile,omitempty"`                                                                      // JSON 文件：{"primary": "<KEK ID>", "keys": {"<KEK ID>": "<base64 32字节>"}}，旧 KEK 保留到数据密钥重新加密后再删除
+	DataKeyRotationDays      int64                  `protobuf:"varint,3,opt,name=data_key_rotation_days,json=dataKeyRotationDays,proto3" json:"data_key_rotation_days,omitempty"`              // 数据密钥轮换周期，默认90天，旧数据在后台重新加密
+	ReencryptBatchSize       int32                  `protobuf:"varint,4,opt,name=reencrypt_batch_size,json=reencryptBatchSize,proto3" json:"reencrypt_batch_size,omitempty"`                   // 重新加密每批处理的行数，默认500
+	ReencryptIntervalSeconds int64                  `protobuf:"varint,5,opt,name=reencrypt_interval_seconds,json=reencryptIntervalSeconds,proto3" json:"reencrypt_interval_seconds,omitempty"` // 检查需要重新加密的数据的间隔，默认600秒
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
+}
+
+func (x *Encryption) Reset() {
+	*x = Encryption{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Encryption) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Encryption) ProtoMessage() {}
+
+func (x *Encryption) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Encryption.ProtoReflect.Descriptor instead.
+func (*Encryption) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Encryption) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *Encryption) GetKeyfile() string {
+	if x != nil {
+		return x.Keyfile
+	}
+	return ""
+}
+
+func (x *Encryption) GetDataKeyRotationDays() int64 {
+	if x != nil {
+		return x.DataKeyRotationDays
+	}
+	return 0
+}
+
+func (x *Encryption) GetReencryptBatchSize() int32 {
+	if x != nil {
+		return x.ReencryptBatchSize
+	}
+	return 0
+}
+
+func (x *Encryption) GetReencryptIntervalSeconds() int64 {
+	if x != nil {
+		return x.ReencryptIntervalSeconds
+	}
+	return 0
+}
+
+// 供内部资源服务使用的 OAuth 接口
+type OAuth struct {
+	state                     protoimpl.MessageState `protogen:"open.v1"`
+	Clients                   []*OAuth_Client        `protobuf:"bytes,1,rep,name=clients,proto3" json:"clients,omitempty"`                                                                         // 可以调用 /oauth/introspect 和 /oauth/token 的服务
+	IntrospectionCacheSeconds int64                  `protobuf:"varint,2,opt,name=introspection_cache_seconds,json=introspectionCacheSeconds,proto3" json:"introspection_cache_seconds,omitempty"` // 内省结果缓存时间，默认10秒
+	Issuer                    string                 `protobuf:"bytes,3,opt,name=issuer,proto3" json:"issuer,omitempty"`                                                                           // 内省结果中的 iss
+	TokenExchangeTtlSeconds   int64                  `protobuf:"varint,4,opt,name=token_exchange_ttl_seconds,json=tokenExchangeTtlSeconds,proto3" json:"token_exchange_ttl_seconds,omitempty"`     // 令牌交换签发的令牌有效期，默认300秒，不超过原令牌
+	unknownFields             protoimpl.UnknownFields
+	sizeCache                 protoimpl.SizeCache
+}
+
+func (x *OAuth) Reset() {
+	*x = OAuth{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OAuth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OAuth) ProtoMessage() {}
+
+func (x *OAuth) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OAuth.ProtoReflect.Descriptor instead.
+func (*OAuth) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *OAuth) GetClients() []*OAuth_Client {
+	if x != nil {
+		return x.Clients
+	}
+	return nil
+}
+
+func (x *OAuth) GetIntrospectionCacheSeconds() int64 {
+	if x != nil {
+		return x.IntrospectionCacheSeconds
+	}
+	return 0
+}
+
+func (x *OAuth) GetIssuer() string {
+	if x != nil {
+		return x.Issuer
+	}
+	return ""
+}
+
+func (x *OAuth) GetTokenExchangeTtlSeconds() int64 {
+	if x != nil {
+		return x.TokenExchangeTtlSeconds
+	}
+	return 0
+}
+
+// DPoP（RFC 9449）证明校验参数，客户端携带 DPoP 头时签发的令牌绑定到其密钥
+type DPoP struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	ProofMaxAgeSeconds int64                  `protobuf:"varint,1,opt,name=proof_max_age_seconds,json=proofMaxAgeSeconds,proto3" json:"proof_max_age_seconds,omitempty"` // 证明 iat 的最大时效，默认60秒
+	PublicUrl          string                 `protobuf:"bytes,2,opt,name=public_url,json=publicUrl,proto3" json:"public_url,omitempty"`                                 // 客户端访问的地址，如 https://api.example.com，用于校验 htu；为空时只校验路径
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *DPoP) Reset() {
+	*x = DPoP{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DPoP) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DPoP) ProtoMessage() {}
+
+func (x *DPoP) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DPoP.ProtoReflect.Descriptor instead.
+func (*DPoP) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *DPoP) GetProofMaxAgeSeconds() int64 {
+	if x != nil {
+		return x.ProofMaxAgeSeconds
+	}
+	return 0
+}
+
+func (x *DPoP) GetPublicUrl() string {
+	if x != nil {
+		return x.PublicUrl
+	}
+	return ""
+}
+
+// SCIM 2.0（RFC 7644）用户与组下发
+type Scim struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Clients       []*Scim_Client         `protobuf:"bytes,1,rep,name=clients,proto3" json:"clients,omitempty"`                          // 身份源等下发客户端，未配置时 SCIM 接口不可用
+	MaxResults    int32                  `protobuf:"varint,2,opt,name=max_results,json=maxResults,proto3" json:"max_results,omitempty"` // 列表每页最多返回的资源数，默认100
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Scim) Reset() {
+	*x = Scim{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Scim) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Scim) ProtoMessage() {}
+
+func (x *Scim) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Scim.ProtoReflect.Descriptor instead.
+func (*Scim) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Scim) GetClients() []*Scim_Client {
+	if x != nil {
+		return x.Clients
+	}
+	return nil
+}
+
+func (x *Scim) GetMaxResults() int32 {
+	if x != nil {
+		return x.MaxResults
+	}
+	return 0
+}
+
+// 外部 OIDC 身份提供方登录
+type Federation struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Providers       []*Federation_Provider `protobuf:"bytes,1,rep,name=providers,proto3" json:"providers,omitempty"`
+	StateTtlSeconds int64                  `protobuf:"varint,2,opt,name=state_ttl_seconds,json=stateTtlSeconds,proto3" json:"state_ttl_seconds,omitempty"` // 从跳转 IdP 到回调完成的最长时间，默认600秒
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Federation) Reset() {
+	*x = Federation{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Federation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Federation) ProtoMessage() {}
+
+func (x *Federation) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Federation.ProtoReflect.Descriptor instead.
+func (*Federation) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Federation) GetProviders() []*Federation_Provider {
+	if x != nil {
+		return x.Providers
+	}
+	return nil
+}
+
+func (x *Federation) GetStateTtlSeconds() int64 {
+	if x != nil {
+		return x.StateTtlSeconds
+	}
+	return 0
+}
+
+// 登录凭证的校验后端，未配置时校验 users.password_hash
+type Credential struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Backends      []*Credential_Backend  `protobuf:"bytes,1,rep,name=backends,proto3" json:"backends,omitempty"`
+	Tenants       []*Credential_Tenant   `protobuf:"bytes,2,rep,name=tenants,proto3" json:"tenants,omitempty"` // 未列出的租户使用本地凭证
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Credential) Reset() {
+	*x = Credential{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Credential) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Credential) ProtoMessage() {}
+
+func (x *Credential) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Credential.ProtoReflect.Descriptor instead.
+func (*Credential) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Credential) GetBackends() []*Credential_Backend {
+	if x != nil {
+		return x.Backends
+	}
+	return nil
+}
+
+func (x *Credential) GetTenants() []*Credential_Tenant {
+	if x != nil {
+		return x.Tenants
+	}
+	return nil
+}
+
+type Discovery struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Consul        *Discovery_Consul      `protobuf:"bytes,1,opt,name=consul,proto3" json:"consul,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Discovery) Reset() {
+	*x = Discovery{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Discovery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Discovery) ProtoMessage() {}
+
+func (x *Discovery) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Discovery.ProtoReflect.Descriptor instead.
+func (*Discovery) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Discovery) GetConsul() *Discovery_Consul {
+	if x != nil {
+		return x.Consul
+	}
+	return nil
+}
+
+type Server_HTTP struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Addr    string                 `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
+	Timeout int64                  `protobuf:"varint,2,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	// 反向代理的地址或网段（CIDR），只有来自这些地址的请求才使用 X-Forwarded-For 等请求头，为空时全部忽略
+	TrustedProxies []string `protobuf:"bytes,3,rep,name=trusted_proxies,json=trustedProxies,proto3" json:"trusted_proxies,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Server_HTTP) Reset() {
+	*x = Server_HTTP{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_HTTP) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_HTTP) ProtoMessage() {}
+
+func (x *Server_HTTP) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_HTTP.ProtoReflect.Descriptor instead.
+func (*Server_HTTP) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{1, 0}
+}
+
+func (x *Server_HTTP) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *Server_HTTP) GetTimeout() int64 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
+func (x *Server_HTTP) GetTrustedProxies() []string {
+	if x != nil {
+		return x.TrustedProxies
+	}
+	return nil
+}
+
+type Data_Database struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Host                 string                 `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	Port                 int32                  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	User                 string                 `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`
+	Password             string                 `protobuf:"bytes,4,opt,name=password,proto3" json:"password,omitempty"`
+	DbName               string                 `protobuf:"bytes,5,opt,name=db_name,json=dbName,proto3" json:"db_name,omitempty"`
+	SslMode              string                 `protobuf:"bytes,6,opt,name=ssl_mode,json=sslMode,proto3" json:"ssl_mode,omitempty"`
+	Timezone             string                 `protobuf:"bytes,7,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	Pool                 *Data_DatabasePool     `protobuf:"bytes,8,opt,name=pool,proto3" json:"pool,omitempty"`
+	AutoMigrate          bool                   `protobuf:"varint,9,opt,name=auto_migrate,json=autoMigrate,proto3" json:"auto_migrate,omitempty"`                               // 启动时执行未应用的迁移，多副本时只有一个副本执行
+	Dsn                  string                 `protobuf:"bytes,10,opt,name=dsn,proto3" json:"dsn,omitempty"`                                                                  // 完整的连接串，设置后忽略 host、port 等字段
+	Replicas             []*Data_Replica        `protobuf:"bytes,11,rep,name=replicas,proto3" json:"replicas,omitempty"`                                                        // 只读副本，只读的 sqlc 查询发送到健康的副本
+	ReplicaCheckInterval int64                  `protobuf:"varint,12,opt,name=replica_check_interval,json=replicaCheckInterval,proto3" json:"replica_check_interval,omitempty"` // 副本健康检查间隔（秒），默认 10
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *Data_Database) Reset() {
+	*x = Data_Database{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_Database) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_Database) ProtoMessage() {}
+
+func (x *Data_Database) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_Database.ProtoReflect.Descriptor instead.
+func (*Data_Database) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{2, 0}
+}
+
+func (x *Data_Database) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *Data_Database) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *Data_Database) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *Data_Database) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *Data_Database) GetDbName() string {
+	if x != nil {
+		return x.DbName
+	}
+	return ""
+}
+
+func (x *Data_Database) GetSslMode() string {
+	if x != nil {
+		return x.SslMode
+	}
+	return ""
+}
+
+func (x *Data_Database) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *Data_Database) GetPool() *Data_DatabasePool {
+	if x != nil {
+		return x.Pool
+	}
+	return nil
+}
+
+func (x *Data_Database) GetAutoMigrate() bool {
+	if x != nil {
+		return x.AutoMigrate
+	}
+	return false
+}
+
+func (x *Data_Database) GetDsn() string {
+	if x != nil {
+		return x.Dsn
+	}
+	return ""
+}
+
+func (x *Data_Database) GetReplicas() []*Data_Replica {
+	if x != nil {
+		return x.Replicas
+	}
+	return nil
+}
+
+func (x *Data_Database) GetReplicaCheckInterval() int64 {
+	if x != nil {
+		return x.ReplicaCheckInterval
+	}
+	return 0
+}
+
+type Data_Replica struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Host          string                 `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"` // 与主库使用相同的用户、密码和数据库
+	Port          int32                  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	Dsn           string                 `protobuf:"bytes,3,opt,name=dsn,proto3" json:"dsn,omitempty"` // 完整的连接串，设置后忽略 host 和 port
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Data_Replica) Reset() {
+	*x = Data_Replica{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_Replica) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_Replica) ProtoMessage() {}
+
+func (x *Data_Replica) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_Replica.ProtoReflect.Descriptor instead.
+func (*Data_Replica) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{2, 1}
+}
+
+func (x *Data_Replica) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *Data_Replica) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *Data_Replica) GetDsn() string {
+	if x != nil {
+		return x.Dsn
+	}
+	return ""
+}
+
+type Data_DatabasePool struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	MaxConns        int32                  `protobuf:"varint,1,opt,name=max_conns,json=maxConns,proto3" json:"max_conns,omitempty"`
+	MinConns        int32                  `protobuf:"varint,2,opt,name=min_conns,json=minConns,proto3" json:"min_conns,omitempty"`
+	MaxConnLifetime int64                  `protobuf:"varint,3,opt,name=max_conn_lifetime,json=maxConnLifetime,proto3" json:"max_conn_lifetime,omitempty"`
+	MaxConnIdleTime int64                  `protobuf:"varint,4,opt,name=max_conn_idle_time,json=maxConnIdleTime,proto3" json:"max_conn_idle_time,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Data_DatabasePool) Reset() {
+	*x = Data_DatabasePool{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_DatabasePool) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_DatabasePool) ProtoMessage() {}
+
+func (x *Data_DatabasePool) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_DatabasePool.ProtoReflect.Descriptor instead.
+func (*Data_DatabasePool) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{2, 2}
+}
+
+func (x *Data_DatabasePool) GetMaxConns() int32 {
+	if x != nil {
+		return x.MaxConns
+	}
+	return 0
+}
+
+func (x *Data_DatabasePool) GetMinConns() int32 {
+	if x != nil {
+		return x.MinConns
+	}
+	return 0
+}
+
+func (x *Data_DatabasePool) GetMaxConnLifetime() int64 {
+	if x != nil {
+		return x.MaxConnLifetime
+	}
+	return 0
+}
+
+func (x *Data_DatabasePool) GetMaxConnIdleTime() int64 {
+	if x != nil {
+		return x.MaxConnIdleTime
+	}
+	return 0
+}
+
+type Data_Redis struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Host             string                 `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	Port             int32                  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	Username         string                 `protobuf:"bytes,3,opt,name=username,proto3" json:"username,omitempty"`
+	Password         string                 `protobuf:"bytes,4,opt,name=password,proto3" json:"password,omitempty"`
+	Db               int32                  `protobuf:"varint,5,opt,name=db,proto3" json:"db,omitempty"`
+	DialTimeout      int64                  `protobuf:"varint,6,opt,name=dial_timeout,json=dialTimeout,proto3" json:"dial_timeout,omitempty"`
+	ReadTimeout      int64                  `protobuf:"varint,7,opt,name=read_timeout,json=readTimeout,proto3" json:"read_timeout,omitempty"`
+	WriteTimeout     int64                  `protobuf:"varint,8,opt,name=write_timeout,json=writeTimeout,proto3" json:"write_timeout,omitempty"`
+	PoolSize         int32                  `protobuf:"varint,9,opt,name=pool_size,json=poolSize,proto3" json:"pool_size,omitempty"`
+	MinIdleConns     int32                  `protobuf:"varint,10,opt,name=min_idle_conns,json=minIdleConns,proto3" json:"min_idle_conns,omitempty"`
+	Mode             string                 `protobuf:"bytes,11,opt,name=mode,proto3" json:"mode,omitempty"`                                                 // single（默认）、sentinel 或 cluster
+	MasterName       string                 `protobuf:"bytes,12,opt,name=master_name,json=masterName,proto3" json:"master_name,omitempty"`                   // sentinel 模式下的主节点名称
+	Addrs            []string               `protobuf:"bytes,13,rep,name=addrs,proto3" json:"addrs,omitempty"`                                               // sentinel 地址或 cluster 种子节点，为空时使用 host:port
+	SentinelPassword string                 `protobuf:"bytes,14,opt,name=sentinel_password,json=sentinelPassword,proto3" json:"sentinel_password,omitempty"` // sentinel 自身的密码，为空时不认证
+	Tls              *Data_RedisTLS         `protobuf:"bytes,15,opt,name=tls,proto3" json:"tls,omitempty"`
+	KeyPrefix        string                 `protobuf:"bytes,16,opt,name=key_prefix,json=keyPrefix,proto3" json:"key_prefix,omitempty"` // 所有键的前缀，用于多个环境共用一个 Redis，pub/sub 频道不加前缀
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Data_Redis) Reset() {
+	*x = Data_Redis{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_Redis) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_Redis) ProtoMessage() {}
+
+func (x *Data_Redis) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_Redis.ProtoReflect.Descriptor instead.
+func (*Data_Redis) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{2, 3}
+}
+
+func (x *Data_Redis) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *Data_Redis) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *Data_Redis) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *Data_Redis) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *Data_Redis) GetDb() int32 {
+	if x != nil {
+		return x.Db
+	}
+	return 0
+}
+
+func (x *Data_Redis) GetDialTimeout() int64 {
+	if x != nil {
+		return x.DialTimeout
+	}
+	return 0
+}
+
+func (x *Data_Redis) GetReadTimeout() int64 {
+	if x != nil {
+		return x.ReadTimeout
+	}
+	return 0
+}
+
+func (x *Data_Redis) GetWriteTimeout() int64 {
+	if x != nil {
+		return x.WriteTimeout
+	}
+	return 0
+}
+
+func (x *Data_Redis) GetPoolSize() int32 {
+	if x != nil {
+		return x.PoolSize
+	}
+	return 0
+}
+
+func (x *Data_Redis) GetMinIdleConns() int32 {
+	if x != nil {
+		return x.MinIdleConns
+	}
+	return 0
+}
+
+func (x *Data_Redis) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *Data_Redis) GetMasterName() string {
+	if x != nil {
+		return x.MasterName
+	}
+	return ""
+}
+
+func (x *Data_Redis) GetAddrs() []string {
+	if x != nil {
+		return x.Addrs
+	}
+	return nil
+}
+
+func (x *Data_Redis) GetSentinelPassword() string {
+	if x != nil {
+		return x.SentinelPassword
+	}
+	return ""
+}
+
+func (x *Data_Redis) GetTls() *Data_RedisTLS {
+	if x != nil {
+		return x.Tls
+	}
+	return nil
+}
+
+func (x *Data_Redis) GetKeyPrefix() string {
+	if x != nil {
+		return x.KeyPrefix
+	}
+	return ""
+}
+
+type Data_RedisTLS struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Enabled            bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	CaFile             string                 `protobuf:"bytes,2,opt,name=ca_file,json=caFile,proto3" json:"ca_file,omitempty"`       // 为空时使用系统根证书
+	CertFile           string                 `protobuf:"bytes,3,opt,name=cert_file,json=certFile,proto3" json:"cert_file,omitempty"` // 客户端证书，服务器要求双向认证时配置
+	KeyFile            string                 `protobuf:"bytes,4,opt,name=key_file,json=keyFile,proto3" json:"key_file,omitempty"`
+	ServerName         string                 `protobuf:"bytes,5,opt,name=server_name,json=serverName,proto3" json:"server_name,omitempty"`                            // 为空时使用连接地址中的主机名
+	InsecureSkipVerify bool                   `protobuf:"varint,6,opt,name=insecure_skip_verify,json=insecureSkipVerify,proto3" json:"insecure_skip_verify,omitempty"` // 不校验证书，仅用于测试环境
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Data_RedisTLS) Reset() {
+	*x = Data_RedisTLS{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_RedisTLS) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_RedisTLS) ProtoMessage() {}
+
+func (x *Data_RedisTLS) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_RedisTLS.ProtoReflect.Descriptor instead.
+func (*Data_RedisTLS) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{2, 4}
+}
+
+func (x *Data_RedisTLS) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *Data_RedisTLS) GetCaFile() string {
+	if x != nil {
+		return x.CaFile
+	}
+	return ""
+}
+
+func (x *Data_RedisTLS) GetCertFile() string {
+	if x != nil {
+		return x.CertFile
+	}
+	return ""
+}
+
+func (x *Data_RedisTLS) GetKeyFile() string {
+	if x != nil {
+		return x.KeyFile
+	}
+	return ""
+}
+
+func (x *Data_RedisTLS) GetServerName() string {
+	if x != nil {
+		return x.ServerName
+	}
+	return ""
+}
+
+func (x *Data_RedisTLS) GetInsecureSkipVerify() bool {
+	if x != nil {
+		return x.InsecureSkipVerify
+	}
+	return false
+}
+
+type Data_Sqlite struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"` // 数据库文件，默认 data/dev.db
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Data_Sqlite) Reset() {
+	*x = Data_Sqlite{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_Sqlite) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_Sqlite) ProtoMessage() {}
+
+func (x *Data_Sqlite) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_Sqlite.ProtoReflect.Descriptor instead.
+func (*Data_Sqlite) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{2, 5}
+}
+
+func (x *Data_Sqlite) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+// 登录挑战等短期状态的存储，优先写入 Redis，Redis 不可用时由熔断器切换到备用存储
+type Data_StateStore struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// memory（默认）、postgres 或 none；memory 只在当前进程内有效，多副本部署时应使用 postgres；
+	// none 表示不降级，Redis 不可用时启动失败
+	Fallback         string `protobuf:"bytes,1,opt,name=fallback,proto3" json:"fallback,omitempty"`
+	FailureThreshold int32  `protobuf:"varint,2,opt,name=failure_threshold,json=failureThreshold,proto3" json:"failure_threshold,omitempty"` // 连续失败多少次后切换到备用存储，默认5
+	OpenSeconds      int64  `protobuf:"varint,3,opt,name=open_seconds,json=openSeconds,proto3" json:"open_seconds,omitempty"`                // 切换后多久重新尝试 Redis，默认30秒
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Data_StateStore) Reset() {
+	*x = Data_StateStore{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_StateStore) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_StateStore) ProtoMessage() {}
+
+func (x *Data_StateStore) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_StateStore.ProtoReflect.Descriptor instead.
+func (*Data_StateStore) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{2, 6}
+}
+
+func (x *Data_StateStore) GetFallback() string {
+	if x != nil {
+		return x.Fallback
+	}
+	return ""
+}
+
+func (x *Data_StateStore) GetFailureThreshold() int32 {
+	if x != nil {
+		return x.FailureThreshold
+	}
+	return 0
+}
+
+func (x *Data_StateStore) GetOpenSeconds() int64 {
+	if x != nil {
+		return x.OpenSeconds
+	}
+	return 0
+}
+
+// 按用户名查找用户的缓存，进程内 LRU 加 Redis 两级，用户变更时通过 Redis pub/sub 通知所有副本清除
+type Data_UserCache struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Enabled       bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Size          int32                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`                               // 进程内最多缓存的用户数，默认10000
+	TtlSeconds    int64                  `protobuf:"varint,3,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"` // 缓存时间，也是副本错过清除通知时读到旧数据的最长时间，默认60秒
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Data_UserCache) Reset() {
+	*x = Data_UserCache{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_UserCache) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_UserCache) ProtoMessage() {}
+
+func (x *Data_UserCache) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_UserCache.ProtoReflect.Descriptor instead.
+func (*Data_UserCache) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{2, 7}
+}
+
+func (x *Data_UserCache) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *Data_UserCache) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Data_UserCache) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+type Mail_SMTP struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Host          string                 `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	Port          int32                  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	Username      string                 `protobuf:"bytes,3,opt,name=username,proto3" json:"username,omitempty"`
+	Password      string                 `protobuf:"bytes,4,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Mail_SMTP) Reset() {
+	*x = Mail_SMTP{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Mail_SMTP) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Mail_SMTP) ProtoMessage() {}
+
+func (x *Mail_SMTP) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Mail_SMTP.ProtoReflect.Descriptor instead.
+func (*Mail_SMTP) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{5, 0}
+}
+
+func (x *Mail_SMTP) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *Mail_SMTP) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *Mail_SMTP) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *Mail_SMTP) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type ClientKdf_Profile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       int32                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	MemoryKib     uint32                 `protobuf:"varint,2,opt,name=memory_kib,json=memoryKib,proto3" json:"memory_kib,omitempty"`
+	Iterations    uint32                 `protobuf:"varint,3,opt,name=iterations,proto3" json:"iterations,omitempty"`
+	Parallelism   uint32                 `protobuf:"varint,4,opt,name=parallelism,proto3" json:"parallelism,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClientKdf_Profile) Reset() {
+	*x = ClientKdf_Profile{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClientKdf_Profile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientKdf_Profile) ProtoMessage() {}
+
+func (x *ClientKdf_Profile) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientKdf_Profile.ProtoReflect.Descriptor instead.
+func (*ClientKdf_Profile) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{9, 0}
+}
+
+func (x *ClientKdf_Profile) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ClientKdf_Profile) GetMemoryKib() uint32 {
+	if x != nil {
+		return x.MemoryKib
+	}
+	return 0
+}
+
+func (x *ClientKdf_Profile) GetIterations() uint32 {
+	if x != nil {
+		return x.Iterations
+	}
+	return 0
+}
+
+func (x *ClientKdf_Profile) GetParallelism() uint32 {
+	if x != nil {
+		return x.Parallelism
+	}
+	return 0
+}
+
+type Events_Nats struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Url             string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"` // 默认 nats://127.0.0.1:4222
+	CredentialsFile string                 `protobuf:"bytes,2,opt,name=credentials_file,json=credentialsFile,proto3" json:"credentials_file,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Events_Nats) Reset() {
+	*x = Events_Nats{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Events_Nats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Events_Nats) ProtoMessage() {}
+
+func (x *Events_Nats) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Events_Nats.ProtoReflect.Descriptor instead.
+func (*Events_Nats) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{12, 0}
+}
+
+func (x *Events_Nats) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Events_Nats) GetCredentialsFile() string {
+	if x != nil {
+		return x.CredentialsFile
+	}
+	return ""
+}
+
+type Events_Kafka struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Brokers       []string               `protobuf:"bytes,1,rep,name=brokers,proto3" json:"brokers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Events_Kafka) Reset() {
+	*x = Events_Kafka{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Events_Kafka) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Events_Kafka) ProtoMessage() {}
+
+func (x *Events_Kafka) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Events_Kafka.ProtoReflect.Descriptor instead.
+func (*Events_Kafka) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{12, 1}
+}
+
+func (x *Events_Kafka) GetBrokers() []string {
+	if x != nil {
+		return x.Brokers
+	}
+	return nil
+}
+
+type Events_RedisStream struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MaxLen        int64                  `protobuf:"varint,1,opt,name=max_len,json=maxLen,proto3" json:"max_len,omitempty"` // 流的近似最大长度，默认100000，0 以下表示不限制
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Events_RedisStream) Reset() {
+	*x = Events_RedisStream{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Events_RedisStream) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Events_RedisStream) ProtoMessage() {}
+
+func (x *Events_RedisStream) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Events_RedisStream.ProtoReflect.Descriptor instead.
+func (*Events_RedisStream) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{12, 2}
+}
+
+func (x *Events_RedisStream) GetMaxLen() int64 {
+	if x != nil {
+		return x.MaxLen
+	}
+	return 0
+}
+
+type OAuth_Client struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	ClientId         string                 `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	ClientSecret     string                 `protobuf:"bytes,2,opt,name=client_secret,json=clientSecret,proto3" json:"client_secret,omitempty"`
+	AllowedAudiences []string               `protobuf:"bytes,3,rep,name=allowed_audiences,json=allowedAudiences,proto3" json:"allowed_audiences,omitempty"` // 令牌交换时可以申请的目标服务
+	AllowedScopes    []string               `protobuf:"bytes,4,rep,name=allowed_scopes,json=allowedScopes,proto3" json:"allowed_scopes,omitempty"`          // 令牌交换时可以申请的 scope
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
-func (x *Discovery) Reset() {
-	*x = Discovery{}
-	mi := &file_internal_conf_v1_conf_proto_msgTypes[5]
+func (x *OAuth_Client) Reset() {
+	*x = OAuth_Client{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Discovery) String() string {
+func (x *OAuth_Client) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Discovery) ProtoMessage() {}
+func (*OAuth_Client) ProtoMessage() {}
 
-func (x *Discovery) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_conf_v1_conf_proto_msgTypes[5]
+func (x *OAuth_Client) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -338,41 +2762,63 @@ func (x *Discovery) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Discovery.ProtoReflect.Descriptor instead.
-func (*Discovery) Descriptor() ([]byte, []int) {
-	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use OAuth_Client.ProtoReflect.Descriptor instead.
+func (*OAuth_Client) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{15, 0}
 }
 
-func (x *Discovery) GetConsul() *Discovery_Consul {
+func (x *OAuth_Client) GetClientId() string {
 	if x != nil {
-		return x.Consul
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *OAuth_Client) GetClientSecret() string {
+	if x != nil {
+		return x.ClientSecret
+	}
+	return ""
+}
+
+func (x *OAuth_Client) GetAllowedAudiences() []string {
+	if x != nil {
+		return x.AllowedAudiences
 	}
 	return nil
 }
 
-type Server_HTTP struct {
+func (x *OAuth_Client) GetAllowedScopes() []string {
+	if x != nil {
+		return x.AllowedScopes
+	}
+	return nil
+}
+
+type Scim_Client struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Addr          string                 `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
-	Timeout       int64                  `protobuf:"varint,2,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Token         string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`                        // 客户端调用时携带的 Bearer 令牌
+	TenantId      int64                  `protobuf:"varint,3,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"` // 下发到的租户，默认为1
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Server_HTTP) Reset() {
-	*x = Server_HTTP{}
-	mi := &file_internal_conf_v1_conf_proto_msgTypes[6]
+func (x *Scim_Client) Reset() {
+	*x = Scim_Client{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Server_HTTP) String() string {
+func (x *Scim_Client) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Server_HTTP) ProtoMessage() {}
+func (*Scim_Client) ProtoMessage() {}
 
-func (x *Server_HTTP) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_conf_v1_conf_proto_msgTypes[6]
+func (x *Scim_Client) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -383,54 +2829,62 @@ func (x *Server_HTTP) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Server_HTTP.ProtoReflect.Descriptor instead.
-func (*Server_HTTP) Descriptor() ([]byte, []int) {
-	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{1, 0}
+// Deprecated: Use Scim_Client.ProtoReflect.Descriptor instead.
+func (*Scim_Client) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{17, 0}
 }
 
-func (x *Server_HTTP) GetAddr() string {
+func (x *Scim_Client) GetName() string {
 	if x != nil {
-		return x.Addr
+		return x.Name
 	}
 	return ""
 }
 
-func (x *Server_HTTP) GetTimeout() int64 {
+func (x *Scim_Client) GetToken() string {
 	if x != nil {
-		return x.Timeout
+		return x.Token
+	}
+	return ""
+}
+
+func (x *Scim_Client) GetTenantId() int64 {
+	if x != nil {
+		return x.TenantId
 	}
 	return 0
 }
 
-type Data_Database struct {
+type Federation_Provider struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Host          string                 `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
-	Port          int32                  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
-	User          string                 `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`
-	Password      string                 `protobuf:"bytes,4,opt,name=password,proto3" json:"password,omitempty"`
-	DbName        string                 `protobuf:"bytes,5,opt,name=db_name,json=dbName,proto3" json:"db_name,omitempty"`
-	SslMode       string                 `protobuf:"bytes,6,opt,name=ssl_mode,json=sslMode,proto3" json:"ssl_mode,omitempty"`
-	Timezone      string                 `protobuf:"bytes,7,opt,name=timezone,proto3" json:"timezone,omitempty"`
-	Pool          *Data_DatabasePool     `protobuf:"bytes,8,opt,name=pool,proto3" json:"pool,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // 提供方标识，保存在 user_identities 中，修改后已关联的身份失效
+	DisplayName   string                 `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	Issuer        string                 `protobuf:"bytes,3,opt,name=issuer,proto3" json:"issuer,omitempty"` // 通过 issuer/.well-known/openid-configuration 发现端点
+	ClientId      string                 `protobuf:"bytes,4,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	ClientSecret  string                 `protobuf:"bytes,5,opt,name=client_secret,json=clientSecret,proto3" json:"client_secret,omitempty"`
+	Scopes        []string               `protobuf:"bytes,6,rep,name=scopes,proto3" json:"scopes,omitempty"`                                     // 默认 openid email profile
+	RedirectUrl   string                 `protobuf:"bytes,7,opt,name=redirect_url,json=redirectUrl,proto3" json:"redirect_url,omitempty"`        // 前端回调地址，需在 IdP 登记
+	LinkByEmail   bool                   `protobuf:"varint,8,opt,name=link_by_email,json=linkByEmail,proto3" json:"link_by_email,omitempty"`     // 首次登录时按 IdP 已验证的邮箱关联已有用户，本地邮箱也须已验证（免密登录、注册邮箱票据、SCIM 或目录服务）
+	AutoProvision bool                   `protobuf:"varint,9,opt,name=auto_provision,json=autoProvision,proto3" json:"auto_provision,omitempty"` // 首次登录且没有可关联的用户时自动创建用户
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Data_Database) Reset() {
-	*x = Data_Database{}
-	mi := &file_internal_conf_v1_conf_proto_msgTypes[7]
+func (x *Federation_Provider) Reset() {
+	*x = Federation_Provider{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Data_Database) String() string {
+func (x *Federation_Provider) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Data_Database) ProtoMessage() {}
+func (*Federation_Provider) ProtoMessage() {}
 
-func (x *Data_Database) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_conf_v1_conf_proto_msgTypes[7]
+func (x *Federation_Provider) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -441,92 +2895,107 @@ func (x *Data_Database) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Data_Database.ProtoReflect.Descriptor instead.
-func (*Data_Database) Descriptor() ([]byte, []int) {
-	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{2, 0}
+// Deprecated: Use Federation_Provider.ProtoReflect.Descriptor instead.
+func (*Federation_Provider) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{18, 0}
 }
 
-func (x *Data_Database) GetHost() string {
+func (x *Federation_Provider) GetId() string {
 	if x != nil {
-		return x.Host
+		return x.Id
 	}
 	return ""
 }
 
-func (x *Data_Database) GetPort() int32 {
+func (x *Federation_Provider) GetDisplayName() string {
 	if x != nil {
-		return x.Port
+		return x.DisplayName
 	}
-	return 0
+	return ""
 }
 
-func (x *Data_Database) GetUser() string {
+func (x *Federation_Provider) GetIssuer() string {
 	if x != nil {
-		return x.User
+		return x.Issuer
 	}
 	return ""
 }
 
-func (x *Data_Database) GetPassword() string {
+func (x *Federation_Provider) GetClientId() string {
 	if x != nil {
-		return x.Password
+		return x.ClientId
 	}
 	return ""
 }
 
-func (x *Data_Database) GetDbName() string {
+func (x *Federation_Provider) GetClientSecret() string {
 	if x != nil {
-		return x.DbName
+		return x.ClientSecret
 	}
 	return ""
 }
 
-func (x *Data_Database) GetSslMode() string {
+func (x *Federation_Provider) GetScopes() []string {
 	if x != nil {
-		return x.SslMode
+		return x.Scopes
 	}
-	return ""
+	return nil
 }
 
-func (x *Data_Database) GetTimezone() string {
+func (x *Federation_Provider) GetRedirectUrl() string {
 	if x != nil {
-		return x.Timezone
+		return x.RedirectUrl
 	}
 	return ""
 }
 
-func (x *Data_Database) GetPool() *Data_DatabasePool {
+func (x *Federation_Provider) GetLinkByEmail() bool {
 	if x != nil {
-		return x.Pool
+		return x.LinkByEmail
 	}
-	return nil
+	return false
 }
 
-type Data_DatabasePool struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	MaxConns        int32                  `protobuf:"varint,1,opt,name=max_conns,json=maxConns,proto3" json:"max_conns,omitempty"`
-	MinConns        int32                  `protobuf:"varint,2,opt,name=min_conns,json=minConns,proto3" json:"min_conns,omitempty"`
-	MaxConnLifetime int64                  `protobuf:"varint,3,opt,name=max_conn_lifetime,json=maxConnLifetime,proto3" json:"max_conn_lifetime,omitempty"`
-	MaxConnIdleTime int64                  `protobuf:"varint,4,opt,name=max_conn_idle_time,json=maxConnIdleTime,proto3" json:"max_conn_idle_time,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+func (x *Federation_Provider) GetAutoProvision() bool {
+	if x != nil {
+		return x.AutoProvision
+	}
+	return false
 }
 
-func (x *Data_DatabasePool) Reset() {
-	*x = Data_DatabasePool{}
-	mi := &file_internal_conf_v1_conf_proto_msgTypes[8]
+type Credential_Ldap struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Url                  string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`                                                            // ldap://host:389 或 ldaps://host:636
+	StartTls             bool                   `protobuf:"varint,2,opt,name=start_tls,json=startTls,proto3" json:"start_tls,omitempty"`                                 // ldap:// 连接后升级为 TLS
+	InsecureSkipVerify   bool                   `protobuf:"varint,3,opt,name=insecure_skip_verify,json=insecureSkipVerify,proto3" json:"insecure_skip_verify,omitempty"` // 不校验证书，仅用于测试环境
+	BindDn               string                 `protobuf:"bytes,4,opt,name=bind_dn,json=bindDn,proto3" json:"bind_dn,omitempty"`                                        // 查找用户使用的服务账号，为空时匿名查找
+	BindPassword         string                 `protobuf:"bytes,5,opt,name=bind_password,json=bindPassword,proto3" json:"bind_password,omitempty"`
+	BaseDn               string                 `protobuf:"bytes,6,opt,name=base_dn,json=baseDn,proto3" json:"base_dn,omitempty"`
+	UserFilter           string                 `protobuf:"bytes,7,opt,name=user_filter,json=userFilter,proto3" json:"user_filter,omitempty"`                                 // {username} 替换为转义后的用户名，默认 (uid={username})
+	EmailAttribute       string                 `protobuf:"bytes,8,opt,name=email_attribute,json=emailAttribute,proto3" json:"email_attribute,omitempty"`                     // 默认 mail
+	DisplayNameAttribute string                 `protobuf:"bytes,9,opt,name=display_name_attribute,json=displayNameAttribute,proto3" json:"display_name_attribute,omitempty"` // 默认 displayName
+	GivenNameAttribute   string                 `protobuf:"bytes,10,opt,name=given_name_attribute,json=givenNameAttribute,proto3" json:"given_name_attribute,omitempty"`      // 默认 givenName
+	FamilyNameAttribute  string                 `protobuf:"bytes,11,opt,name=family_name_attribute,json=familyNameAttribute,proto3" json:"family_name_attribute,omitempty"`   // 默认 sn
+	TimeoutSeconds       int64                  `protobuf:"varint,12,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`                   // 连接和请求超时，默认5秒
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *Credential_Ldap) Reset() {
+	*x = Credential_Ldap{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Data_DatabasePool) String() string {
+func (x *Credential_Ldap) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Data_DatabasePool) ProtoMessage() {}
+func (*Credential_Ldap) ProtoMessage() {}
 
-func (x *Data_DatabasePool) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_conf_v1_conf_proto_msgTypes[8]
+func (x *Credential_Ldap) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -537,70 +3006,118 @@ func (x *Data_DatabasePool) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Data_DatabasePool.ProtoReflect.Descriptor instead.
-func (*Data_DatabasePool) Descriptor() ([]byte, []int) {
-	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{2, 1}
+// Deprecated: Use Credential_Ldap.ProtoReflect.Descriptor instead.
+func (*Credential_Ldap) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{19, 0}
 }
 
-func (x *Data_DatabasePool) GetMaxConns() int32 {
+func (x *Credential_Ldap) GetUrl() string {
 	if x != nil {
-		return x.MaxConns
+		return x.Url
 	}
-	return 0
+	return ""
 }
 
-func (x *Data_DatabasePool) GetMinConns() int32 {
+func (x *Credential_Ldap) GetStartTls() bool {
 	if x != nil {
-		return x.MinConns
+		return x.StartTls
 	}
-	return 0
+	return false
 }
 
-func (x *Data_DatabasePool) GetMaxConnLifetime() int64 {
+func (x *Credential_Ldap) GetInsecureSkipVerify() bool {
 	if x != nil {
-		return x.MaxConnLifetime
+		return x.InsecureSkipVerify
 	}
-	return 0
+	return false
 }
 
-func (x *Data_DatabasePool) GetMaxConnIdleTime() int64 {
+func (x *Credential_Ldap) GetBindDn() string {
 	if x != nil {
-		return x.MaxConnIdleTime
+		return x.BindDn
+	}
+	return ""
+}
+
+func (x *Credential_Ldap) GetBindPassword() string {
+	if x != nil {
+		return x.BindPassword
+	}
+	return ""
+}
+
+func (x *Credential_Ldap) GetBaseDn() string {
+	if x != nil {
+		return x.BaseDn
+	}
+	return ""
+}
+
+func (x *Credential_Ldap) GetUserFilter() string {
+	if x != nil {
+		return x.UserFilter
+	}
+	return ""
+}
+
+func (x *Credential_Ldap) GetEmailAttribute() string {
+	if x != nil {
+		return x.EmailAttribute
+	}
+	return ""
+}
+
+func (x *Credential_Ldap) GetDisplayNameAttribute() string {
+	if x != nil {
+		return x.DisplayNameAttribute
+	}
+	return ""
+}
+
+func (x *Credential_Ldap) GetGivenNameAttribute() string {
+	if x != nil {
+		return x.GivenNameAttribute
+	}
+	return ""
+}
+
+func (x *Credential_Ldap) GetFamilyNameAttribute() string {
+	if x != nil {
+		return x.FamilyNameAttribute
+	}
+	return ""
+}
+
+func (x *Credential_Ldap) GetTimeoutSeconds() int64 {
+	if x != nil {
+		return x.TimeoutSeconds
 	}
 	return 0
 }
 
-type Data_Redis struct {
+type Credential_Backend struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Host          string                 `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
-	Port          int32                  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
-	Username      string                 `protobuf:"bytes,3,opt,name=username,proto3" json:"username,omitempty"`
-	Password      string                 `protobuf:"bytes,4,opt,name=password,proto3" json:"password,omitempty"`
-	Db            int32                  `protobuf:"varint,5,opt,name=db,proto3" json:"db,omitempty"`
-	DialTimeout   int64                  `protobuf:"varint,6,opt,name=dial_timeout,json=dialTimeout,proto3" json:"dial_timeout,omitempty"`
-	ReadTimeout   int64                  `protobuf:"varint,7,opt,name=read_timeout,json=readTimeout,proto3" json:"read_timeout,omitempty"`
-	WriteTimeout  int64                  `protobuf:"varint,8,opt,name=write_timeout,json=writeTimeout,proto3" json:"write_timeout,omitempty"`
-	PoolSize      int32                  `protobuf:"varint,9,opt,name=pool_size,json=poolSize,proto3" json:"pool_size,omitempty"`
-	MinIdleConns  int32                  `protobuf:"varint,10,opt,name=min_idle_conns,json=minIdleConns,proto3" json:"min_idle_conns,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // 后端标识，保存在 users.credential_backend 中，local 为保留值
+	Ldap          *Credential_Ldap       `protobuf:"bytes,2,opt,name=ldap,proto3" json:"ldap,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Data_Redis) Reset() {
-	*x = Data_Redis{}
-	mi := &file_internal_conf_v1_conf_proto_msgTypes[9]
+func (x *Credential_Backend) Reset() {
+	*x = Credential_Backend{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Data_Redis) String() string {
+func (x *Credential_Backend) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Data_Redis) ProtoMessage() {}
+func (*Credential_Backend) ProtoMessage() {}
 
-func (x *Data_Redis) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_conf_v1_conf_proto_msgTypes[9]
+func (x *Credential_Backend) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -611,79 +3128,75 @@ func (x *Data_Redis) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Data_Redis.ProtoReflect.Descriptor instead.
-func (*Data_Redis) Descriptor() ([]byte, []int) {
-	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{2, 2}
+// Deprecated: Use Credential_Backend.ProtoReflect.Descriptor instead.
+func (*Credential_Backend) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{19, 1}
 }
 
-func (x *Data_Redis) GetHost() string {
+func (x *Credential_Backend) GetId() string {
 	if x != nil {
-		return x.Host
+		return x.Id
 	}
 	return ""
 }
 
-func (x *Data_Redis) GetPort() int32 {
+func (x *Credential_Backend) GetLdap() *Credential_Ldap {
 	if x != nil {
-		return x.Port
+		return x.Ldap
 	}
-	return 0
+	return nil
 }
 
-func (x *Data_Redis) GetUsername() string {
-	if x != nil {
-		return x.Username
-	}
-	return ""
+type Credential_Tenant struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TenantId      int64                  `protobuf:"varint,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Backend       string                 `protobuf:"bytes,2,opt,name=backend,proto3" json:"backend,omitempty"` // 租户默认后端，用户的 credential_backend 优先
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Data_Redis) GetPassword() string {
-	if x != nil {
-		return x.Password
-	}
-	return ""
+func (x *Credential_Tenant) Reset() {
+	*x = Credential_Tenant{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *Data_Redis) GetDb() int32 {
-	if x != nil {
-		return x.Db
-	}
-	return 0
+func (x *Credential_Tenant) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *Data_Redis) GetDialTimeout() int64 {
-	if x != nil {
-		return x.DialTimeout
-	}
-	return 0
-}
+func (*Credential_Tenant) ProtoMessage() {}
 
-func (x *Data_Redis) GetReadTimeout() int64 {
+func (x *Credential_Tenant) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[40]
 	if x != nil {
-		return x.ReadTimeout
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *Data_Redis) GetWriteTimeout() int64 {
-	if x != nil {
-		return x.WriteTimeout
-	}
-	return 0
+// Deprecated: Use Credential_Tenant.ProtoReflect.Descriptor instead.
+func (*Credential_Tenant) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{19, 2}
 }
 
-func (x *Data_Redis) GetPoolSize() int32 {
+func (x *Credential_Tenant) GetTenantId() int64 {
 	if x != nil {
-		return x.PoolSize
+		return x.TenantId
 	}
 	return 0
 }
 
-func (x *Data_Redis) GetMinIdleConns() int32 {
+func (x *Credential_Tenant) GetBackend() string {
 	if x != nil {
-		return x.MinIdleConns
+		return x.Backend
 	}
-	return 0
+	return ""
 }
 
 type Discovery_Consul struct {
@@ -697,7 +3210,7 @@ type Discovery_Consul struct {
 
 func (x *Discovery_Consul) Reset() {
 	*x = Discovery_Consul{}
-	mi := &file_internal_conf_v1_conf_proto_msgTypes[10]
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -709,7 +3222,7 @@ func (x *Discovery_Consul) String() string {
 func (*Discovery_Consul) ProtoMessage() {}
 
 func (x *Discovery_Consul) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_conf_v1_conf_proto_msgTypes[10]
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -722,7 +3235,7 @@ func (x *Discovery_Consul) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Discovery_Consul.ProtoReflect.Descriptor instead.
 func (*Discovery_Consul) Descriptor() ([]byte, []int) {
-	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{5, 0}
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{20, 0}
 }
 
 func (x *Discovery_Consul) GetAddr() string {
@@ -750,21 +3263,52 @@ var File_internal_conf_v1_conf_proto protoreflect.FileDescriptor
 
 const file_internal_conf_v1_conf_proto_rawDesc = "" +
 	"\n" +
-	"\x1binternal/conf/v1/conf.proto\x12\aconf.v1\"\xd2\x01\n" +
+	"\x1binternal/conf/v1/conf.proto\x12\aconf.v1\"\x88\a\n" +
 	"\tBootstrap\x12'\n" +
 	"\x06server\x18\x01 \x01(\v2\x0f.conf.v1.ServerR\x06server\x12!\n" +
 	"\x04data\x18\x02 \x01(\v2\r.conf.v1.DataR\x04data\x12!\n" +
 	"\x04auth\x18\x03 \x01(\v2\r.conf.v1.AuthR\x04auth\x12$\n" +
 	"\x05trace\x18\x04 \x01(\v2\x0e.conf.v1.TraceR\x05trace\x120\n" +
-	"\tdiscovery\x18\x05 \x01(\v2\x12.conf.v1.DiscoveryR\tdiscovery\"h\n" +
+	"\tdiscovery\x18\x05 \x01(\v2\x12.conf.v1.DiscoveryR\tdiscovery\x12!\n" +
+	"\x04mail\x18\x06 \x01(\v2\r.conf.v1.MailR\x04mail\x12*\n" +
+	"\atenancy\x18\a \x01(\v2\x10.conf.v1.TenancyR\atenancy\x129\n" +
+	"\fregistration\x18\b \x01(\v2\x15.conf.v1.RegistrationR\fregistration\x128\n" +
+	"\rproof_of_work\x18\t \x01(\v2\x14.conf.v1.ProofOfWorkR\vproofOfWork\x121\n" +
+	"\n" +
+	"client_kdf\x18\n" +
+	" \x01(\v2\x12.conf.v1.ClientKdfR\tclientKdf\x121\n" +
+	"\n" +
+	"login_risk\x18\v \x01(\v2\x12.conf.v1.LoginRiskR\tloginRisk\x12'\n" +
+	"\x06notify\x18\f \x01(\v2\x0f.conf.v1.NotifyR\x06notify\x12$\n" +
+	"\x05oauth\x18\r \x01(\v2\x0e.conf.v1.OAuthR\x05oauth\x12!\n" +
+	"\x04dpop\x18\x0e \x01(\v2\r.conf.v1.DPoPR\x04dpop\x12!\n" +
+	"\x04scim\x18\x0f \x01(\v2\r.conf.v1.ScimR\x04scim\x123\n" +
+	"\n" +
+	"federation\x18\x10 \x01(\v2\x13.conf.v1.FederationR\n" +
+	"federation\x123\n" +
+	"\n" +
+	"credential\x18\x11 \x01(\v2\x13.conf.v1.CredentialR\n" +
+	"credential\x12'\n" +
+	"\x06events\x18\x12 \x01(\v2\x0f.conf.v1.EventsR\x06events\x12-\n" +
+	"\bwebhooks\x18\x13 \x01(\v2\x11.conf.v1.WebhooksR\bwebhooks\x123\n" +
+	"\n" +
+	"encryption\x18\x14 \x01(\v2\x13.conf.v1.EncryptionR\n" +
+	"encryption\"\x91\x01\n" +
 	"\x06Server\x12(\n" +
-	"\x04http\x18\x01 \x01(\v2\x14.conf.v1.Server.HTTPR\x04http\x1a4\n" +
+	"\x04http\x18\x01 \x01(\v2\x14.conf.v1.Server.HTTPR\x04http\x1a]\n" +
 	"\x04HTTP\x12\x12\n" +
 	"\x04addr\x18\x01 \x01(\tR\x04addr\x12\x18\n" +
-	"\atimeout\x18\x02 \x01(\x03R\atimeout\"\x96\x06\n" +
+	"\atimeout\x18\x02 \x01(\x03R\atimeout\x12'\n" +
+	"\x0ftrusted_proxies\x18\x03 \x03(\tR\x0etrustedProxies\"\xb2\x0e\n" +
 	"\x04Data\x122\n" +
 	"\bdatabase\x18\x01 \x01(\v2\x16.conf.v1.Data.DatabaseR\bdatabase\x12)\n" +
-	"\x05redis\x18\x02 \x01(\v2\x13.conf.v1.Data.RedisR\x05redis\x1a\xe2\x01\n" +
+	"\x05redis\x18\x02 \x01(\v2\x13.conf.v1.Data.RedisR\x05redis\x12\x16\n" +
+	"\x06driver\x18\x03 \x01(\tR\x06driver\x12,\n" +
+	"\x06sqlite\x18\x04 \x01(\v2\x14.conf.v1.Data.SqliteR\x06sqlite\x129\n" +
+	"\vstate_store\x18\x05 \x01(\v2\x18.conf.v1.Data.StateStoreR\n" +
+	"stateStore\x126\n" +
+	"\n" +
+	"user_cache\x18\x06 \x01(\v2\x17.conf.v1.Data.UserCacheR\tuserCache\x1a\x80\x03\n" +
 	"\bDatabase\x12\x12\n" +
 	"\x04host\x18\x01 \x01(\tR\x04host\x12\x12\n" +
 	"\x04port\x18\x02 \x01(\x05R\x04port\x12\x12\n" +
@@ -773,12 +3317,21 @@ const file_internal_conf_v1_conf_proto_rawDesc = "" +
 	"\adb_name\x18\x05 \x01(\tR\x06dbName\x12\x19\n" +
 	"\bssl_mode\x18\x06 \x01(\tR\asslMode\x12\x1a\n" +
 	"\btimezone\x18\a \x01(\tR\btimezone\x12.\n" +
-	"\x04pool\x18\b \x01(\v2\x1a.conf.v1.Data.DatabasePoolR\x04pool\x1a\xa1\x01\n" +
+	"\x04pool\x18\b \x01(\v2\x1a.conf.v1.Data.DatabasePoolR\x04pool\x12!\n" +
+	"\fauto_migrate\x18\t \x01(\bR\vautoMigrate\x12\x10\n" +
+	"\x03dsn\x18\n" +
+	" \x01(\tR\x03dsn\x121\n" +
+	"\breplicas\x18\v \x03(\v2\x15.conf.v1.Data.ReplicaR\breplicas\x124\n" +
+	"\x16replica_check_interval\x18\f \x01(\x03R\x14replicaCheckInterval\x1aC\n" +
+	"\aReplica\x12\x12\n" +
+	"\x04host\x18\x01 \x01(\tR\x04host\x12\x12\n" +
+	"\x04port\x18\x02 \x01(\x05R\x04port\x12\x10\n" +
+	"\x03dsn\x18\x03 \x01(\tR\x03dsn\x1a\xa1\x01\n" +
 	"\fDatabasePool\x12\x1b\n" +
 	"\tmax_conns\x18\x01 \x01(\x05R\bmaxConns\x12\x1b\n" +
 	"\tmin_conns\x18\x02 \x01(\x05R\bminConns\x12*\n" +
 	"\x11max_conn_lifetime\x18\x03 \x01(\x03R\x0fmaxConnLifetime\x12+\n" +
-	"\x12max_conn_idle_time\x18\x04 \x01(\x03R\x0fmaxConnIdleTime\x1a\xa5\x02\n" +
+	"\x12max_conn_idle_time\x18\x04 \x01(\x03R\x0fmaxConnIdleTime\x1a\xe6\x03\n" +
 	"\x05Redis\x12\x12\n" +
 	"\x04host\x18\x01 \x01(\tR\x04host\x12\x12\n" +
 	"\x04port\x18\x02 \x01(\x05R\x04port\x12\x1a\n" +
@@ -790,15 +3343,212 @@ const file_internal_conf_v1_conf_proto_rawDesc = "" +
 	"\rwrite_timeout\x18\b \x01(\x03R\fwriteTimeout\x12\x1b\n" +
 	"\tpool_size\x18\t \x01(\x05R\bpoolSize\x12$\n" +
 	"\x0emin_idle_conns\x18\n" +
-	" \x01(\x05R\fminIdleConns\"\x8b\x01\n" +
+	" \x01(\x05R\fminIdleConns\x12\x12\n" +
+	"\x04mode\x18\v \x01(\tR\x04mode\x12\x1f\n" +
+	"\vmaster_name\x18\f \x01(\tR\n" +
+	"masterName\x12\x14\n" +
+	"\x05addrs\x18\r \x03(\tR\x05addrs\x12+\n" +
+	"\x11sentinel_password\x18\x0e \x01(\tR\x10sentinelPassword\x12(\n" +
+	"\x03tls\x18\x0f \x01(\v2\x16.conf.v1.Data.RedisTLSR\x03tls\x12\x1d\n" +
+	"\n" +
+	"key_prefix\x18\x10 \x01(\tR\tkeyPrefix\x1a\xc8\x01\n" +
+	"\bRedisTLS\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12\x17\n" +
+	"\aca_file\x18\x02 \x01(\tR\x06caFile\x12\x1b\n" +
+	"\tcert_file\x18\x03 \x01(\tR\bcertFile\x12\x19\n" +
+	"\bkey_file\x18\x04 \x01(\tR\akeyFile\x12\x1f\n" +
+	"\vserver_name\x18\x05 \x01(\tR\n" +
+	"serverName\x120\n" +
+	"\x14insecure_skip_verify\x18\x06 \x01(\bR\x12insecureSkipVerify\x1a\x1c\n" +
+	"\x06Sqlite\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x1ax\n" +
+	"\n" +
+	"StateStore\x12\x1a\n" +
+	"\bfallback\x18\x01 \x01(\tR\bfallback\x12+\n" +
+	"\x11failure_threshold\x18\x02 \x01(\x05R\x10failureThreshold\x12!\n" +
+	"\fopen_seconds\x18\x03 \x01(\x03R\vopenSeconds\x1aZ\n" +
+	"\tUserCache\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x05R\x04size\x12\x1f\n" +
+	"\vttl_seconds\x18\x03 \x01(\x03R\n" +
+	"ttlSeconds\"\x9a\x06\n" +
 	"\x04Auth\x12\x1d\n" +
 	"\n" +
 	"jwt_secret\x18\x01 \x01(\tR\tjwtSecret\x12(\n" +
 	"\x10jwt_expire_hours\x18\x02 \x01(\x03R\x0ejwtExpireHours\x12:\n" +
-	"\x19challenge_timeout_seconds\x18\x03 \x01(\x03R\x17challengeTimeoutSeconds\"?\n" +
+	"\x19challenge_timeout_seconds\x18\x03 \x01(\x03R\x17challengeTimeoutSeconds\x12?\n" +
+	"\x1cauth_request_timeout_seconds\x18\x04 \x01(\x03R\x19authRequestTimeoutSeconds\x12J\n" +
+	"\"cross_device_login_timeout_seconds\x18\x05 \x01(\x03R\x1ecrossDeviceLoginTimeoutSeconds\x127\n" +
+	"\x18cross_device_approve_url\x18\x06 \x01(\tR\x15crossDeviceApproveUrl\x12$\n" +
+	"\x0emagic_link_url\x18\a \x01(\tR\fmagicLinkUrl\x12;\n" +
+	"\x1amagic_link_timeout_seconds\x18\b \x01(\x03R\x17magicLinkTimeoutSeconds\x125\n" +
+	"\x17magic_link_max_requests\x18\t \x01(\x03R\x14magicLinkMaxRequests\x12B\n" +
+	"\x1emagic_link_rate_window_seconds\x18\n" +
+	" \x01(\x03R\x1amagicLinkRateWindowSeconds\x12+\n" +
+	"\x11credential_pepper\x18\v \x01(\tR\x10credentialPepper\x124\n" +
+	"\x16credential_pepper_file\x18\f \x01(\tR\x14credentialPepperFile\x12*\n" +
+	"\x11argon2_memory_kib\x18\r \x01(\rR\x0fargon2MemoryKib\x12+\n" +
+	"\x11argon2_iterations\x18\x0e \x01(\rR\x10argon2Iterations\x12-\n" +
+	"\x12argon2_parallelism\x18\x0f \x01(\rR\x11argon2Parallelism\"?\n" +
 	"\x05Trace\x12\x1a\n" +
 	"\bendpoint\x18\x01 \x01(\tR\bendpoint\x12\x1a\n" +
-	"\binsecure\x18\x02 \x01(\bR\binsecure\"\x97\x01\n" +
+	"\binsecure\x18\x02 \x01(\bR\binsecure\"\xdd\x01\n" +
+	"\x04Mail\x12\x16\n" +
+	"\x06driver\x18\x01 \x01(\tR\x06driver\x12\x12\n" +
+	"\x04from\x18\x02 \x01(\tR\x04from\x12\x19\n" +
+	"\bfile_dir\x18\x03 \x01(\tR\afileDir\x12&\n" +
+	"\x04smtp\x18\x04 \x01(\v2\x12.conf.v1.Mail.SMTPR\x04smtp\x1af\n" +
+	"\x04SMTP\x12\x12\n" +
+	"\x04host\x18\x01 \x01(\tR\x04host\x12\x12\n" +
+	"\x04port\x18\x02 \x01(\x05R\x04port\x12\x1a\n" +
+	"\busername\x18\x03 \x01(\tR\busername\x12\x1a\n" +
+	"\bpassword\x18\x04 \x01(\tR\bpassword\"\x93\x01\n" +
+	"\aTenancy\x12\x16\n" +
+	"\x06header\x18\x01 \x01(\tR\x06header\x12(\n" +
+	"\x10client_id_header\x18\x02 \x01(\tR\x0eclientIdHeader\x12\x1f\n" +
+	"\vbase_domain\x18\x03 \x01(\tR\n" +
+	"baseDomain\x12%\n" +
+	"\x0edefault_tenant\x18\x04 \x01(\tR\rdefaultTenant\"\xf9\x01\n" +
+	"\fRegistration\x12\x12\n" +
+	"\x04mode\x18\x01 \x01(\tR\x04mode\x12'\n" +
+	"\x0fallowed_domains\x18\x02 \x03(\tR\x0eallowedDomains\x12&\n" +
+	"\x0finvite_max_uses\x18\x03 \x01(\x05R\rinviteMaxUses\x12,\n" +
+	"\x12invite_ttl_seconds\x18\x04 \x01(\x03R\x10inviteTtlSeconds\x12\x1d\n" +
+	"\n" +
+	"verify_url\x18\x05 \x01(\tR\tverifyUrl\x127\n" +
+	"\x18email_ticket_ttl_seconds\x18\x06 \x01(\x03R\x15emailTicketTtlSeconds\"\xb3\x02\n" +
+	"\vProofOfWork\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12%\n" +
+	"\x0emin_difficulty\x18\x02 \x01(\x05R\rminDifficulty\x12%\n" +
+	"\x0emax_difficulty\x18\x03 \x01(\x05R\rmaxDifficulty\x12\x1f\n" +
+	"\vttl_seconds\x18\x04 \x01(\x03R\n" +
+	"ttlSeconds\x12.\n" +
+	"\x13rate_window_seconds\x18\x05 \x01(\x03R\x11rateWindowSeconds\x125\n" +
+	"\x16registration_threshold\x18\x06 \x01(\x03R\x15registrationThreshold\x124\n" +
+	"\x16failed_login_threshold\x18\a \x01(\x03R\x14failedLoginThreshold\"\x9f\x02\n" +
+	"\tClientKdf\x126\n" +
+	"\bprofiles\x18\x01 \x03(\v2\x1a.conf.v1.ClientKdf.ProfileR\bprofiles\x12%\n" +
+	"\x0erequire_ticket\x18\x02 \x01(\bR\rrequireTicket\x12,\n" +
+	"\x12ticket_ttl_seconds\x18\x03 \x01(\x03R\x10ticketTtlSeconds\x1a\x84\x01\n" +
+	"\aProfile\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x05R\aversion\x12\x1d\n" +
+	"\n" +
+	"memory_kib\x18\x02 \x01(\rR\tmemoryKib\x12\x1e\n" +
+	"\n" +
+	"iterations\x18\x03 \x01(\rR\n" +
+	"iterations\x12 \n" +
+	"\vparallelism\x18\x04 \x01(\rR\vparallelism\"\x91\x04\n" +
+	"\tLoginRisk\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12(\n" +
+	"\x10ipv4_prefix_bits\x18\x02 \x01(\x05R\x0eipv4PrefixBits\x12(\n" +
+	"\x10ipv6_prefix_bits\x18\x03 \x01(\x05R\x0eipv6PrefixBits\x12(\n" +
+	"\x10new_device_score\x18\x04 \x01(\x05R\x0enewDeviceScore\x12*\n" +
+	"\x11new_network_score\x18\x05 \x01(\x05R\x0fnewNetworkScore\x12,\n" +
+	"\x12unusual_hour_score\x18\x06 \x01(\x05R\x10unusualHourScore\x123\n" +
+	"\x16usual_hours_min_logins\x18\a \x01(\x05R\x13usualHoursMinLogins\x12!\n" +
+	"\fhistory_days\x18\b \x01(\x03R\vhistoryDays\x12*\n" +
+	"\x11step_up_threshold\x18\t \x01(\x05R\x0fstepUpThreshold\x12-\n" +
+	"\x13step_up_ttl_seconds\x18\n" +
+	" \x01(\x03R\x10stepUpTtlSeconds\x12/\n" +
+	"\x14step_up_max_attempts\x18\v \x01(\x05R\x11stepUpMaxAttempts\x12.\n" +
+	"\x13step_up_unreachable\x18\f \x01(\tR\x11stepUpUnreachable\" \n" +
+	"\x06Notify\x12\x16\n" +
+	"\x06driver\x18\x01 \x01(\tR\x06driver\"\xc2\x03\n" +
+	"\x06Events\x12\x16\n" +
+	"\x06broker\x18\x01 \x01(\tR\x06broker\x12\x14\n" +
+	"\x05topic\x18\x02 \x01(\tR\x05topic\x12(\n" +
+	"\x04nats\x18\x03 \x01(\v2\x14.conf.v1.Events.NatsR\x04nats\x12+\n" +
+	"\x05kafka\x18\x04 \x01(\v2\x15.conf.v1.Events.KafkaR\x05kafka\x121\n" +
+	"\x05redis\x18\x05 \x01(\v2\x1b.conf.v1.Events.RedisStreamR\x05redis\x12\x1d\n" +
+	"\n" +
+	"batch_size\x18\x06 \x01(\x05R\tbatchSize\x12(\n" +
+	"\x10poll_interval_ms\x18\a \x01(\x03R\x0epollIntervalMs\x12'\n" +
+	"\x0fretention_hours\x18\b \x01(\x03R\x0eretentionHours\x1aC\n" +
+	"\x04Nats\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12)\n" +
+	"\x10credentials_file\x18\x02 \x01(\tR\x0fcredentialsFile\x1a!\n" +
+	"\x05Kafka\x12\x18\n" +
+	"\abrokers\x18\x01 \x03(\tR\abrokers\x1a&\n" +
+	"\vRedisStream\x12\x17\n" +
+	"\amax_len\x18\x01 \x01(\x03R\x06maxLen\"\xe2\x02\n" +
+	"\bWebhooks\x12!\n" +
+	"\fmax_attempts\x18\x01 \x01(\x05R\vmaxAttempts\x126\n" +
+	"\x17initial_backoff_seconds\x18\x02 \x01(\x03R\x15initialBackoffSeconds\x12.\n" +
+	"\x13max_backoff_seconds\x18\x03 \x01(\x03R\x11maxBackoffSeconds\x12'\n" +
+	"\x0ftimeout_seconds\x18\x04 \x01(\x03R\x0etimeoutSeconds\x12\x1d\n" +
+	"\n" +
+	"batch_size\x18\x05 \x01(\x05R\tbatchSize\x12(\n" +
+	"\x10poll_interval_ms\x18\x06 \x01(\x03R\x0epollIntervalMs\x12.\n" +
+	"\x13allow_insecure_urls\x18\a \x01(\bR\x11allowInsecureUrls\x12)\n" +
+	"\x10allowed_networks\x18\b \x03(\tR\x0fallowedNetworks\"\xe7\x01\n" +
+	"\n" +
+	"Encryption\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x18\n" +
+	"\akeyfile\x18\x02 \x01(\tR\akeyfile\x123\n" +
+	"\x16data_key_rotation_days\x18\x03 \x01(\x03R\x13dataKeyRotationDays\x120\n" +
+	"\x14reencrypt_batch_size\x18\x04 \x01(\x05R\x12reencryptBatchSize\x12<\n" +
+	"\x1areencrypt_interval_seconds\x18\x05 \x01(\x03R\x18reencryptIntervalSeconds\"\xee\x02\n" +
+	"\x05OAuth\x12/\n" +
+	"\aclients\x18\x01 \x03(\v2\x15.conf.v1.OAuth.ClientR\aclients\x12>\n" +
+	"\x1bintrospection_cache_seconds\x18\x02 \x01(\x03R\x19introspectionCacheSeconds\x12\x16\n" +
+	"\x06issuer\x18\x03 \x01(\tR\x06issuer\x12;\n" +
+	"\x1atoken_exchange_ttl_seconds\x18\x04 \x01(\x03R\x17tokenExchangeTtlSeconds\x1a\x9e\x01\n" +
+	"\x06Client\x12\x1b\n" +
+	"\tclient_id\x18\x01 \x01(\tR\bclientId\x12#\n" +
+	"\rclient_secret\x18\x02 \x01(\tR\fclientSecret\x12+\n" +
+	"\x11allowed_audiences\x18\x03 \x03(\tR\x10allowedAudiences\x12%\n" +
+	"\x0eallowed_scopes\x18\x04 \x03(\tR\rallowedScopes\"X\n" +
+	"\x04DPoP\x121\n" +
+	"\x15proof_max_age_seconds\x18\x01 \x01(\x03R\x12proofMaxAgeSeconds\x12\x1d\n" +
+	"\n" +
+	"public_url\x18\x02 \x01(\tR\tpublicUrl\"\xa8\x01\n" +
+	"\x04Scim\x12.\n" +
+	"\aclients\x18\x01 \x03(\v2\x14.conf.v1.Scim.ClientR\aclients\x12\x1f\n" +
+	"\vmax_results\x18\x02 \x01(\x05R\n" +
+	"maxResults\x1aO\n" +
+	"\x06Client\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\x12\x1b\n" +
+	"\ttenant_id\x18\x03 \x01(\x03R\btenantId\"\x94\x03\n" +
+	"\n" +
+	"Federation\x12:\n" +
+	"\tproviders\x18\x01 \x03(\v2\x1c.conf.v1.Federation.ProviderR\tproviders\x12*\n" +
+	"\x11state_ttl_seconds\x18\x02 \x01(\x03R\x0fstateTtlSeconds\x1a\x9d\x02\n" +
+	"\bProvider\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
+	"\fdisplay_name\x18\x02 \x01(\tR\vdisplayName\x12\x16\n" +
+	"\x06issuer\x18\x03 \x01(\tR\x06issuer\x12\x1b\n" +
+	"\tclient_id\x18\x04 \x01(\tR\bclientId\x12#\n" +
+	"\rclient_secret\x18\x05 \x01(\tR\fclientSecret\x12\x16\n" +
+	"\x06scopes\x18\x06 \x03(\tR\x06scopes\x12!\n" +
+	"\fredirect_url\x18\a \x01(\tR\vredirectUrl\x12\"\n" +
+	"\rlink_by_email\x18\b \x01(\bR\vlinkByEmail\x12%\n" +
+	"\x0eauto_provision\x18\t \x01(\bR\rautoProvision\"\xd5\x05\n" +
+	"\n" +
+	"Credential\x127\n" +
+	"\bbackends\x18\x01 \x03(\v2\x1b.conf.v1.Credential.BackendR\bbackends\x124\n" +
+	"\atenants\x18\x02 \x03(\v2\x1a.conf.v1.Credential.TenantR\atenants\x1a\xcd\x03\n" +
+	"\x04Ldap\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x1b\n" +
+	"\tstart_tls\x18\x02 \x01(\bR\bstartTls\x120\n" +
+	"\x14insecure_skip_verify\x18\x03 \x01(\bR\x12insecureSkipVerify\x12\x17\n" +
+	"\abind_dn\x18\x04 \x01(\tR\x06bindDn\x12#\n" +
+	"\rbind_password\x18\x05 \x01(\tR\fbindPassword\x12\x17\n" +
+	"\abase_dn\x18\x06 \x01(\tR\x06baseDn\x12\x1f\n" +
+	"\vuser_filter\x18\a \x01(\tR\n" +
+	"userFilter\x12'\n" +
+	"\x0femail_attribute\x18\b \x01(\tR\x0eemailAttribute\x124\n" +
+	"\x16display_name_attribute\x18\t \x01(\tR\x14displayNameAttribute\x120\n" +
+	"\x14given_name_attribute\x18\n" +
+	" \x01(\tR\x12givenNameAttribute\x122\n" +
+	"\x15family_name_attribute\x18\v \x01(\tR\x13familyNameAttribute\x12'\n" +
+	"\x0ftimeout_seconds\x18\f \x01(\x03R\x0etimeoutSeconds\x1aG\n" +
+	"\aBackend\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12,\n" +
+	"\x04ldap\x18\x02 \x01(\v2\x18.conf.v1.Credential.LdapR\x04ldap\x1a?\n" +
+	"\x06Tenant\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\x03R\btenantId\x12\x18\n" +
+	"\abackend\x18\x02 \x01(\tR\abackend\"\x97\x01\n" +
 	"\tDiscovery\x121\n" +
 	"\x06consul\x18\x01 \x01(\v2\x19.conf.v1.Discovery.ConsulR\x06consul\x1aW\n" +
 	"\x06Consul\x12\x12\n" +
@@ -820,19 +3570,50 @@ func file_internal_conf_v1_conf_proto_rawDescGZIP() []byte {
 }
 
 var (
-	file_internal_conf_v1_conf_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+	file_internal_conf_v1_conf_proto_msgTypes = make([]protoimpl.MessageInfo, 42)
 	file_internal_conf_v1_conf_proto_goTypes  = []any{
-		(*Bootstrap)(nil),         // 0: conf.v1.Bootstrap
-		(*Server)(nil),            // 1: conf.v1.Server
-		(*Data)(nil),              // 2: conf.v1.Data
-		(*Auth)(nil),              // 3: conf.v1.Auth
-		(*Trace)(nil),             // 4: conf.v1.Trace
-		(*Discovery)(nil),         // 5: conf.v1.Discovery
-		(*Server_HTTP)(nil),       // 6: conf.v1.Server.HTTP
-		(*Data_Database)(nil),     // 7: conf.v1.Data.Database
-		(*Data_DatabasePool)(nil), // 8: conf.v1.Data.DatabasePool
-		(*Data_Redis)(nil),        // 9: conf.v1.Data.Redis
-		(*Discovery_Consul)(nil),  // 10: conf.v1.Discovery.Consul
+		(*Bootstrap)(nil),           // 0: conf.v1.Bootstrap
+		(*Server)(nil),              // 1: conf.v1.Server
+		(*Data)(nil),                // 2: conf.v1.Data
+		(*Auth)(nil),                // 3: conf.v1.Auth
+		(*Trace)(nil),               // 4: conf.v1.Trace
+		(*Mail)(nil),                // 5: conf.v1.Mail
+		(*Tenancy)(nil),             // 6: conf.v1.Tenancy
+		(*Registration)(nil),        // 7: conf.v1.Registration
+		(*ProofOfWork)(nil),         // 8: conf.v1.ProofOfWork
+		(*ClientKdf)(nil),           // 9: conf.v1.ClientKdf
+		(*LoginRisk)(nil),           // 10: conf.v1.LoginRisk
+		(*Notify)(nil),              // 11: conf.v1.Notify
+		(*Events)(nil),              // 12: conf.v1.Events
+		(*Webhooks)(nil),            // 13: conf.v1.Webhooks
+		(*Encryption)(nil),          // 14: conf.v1.Encryption
+		(*OAuth)(nil),               // 15: conf.v1.OAuth
+		(*DPoP)(nil),                // 16: conf.v1.DPoP
+		(*Scim)(nil),                // 17: conf.v1.Scim
+		(*Federation)(nil),          // 18: conf.v1.Federation
+		(*Credential)(nil),          // 19: conf.v1.Credential
+		(*Discovery)(nil),           // 20: conf.v1.Discovery
+		(*Server_HTTP)(nil),         // 21: conf.v1.Server.HTTP
+		(*Data_Database)(nil),       // 22: conf.v1.Data.Database
+		(*Data_Replica)(nil),        // 23: conf.v1.Data.Replica
+		(*Data_DatabasePool)(nil),   // 24: conf.v1.Data.DatabasePool
+		(*Data_Redis)(nil),          // 25: conf.v1.Data.Redis
+		(*Data_RedisTLS)(nil),       // 26: conf.v1.Data.RedisTLS
+		(*Data_Sqlite)(nil),         // 27: conf.v1.Data.Sqlite
+		(*Data_StateStore)(nil),     // 28: conf.v1.Data.StateStore
+		(*Data_UserCache)(nil),      // 29: conf.v1.Data.UserCache
+		(*Mail_SMTP)(nil),           // 30: conf.v1.Mail.SMTP
+		(*ClientKdf_Profile)(nil),   // 31: conf.v1.ClientKdf.Profile
+		(*Events_Nats)(nil),         // 32: conf.v1.Events.Nats
+		(*Events_Kafka)(nil),        // 33: conf.v1.Events.Kafka
+		(*Events_RedisStream)(nil),  // 34: conf.v1.Events.RedisStream
+		(*OAuth_Client)(nil),        // 35: conf.v1.OAuth.Client
+		(*Scim_Client)(nil),         // 36: conf.v1.Scim.Client
+		(*Federation_Provider)(nil), // 37: conf.v1.Federation.Provider
+		(*Credential_Ldap)(nil),     // 38: conf.v1.Credential.Ldap
+		(*Credential_Backend)(nil),  // 39: conf.v1.Credential.Backend
+		(*Credential_Tenant)(nil),   // 40: conf.v1.Credential.Tenant
+		(*Discovery_Consul)(nil),    // 41: conf.v1.Discovery.Consul
 	}
 )
 
@@ -841,17 +3622,48 @@ var file_internal_conf_v1_conf_proto_depIdxs = []int32{
 	2,  // 1: conf.v1.Bootstrap.data:type_name -> conf.v1.Data
 	3,  // 2: conf.v1.Bootstrap.auth:type_name -> conf.v1.Auth
 	4,  // 3: conf.v1.Bootstrap.trace:type_name -> conf.v1.Trace
-	5,  // 4: conf.v1.Bootstrap.discovery:type_name -> conf.v1.Discovery
-	6,  // 5: conf.v1.Server.http:type_name -> conf.v1.Server.HTTP
-	7,  // 6: conf.v1.Data.database:type_name -> conf.v1.Data.Database
-	9,  // 7: conf.v1.Data.redis:type_name -> conf.v1.Data.Redis
-	10, // 8: conf.v1.Discovery.consul:type_name -> conf.v1.Discovery.Consul
-	8,  // 9: conf.v1.Data.Database.pool:type_name -> conf.v1.Data.DatabasePool
-	10, // [10:10] is the sub-list for method output_type
-	10, // [10:10] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	20, // 4: conf.v1.Bootstrap.discovery:type_name -> conf.v1.Discovery
+	5,  // 5: conf.v1.Bootstrap.mail:type_name -> conf.v1.Mail
+	6,  // 6: conf.v1.Bootstrap.tenancy:type_name -> conf.v1.Tenancy
+	7,  // 7: conf.v1.Bootstrap.registration:type_name -> conf.v1.Registration
+	8,  // 8: conf.v1.Bootstrap.proof_of_work:type_name -> conf.v1.ProofOfWork
+	9,  // 9: conf.v1.Bootstrap.client_kdf:type_name -> conf.v1.ClientKdf
+	10, // 10: conf.v1.Bootstrap.login_risk:type_name -> conf.v1.LoginRisk
+	11, // 11: conf.v1.Bootstrap.notify:type_name -> conf.v1.Notify
+	15, // 12: conf.v1.Bootstrap.oauth:type_name -> conf.v1.OAuth
+	16, // 13: conf.v1.Bootstrap.dpop:type_name -> conf.v1.DPoP
+	17, // 14: conf.v1.Bootstrap.scim:type_name -> conf.v1.Scim
+	18, // 15: conf.v1.Bootstrap.federation:type_name -> conf.v1.Federation
+	19, // 16: conf.v1.Bootstrap.credential:type_name -> conf.v1.Credential
+	12, // 17: conf.v1.Bootstrap.events:type_name -> conf.v1.Events
+	13, // 18: conf.v1.Bootstrap.webhooks:type_name -> conf.v1.Webhooks
+	14, // 19: conf.v1.Bootstrap.encryption:type_name -> conf.v1.Encryption
+	21, // 20: conf.v1.Server.http:type_name -> conf.v1.Server.HTTP
+	22, // 21: conf.v1.Data.database:type_name -> conf.v1.Data.Database
+	25, // 22: conf.v1.Data.redis:type_name -> conf.v1.Data.Redis
+	27, // 23: conf.v1.Data.sqlite:type_name -> conf.v1.Data.Sqlite
+	28, // 24: conf.v1.Data.state_store:type_name -> conf.v1.Data.StateStore
+	29, // 25: conf.v1.Data.user_cache:type_name -> conf.v1.Data.UserCache
+	30, // 26: conf.v1.Mail.smtp:type_name -> conf.v1.Mail.SMTP
+	31, // 27: conf.v1.ClientKdf.profiles:type_name -> conf.v1.ClientKdf.Profile
+	32, // 28: conf.v1.Events.nats:type_name -> conf.v1.Events.Nats
+	33, // 29: conf.v1.Events.kafka:type_name -> conf.v1.Events.Kafka
+	34, // 30: conf.v1.Events.redis:type_name -> conf.v1.Events.RedisStream
+	35, // 31: conf.v1.OAuth.clients:type_name -> conf.v1.OAuth.Client
+	36, // 32: conf.v1.Scim.clients:type_name -> conf.v1.Scim.Client
+	37, // 33: conf.v1.Federation.providers:type_name -> conf.v1.Federation.Provider
+	39, // 34: conf.v1.Credential.backends:type_name -> conf.v1.Credential.Backend
+	40, // 35: conf.v1.Credential.tenants:type_name -> conf.v1.Credential.Tenant
+	41, // 36: conf.v1.Discovery.consul:type_name -> conf.v1.Discovery.Consul
+	24, // 37: conf.v1.Data.Database.pool:type_name -> conf.v1.Data.DatabasePool
+	23, // 38: conf.v1.Data.Database.replicas:type_name -> conf.v1.Data.Replica
+	26, // 39: conf.v1.Data.Redis.tls:type_name -> conf.v1.Data.RedisTLS
+	38, // 40: conf.v1.Credential.Backend.ldap:type_name -> conf.v1.Credential.Ldap
+	41, // [41:41] is the sub-list for method output_type
+	41, // [41:41] is the sub-list for method input_type
+	41, // [41:41] is the sub-list for extension type_name
+	41, // [41:41] is the sub-list for extension extendee
+	0,  // [0:41] is the sub-list for field type_name
 }
 
 func init() { file_internal_conf_v1_conf_proto_init() }
@@ -865,7 +3677,7 @@ func file_internal_conf_v1_conf_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_conf_v1_conf_proto_rawDesc), len(file_internal_conf_v1_conf_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   11,
+			NumMessages:   42,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
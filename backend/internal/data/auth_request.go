@@ -0,0 +1,150 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// AuthRequestRepo 登录请求数据访问接口
+type AuthRequestRepo interface {
+	CreateAuthRequest(ctx context.Context, req *model.AuthRequest) error
+	GetAuthRequest(ctx context.Context, id string) (*model.AuthRequest, error)
+	// TransitAuthRequest 仅当当前状态为 from 时写入 req 的新状态并广播，返回是否更新成功
+	TransitAuthRequest(ctx context.Context, req *model.AuthRequest, from model.AuthRequestState) (bool, error)
+	DeleteAuthRequest(ctx context.Context, id string) error
+	// SubscribeAuthRequest 订阅登录请求的状态变化通知，返回的 close 用于取消订阅
+	SubscribeAuthRequest(ctx context.Context, id string) (<-chan struct{}, func() error, error)
+}
+
+// transitAuthRequestScript 原子地比较并更新状态，然后通过 pub/sub 通知所有副本。
+// 频道不是键，放在 ARGV 中，集群模式下脚本只访问 KEYS[1] 所在的槽
+var transitAuthRequestScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'state') ~= ARGV[1] then
+  return 0
+end
+redis.call('HSET', KEYS[1], 'state', ARGV[2], 'user_id', ARGV[3], 'auth_token', ARGV[4])
+redis.call('PUBLISH', ARGV[5], ARGV[2])
+return 1
+`)
+
+// authRequestRepo 只使用 Redis，不经过 StateStore：状态变化需要原子的比较并更新，
+// 并通过 pub/sub 通知所有副本上的订阅方，备用存储都做不到。
+// Redis 不可用时直接返回错误，扫码登录和登录请求暂时不可用（fail closed），密码等其他登录方式不受影响
+type authRequestRepo struct {
+	rdb redis.UniversalClient
+	l   *zap.Logger
+}
+
+// NewAuthRequestRepo 本地开发驱动没有 Redis，登录请求保存在进程内
+func NewAuthRequestRepo(data *Data, logger *zap.Logger) AuthRequestRepo {
+	if data.driver != DriverPostgres {
+		return newMemoryAuthRequestRepo()
+	}
+	return &authRequestRepo{
+		rdb: data.rdb,
+		l:   logger,
+	}
+}
+
+func authRequestKey(id string) string {
+	return fmt.Sprintf("auth_request:%s", id)
+}
+
+func authRequestChannel(id string) string {
+	return fmt.Sprintf("auth_request_events:%s", id)
+}
+
+func (r *authRequestRepo) CreateAuthRequest(ctx context.Context, req *model.AuthRequest) error {
+	key := authRequestKey(req.ID)
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key,
+			"state", int32(req.State),
+			"client_name", req.ClientName,
+			"watch_token_hash", req.WatchTokenHash,
+			"user_id", req.UserID,
+			"auth_token", req.AuthToken,
+			"created_at", req.CreatedAt.Unix(),
+			"expires_at", req.ExpiresAt.Unix(),
+		)
+		pipe.ExpireAt(ctx, key, req.ExpiresAt)
+		return nil
+	})
+	return err
+}
+
+func (r *authRequestRepo) GetAuthRequest(ctx context.Context, id string) (*model.AuthRequest, error) {
+	fields, err := r.rdb.HGetAll(ctx, authRequestKey(id)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, model.ErrAuthRequestNotFound
+	}
+
+	state, _ := strconv.ParseInt(fields["state"], 10, 32)
+	userID, _ := strconv.ParseInt(fields["user_id"], 10, 64)
+	createdAt, _ := strconv.ParseInt(fields["created_at"], 10, 64)
+	expiresAt, _ := strconv.ParseInt(fields["expires_at"], 10, 64)
+
+	return &model.AuthRequest{
+		ID:             id,
+		State:          model.AuthRequestState(state),
+		ClientName:     fields["client_name"],
+		WatchTokenHash: fields["watch_token_hash"],
+		UserID:         userID,
+		AuthToken:      fields["auth_token"],
+		CreatedAt:      time.Unix(createdAt, 0),
+		ExpiresAt:      time.Unix(expiresAt, 0),
+	}, nil
+}
+
+func (r *authRequestRepo) TransitAuthRequest(ctx context.Context, req *model.AuthRequest, from model.AuthRequestState) (bool, error) {
+	n, err := transitAuthRequestScript.Run(ctx, r.rdb, []string{authRequestKey(req.ID)},
+		int32(from), int32(req.State), req.UserID, req.AuthToken, authRequestChannel(req.ID),
+	).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (r *authRequestRepo) DeleteAuthRequest(ctx context.Context, id string) error {
+	return r.rdb.Del(ctx, authRequestKey(id)).Err()
+}
+
+func (r *authRequestRepo) SubscribeAuthRequest(ctx context.Context, id string) (<-chan struct{}, func() error, error) {
+	sub := r.rdb.Subscribe(ctx, authRequestChannel(id))
+	// 等待订阅确认，保证之后的状态变化不会丢失
+	if _, err := sub.Receive(ctx); err != nil {
+		_ = sub.Close()
+		return nil, nil, err
+	}
+
+	notify := make(chan struct{}, 1)
+	go func() {
+		defer close(notify)
+		for range sub.Channel() {
+			select {
+			case notify <- struct{}{}:
+			default:
+				// 已有未处理的通知，订阅方会重新读取最新状态
+			}
+		}
+	}()
+
+	return notify, func() error {
+		err := sub.Close()
+		if errors.Is(err, redis.ErrClosed) {
+			return nil
+		}
+		return err
+	}, nil
+}
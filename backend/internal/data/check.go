@@ -13,21 +13,29 @@ import (
 
 type checkRepo struct {
 	pool *pgxpool.Pool
-	rdb  *redis.Client
-	l    *zap.Logger
+	rdb  redis.UniversalClient
+	// degradable 短期状态配置了备用存储，Redis 不可用时仍能登录
+	degradable bool
+	l          *zap.Logger
 }
 
 type CheckRepo interface {
 	Ready(context.Context, model.HealthCheckReq) (model.HealthCheckReply, error)
 }
 
-func NewCheckRepo(pool *pgxpool.Pool, rdb *redis.Client,
-	l *zap.Logger,
-) CheckRepo {
+func NewCheckRepo(data *Data, states StateStore, l *zap.Logger) CheckRepo {
+	switch data.driver {
+	case DriverMemory:
+		return localCheckRepo{}
+	case DriverSQLite:
+		return localCheckRepo{ping: data.sqlite.PingContext}
+	}
+	_, degradable := states.(*failoverStateStore)
 	return &checkRepo{
-		pool: pool,
-		rdb:  rdb,
-		l:    l,
+		pool:       data.db,
+		rdb:        data.rdb,
+		degradable: degradable,
+		l:          l,
 	}
 }
 
@@ -42,6 +50,16 @@ func (c checkRepo) Ready(ctx context.Context, _ model.HealthCheckReq) (model.Hea
 		}, connect.NewError(connect.CodeUnavailable, err)
 	}
 	if err := c.rdb.Ping(ctx).Err(); err != nil {
+		if c.degradable {
+			// 仍然就绪，避免所有副本同时被摘除；依赖 Redis 的其他功能暂时不可用
+			return model.HealthCheckReply{
+				Status: "Degraded",
+				Details: map[string]string{
+					"Components": "Redis",
+					"Message":    err.Error(),
+				},
+			}, nil
+		}
 		return model.HealthCheckReply{
 			Status: "Unhealthy",
 			Details: map[string]string{
@@ -0,0 +1,96 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+
+	"go.uber.org/zap"
+)
+
+// CrossDeviceLoginRepo 扫码登录数据访问接口
+type CrossDeviceLoginRepo interface {
+	// CreateCrossDeviceLogin 短码已存在时返回 false
+	CreateCrossDeviceLogin(ctx context.Context, login *model.CrossDeviceLogin) (bool, error)
+	GetCrossDeviceLogin(ctx context.Context, code string) (*model.CrossDeviceLogin, error)
+	DeleteCrossDeviceLogin(ctx context.Context, code string) error
+}
+
+// crossDeviceLoginRepo 短码保存在 StateStore 中，对应的登录请求仍在 Redis 中，见 authRequestRepo
+type crossDeviceLoginRepo struct {
+	states StateStore
+	l      *zap.Logger
+}
+
+// crossDeviceLoginRecord StateStore 中保存的扫码登录记录
+type crossDeviceLoginRecord struct {
+	AuthRequestID string `json:"auth_request_id"`
+	ClientName    string `json:"client_name"`
+	IP            string `json:"ip"`
+	UserAgent     string `json:"user_agent"`
+	LocationHint  string `json:"location_hint"`
+	CreatedAt     int64  `json:"created_at"`
+	ExpiresAt     int64  `json:"expires_at"`
+}
+
+func NewCrossDeviceLoginRepo(states StateStore, logger *zap.Logger) CrossDeviceLoginRepo {
+	return &crossDeviceLoginRepo{
+		states: states,
+		l:      logger,
+	}
+}
+
+func crossDeviceLoginKey(code string) string {
+	return fmt.Sprintf("cross_device_login:%s", code)
+}
+
+func (r *crossDeviceLoginRepo) CreateCrossDeviceLogin(ctx context.Context, login *model.CrossDeviceLogin) (bool, error) {
+	value, err := json.Marshal(crossDeviceLoginRecord{
+		AuthRequestID: login.AuthRequestID,
+		ClientName:    login.ClientName,
+		IP:            login.Requester.IP,
+		UserAgent:     login.Requester.UserAgent,
+		LocationHint:  login.Requester.LocationHint,
+		CreatedAt:     login.CreatedAt.Unix(),
+		ExpiresAt:     login.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return false, err
+	}
+	return r.states.SetNX(ctx, crossDeviceLoginKey(login.Code), string(value), time.Until(login.ExpiresAt))
+}
+
+func (r *crossDeviceLoginRepo) GetCrossDeviceLogin(ctx context.Context, code string) (*model.CrossDeviceLogin, error) {
+	value, err := r.states.Get(ctx, crossDeviceLoginKey(code))
+	if err != nil {
+		if errors.Is(err, ErrStateNotFound) {
+			return nil, model.ErrCrossDeviceLoginNotFound
+		}
+		return nil, err
+	}
+
+	var record crossDeviceLoginRecord
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return nil, err
+	}
+	return &model.CrossDeviceLogin{
+		Code:          code,
+		AuthRequestID: record.AuthRequestID,
+		ClientName:    record.ClientName,
+		Requester: model.ClientInfo{
+			IP:           record.IP,
+			UserAgent:    record.UserAgent,
+			LocationHint: record.LocationHint,
+		},
+		CreatedAt: time.Unix(record.CreatedAt, 0),
+		ExpiresAt: time.Unix(record.ExpiresAt, 0),
+	}, nil
+}
+
+func (r *crossDeviceLoginRepo) DeleteCrossDeviceLogin(ctx context.Context, code string) error {
+	return r.states.Del(ctx, crossDeviceLoginKey(code))
+}
@@ -1,11 +1,18 @@
 package data
 
 import (
+	"cmp"
 	"context"
+	"database/sql"
 	"fmt"
+	"net"
+	"net/url"
+	"strconv"
 	"time"
 
 	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data/schema"
+	"connect-go-example/internal/pkg/migrate"
 
 	"github.com/exaring/otelpgx"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -19,52 +26,127 @@ var Module = fx.Module("data",
 	fx.Provide(
 		NewData,
 		NewDB,
+		NewDBRouter,
+		NewTransactor,
 		NewCache,
+		NewStateStore,
+		NewUserCache,
+		NewKeyring,
+		NewFieldReencryptor,
 		NewUserRepo,
 		NewCheckRepo,
+		NewAuthRequestRepo,
+		NewCrossDeviceLoginRepo,
+		NewMagicLinkRepo,
+		NewTenantRepo,
+		NewInviteRepo,
+		NewPowRepo,
+		NewDeviceRepo,
+		NewStepUpRepo,
+		NewSessionRepo,
+		NewDPoPRepo,
+		NewScimRepo,
+		NewIdentityRepo,
+		NewOutboxRepo,
+		NewEventRelay,
+		NewWebhookRepo,
+		NewWebhookDispatcher,
 	),
 )
 
+// data.driver 的取值
+const (
+	DriverPostgres = "postgres"
+	DriverMemory   = "memory"
+	DriverSQLite   = "sqlite"
+)
+
 // Data 包含所有数据源的客户端
 type Data struct {
-	db  *pgxpool.Pool
-	rdb *redis.Client
+	db *pgxpool.Pool
+	// query 执行 sqlc 查询，只读查询可能发送到只读副本
+	query *DBRouter
+	rdb   redis.UniversalClient
+
+	// 以下字段只在本地开发驱动下使用
+	driver string
+	memory *memoryStore
+	sqlite *sql.DB
 }
 
-// NewData 是 Data 的构造函数
-func NewData(db *pgxpool.Pool, rdb *redis.Client) *Data {
-	return &Data{
-		db:  db,
-		rdb: rdb,
+// NewData 是 Data 的构造函数，sqlite 驱动在这里打开数据库文件
+func NewData(lc fx.Lifecycle, cfg *conf.Bootstrap, db *pgxpool.Pool, query *DBRouter, rdb redis.UniversalClient, logger *zap.Logger) (*Data, error) {
+	d := &Data{
+		db:     db,
+		query:  query,
+		rdb:    rdb,
+		driver: driverOf(cfg),
+	}
+
+	// SCIM 和外部身份登录只有 Postgres 实现，本地开发驱动下配置时直接失败，避免调用时才连接空的数据库
+	if d.driver != DriverPostgres {
+		if len(cfg.GetScim().GetClients()) > 0 {
+			return nil, fmt.Errorf("scim is not supported by the %s driver", d.driver)
+		}
+		if len(cfg.GetFederation().GetProviders()) > 0 {
+			return nil, fmt.Errorf("federation is not supported by the %s driver", d.driver)
+		}
 	}
+
+	switch d.driver {
+	case DriverMemory:
+		d.memory = newMemoryStore()
+	case DriverSQLite:
+		sqliteDB, err := openSQLite(cfg.Data.GetSqlite().GetPath())
+		if err != nil {
+			return nil, err
+		}
+		d.sqlite = sqliteDB
+		lc.Append(fx.Hook{
+			OnStop: func(ctx context.Context) error {
+				logger.Info("Closing SQLite database...")
+				return sqliteDB.Close()
+			},
+		})
+	}
+	if d.driver != DriverPostgres {
+		logger.Warn("Using local data driver, SCIM, federation and webhooks are unavailable, short-lived state is kept in memory", zap.String("driver", d.driver))
+	}
+
+	return d, nil
+}
+
+// driverOf 返回配置的数据驱动，未配置时为 postgres
+func driverOf(cfg *conf.Bootstrap) string {
+	if driver := cfg.GetData().GetDriver(); driver != "" {
+		return driver
+	}
+	return DriverPostgres
 }
 
 // NewDB 创建数据库连接池
 func NewDB(lc fx.Lifecycle, cfg *conf.Bootstrap, logger *zap.Logger) (*pgxpool.Pool, error) {
 	dbCfg := cfg.Data.Database // 从 Config 中获取 Data 配置
 
-	connString := fmt.Sprintf("postgresql://%s:%s@%s:%d/%s?sslmode=%s&timezone=%s",
-		dbCfg.User,
-		dbCfg.Password,
-		dbCfg.Host,
-		dbCfg.Port,
-		dbCfg.DbName,
-		dbCfg.SslMode,
-		dbCfg.Timezone,
-	)
-
-	poolCfg, err := pgxpool.ParseConfig(connString)
-	if err != nil {
-		return nil, fmt.Errorf("parse database config failed: %v", err)
+	// 本地开发驱动不连接 Postgres，连接池在首次使用时才建立连接，依赖它的功能调用时返回错误
+	if driverOf(cfg) != DriverPostgres {
+		pool, err := newPool(dbCfg.GetDsn(), dbCfg.GetPool())
+		if err != nil {
+			return nil, err
+		}
+		lc.Append(fx.Hook{
+			OnStop: func(ctx context.Context) error {
+				pool.Close()
+				return nil
+			},
+		})
+		return pool, nil
 	}
 
-	// 链路追踪配置
-	poolCfg.ConnConfig.Tracer = otelpgx.NewTracer()
-
 	// 创建连接池
-	pool, err := pgxpool.NewWithConfig(context.Background(), poolCfg)
+	pool, err := newPool(primaryConnString(dbCfg), dbCfg.Pool)
 	if err != nil {
-		return nil, fmt.Errorf("connect to database failed: %v", err)
+		return nil, err
 	}
 
 	// 记录数据库统计信息
@@ -76,7 +158,23 @@ func NewDB(lc fx.Lifecycle, cfg *conf.Bootstrap, logger *zap.Logger) (*pgxpool.P
 	if err := pool.Ping(context.Background()); err != nil {
 		return nil, fmt.Errorf("database ping failed: %v", err)
 	}
-	fmt.Printf("dbCfg:%+v", dbCfg)
+	logger.Info("Database connected", zap.String("host", pool.Config().ConnConfig.Host), zap.String("database", pool.Config().ConnConfig.Database))
+
+	// 按配置在启动时迁移，advisory lock 保证多副本同时启动时只有一个执行
+	if dbCfg.AutoMigrate {
+		migrator, err := NewMigrator(pool, logger)
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("auto migrate failed: %v", err)
+		}
+		logger.Info("Database migrated", zap.Int("applied", applied))
+	}
+
 	// 注册关闭钩子
 	lc.Append(fx.Hook{
 		OnStop: func(ctx context.Context) error {
@@ -89,37 +187,112 @@ func NewDB(lc fx.Lifecycle, cfg *conf.Bootstrap, logger *zap.Logger) (*pgxpool.P
 	return pool, nil
 }
 
+// newPool 按连接串和连接池配置创建连接池，连接在首次使用时建立
+func newPool(connString string, poolCfg *conf.Data_DatabasePool) (*pgxpool.Pool, error) {
+	cfg, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, fmt.Errorf("parse database config failed: %v", err)
+	}
+	if poolCfg != nil {
+		if poolCfg.MaxConns > 0 {
+			cfg.MaxConns = poolCfg.MaxConns
+		}
+		if poolCfg.MinConns > 0 {
+			cfg.MinConns = poolCfg.MinConns
+		}
+		if poolCfg.MaxConnLifetime > 0 {
+			cfg.MaxConnLifetime = time.Duration(poolCfg.MaxConnLifetime) * time.Second
+		}
+		if poolCfg.MaxConnIdleTime > 0 {
+			cfg.MaxConnIdleTime = time.Duration(poolCfg.MaxConnIdleTime) * time.Second
+		}
+	}
+
+	// 链路追踪配置
+	cfg.ConnConfig.Tracer = otelpgx.NewTracer()
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	if err != nil {
+		return nil, fmt.Errorf("connect to database failed: %v", err)
+	}
+	return pool, nil
+}
+
+// primaryConnString 优先使用配置的 DSN，否则由各字段拼接，用户名和密码经过转义
+func primaryConnString(dbCfg *conf.Data_Database) string {
+	if dbCfg.Dsn != "" {
+		return dbCfg.Dsn
+	}
+	return connString(dbCfg, dbCfg.Host, dbCfg.Port)
+}
+
+// replicaConnString 副本未配置 DSN 时沿用主库的用户、密码和数据库
+func replicaConnString(dbCfg *conf.Data_Database, replicaCfg *conf.Data_Replica) string {
+	if replicaCfg.Dsn != "" {
+		return replicaCfg.Dsn
+	}
+	return connString(dbCfg, replicaCfg.Host, replicaCfg.Port)
+}
+
+func connString(dbCfg *conf.Data_Database, host string, port int32) string {
+	query := url.Values{}
+	if dbCfg.SslMode != "" {
+		query.Set("sslmode", dbCfg.SslMode)
+	}
+	if dbCfg.Timezone != "" {
+		query.Set("timezone", dbCfg.Timezone)
+	}
+	u := url.URL{
+		Scheme:   "postgresql",
+		User:     url.UserPassword(dbCfg.User, dbCfg.Password),
+		Host:     net.JoinHostPort(host, strconv.Itoa(int(port))),
+		Path:     "/" + dbCfg.DbName,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+// NewMigrator 使用内嵌的迁移脚本创建迁移器
+func NewMigrator(pool *pgxpool.Pool, logger *zap.Logger) (*migrate.Migrator, error) {
+	return migrate.New(pool, schema.Migrations, logger)
+}
+
 // NewCache 创建 Redis 客户端
-func NewCache(lc fx.Lifecycle, cfg *conf.Bootstrap, logger *zap.Logger) (*redis.Client, error) {
-	redisCfg := cfg.Data.Redis // 从 Config 中获取 Redis 配置
-
-	rdb := redis.NewClient(&redis.Options{
-		Addr:         fmt.Sprintf("%s:%d", redisCfg.Host, redisCfg.Port),
-		Username:     redisCfg.Username,
-		Password:     redisCfg.Password,
-		DB:           int(redisCfg.Db),
-		DialTimeout:  time.Duration(redisCfg.DialTimeout) * time.Second,
-		ReadTimeout:  time.Duration(redisCfg.ReadTimeout) * time.Second,
-		WriteTimeout: time.Duration(redisCfg.WriteTimeout) * time.Second,
-		PoolSize:     int(redisCfg.PoolSize),
-		MinIdleConns: int(redisCfg.MinIdleConns),
-	})
+func NewCache(lc fx.Lifecycle, cfg *conf.Bootstrap, logger *zap.Logger) (redis.UniversalClient, error) {
+	redisCfg := cfg.Data.GetRedis() // 从 Config 中获取 Redis 配置
+
+	rdb, err := newRedisClient(redisCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// 本地开发驱动不要求 Redis 可用，登录挑战保存在内存中
+	if driverOf(cfg) != DriverPostgres {
+		lc.Append(fx.Hook{
+			OnStop: func(ctx context.Context) error {
+				return rdb.Close()
+			},
+		})
+		return rdb, nil
+	}
 
 	// 测试连接
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := rdb.Ping(ctx).Err(); err != nil {
-		// 关闭连接以避免资源泄漏
-		err := rdb.Close()
-		if err != nil {
-			return nil, err
+		fallback := stateFallbackOf(cfg)
+		if fallback == StateFallbackNone {
+			// 关闭连接以避免资源泄漏
+			_ = rdb.Close()
+			return nil, fmt.Errorf("redis ping failed: %v", err)
 		}
-		return nil, fmt.Errorf("redis ping failed: %v", err)
+		// 登录挑战等短期状态由备用存储接管，客户端在 Redis 恢复后自动重连
+		logger.Warn("Redis unavailable, starting in degraded mode", zap.String("fallback", fallback), zap.Error(err))
+	} else {
+		logger.Info("Redis connected", zap.String("mode", cmp.Or(redisCfg.GetMode(), RedisModeSingle)), zap.Strings("addrs", redisCfg.GetAddrs()), zap.String("host", redisCfg.GetHost()))
 	}
 
-	logger.Info(fmt.Sprintf("Redis connected successfully to %s", redisCfg.Host))
-
 	// 注册关闭钩子
 	lc.Append(fx.Hook{
 		OnStop: func(ctx context.Context) error {
@@ -58,8 +58,8 @@ type MockQueries struct {
 	mock.Mock
 }
 
-func (m *MockQueries) GetUserByName(ctx context.Context, username string) (models.User, error) {
-	args := m.Called(ctx, username)
+func (m *MockQueries) GetUserByName(ctx context.Context, params models.GetUserByNameParams) (models.User, error) {
+	args := m.Called(ctx, params)
 	return args.Get(0).(models.User), args.Error(1)
 }
 
@@ -84,7 +84,7 @@ func (suite *DataTestSuite) SetupTest() {
 
 	// 使用默认配置创建连接
 	// 这里简化处理，实际项目中应该使用测试配置
-	suite.data = NewData(suite.dbPool, suite.redis)
+	suite.data = &Data{db: suite.dbPool, rdb: suite.redis, driver: DriverPostgres}
 }
 
 func (suite *DataTestSuite) TestHealthCheck_Success() {
@@ -118,7 +118,7 @@ func (suite *CheckRepoTestSuite) SetupTest() {
 
 	// 使用默认配置创建连接
 	// 这里简化处理，实际项目中应该使用测试配置
-	suite.checkRepo = NewCheckRepo(suite.dbPool, suite.redis, suite.logger)
+	suite.checkRepo = NewCheckRepo(&Data{db: suite.dbPool, rdb: suite.redis, driver: DriverPostgres}, nil, suite.logger)
 }
 
 func (suite *CheckRepoTestSuite) TestReady_Success() {
@@ -0,0 +1,146 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	"connect-go-example/internal/data/models"
+
+	"go.uber.org/zap"
+)
+
+// DeviceRepo 用户设备和登录记录数据访问接口，所有操作都限定在 ctx 中的租户内
+type DeviceRepo interface {
+	ListDevices(ctx context.Context, userID int64) ([]*model.Device, error)
+	// UpsertDevice 记录设备，已存在时更新最近登录信息
+	UpsertDevice(ctx context.Context, device *model.Device) error
+	CreateLoginEvent(ctx context.Context, event *model.LoginEvent) error
+	// ListLoginEvents 返回 since 之后的登录记录，最新的在前
+	ListLoginEvents(ctx context.Context, userID int64, since time.Time) ([]*model.LoginEvent, error)
+}
+
+type deviceRepo struct {
+	queries *models.Queries
+	l       *zap.Logger
+}
+
+func NewDeviceRepo(data *Data, logger *zap.Logger) DeviceRepo {
+	switch data.driver {
+	case DriverMemory:
+		return &memoryDeviceRepo{store: data.memory}
+	case DriverSQLite:
+		return &sqliteDeviceRepo{db: data.sqlite}
+	}
+	return &deviceRepo{
+		queries: models.New(data.query),
+		l:       logger,
+	}
+}
+
+func (r *deviceRepo) ListDevices(ctx context.Context, userID int64) ([]*model.Device, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := withTx(ctx, r.queries).ListUserDevices(ctx, models.ListUserDevicesParams{
+		TenantID: int32(tenantID),
+		UserID:   int32(userID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]*model.Device, 0, len(rows))
+	for _, row := range rows {
+		devices = append(devices, &model.Device{
+			ID:           int64(row.ID),
+			UserID:       userID,
+			Fingerprint:  row.Fingerprint,
+			DeviceID:     row.DeviceID,
+			UserAgent:    row.UserAgent,
+			IPPrefix:     row.IpPrefix,
+			LocationHint: row.LocationHint,
+			FirstSeenAt:  row.FirstSeenAt,
+			LastSeenAt:   row.LastSeenAt,
+		})
+	}
+	return devices, nil
+}
+
+func (r *deviceRepo) UpsertDevice(ctx context.Context, device *model.Device) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	row, err := withTx(ctx, r.queries).UpsertUserDevice(ctx, models.UpsertUserDeviceParams{
+		TenantID:     int32(tenantID),
+		UserID:       int32(device.UserID),
+		Fingerprint:  device.Fingerprint,
+		DeviceID:     device.DeviceID,
+		UserAgent:    device.UserAgent,
+		IpPrefix:     device.IPPrefix,
+		LocationHint: device.LocationHint,
+	})
+	if err != nil {
+		return err
+	}
+
+	device.ID = int64(row.ID)
+	device.FirstSeenAt = row.FirstSeenAt
+	device.LastSeenAt = row.LastSeenAt
+	return nil
+}
+
+func (r *deviceRepo) CreateLoginEvent(ctx context.Context, event *model.LoginEvent) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	reasons := event.Reasons
+	if reasons == nil {
+		reasons = []string{}
+	}
+	return withTx(ctx, r.queries).CreateLoginEvent(ctx, models.CreateLoginEventParams{
+		TenantID:    int32(tenantID),
+		UserID:      int32(event.UserID),
+		Fingerprint: event.Fingerprint,
+		IpPrefix:    event.IPPrefix,
+		RiskScore:   event.RiskScore,
+		Reasons:     reasons,
+		StepUp:      event.StepUp,
+	})
+}
+
+func (r *deviceRepo) ListLoginEvents(ctx context.Context, userID int64, since time.Time) ([]*model.LoginEvent, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := withTx(ctx, r.queries).ListLoginEvents(ctx, models.ListLoginEventsParams{
+		TenantID: int32(tenantID),
+		UserID:   int32(userID),
+		Since:    since,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*model.LoginEvent, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, &model.LoginEvent{
+			UserID:      userID,
+			Fingerprint: row.Fingerprint,
+			IPPrefix:    row.IpPrefix,
+			RiskScore:   row.RiskScore,
+			Reasons:     row.Reasons,
+			StepUp:      row.StepUp,
+			CreatedAt:   row.CreatedAt,
+		})
+	}
+	return events, nil
+}
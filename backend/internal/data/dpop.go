@@ -0,0 +1,32 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DPoPRepo DPoP 证明数据访问接口
+type DPoPRepo interface {
+	// UseProofID 标记证明已使用，返回是否为首次使用
+	UseProofID(ctx context.Context, id string, ttl time.Duration) (bool, error)
+}
+
+// dpopRepo 使用记录保存在 StateStore 中；存储不可用时返回错误，证明被拒绝
+type dpopRepo struct {
+	states StateStore
+	l      *zap.Logger
+}
+
+func NewDPoPRepo(states StateStore, logger *zap.Logger) DPoPRepo {
+	return &dpopRepo{
+		states: states,
+		l:      logger,
+	}
+}
+
+func (r *dpopRepo) UseProofID(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	return r.states.SetNX(ctx, fmt.Sprintf("dpop_jti:%s", id), "1", ttl)
+}
@@ -0,0 +1,537 @@
+package data
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data/models"
+	"connect-go-example/internal/pkg/fieldcrypt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// data_keys.purpose 的取值
+const (
+	dataKeyEncryption = "encryption"
+	dataKeyBlindIndex = "blind_index"
+)
+
+// 加密字段的名称，与租户ID一起作为 AAD，密文不能被复制到其他列或租户下使用
+const (
+	fieldUserEmail       = "users.email"
+	fieldUserDisplayName = "users.display_name"
+	fieldUserGivenName   = "users.given_name"
+	fieldUserFamilyName  = "users.family_name"
+	fieldIdentityEmail   = "user_identities.email"
+	// StateStore 和 Redis 用户缓存中的字段，密文另外绑定记录ID或用户名
+	fieldUserCachePasswordHash = "user_cache.password_hash"
+	fieldUserCacheEmail        = "user_cache.email"
+	fieldStepUpEmail           = "step_up.email"
+	fieldMagicLinkEmail        = "magic_link.email"
+)
+
+const (
+	// dataKeyRefreshInterval 各副本重新读取数据密钥的间隔
+	dataKeyRefreshInterval = time.Minute
+	// dataKeyActivationDelay 新数据密钥创建后经过该时间才用于加密，此时所有副本都已加载，都能解密新数据
+	dataKeyActivationDelay = 2 * dataKeyRefreshInterval
+)
+
+func fieldAAD(field string, tenantID int64) []byte {
+	return []byte(field + ":" + strconv.FormatInt(tenantID, 10))
+}
+
+// sealStateField 加密保存在 Redis 等短期存储中的字段，密文绑定字段、租户和记录ID，不能被复制到其他记录下使用；
+// 本地开发驱动没有 Keyring，短期状态只在进程内存中，以明文格式保存
+func sealStateField(keyring *fieldcrypt.Keyring, field string, tenantID int64, id, value string) (fieldcrypt.Ciphertext, error) {
+	if keyring == nil {
+		return fieldcrypt.Unencrypted(value), nil
+	}
+	return keyring.Encrypt(value, stateFieldAAD(field, tenantID, id))
+}
+
+// openStateField 解密 sealStateField 的结果
+func openStateField(keyring *fieldcrypt.Keyring, field string, tenantID int64, id string, value fieldcrypt.Ciphertext) (string, error) {
+	if keyring == nil && value.KeyID() != 0 {
+		return "", fieldcrypt.ErrUnknownKey
+	}
+	return keyring.Decrypt(value, stateFieldAAD(field, tenantID, id))
+}
+
+func stateFieldAAD(field string, tenantID int64, id string) []byte {
+	return append(fieldAAD(field, tenantID), ":"+id...)
+}
+
+// emailIndex 邮箱规范化为小写后计算盲索引，按邮箱查找不区分大小写
+func emailIndex(keyring *fieldcrypt.Keyring, tenantID int64, email string) fieldcrypt.BlindIndex {
+	return keyring.BlindIndex(strings.ToLower(strings.TrimSpace(email)), fieldAAD(fieldUserEmail, tenantID))
+}
+
+// fieldCodec 加解密同一租户下的多个字段，只记录第一个错误，调用方最后检查 err
+type fieldCodec struct {
+	keyring  *fieldcrypt.Keyring
+	tenantID int64
+	err      error
+}
+
+func newFieldCodec(keyring *fieldcrypt.Keyring, tenantID int64) *fieldCodec {
+	return &fieldCodec{keyring: keyring, tenantID: tenantID}
+}
+
+func (c *fieldCodec) encrypt(field, value string) fieldcrypt.Ciphertext {
+	if c.err != nil {
+		return nil
+	}
+	ciphertext, err := c.keyring.Encrypt(value, fieldAAD(field, c.tenantID))
+	c.err = err
+	return ciphertext
+}
+
+func (c *fieldCodec) decrypt(field string, value fieldcrypt.Ciphertext) string {
+	if c.err != nil {
+		return ""
+	}
+	plaintext, err := c.keyring.Decrypt(value, fieldAAD(field, c.tenantID))
+	if err != nil {
+		c.err = fmt.Errorf("decrypt %s: %w", field, err)
+	}
+	return plaintext
+}
+
+func (c *fieldCodec) emailIndex(email string) fieldcrypt.BlindIndex {
+	return emailIndex(c.keyring, c.tenantID, email)
+}
+
+// keyManager 创建、轮换数据密钥并加载到 Keyring
+type keyManager struct {
+	queries  *models.Queries
+	tx       Transactor
+	provider fieldcrypt.KeyProvider
+	keyring  *fieldcrypt.Keyring
+	rotation time.Duration
+	l        *zap.Logger
+}
+
+// NewKeyring 加载数据密钥，没有时创建；本地开发驱动不加密，返回 nil。
+// 启动时把不是由主 KEK 加密的数据密钥重新加密，之后定期读取其他副本轮换出的新密钥
+func NewKeyring(lc fx.Lifecycle, cfg *conf.Bootstrap, data *Data, tx Transactor, logger *zap.Logger) (*fieldcrypt.Keyring, error) {
+	if data.driver != DriverPostgres {
+		return nil, nil
+	}
+
+	provider, err := fieldcrypt.NewKeyProvider(cfg.GetEncryption())
+	if err != nil {
+		return nil, err
+	}
+	m := &keyManager{
+		queries:  models.New(data.query),
+		tx:       tx,
+		provider: provider,
+		rotation: 90 * 24 * time.Hour, // 默认90天
+		l:        logger,
+	}
+	if days := cfg.GetEncryption().GetDataKeyRotationDays(); days > 0 {
+		m.rotation = time.Duration(days) * 24 * time.Hour
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := m.sync(ctx, true); err != nil {
+		return nil, fmt.Errorf("load data keys failed: %w", err)
+	}
+	logger.Info("Field encryption keys loaded", zap.Uint32("active_key_id", m.keyring.ActiveKeyID()))
+
+	refreshCtx, stop := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				m.run(refreshCtx)
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			stop()
+			<-done
+			return nil
+		},
+	})
+	return m.keyring, nil
+}
+
+func (m *keyManager) run(ctx context.Context) {
+	ticker := time.NewTicker(dataKeyRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := m.sync(ctx, false); err != nil && ctx.Err() == nil {
+			m.l.Warn("Refresh data keys failed", zap.Error(err))
+		}
+	}
+}
+
+// sync 在锁内读取数据密钥，缺少或到期时创建，rewrap 为 true 时把旧 KEK 加密的数据密钥改用主 KEK 加密
+func (m *keyManager) sync(ctx context.Context, rewrap bool) error {
+	return m.tx.WithinTx(ctx, func(ctx context.Context) error {
+		q := withTx(ctx, m.queries)
+		if err := q.LockDataKeys(ctx); err != nil {
+			return err
+		}
+		rows, err := q.ListDataKeys(ctx)
+		if err != nil {
+			return err
+		}
+
+		var index, latest *models.DataKey
+		for i := range rows {
+			switch rows[i].Purpose {
+			case dataKeyBlindIndex:
+				index = &rows[i]
+			case dataKeyEncryption:
+				latest = &rows[i]
+			}
+		}
+		if index == nil {
+			if index, err = m.create(ctx, dataKeyBlindIndex); err != nil {
+				return err
+			}
+			rows = append(rows, *index)
+		}
+		if latest == nil || time.Since(latest.CreatedAt) >= m.rotation {
+			created, err := m.create(ctx, dataKeyEncryption)
+			if err != nil {
+				return err
+			}
+			rows = append(rows, *created)
+			if latest != nil {
+				m.l.Info("Data key rotated", zap.Int32("key_id", created.ID), zap.Int32("previous_key_id", latest.ID))
+			}
+		}
+
+		if rewrap {
+			for i := range rows {
+				if err := m.rewrap(ctx, &rows[i]); err != nil {
+					return err
+				}
+			}
+		}
+		return m.load(ctx, rows)
+	})
+}
+
+// create 生成数据密钥，用主 KEK 加密后保存
+func (m *keyManager) create(ctx context.Context, purpose string) (*models.DataKey, error) {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key) // crypto/rand.Read 不会返回错误
+	kekID, wrapped, err := m.provider.Wrap(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	row, err := withTx(ctx, m.queries).CreateDataKey(ctx, models.CreateDataKeyParams{
+		Purpose:    purpose,
+		KekID:      kekID,
+		WrappedKey: wrapped,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.DataKey{ID: row.ID, Purpose: purpose, KekID: kekID, WrappedKey: wrapped, CreatedAt: row.CreatedAt}, nil
+}
+
+// rewrap 轮换 KEK 后，旧 KEK 在所有数据密钥都重新加密后才能从密钥文件中删除
+func (m *keyManager) rewrap(ctx context.Context, row *models.DataKey) error {
+	if row.KekID == m.provider.PrimaryKeyID() {
+		return nil
+	}
+	key, err := m.provider.Unwrap(ctx, row.KekID, row.WrappedKey)
+	if err != nil {
+		return fmt.Errorf("unwrap data key %d: %w", row.ID, err)
+	}
+	kekID, wrapped, err := m.provider.Wrap(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := withTx(ctx, m.queries).RewrapDataKey(ctx, models.RewrapDataKeyParams{
+		KekID:      kekID,
+		WrappedKey: wrapped,
+		ID:         row.ID,
+	}); err != nil {
+		return err
+	}
+	m.l.Info("Data key rewrapped", zap.Int32("key_id", row.ID), zap.String("from_kek", row.KekID), zap.String("to_kek", kekID))
+	row.KekID, row.WrappedKey = kekID, wrapped
+	return nil
+}
+
+// load 解密尚未加载的数据密钥并选出用于加密的密钥
+func (m *keyManager) load(ctx context.Context, rows []models.DataKey) error {
+	var active *models.DataKey
+	for i := range rows {
+		row := &rows[i]
+		if row.Purpose == dataKeyBlindIndex {
+			if m.keyring != nil {
+				continue
+			}
+			key, err := m.provider.Unwrap(ctx, row.KekID, row.WrappedKey)
+			if err != nil {
+				return fmt.Errorf("unwrap blind index key: %w", err)
+			}
+			if m.keyring, err = fieldcrypt.NewKeyring(key); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := range rows {
+		row := &rows[i]
+		if row.Purpose != dataKeyEncryption {
+			continue
+		}
+		// 最新的已过激活等待的密钥用于加密，首次启动时只有刚创建的密钥
+		if active == nil || time.Since(row.CreatedAt) >= dataKeyActivationDelay {
+			active = row
+		}
+		if m.keyring.Has(uint32(row.ID)) {
+			continue
+		}
+		key, err := m.provider.Unwrap(ctx, row.KekID, row.WrappedKey)
+		if err != nil {
+			return fmt.Errorf("unwrap data key %d: %w", row.ID, err)
+		}
+		if err := m.keyring.Add(fieldcrypt.DataKey{ID: uint32(row.ID), Key: key}); err != nil {
+			return err
+		}
+	}
+	if active == nil {
+		return errors.New("no data key available")
+	}
+	return m.keyring.SetActive(uint32(active.ID))
+}
+
+// FieldReencryptor 在后台把旧数据密钥加密的字段和迁移前的明文改用当前数据密钥加密，并补齐盲索引
+type FieldReencryptor struct {
+	queries   *models.Queries
+	keyring   *fieldcrypt.Keyring
+	batchSize int32
+	interval  time.Duration
+	l         *zap.Logger
+}
+
+// NewFieldReencryptor 本地开发驱动不加密，不启动任务
+func NewFieldReencryptor(lc fx.Lifecycle, cfg *conf.Bootstrap, data *Data, keyring *fieldcrypt.Keyring, logger *zap.Logger) *FieldReencryptor {
+	encryptionCfg := cfg.GetEncryption()
+	r := &FieldReencryptor{
+		queries:   models.New(data.query),
+		keyring:   keyring,
+		batchSize: 500,              // 默认500
+		interval:  10 * time.Minute, // 默认10分钟
+		l:         logger,
+	}
+	if encryptionCfg.GetReencryptBatchSize() > 0 {
+		r.batchSize = encryptionCfg.GetReencryptBatchSize()
+	}
+	if encryptionCfg.GetReencryptIntervalSeconds() > 0 {
+		r.interval = time.Duration(encryptionCfg.GetReencryptIntervalSeconds()) * time.Second
+	}
+	if keyring == nil {
+		return r
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(startCtx context.Context) error {
+			// 盲索引补齐前无法按邮箱判重，在 HTTP 服务启动前完成
+			n, err := r.backfillEmailIndex(startCtx)
+			if err != nil {
+				return fmt.Errorf("backfill email index: %w", err)
+			}
+			if n > 0 {
+				r.l.Info("Backfilled email blind index", zap.Int64("users", n))
+			}
+			go func() {
+				defer close(done)
+				r.run(ctx)
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	})
+	return r
+}
+
+func (r *FieldReencryptor) run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		users, err := r.reencryptUsers(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.l.Warn("Re-encrypt user fields failed", zap.Error(err))
+		}
+		identities, err := r.reencryptIdentities(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.l.Warn("Re-encrypt identity emails failed", zap.Error(err))
+		}
+		if users+identities > 0 {
+			r.l.Info("Re-encrypted personal data fields",
+				zap.Int64("users", users), zap.Int64("identities", identities),
+				zap.Uint32("key_id", r.keyring.ActiveKeyID()))
+		}
+		timer.Reset(r.interval)
+	}
+}
+
+// reencryptUsers 按 ID 顺序扫描所有用户，返回重新加密的行数
+func (r *FieldReencryptor) reencryptUsers(ctx context.Context) (int64, error) {
+	var total int64
+	var after int32
+	for {
+		rows, err := r.queries.ListUserFields(ctx, models.ListUserFieldsParams{AfterID: after, BatchSize: r.batchSize})
+		if err != nil {
+			return total, err
+		}
+		for _, row := range rows {
+			n, err := r.reencryptUser(ctx, row)
+			if err != nil {
+				var pgErr *pgconn.PgError
+				if !errors.As(err, &pgErr) || pgErr.Code != pgUniqueViolation {
+					return total, err
+				}
+				// 迁移前允许只有大小写不同的重复邮箱，补齐盲索引时冲突，保留旧数据由管理员处理
+				r.l.Warn("Duplicate email prevents re-encryption", zap.Int32("user_id", row.ID))
+			}
+			total += n
+		}
+		if len(rows) < int(r.batchSize) {
+			return total, nil
+		}
+		after = rows[len(rows)-1].ID
+	}
+}
+
+// backfillEmailIndex 为迁移前的明文邮箱补齐盲索引，返回更新的行数
+func (r *FieldReencryptor) backfillEmailIndex(ctx context.Context) (int64, error) {
+	var total int64
+	var after int32
+	for {
+		rows, err := r.queries.ListUsersWithoutEmailIndex(ctx, models.ListUsersWithoutEmailIndexParams{AfterID: after, BatchSize: r.batchSize})
+		if err != nil {
+			return total, err
+		}
+		for _, row := range rows {
+			n, err := r.reencryptUser(ctx, models.ListUserFieldsRow(row))
+			if err != nil {
+				var pgErr *pgconn.PgError
+				if !errors.As(err, &pgErr) || pgErr.Code != pgUniqueViolation {
+					return total, err
+				}
+				// 与已有盲索引冲突的重复邮箱保留旧数据由管理员处理，新邮箱仍会与已补齐的一行冲突
+				r.l.Warn("Duplicate email prevents re-encryption", zap.Int32("user_id", row.ID))
+			}
+			total += n
+		}
+		if len(rows) < int(r.batchSize) {
+			return total, nil
+		}
+		after = rows[len(rows)-1].ID
+	}
+}
+
+func (r *FieldReencryptor) reencryptUser(ctx context.Context, row models.ListUserFieldsRow) (int64, error) {
+	if !r.keyring.Stale(row.Email) && !r.keyring.Stale(row.DisplayName) &&
+		!r.keyring.Stale(row.GivenName) && !r.keyring.Stale(row.FamilyName) &&
+		(row.Email == nil || row.EmailIndex != nil) {
+		return 0, nil
+	}
+
+	tenantID := int64(row.TenantID)
+	params := models.ReencryptUserFieldsParams{
+		ID:             row.ID,
+		OldEmail:       row.Email,
+		OldDisplayName: row.DisplayName,
+		OldGivenName:   row.GivenName,
+		OldFamilyName:  row.FamilyName,
+	}
+	email, err := r.reencrypt(row.Email, fieldAAD(fieldUserEmail, tenantID), &params.Email)
+	if err != nil {
+		return 0, err
+	}
+	params.EmailIndex = emailIndex(r.keyring, tenantID, email)
+	if _, err := r.reencrypt(row.DisplayName, fieldAAD(fieldUserDisplayName, tenantID), &params.DisplayName); err != nil {
+		return 0, err
+	}
+	if _, err := r.reencrypt(row.GivenName, fieldAAD(fieldUserGivenName, tenantID), &params.GivenName); err != nil {
+		return 0, err
+	}
+	if _, err := r.reencrypt(row.FamilyName, fieldAAD(fieldUserFamilyName, tenantID), &params.FamilyName); err != nil {
+		return 0, err
+	}
+	return r.queries.ReencryptUserFields(ctx, params)
+}
+
+// reencryptIdentities 按 ID 顺序扫描所有外部身份，返回重新加密的行数
+func (r *FieldReencryptor) reencryptIdentities(ctx context.Context) (int64, error) {
+	var total int64
+	var after int32
+	for {
+		rows, err := r.queries.ListIdentityEmails(ctx, models.ListIdentityEmailsParams{AfterID: after, BatchSize: r.batchSize})
+		if err != nil {
+			return total, err
+		}
+		for _, row := range rows {
+			if !r.keyring.Stale(row.Email) {
+				continue
+			}
+			params := models.ReencryptIdentityEmailParams{ID: row.ID, OldEmail: row.Email}
+			if _, err := r.reencrypt(row.Email, fieldAAD(fieldIdentityEmail, int64(row.TenantID)), &params.Email); err != nil {
+				return total, err
+			}
+			n, err := r.queries.ReencryptIdentityEmail(ctx, params)
+			if err != nil {
+				return total, err
+			}
+			total += n
+		}
+		if len(rows) < int(r.batchSize) {
+			return total, nil
+		}
+		after = rows[len(rows)-1].ID
+	}
+}
+
+// reencrypt 解密后用当前数据密钥重新加密，不需要重新加密时保留原密文，返回明文
+func (r *FieldReencryptor) reencrypt(c fieldcrypt.Ciphertext, aad []byte, out *fieldcrypt.Ciphertext) (string, error) {
+	plaintext, err := r.keyring.Decrypt(c, aad)
+	if err != nil {
+		return "", err
+	}
+	if !r.keyring.Stale(c) {
+		*out = c
+		return plaintext, nil
+	}
+	*out, err = r.keyring.Encrypt(plaintext, aad)
+	return plaintext, err
+}
@@ -0,0 +1,117 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	"connect-go-example/internal/pkg/fieldcrypt"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestKeyring(t *testing.T) *fieldcrypt.Keyring {
+	keyring, err := fieldcrypt.NewKeyring(bytes.Repeat([]byte{9}, 32))
+	require.NoError(t, err)
+	require.NoError(t, keyring.Add(fieldcrypt.DataKey{ID: 1, Key: bytes.Repeat([]byte{1}, 32)}))
+	require.NoError(t, keyring.SetActive(1))
+	return keyring
+}
+
+func TestFieldCodec(t *testing.T) {
+	keyring := newTestKeyring(t)
+	codec := newFieldCodec(keyring, 1)
+
+	email := codec.encrypt(fieldUserEmail, "alice@example.com")
+	name := codec.encrypt(fieldUserDisplayName, "Alice")
+	assert.NoError(t, codec.err)
+	assert.Equal(t, "alice@example.com", codec.decrypt(fieldUserEmail, email))
+	assert.Equal(t, "Alice", codec.decrypt(fieldUserDisplayName, name))
+	assert.NoError(t, codec.err)
+
+	// 查找邮箱不区分大小写，不同租户的索引不同
+	assert.Equal(t, codec.emailIndex("alice@example.com"), codec.emailIndex(" Alice@Example.COM "))
+	assert.NotEqual(t, codec.emailIndex("alice@example.com"), newFieldCodec(keyring, 2).emailIndex("alice@example.com"))
+
+	// 密文不能用在其他列，之后的操作保留第一个错误
+	assert.Empty(t, codec.decrypt(fieldUserDisplayName, email))
+	assert.ErrorIs(t, codec.err, fieldcrypt.ErrDecrypt)
+	assert.Empty(t, codec.decrypt(fieldUserEmail, email))
+	assert.Nil(t, codec.encrypt(fieldUserEmail, "bob@example.com"))
+
+	other := newFieldCodec(keyring, 2)
+	other.decrypt(fieldUserEmail, email)
+	assert.ErrorIs(t, other.err, fieldcrypt.ErrDecrypt)
+}
+
+func TestStateFieldEncryption(t *testing.T) {
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Minute).Truncate(time.Second)
+
+	for name, keyring := range map[string]*fieldcrypt.Keyring{"keyring": newTestKeyring(t), "local": nil} {
+		states := newMemoryStateStore()
+		stepUps := NewStepUpRepo(states, keyring, zap.NewNop())
+		links := NewMagicLinkRepo(states, keyring, zap.NewNop())
+
+		require.NoError(t, stepUps.CreateStepUp(ctx, &model.StepUp{ID: "s1", TenantID: 1, UserID: 1, Email: "alice@example.com", ExpiresAt: expiresAt}))
+		require.NoError(t, links.CreateMagicLink(ctx, &model.MagicLink{ID: "l1", TenantID: 1, UserID: 1, Email: "alice@example.com", ExpiresAt: expiresAt}))
+
+		stepUp, err := stepUps.GetStepUp(ctx, "s1")
+		require.NoError(t, err, name)
+		assert.Equal(t, "alice@example.com", stepUp.Email, name)
+		link, err := links.GetMagicLink(ctx, "l1")
+		require.NoError(t, err, name)
+		assert.Equal(t, "alice@example.com", link.Email, name)
+
+		if keyring == nil {
+			continue
+		}
+		// 存储中没有邮箱明文，记录被复制到其他ID下不能解密
+		for _, key := range []string{stepUpKey("s1"), magicLinkKey("l1")} {
+			value, err := states.Get(ctx, key)
+			require.NoError(t, err)
+			assert.NotContains(t, value, "alice@example.com")
+		}
+		value, _ := states.Get(ctx, stepUpKey("s1"))
+		require.NoError(t, states.Set(ctx, stepUpKey("s2"), value, time.Minute))
+		_, err = stepUps.GetStepUp(ctx, "s2")
+		assert.Error(t, err)
+	}
+}
+
+func TestFieldReencryptor_Reencrypt(t *testing.T) {
+	keyring := newTestKeyring(t)
+	r := &FieldReencryptor{keyring: keyring, l: zap.NewNop()}
+	aad := fieldAAD(fieldUserEmail, 1)
+
+	current, err := keyring.Encrypt("alice@example.com", aad)
+	require.NoError(t, err)
+	var out fieldcrypt.Ciphertext
+	plaintext, err := r.reencrypt(current, aad, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice@example.com", plaintext)
+	assert.Equal(t, current, out)
+
+	// 迁移前的明文和旧密钥的密文都改用当前密钥加密
+	require.NoError(t, keyring.Add(fieldcrypt.DataKey{ID: 2, Key: bytes.Repeat([]byte{2}, 32)}))
+	require.NoError(t, keyring.SetActive(2))
+	for _, c := range []fieldcrypt.Ciphertext{current, fieldcrypt.Unencrypted("alice@example.com")} {
+		plaintext, err = r.reencrypt(c, aad, &out)
+		assert.NoError(t, err)
+		assert.Equal(t, "alice@example.com", plaintext)
+		assert.Equal(t, uint32(2), out.KeyID())
+		assert.False(t, keyring.Stale(out))
+	}
+
+	plaintext, err = r.reencrypt(nil, aad, &out)
+	assert.NoError(t, err)
+	assert.Empty(t, plaintext)
+	assert.Nil(t, out)
+
+	_, err = r.reencrypt(current, fieldAAD(fieldUserEmail, 2), &out)
+	assert.ErrorIs(t, err, fieldcrypt.ErrDecrypt)
+}
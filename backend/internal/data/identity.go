@@ -0,0 +1,209 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	"connect-go-example/internal/data/models"
+	"connect-go-example/internal/pkg/fieldcrypt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+// IdentityRepo 外部身份关联和 OIDC 授权请求的数据访问接口，所有操作都限定在 ctx 中的租户内
+type IdentityRepo interface {
+	GetUserIdentity(ctx context.Context, provider, subject string) (*model.UserIdentity, error)
+	CreateUserIdentity(ctx context.Context, identity *model.UserIdentity) error
+	// CreateFederatedUser 创建用户并关联外部身份，返回用户ID
+	CreateFederatedUser(ctx context.Context, user *model.FederatedUser) (int64, error)
+	// TouchUserIdentity 记录一次登录并更新 IdP 返回的邮箱
+	TouchUserIdentity(ctx context.Context, identity *model.UserIdentity) error
+	SaveFederatedLoginState(ctx context.Context, state *model.FederatedLoginState) error
+	// TakeFederatedLoginState 取出并删除授权请求，保证 state 只能使用一次
+	TakeFederatedLoginState(ctx context.Context, stateHash string) (*model.FederatedLoginState, error)
+}
+
+type identityRepo struct {
+	queries *models.Queries
+	keyring *fieldcrypt.Keyring
+	states  StateStore
+	// cache 创建用户后清除该用户名“不存在”的缓存
+	cache *UserCache
+	l     *zap.Logger
+}
+
+// federatedLoginStateRecord StateStore 中保存的授权请求
+type federatedLoginStateRecord struct {
+	TenantID     int64  `json:"tenant_id"`
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	BindingHash  string `json:"binding_hash"`
+	LinkUserID   int64  `json:"link_user_id,omitempty"`
+	ExpiresAt    int64  `json:"expires_at"`
+}
+
+func NewIdentityRepo(data *Data, states StateStore, cache *UserCache, keyring *fieldcrypt.Keyring, logger *zap.Logger) IdentityRepo {
+	return &identityRepo{
+		queries: models.New(data.query),
+		keyring: keyring,
+		states:  states,
+		cache:   cache,
+		l:       logger,
+	}
+}
+
+func federatedLoginStateKey(stateHash string) string {
+	return fmt.Sprintf("federated_login:%s", stateHash)
+}
+
+func (r *identityRepo) GetUserIdentity(ctx context.Context, provider, subject string) (*model.UserIdentity, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := withTx(ctx, r.queries).GetUserIdentity(ctx, models.GetUserIdentityParams{
+		TenantID: int32(tenantID),
+		Provider: provider,
+		Subject:  subject,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrIdentityNotFound
+		}
+		return nil, err
+	}
+
+	return &model.UserIdentity{
+		TenantID: tenantID,
+		UserID:   int64(row.UserID),
+		Username: row.Username,
+		Provider: provider,
+		Subject:  subject,
+		Disabled: !row.Active,
+	}, nil
+}
+
+func (r *identityRepo) CreateUserIdentity(ctx context.Context, identity *model.UserIdentity) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	codec := newFieldCodec(r.keyring, tenantID)
+	params := models.CreateUserIdentityParams{
+		TenantID: int32(tenantID),
+		UserID:   int32(identity.UserID),
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    codec.encrypt(fieldIdentityEmail, identity.Email),
+	}
+	if codec.err != nil {
+		return codec.err
+	}
+	return identityError(withTx(ctx, r.queries).CreateUserIdentity(ctx, params))
+}
+
+func (r *identityRepo) CreateFederatedUser(ctx context.Context, user *model.FederatedUser) (int64, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	codec := newFieldCodec(r.keyring, tenantID)
+	params := models.CreateFederatedUserParams{
+		TenantID:      int32(tenantID),
+		Username:      user.Username,
+		Email:         codec.encrypt(fieldUserEmail, user.Email),
+		EmailIndex:    codec.emailIndex(user.Email),
+		DisplayName:   codec.encrypt(fieldUserDisplayName, user.DisplayName),
+		Provider:      user.Provider,
+		Subject:       user.Subject,
+		IdentityEmail: codec.encrypt(fieldIdentityEmail, user.Email),
+	}
+	if codec.err != nil {
+		return 0, codec.err
+	}
+	userID, err := withTx(ctx, r.queries).CreateFederatedUser(ctx, params)
+	if err != nil {
+		return 0, identityError(err)
+	}
+	r.cache.Invalidate(ctx, tenantID, user.Username)
+	return int64(userID), nil
+}
+
+func (r *identityRepo) TouchUserIdentity(ctx context.Context, identity *model.UserIdentity) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	codec := newFieldCodec(r.keyring, tenantID)
+	params := models.TouchUserIdentityParams{
+		TenantID: int32(tenantID),
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    codec.encrypt(fieldIdentityEmail, identity.Email),
+	}
+	if codec.err != nil {
+		return codec.err
+	}
+	return withTx(ctx, r.queries).TouchUserIdentity(ctx, params)
+}
+
+func (r *identityRepo) SaveFederatedLoginState(ctx context.Context, state *model.FederatedLoginState) error {
+	value, err := json.Marshal(federatedLoginStateRecord{
+		TenantID:     state.TenantID,
+		Provider:     state.Provider,
+		Nonce:        state.Nonce,
+		CodeVerifier: state.CodeVerifier,
+		BindingHash:  state.BindingHash,
+		LinkUserID:   state.LinkUserID,
+		ExpiresAt:    state.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	return r.states.Set(ctx, federatedLoginStateKey(state.StateHash), string(value), time.Until(state.ExpiresAt))
+}
+
+func (r *identityRepo) TakeFederatedLoginState(ctx context.Context, stateHash string) (*model.FederatedLoginState, error) {
+	value, err := r.states.GetDel(ctx, federatedLoginStateKey(stateHash))
+	if err != nil {
+		if errors.Is(err, ErrStateNotFound) {
+			return nil, model.ErrFederatedStateNotFound
+		}
+		return nil, err
+	}
+
+	var record federatedLoginStateRecord
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return nil, err
+	}
+	return &model.FederatedLoginState{
+		StateHash:    stateHash,
+		TenantID:     record.TenantID,
+		Provider:     record.Provider,
+		Nonce:        record.Nonce,
+		CodeVerifier: record.CodeVerifier,
+		BindingHash:  record.BindingHash,
+		LinkUserID:   record.LinkUserID,
+		ExpiresAt:    time.Unix(record.ExpiresAt, 0),
+	}, nil
+}
+
+// identityError 唯一约束冲突转换为 ErrIdentityConflict
+func identityError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return model.ErrIdentityConflict
+	}
+	return err
+}
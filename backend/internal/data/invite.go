@@ -0,0 +1,127 @@
+package data
+
+import (
+	"context"
+	"errors"
+
+	"connect-go-example/internal/biz/model"
+	"connect-go-example/internal/data/models"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// InviteRepo 邀请码数据访问接口，所有操作都限定在 ctx 中的租户内
+type InviteRepo interface {
+	CreateInvite(ctx context.Context, invite *model.Invite) error
+	// RedeemInvite 占用一次邀请码，不可用时返回 ErrInviteUnavailable
+	RedeemInvite(ctx context.Context, id int64) (*model.Invite, error)
+	CreateInviteRedemption(ctx context.Context, inviteID, userID int64) error
+	ListInvites(ctx context.Context) ([]*model.Invite, error)
+}
+
+type inviteRepo struct {
+	queries *models.Queries
+	l       *zap.Logger
+}
+
+func NewInviteRepo(data *Data, logger *zap.Logger) InviteRepo {
+	switch data.driver {
+	case DriverMemory:
+		return &memoryInviteRepo{store: data.memory}
+	case DriverSQLite:
+		return &sqliteInviteRepo{db: data.sqlite}
+	}
+	return &inviteRepo{
+		queries: models.New(data.query),
+		l:       logger,
+	}
+}
+
+func (r *inviteRepo) CreateInvite(ctx context.Context, invite *model.Invite) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	row, err := withTx(ctx, r.queries).CreateInvite(ctx, models.CreateInviteParams{
+		TenantID:  int32(tenantID),
+		InviterID: int32(invite.InviterID),
+		MaxUses:   invite.MaxUses,
+		ExpiresAt: invite.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	invite.ID = int64(row.ID)
+	invite.TenantID = tenantID
+	invite.CreatedAt = row.CreatedAt
+	return nil
+}
+
+func (r *inviteRepo) RedeemInvite(ctx context.Context, id int64) (*model.Invite, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := withTx(ctx, r.queries).RedeemInvite(ctx, models.RedeemInviteParams{
+		ID:       int32(id),
+		TenantID: int32(tenantID),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrInviteUnavailable
+		}
+		return nil, err
+	}
+
+	return &model.Invite{
+		ID:        int64(row.ID),
+		TenantID:  tenantID,
+		InviterID: int64(row.InviterID),
+		MaxUses:   row.MaxUses,
+		UsedCount: row.UsedCount,
+		ExpiresAt: row.ExpiresAt,
+		CreatedAt: row.CreatedAt,
+	}, nil
+}
+
+func (r *inviteRepo) CreateInviteRedemption(ctx context.Context, inviteID, userID int64) error {
+	return withTx(ctx, r.queries).CreateInviteRedemption(ctx, models.CreateInviteRedemptionParams{
+		InviteID: int32(inviteID),
+		UserID:   int32(userID),
+	})
+}
+
+func (r *inviteRepo) ListInvites(ctx context.Context) ([]*model.Invite, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := withTx(ctx, r.queries).ListInvites(ctx, int32(tenantID))
+	if err != nil {
+		return nil, err
+	}
+
+	invites := make([]*model.Invite, 0, len(rows))
+	for _, row := range rows {
+		invitees := make([]int64, 0, len(row.InviteeIds))
+		for _, id := range row.InviteeIds {
+			invitees = append(invitees, int64(id))
+		}
+		invites = append(invites, &model.Invite{
+			ID:         int64(row.ID),
+			TenantID:   tenantID,
+			InviterID:  int64(row.InviterID),
+			MaxUses:    row.MaxUses,
+			UsedCount:  row.UsedCount,
+			InviteeIDs: invitees,
+			ExpiresAt:  row.ExpiresAt,
+			CreatedAt:  row.CreatedAt,
+		})
+	}
+	return invites, nil
+}
@@ -0,0 +1,502 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+)
+
+// LocalUserRepoTestSuite 对 memory 和 sqlite 驱动运行相同的用例
+type LocalUserRepoTestSuite struct {
+	suite.Suite
+	newRepo func(t *testing.T) (UserRepo, TenantRepo)
+	repo    UserRepo
+	tenants TenantRepo
+	ctx     context.Context
+}
+
+func (suite *LocalUserRepoTestSuite) SetupTest() {
+	suite.repo, suite.tenants = suite.newRepo(suite.T())
+	suite.ctx = model.NewTenantContext(context.Background(), &defaultTenant)
+}
+
+func (suite *LocalUserRepoTestSuite) TestCreateAndGetUser() {
+	id, err := suite.repo.CreateUser(suite.ctx, &model.User{Username: "alice", PasswordHash: "hash", Salt: "salt", KdfVersion: 2, Email: "alice@example.com"})
+	assert.NoError(suite.T(), err)
+
+	user, err := suite.repo.GetUserByName(suite.ctx, "alice")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), id, user.ID)
+	assert.Equal(suite.T(), "hash", user.PasswordHash)
+	assert.Equal(suite.T(), int32(2), user.KdfVersion)
+
+	user, err = suite.repo.GetUserByEmail(suite.ctx, "alice@example.com")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), id, user.ID)
+
+	// 其他租户看不到该用户
+	_, err = suite.repo.GetUserByName(model.NewTenantContext(context.Background(), &model.Tenant{ID: 2}), "alice")
+	assert.ErrorIs(suite.T(), err, model.ErrUserNotFound)
+}
+
+func (suite *LocalUserRepoTestSuite) TestCreateUser_Conflict() {
+	_, err := suite.repo.CreateUser(suite.ctx, &model.User{Username: "alice", PasswordHash: "hash", Salt: "salt", Email: "alice@example.com"})
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.repo.CreateUser(suite.ctx, &model.User{Username: "alice", PasswordHash: "hash", Salt: "salt"})
+	assert.ErrorIs(suite.T(), err, model.ErrUserConflict)
+	_, err = suite.repo.CreateUser(suite.ctx, &model.User{Username: "bob", PasswordHash: "hash", Salt: "salt", Email: "alice@example.com"})
+	assert.ErrorIs(suite.T(), err, model.ErrUserConflict)
+
+	// 没有邮箱的用户互不冲突
+	_, err = suite.repo.CreateUser(suite.ctx, &model.User{Username: "carol", PasswordHash: "hash", Salt: "salt"})
+	assert.NoError(suite.T(), err)
+	_, err = suite.repo.CreateUser(suite.ctx, &model.User{Username: "dave", PasswordHash: "hash", Salt: "salt"})
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *LocalUserRepoTestSuite) TestUpdateCredential() {
+	id, err := suite.repo.CreateUser(suite.ctx, &model.User{Username: "alice", PasswordHash: "hash", Salt: "salt"})
+	assert.NoError(suite.T(), err)
+
+	err = suite.repo.UpdateCredential(suite.ctx, &model.User{ID: id, PasswordHash: "new-hash", Salt: "new-salt", KdfVersion: 3})
+	assert.NoError(suite.T(), err)
+
+	user, err := suite.repo.GetUserByName(suite.ctx, "alice")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "new-hash", user.PasswordHash)
+	assert.Equal(suite.T(), "new-salt", user.Salt)
+	assert.Equal(suite.T(), int32(3), user.KdfVersion)
+}
+
+func (suite *LocalUserRepoTestSuite) TestMarkEmailVerified() {
+	id, err := suite.repo.CreateUser(suite.ctx, &model.User{Username: "alice", PasswordHash: "hash", Salt: "salt", Email: "alice@example.com"})
+	assert.NoError(suite.T(), err)
+	user, err := suite.repo.GetUserByEmail(suite.ctx, "alice@example.com")
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), user.EmailVerified)
+
+	// 邮箱已变化时不标记
+	assert.NoError(suite.T(), suite.repo.MarkEmailVerified(suite.ctx, &model.User{ID: id, Username: "alice", Email: "old@example.com"}))
+	user, err = suite.repo.GetUserByEmail(suite.ctx, "alice@example.com")
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), user.EmailVerified)
+
+	assert.NoError(suite.T(), suite.repo.MarkEmailVerified(suite.ctx, &model.User{ID: id, Username: "alice", Email: "alice@example.com"}))
+	user, err = suite.repo.GetUserByEmail(suite.ctx, "alice@example.com")
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), user.EmailVerified)
+	user, err = suite.repo.GetUserByName(suite.ctx, "alice")
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), user.EmailVerified)
+
+	_, err = suite.repo.CreateUser(suite.ctx, &model.User{Username: "bob", PasswordHash: "hash", Salt: "salt", Email: "bob@example.com", EmailVerified: true})
+	assert.NoError(suite.T(), err)
+	user, err = suite.repo.GetUserByEmail(suite.ctx, "bob@example.com")
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), user.EmailVerified)
+}
+
+func (suite *LocalUserRepoTestSuite) TestSyncDirectoryUser() {
+	created, err := suite.repo.SyncDirectoryUser(suite.ctx, &model.DirectoryUser{Username: "carol", Email: "carol@corp.example.com", Backend: "corp"})
+	assert.NoError(suite.T(), err)
+
+	synced, err := suite.repo.SyncDirectoryUser(suite.ctx, &model.DirectoryUser{Username: "carol", Backend: "corp"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), created.ID, synced.ID)
+
+	user, err := suite.repo.GetUserByName(suite.ctx, "carol")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "corp", user.CredentialBackend)
+	assert.Equal(suite.T(), "carol@corp.example.com", user.Email)
+	// 目录服务返回的邮箱视为已验证
+	assert.True(suite.T(), user.EmailVerified)
+
+	roles, err := suite.repo.GetUserRoles(suite.ctx, user.ID)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), roles)
+}
+
+func (suite *LocalUserRepoTestSuite) TestSyncDirectoryUser_BackendConflict() {
+	_, err := suite.repo.CreateUser(suite.ctx, &model.User{Username: "alice", PasswordHash: "hash", Salt: "salt"})
+	assert.NoError(suite.T(), err)
+	_, err = suite.repo.SyncDirectoryUser(suite.ctx, &model.DirectoryUser{Username: "carol", Backend: "corp"})
+	assert.NoError(suite.T(), err)
+
+	// 有本地凭证的用户和其他目录的用户都不会被接管
+	_, err = suite.repo.SyncDirectoryUser(suite.ctx, &model.DirectoryUser{Username: "alice", DisplayName: "Mallory", Backend: "corp"})
+	assert.ErrorIs(suite.T(), err, model.ErrUserConflict)
+	_, err = suite.repo.SyncDirectoryUser(suite.ctx, &model.DirectoryUser{Username: "carol", Backend: "partner"})
+	assert.ErrorIs(suite.T(), err, model.ErrUserConflict)
+	user, err := suite.repo.GetUserByName(suite.ctx, "alice")
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), user.CredentialBackend)
+	assert.Equal(suite.T(), "hash", user.PasswordHash)
+
+	// 管理员指定后端后可以同步
+	assert.NoError(suite.T(), suite.repo.SetCredentialBackend(suite.ctx, "alice", "corp"))
+	_, err = suite.repo.SyncDirectoryUser(suite.ctx, &model.DirectoryUser{Username: "alice", Backend: "corp"})
+	assert.NoError(suite.T(), err)
+	user, err = suite.repo.GetUserByName(suite.ctx, "alice")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "corp", user.CredentialBackend)
+
+	assert.NoError(suite.T(), suite.repo.SetCredentialBackend(suite.ctx, "alice", ""))
+	user, err = suite.repo.GetUserByName(suite.ctx, "alice")
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), user.CredentialBackend)
+
+	assert.ErrorIs(suite.T(), suite.repo.SetCredentialBackend(suite.ctx, "nobody", "corp"), model.ErrUserNotFound)
+}
+
+func (suite *LocalUserRepoTestSuite) TestAuthChallenge() {
+	assert.NoError(suite.T(), suite.repo.StoreAuthChallenge(suite.ctx, "alice", "challenge", time.Minute))
+
+	challenge, err := suite.repo.GetAuthChallenge(suite.ctx, "alice")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "challenge", challenge)
+
+	// 挑战只能使用一次
+	_, err = suite.repo.GetAuthChallenge(suite.ctx, "alice")
+	assert.Error(suite.T(), err)
+
+	// 过期后读取不到
+	assert.NoError(suite.T(), suite.repo.StoreAuthChallenge(suite.ctx, "alice", "challenge", time.Nanosecond))
+	time.Sleep(time.Millisecond)
+	_, err = suite.repo.GetAuthChallenge(suite.ctx, "alice")
+	assert.Error(suite.T(), err)
+}
+
+func (suite *LocalUserRepoTestSuite) TestGetTenantBySlug() {
+	tenant, err := suite.tenants.GetTenantBySlug(suite.ctx, "default")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), defaultTenant, *tenant)
+
+	_, err = suite.tenants.GetTenantBySlug(suite.ctx, "acme")
+	assert.ErrorIs(suite.T(), err, model.ErrTenantNotFound)
+}
+
+func TestMemoryUserRepoTestSuite(t *testing.T) {
+	suite.Run(t, &LocalUserRepoTestSuite{newRepo: func(*testing.T) (UserRepo, TenantRepo) {
+		return &memoryUserRepo{store: newMemoryStore(), authChallenges: authChallenges{newMemoryStateStore()}, l: zap.NewNop()}, memoryTenantRepo{}
+	}})
+}
+
+func TestSQLiteUserRepoTestSuite(t *testing.T) {
+	suite.Run(t, &LocalUserRepoTestSuite{newRepo: func(t *testing.T) (UserRepo, TenantRepo) {
+		db := newTestSQLite(t)
+		return &sqliteUserRepo{db: db.sqlite, authChallenges: authChallenges{newMemoryStateStore()}, l: zap.NewNop()}, &sqliteTenantRepo{db: db.sqlite}
+	}})
+}
+
+func newTestSQLite(t *testing.T) *Data {
+	db, err := openSQLite(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return &Data{driver: DriverSQLite, sqlite: db}
+}
+
+func TestSQLiteTransactor(t *testing.T) {
+	data := newTestSQLite(t)
+	repo := NewUserRepo(data, newMemoryStateStore(), nil, nil, zap.NewNop())
+	tx := NewTransactor(data, zap.NewNop())
+	ctx := model.NewTenantContext(context.Background(), &defaultTenant)
+	errFailed := errors.New("failed")
+
+	err := tx.WithinTx(ctx, func(ctx context.Context) error {
+		_, err := repo.CreateUser(ctx, &model.User{Username: "alice", PasswordHash: "hash", Salt: "salt"})
+		assert.NoError(t, err)
+
+		// 内层失败只回滚到保存点
+		err = tx.WithinTx(ctx, func(ctx context.Context) error {
+			_, err := repo.CreateUser(ctx, &model.User{Username: "bob", PasswordHash: "hash", Salt: "salt"})
+			assert.NoError(t, err)
+			return errFailed
+		})
+		assert.ErrorIs(t, err, errFailed)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	_, err = repo.GetUserByName(ctx, "alice")
+	assert.NoError(t, err)
+	_, err = repo.GetUserByName(ctx, "bob")
+	assert.ErrorIs(t, err, model.ErrUserNotFound)
+
+	// 外层失败时整个事务回滚
+	err = tx.WithinTx(ctx, func(ctx context.Context) error {
+		_, err := repo.CreateUser(ctx, &model.User{Username: "carol", PasswordHash: "hash", Salt: "salt"})
+		assert.NoError(t, err)
+		return errFailed
+	})
+	assert.ErrorIs(t, err, errFailed)
+	_, err = repo.GetUserByName(ctx, "carol")
+	assert.ErrorIs(t, err, model.ErrUserNotFound)
+}
+
+func TestMemoryAuthRequestRepo(t *testing.T) {
+	ctx := context.Background()
+	repo := NewAuthRequestRepo(&Data{driver: DriverMemory}, zap.NewNop())
+	req := &model.AuthRequest{ID: "req", State: model.AuthRequestStatePending, ExpiresAt: time.Now().Add(time.Minute)}
+	assert.NoError(t, repo.CreateAuthRequest(ctx, req))
+
+	notify, closeSub, err := repo.SubscribeAuthRequest(ctx, "req")
+	assert.NoError(t, err)
+
+	approved := &model.AuthRequest{ID: "req", State: model.AuthRequestStateApproved, UserID: 1, AuthToken: "token"}
+	ok, err := repo.TransitAuthRequest(ctx, approved, model.AuthRequestStatePending)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	select {
+	case <-notify:
+	case <-time.After(time.Second):
+		t.Fatal("no notification")
+	}
+
+	// 状态已经变化，不能再次转换
+	ok, err = repo.TransitAuthRequest(ctx, approved, model.AuthRequestStatePending)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	stored, err := repo.GetAuthRequest(ctx, "req")
+	assert.NoError(t, err)
+	assert.Equal(t, model.AuthRequestStateApproved, stored.State)
+	assert.Equal(t, "token", stored.AuthToken)
+
+	assert.NoError(t, closeSub())
+	assert.NoError(t, closeSub())
+	_, open := <-notify
+	assert.False(t, open)
+
+	assert.NoError(t, repo.DeleteAuthRequest(ctx, "req"))
+	_, err = repo.GetAuthRequest(ctx, "req")
+	assert.ErrorIs(t, err, model.ErrAuthRequestNotFound)
+}
+
+// TestSQLiteSchema sqlite 的表和列与迁移脚本生成的 sqlc 模型一致
+// LocalDeviceInviteTestSuite 对 memory 和 sqlite 驱动的设备和邀请码存储运行相同的用例
+type LocalDeviceInviteTestSuite struct {
+	suite.Suite
+	newData func(t *testing.T) *Data
+	users   UserRepo
+	devices DeviceRepo
+	invites InviteRepo
+	ctx     context.Context
+	other   context.Context
+}
+
+func (suite *LocalDeviceInviteTestSuite) SetupTest() {
+	data := suite.newData(suite.T())
+	suite.users = NewUserRepo(data, newMemoryStateStore(), nil, nil, zap.NewNop())
+	suite.devices = NewDeviceRepo(data, zap.NewNop())
+	suite.invites = NewInviteRepo(data, zap.NewNop())
+	suite.ctx = model.NewTenantContext(context.Background(), &defaultTenant)
+	suite.other = model.NewTenantContext(context.Background(), &model.Tenant{ID: 2})
+}
+
+func (suite *LocalDeviceInviteTestSuite) createUser(username string) int64 {
+	id, err := suite.users.CreateUser(suite.ctx, &model.User{Username: username, PasswordHash: "hash", Salt: "salt"})
+	require.NoError(suite.T(), err)
+	return id
+}
+
+func (suite *LocalDeviceInviteTestSuite) TestDevices() {
+	userID := suite.createUser("alice")
+
+	first := &model.Device{UserID: userID, Fingerprint: "fp-1", DeviceID: "d-1", UserAgent: "ua-1", IPPrefix: "192.0.2.0/24"}
+	assert.NoError(suite.T(), suite.devices.UpsertDevice(suite.ctx, first))
+	assert.NotZero(suite.T(), first.ID)
+	assert.False(suite.T(), first.FirstSeenAt.IsZero())
+
+	// 同一设备再次登录时更新最近登录信息
+	again := &model.Device{UserID: userID, Fingerprint: "fp-1", DeviceID: "d-1", UserAgent: "ua-2", IPPrefix: "198.51.100.0/24"}
+	assert.NoError(suite.T(), suite.devices.UpsertDevice(suite.ctx, again))
+	assert.Equal(suite.T(), first.ID, again.ID)
+	assert.Equal(suite.T(), first.FirstSeenAt, again.FirstSeenAt)
+	assert.NoError(suite.T(), suite.devices.UpsertDevice(suite.ctx, &model.Device{UserID: userID, Fingerprint: "fp-2"}))
+
+	devices, err := suite.devices.ListDevices(suite.ctx, userID)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), devices, 2)
+	for _, d := range devices {
+		if d.Fingerprint == "fp-1" {
+			assert.Equal(suite.T(), "ua-2", d.UserAgent)
+			assert.Equal(suite.T(), "198.51.100.0/24", d.IPPrefix)
+		}
+	}
+
+	devices, err = suite.devices.ListDevices(suite.other, userID)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), devices)
+}
+
+func (suite *LocalDeviceInviteTestSuite) TestLoginEvents() {
+	userID := suite.createUser("alice")
+
+	assert.NoError(suite.T(), suite.devices.CreateLoginEvent(suite.ctx, &model.LoginEvent{UserID: userID, Fingerprint: "fp-1", RiskScore: 40, Reasons: []string{"new_device"}, StepUp: true}))
+	assert.NoError(suite.T(), suite.devices.CreateLoginEvent(suite.ctx, &model.LoginEvent{UserID: userID, Fingerprint: "fp-1"}))
+
+	events, err := suite.devices.ListLoginEvents(suite.ctx, userID, time.Now().Add(-time.Hour))
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), events, 2) {
+		// 最新的在前
+		assert.Empty(suite.T(), events[0].Reasons)
+		assert.Equal(suite.T(), []string{"new_device"}, events[1].Reasons)
+		assert.Equal(suite.T(), int32(40), events[1].RiskScore)
+		assert.True(suite.T(), events[1].StepUp)
+	}
+
+	events, err = suite.devices.ListLoginEvents(suite.ctx, userID, time.Now().Add(time.Hour))
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), events)
+	events, err = suite.devices.ListLoginEvents(suite.other, userID, time.Now().Add(-time.Hour))
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), events)
+}
+
+func (suite *LocalDeviceInviteTestSuite) TestInvites() {
+	inviterID := suite.createUser("alice")
+
+	invite := &model.Invite{InviterID: inviterID, MaxUses: 1, ExpiresAt: time.Now().Add(time.Hour)}
+	assert.NoError(suite.T(), suite.invites.CreateInvite(suite.ctx, invite))
+	assert.NotZero(suite.T(), invite.ID)
+	assert.Equal(suite.T(), defaultTenant.ID, invite.TenantID)
+
+	// 其他租户不能使用
+	_, err := suite.invites.RedeemInvite(suite.other, invite.ID)
+	assert.ErrorIs(suite.T(), err, model.ErrInviteUnavailable)
+
+	redeemed, err := suite.invites.RedeemInvite(suite.ctx, invite.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int32(1), redeemed.UsedCount)
+	assert.Equal(suite.T(), inviterID, redeemed.InviterID)
+	inviteeID := suite.createUser("bob")
+	assert.NoError(suite.T(), suite.invites.CreateInviteRedemption(suite.ctx, invite.ID, inviteeID))
+
+	// 次数用完
+	_, err = suite.invites.RedeemInvite(suite.ctx, invite.ID)
+	assert.ErrorIs(suite.T(), err, model.ErrInviteUnavailable)
+
+	// 已过期
+	expired := &model.Invite{InviterID: inviterID, MaxUses: 5, ExpiresAt: time.Now().Add(-time.Minute)}
+	assert.NoError(suite.T(), suite.invites.CreateInvite(suite.ctx, expired))
+	_, err = suite.invites.RedeemInvite(suite.ctx, expired.ID)
+	assert.ErrorIs(suite.T(), err, model.ErrInviteUnavailable)
+
+	invites, err := suite.invites.ListInvites(suite.ctx)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), invites, 2) {
+		assert.Equal(suite.T(), expired.ID, invites[0].ID)
+		assert.Empty(suite.T(), invites[0].InviteeIDs)
+		assert.Equal(suite.T(), []int64{inviteeID}, invites[1].InviteeIDs)
+		assert.Equal(suite.T(), int32(1), invites[1].UsedCount)
+	}
+	invites, err = suite.invites.ListInvites(suite.other)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), invites)
+}
+
+func TestMemoryDeviceInviteTestSuite(t *testing.T) {
+	suite.Run(t, &LocalDeviceInviteTestSuite{newData: func(*testing.T) *Data {
+		return &Data{driver: DriverMemory, memory: newMemoryStore()}
+	}})
+}
+
+func TestSQLiteDeviceInviteTestSuite(t *testing.T) {
+	suite.Run(t, &LocalDeviceInviteTestSuite{newData: newTestSQLite})
+}
+
+func TestNewData_LocalDriverUnsupportedFeatures(t *testing.T) {
+	for name, cfg := range map[string]*conf.Bootstrap{
+		"scim":       {Data: &conf.Data{Driver: DriverMemory}, Scim: &conf.Scim{Clients: []*conf.Scim_Client{{Name: "idp", Token: "token"}}}},
+		"federation": {Data: &conf.Data{Driver: DriverSQLite}, Federation: &conf.Federation{Providers: []*conf.Federation_Provider{{Id: "google"}}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewData(fxtest.NewLifecycle(t), cfg, nil, nil, nil, zap.NewNop())
+			assert.ErrorContains(t, err, "not supported")
+		})
+	}
+}
+
+func TestSQLiteSchema(t *testing.T) {
+	db := newTestSQLite(t).sqlite
+	tables := map[string]any{
+		"data_keys":             models.DataKey{},
+		"group_members":         models.GroupMember{},
+		"invites":               models.Invite{},
+		"invite_redemptions":    models.InviteRedemption{},
+		"login_events":          models.LoginEvent{},
+		"outbox":                models.Outbox{},
+		"state_entries":         models.StateEntry{},
+		"tenants":               models.Tenant{},
+		"tenant_clients":        models.TenantClient{},
+		"users":                 models.User{},
+		"user_devices":          models.UserDevice{},
+		"user_groups":           models.UserGroup{},
+		"user_identities":       models.UserIdentity{},
+		"user_roles":            models.UserRole{},
+		"webhook_deliveries":    models.WebhookDelivery{},
+		"webhook_subscriptions": models.WebhookSubscription{},
+	}
+
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'")
+	require.NoError(t, err)
+	var names []string
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	require.NoError(t, rows.Err())
+	assert.ElementsMatch(t, slices.Collect(maps.Keys(tables)), names)
+
+	for table, row := range tables {
+		var want []string
+		typ := reflect.TypeOf(row)
+		for i := range typ.NumField() {
+			want = append(want, snakeCase(typ.Field(i).Name))
+		}
+
+		rows, err := db.Query("SELECT name FROM pragma_table_info(?)", table)
+		require.NoError(t, err)
+		var got []string
+		for rows.Next() {
+			var name string
+			require.NoError(t, rows.Scan(&name))
+			got = append(got, name)
+		}
+		require.NoError(t, rows.Err())
+		assert.ElementsMatch(t, want, got, table)
+	}
+}
+
+// snakeCase 把 sqlc 生成的字段名转换为列名，如 KekID 转换为 kek_id
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) && i > 0 && unicode.IsLower(rune(name[i-1])) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,127 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	"connect-go-example/internal/pkg/fieldcrypt"
+
+	"go.uber.org/zap"
+)
+
+// MagicLinkRepo 邮件免密登录数据访问接口
+type MagicLinkRepo interface {
+	CreateMagicLink(ctx context.Context, link *model.MagicLink) error
+	GetMagicLink(ctx context.Context, id string) (*model.MagicLink, error)
+	// ConsumeMagicLink 删除链接，返回是否由本次调用删除，保证链接只能使用一次
+	ConsumeMagicLink(ctx context.Context, id string) (bool, error)
+	// CountMagicLinkRequest 记录 ctx 中租户下该邮箱的一次请求，并返回窗口内的请求次数
+	CountMagicLinkRequest(ctx context.Context, email string, window time.Duration) (int64, error)
+}
+
+// magicLinkRepo 链接和请求计数保存在 StateStore 中；存储不可用时返回错误，不发送链接
+type magicLinkRepo struct {
+	states  StateStore
+	keyring *fieldcrypt.Keyring
+	l       *zap.Logger
+}
+
+// magicLinkRecord StateStore 中保存的登录链接
+type magicLinkRecord struct {
+	TenantID int64  `json:"tenant_id"`
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+	// Email 加密后保存，见 sealStateField
+	Email     fieldcrypt.Ciphertext `json:"email_enc,omitempty"`
+	NonceHash string                `json:"nonce_hash"`
+	CreatedAt int64                 `json:"created_at"`
+	ExpiresAt int64                 `json:"expires_at"`
+}
+
+func NewMagicLinkRepo(states StateStore, keyring *fieldcrypt.Keyring, logger *zap.Logger) MagicLinkRepo {
+	return &magicLinkRepo{
+		states:  states,
+		keyring: keyring,
+		l:       logger,
+	}
+}
+
+func magicLinkKey(id string) string {
+	return fmt.Sprintf("magic_link:%s", id)
+}
+
+func magicLinkRateKey(tenantID int64, email string) string {
+	return fmt.Sprintf("magic_link_rate:%d:%s", tenantID, email)
+}
+
+func (r *magicLinkRepo) CreateMagicLink(ctx context.Context, link *model.MagicLink) error {
+	email, err := sealStateField(r.keyring, fieldMagicLinkEmail, link.TenantID, link.ID, link.Email)
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal(magicLinkRecord{
+		TenantID:  link.TenantID,
+		UserID:    link.UserID,
+		Username:  link.Username,
+		Email:     email,
+		NonceHash: link.NonceHash,
+		CreatedAt: link.CreatedAt.Unix(),
+		ExpiresAt: link.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	return r.states.Set(ctx, magicLinkKey(link.ID), string(value), time.Until(link.ExpiresAt))
+}
+
+func (r *magicLinkRepo) GetMagicLink(ctx context.Context, id string) (*model.MagicLink, error) {
+	value, err := r.states.Get(ctx, magicLinkKey(id))
+	if err != nil {
+		if errors.Is(err, ErrStateNotFound) {
+			return nil, model.ErrMagicLinkNotFound
+		}
+		return nil, err
+	}
+
+	var record magicLinkRecord
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return nil, err
+	}
+	email, err := openStateField(r.keyring, fieldMagicLinkEmail, record.TenantID, id, record.Email)
+	if err != nil {
+		return nil, err
+	}
+	return &model.MagicLink{
+		ID:        id,
+		TenantID:  record.TenantID,
+		UserID:    record.UserID,
+		Username:  record.Username,
+		Email:     email,
+		NonceHash: record.NonceHash,
+		CreatedAt: time.Unix(record.CreatedAt, 0),
+		ExpiresAt: time.Unix(record.ExpiresAt, 0),
+	}, nil
+}
+
+func (r *magicLinkRepo) ConsumeMagicLink(ctx context.Context, id string) (bool, error) {
+	_, err := r.states.GetDel(ctx, magicLinkKey(id))
+	if err != nil {
+		if errors.Is(err, ErrStateNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *magicLinkRepo) CountMagicLinkRequest(ctx context.Context, email string, window time.Duration) (int64, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return r.states.Incr(ctx, magicLinkRateKey(tenantID, email), window)
+}
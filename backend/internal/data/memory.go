@@ -0,0 +1,663 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+
+	"connectrpc.com/connect"
+	"go.uber.org/zap"
+)
+
+// defaultTenant 本地开发驱动内置的默认租户，与迁移脚本中的默认租户一致
+var defaultTenant = model.Tenant{ID: 1, Slug: "default", Name: "Default"}
+
+// memoryStateStore 在进程内保存短期状态，供本地开发驱动使用，也是 Redis 不可用时的默认备用存储
+type memoryStateStore struct {
+	mu    sync.Mutex
+	items map[string]stateItem
+}
+
+type stateItem struct {
+	value     string
+	expiresAt time.Time
+}
+
+func newMemoryStateStore() *memoryStateStore {
+	return &memoryStateStore{items: make(map[string]stateItem)}
+}
+
+func (s *memoryStateStore) Set(_ context.Context, key, value string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 写入时顺带清理过期的状态，避免无人读取的状态一直占用内存
+	now := time.Now()
+	for k, item := range s.items {
+		if now.After(item.expiresAt) {
+			delete(s.items, k)
+		}
+	}
+	s.items[key] = stateItem{value: value, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+func (s *memoryStateStore) Get(_ context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.items[key]
+	if !ok || time.Now().After(item.expiresAt) {
+		return "", ErrStateNotFound
+	}
+	return item.value, nil
+}
+
+func (s *memoryStateStore) GetDel(_ context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.items[key]
+	delete(s.items, key)
+	if !ok || time.Now().After(item.expiresAt) {
+		return "", ErrStateNotFound
+	}
+	return item.value, nil
+}
+
+func (s *memoryStateStore) Del(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, key)
+	return nil
+}
+
+func (s *memoryStateStore) SetNX(_ context.Context, key, value string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if item, ok := s.items[key]; ok && !now.After(item.expiresAt) {
+		return false, nil
+	}
+	s.items[key] = stateItem{value: value, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+func (s *memoryStateStore) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := s.Get(ctx, key)
+	if errors.Is(err, ErrStateNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *memoryStateStore) Incr(_ context.Context, key string, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	item, ok := s.items[key]
+	if !ok || now.After(item.expiresAt) {
+		// 过期的计数从1重新开始
+		item = stateItem{value: "0", expiresAt: now.Add(ttl)}
+	}
+	n, err := strconv.ParseInt(item.value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	n++
+	item.value = strconv.FormatInt(n, 10)
+	s.items[key] = item
+	return n, nil
+}
+
+// memoryOutbox 在进程内保存领域事件，供本地开发驱动使用
+type memoryOutbox struct {
+	mu     sync.Mutex
+	nextID int64
+	events []*model.DomainEvent
+	// published 投递成功的时间，按事件ID索引
+	published map[int64]time.Time
+}
+
+func newMemoryOutbox() *memoryOutbox {
+	return &memoryOutbox{published: make(map[int64]time.Time)}
+}
+
+func (o *memoryOutbox) Append(_ context.Context, event *model.DomainEvent) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.nextID++
+	event.ID = o.nextID
+	stored := *event
+	stored.CreatedAt = time.Now()
+	o.events = append(o.events, &stored)
+	return nil
+}
+
+// ClaimRelay 只有一个进程，总是由自己投递
+func (o *memoryOutbox) ClaimRelay(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (o *memoryOutbox) ListPending(_ context.Context, limit int) ([]*model.DomainEvent, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var events []*model.DomainEvent
+	for _, event := range o.events {
+		if len(events) == limit {
+			break
+		}
+		if _, ok := o.published[event.ID]; !ok {
+			e := *event
+			events = append(events, &e)
+		}
+	}
+	return events, nil
+}
+
+func (o *memoryOutbox) MarkPublished(_ context.Context, ids []int64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, id := range ids {
+		o.published[id] = time.Now()
+	}
+	return nil
+}
+
+func (o *memoryOutbox) PurgePublished(_ context.Context, before time.Time) (int64, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var n int64
+	o.events = slices.DeleteFunc(o.events, func(event *model.DomainEvent) bool {
+		at, ok := o.published[event.ID]
+		if ok && at.Before(before) {
+			delete(o.published, event.ID)
+			n++
+			return true
+		}
+		return false
+	})
+	return n, nil
+}
+
+// memoryAuthRequestRepo 在进程内保存登录请求，供本地开发驱动使用；只有一个进程，状态变化直接通知本进程的订阅方
+type memoryAuthRequestRepo struct {
+	mu       sync.Mutex
+	requests map[string]model.AuthRequest
+	subs     map[string]map[chan struct{}]struct{}
+}
+
+func newMemoryAuthRequestRepo() *memoryAuthRequestRepo {
+	return &memoryAuthRequestRepo{
+		requests: make(map[string]model.AuthRequest),
+		subs:     make(map[string]map[chan struct{}]struct{}),
+	}
+}
+
+func (r *memoryAuthRequestRepo) CreateAuthRequest(_ context.Context, req *model.AuthRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// 写入时顺带清理过期的请求
+	now := time.Now()
+	for id, stored := range r.requests {
+		if now.After(stored.ExpiresAt) {
+			delete(r.requests, id)
+		}
+	}
+	r.requests[req.ID] = *req
+	return nil
+}
+
+func (r *memoryAuthRequestRepo) GetAuthRequest(_ context.Context, id string) (*model.AuthRequest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.requests[id]
+	if !ok || time.Now().After(stored.ExpiresAt) {
+		return nil, model.ErrAuthRequestNotFound
+	}
+	return &stored, nil
+}
+
+func (r *memoryAuthRequestRepo) TransitAuthRequest(_ context.Context, req *model.AuthRequest, from model.AuthRequestState) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.requests[req.ID]
+	if !ok || time.Now().After(stored.ExpiresAt) || stored.State != from {
+		return false, nil
+	}
+	stored.State = req.State
+	stored.UserID = req.UserID
+	stored.AuthToken = req.AuthToken
+	r.requests[req.ID] = stored
+	for notify := range r.subs[req.ID] {
+		select {
+		case notify <- struct{}{}:
+		default:
+			// 已有未处理的通知，订阅方会重新读取最新状态
+		}
+	}
+	return true, nil
+}
+
+func (r *memoryAuthRequestRepo) DeleteAuthRequest(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.requests, id)
+	return nil
+}
+
+func (r *memoryAuthRequestRepo) SubscribeAuthRequest(_ context.Context, id string) (<-chan struct{}, func() error, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	notify := make(chan struct{}, 1)
+	if r.subs[id] == nil {
+		r.subs[id] = make(map[chan struct{}]struct{})
+	}
+	r.subs[id][notify] = struct{}{}
+
+	var once sync.Once
+	return notify, func() error {
+		once.Do(func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			delete(r.subs[id], notify)
+			if len(r.subs[id]) == 0 {
+				delete(r.subs, id)
+			}
+			close(notify)
+		})
+		return nil
+	}, nil
+}
+
+// memoryStore memory 驱动的数据，进程退出后丢失
+type memoryStore struct {
+	mu     sync.RWMutex
+	nextID int64
+	users  map[int64]*model.User
+	roles  map[int64][]string
+
+	nextDeviceID int64
+	devices      []*memoryDevice
+	loginEvents  []*memoryLoginEvent
+	nextInviteID int64
+	invites      map[int64]*model.Invite
+}
+
+// memoryDevice 和 memoryLoginEvent 记录所属租户，model 中没有租户字段
+type memoryDevice struct {
+	tenantID int64
+	device   model.Device
+}
+
+type memoryLoginEvent struct {
+	tenantID int64
+	event    model.LoginEvent
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		users:   make(map[int64]*model.User),
+		roles:   make(map[int64][]string),
+		invites: make(map[int64]*model.Invite),
+	}
+}
+
+// find 返回租户内第一个满足 match 的用户，调用方需持有锁
+func (s *memoryStore) find(tenantID int64, match func(u *model.User) bool) *model.User {
+	for _, u := range s.users {
+		if u.TenantID == tenantID && match(u) {
+			return u
+		}
+	}
+	return nil
+}
+
+type memoryUserRepo struct {
+	store *memoryStore
+	authChallenges
+	l *zap.Logger
+}
+
+func (r *memoryUserRepo) GetUserByName(ctx context.Context, username string) (*model.User, error) {
+	return r.get(ctx, func(u *model.User) bool { return u.Username == username })
+}
+
+func (r *memoryUserRepo) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	return r.get(ctx, func(u *model.User) bool { return email != "" && u.Email == email })
+}
+
+func (r *memoryUserRepo) get(ctx context.Context, match func(u *model.User) bool) (*model.User, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	u := r.store.find(tenantID, match)
+	if u == nil {
+		return nil, model.ErrUserNotFound
+	}
+	user := *u
+	return &user, nil
+}
+
+func (r *memoryUserRepo) CreateUser(ctx context.Context, req *model.User) (int64, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if r.store.find(tenantID, func(u *model.User) bool {
+		return u.Username == req.Username || (req.Email != "" && u.Email == req.Email)
+	}) != nil {
+		return 0, model.ErrUserConflict
+	}
+	r.store.nextID++
+	r.store.users[r.store.nextID] = &model.User{
+		ID:            r.store.nextID,
+		TenantID:      tenantID,
+		Username:      req.Username,
+		PasswordHash:  req.PasswordHash,
+		Salt:          req.Salt,
+		KdfVersion:    req.KdfVersion,
+		Email:         req.Email,
+		EmailVerified: req.EmailVerified && req.Email != "",
+	}
+	return r.store.nextID, nil
+}
+
+func (r *memoryUserRepo) MarkEmailVerified(ctx context.Context, user *model.User) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if u, ok := r.store.users[user.ID]; ok && u.TenantID == tenantID && user.Email != "" && u.Email == user.Email {
+		u.EmailVerified = true
+	}
+	return nil
+}
+
+func (r *memoryUserRepo) SyncDirectoryUser(ctx context.Context, user *model.DirectoryUser) (*model.User, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	existing := r.store.find(tenantID, func(u *model.User) bool { return u.Username == user.Username })
+	if existing != nil && existing.CredentialBackend != user.Backend && (existing.CredentialBackend != "" || existing.PasswordHash != "") {
+		return nil, model.ErrUserConflict
+	}
+	if user.Email != "" && r.store.find(tenantID, func(u *model.User) bool {
+		return u.Email == user.Email && u != existing
+	}) != nil {
+		return nil, model.ErrUserConflict
+	}
+	if existing == nil {
+		r.store.nextID++
+		existing = &model.User{ID: r.store.nextID, TenantID: tenantID, Username: user.Username, CredentialBackend: user.Backend}
+		r.store.users[existing.ID] = existing
+	}
+	if user.Email != "" {
+		existing.Email, existing.EmailVerified = user.Email, true
+	}
+
+	return &model.User{
+		ID:            existing.ID,
+		TenantID:      tenantID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.Email != "",
+		Disabled:      existing.Disabled,
+	}, nil
+}
+
+func (r *memoryUserRepo) GetUserRoles(ctx context.Context, userID int64) ([]string, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	if u, ok := r.store.users[userID]; !ok || u.TenantID != tenantID {
+		return nil, nil
+	}
+	return slices.Clone(r.store.roles[userID]), nil
+}
+
+func (r *memoryUserRepo) UpdateCredential(ctx context.Context, user *model.User) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if u, ok := r.store.users[user.ID]; ok && u.TenantID == tenantID {
+		u.PasswordHash, u.Salt, u.KdfVersion = user.PasswordHash, user.Salt, user.KdfVersion
+	}
+	return nil
+}
+
+func (r *memoryUserRepo) SetCredentialBackend(ctx context.Context, username, backend string) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	u := r.store.find(tenantID, func(u *model.User) bool { return u.Username == username })
+	if u == nil {
+		return model.ErrUserNotFound
+	}
+	u.CredentialBackend = backend
+	return nil
+}
+
+type memoryDeviceRepo struct {
+	store *memoryStore
+}
+
+func (r *memoryDeviceRepo) ListDevices(ctx context.Context, userID int64) ([]*model.Device, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	var devices []*model.Device
+	for _, d := range r.store.devices {
+		if d.tenantID == tenantID && d.device.UserID == userID {
+			device := d.device
+			devices = append(devices, &device)
+		}
+	}
+	slices.SortFunc(devices, func(a, b *model.Device) int { return b.LastSeenAt.Compare(a.LastSeenAt) })
+	return devices, nil
+}
+
+func (r *memoryDeviceRepo) UpsertDevice(ctx context.Context, device *model.Device) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	now := time.Now()
+	for _, d := range r.store.devices {
+		if d.tenantID == tenantID && d.device.UserID == device.UserID && d.device.Fingerprint == device.Fingerprint {
+			d.device.UserAgent, d.device.IPPrefix, d.device.LocationHint = device.UserAgent, device.IPPrefix, device.LocationHint
+			d.device.LastSeenAt = now
+			device.ID, device.FirstSeenAt, device.LastSeenAt = d.device.ID, d.device.FirstSeenAt, d.device.LastSeenAt
+			return nil
+		}
+	}
+	r.store.nextDeviceID++
+	device.ID, device.FirstSeenAt, device.LastSeenAt = r.store.nextDeviceID, now, now
+	r.store.devices = append(r.store.devices, &memoryDevice{tenantID: tenantID, device: *device})
+	return nil
+}
+
+func (r *memoryDeviceRepo) CreateLoginEvent(ctx context.Context, event *model.LoginEvent) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	e := *event
+	e.Reasons = slices.Clone(event.Reasons)
+	e.CreatedAt = time.Now()
+	r.store.loginEvents = append(r.store.loginEvents, &memoryLoginEvent{tenantID: tenantID, event: e})
+	return nil
+}
+
+func (r *memoryDeviceRepo) ListLoginEvents(ctx context.Context, userID int64, since time.Time) ([]*model.LoginEvent, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	// 与 Postgres 的 ListLoginEvents 一致，最新的在前，最多500条
+	var events []*model.LoginEvent
+	for i := len(r.store.loginEvents) - 1; i >= 0 && len(events) < 500; i-- {
+		e := r.store.loginEvents[i]
+		if e.tenantID == tenantID && e.event.UserID == userID && e.event.CreatedAt.After(since) {
+			event := e.event
+			event.Reasons = slices.Clone(e.event.Reasons)
+			events = append(events, &event)
+		}
+	}
+	return events, nil
+}
+
+type memoryInviteRepo struct {
+	store *memoryStore
+}
+
+func (r *memoryInviteRepo) CreateInvite(ctx context.Context, invite *model.Invite) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.store.nextInviteID++
+	invite.ID, invite.TenantID, invite.CreatedAt = r.store.nextInviteID, tenantID, time.Now()
+	stored := *invite
+	stored.UsedCount, stored.InviteeIDs = 0, nil
+	r.store.invites[invite.ID] = &stored
+	return nil
+}
+
+func (r *memoryInviteRepo) RedeemInvite(ctx context.Context, id int64) (*model.Invite, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	invite, ok := r.store.invites[id]
+	if !ok || invite.TenantID != tenantID || invite.UsedCount >= invite.MaxUses || !invite.ExpiresAt.After(time.Now()) {
+		return nil, model.ErrInviteUnavailable
+	}
+	invite.UsedCount++
+	redeemed := *invite
+	redeemed.InviteeIDs = nil
+	return &redeemed, nil
+}
+
+func (r *memoryInviteRepo) CreateInviteRedemption(_ context.Context, inviteID, userID int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	invite, ok := r.store.invites[inviteID]
+	if !ok {
+		return model.ErrInviteUnavailable
+	}
+	if !slices.Contains(invite.InviteeIDs, userID) {
+		invite.InviteeIDs = append(invite.InviteeIDs, userID)
+	}
+	return nil
+}
+
+func (r *memoryInviteRepo) ListInvites(ctx context.Context) ([]*model.Invite, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	invites := make([]*model.Invite, 0)
+	for _, i := range r.store.invites {
+		if i.TenantID == tenantID {
+			invite := *i
+			invite.InviteeIDs = append([]int64{}, i.InviteeIDs...)
+			invites = append(invites, &invite)
+		}
+	}
+	slices.SortFunc(invites, func(a, b *model.Invite) int { return int(b.ID - a.ID) })
+	return invites, nil
+}
+
+// memoryTenantRepo 只有默认租户
+type memoryTenantRepo struct{}
+
+func (memoryTenantRepo) GetTenantBySlug(_ context.Context, slug string) (*model.Tenant, error) {
+	if !strings.EqualFold(slug, defaultTenant.Slug) {
+		return nil, model.ErrTenantNotFound
+	}
+	t := defaultTenant
+	return &t, nil
+}
+
+func (memoryTenantRepo) GetTenantByClientID(context.Context, string) (*model.Tenant, error) {
+	return nil, model.ErrTenantNotFound
+}
+
+// localCheckRepo 本地开发驱动不依赖外部服务，ping 为 nil 时总是就绪
+type localCheckRepo struct {
+	ping func(ctx context.Context) error
+}
+
+func (c localCheckRepo) Ready(ctx context.Context, _ model.HealthCheckReq) (model.HealthCheckReply, error) {
+	if c.ping != nil {
+		if err := c.ping(ctx); err != nil {
+			return model.HealthCheckReply{
+				Status: "Unhealthy",
+				Details: map[string]string{
+					"Components": "SQLite",
+					"Message":    err.Error(),
+				},
+			}, connect.NewError(connect.CodeUnavailable, err)
+		}
+	}
+	return model.HealthCheckReply{Status: "Ready"}, nil
+}
+
+// memoryTransactor memory 驱动没有事务，直接执行 fn，失败时已写入的数据不会回滚
+type memoryTransactor struct{}
+
+func (memoryTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
@@ -0,0 +1,86 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"connect-go-example/internal/data/models"
+	"connect-go-example/internal/pkg/migrate"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// legacySchema 最初的 schema.sql，迁移之前手工建库使用
+const legacySchema = `CREATE TABLE users
+(
+    id            SERIAL PRIMARY KEY,
+    username      VARCHAR(255) UNIQUE       NOT NULL,
+    password_hash VARCHAR(255)              NOT NULL,
+    salt          VARCHAR(255)              NOT NULL,
+    created_at    timestamptz DEFAULT now() NOT NULL,
+    updated_at    timestamptz DEFAULT now() NOT NULL
+)`
+
+// TestMigrator_BaselineLegacySchema 按旧 schema.sql 建的库 baseline 后执行全部迁移，
+// 需要 MIGRATE_TEST_DSN 指向可以创建 schema 的 PostgreSQL
+func TestMigrator_BaselineLegacySchema(t *testing.T) {
+	dsn := os.Getenv("MIGRATE_TEST_DSN")
+	if dsn == "" {
+		t.Skip("MIGRATE_TEST_DSN not set")
+	}
+	ctx := context.Background()
+
+	// 每次在独立的 schema 中执行，结束后删除
+	admin, err := pgxpool.New(ctx, dsn)
+	require.NoError(t, err)
+	defer admin.Close()
+	schemaName := fmt.Sprintf("migrate_test_%d", time.Now().UnixNano())
+	_, err = admin.Exec(ctx, "CREATE SCHEMA "+schemaName)
+	require.NoError(t, err)
+	defer func() { _, _ = admin.Exec(ctx, "DROP SCHEMA "+schemaName+" CASCADE") }()
+
+	cfg, err := pgxpool.ParseConfig(dsn)
+	require.NoError(t, err)
+	cfg.ConnConfig.RuntimeParams["search_path"] = schemaName
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	_, err = pool.Exec(ctx, legacySchema)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, "INSERT INTO users (username, password_hash, salt) VALUES ('alice', 'hash', 'salt')")
+	require.NoError(t, err)
+
+	m, err := NewMigrator(pool, zap.NewNop())
+	require.NoError(t, err)
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+
+	_, err = m.Up(ctx)
+	assert.ErrorIs(t, err, migrate.ErrUnversioned)
+
+	n, err := m.Baseline(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	n, err = m.Up(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, len(statuses)-1, n)
+
+	// 已有用户归入默认租户，新增的列使用默认值
+	user, err := models.New(pool).GetUserByName(ctx, models.GetUserByNameParams{TenantID: 1, Username: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, "hash", user.PasswordHash)
+	assert.True(t, user.Active)
+	assert.Nil(t, user.Email)
+
+	// 每个迁移都可以回滚
+	n, err = m.Down(ctx, len(statuses))
+	require.NoError(t, err)
+	assert.Equal(t, len(statuses), n)
+}
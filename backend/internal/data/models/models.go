@@ -6,14 +6,182 @@ package models
 
 import (
 	"time"
+
+	"connect-go-example/internal/pkg/fieldcrypt"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// 数据密钥，新数据使用最新的 encryption 密钥加密，旧密钥保留用于解密
+type DataKey struct {
+	ID         int32
+	Purpose    string
+	KekID      string
+	WrappedKey []byte
+	CreatedAt  time.Time
+}
+
+// 用户组成员
+type GroupMember struct {
+	GroupID   int32
+	UserID    int32
+	CreatedAt time.Time
+}
+
+// 邀请码表，邀请码本身由服务端签名生成，不落库
+type Invite struct {
+	ID        int32
+	TenantID  int32
+	InviterID int32
+	MaxUses   int32
+	UsedCount int32
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// 邀请码使用记录
+type InviteRedemption struct {
+	InviteID  int32
+	UserID    int32
+	CreatedAt time.Time
+}
+
+// 成功登录记录，用于统计常用时段和网段
+type LoginEvent struct {
+	ID          int64
+	TenantID    int32
+	UserID      int32
+	Fingerprint string
+	IpPrefix    string
+	RiskScore   int32
+	Reasons     []string
+	StepUp      bool
+	CreatedAt   time.Time
+}
+
+// 待投递和已投递的领域事件
+type Outbox struct {
+	ID            int64
+	TenantID      int32
+	AggregateType string
+	AggregateID   string
+	EventType     string
+	Payload       []byte
+	CreatedAt     time.Time
+	// 投递成功的时间，为空表示待投递
+	PublishedAt pgtype.Timestamptz
+}
+
+// 短期状态，Redis 的备用存储
+type StateEntry struct {
+	Key       string
+	Value     string
+	ExpiresAt time.Time
+}
+
+// 租户表
+type Tenant struct {
+	ID        int32
+	Slug      string
+	Name      string
+	CreatedAt time.Time
+}
+
+// 客户端所属租户
+type TenantClient struct {
+	ClientID string
+	TenantID int32
+}
+
 // 用户表
 type User struct {
+	ID                int32
+	Username          string
+	PasswordHash      string
+	Salt              string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	TenantID          int32
+	KdfVersion        int32
+	Email             fieldcrypt.Ciphertext
+	ExternalID        *string
+	DisplayName       fieldcrypt.Ciphertext
+	GivenName         fieldcrypt.Ciphertext
+	FamilyName        fieldcrypt.Ciphertext
+	Active            bool
+	CredentialBackend *string
+	// 规范化邮箱的盲索引，用于按邮箱查找
+	EmailIndex fieldcrypt.BlindIndex
+	// 邮箱已验证
+	EmailVerified bool
+}
+
+// 用户登录过的设备
+type UserDevice struct {
 	ID           int32
-	Username     string
-	PasswordHash string
-	Salt         string
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	TenantID     int32
+	UserID       int32
+	Fingerprint  string
+	DeviceID     string
+	UserAgent    string
+	IpPrefix     string
+	LocationHint string
+	FirstSeenAt  time.Time
+	LastSeenAt   time.Time
+}
+
+// 用户组，由 SCIM 客户端下发
+type UserGroup struct {
+	ID          int32
+	TenantID    int32
+	DisplayName string
+	ExternalID  *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// 外部 OIDC 身份与本地用户的关联
+type UserIdentity struct {
+	ID          int32
+	TenantID    int32
+	UserID      int32
+	Provider    string
+	Subject     string
+	Email       fieldcrypt.Ciphertext
+	CreatedAt   time.Time
+	LastLoginAt time.Time
+}
+
+// 用户角色表
+type UserRole struct {
+	UserID    int32
+	Role      string
+	CreatedAt time.Time
+}
+
+// Webhook 投递记录
+type WebhookDelivery struct {
+	ID             int64
+	TenantID       int32
+	SubscriptionID int32
+	EventID        int64
+	EventType      string
+	Payload        []byte
+	Status         string
+	Attempts       int32
+	NextAttemptAt  time.Time
+	LastAttemptAt  pgtype.Timestamptz
+	ResponseStatus *int32
+	LastError      *string
+	DeliveredAt    pgtype.Timestamptz
+	CreatedAt      time.Time
+}
+
+// Webhook 订阅
+type WebhookSubscription struct {
+	ID        int32
+	TenantID  int32
+	Url       string
+	Events    []string
+	Secret    string
+	CreatedAt time.Time
 }
@@ -6,27 +6,608 @@ package models
 
 import (
 	"context"
+	"time"
 )
 
 type Querier interface {
-	//CreateUser
+	// 只添加同一租户内存在的用户
+	//
+	//  INSERT INTO group_members (group_id, user_id)
+	//  SELECT $1, u.id
+	//  FROM users u
+	//  WHERE u.tenant_id = $2
+	//    AND u.id = ANY ($3::INTEGER[])
+	//  ON CONFLICT DO NOTHING
+	AddGroupMembers(ctx context.Context, arg AddGroupMembersParams) error
+	// 先按聚合加事务级咨询锁再分配 id，同一聚合的事件按提交顺序得到递增的 id
+	//
+	//  WITH aggregate_lock AS (SELECT pg_advisory_xact_lock(hashtextextended($2::text || ':' || $3::text, 0)))
+	//  INSERT
+	//  INTO outbox (tenant_id, aggregate_type, aggregate_id, event_type, payload)
+	//  SELECT $1, $2, $3, $4, $5
+	//  FROM aggregate_lock
+	//  RETURNING id
+	AppendOutboxEvent(ctx context.Context, arg AppendOutboxEventParams) (int64, error)
+	// 取出到期的投递并把 next_attempt_at 推迟到租约结束，投递中的副本崩溃后由其他副本在租约到期后重试
+	//
+	//  WITH due AS (SELECT d.id
+	//               FROM webhook_deliveries d
+	//               WHERE d.status = 'pending'
+	//                 AND d.next_attempt_at <= now()
+	//               ORDER BY d.next_attempt_at
+	//               LIMIT $2 FOR UPDATE SKIP LOCKED)
+	//  UPDATE webhook_deliveries d
+	//  SET next_attempt_at = now() + make_interval(secs => $1::float8)
+	//  FROM due,
+	//       webhook_subscriptions s
+	//  WHERE d.id = due.id
+	//    AND s.id = d.subscription_id
+	//  RETURNING d.id, d.tenant_id, d.subscription_id, d.event_id, d.event_type, d.payload, d.attempts, d.created_at, s.url, s.secret
+	ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]ClaimWebhookDeliveriesRow, error)
+	//ClearGroupMembers
+	//
+	//  DELETE
+	//  FROM group_members
+	//  WHERE group_id = $1
+	ClearGroupMembers(ctx context.Context, groupID int32) error
+	//CountGroups
+	//
+	//  SELECT count(*)
+	//  FROM user_groups
+	//  WHERE tenant_id = $1
+	//    AND ($2::TEXT IS NULL OR lower(display_name) LIKE lower($2))
+	//    AND ($3::TEXT IS NULL OR external_id LIKE $3)
+	CountGroups(ctx context.Context, arg CountGroupsParams) (int64, error)
+	//CountScimUsers
+	//
+	//  SELECT count(*)
+	//  FROM users
+	//  WHERE tenant_id = $1
+	//    AND ($2::TEXT IS NULL OR lower(username) LIKE lower($2))
+	//    AND ($3::TEXT IS NULL OR external_id LIKE $3)
+	//    AND ($4::BYTEA IS NULL OR email_index = $4 OR
+	//         (email_index IS NULL AND email = $5::BYTEA))
+	//    AND (NOT $6::BOOLEAN OR email IS NOT NULL)
+	CountScimUsers(ctx context.Context, arg CountScimUsersParams) (int64, error)
+	//CreateDataKey
+	//
+	//  INSERT INTO data_keys (purpose, kek_id, wrapped_key)
+	//  VALUES ($1, $2, $3)
+	//  RETURNING id, created_at
+	CreateDataKey(ctx context.Context, arg CreateDataKeyParams) (CreateDataKeyRow, error)
+	// 同一语句中创建用户和身份关联，任一冲突时都不会留下没有关联的用户；两个表的邮箱密文不同
 	//
-	//  INSERT INTO users (username, password_hash, salt)
+	//  WITH new_user AS (
+	//      INSERT INTO users (tenant_id, username, password_hash, salt, email, email_index, display_name, email_verified)
+	//          VALUES ($4, $5, '', '', $6, $7, $8, $6 IS NOT NULL)
+	//          RETURNING id, tenant_id)
+	//  INSERT
+	//  INTO user_identities (tenant_id, user_id, provider, subject, email)
+	//  SELECT tenant_id, id, $1, $2, $3::BYTEA
+	//  FROM new_user
+	//  RETURNING user_id
+	CreateFederatedUser(ctx context.Context, arg CreateFederatedUserParams) (int32, error)
+	//CreateGroup
+	//
+	//  INSERT INTO user_groups (tenant_id, display_name, external_id)
 	//  VALUES ($1, $2, $3)
-	//  RETURNING id, username, password_hash, salt, created_at, updated_at
-	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	//  RETURNING id, display_name, external_id, created_at, updated_at
+	CreateGroup(ctx context.Context, arg CreateGroupParams) (CreateGroupRow, error)
+	//CreateInvite
+	//
+	//  INSERT INTO invites (tenant_id, inviter_id, max_uses, expires_at)
+	//  VALUES ($1, $2, $3, $4)
+	//  RETURNING id, created_at
+	CreateInvite(ctx context.Context, arg CreateInviteParams) (CreateInviteRow, error)
+	//CreateInviteRedemption
+	//
+	//  INSERT INTO invite_redemptions (invite_id, user_id)
+	//  VALUES ($1, $2)
+	CreateInviteRedemption(ctx context.Context, arg CreateInviteRedemptionParams) error
+	//CreateLoginEvent
+	//
+	//  INSERT INTO login_events (tenant_id, user_id, fingerprint, ip_prefix, risk_score, reasons, step_up)
+	//  VALUES ($1, $2, $3, $4, $5, $6, $7)
+	CreateLoginEvent(ctx context.Context, arg CreateLoginEventParams) error
+	// SCIM 下发的用户没有凭证，password_hash 为空时无法通过凭证登录；IdP 下发的邮箱视为已验证
+	//
+	//  INSERT INTO users (tenant_id, username, password_hash, salt, email, email_index, external_id, display_name, given_name, family_name, active, email_verified)
+	//  VALUES ($1, $2, '', '', $3, $4, $5, $6, $7, $8, $9, $3 IS NOT NULL)
+	//  RETURNING id, external_id, username, display_name, given_name, family_name, email, active, created_at, updated_at
+	CreateScimUser(ctx context.Context, arg CreateScimUserParams) (CreateScimUserRow, error)
+	//CreateUser
+	//
+	//  INSERT INTO users (tenant_id, username, password_hash, salt, email, email_index, kdf_version, email_verified)
+	//  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	//  RETURNING id, tenant_id, username, password_hash, salt, email, kdf_version, created_at, updated_at
+	CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error)
+	//CreateUserIdentity
+	//
+	//  INSERT INTO user_identities (tenant_id, user_id, provider, subject, email)
+	//  VALUES ($1, $2, $3, $4, $5)
+	CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) error
+	//CreateWebhookSubscription
+	//
+	//  INSERT INTO webhook_subscriptions (tenant_id, url, events, secret)
+	//  VALUES ($1, $2, $3, $4)
+	//  RETURNING id, created_at
+	CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (CreateWebhookSubscriptionRow, error)
+	//DeleteGroup
+	//
+	//  DELETE
+	//  FROM user_groups
+	//  WHERE tenant_id = $1
+	//    AND id = $2
+	DeleteGroup(ctx context.Context, arg DeleteGroupParams) (int64, error)
+	//DeleteStateEntry
+	//
+	//  DELETE
+	//  FROM state_entries
+	//  WHERE key = $1
+	DeleteStateEntry(ctx context.Context, key string) error
+	//DeleteUser
+	//
+	//  DELETE
+	//  FROM users
+	//  WHERE tenant_id = $1
+	//    AND id = $2
+	DeleteUser(ctx context.Context, arg DeleteUserParams) (int64, error)
+	//DeleteWebhookSubscription
+	//
+	//  DELETE
+	//  FROM webhook_subscriptions
+	//  WHERE id = $1
+	//    AND tenant_id = $2
+	DeleteWebhookSubscription(ctx context.Context, arg DeleteWebhookSubscriptionParams) (int64, error)
+	// 为订阅了该事件的每个订阅创建一条投递记录，重复投递的事件不会重复创建
+	//
+	//  INSERT
+	//  INTO webhook_deliveries (tenant_id, subscription_id, event_id, event_type, payload)
+	//  SELECT s.tenant_id, s.id, $1, $2, $3
+	//  FROM webhook_subscriptions s
+	//  WHERE s.tenant_id = $4
+	//    AND $2::text = ANY (s.events)
+	//  ON CONFLICT (subscription_id, event_id) DO NOTHING
+	EnqueueWebhookDeliveries(ctx context.Context, arg EnqueueWebhookDeliveriesParams) (int64, error)
+	//GetGroup
+	//
+	//  SELECT id, display_name, external_id, created_at, updated_at
+	//  FROM user_groups
+	//  WHERE tenant_id = $1
+	//    AND id = $2
+	GetGroup(ctx context.Context, arg GetGroupParams) (GetGroupRow, error)
+	//GetScimUser
+	//
+	//  SELECT id, external_id, username, display_name, given_name, family_name, email, active, created_at, updated_at
+	//  FROM users
+	//  WHERE tenant_id = $1
+	//    AND id = $2
+	GetScimUser(ctx context.Context, arg GetScimUserParams) (GetScimUserRow, error)
+	// state_entries 是 UNLOGGED 表，只读副本上没有数据，调用方必须使用主库连接
+	//
+	//  SELECT value
+	//  FROM state_entries
+	//  WHERE key = $1
+	//    AND expires_at > now()
+	GetStateEntry(ctx context.Context, key string) (string, error)
+	//GetTenantByClientID
+	//
+	//  SELECT t.id, t.slug, t.name
+	//  FROM tenants t
+	//           JOIN tenant_clients c ON c.tenant_id = t.id
+	//  WHERE c.client_id = $1
+	GetTenantByClientID(ctx context.Context, clientID string) (GetTenantByClientIDRow, error)
+	//GetTenantBySlug
+	//
+	//  SELECT id, slug, name
+	//  FROM tenants
+	//  WHERE slug = $1
+	GetTenantBySlug(ctx context.Context, slug string) (GetTenantBySlugRow, error)
+	// 尚未重新加密的旧数据没有盲索引，按迁移后的明文格式匹配
+	//
+	//  SELECT username, salt, id, password_hash, email, kdf_version, active, email_verified
+	//  FROM users
+	//  WHERE tenant_id = $1
+	//    AND (email_index = $2 OR (email_index IS NULL AND email = $3))
+	GetUserByEmail(ctx context.Context, arg GetUserByEmailParams) (GetUserByEmailRow, error)
 	//GetUserByName
 	//
-	//  SELECT username, salt, id, password_hash
+	//  SELECT username, salt, id, password_hash, kdf_version, email, active, credential_backend, email_verified
 	//  FROM users
-	//  WHERE username = $1
-	GetUserByName(ctx context.Context, username string) (GetUserByNameRow, error)
+	//  WHERE tenant_id = $1
+	//    AND username = $2
+	GetUserByName(ctx context.Context, arg GetUserByNameParams) (GetUserByNameRow, error)
+	//GetUserIdentity
+	//
+	//  SELECT i.user_id, u.username, u.active
+	//  FROM user_identities i
+	//           JOIN users u ON u.id = i.user_id
+	//  WHERE i.tenant_id = $1
+	//    AND i.provider = $2
+	//    AND i.subject = $3
+	GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (GetUserIdentityRow, error)
+	//GetUserRoles
+	//
+	//  SELECT r.role
+	//  FROM user_roles r
+	//           JOIN users u ON u.id = r.user_id
+	//  WHERE u.tenant_id = $1
+	//    AND r.user_id = $2
+	//  ORDER BY r.role
+	GetUserRoles(ctx context.Context, arg GetUserRolesParams) ([]string, error)
+	// 过期的计数从1重新开始，过期时间只在计数开始时设置
+	//
+	//  INSERT INTO state_entries (key, value, expires_at)
+	//  VALUES ($1, '1', $2)
+	//  ON CONFLICT (key) DO UPDATE
+	//      SET value      = CASE
+	//                           WHEN state_entries.expires_at > now() THEN (state_entries.value::bigint + 1)::text
+	//                           ELSE '1' END,
+	//          expires_at = CASE
+	//                           WHEN state_entries.expires_at > now() THEN state_entries.expires_at
+	//                           ELSE EXCLUDED.expires_at END
+	//  RETURNING value::bigint
+	IncrStateEntry(ctx context.Context, arg IncrStateEntryParams) (int64, error)
 	//InsertTestUser
 	//
-	//  INSERT INTO users(username, password_hash, salt)
-	//  VALUES ('admin', 'asdas', '123123')
-	//  RETURNING id, username, password_hash, salt, created_at, updated_at
+	//  INSERT INTO users(tenant_id, username, password_hash, salt)
+	//  VALUES (1, 'admin', 'asdas', '123123')
+	//  RETURNING id, username, password_hash, salt, created_at, updated_at, tenant_id, kdf_version, email, external_id, display_name, given_name, family_name, active, credential_backend, email_index, email_verified
 	InsertTestUser(ctx context.Context) (User, error)
+	//ListDataKeys
+	//
+	//  SELECT id, purpose, kek_id, wrapped_key, created_at
+	//  FROM data_keys
+	//  ORDER BY id
+	ListDataKeys(ctx context.Context) ([]DataKey, error)
+	//ListGroupMembers
+	//
+	//  SELECT m.group_id, u.id AS user_id, u.username
+	//  FROM group_members m
+	//           JOIN users u ON u.id = m.user_id
+	//  WHERE u.tenant_id = $1
+	//    AND m.group_id = ANY ($2::INTEGER[])
+	//  ORDER BY m.group_id, u.id
+	ListGroupMembers(ctx context.Context, arg ListGroupMembersParams) ([]ListGroupMembersRow, error)
+	//ListGroups
+	//
+	//  SELECT id, display_name, external_id, created_at, updated_at
+	//  FROM user_groups
+	//  WHERE tenant_id = $1
+	//    AND ($2::TEXT IS NULL OR lower(display_name) LIKE lower($2))
+	//    AND ($3::TEXT IS NULL OR external_id LIKE $3)
+	//  ORDER BY id
+	//  LIMIT $5 OFFSET $4
+	ListGroups(ctx context.Context, arg ListGroupsParams) ([]ListGroupsRow, error)
+	//ListIdentityEmails
+	//
+	//  SELECT id, tenant_id, email
+	//  FROM user_identities
+	//  WHERE id > $1
+	//  ORDER BY id
+	//  LIMIT $2
+	ListIdentityEmails(ctx context.Context, arg ListIdentityEmailsParams) ([]ListIdentityEmailsRow, error)
+	//ListInvites
+	//
+	//  SELECT i.id,
+	//         i.inviter_id,
+	//         i.max_uses,
+	//         i.used_count,
+	//         i.expires_at,
+	//         i.created_at,
+	//         COALESCE(array_agg(r.user_id ORDER BY r.created_at) FILTER (WHERE r.user_id IS NOT NULL), '{}')::INTEGER[] AS invitee_ids
+	//  FROM invites i
+	//           LEFT JOIN invite_redemptions r ON r.invite_id = i.id
+	//  WHERE i.tenant_id = $1
+	//  GROUP BY i.id
+	//  ORDER BY i.id DESC
+	ListInvites(ctx context.Context, tenantID int32) ([]ListInvitesRow, error)
+	//ListLoginEvents
+	//
+	//  SELECT fingerprint, ip_prefix, risk_score, reasons, step_up, created_at
+	//  FROM login_events
+	//  WHERE tenant_id = $1
+	//    AND user_id = $2
+	//    AND created_at > $3
+	//  ORDER BY created_at DESC
+	//  LIMIT 500
+	ListLoginEvents(ctx context.Context, arg ListLoginEventsParams) ([]ListLoginEventsRow, error)
+	//ListPendingOutboxEvents
+	//
+	//  SELECT id, tenant_id, aggregate_type, aggregate_id, event_type, payload, created_at
+	//  FROM outbox
+	//  WHERE published_at IS NULL
+	//  ORDER BY id
+	//  LIMIT $1
+	ListPendingOutboxEvents(ctx context.Context, batchSize int32) ([]ListPendingOutboxEventsRow, error)
+	// userName、externalId 的过滤条件为 LIKE 模式，userName 不区分大小写；邮箱已加密，只能按盲索引精确匹配；为空时不过滤
+	//
+	//  SELECT id, external_id, username, display_name, given_name, family_name, email, active, created_at, updated_at
+	//  FROM users
+	//  WHERE tenant_id = $1
+	//    AND ($2::TEXT IS NULL OR lower(username) LIKE lower($2))
+	//    AND ($3::TEXT IS NULL OR external_id LIKE $3)
+	//    AND ($4::BYTEA IS NULL OR email_index = $4 OR
+	//         (email_index IS NULL AND email = $5::BYTEA))
+	//    AND (NOT $6::BOOLEAN OR email IS NOT NULL)
+	//  ORDER BY id
+	//  LIMIT $8 OFFSET $7
+	ListScimUsers(ctx context.Context, arg ListScimUsersParams) ([]ListScimUsersRow, error)
+	//ListUserDevices
+	//
+	//  SELECT id, fingerprint, device_id, user_agent, ip_prefix, location_hint, first_seen_at, last_seen_at
+	//  FROM user_devices
+	//  WHERE tenant_id = $1
+	//    AND user_id = $2
+	//  ORDER BY last_seen_at DESC
+	ListUserDevices(ctx context.Context, arg ListUserDevicesParams) ([]ListUserDevicesRow, error)
+	// 按 ID 分批读取加密字段，由重新加密任务检查是否需要重新加密
+	//
+	//  SELECT id, tenant_id, email, email_index, display_name, given_name, family_name
+	//  FROM users
+	//  WHERE id > $1
+	//  ORDER BY id
+	//  LIMIT $2
+	ListUserFields(ctx context.Context, arg ListUserFieldsParams) ([]ListUserFieldsRow, error)
+	// 迁移前的明文邮箱还没有盲索引，启动时补齐后才对外服务
+	//
+	//  SELECT id, tenant_id, email, email_index, display_name, given_name, family_name
+	//  FROM users
+	//  WHERE id > $1
+	//    AND email IS NOT NULL
+	//    AND email_index IS NULL
+	//  ORDER BY id
+	//  LIMIT $2
+	ListUsersWithoutEmailIndex(ctx context.Context, arg ListUsersWithoutEmailIndexParams) ([]ListUsersWithoutEmailIndexRow, error)
+	//ListWebhookDeliveries
+	//
+	//  SELECT id,
+	//         subscription_id,
+	//         event_id,
+	//         event_type,
+	//         status,
+	//         attempts,
+	//         next_attempt_at,
+	//         last_attempt_at,
+	//         response_status,
+	//         last_error,
+	//         delivered_at,
+	//         created_at
+	//  FROM webhook_deliveries
+	//  WHERE tenant_id = $1
+	//    AND subscription_id = $2
+	//  ORDER BY id DESC
+	//  LIMIT $3
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]ListWebhookDeliveriesRow, error)
+	//ListWebhookSubscriptions
+	//
+	//  SELECT id, url, events, created_at
+	//  FROM webhook_subscriptions
+	//  WHERE tenant_id = $1
+	//  ORDER BY id
+	ListWebhookSubscriptions(ctx context.Context, tenantID int32) ([]ListWebhookSubscriptionsRow, error)
+	// 创建、轮换和重新加密数据密钥前加锁，多副本同时启动时只有一个创建密钥
+	//
+	//  SELECT pg_advisory_xact_lock(hashtextextended('data_keys', 0))
+	LockDataKeys(ctx context.Context) error
+	// 只有邮箱仍是验证时的地址才标记
+	//
+	//  UPDATE users
+	//  SET email_verified = true,
+	//      updated_at     = now()
+	//  WHERE tenant_id = $1
+	//    AND id = $2
+	//    AND (email_index = $3 OR (email_index IS NULL AND email = $4))
+	MarkEmailVerified(ctx context.Context, arg MarkEmailVerifiedParams) error
+	//MarkOutboxEventsPublished
+	//
+	//  UPDATE outbox
+	//  SET published_at = now()
+	//  WHERE id = ANY ($1::bigint[])
+	MarkOutboxEventsPublished(ctx context.Context, ids []int64) error
+	//PurgePublishedOutboxEvents
+	//
+	//  DELETE
+	//  FROM outbox
+	//  WHERE published_at < $1::timestamptz
+	PurgePublishedOutboxEvents(ctx context.Context, before time.Time) (int64, error)
+	//PurgeStateEntries
+	//
+	//  DELETE
+	//  FROM state_entries
+	//  WHERE expires_at <= now()
+	PurgeStateEntries(ctx context.Context) error
+	//RecordWebhookAttempt
+	//
+	//  UPDATE webhook_deliveries
+	//  SET status          = $1,
+	//      attempts        = attempts + 1,
+	//      next_attempt_at = $2,
+	//      last_attempt_at = now(),
+	//      response_status = $3,
+	//      last_error      = $4,
+	//      delivered_at    = CASE WHEN $1 = 'succeeded' THEN now() END
+	//  WHERE id = $5
+	RecordWebhookAttempt(ctx context.Context, arg RecordWebhookAttemptParams) error
+	// 条件更新保证并发注册时不会超出可用次数
+	//
+	//  UPDATE invites
+	//  SET used_count = used_count + 1
+	//  WHERE id = $1
+	//    AND tenant_id = $2
+	//    AND used_count < max_uses
+	//    AND expires_at > now()
+	//  RETURNING id, inviter_id, max_uses, used_count, expires_at, created_at
+	RedeemInvite(ctx context.Context, arg RedeemInviteParams) (RedeemInviteRow, error)
+	// 重新投递从第一次尝试开始计算退避，已成功的投递也可以重新投递
+	//
+	//  UPDATE webhook_deliveries
+	//  SET status          = 'pending',
+	//      attempts        = 0,
+	//      next_attempt_at = now(),
+	//      delivered_at    = NULL
+	//  WHERE id = $1
+	//    AND tenant_id = $2
+	//  RETURNING id,
+	//      subscription_id,
+	//      event_id,
+	//      event_type,
+	//      status,
+	//      attempts,
+	//      next_attempt_at,
+	//      last_attempt_at,
+	//      response_status,
+	//      last_error,
+	//      delivered_at,
+	//      created_at
+	RedeliverWebhookDelivery(ctx context.Context, arg RedeliverWebhookDeliveryParams) (RedeliverWebhookDeliveryRow, error)
+	//ReencryptIdentityEmail
+	//
+	//  UPDATE user_identities
+	//  SET email = $1
+	//  WHERE id = $2
+	//    AND email IS NOT DISTINCT FROM $3::BYTEA
+	ReencryptIdentityEmail(ctx context.Context, arg ReencryptIdentityEmailParams) (int64, error)
+	// 字段在读取后被修改时不更新，由下一轮处理
+	//
+	//  UPDATE users
+	//  SET email        = $1,
+	//      email_index  = $2,
+	//      display_name = $3,
+	//      given_name   = $4,
+	//      family_name  = $5
+	//  WHERE id = $6
+	//    AND email IS NOT DISTINCT FROM $7::BYTEA
+	//    AND display_name IS NOT DISTINCT FROM $8::BYTEA
+	//    AND given_name IS NOT DISTINCT FROM $9::BYTEA
+	//    AND family_name IS NOT DISTINCT FROM $10::BYTEA
+	ReencryptUserFields(ctx context.Context, arg ReencryptUserFieldsParams) (int64, error)
+	//RemoveGroupMembers
+	//
+	//  DELETE
+	//  FROM group_members
+	//  WHERE group_id = $1
+	//    AND user_id = ANY ($2::INTEGER[])
+	RemoveGroupMembers(ctx context.Context, arg RemoveGroupMembersParams) error
+	//RewrapDataKey
+	//
+	//  UPDATE data_keys
+	//  SET kek_id      = $1,
+	//      wrapped_key = $2
+	//  WHERE id = $3
+	RewrapDataKey(ctx context.Context, arg RewrapDataKeyParams) error
+	// credential_backend 为 NULL 时使用租户默认后端
+	//
+	//  UPDATE users
+	//  SET credential_backend = $1,
+	//      updated_at         = now()
+	//  WHERE tenant_id = $2
+	//    AND username = $3
+	SetCredentialBackend(ctx context.Context, arg SetCredentialBackendParams) (int64, error)
+	//SetStateEntry
+	//
+	//  INSERT INTO state_entries (key, value, expires_at)
+	//  VALUES ($1, $2, $3)
+	//  ON CONFLICT (key) DO UPDATE
+	//      SET value      = EXCLUDED.value,
+	//          expires_at = EXCLUDED.expires_at
+	SetStateEntry(ctx context.Context, arg SetStateEntryParams) error
+	// 键不存在或已过期时写入，影响行数为0说明键仍然有效
+	//
+	//  INSERT INTO state_entries (key, value, expires_at)
+	//  VALUES ($1, $2, $3)
+	//  ON CONFLICT (key) DO UPDATE
+	//      SET value      = EXCLUDED.value,
+	//          expires_at = EXCLUDED.expires_at
+	//  WHERE state_entries.expires_at <= now()
+	SetStateEntryNX(ctx context.Context, arg SetStateEntryNXParams) (int64, error)
+	// 目录服务登录成功后同步属性，首次登录时创建没有本地凭证的用户；已有用户不修改 credential_backend。
+	// 已有用户属于其他后端（包括有本地凭证、未指定后端的用户）时不更新，返回空结果。
+	// 目录服务返回的邮箱视为已验证
+	//
+	//  INSERT INTO users (tenant_id, username, password_hash, salt, email, email_index, display_name, given_name, family_name, credential_backend, email_verified)
+	//  VALUES ($1, $2, '', '', $3, $4, $5, $6, $7, $8, $3 IS NOT NULL)
+	//  ON CONFLICT (tenant_id, username) DO UPDATE
+	//      SET email          = COALESCE(EXCLUDED.email, users.email),
+	//          email_index    = CASE WHEN EXCLUDED.email IS NULL THEN users.email_index ELSE EXCLUDED.email_index END,
+	//          email_verified = EXCLUDED.email_verified OR users.email_verified,
+	//          display_name   = EXCLUDED.display_name,
+	//          given_name     = EXCLUDED.given_name,
+	//          family_name    = EXCLUDED.family_name,
+	//          updated_at     = now()
+	//  WHERE users.credential_backend = EXCLUDED.credential_backend
+	//     OR (users.credential_backend IS NULL AND users.password_hash = '')
+	//  RETURNING id, active
+	SyncDirectoryUser(ctx context.Context, arg SyncDirectoryUserParams) (SyncDirectoryUserRow, error)
+	//TakeStateEntry
+	//
+	//  DELETE
+	//  FROM state_entries
+	//  WHERE key = $1
+	//    AND expires_at > now()
+	//  RETURNING value
+	TakeStateEntry(ctx context.Context, key string) (string, error)
+	//TouchUserIdentity
+	//
+	//  UPDATE user_identities
+	//  SET email         = $1,
+	//      last_login_at = now()
+	//  WHERE tenant_id = $2
+	//    AND provider = $3
+	//    AND subject = $4
+	TouchUserIdentity(ctx context.Context, arg TouchUserIdentityParams) error
+	// 多副本时只有持有锁的副本投递，锁在事务结束时释放
+	//
+	//  SELECT pg_try_advisory_xact_lock(hashtextextended('outbox_relay', 0))
+	TryLockOutboxRelay(ctx context.Context) (bool, error)
+	//UpdateCredential
+	//
+	//  UPDATE users
+	//  SET password_hash = $1,
+	//      salt          = $2,
+	//      kdf_version   = $3,
+	//      updated_at    = now()
+	//  WHERE tenant_id = $4
+	//    AND id = $5
+	UpdateCredential(ctx context.Context, arg UpdateCredentialParams) error
+	//UpdateGroup
+	//
+	//  UPDATE user_groups
+	//  SET display_name = $1,
+	//      external_id  = $2,
+	//      updated_at   = now()
+	//  WHERE tenant_id = $3
+	//    AND id = $4
+	//  RETURNING id, display_name, external_id, created_at, updated_at
+	UpdateGroup(ctx context.Context, arg UpdateGroupParams) (UpdateGroupRow, error)
+	//UpdateScimUser
+	//
+	//  UPDATE users
+	//  SET username       = $1,
+	//      email          = $2,
+	//      email_index    = $3,
+	//      email_verified = $2 IS NOT NULL,
+	//      external_id    = $4,
+	//      display_name   = $5,
+	//      given_name     = $6,
+	//      family_name    = $7,
+	//      active         = $8,
+	//      updated_at     = now()
+	//  WHERE tenant_id = $9
+	//    AND id = $10
+	//  RETURNING id, external_id, username, display_name, given_name, family_name, email, active, created_at, updated_at
+	UpdateScimUser(ctx context.Context, arg UpdateScimUserParams) (UpdateScimUserRow, error)
+	//UpsertUserDevice
+	//
+	//  INSERT INTO user_devices (tenant_id, user_id, fingerprint, device_id, user_agent, ip_prefix, location_hint)
+	//  VALUES ($1, $2, $3, $4, $5, $6, $7)
+	//  ON CONFLICT (user_id, fingerprint) DO UPDATE
+	//      SET user_agent    = EXCLUDED.user_agent,
+	//          ip_prefix     = EXCLUDED.ip_prefix,
+	//          location_hint = EXCLUDED.location_hint,
+	//          last_seen_at  = now()
+	//  RETURNING id, first_seen_at, last_seen_at
+	UpsertUserDevice(ctx context.Context, arg UpsertUserDeviceParams) (UpsertUserDeviceRow, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -7,90 +7,2806 @@ package models
 
 import (
 	"context"
+	"time"
+
+	"connect-go-example/internal/pkg/fieldcrypt"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const CreateUser = `-- name: CreateUser :one
-INSERT INTO users (username, password_hash, salt)
+const AddGroupMembers = `-- name: AddGroupMembers :exec
+INSERT INTO group_members (group_id, user_id)
+SELECT $1, u.id
+FROM users u
+WHERE u.tenant_id = $2
+  AND u.id = ANY ($3::INTEGER[])
+ON CONFLICT DO NOTHING
+`
+
+type AddGroupMembersParams struct {
+	GroupID  int32
+	TenantID int32
+	UserIds  []int32
+}
+
+// 只添加同一租户内存在的用户
+//
+//	INSERT INTO group_members (group_id, user_id)
+//	SELECT $1, u.id
+//	FROM users u
+//	WHERE u.tenant_id = $2
+//	  AND u.id = ANY ($3::INTEGER[])
+//	ON CONFLICT DO NOTHING
+func (q *Queries) AddGroupMembers(ctx context.Context, arg AddGroupMembersParams) error {
+	_, err := q.db.Exec(ctx, AddGroupMembers, arg.GroupID, arg.TenantID, arg.UserIds)
+	return err
+}
+
+const AppendOutboxEvent = `-- name: AppendOutboxEvent :one
+WITH aggregate_lock AS (SELECT pg_advisory_xact_lock(hashtextextended($2::text || ':' || $3::text, 0)))
+INSERT
+INTO outbox (tenant_id, aggregate_type, aggregate_id, event_type, payload)
+SELECT $1, $2, $3, $4, $5
+FROM aggregate_lock
+RETURNING id
+`
+
+type AppendOutboxEventParams struct {
+	TenantID      int32
+	AggregateType string
+	AggregateID   string
+	EventType     string
+	Payload       []byte
+}
+
+// 先按聚合加事务级咨询锁再分配 id，同一聚合的事件按提交顺序得到递增的 id
+//
+//	WITH aggregate_lock AS (SELECT pg_advisory_xact_lock(hashtextextended($2::text || ':' || $3::text, 0)))
+//	INSERT
+//	INTO outbox (tenant_id, aggregate_type, aggregate_id, event_type, payload)
+//	SELECT $1, $2, $3, $4, $5
+//	FROM aggregate_lock
+//	RETURNING id
+func (q *Queries) AppendOutboxEvent(ctx context.Context, arg AppendOutboxEventParams) (int64, error) {
+	row := q.db.QueryRow(ctx, AppendOutboxEvent,
+		arg.TenantID,
+		arg.AggregateType,
+		arg.AggregateID,
+		arg.EventType,
+		arg.Payload,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const ClaimWebhookDeliveries = `-- name: ClaimWebhookDeliveries :many
+WITH due AS (SELECT d.id
+             FROM webhook_deliveries d
+             WHERE d.status = 'pending'
+               AND d.next_attempt_at <= now()
+             ORDER BY d.next_attempt_at
+             LIMIT $2 FOR UPDATE SKIP LOCKED)
+UPDATE webhook_deliveries d
+SET next_attempt_at = now() + make_interval(secs => $1::float8)
+FROM due,
+     webhook_subscriptions s
+WHERE d.id = due.id
+  AND s.id = d.subscription_id
+RETURNING d.id, d.tenant_id, d.subscription_id, d.event_id, d.event_type, d.payload, d.attempts, d.created_at, s.url, s.secret
+`
+
+type ClaimWebhookDeliveriesParams struct {
+	LeaseSeconds float64
+	BatchSize    int32
+}
+
+type ClaimWebhookDeliveriesRow struct {
+	ID             int64
+	TenantID       int32
+	SubscriptionID int32
+	EventID        int64
+	EventType      string
+	Payload        []byte
+	Attempts       int32
+	CreatedAt      time.Time
+	Url            string
+	Secret         string
+}
+
+// 取出到期的投递并把 next_attempt_at 推迟到租约结束，投递中的副本崩溃后由其他副本在租约到期后重试
+//
+//	WITH due AS (SELECT d.id
+//	             FROM webhook_deliveries d
+//	             WHERE d.status = 'pending'
+//	               AND d.next_attempt_at <= now()
+//	             ORDER BY d.next_attempt_at
+//	             LIMIT $2 FOR UPDATE SKIP LOCKED)
+//	UPDATE webhook_deliveries d
+//	SET next_attempt_at = now() + make_interval(secs => $1::float8)
+//	FROM due,
+//	     webhook_subscriptions s
+//	WHERE d.id = due.id
+//	  AND s.id = d.subscription_id
+//	RETURNING d.id, d.tenant_id, d.subscription_id, d.event_id, d.event_type, d.payload, d.attempts, d.created_at, s.url, s.secret
+func (q *Queries) ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]ClaimWebhookDeliveriesRow, error) {
+	rows, err := q.db.Query(ctx, ClaimWebhookDeliveries, arg.LeaseSeconds, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClaimWebhookDeliveriesRow
+	for rows.Next() {
+		var i ClaimWebhookDeliveriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.SubscriptionID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Attempts,
+			&i.CreatedAt,
+			&i.Url,
+			&i.Secret,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ClearGroupMembers = `-- name: ClearGroupMembers :exec
+DELETE
+FROM group_members
+WHERE group_id = $1
+`
+
+// ClearGroupMembers
+//
+//	DELETE
+//	FROM group_members
+//	WHERE group_id = $1
+func (q *Queries) ClearGroupMembers(ctx context.Context, groupID int32) error {
+	_, err := q.db.Exec(ctx, ClearGroupMembers, groupID)
+	return err
+}
+
+const CountGroups = `-- name: CountGroups :one
+SELECT count(*)
+FROM user_groups
+WHERE tenant_id = $1
+  AND ($2::TEXT IS NULL OR lower(display_name) LIKE lower($2))
+  AND ($3::TEXT IS NULL OR external_id LIKE $3)
+`
+
+type CountGroupsParams struct {
+	TenantID    int32
+	DisplayName *string
+	ExternalID  *string
+}
+
+// CountGroups
+//
+//	SELECT count(*)
+//	FROM user_groups
+//	WHERE tenant_id = $1
+//	  AND ($2::TEXT IS NULL OR lower(display_name) LIKE lower($2))
+//	  AND ($3::TEXT IS NULL OR external_id LIKE $3)
+func (q *Queries) CountGroups(ctx context.Context, arg CountGroupsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, CountGroups, arg.TenantID, arg.DisplayName, arg.ExternalID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CountScimUsers = `-- name: CountScimUsers :one
+SELECT count(*)
+FROM users
+WHERE tenant_id = $1
+  AND ($2::TEXT IS NULL OR lower(username) LIKE lower($2))
+  AND ($3::TEXT IS NULL OR external_id LIKE $3)
+  AND ($4::BYTEA IS NULL OR email_index = $4 OR
+       (email_index IS NULL AND email = $5::BYTEA))
+  AND (NOT $6::BOOLEAN OR email IS NOT NULL)
+`
+
+type CountScimUsersParams struct {
+	TenantID    int32
+	UserName    *string
+	ExternalID  *string
+	EmailIndex  []byte
+	LegacyEmail []byte
+	HasEmail    bool
+}
+
+// CountScimUsers
+//
+//	SELECT count(*)
+//	FROM users
+//	WHERE tenant_id = $1
+//	  AND ($2::TEXT IS NULL OR lower(username) LIKE lower($2))
+//	  AND ($3::TEXT IS NULL OR external_id LIKE $3)
+//	  AND ($4::BYTEA IS NULL OR email_index = $4 OR
+//	       (email_index IS NULL AND email = $5::BYTEA))
+//	  AND (NOT $6::BOOLEAN OR email IS NOT NULL)
+func (q *Queries) CountScimUsers(ctx context.Context, arg CountScimUsersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, CountScimUsers,
+		arg.TenantID,
+		arg.UserName,
+		arg.ExternalID,
+		arg.EmailIndex,
+		arg.LegacyEmail,
+		arg.HasEmail,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CreateDataKey = `-- name: CreateDataKey :one
+INSERT INTO data_keys (purpose, kek_id, wrapped_key)
+VALUES ($1, $2, $3)
+RETURNING id, created_at
+`
+
+type CreateDataKeyParams struct {
+	Purpose    string
+	KekID      string
+	WrappedKey []byte
+}
+
+type CreateDataKeyRow struct {
+	ID        int32
+	CreatedAt time.Time
+}
+
+// CreateDataKey
+//
+//	INSERT INTO data_keys (purpose, kek_id, wrapped_key)
+//	VALUES ($1, $2, $3)
+//	RETURNING id, created_at
+func (q *Queries) CreateDataKey(ctx context.Context, arg CreateDataKeyParams) (CreateDataKeyRow, error) {
+	row := q.db.QueryRow(ctx, CreateDataKey, arg.Purpose, arg.KekID, arg.WrappedKey)
+	var i CreateDataKeyRow
+	err := row.Scan(&i.ID, &i.CreatedAt)
+	return i, err
+}
+
+const CreateFederatedUser = `-- name: CreateFederatedUser :one
+WITH new_user AS (
+    INSERT INTO users (tenant_id, username, password_hash, salt, email, email_index, display_name, email_verified)
+        VALUES ($4, $5, '', '', $6, $7, $8, $6 IS NOT NULL)
+        RETURNING id, tenant_id)
+INSERT
+INTO user_identities (tenant_id, user_id, provider, subject, email)
+SELECT tenant_id, id, $1, $2, $3::BYTEA
+FROM new_user
+RETURNING user_id
+`
+
+type CreateFederatedUserParams struct {
+	Provider      string
+	Subject       string
+	IdentityEmail []byte
+	TenantID      int32
+	Username      string
+	Email         fieldcrypt.Ciphertext
+	EmailIndex    fieldcrypt.BlindIndex
+	DisplayName   fieldcrypt.Ciphertext
+}
+
+// 同一语句中创建用户和身份关联，任一冲突时都不会留下没有关联的用户；两个表的邮箱密文不同
+//
+//	WITH new_user AS (
+//	    INSERT INTO users (tenant_id, username, password_hash, salt, email, email_index, display_name, email_verified)
+//	        VALUES ($4, $5, '', '', $6, $7, $8, $6 IS NOT NULL)
+//	        RETURNING id, tenant_id)
+//	INSERT
+//	INTO user_identities (tenant_id, user_id, provider, subject, email)
+//	SELECT tenant_id, id, $1, $2, $3::BYTEA
+//	FROM new_user
+//	RETURNING user_id
+func (q *Queries) CreateFederatedUser(ctx context.Context, arg CreateFederatedUserParams) (int32, error) {
+	row := q.db.QueryRow(ctx, CreateFederatedUser,
+		arg.Provider,
+		arg.Subject,
+		arg.IdentityEmail,
+		arg.TenantID,
+		arg.Username,
+		arg.Email,
+		arg.EmailIndex,
+		arg.DisplayName,
+	)
+	var user_id int32
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
+const CreateGroup = `-- name: CreateGroup :one
+INSERT INTO user_groups (tenant_id, display_name, external_id)
 VALUES ($1, $2, $3)
-RETURNING id, username, password_hash, salt, created_at, updated_at
+RETURNING id, display_name, external_id, created_at, updated_at
+`
+
+type CreateGroupParams struct {
+	TenantID    int32
+	DisplayName string
+	ExternalID  *string
+}
+
+type CreateGroupRow struct {
+	ID          int32
+	DisplayName string
+	ExternalID  *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// CreateGroup
+//
+//	INSERT INTO user_groups (tenant_id, display_name, external_id)
+//	VALUES ($1, $2, $3)
+//	RETURNING id, display_name, external_id, created_at, updated_at
+func (q *Queries) CreateGroup(ctx context.Context, arg CreateGroupParams) (CreateGroupRow, error) {
+	row := q.db.QueryRow(ctx, CreateGroup, arg.TenantID, arg.DisplayName, arg.ExternalID)
+	var i CreateGroupRow
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayName,
+		&i.ExternalID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const CreateInvite = `-- name: CreateInvite :one
+INSERT INTO invites (tenant_id, inviter_id, max_uses, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id, created_at
+`
+
+type CreateInviteParams struct {
+	TenantID  int32
+	InviterID int32
+	MaxUses   int32
+	ExpiresAt time.Time
+}
+
+type CreateInviteRow struct {
+	ID        int32
+	CreatedAt time.Time
+}
+
+// CreateInvite
+//
+//	INSERT INTO invites (tenant_id, inviter_id, max_uses, expires_at)
+//	VALUES ($1, $2, $3, $4)
+//	RETURNING id, created_at
+func (q *Queries) CreateInvite(ctx context.Context, arg CreateInviteParams) (CreateInviteRow, error) {
+	row := q.db.QueryRow(ctx, CreateInvite,
+		arg.TenantID,
+		arg.InviterID,
+		arg.MaxUses,
+		arg.ExpiresAt,
+	)
+	var i CreateInviteRow
+	err := row.Scan(&i.ID, &i.CreatedAt)
+	return i, err
+}
+
+const CreateInviteRedemption = `-- name: CreateInviteRedemption :exec
+INSERT INTO invite_redemptions (invite_id, user_id)
+VALUES ($1, $2)
+`
+
+type CreateInviteRedemptionParams struct {
+	InviteID int32
+	UserID   int32
+}
+
+// CreateInviteRedemption
+//
+//	INSERT INTO invite_redemptions (invite_id, user_id)
+//	VALUES ($1, $2)
+func (q *Queries) CreateInviteRedemption(ctx context.Context, arg CreateInviteRedemptionParams) error {
+	_, err := q.db.Exec(ctx, CreateInviteRedemption, arg.InviteID, arg.UserID)
+	return err
+}
+
+const CreateLoginEvent = `-- name: CreateLoginEvent :exec
+INSERT INTO login_events (tenant_id, user_id, fingerprint, ip_prefix, risk_score, reasons, step_up)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+type CreateLoginEventParams struct {
+	TenantID    int32
+	UserID      int32
+	Fingerprint string
+	IpPrefix    string
+	RiskScore   int32
+	Reasons     []string
+	StepUp      bool
+}
+
+// CreateLoginEvent
+//
+//	INSERT INTO login_events (tenant_id, user_id, fingerprint, ip_prefix, risk_score, reasons, step_up)
+//	VALUES ($1, $2, $3, $4, $5, $6, $7)
+func (q *Queries) CreateLoginEvent(ctx context.Context, arg CreateLoginEventParams) error {
+	_, err := q.db.Exec(ctx, CreateLoginEvent,
+		arg.TenantID,
+		arg.UserID,
+		arg.Fingerprint,
+		arg.IpPrefix,
+		arg.RiskScore,
+		arg.Reasons,
+		arg.StepUp,
+	)
+	return err
+}
+
+const CreateScimUser = `-- name: CreateScimUser :one
+INSERT INTO users (tenant_id, username, password_hash, salt, email, email_index, external_id, display_name, given_name, family_name, active, email_verified)
+VALUES ($1, $2, '', '', $3, $4, $5, $6, $7, $8, $9, $3 IS NOT NULL)
+RETURNING id, external_id, username, display_name, given_name, family_name, email, active, created_at, updated_at
+`
+
+type CreateScimUserParams struct {
+	TenantID    int32
+	Username    string
+	Email       fieldcrypt.Ciphertext
+	EmailIndex  fieldcrypt.BlindIndex
+	ExternalID  *string
+	DisplayName fieldcrypt.Ciphertext
+	GivenName   fieldcrypt.Ciphertext
+	FamilyName  fieldcrypt.Ciphertext
+	Active      bool
+}
+
+type CreateScimUserRow struct {
+	ID          int32
+	ExternalID  *string
+	Username    string
+	DisplayName fieldcrypt.Ciphertext
+	GivenName   fieldcrypt.Ciphertext
+	FamilyName  fieldcrypt.Ciphertext
+	Email       fieldcrypt.Ciphertext
+	Active      bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// SCIM 下发的用户没有凭证，password_hash 为空时无法通过凭证登录；IdP 下发的邮箱视为已验证
+//
+//	INSERT INTO users (tenant_id, username, password_hash, salt, email, email_index, external_id, display_name, given_name, family_name, active, email_verified)
+//	VALUES ($1, $2, '', '', $3, $4, $5, $6, $7, $8, $9, $3 IS NOT NULL)
+//	RETURNING id, external_id, username, display_name, given_name, family_name, email, active, created_at, updated_at
+func (q *Queries) CreateScimUser(ctx context.Context, arg CreateScimUserParams) (CreateScimUserRow, error) {
+	row := q.db.QueryRow(ctx, CreateScimUser,
+		arg.TenantID,
+		arg.Username,
+		arg.Email,
+		arg.EmailIndex,
+		arg.ExternalID,
+		arg.DisplayName,
+		arg.GivenName,
+		arg.FamilyName,
+		arg.Active,
+	)
+	var i CreateScimUserRow
+	err := row.Scan(
+		&i.ID,
+		&i.ExternalID,
+		&i.Username,
+		&i.DisplayName,
+		&i.GivenName,
+		&i.FamilyName,
+		&i.Email,
+		&i.Active,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const CreateUser = `-- name: CreateUser :one
+INSERT INTO users (tenant_id, username, password_hash, salt, email, email_index, kdf_version, email_verified)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, tenant_id, username, password_hash, salt, email, kdf_version, created_at, updated_at
 `
 
 type CreateUserParams struct {
+	TenantID      int32
+	Username      string
+	PasswordHash  string
+	Salt          string
+	Email         fieldcrypt.Ciphertext
+	EmailIndex    fieldcrypt.BlindIndex
+	KdfVersion    int32
+	EmailVerified bool
+}
+
+type CreateUserRow struct {
+	ID           int32
+	TenantID     int32
 	Username     string
 	PasswordHash string
 	Salt         string
+	Email        fieldcrypt.Ciphertext
+	KdfVersion   int32
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
 }
 
 // CreateUser
 //
-//	INSERT INTO users (username, password_hash, salt)
-//	VALUES ($1, $2, $3)
-//	RETURNING id, username, password_hash, salt, created_at, updated_at
-func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRow(ctx, CreateUser, arg.Username, arg.PasswordHash, arg.Salt)
-	var i User
+//	INSERT INTO users (tenant_id, username, password_hash, salt, email, email_index, kdf_version, email_verified)
+//	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+//	RETURNING id, tenant_id, username, password_hash, salt, email, kdf_version, created_at, updated_at
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error) {
+	row := q.db.QueryRow(ctx, CreateUser,
+		arg.TenantID,
+		arg.Username,
+		arg.PasswordHash,
+		arg.Salt,
+		arg.Email,
+		arg.EmailIndex,
+		arg.KdfVersion,
+		arg.EmailVerified,
+	)
+	var i CreateUserRow
 	err := row.Scan(
 		&i.ID,
+		&i.TenantID,
 		&i.Username,
 		&i.PasswordHash,
 		&i.Salt,
+		&i.Email,
+		&i.KdfVersion,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const GetUserByName = `-- name: GetUserByName :one
-SELECT username, salt, id, password_hash
+const CreateUserIdentity = `-- name: CreateUserIdentity :exec
+INSERT INTO user_identities (tenant_id, user_id, provider, subject, email)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+type CreateUserIdentityParams struct {
+	TenantID int32
+	UserID   int32
+	Provider string
+	Subject  string
+	Email    fieldcrypt.Ciphertext
+}
+
+// CreateUserIdentity
+//
+//	INSERT INTO user_identities (tenant_id, user_id, provider, subject, email)
+//	VALUES ($1, $2, $3, $4, $5)
+func (q *Queries) CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) error {
+	_, err := q.db.Exec(ctx, CreateUserIdentity,
+		arg.TenantID,
+		arg.UserID,
+		arg.Provider,
+		arg.Subject,
+		arg.Email,
+	)
+	return err
+}
+
+const CreateWebhookSubscription = `-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (tenant_id, url, events, secret)
+VALUES ($1, $2, $3, $4)
+RETURNING id, created_at
+`
+
+type CreateWebhookSubscriptionParams struct {
+	TenantID int32
+	Url      string
+	Events   []string
+	Secret   string
+}
+
+type CreateWebhookSubscriptionRow struct {
+	ID        int32
+	CreatedAt time.Time
+}
+
+// CreateWebhookSubscription
+//
+//	INSERT INTO webhook_subscriptions (tenant_id, url, events, secret)
+//	VALUES ($1, $2, $3, $4)
+//	RETURNING id, created_at
+func (q *Queries) CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (CreateWebhookSubscriptionRow, error) {
+	row := q.db.QueryRow(ctx, CreateWebhookSubscription,
+		arg.TenantID,
+		arg.Url,
+		arg.Events,
+		arg.Secret,
+	)
+	var i CreateWebhookSubscriptionRow
+	err := row.Scan(&i.ID, &i.CreatedAt)
+	return i, err
+}
+
+const DeleteGroup = `-- name: DeleteGroup :execrows
+DELETE
+FROM user_groups
+WHERE tenant_id = $1
+  AND id = $2
+`
+
+type DeleteGroupParams struct {
+	TenantID int32
+	ID       int32
+}
+
+// DeleteGroup
+//
+//	DELETE
+//	FROM user_groups
+//	WHERE tenant_id = $1
+//	  AND id = $2
+func (q *Queries) DeleteGroup(ctx context.Context, arg DeleteGroupParams) (int64, error) {
+	result, err := q.db.Exec(ctx, DeleteGroup, arg.TenantID, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const DeleteStateEntry = `-- name: DeleteStateEntry :exec
+DELETE
+FROM state_entries
+WHERE key = $1
+`
+
+// DeleteStateEntry
+//
+//	DELETE
+//	FROM state_entries
+//	WHERE key = $1
+func (q *Queries) DeleteStateEntry(ctx context.Context, key string) error {
+	_, err := q.db.Exec(ctx, DeleteStateEntry, key)
+	return err
+}
+
+const DeleteUser = `-- name: DeleteUser :execrows
+DELETE
 FROM users
-WHERE username = $1
+WHERE tenant_id = $1
+  AND id = $2
 `
 
-type GetUserByNameRow struct {
-	Username     string
-	Salt         string
-	ID           int32
-	PasswordHash string
+type DeleteUserParams struct {
+	TenantID int32
+	ID       int32
 }
 
-// GetUserByName
+// DeleteUser
 //
-//	SELECT username, salt, id, password_hash
+//	DELETE
 //	FROM users
-//	WHERE username = $1
-func (q *Queries) GetUserByName(ctx context.Context, username string) (GetUserByNameRow, error) {
-	row := q.db.QueryRow(ctx, GetUserByName, username)
-	var i GetUserByNameRow
+//	WHERE tenant_id = $1
+//	  AND id = $2
+func (q *Queries) DeleteUser(ctx context.Context, arg DeleteUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, DeleteUser, arg.TenantID, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const DeleteWebhookSubscription = `-- name: DeleteWebhookSubscription :execrows
+DELETE
+FROM webhook_subscriptions
+WHERE id = $1
+  AND tenant_id = $2
+`
+
+type DeleteWebhookSubscriptionParams struct {
+	ID       int32
+	TenantID int32
+}
+
+// DeleteWebhookSubscription
+//
+//	DELETE
+//	FROM webhook_subscriptions
+//	WHERE id = $1
+//	  AND tenant_id = $2
+func (q *Queries) DeleteWebhookSubscription(ctx context.Context, arg DeleteWebhookSubscriptionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, DeleteWebhookSubscription, arg.ID, arg.TenantID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const EnqueueWebhookDeliveries = `-- name: EnqueueWebhookDeliveries :execrows
+INSERT
+INTO webhook_deliveries (tenant_id, subscription_id, event_id, event_type, payload)
+SELECT s.tenant_id, s.id, $1, $2, $3
+FROM webhook_subscriptions s
+WHERE s.tenant_id = $4
+  AND $2::text = ANY (s.events)
+ON CONFLICT (subscription_id, event_id) DO NOTHING
+`
+
+type EnqueueWebhookDeliveriesParams struct {
+	EventID   int64
+	EventType string
+	Payload   []byte
+	TenantID  int32
+}
+
+// 为订阅了该事件的每个订阅创建一条投递记录，重复投递的事件不会重复创建
+//
+//	INSERT
+//	INTO webhook_deliveries (tenant_id, subscription_id, event_id, event_type, payload)
+//	SELECT s.tenant_id, s.id, $1, $2, $3
+//	FROM webhook_subscriptions s
+//	WHERE s.tenant_id = $4
+//	  AND $2::text = ANY (s.events)
+//	ON CONFLICT (subscription_id, event_id) DO NOTHING
+func (q *Queries) EnqueueWebhookDeliveries(ctx context.Context, arg EnqueueWebhookDeliveriesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, EnqueueWebhookDeliveries,
+		arg.EventID,
+		arg.EventType,
+		arg.Payload,
+		arg.TenantID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const GetGroup = `-- name: GetGroup :one
+SELECT id, display_name, external_id, created_at, updated_at
+FROM user_groups
+WHERE tenant_id = $1
+  AND id = $2
+`
+
+type GetGroupParams struct {
+	TenantID int32
+	ID       int32
+}
+
+type GetGroupRow struct {
+	ID          int32
+	DisplayName string
+	ExternalID  *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// GetGroup
+//
+//	SELECT id, display_name, external_id, created_at, updated_at
+//	FROM user_groups
+//	WHERE tenant_id = $1
+//	  AND id = $2
+func (q *Queries) GetGroup(ctx context.Context, arg GetGroupParams) (GetGroupRow, error) {
+	row := q.db.QueryRow(ctx, GetGroup, arg.TenantID, arg.ID)
+	var i GetGroupRow
 	err := row.Scan(
-		&i.Username,
-		&i.Salt,
 		&i.ID,
-		&i.PasswordHash,
+		&i.DisplayName,
+		&i.ExternalID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const InsertTestUser = `-- name: InsertTestUser :one
-INSERT INTO users(username, password_hash, salt)
-VALUES ('admin', 'asdas', '123123')
-RETURNING id, username, password_hash, salt, created_at, updated_at
+const GetScimUser = `-- name: GetScimUser :one
+SELECT id, external_id, username, display_name, given_name, family_name, email, active, created_at, updated_at
+FROM users
+WHERE tenant_id = $1
+  AND id = $2
 `
 
-// InsertTestUser
+type GetScimUserParams struct {
+	TenantID int32
+	ID       int32
+}
+
+type GetScimUserRow struct {
+	ID          int32
+	ExternalID  *string
+	Username    string
+	DisplayName fieldcrypt.Ciphertext
+	GivenName   fieldcrypt.Ciphertext
+	FamilyName  fieldcrypt.Ciphertext
+	Email       fieldcrypt.Ciphertext
+	Active      bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// GetScimUser
 //
-//	INSERT INTO users(username, password_hash, salt)
-//	VALUES ('admin', 'asdas', '123123')
-//	RETURNING id, username, password_hash, salt, created_at, updated_at
-func (q *Queries) InsertTestUser(ctx context.Context) (User, error) {
-	row := q.db.QueryRow(ctx, InsertTestUser)
-	var i User
+//	SELECT id, external_id, username, display_name, given_name, family_name, email, active, created_at, updated_at
+//	FROM users
+//	WHERE tenant_id = $1
+//	  AND id = $2
+func (q *Queries) GetScimUser(ctx context.Context, arg GetScimUserParams) (GetScimUserRow, error) {
+	row := q.db.QueryRow(ctx, GetScimUser, arg.TenantID, arg.ID)
+	var i GetScimUserRow
 	err := row.Scan(
 		&i.ID,
+		&i.ExternalID,
 		&i.Username,
-		&i.PasswordHash,
-		&i.Salt,
+		&i.DisplayName,
+		&i.GivenName,
+		&i.FamilyName,
+		&i.Email,
+		&i.Active,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
+
+const GetStateEntry = `-- name: GetStateEntry :one
+SELECT value
+FROM state_entries
+WHERE key = $1
+  AND expires_at > now()
+`
+
+// state_entries 是 UNLOGGED 表，只读副本上没有数据，调用方必须使用主库连接
+//
+//	SELECT value
+//	FROM state_entries
+//	WHERE key = $1
+//	  AND expires_at > now()
+func (q *Queries) GetStateEntry(ctx context.Context, key string) (string, error) {
+	row := q.db.QueryRow(ctx, GetStateEntry, key)
+	var value string
+	err := row.Scan(&value)
+	return value, err
+}
+
+const GetTenantByClientID = `-- name: GetTenantByClientID :one
+SELECT t.id, t.slug, t.name
+FROM tenants t
+         JOIN tenant_clients c ON c.tenant_id = t.id
+WHERE c.client_id = $1
+`
+
+type GetTenantByClientIDRow struct {
+	ID   int32
+	Slug string
+	Name string
+}
+
+// GetTenantByClientID
+//
+//	SELECT t.id, t.slug, t.name
+//	FROM tenants t
+//	         JOIN tenant_clients c ON c.tenant_id = t.id
+//	WHERE c.client_id = $1
+func (q *Queries) GetTenantByClientID(ctx context.Context, clientID string) (GetTenantByClientIDRow, error) {
+	row := q.db.QueryRow(ctx, GetTenantByClientID, clientID)
+	var i GetTenantByClientIDRow
+	err := row.Scan(&i.ID, &i.Slug, &i.Name)
+	return i, err
+}
+
+const GetTenantBySlug = `-- name: GetTenantBySlug :one
+SELECT id, slug, name
+FROM tenants
+WHERE slug = $1
+`
+
+type GetTenantBySlugRow struct {
+	ID   int32
+	Slug string
+	Name string
+}
+
+// GetTenantBySlug
+//
+//	SELECT id, slug, name
+//	FROM tenants
+//	WHERE slug = $1
+func (q *Queries) GetTenantBySlug(ctx context.Context, slug string) (GetTenantBySlugRow, error) {
+	row := q.db.QueryRow(ctx, GetTenantBySlug, slug)
+	var i GetTenantBySlugRow
+	err := row.Scan(&i.ID, &i.Slug, &i.Name)
+	return i, err
+}
+
+const GetUserByEmail = `-- name: GetUserByEmail :one
+SELECT username, salt, id, password_hash, email, kdf_version, active, email_verified
+FROM users
+WHERE tenant_id = $1
+  AND (email_index = $2 OR (email_index IS NULL AND email = $3))
+`
+
+type GetUserByEmailParams struct {
+	TenantID    int32
+	EmailIndex  fieldcrypt.BlindIndex
+	LegacyEmail fieldcrypt.Ciphertext
+}
+
+type GetUserByEmailRow struct {
+	Username      string
+	Salt          string
+	ID            int32
+	PasswordHash  string
+	Email         fieldcrypt.Ciphertext
+	KdfVersion    int32
+	Active        bool
+	EmailVerified bool
+}
+
+// 尚未重新加密的旧数据没有盲索引，按迁移后的明文格式匹配
+//
+//	SELECT username, salt, id, password_hash, email, kdf_version, active, email_verified
+//	FROM users
+//	WHERE tenant_id = $1
+//	  AND (email_index = $2 OR (email_index IS NULL AND email = $3))
+func (q *Queries) GetUserByEmail(ctx context.Context, arg GetUserByEmailParams) (GetUserByEmailRow, error) {
+	row := q.db.QueryRow(ctx, GetUserByEmail, arg.TenantID, arg.EmailIndex, arg.LegacyEmail)
+	var i GetUserByEmailRow
+	err := row.Scan(
+		&i.Username,
+		&i.Salt,
+		&i.ID,
+		&i.PasswordHash,
+		&i.Email,
+		&i.KdfVersion,
+		&i.Active,
+		&i.EmailVerified,
+	)
+	return i, err
+}
+
+const GetUserByName = `-- name: GetUserByName :one
+SELECT username, salt, id, password_hash, kdf_version, email, active, credential_backend, email_verified
+FROM users
+WHERE tenant_id = $1
+  AND username = $2
+`
+
+type GetUserByNameParams struct {
+	TenantID int32
+	Username string
+}
+
+type GetUserByNameRow struct {
+	Username          string
+	Salt              string
+	ID                int32
+	PasswordHash      string
+	KdfVersion        int32
+	Email             fieldcrypt.Ciphertext
+	Active            bool
+	CredentialBackend *string
+	EmailVerified     bool
+}
+
+// GetUserByName
+//
+//	SELECT username, salt, id, password_hash, kdf_version, email, active, credential_backend, email_verified
+//	FROM users
+//	WHERE tenant_id = $1
+//	  AND username = $2
+func (q *Queries) GetUserByName(ctx context.Context, arg GetUserByNameParams) (GetUserByNameRow, error) {
+	row := q.db.QueryRow(ctx, GetUserByName, arg.TenantID, arg.Username)
+	var i GetUserByNameRow
+	err := row.Scan(
+		&i.Username,
+		&i.Salt,
+		&i.ID,
+		&i.PasswordHash,
+		&i.KdfVersion,
+		&i.Email,
+		&i.Active,
+		&i.CredentialBackend,
+		&i.EmailVerified,
+	)
+	return i, err
+}
+
+const GetUserIdentity = `-- name: GetUserIdentity :one
+SELECT i.user_id, u.username, u.active
+FROM user_identities i
+         JOIN users u ON u.id = i.user_id
+WHERE i.tenant_id = $1
+  AND i.provider = $2
+  AND i.subject = $3
+`
+
+type GetUserIdentityParams struct {
+	TenantID int32
+	Provider string
+	Subject  string
+}
+
+type GetUserIdentityRow struct {
+	UserID   int32
+	Username string
+	Active   bool
+}
+
+// GetUserIdentity
+//
+//	SELECT i.user_id, u.username, u.active
+//	FROM user_identities i
+//	         JOIN users u ON u.id = i.user_id
+//	WHERE i.tenant_id = $1
+//	  AND i.provider = $2
+//	  AND i.subject = $3
+func (q *Queries) GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (GetUserIdentityRow, error) {
+	row := q.db.QueryRow(ctx, GetUserIdentity, arg.TenantID, arg.Provider, arg.Subject)
+	var i GetUserIdentityRow
+	err := row.Scan(&i.UserID, &i.Username, &i.Active)
+	return i, err
+}
+
+const GetUserRoles = `-- name: GetUserRoles :many
+SELECT r.role
+FROM user_roles r
+         JOIN users u ON u.id = r.user_id
+WHERE u.tenant_id = $1
+  AND r.user_id = $2
+ORDER BY r.role
+`
+
+type GetUserRolesParams struct {
+	TenantID int32
+	UserID   int32
+}
+
+// GetUserRoles
+//
+//	SELECT r.role
+//	FROM user_roles r
+//	         JOIN users u ON u.id = r.user_id
+//	WHERE u.tenant_id = $1
+//	  AND r.user_id = $2
+//	ORDER BY r.role
+func (q *Queries) GetUserRoles(ctx context.Context, arg GetUserRolesParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, GetUserRoles, arg.TenantID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, err
+		}
+		items = append(items, role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const IncrStateEntry = `-- name: IncrStateEntry :one
+INSERT INTO state_entries (key, value, expires_at)
+VALUES ($1, '1', $2)
+ON CONFLICT (key) DO UPDATE
+    SET value      = CASE
+                         WHEN state_entries.expires_at > now() THEN (state_entries.value::bigint + 1)::text
+                         ELSE '1' END,
+        expires_at = CASE
+                         WHEN state_entries.expires_at > now() THEN state_entries.expires_at
+                         ELSE EXCLUDED.expires_at END
+RETURNING value::bigint
+`
+
+type IncrStateEntryParams struct {
+	Key       string
+	ExpiresAt time.Time
+}
+
+// 过期的计数从1重新开始，过期时间只在计数开始时设置
+//
+//	INSERT INTO state_entries (key, value, expires_at)
+//	VALUES ($1, '1', $2)
+//	ON CONFLICT (key) DO UPDATE
+//	    SET value      = CASE
+//	                         WHEN state_entries.expires_at > now() THEN (state_entries.value::bigint + 1)::text
+//	                         ELSE '1' END,
+//	        expires_at = CASE
+//	                         WHEN state_entries.expires_at > now() THEN state_entries.expires_at
+//	                         ELSE EXCLUDED.expires_at END
+//	RETURNING value::bigint
+func (q *Queries) IncrStateEntry(ctx context.Context, arg IncrStateEntryParams) (int64, error) {
+	row := q.db.QueryRow(ctx, IncrStateEntry, arg.Key, arg.ExpiresAt)
+	var value int64
+	err := row.Scan(&value)
+	return value, err
+}
+
+const InsertTestUser = `-- name: InsertTestUser :one
+INSERT INTO users(tenant_id, username, password_hash, salt)
+VALUES (1, 'admin', 'asdas', '123123')
+RETURNING id, username, password_hash, salt, created_at, updated_at, tenant_id, kdf_version, email, external_id, display_name, given_name, family_name, active, credential_backend, email_index, email_verified
+`
+
+// InsertTestUser
+//
+//	INSERT INTO users(tenant_id, username, password_hash, salt)
+//	VALUES (1, 'admin', 'asdas', '123123')
+//	RETURNING id, username, password_hash, salt, created_at, updated_at, tenant_id, kdf_version, email, external_id, display_name, given_name, family_name, active, credential_backend, email_index, email_verified
+func (q *Queries) InsertTestUser(ctx context.Context) (User, error) {
+	row := q.db.QueryRow(ctx, InsertTestUser)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.Salt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TenantID,
+		&i.KdfVersion,
+		&i.Email,
+		&i.ExternalID,
+		&i.DisplayName,
+		&i.GivenName,
+		&i.FamilyName,
+		&i.Active,
+		&i.CredentialBackend,
+		&i.EmailIndex,
+		&i.EmailVerified,
+	)
+	return i, err
+}
+
+const ListDataKeys = `-- name: ListDataKeys :many
+SELECT id, purpose, kek_id, wrapped_key, created_at
+FROM data_keys
+ORDER BY id
+`
+
+// ListDataKeys
+//
+//	SELECT id, purpose, kek_id, wrapped_key, created_at
+//	FROM data_keys
+//	ORDER BY id
+func (q *Queries) ListDataKeys(ctx context.Context) ([]DataKey, error) {
+	rows, err := q.db.Query(ctx, ListDataKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DataKey
+	for rows.Next() {
+		var i DataKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.Purpose,
+			&i.KekID,
+			&i.WrappedKey,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListGroupMembers = `-- name: ListGroupMembers :many
+SELECT m.group_id, u.id AS user_id, u.username
+FROM group_members m
+         JOIN users u ON u.id = m.user_id
+WHERE u.tenant_id = $1
+  AND m.group_id = ANY ($2::INTEGER[])
+ORDER BY m.group_id, u.id
+`
+
+type ListGroupMembersParams struct {
+	TenantID int32
+	GroupIds []int32
+}
+
+type ListGroupMembersRow struct {
+	GroupID  int32
+	UserID   int32
+	Username string
+}
+
+// ListGroupMembers
+//
+//	SELECT m.group_id, u.id AS user_id, u.username
+//	FROM group_members m
+//	         JOIN users u ON u.id = m.user_id
+//	WHERE u.tenant_id = $1
+//	  AND m.group_id = ANY ($2::INTEGER[])
+//	ORDER BY m.group_id, u.id
+func (q *Queries) ListGroupMembers(ctx context.Context, arg ListGroupMembersParams) ([]ListGroupMembersRow, error) {
+	rows, err := q.db.Query(ctx, ListGroupMembers, arg.TenantID, arg.GroupIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListGroupMembersRow
+	for rows.Next() {
+		var i ListGroupMembersRow
+		if err := rows.Scan(&i.GroupID, &i.UserID, &i.Username); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListGroups = `-- name: ListGroups :many
+SELECT id, display_name, external_id, created_at, updated_at
+FROM user_groups
+WHERE tenant_id = $1
+  AND ($2::TEXT IS NULL OR lower(display_name) LIKE lower($2))
+  AND ($3::TEXT IS NULL OR external_id LIKE $3)
+ORDER BY id
+LIMIT $5 OFFSET $4
+`
+
+type ListGroupsParams struct {
+	TenantID    int32
+	DisplayName *string
+	ExternalID  *string
+	Skip        int32
+	MaxResults  int32
+}
+
+type ListGroupsRow struct {
+	ID          int32
+	DisplayName string
+	ExternalID  *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// ListGroups
+//
+//	SELECT id, display_name, external_id, created_at, updated_at
+//	FROM user_groups
+//	WHERE tenant_id = $1
+//	  AND ($2::TEXT IS NULL OR lower(display_name) LIKE lower($2))
+//	  AND ($3::TEXT IS NULL OR external_id LIKE $3)
+//	ORDER BY id
+//	LIMIT $5 OFFSET $4
+func (q *Queries) ListGroups(ctx context.Context, arg ListGroupsParams) ([]ListGroupsRow, error) {
+	rows, err := q.db.Query(ctx, ListGroups,
+		arg.TenantID,
+		arg.DisplayName,
+		arg.ExternalID,
+		arg.Skip,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListGroupsRow
+	for rows.Next() {
+		var i ListGroupsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayName,
+			&i.ExternalID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListIdentityEmails = `-- name: ListIdentityEmails :many
+SELECT id, tenant_id, email
+FROM user_identities
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListIdentityEmailsParams struct {
+	AfterID   int32
+	BatchSize int32
+}
+
+type ListIdentityEmailsRow struct {
+	ID       int32
+	TenantID int32
+	Email    fieldcrypt.Ciphertext
+}
+
+// ListIdentityEmails
+//
+//	SELECT id, tenant_id, email
+//	FROM user_identities
+//	WHERE id > $1
+//	ORDER BY id
+//	LIMIT $2
+func (q *Queries) ListIdentityEmails(ctx context.Context, arg ListIdentityEmailsParams) ([]ListIdentityEmailsRow, error) {
+	rows, err := q.db.Query(ctx, ListIdentityEmails, arg.AfterID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListIdentityEmailsRow
+	for rows.Next() {
+		var i ListIdentityEmailsRow
+		if err := rows.Scan(&i.ID, &i.TenantID, &i.Email); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListInvites = `-- name: ListInvites :many
+SELECT i.id,
+       i.inviter_id,
+       i.max_uses,
+       i.used_count,
+       i.expires_at,
+       i.created_at,
+       COALESCE(array_agg(r.user_id ORDER BY r.created_at) FILTER (WHERE r.user_id IS NOT NULL), '{}')::INTEGER[] AS invitee_ids
+FROM invites i
+         LEFT JOIN invite_redemptions r ON r.invite_id = i.id
+WHERE i.tenant_id = $1
+GROUP BY i.id
+ORDER BY i.id DESC
+`
+
+type ListInvitesRow struct {
+	ID         int32
+	InviterID  int32
+	MaxUses    int32
+	UsedCount  int32
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+	InviteeIds []int32
+}
+
+// ListInvites
+//
+//	SELECT i.id,
+//	       i.inviter_id,
+//	       i.max_uses,
+//	       i.used_count,
+//	       i.expires_at,
+//	       i.created_at,
+//	       COALESCE(array_agg(r.user_id ORDER BY r.created_at) FILTER (WHERE r.user_id IS NOT NULL), '{}')::INTEGER[] AS invitee_ids
+//	FROM invites i
+//	         LEFT JOIN invite_redemptions r ON r.invite_id = i.id
+//	WHERE i.tenant_id = $1
+//	GROUP BY i.id
+//	ORDER BY i.id DESC
+func (q *Queries) ListInvites(ctx context.Context, tenantID int32) ([]ListInvitesRow, error) {
+	rows, err := q.db.Query(ctx, ListInvites, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListInvitesRow
+	for rows.Next() {
+		var i ListInvitesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.InviterID,
+			&i.MaxUses,
+			&i.UsedCount,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.InviteeIds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListLoginEvents = `-- name: ListLoginEvents :many
+SELECT fingerprint, ip_prefix, risk_score, reasons, step_up, created_at
+FROM login_events
+WHERE tenant_id = $1
+  AND user_id = $2
+  AND created_at > $3
+ORDER BY created_at DESC
+LIMIT 500
+`
+
+type ListLoginEventsParams struct {
+	TenantID int32
+	UserID   int32
+	Since    time.Time
+}
+
+type ListLoginEventsRow struct {
+	Fingerprint string
+	IpPrefix    string
+	RiskScore   int32
+	Reasons     []string
+	StepUp      bool
+	CreatedAt   time.Time
+}
+
+// ListLoginEvents
+//
+//	SELECT fingerprint, ip_prefix, risk_score, reasons, step_up, created_at
+//	FROM login_events
+//	WHERE tenant_id = $1
+//	  AND user_id = $2
+//	  AND created_at > $3
+//	ORDER BY created_at DESC
+//	LIMIT 500
+func (q *Queries) ListLoginEvents(ctx context.Context, arg ListLoginEventsParams) ([]ListLoginEventsRow, error) {
+	rows, err := q.db.Query(ctx, ListLoginEvents, arg.TenantID, arg.UserID, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLoginEventsRow
+	for rows.Next() {
+		var i ListLoginEventsRow
+		if err := rows.Scan(
+			&i.Fingerprint,
+			&i.IpPrefix,
+			&i.RiskScore,
+			&i.Reasons,
+			&i.StepUp,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListPendingOutboxEvents = `-- name: ListPendingOutboxEvents :many
+SELECT id, tenant_id, aggregate_type, aggregate_id, event_type, payload, created_at
+FROM outbox
+WHERE published_at IS NULL
+ORDER BY id
+LIMIT $1
+`
+
+type ListPendingOutboxEventsRow struct {
+	ID            int64
+	TenantID      int32
+	AggregateType string
+	AggregateID   string
+	EventType     string
+	Payload       []byte
+	CreatedAt     time.Time
+}
+
+// ListPendingOutboxEvents
+//
+//	SELECT id, tenant_id, aggregate_type, aggregate_id, event_type, payload, created_at
+//	FROM outbox
+//	WHERE published_at IS NULL
+//	ORDER BY id
+//	LIMIT $1
+func (q *Queries) ListPendingOutboxEvents(ctx context.Context, batchSize int32) ([]ListPendingOutboxEventsRow, error) {
+	rows, err := q.db.Query(ctx, ListPendingOutboxEvents, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPendingOutboxEventsRow
+	for rows.Next() {
+		var i ListPendingOutboxEventsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.AggregateType,
+			&i.AggregateID,
+			&i.EventType,
+			&i.Payload,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListScimUsers = `-- name: ListScimUsers :many
+SELECT id, external_id, username, display_name, given_name, family_name, email, active, created_at, updated_at
+FROM users
+WHERE tenant_id = $1
+  AND ($2::TEXT IS NULL OR lower(username) LIKE lower($2))
+  AND ($3::TEXT IS NULL OR external_id LIKE $3)
+  AND ($4::BYTEA IS NULL OR email_index = $4 OR
+       (email_index IS NULL AND email = $5::BYTEA))
+  AND (NOT $6::BOOLEAN OR email IS NOT NULL)
+ORDER BY id
+LIMIT $8 OFFSET $7
+`
+
+type ListScimUsersParams struct {
+	TenantID    int32
+	UserName    *string
+	ExternalID  *string
+	EmailIndex  []byte
+	LegacyEmail []byte
+	HasEmail    bool
+	Skip        int32
+	MaxResults  int32
+}
+
+type ListScimUsersRow struct {
+	ID          int32
+	ExternalID  *string
+	Username    string
+	DisplayName fieldcrypt.Ciphertext
+	GivenName   fieldcrypt.Ciphertext
+	FamilyName  fieldcrypt.Ciphertext
+	Email       fieldcrypt.Ciphertext
+	Active      bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// userName、externalId 的过滤条件为 LIKE 模式，userName 不区分大小写；邮箱已加密，只能按盲索引精确匹配；为空时不过滤
+//
+//	SELECT id, external_id, username, display_name, given_name, family_name, email, active, created_at, updated_at
+//	FROM users
+//	WHERE tenant_id = $1
+//	  AND ($2::TEXT IS NULL OR lower(username) LIKE lower($2))
+//	  AND ($3::TEXT IS NULL OR external_id LIKE $3)
+//	  AND ($4::BYTEA IS NULL OR email_index = $4 OR
+//	       (email_index IS NULL AND email = $5::BYTEA))
+//	  AND (NOT $6::BOOLEAN OR email IS NOT NULL)
+//	ORDER BY id
+//	LIMIT $8 OFFSET $7
+func (q *Queries) ListScimUsers(ctx context.Context, arg ListScimUsersParams) ([]ListScimUsersRow, error) {
+	rows, err := q.db.Query(ctx, ListScimUsers,
+		arg.TenantID,
+		arg.UserName,
+		arg.ExternalID,
+		arg.EmailIndex,
+		arg.LegacyEmail,
+		arg.HasEmail,
+		arg.Skip,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListScimUsersRow
+	for rows.Next() {
+		var i ListScimUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ExternalID,
+			&i.Username,
+			&i.DisplayName,
+			&i.GivenName,
+			&i.FamilyName,
+			&i.Email,
+			&i.Active,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListUserDevices = `-- name: ListUserDevices :many
+SELECT id, fingerprint, device_id, user_agent, ip_prefix, location_hint, first_seen_at, last_seen_at
+FROM user_devices
+WHERE tenant_id = $1
+  AND user_id = $2
+ORDER BY last_seen_at DESC
+`
+
+type ListUserDevicesParams struct {
+	TenantID int32
+	UserID   int32
+}
+
+type ListUserDevicesRow struct {
+	ID           int32
+	Fingerprint  string
+	DeviceID     string
+	UserAgent    string
+	IpPrefix     string
+	LocationHint string
+	FirstSeenAt  time.Time
+	LastSeenAt   time.Time
+}
+
+// ListUserDevices
+//
+//	SELECT id, fingerprint, device_id, user_agent, ip_prefix, location_hint, first_seen_at, last_seen_at
+//	FROM user_devices
+//	WHERE tenant_id = $1
+//	  AND user_id = $2
+//	ORDER BY last_seen_at DESC
+func (q *Queries) ListUserDevices(ctx context.Context, arg ListUserDevicesParams) ([]ListUserDevicesRow, error) {
+	rows, err := q.db.Query(ctx, ListUserDevices, arg.TenantID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserDevicesRow
+	for rows.Next() {
+		var i ListUserDevicesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Fingerprint,
+			&i.DeviceID,
+			&i.UserAgent,
+			&i.IpPrefix,
+			&i.LocationHint,
+			&i.FirstSeenAt,
+			&i.LastSeenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListUserFields = `-- name: ListUserFields :many
+SELECT id, tenant_id, email, email_index, display_name, given_name, family_name
+FROM users
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListUserFieldsParams struct {
+	AfterID   int32
+	BatchSize int32
+}
+
+type ListUserFieldsRow struct {
+	ID          int32
+	TenantID    int32
+	Email       fieldcrypt.Ciphertext
+	EmailIndex  fieldcrypt.BlindIndex
+	DisplayName fieldcrypt.Ciphertext
+	GivenName   fieldcrypt.Ciphertext
+	FamilyName  fieldcrypt.Ciphertext
+}
+
+// 按 ID 分批读取加密字段，由重新加密任务检查是否需要重新加密
+//
+//	SELECT id, tenant_id, email, email_index, display_name, given_name, family_name
+//	FROM users
+//	WHERE id > $1
+//	ORDER BY id
+//	LIMIT $2
+func (q *Queries) ListUserFields(ctx context.Context, arg ListUserFieldsParams) ([]ListUserFieldsRow, error) {
+	rows, err := q.db.Query(ctx, ListUserFields, arg.AfterID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserFieldsRow
+	for rows.Next() {
+		var i ListUserFieldsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.Email,
+			&i.EmailIndex,
+			&i.DisplayName,
+			&i.GivenName,
+			&i.FamilyName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListUsersWithoutEmailIndex = `-- name: ListUsersWithoutEmailIndex :many
+SELECT id, tenant_id, email, email_index, display_name, given_name, family_name
+FROM users
+WHERE id > $1
+  AND email IS NOT NULL
+  AND email_index IS NULL
+ORDER BY id
+LIMIT $2
+`
+
+type ListUsersWithoutEmailIndexParams struct {
+	AfterID   int32
+	BatchSize int32
+}
+
+type ListUsersWithoutEmailIndexRow struct {
+	ID          int32
+	TenantID    int32
+	Email       fieldcrypt.Ciphertext
+	EmailIndex  fieldcrypt.BlindIndex
+	DisplayName fieldcrypt.Ciphertext
+	GivenName   fieldcrypt.Ciphertext
+	FamilyName  fieldcrypt.Ciphertext
+}
+
+// 迁移前的明文邮箱还没有盲索引，启动时补齐后才对外服务
+//
+//	SELECT id, tenant_id, email, email_index, display_name, given_name, family_name
+//	FROM users
+//	WHERE id > $1
+//	  AND email IS NOT NULL
+//	  AND email_index IS NULL
+//	ORDER BY id
+//	LIMIT $2
+func (q *Queries) ListUsersWithoutEmailIndex(ctx context.Context, arg ListUsersWithoutEmailIndexParams) ([]ListUsersWithoutEmailIndexRow, error) {
+	rows, err := q.db.Query(ctx, ListUsersWithoutEmailIndex, arg.AfterID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUsersWithoutEmailIndexRow
+	for rows.Next() {
+		var i ListUsersWithoutEmailIndexRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.Email,
+			&i.EmailIndex,
+			&i.DisplayName,
+			&i.GivenName,
+			&i.FamilyName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListWebhookDeliveries = `-- name: ListWebhookDeliveries :many
+SELECT id,
+       subscription_id,
+       event_id,
+       event_type,
+       status,
+       attempts,
+       next_attempt_at,
+       last_attempt_at,
+       response_status,
+       last_error,
+       delivered_at,
+       created_at
+FROM webhook_deliveries
+WHERE tenant_id = $1
+  AND subscription_id = $2
+ORDER BY id DESC
+LIMIT $3
+`
+
+type ListWebhookDeliveriesParams struct {
+	TenantID       int32
+	SubscriptionID int32
+	MaxResults     int32
+}
+
+type ListWebhookDeliveriesRow struct {
+	ID             int64
+	SubscriptionID int32
+	EventID        int64
+	EventType      string
+	Status         string
+	Attempts       int32
+	NextAttemptAt  time.Time
+	LastAttemptAt  pgtype.Timestamptz
+	ResponseStatus *int32
+	LastError      *string
+	DeliveredAt    pgtype.Timestamptz
+	CreatedAt      time.Time
+}
+
+// ListWebhookDeliveries
+//
+//	SELECT id,
+//	       subscription_id,
+//	       event_id,
+//	       event_type,
+//	       status,
+//	       attempts,
+//	       next_attempt_at,
+//	       last_attempt_at,
+//	       response_status,
+//	       last_error,
+//	       delivered_at,
+//	       created_at
+//	FROM webhook_deliveries
+//	WHERE tenant_id = $1
+//	  AND subscription_id = $2
+//	ORDER BY id DESC
+//	LIMIT $3
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]ListWebhookDeliveriesRow, error) {
+	rows, err := q.db.Query(ctx, ListWebhookDeliveries, arg.TenantID, arg.SubscriptionID, arg.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWebhookDeliveriesRow
+	for rows.Next() {
+		var i ListWebhookDeliveriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventID,
+			&i.EventType,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastAttemptAt,
+			&i.ResponseStatus,
+			&i.LastError,
+			&i.DeliveredAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListWebhookSubscriptions = `-- name: ListWebhookSubscriptions :many
+SELECT id, url, events, created_at
+FROM webhook_subscriptions
+WHERE tenant_id = $1
+ORDER BY id
+`
+
+type ListWebhookSubscriptionsRow struct {
+	ID        int32
+	Url       string
+	Events    []string
+	CreatedAt time.Time
+}
+
+// ListWebhookSubscriptions
+//
+//	SELECT id, url, events, created_at
+//	FROM webhook_subscriptions
+//	WHERE tenant_id = $1
+//	ORDER BY id
+func (q *Queries) ListWebhookSubscriptions(ctx context.Context, tenantID int32) ([]ListWebhookSubscriptionsRow, error) {
+	rows, err := q.db.Query(ctx, ListWebhookSubscriptions, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWebhookSubscriptionsRow
+	for rows.Next() {
+		var i ListWebhookSubscriptionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Events,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const LockDataKeys = `-- name: LockDataKeys :exec
+SELECT pg_advisory_xact_lock(hashtextextended('data_keys', 0))
+`
+
+// 创建、轮换和重新加密数据密钥前加锁，多副本同时启动时只有一个创建密钥
+//
+//	SELECT pg_advisory_xact_lock(hashtextextended('data_keys', 0))
+func (q *Queries) LockDataKeys(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, LockDataKeys)
+	return err
+}
+
+const MarkEmailVerified = `-- name: MarkEmailVerified :exec
+UPDATE users
+SET email_verified = true,
+    updated_at     = now()
+WHERE tenant_id = $1
+  AND id = $2
+  AND (email_index = $3 OR (email_index IS NULL AND email = $4))
+`
+
+type MarkEmailVerifiedParams struct {
+	TenantID    int32
+	ID          int32
+	EmailIndex  fieldcrypt.BlindIndex
+	LegacyEmail fieldcrypt.Ciphertext
+}
+
+// 只有邮箱仍是验证时的地址才标记
+//
+//	UPDATE users
+//	SET email_verified = true,
+//	    updated_at     = now()
+//	WHERE tenant_id = $1
+//	  AND id = $2
+//	  AND (email_index = $3 OR (email_index IS NULL AND email = $4))
+func (q *Queries) MarkEmailVerified(ctx context.Context, arg MarkEmailVerifiedParams) error {
+	_, err := q.db.Exec(ctx, MarkEmailVerified,
+		arg.TenantID,
+		arg.ID,
+		arg.EmailIndex,
+		arg.LegacyEmail,
+	)
+	return err
+}
+
+const MarkOutboxEventsPublished = `-- name: MarkOutboxEventsPublished :exec
+UPDATE outbox
+SET published_at = now()
+WHERE id = ANY ($1::bigint[])
+`
+
+// MarkOutboxEventsPublished
+//
+//	UPDATE outbox
+//	SET published_at = now()
+//	WHERE id = ANY ($1::bigint[])
+func (q *Queries) MarkOutboxEventsPublished(ctx context.Context, ids []int64) error {
+	_, err := q.db.Exec(ctx, MarkOutboxEventsPublished, ids)
+	return err
+}
+
+const PurgePublishedOutboxEvents = `-- name: PurgePublishedOutboxEvents :execrows
+DELETE
+FROM outbox
+WHERE published_at < $1::timestamptz
+`
+
+// PurgePublishedOutboxEvents
+//
+//	DELETE
+//	FROM outbox
+//	WHERE published_at < $1::timestamptz
+func (q *Queries) PurgePublishedOutboxEvents(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, PurgePublishedOutboxEvents, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const PurgeStateEntries = `-- name: PurgeStateEntries :exec
+DELETE
+FROM state_entries
+WHERE expires_at <= now()
+`
+
+// PurgeStateEntries
+//
+//	DELETE
+//	FROM state_entries
+//	WHERE expires_at <= now()
+func (q *Queries) PurgeStateEntries(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, PurgeStateEntries)
+	return err
+}
+
+const RecordWebhookAttempt = `-- name: RecordWebhookAttempt :exec
+UPDATE webhook_deliveries
+SET status          = $1,
+    attempts        = attempts + 1,
+    next_attempt_at = $2,
+    last_attempt_at = now(),
+    response_status = $3,
+    last_error      = $4,
+    delivered_at    = CASE WHEN $1 = 'succeeded' THEN now() END
+WHERE id = $5
+`
+
+type RecordWebhookAttemptParams struct {
+	Status         string
+	NextAttemptAt  time.Time
+	ResponseStatus *int32
+	LastError      *string
+	ID             int64
+}
+
+// RecordWebhookAttempt
+//
+//	UPDATE webhook_deliveries
+//	SET status          = $1,
+//	    attempts        = attempts + 1,
+//	    next_attempt_at = $2,
+//	    last_attempt_at = now(),
+//	    response_status = $3,
+//	    last_error      = $4,
+//	    delivered_at    = CASE WHEN $1 = 'succeeded' THEN now() END
+//	WHERE id = $5
+func (q *Queries) RecordWebhookAttempt(ctx context.Context, arg RecordWebhookAttemptParams) error {
+	_, err := q.db.Exec(ctx, RecordWebhookAttempt,
+		arg.Status,
+		arg.NextAttemptAt,
+		arg.ResponseStatus,
+		arg.LastError,
+		arg.ID,
+	)
+	return err
+}
+
+const RedeemInvite = `-- name: RedeemInvite :one
+UPDATE invites
+SET used_count = used_count + 1
+WHERE id = $1
+  AND tenant_id = $2
+  AND used_count < max_uses
+  AND expires_at > now()
+RETURNING id, inviter_id, max_uses, used_count, expires_at, created_at
+`
+
+type RedeemInviteParams struct {
+	ID       int32
+	TenantID int32
+}
+
+type RedeemInviteRow struct {
+	ID        int32
+	InviterID int32
+	MaxUses   int32
+	UsedCount int32
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// 条件更新保证并发注册时不会超出可用次数
+//
+//	UPDATE invites
+//	SET used_count = used_count + 1
+//	WHERE id = $1
+//	  AND tenant_id = $2
+//	  AND used_count < max_uses
+//	  AND expires_at > now()
+//	RETURNING id, inviter_id, max_uses, used_count, expires_at, created_at
+func (q *Queries) RedeemInvite(ctx context.Context, arg RedeemInviteParams) (RedeemInviteRow, error) {
+	row := q.db.QueryRow(ctx, RedeemInvite, arg.ID, arg.TenantID)
+	var i RedeemInviteRow
+	err := row.Scan(
+		&i.ID,
+		&i.InviterID,
+		&i.MaxUses,
+		&i.UsedCount,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const RedeliverWebhookDelivery = `-- name: RedeliverWebhookDelivery :one
+UPDATE webhook_deliveries
+SET status          = 'pending',
+    attempts        = 0,
+    next_attempt_at = now(),
+    delivered_at    = NULL
+WHERE id = $1
+  AND tenant_id = $2
+RETURNING id,
+    subscription_id,
+    event_id,
+    event_type,
+    status,
+    attempts,
+    next_attempt_at,
+    last_attempt_at,
+    response_status,
+    last_error,
+    delivered_at,
+    created_at
+`
+
+type RedeliverWebhookDeliveryParams struct {
+	ID       int64
+	TenantID int32
+}
+
+type RedeliverWebhookDeliveryRow struct {
+	ID             int64
+	SubscriptionID int32
+	EventID        int64
+	EventType      string
+	Status         string
+	Attempts       int32
+	NextAttemptAt  time.Time
+	LastAttemptAt  pgtype.Timestamptz
+	ResponseStatus *int32
+	LastError      *string
+	DeliveredAt    pgtype.Timestamptz
+	CreatedAt      time.Time
+}
+
+// 重新投递从第一次尝试开始计算退避，已成功的投递也可以重新投递
+//
+//	UPDATE webhook_deliveries
+//	SET status          = 'pending',
+//	    attempts        = 0,
+//	    next_attempt_at = now(),
+//	    delivered_at    = NULL
+//	WHERE id = $1
+//	  AND tenant_id = $2
+//	RETURNING id,
+//	    subscription_id,
+//	    event_id,
+//	    event_type,
+//	    status,
+//	    attempts,
+//	    next_attempt_at,
+//	    last_attempt_at,
+//	    response_status,
+//	    last_error,
+//	    delivered_at,
+//	    created_at
+func (q *Queries) RedeliverWebhookDelivery(ctx context.Context, arg RedeliverWebhookDeliveryParams) (RedeliverWebhookDeliveryRow, error) {
+	row := q.db.QueryRow(ctx, RedeliverWebhookDelivery, arg.ID, arg.TenantID)
+	var i RedeliverWebhookDeliveryRow
+	err := row.Scan(
+		&i.ID,
+		&i.SubscriptionID,
+		&i.EventID,
+		&i.EventType,
+		&i.Status,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&i.LastAttemptAt,
+		&i.ResponseStatus,
+		&i.LastError,
+		&i.DeliveredAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const ReencryptIdentityEmail = `-- name: ReencryptIdentityEmail :execrows
+UPDATE user_identities
+SET email = $1
+WHERE id = $2
+  AND email IS NOT DISTINCT FROM $3::BYTEA
+`
+
+type ReencryptIdentityEmailParams struct {
+	Email    fieldcrypt.Ciphertext
+	ID       int32
+	OldEmail []byte
+}
+
+// ReencryptIdentityEmail
+//
+//	UPDATE user_identities
+//	SET email = $1
+//	WHERE id = $2
+//	  AND email IS NOT DISTINCT FROM $3::BYTEA
+func (q *Queries) ReencryptIdentityEmail(ctx context.Context, arg ReencryptIdentityEmailParams) (int64, error) {
+	result, err := q.db.Exec(ctx, ReencryptIdentityEmail, arg.Email, arg.ID, arg.OldEmail)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const ReencryptUserFields = `-- name: ReencryptUserFields :execrows
+UPDATE users
+SET email        = $1,
+    email_index  = $2,
+    display_name = $3,
+    given_name   = $4,
+    family_name  = $5
+WHERE id = $6
+  AND email IS NOT DISTINCT FROM $7::BYTEA
+  AND display_name IS NOT DISTINCT FROM $8::BYTEA
+  AND given_name IS NOT DISTINCT FROM $9::BYTEA
+  AND family_name IS NOT DISTINCT FROM $10::BYTEA
+`
+
+type ReencryptUserFieldsParams struct {
+	Email          fieldcrypt.Ciphertext
+	EmailIndex     fieldcrypt.BlindIndex
+	DisplayName    fieldcrypt.Ciphertext
+	GivenName      fieldcrypt.Ciphertext
+	FamilyName     fieldcrypt.Ciphertext
+	ID             int32
+	OldEmail       []byte
+	OldDisplayName []byte
+	OldGivenName   []byte
+	OldFamilyName  []byte
+}
+
+// 字段在读取后被修改时不更新，由下一轮处理
+//
+//	UPDATE users
+//	SET email        = $1,
+//	    email_index  = $2,
+//	    display_name = $3,
+//	    given_name   = $4,
+//	    family_name  = $5
+//	WHERE id = $6
+//	  AND email IS NOT DISTINCT FROM $7::BYTEA
+//	  AND display_name IS NOT DISTINCT FROM $8::BYTEA
+//	  AND given_name IS NOT DISTINCT FROM $9::BYTEA
+//	  AND family_name IS NOT DISTINCT FROM $10::BYTEA
+func (q *Queries) ReencryptUserFields(ctx context.Context, arg ReencryptUserFieldsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, ReencryptUserFields,
+		arg.Email,
+		arg.EmailIndex,
+		arg.DisplayName,
+		arg.GivenName,
+		arg.FamilyName,
+		arg.ID,
+		arg.OldEmail,
+		arg.OldDisplayName,
+		arg.OldGivenName,
+		arg.OldFamilyName,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const RemoveGroupMembers = `-- name: RemoveGroupMembers :exec
+DELETE
+FROM group_members
+WHERE group_id = $1
+  AND user_id = ANY ($2::INTEGER[])
+`
+
+type RemoveGroupMembersParams struct {
+	GroupID int32
+	UserIds []int32
+}
+
+// RemoveGroupMembers
+//
+//	DELETE
+//	FROM group_members
+//	WHERE group_id = $1
+//	  AND user_id = ANY ($2::INTEGER[])
+func (q *Queries) RemoveGroupMembers(ctx context.Context, arg RemoveGroupMembersParams) error {
+	_, err := q.db.Exec(ctx, RemoveGroupMembers, arg.GroupID, arg.UserIds)
+	return err
+}
+
+const RewrapDataKey = `-- name: RewrapDataKey :exec
+UPDATE data_keys
+SET kek_id      = $1,
+    wrapped_key = $2
+WHERE id = $3
+`
+
+type RewrapDataKeyParams struct {
+	KekID      string
+	WrappedKey []byte
+	ID         int32
+}
+
+// RewrapDataKey
+//
+//	UPDATE data_keys
+//	SET kek_id      = $1,
+//	    wrapped_key = $2
+//	WHERE id = $3
+func (q *Queries) RewrapDataKey(ctx context.Context, arg RewrapDataKeyParams) error {
+	_, err := q.db.Exec(ctx, RewrapDataKey, arg.KekID, arg.WrappedKey, arg.ID)
+	return err
+}
+
+const SetCredentialBackend = `-- name: SetCredentialBackend :execrows
+UPDATE users
+SET credential_backend = $1,
+    updated_at         = now()
+WHERE tenant_id = $2
+  AND username = $3
+`
+
+type SetCredentialBackendParams struct {
+	CredentialBackend *string
+	TenantID          int32
+	Username          string
+}
+
+// credential_backend 为 NULL 时使用租户默认后端
+//
+//	UPDATE users
+//	SET credential_backend = $1,
+//	    updated_at         = now()
+//	WHERE tenant_id = $2
+//	  AND username = $3
+func (q *Queries) SetCredentialBackend(ctx context.Context, arg SetCredentialBackendParams) (int64, error) {
+	result, err := q.db.Exec(ctx, SetCredentialBackend, arg.CredentialBackend, arg.TenantID, arg.Username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const SetStateEntry = `-- name: SetStateEntry :exec
+INSERT INTO state_entries (key, value, expires_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (key) DO UPDATE
+    SET value      = EXCLUDED.value,
+        expires_at = EXCLUDED.expires_at
+`
+
+type SetStateEntryParams struct {
+	Key       string
+	Value     string
+	ExpiresAt time.Time
+}
+
+// SetStateEntry
+//
+//	INSERT INTO state_entries (key, value, expires_at)
+//	VALUES ($1, $2, $3)
+//	ON CONFLICT (key) DO UPDATE
+//	    SET value      = EXCLUDED.value,
+//	        expires_at = EXCLUDED.expires_at
+func (q *Queries) SetStateEntry(ctx context.Context, arg SetStateEntryParams) error {
+	_, err := q.db.Exec(ctx, SetStateEntry, arg.Key, arg.Value, arg.ExpiresAt)
+	return err
+}
+
+const SetStateEntryNX = `-- name: SetStateEntryNX :execrows
+INSERT INTO state_entries (key, value, expires_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (key) DO UPDATE
+    SET value      = EXCLUDED.value,
+        expires_at = EXCLUDED.expires_at
+WHERE state_entries.expires_at <= now()
+`
+
+type SetStateEntryNXParams struct {
+	Key       string
+	Value     string
+	ExpiresAt time.Time
+}
+
+// 键不存在或已过期时写入，影响行数为0说明键仍然有效
+//
+//	INSERT INTO state_entries (key, value, expires_at)
+//	VALUES ($1, $2, $3)
+//	ON CONFLICT (key) DO UPDATE
+//	    SET value      = EXCLUDED.value,
+//	        expires_at = EXCLUDED.expires_at
+//	WHERE state_entries.expires_at <= now()
+func (q *Queries) SetStateEntryNX(ctx context.Context, arg SetStateEntryNXParams) (int64, error) {
+	result, err := q.db.Exec(ctx, SetStateEntryNX, arg.Key, arg.Value, arg.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const SyncDirectoryUser = `-- name: SyncDirectoryUser :one
+INSERT INTO users (tenant_id, username, password_hash, salt, email, email_index, display_name, given_name, family_name, credential_backend, email_verified)
+VALUES ($1, $2, '', '', $3, $4, $5, $6, $7, $8, $3 IS NOT NULL)
+ON CONFLICT (tenant_id, username) DO UPDATE
+    SET email          = COALESCE(EXCLUDED.email, users.email),
+        email_index    = CASE WHEN EXCLUDED.email IS NULL THEN users.email_index ELSE EXCLUDED.email_index END,
+        email_verified = EXCLUDED.email_verified OR users.email_verified,
+        display_name   = EXCLUDED.display_name,
+        given_name     = EXCLUDED.given_name,
+        family_name    = EXCLUDED.family_name,
+        updated_at     = now()
+WHERE users.credential_backend = EXCLUDED.credential_backend
+   OR (users.credential_backend IS NULL AND users.password_hash = '')
+RETURNING id, active
+`
+
+type SyncDirectoryUserParams struct {
+	TenantID          int32
+	Username          string
+	Email             fieldcrypt.Ciphertext
+	EmailIndex        fieldcrypt.BlindIndex
+	DisplayName       fieldcrypt.Ciphertext
+	GivenName         fieldcrypt.Ciphertext
+	FamilyName        fieldcrypt.Ciphertext
+	CredentialBackend *string
+}
+
+type SyncDirectoryUserRow struct {
+	ID     int32
+	Active bool
+}
+
+// 目录服务登录成功后同步属性，首次登录时创建没有本地凭证的用户；已有用户不修改 credential_backend。
+// 已有用户属于其他后端（包括有本地凭证、未指定后端的用户）时不更新，返回空结果。
+// 目录服务返回的邮箱视为已验证
+//
+//	INSERT INTO users (tenant_id, username, password_hash, salt, email, email_index, display_name, given_name, family_name, credential_backend, email_verified)
+//	VALUES ($1, $2, '', '', $3, $4, $5, $6, $7, $8, $3 IS NOT NULL)
+//	ON CONFLICT (tenant_id, username) DO UPDATE
+//	    SET email          = COALESCE(EXCLUDED.email, users.email),
+//	        email_index    = CASE WHEN EXCLUDED.email IS NULL THEN users.email_index ELSE EXCLUDED.email_index END,
+//	        email_verified = EXCLUDED.email_verified OR users.email_verified,
+//	        display_name   = EXCLUDED.display_name,
+//	        given_name     = EXCLUDED.given_name,
+//	        family_name    = EXCLUDED.family_name,
+//	        updated_at     = now()
+//	WHERE users.credential_backend = EXCLUDED.credential_backend
+//	   OR (users.credential_backend IS NULL AND users.password_hash = '')
+//	RETURNING id, active
+func (q *Queries) SyncDirectoryUser(ctx context.Context, arg SyncDirectoryUserParams) (SyncDirectoryUserRow, error) {
+	row := q.db.QueryRow(ctx, SyncDirectoryUser,
+		arg.TenantID,
+		arg.Username,
+		arg.Email,
+		arg.EmailIndex,
+		arg.DisplayName,
+		arg.GivenName,
+		arg.FamilyName,
+		arg.CredentialBackend,
+	)
+	var i SyncDirectoryUserRow
+	err := row.Scan(&i.ID, &i.Active)
+	return i, err
+}
+
+const TakeStateEntry = `-- name: TakeStateEntry :one
+DELETE
+FROM state_entries
+WHERE key = $1
+  AND expires_at > now()
+RETURNING value
+`
+
+// TakeStateEntry
+//
+//	DELETE
+//	FROM state_entries
+//	WHERE key = $1
+//	  AND expires_at > now()
+//	RETURNING value
+func (q *Queries) TakeStateEntry(ctx context.Context, key string) (string, error) {
+	row := q.db.QueryRow(ctx, TakeStateEntry, key)
+	var value string
+	err := row.Scan(&value)
+	return value, err
+}
+
+const TouchUserIdentity = `-- name: TouchUserIdentity :exec
+UPDATE user_identities
+SET email         = $1,
+    last_login_at = now()
+WHERE tenant_id = $2
+  AND provider = $3
+  AND subject = $4
+`
+
+type TouchUserIdentityParams struct {
+	Email    fieldcrypt.Ciphertext
+	TenantID int32
+	Provider string
+	Subject  string
+}
+
+// TouchUserIdentity
+//
+//	UPDATE user_identities
+//	SET email         = $1,
+//	    last_login_at = now()
+//	WHERE tenant_id = $2
+//	  AND provider = $3
+//	  AND subject = $4
+func (q *Queries) TouchUserIdentity(ctx context.Context, arg TouchUserIdentityParams) error {
+	_, err := q.db.Exec(ctx, TouchUserIdentity,
+		arg.Email,
+		arg.TenantID,
+		arg.Provider,
+		arg.Subject,
+	)
+	return err
+}
+
+const TryLockOutboxRelay = `-- name: TryLockOutboxRelay :one
+SELECT pg_try_advisory_xact_lock(hashtextextended('outbox_relay', 0))
+`
+
+// 多副本时只有持有锁的副本投递，锁在事务结束时释放
+//
+//	SELECT pg_try_advisory_xact_lock(hashtextextended('outbox_relay', 0))
+func (q *Queries) TryLockOutboxRelay(ctx context.Context) (bool, error) {
+	row := q.db.QueryRow(ctx, TryLockOutboxRelay)
+	var pg_try_advisory_xact_lock bool
+	err := row.Scan(&pg_try_advisory_xact_lock)
+	return pg_try_advisory_xact_lock, err
+}
+
+const UpdateCredential = `-- name: UpdateCredential :exec
+UPDATE users
+SET password_hash = $1,
+    salt          = $2,
+    kdf_version   = $3,
+    updated_at    = now()
+WHERE tenant_id = $4
+  AND id = $5
+`
+
+type UpdateCredentialParams struct {
+	PasswordHash string
+	Salt         string
+	KdfVersion   int32
+	TenantID     int32
+	ID           int32
+}
+
+// UpdateCredential
+//
+//	UPDATE users
+//	SET password_hash = $1,
+//	    salt          = $2,
+//	    kdf_version   = $3,
+//	    updated_at    = now()
+//	WHERE tenant_id = $4
+//	  AND id = $5
+func (q *Queries) UpdateCredential(ctx context.Context, arg UpdateCredentialParams) error {
+	_, err := q.db.Exec(ctx, UpdateCredential,
+		arg.PasswordHash,
+		arg.Salt,
+		arg.KdfVersion,
+		arg.TenantID,
+		arg.ID,
+	)
+	return err
+}
+
+const UpdateGroup = `-- name: UpdateGroup :one
+UPDATE user_groups
+SET display_name = $1,
+    external_id  = $2,
+    updated_at   = now()
+WHERE tenant_id = $3
+  AND id = $4
+RETURNING id, display_name, external_id, created_at, updated_at
+`
+
+type UpdateGroupParams struct {
+	DisplayName string
+	ExternalID  *string
+	TenantID    int32
+	ID          int32
+}
+
+type UpdateGroupRow struct {
+	ID          int32
+	DisplayName string
+	ExternalID  *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// UpdateGroup
+//
+//	UPDATE user_groups
+//	SET display_name = $1,
+//	    external_id  = $2,
+//	    updated_at   = now()
+//	WHERE tenant_id = $3
+//	  AND id = $4
+//	RETURNING id, display_name, external_id, created_at, updated_at
+func (q *Queries) UpdateGroup(ctx context.Context, arg UpdateGroupParams) (UpdateGroupRow, error) {
+	row := q.db.QueryRow(ctx, UpdateGroup,
+		arg.DisplayName,
+		arg.ExternalID,
+		arg.TenantID,
+		arg.ID,
+	)
+	var i UpdateGroupRow
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayName,
+		&i.ExternalID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const UpdateScimUser = `-- name: UpdateScimUser :one
+UPDATE users
+SET username       = $1,
+    email          = $2,
+    email_index    = $3,
+    email_verified = $2 IS NOT NULL,
+    external_id    = $4,
+    display_name   = $5,
+    given_name     = $6,
+    family_name    = $7,
+    active         = $8,
+    updated_at     = now()
+WHERE tenant_id = $9
+  AND id = $10
+RETURNING id, external_id, username, display_name, given_name, family_name, email, active, created_at, updated_at
+`
+
+type UpdateScimUserParams struct {
+	Username    string
+	Email       fieldcrypt.Ciphertext
+	EmailIndex  fieldcrypt.BlindIndex
+	ExternalID  *string
+	DisplayName fieldcrypt.Ciphertext
+	GivenName   fieldcrypt.Ciphertext
+	FamilyName  fieldcrypt.Ciphertext
+	Active      bool
+	TenantID    int32
+	ID          int32
+}
+
+type UpdateScimUserRow struct {
+	ID          int32
+	ExternalID  *string
+	Username    string
+	DisplayName fieldcrypt.Ciphertext
+	GivenName   fieldcrypt.Ciphertext
+	FamilyName  fieldcrypt.Ciphertext
+	Email       fieldcrypt.Ciphertext
+	Active      bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// UpdateScimUser
+//
+//	UPDATE users
+//	SET username       = $1,
+//	    email          = $2,
+//	    email_index    = $3,
+//	    email_verified = $2 IS NOT NULL,
+//	    external_id    = $4,
+//	    display_name   = $5,
+//	    given_name     = $6,
+//	    family_name    = $7,
+//	    active         = $8,
+//	    updated_at     = now()
+//	WHERE tenant_id = $9
+//	  AND id = $10
+//	RETURNING id, external_id, username, display_name, given_name, family_name, email, active, created_at, updated_at
+func (q *Queries) UpdateScimUser(ctx context.Context, arg UpdateScimUserParams) (UpdateScimUserRow, error) {
+	row := q.db.QueryRow(ctx, UpdateScimUser,
+		arg.Username,
+		arg.Email,
+		arg.EmailIndex,
+		arg.ExternalID,
+		arg.DisplayName,
+		arg.GivenName,
+		arg.FamilyName,
+		arg.Active,
+		arg.TenantID,
+		arg.ID,
+	)
+	var i UpdateScimUserRow
+	err := row.Scan(
+		&i.ID,
+		&i.ExternalID,
+		&i.Username,
+		&i.DisplayName,
+		&i.GivenName,
+		&i.FamilyName,
+		&i.Email,
+		&i.Active,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const UpsertUserDevice = `-- name: UpsertUserDevice :one
+INSERT INTO user_devices (tenant_id, user_id, fingerprint, device_id, user_agent, ip_prefix, location_hint)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (user_id, fingerprint) DO UPDATE
+    SET user_agent    = EXCLUDED.user_agent,
+        ip_prefix     = EXCLUDED.ip_prefix,
+        location_hint = EXCLUDED.location_hint,
+        last_seen_at  = now()
+RETURNING id, first_seen_at, last_seen_at
+`
+
+type UpsertUserDeviceParams struct {
+	TenantID     int32
+	UserID       int32
+	Fingerprint  string
+	DeviceID     string
+	UserAgent    string
+	IpPrefix     string
+	LocationHint string
+}
+
+type UpsertUserDeviceRow struct {
+	ID          int32
+	FirstSeenAt time.Time
+	LastSeenAt  time.Time
+}
+
+// UpsertUserDevice
+//
+//	INSERT INTO user_devices (tenant_id, user_id, fingerprint, device_id, user_agent, ip_prefix, location_hint)
+//	VALUES ($1, $2, $3, $4, $5, $6, $7)
+//	ON CONFLICT (user_id, fingerprint) DO UPDATE
+//	    SET user_agent    = EXCLUDED.user_agent,
+//	        ip_prefix     = EXCLUDED.ip_prefix,
+//	        location_hint = EXCLUDED.location_hint,
+//	        last_seen_at  = now()
+//	RETURNING id, first_seen_at, last_seen_at
+func (q *Queries) UpsertUserDevice(ctx context.Context, arg UpsertUserDeviceParams) (UpsertUserDeviceRow, error) {
+	row := q.db.QueryRow(ctx, UpsertUserDevice,
+		arg.TenantID,
+		arg.UserID,
+		arg.Fingerprint,
+		arg.DeviceID,
+		arg.UserAgent,
+		arg.IpPrefix,
+		arg.LocationHint,
+	)
+	var i UpsertUserDeviceRow
+	err := row.Scan(&i.ID, &i.FirstSeenAt, &i.LastSeenAt)
+	return i, err
+}
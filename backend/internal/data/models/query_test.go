@@ -9,7 +9,10 @@ import (
 )
 
 func TestGetUserByName(t *testing.T) {
-	arg := "admin"
+	arg := GetUserByNameParams{
+		TenantID: 1,
+		Username: "admin",
+	}
 
 	result, err := testQueries.GetUserByName(context.Background(), arg)
 	assert.NoError(t, err)
@@ -0,0 +1,269 @@
+package data
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data/models"
+	"connect-go-example/internal/pkg/broker"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// OutboxRepo 发件箱数据访问接口
+type OutboxRepo interface {
+	// Append 写入事件，应在修改数据的同一事务中调用，事务回滚时事件一并丢弃
+	Append(ctx context.Context, event *model.DomainEvent) error
+	// ClaimRelay 必须在事务中调用，返回当前副本是否负责投递，事务结束前其他副本拿不到
+	ClaimRelay(ctx context.Context) (bool, error)
+	// ListPending 按 ID 顺序返回待投递的事件
+	ListPending(ctx context.Context, limit int) ([]*model.DomainEvent, error)
+	MarkPublished(ctx context.Context, ids []int64) error
+	// PurgePublished 删除 before 之前投递的事件
+	PurgePublished(ctx context.Context, before time.Time) (int64, error)
+}
+
+type outboxRepo struct {
+	queries *models.Queries
+	l       *zap.Logger
+}
+
+// NewOutboxRepo 本地开发驱动把事件保存在内存中，重启后未投递的事件会丢失
+func NewOutboxRepo(data *Data, logger *zap.Logger) OutboxRepo {
+	if data.driver != DriverPostgres {
+		return newMemoryOutbox()
+	}
+	return &outboxRepo{
+		queries: models.New(data.query),
+		l:       logger,
+	}
+}
+
+func (r *outboxRepo) Append(ctx context.Context, event *model.DomainEvent) error {
+	id, err := withTx(ctx, r.queries).AppendOutboxEvent(ctx, models.AppendOutboxEventParams{
+		TenantID:      int32(event.TenantID),
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		EventType:     event.Type,
+		Payload:       event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+	event.ID = id
+	return nil
+}
+
+func (r *outboxRepo) ClaimRelay(ctx context.Context) (bool, error) {
+	return withTx(ctx, r.queries).TryLockOutboxRelay(ctx)
+}
+
+func (r *outboxRepo) ListPending(ctx context.Context, limit int) ([]*model.DomainEvent, error) {
+	rows, err := withTx(ctx, r.queries).ListPendingOutboxEvents(ctx, int32(limit))
+	if err != nil {
+		return nil, err
+	}
+	events := make([]*model.DomainEvent, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, &model.DomainEvent{
+			ID:            row.ID,
+			TenantID:      int64(row.TenantID),
+			AggregateType: row.AggregateType,
+			AggregateID:   row.AggregateID,
+			Type:          row.EventType,
+			Payload:       row.Payload,
+			CreatedAt:     row.CreatedAt,
+		})
+	}
+	return events, nil
+}
+
+func (r *outboxRepo) MarkPublished(ctx context.Context, ids []int64) error {
+	return withTx(ctx, r.queries).MarkOutboxEventsPublished(ctx, ids)
+}
+
+func (r *outboxRepo) PurgePublished(ctx context.Context, before time.Time) (int64, error) {
+	return withTx(ctx, r.queries).PurgePublishedOutboxEvents(ctx, before)
+}
+
+// 投递任务的默认参数
+const (
+	outboxPublishTimeout = 10 * time.Second
+	outboxPurgeInterval  = time.Hour
+)
+
+// EventRelay 把发件箱中的事件投递到消息代理，并在同一事务中为 Webhook 订阅创建投递记录。
+// 事件投递成功后才标记，标记失败时会重复投递，消费方按事件ID去重；同一聚合的事件按 ID 顺序投递，前一个失败时后面的留到下一轮
+type EventRelay struct {
+	outbox       OutboxRepo
+	tx           Transactor
+	broker       broker.Broker
+	webhooks     WebhookRepo
+	batchSize    int
+	pollInterval time.Duration
+	retention    time.Duration
+	lastPurge    time.Time
+	l            *zap.Logger
+}
+
+// NewEventRelay 未配置消息代理时事件只投递给 Webhook
+func NewEventRelay(lc fx.Lifecycle, cfg *conf.Bootstrap, outbox OutboxRepo, tx Transactor, b broker.Broker, webhooks WebhookRepo, logger *zap.Logger) *EventRelay {
+	eventsCfg := cfg.GetEvents()
+	r := &EventRelay{
+		outbox:       outbox,
+		tx:           tx,
+		broker:       b,
+		webhooks:     webhooks,
+		batchSize:    100,                // 默认100
+		pollInterval: time.Second,        // 默认1秒
+		retention:    7 * 24 * time.Hour, // 默认7天
+		l:            logger,
+	}
+	if eventsCfg.GetBatchSize() > 0 {
+		r.batchSize = int(eventsCfg.GetBatchSize())
+	}
+	if eventsCfg.GetPollIntervalMs() > 0 {
+		r.pollInterval = time.Duration(eventsCfg.GetPollIntervalMs()) * time.Millisecond
+	}
+	if eventsCfg.GetRetentionHours() > 0 {
+		r.retention = time.Duration(eventsCfg.GetRetentionHours()) * time.Hour
+	}
+
+	if b == nil {
+		logger.Info("Event broker is not configured, domain events are only delivered to webhooks")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				r.run(ctx)
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	})
+	return r
+}
+
+func (r *EventRelay) run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		more, err := r.relay(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.l.Warn("Relay outbox events failed", zap.Error(err))
+		}
+		r.purge(ctx)
+
+		// 一批全部投递成功且可能还有剩余时立即继续
+		if more {
+			timer.Reset(0)
+		} else {
+			timer.Reset(r.pollInterval)
+		}
+	}
+}
+
+// relay 投递一批事件，返回是否应立即投递下一批
+func (r *EventRelay) relay(ctx context.Context) (bool, error) {
+	more := false
+	err := r.tx.WithinTx(ctx, func(ctx context.Context) error {
+		claimed, err := r.outbox.ClaimRelay(ctx)
+		if err != nil || !claimed {
+			return err
+		}
+		events, err := r.outbox.ListPending(ctx, r.batchSize)
+		if err != nil {
+			return err
+		}
+
+		failed := make(map[string]bool)
+		published := make([]int64, 0, len(events))
+		for _, event := range events {
+			aggregate := event.AggregateType + ":" + event.AggregateID
+			if failed[aggregate] {
+				continue
+			}
+			if err := r.publish(ctx, event); err != nil {
+				if ctx.Err() != nil {
+					break
+				}
+				failed[aggregate] = true
+				r.l.Warn("Publish domain event failed",
+					zap.Int64("event_id", event.ID), zap.String("event_type", event.Type), zap.Error(err))
+				continue
+			}
+			// 创建投递记录失败时事务已中止，整批回滚后重试，已创建的记录不会重复
+			if err := r.enqueueWebhooks(ctx, event); err != nil {
+				return err
+			}
+			published = append(published, event.ID)
+		}
+		more = len(events) == r.batchSize && len(failed) == 0
+		if len(published) == 0 {
+			return nil
+		}
+		return r.outbox.MarkPublished(ctx, published)
+	})
+	return more, err
+}
+
+func (r *EventRelay) publish(ctx context.Context, event *model.DomainEvent) error {
+	if r.broker == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, outboxPublishTimeout)
+	defer cancel()
+	return r.broker.Publish(ctx, &broker.Message{
+		ID:      strconv.FormatInt(event.ID, 10),
+		Key:     event.AggregateID,
+		Type:    event.Type,
+		Payload: event.Payload,
+		Headers: map[string]string{
+			"tenant_id":      strconv.FormatInt(event.TenantID, 10),
+			"aggregate_type": event.AggregateType,
+			"occurred_at":    event.CreatedAt.UTC().Format(time.RFC3339Nano),
+		},
+	})
+}
+
+func (r *EventRelay) enqueueWebhooks(ctx context.Context, event *model.DomainEvent) error {
+	body, err := webhookBody(event)
+	if err != nil {
+		return err
+	}
+	return r.webhooks.Enqueue(ctx, event, body)
+}
+
+// purge 定期删除保留期之前投递的事件
+func (r *EventRelay) purge(ctx context.Context) {
+	if time.Since(r.lastPurge) < outboxPurgeInterval {
+		return
+	}
+	r.lastPurge = time.Now()
+	n, err := r.outbox.PurgePublished(ctx, time.Now().Add(-r.retention))
+	if err != nil {
+		r.l.Warn("Purge published outbox events failed", zap.Error(err))
+		return
+	}
+	if n > 0 {
+		r.l.Info("Purged published outbox events", zap.Int64("count", n))
+	}
+}
@@ -0,0 +1,163 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	"connect-go-example/internal/pkg/broker"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// flakyBroker 发送到 down 中的键时失败，其余转发给 MemoryBroker
+type flakyBroker struct {
+	*broker.MemoryBroker
+	down map[string]bool
+}
+
+func (b *flakyBroker) Publish(ctx context.Context, msg *broker.Message) error {
+	if b.down[msg.Key] {
+		return errors.New("broker unavailable")
+	}
+	return b.MemoryBroker.Publish(ctx, msg)
+}
+
+func newTestRelay(batchSize int) (*EventRelay, *memoryOutbox, *flakyBroker) {
+	outbox := newMemoryOutbox()
+	b := &flakyBroker{MemoryBroker: broker.NewMemoryBroker(), down: make(map[string]bool)}
+	return &EventRelay{
+		outbox:    outbox,
+		tx:        memoryTransactor{},
+		broker:    b,
+		webhooks:  &fakeWebhookRepo{},
+		batchSize: batchSize,
+		retention: time.Hour,
+		l:         zap.NewNop(),
+	}, outbox, b
+}
+
+func appendUserEvent(t *testing.T, outbox OutboxRepo, userID int64, eventType string) {
+	assert.NoError(t, outbox.Append(context.Background(), &model.DomainEvent{
+		TenantID:      1,
+		AggregateType: model.AggregateUser,
+		AggregateID:   strconv.FormatInt(userID, 10),
+		Type:          eventType,
+		Payload:       []byte(`{}`),
+	}))
+}
+
+func publishedEvents(b *flakyBroker) []string {
+	var events []string
+	for _, msg := range b.Messages() {
+		events = append(events, msg.Key+":"+msg.Type)
+	}
+	return events
+}
+
+func TestEventRelay(t *testing.T) {
+	ctx := context.Background()
+	relay, outbox, b := newTestRelay(2)
+	appendUserEvent(t, outbox, 1, model.EventUserRegistered)
+	appendUserEvent(t, outbox, 1, model.EventUserLoggedIn)
+	appendUserEvent(t, outbox, 2, model.EventUserRegistered)
+
+	// 一批已满，还可能有剩余
+	more, err := relay.relay(ctx)
+	assert.NoError(t, err)
+	assert.True(t, more)
+	more, err = relay.relay(ctx)
+	assert.NoError(t, err)
+	assert.False(t, more)
+
+	assert.Equal(t, []string{"1:UserRegistered", "1:UserLoggedIn", "2:UserRegistered"}, publishedEvents(b))
+	msg := b.Messages()[0]
+	assert.Equal(t, "1", msg.ID)
+	assert.Equal(t, "1", msg.Headers["tenant_id"])
+	assert.Equal(t, model.AggregateUser, msg.Headers["aggregate_type"])
+
+	pending, err := outbox.ListPending(ctx, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestEventRelay_PreservesAggregateOrder(t *testing.T) {
+	ctx := context.Background()
+	relay, outbox, b := newTestRelay(10)
+	appendUserEvent(t, outbox, 1, model.EventUserRegistered)
+	appendUserEvent(t, outbox, 2, model.EventUserRegistered)
+	appendUserEvent(t, outbox, 1, model.EventUserDisabled)
+
+	// 用户1的第一个事件失败时，后面的事件也不投递，其他用户不受影响
+	b.down["1"] = true
+	more, err := relay.relay(ctx)
+	assert.NoError(t, err)
+	assert.False(t, more)
+	assert.Equal(t, []string{"2:UserRegistered"}, publishedEvents(b))
+
+	b.down["1"] = false
+	_, err = relay.relay(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2:UserRegistered", "1:UserRegistered", "1:UserDisabled"}, publishedEvents(b))
+}
+
+func TestEventRelay_Webhooks(t *testing.T) {
+	ctx := context.Background()
+	relay, outbox, b := newTestRelay(10)
+	webhooks := relay.webhooks.(*fakeWebhookRepo)
+	appendUserEvent(t, outbox, 1, model.EventUserRegistered)
+	appendUserEvent(t, outbox, 2, model.EventUserRegistered)
+
+	// 消息代理投递失败的事件不创建 Webhook 投递，下一轮再创建
+	b.down["2"] = true
+	_, err := relay.relay(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, webhooks.enqueued, 1)
+
+	var body map[string]any
+	assert.NoError(t, json.Unmarshal(webhooks.enqueued[0], &body))
+	assert.Equal(t, "1", body["id"])
+	assert.Equal(t, model.EventUserRegistered, body["type"])
+	assert.Equal(t, float64(1), body["tenantId"])
+	assert.Equal(t, map[string]any{}, body["data"])
+
+	// 未配置消息代理时只投递给 Webhook
+	relay.broker = nil
+	_, err = relay.relay(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, webhooks.enqueued, 2)
+	pending, _ := outbox.ListPending(ctx, 10)
+	assert.Empty(t, pending)
+
+	// 创建投递失败时整批不标记
+	appendUserEvent(t, outbox, 3, model.EventUserRegistered)
+	webhooks.err = errors.New("db down")
+	_, err = relay.relay(ctx)
+	assert.Error(t, err)
+	pending, _ = outbox.ListPending(ctx, 10)
+	assert.Len(t, pending, 1)
+}
+
+func TestEventRelay_Purge(t *testing.T) {
+	ctx := context.Background()
+	relay, outbox, _ := newTestRelay(10)
+	appendUserEvent(t, outbox, 1, model.EventUserRegistered)
+	appendUserEvent(t, outbox, 2, model.EventUserRegistered)
+	assert.NoError(t, outbox.MarkPublished(ctx, []int64{1}))
+
+	// 保留期内不删除
+	relay.purge(ctx)
+	assert.Len(t, outbox.events, 2)
+
+	relay.retention = 0
+	relay.lastPurge = time.Time{}
+	relay.purge(ctx)
+	assert.Len(t, outbox.events, 1)
+	pending, _ := outbox.ListPending(ctx, 10)
+	assert.Len(t, pending, 1)
+}
@@ -0,0 +1,73 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+
+	"go.uber.org/zap"
+)
+
+// PowRepo 工作量证明数据访问接口，事件计数按 ctx 中的租户区分
+type PowRepo interface {
+	// RecordPowEvent 记录一次事件，计数在窗口结束后清零
+	RecordPowEvent(ctx context.Context, event string, window time.Duration) error
+	CountPowEvent(ctx context.Context, event string) (int64, error)
+	// UsePowChallenge 标记题目已使用，返回是否为首次使用
+	UsePowChallenge(ctx context.Context, id string, ttl time.Duration) (bool, error)
+}
+
+// powRepo 计数和使用记录保存在 StateStore 中；存储不可用时 UsePowChallenge 返回错误，
+// 工作量证明被拒绝，事件计数失败由调用方只记录日志
+type powRepo struct {
+	states StateStore
+	l      *zap.Logger
+}
+
+func NewPowRepo(states StateStore, logger *zap.Logger) PowRepo {
+	return &powRepo{
+		states: states,
+		l:      logger,
+	}
+}
+
+func powEventKey(ctx context.Context, event string) (string, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("pow_event:%d:%s", tenantID, event), nil
+}
+
+func (r *powRepo) RecordPowEvent(ctx context.Context, event string, window time.Duration) error {
+	key, err := powEventKey(ctx, event)
+	if err != nil {
+		return err
+	}
+	_, err = r.states.Incr(ctx, key, window)
+	return err
+}
+
+func (r *powRepo) CountPowEvent(ctx context.Context, event string) (int64, error) {
+	key, err := powEventKey(ctx, event)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := r.states.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, ErrStateNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func (r *powRepo) UsePowChallenge(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	return r.states.SetNX(ctx, fmt.Sprintf("pow_used:%s", id), "1", ttl)
+}
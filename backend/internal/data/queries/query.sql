@@ -1,14 +1,550 @@
 -- name: InsertTestUser :one
-INSERT INTO users(username, password_hash, salt)
-VALUES ('admin', 'asdas', '123123')
+INSERT INTO users(tenant_id, username, password_hash, salt)
+VALUES (1, 'admin', 'asdas', '123123')
 RETURNING *;
 
 -- name: CreateUser :one
-INSERT INTO users (username, password_hash, salt)
-VALUES ($1, $2, $3)
-RETURNING id, username, password_hash, salt, created_at, updated_at;
+INSERT INTO users (tenant_id, username, password_hash, salt, email, email_index, kdf_version, email_verified)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, tenant_id, username, password_hash, salt, email, kdf_version, created_at, updated_at;
 
 -- name: GetUserByName :one
-SELECT username, salt, id, password_hash
+SELECT username, salt, id, password_hash, kdf_version, email, active, credential_backend, email_verified
+FROM users
+WHERE tenant_id = @tenant_id
+  AND username = @username;
+
+-- name: GetUserByEmail :one
+-- 尚未重新加密的旧数据没有盲索引，按迁移后的明文格式匹配
+SELECT username, salt, id, password_hash, email, kdf_version, active, email_verified
+FROM users
+WHERE tenant_id = @tenant_id
+  AND (email_index = @email_index OR (email_index IS NULL AND email = @legacy_email));
+
+-- name: MarkEmailVerified :exec
+-- 只有邮箱仍是验证时的地址才标记
+UPDATE users
+SET email_verified = true,
+    updated_at     = now()
+WHERE tenant_id = @tenant_id
+  AND id = @id
+  AND (email_index = @email_index OR (email_index IS NULL AND email = @legacy_email));
+
+-- name: GetTenantBySlug :one
+SELECT id, slug, name
+FROM tenants
+WHERE slug = @slug;
+
+-- name: GetTenantByClientID :one
+SELECT t.id, t.slug, t.name
+FROM tenants t
+         JOIN tenant_clients c ON c.tenant_id = t.id
+WHERE c.client_id = @client_id;
+
+-- name: GetUserRoles :many
+SELECT r.role
+FROM user_roles r
+         JOIN users u ON u.id = r.user_id
+WHERE u.tenant_id = @tenant_id
+  AND r.user_id = @user_id
+ORDER BY r.role;
+
+-- name: CreateInvite :one
+INSERT INTO invites (tenant_id, inviter_id, max_uses, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id, created_at;
+
+-- name: RedeemInvite :one
+-- 条件更新保证并发注册时不会超出可用次数
+UPDATE invites
+SET used_count = used_count + 1
+WHERE id = @id
+  AND tenant_id = @tenant_id
+  AND used_count < max_uses
+  AND expires_at > now()
+RETURNING id, inviter_id, max_uses, used_count, expires_at, created_at;
+
+-- name: CreateInviteRedemption :exec
+INSERT INTO invite_redemptions (invite_id, user_id)
+VALUES ($1, $2);
+
+-- name: ListInvites :many
+SELECT i.id,
+       i.inviter_id,
+       i.max_uses,
+       i.used_count,
+       i.expires_at,
+       i.created_at,
+       COALESCE(array_agg(r.user_id ORDER BY r.created_at) FILTER (WHERE r.user_id IS NOT NULL), '{}')::INTEGER[] AS invitee_ids
+FROM invites i
+         LEFT JOIN invite_redemptions r ON r.invite_id = i.id
+WHERE i.tenant_id = @tenant_id
+GROUP BY i.id
+ORDER BY i.id DESC;
+
+-- name: UpdateCredential :exec
+UPDATE users
+SET password_hash = @password_hash,
+    salt          = @salt,
+    kdf_version   = @kdf_version,
+    updated_at    = now()
+WHERE tenant_id = @tenant_id
+  AND id = @id;
+
+-- name: SetCredentialBackend :execrows
+-- credential_backend 为 NULL 时使用租户默认后端
+UPDATE users
+SET credential_backend = @credential_backend,
+    updated_at         = now()
+WHERE tenant_id = @tenant_id
+  AND username = @username;
+
+-- name: ListUserDevices :many
+SELECT id, fingerprint, device_id, user_agent, ip_prefix, location_hint, first_seen_at, last_seen_at
+FROM user_devices
+WHERE tenant_id = @tenant_id
+  AND user_id = @user_id
+ORDER BY last_seen_at DESC;
+
+-- name: UpsertUserDevice :one
+INSERT INTO user_devices (tenant_id, user_id, fingerprint, device_id, user_agent, ip_prefix, location_hint)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (user_id, fingerprint) DO UPDATE
+    SET user_agent    = EXCLUDED.user_agent,
+        ip_prefix     = EXCLUDED.ip_prefix,
+        location_hint = EXCLUDED.location_hint,
+        last_seen_at  = now()
+RETURNING id, first_seen_at, last_seen_at;
+
+-- name: CreateLoginEvent :exec
+INSERT INTO login_events (tenant_id, user_id, fingerprint, ip_prefix, risk_score, reasons, step_up)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: ListLoginEvents :many
+SELECT fingerprint, ip_prefix, risk_score, reasons, step_up, created_at
+FROM login_events
+WHERE tenant_id = @tenant_id
+  AND user_id = @user_id
+  AND created_at > @since
+ORDER BY created_at DESC
+LIMIT 500;
+
+-- name: CreateScimUser :one
+-- SCIM 下发的用户没有凭证，password_hash 为空时无法通过凭证登录；IdP 下发的邮箱视为已验证
+INSERT INTO users (tenant_id, username, password_hash, salt, email, email_index, external_id, display_name, given_name, family_name, active, email_verified)
+VALUES ($1, $2, '', '', $3, $4, $5, $6, $7, $8, $9, $3 IS NOT NULL)
+RETURNING id, external_id, username, display_name, given_name, family_name, email, active, created_at, updated_at;
+
+-- name: GetScimUser :one
+SELECT id, external_id, username, display_name, given_name, family_name, email, active, created_at, updated_at
+FROM users
+WHERE tenant_id = @tenant_id
+  AND id = @id;
+
+-- name: UpdateScimUser :one
+UPDATE users
+SET username       = @username,
+    email          = @email,
+    email_index    = @email_index,
+    email_verified = @email IS NOT NULL,
+    external_id    = @external_id,
+    display_name   = @display_name,
+    given_name     = @given_name,
+    family_name    = @family_name,
+    active         = @active,
+    updated_at     = now()
+WHERE tenant_id = @tenant_id
+  AND id = @id
+RETURNING id, external_id, username, display_name, given_name, family_name, email, active, created_at, updated_at;
+
+-- name: DeleteUser :execrows
+DELETE
+FROM users
+WHERE tenant_id = @tenant_id
+  AND id = @id;
+
+-- name: ListScimUsers :many
+-- userName、externalId 的过滤条件为 LIKE 模式，userName 不区分大小写；邮箱已加密，只能按盲索引精确匹配；为空时不过滤
+SELECT id, external_id, username, display_name, given_name, family_name, email, active, created_at, updated_at
+FROM users
+WHERE tenant_id = @tenant_id
+  AND (sqlc.narg(user_name)::TEXT IS NULL OR lower(username) LIKE lower(sqlc.narg(user_name)))
+  AND (sqlc.narg(external_id)::TEXT IS NULL OR external_id LIKE sqlc.narg(external_id))
+  AND (sqlc.narg(email_index)::BYTEA IS NULL OR email_index = sqlc.narg(email_index) OR
+       (email_index IS NULL AND email = sqlc.narg(legacy_email)::BYTEA))
+  AND (NOT @has_email::BOOLEAN OR email IS NOT NULL)
+ORDER BY id
+LIMIT @max_results OFFSET @skip;
+
+-- name: CountScimUsers :one
+SELECT count(*)
 FROM users
-WHERE username = @username;
+WHERE tenant_id = @tenant_id
+  AND (sqlc.narg(user_name)::TEXT IS NULL OR lower(username) LIKE lower(sqlc.narg(user_name)))
+  AND (sqlc.narg(external_id)::TEXT IS NULL OR external_id LIKE sqlc.narg(external_id))
+  AND (sqlc.narg(email_index)::BYTEA IS NULL OR email_index = sqlc.narg(email_index) OR
+       (email_index IS NULL AND email = sqlc.narg(legacy_email)::BYTEA))
+  AND (NOT @has_email::BOOLEAN OR email IS NOT NULL);
+
+-- name: CreateGroup :one
+INSERT INTO user_groups (tenant_id, display_name, external_id)
+VALUES ($1, $2, $3)
+RETURNING id, display_name, external_id, created_at, updated_at;
+
+-- name: GetGroup :one
+SELECT id, display_name, external_id, created_at, updated_at
+FROM user_groups
+WHERE tenant_id = @tenant_id
+  AND id = @id;
+
+-- name: UpdateGroup :one
+UPDATE user_groups
+SET display_name = @display_name,
+    external_id  = @external_id,
+    updated_at   = now()
+WHERE tenant_id = @tenant_id
+  AND id = @id
+RETURNING id, display_name, external_id, created_at, updated_at;
+
+-- name: DeleteGroup :execrows
+DELETE
+FROM user_groups
+WHERE tenant_id = @tenant_id
+  AND id = @id;
+
+-- name: ListGroups :many
+SELECT id, display_name, external_id, created_at, updated_at
+FROM user_groups
+WHERE tenant_id = @tenant_id
+  AND (sqlc.narg(display_name)::TEXT IS NULL OR lower(display_name) LIKE lower(sqlc.narg(display_name)))
+  AND (sqlc.narg(external_id)::TEXT IS NULL OR external_id LIKE sqlc.narg(external_id))
+ORDER BY id
+LIMIT @max_results OFFSET @skip;
+
+-- name: CountGroups :one
+SELECT count(*)
+FROM user_groups
+WHERE tenant_id = @tenant_id
+  AND (sqlc.narg(display_name)::TEXT IS NULL OR lower(display_name) LIKE lower(sqlc.narg(display_name)))
+  AND (sqlc.narg(external_id)::TEXT IS NULL OR external_id LIKE sqlc.narg(external_id));
+
+-- name: ListGroupMembers :many
+SELECT m.group_id, u.id AS user_id, u.username
+FROM group_members m
+         JOIN users u ON u.id = m.user_id
+WHERE u.tenant_id = @tenant_id
+  AND m.group_id = ANY (@group_ids::INTEGER[])
+ORDER BY m.group_id, u.id;
+
+-- name: AddGroupMembers :exec
+-- 只添加同一租户内存在的用户
+INSERT INTO group_members (group_id, user_id)
+SELECT @group_id, u.id
+FROM users u
+WHERE u.tenant_id = @tenant_id
+  AND u.id = ANY (@user_ids::INTEGER[])
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveGroupMembers :exec
+DELETE
+FROM group_members
+WHERE group_id = @group_id
+  AND user_id = ANY (@user_ids::INTEGER[]);
+
+-- name: ClearGroupMembers :exec
+DELETE
+FROM group_members
+WHERE group_id = @group_id;
+
+-- name: GetUserIdentity :one
+SELECT i.user_id, u.username, u.active
+FROM user_identities i
+         JOIN users u ON u.id = i.user_id
+WHERE i.tenant_id = @tenant_id
+  AND i.provider = @provider
+  AND i.subject = @subject;
+
+-- name: CreateUserIdentity :exec
+INSERT INTO user_identities (tenant_id, user_id, provider, subject, email)
+VALUES (@tenant_id, @user_id, @provider, @subject, @email);
+
+-- name: CreateFederatedUser :one
+-- 同一语句中创建用户和身份关联，任一冲突时都不会留下没有关联的用户；两个表的邮箱密文不同
+WITH new_user AS (
+    INSERT INTO users (tenant_id, username, password_hash, salt, email, email_index, display_name, email_verified)
+        VALUES (@tenant_id, @username, '', '', @email, @email_index, @display_name, @email IS NOT NULL)
+        RETURNING id, tenant_id)
+INSERT
+INTO user_identities (tenant_id, user_id, provider, subject, email)
+SELECT tenant_id, id, @provider, @subject, sqlc.narg(identity_email)::BYTEA
+FROM new_user
+RETURNING user_id;
+
+-- name: TouchUserIdentity :exec
+UPDATE user_identities
+SET email         = @email,
+    last_login_at = now()
+WHERE tenant_id = @tenant_id
+  AND provider = @provider
+  AND subject = @subject;
+
+-- name: SyncDirectoryUser :one
+-- 目录服务登录成功后同步属性，首次登录时创建没有本地凭证的用户；已有用户不修改 credential_backend。
+-- 已有用户属于其他后端（包括有本地凭证、未指定后端的用户）时不更新，返回空结果。
+-- 目录服务返回的邮箱视为已验证
+INSERT INTO users (tenant_id, username, password_hash, salt, email, email_index, display_name, given_name, family_name, credential_backend, email_verified)
+VALUES (@tenant_id, @username, '', '', @email, @email_index, @display_name, @given_name, @family_name, @credential_backend, @email IS NOT NULL)
+ON CONFLICT (tenant_id, username) DO UPDATE
+    SET email          = COALESCE(EXCLUDED.email, users.email),
+        email_index    = CASE WHEN EXCLUDED.email IS NULL THEN users.email_index ELSE EXCLUDED.email_index END,
+        email_verified = EXCLUDED.email_verified OR users.email_verified,
+        display_name   = EXCLUDED.display_name,
+        given_name     = EXCLUDED.given_name,
+        family_name    = EXCLUDED.family_name,
+        updated_at     = now()
+WHERE users.credential_backend = EXCLUDED.credential_backend
+   OR (users.credential_backend IS NULL AND users.password_hash = '')
+RETURNING id, active;
+
+-- name: SetStateEntry :exec
+INSERT INTO state_entries (key, value, expires_at)
+VALUES (@key, @value, @expires_at)
+ON CONFLICT (key) DO UPDATE
+    SET value      = EXCLUDED.value,
+        expires_at = EXCLUDED.expires_at;
+
+-- name: SetStateEntryNX :execrows
+-- 键不存在或已过期时写入，影响行数为0说明键仍然有效
+INSERT INTO state_entries (key, value, expires_at)
+VALUES (@key, @value, @expires_at)
+ON CONFLICT (key) DO UPDATE
+    SET value      = EXCLUDED.value,
+        expires_at = EXCLUDED.expires_at
+WHERE state_entries.expires_at <= now();
+
+-- name: IncrStateEntry :one
+-- 过期的计数从1重新开始，过期时间只在计数开始时设置
+INSERT INTO state_entries (key, value, expires_at)
+VALUES (@key, '1', @expires_at)
+ON CONFLICT (key) DO UPDATE
+    SET value      = CASE
+                         WHEN state_entries.expires_at > now() THEN (state_entries.value::bigint + 1)::text
+                         ELSE '1' END,
+        expires_at = CASE
+                         WHEN state_entries.expires_at > now() THEN state_entries.expires_at
+                         ELSE EXCLUDED.expires_at END
+RETURNING value::bigint;
+
+-- name: GetStateEntry :one
+-- state_entries 是 UNLOGGED 表，只读副本上没有数据，调用方必须使用主库连接
+SELECT value
+FROM state_entries
+WHERE key = @key
+  AND expires_at > now();
+
+-- name: TakeStateEntry :one
+DELETE
+FROM state_entries
+WHERE key = @key
+  AND expires_at > now()
+RETURNING value;
+
+-- name: DeleteStateEntry :exec
+DELETE
+FROM state_entries
+WHERE key = @key;
+
+-- name: PurgeStateEntries :exec
+DELETE
+FROM state_entries
+WHERE expires_at <= now();
+
+-- name: AppendOutboxEvent :one
+-- 先按聚合加事务级咨询锁再分配 id，同一聚合的事件按提交顺序得到递增的 id
+WITH aggregate_lock AS (SELECT pg_advisory_xact_lock(hashtextextended(@aggregate_type::text || ':' || @aggregate_id::text, 0)))
+INSERT
+INTO outbox (tenant_id, aggregate_type, aggregate_id, event_type, payload)
+SELECT @tenant_id, @aggregate_type, @aggregate_id, @event_type, @payload
+FROM aggregate_lock
+RETURNING id;
+
+-- name: TryLockOutboxRelay :one
+-- 多副本时只有持有锁的副本投递，锁在事务结束时释放
+SELECT pg_try_advisory_xact_lock(hashtextextended('outbox_relay', 0));
+
+-- name: ListPendingOutboxEvents :many
+SELECT id, tenant_id, aggregate_type, aggregate_id, event_type, payload, created_at
+FROM outbox
+WHERE published_at IS NULL
+ORDER BY id
+LIMIT @batch_size;
+
+-- name: MarkOutboxEventsPublished :exec
+UPDATE outbox
+SET published_at = now()
+WHERE id = ANY (@ids::bigint[]);
+
+-- name: PurgePublishedOutboxEvents :execrows
+DELETE
+FROM outbox
+WHERE published_at < @before::timestamptz;
+
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (tenant_id, url, events, secret)
+VALUES (@tenant_id, @url, @events, @secret)
+RETURNING id, created_at;
+
+-- name: ListWebhookSubscriptions :many
+SELECT id, url, events, created_at
+FROM webhook_subscriptions
+WHERE tenant_id = @tenant_id
+ORDER BY id;
+
+-- name: DeleteWebhookSubscription :execrows
+DELETE
+FROM webhook_subscriptions
+WHERE id = @id
+  AND tenant_id = @tenant_id;
+
+-- name: EnqueueWebhookDeliveries :execrows
+-- 为订阅了该事件的每个订阅创建一条投递记录，重复投递的事件不会重复创建
+INSERT
+INTO webhook_deliveries (tenant_id, subscription_id, event_id, event_type, payload)
+SELECT s.tenant_id, s.id, @event_id, @event_type, @payload
+FROM webhook_subscriptions s
+WHERE s.tenant_id = @tenant_id
+  AND @event_type::text = ANY (s.events)
+ON CONFLICT (subscription_id, event_id) DO NOTHING;
+
+-- name: ClaimWebhookDeliveries :many
+-- 取出到期的投递并把 next_attempt_at 推迟到租约结束，投递中的副本崩溃后由其他副本在租约到期后重试
+WITH due AS (SELECT d.id
+             FROM webhook_deliveries d
+             WHERE d.status = 'pending'
+               AND d.next_attempt_at <= now()
+             ORDER BY d.next_attempt_at
+             LIMIT @batch_size FOR UPDATE SKIP LOCKED)
+UPDATE webhook_deliveries d
+SET next_attempt_at = now() + make_interval(secs => @lease_seconds::float8)
+FROM due,
+     webhook_subscriptions s
+WHERE d.id = due.id
+  AND s.id = d.subscription_id
+RETURNING d.id, d.tenant_id, d.subscription_id, d.event_id, d.event_type, d.payload, d.attempts, d.created_at, s.url, s.secret;
+
+-- name: RecordWebhookAttempt :exec
+UPDATE webhook_deliveries
+SET status          = @status,
+    attempts        = attempts + 1,
+    next_attempt_at = @next_attempt_at,
+    last_attempt_at = now(),
+    response_status = @response_status,
+    last_error      = @last_error,
+    delivered_at    = CASE WHEN @status = 'succeeded' THEN now() END
+WHERE id = @id;
+
+-- name: ListWebhookDeliveries :many
+SELECT id,
+       subscription_id,
+       event_id,
+       event_type,
+       status,
+       attempts,
+       next_attempt_at,
+       last_attempt_at,
+       response_status,
+       last_error,
+       delivered_at,
+       created_at
+FROM webhook_deliveries
+WHERE tenant_id = @tenant_id
+  AND subscription_id = @subscription_id
+ORDER BY id DESC
+LIMIT @max_results;
+
+-- name: RedeliverWebhookDelivery :one
+-- 重新投递从第一次尝试开始计算退避，已成功的投递也可以重新投递
+UPDATE webhook_deliveries
+SET status          = 'pending',
+    attempts        = 0,
+    next_attempt_at = now(),
+    delivered_at    = NULL
+WHERE id = @id
+  AND tenant_id = @tenant_id
+RETURNING id,
+    subscription_id,
+    event_id,
+    event_type,
+    status,
+    attempts,
+    next_attempt_at,
+    last_attempt_at,
+    response_status,
+    last_error,
+    delivered_at,
+    created_at;
+
+-- name: LockDataKeys :exec
+-- 创建、轮换和重新加密数据密钥前加锁，多副本同时启动时只有一个创建密钥
+SELECT pg_advisory_xact_lock(hashtextextended('data_keys', 0));
+
+-- name: ListDataKeys :many
+SELECT id, purpose, kek_id, wrapped_key, created_at
+FROM data_keys
+ORDER BY id;
+
+-- name: CreateDataKey :one
+INSERT INTO data_keys (purpose, kek_id, wrapped_key)
+VALUES ($1, $2, $3)
+RETURNING id, created_at;
+
+-- name: RewrapDataKey :exec
+UPDATE data_keys
+SET kek_id      = @kek_id,
+    wrapped_key = @wrapped_key
+WHERE id = @id;
+
+-- name: ListUserFields :many
+-- 按 ID 分批读取加密字段，由重新加密任务检查是否需要重新加密
+SELECT id, tenant_id, email, email_index, display_name, given_name, family_name
+FROM users
+WHERE id > @after_id
+ORDER BY id
+LIMIT @batch_size;
+
+-- name: ListUsersWithoutEmailIndex :many
+-- 迁移前的明文邮箱还没有盲索引，启动时补齐后才对外服务
+SELECT id, tenant_id, email, email_index, display_name, given_name, family_name
+FROM users
+WHERE id > @after_id
+  AND email IS NOT NULL
+  AND email_index IS NULL
+ORDER BY id
+LIMIT @batch_size;
+
+-- name: ReencryptUserFields :execrows
+-- 字段在读取后被修改时不更新，由下一轮处理
+UPDATE users
+SET email        = @email,
+    email_index  = @email_index,
+    display_name = @display_name,
+    given_name   = @given_name,
+    family_name  = @family_name
+WHERE id = @id
+  AND email IS NOT DISTINCT FROM sqlc.narg(old_email)::BYTEA
+  AND display_name IS NOT DISTINCT FROM sqlc.narg(old_display_name)::BYTEA
+  AND given_name IS NOT DISTINCT FROM sqlc.narg(old_given_name)::BYTEA
+  AND family_name IS NOT DISTINCT FROM sqlc.narg(old_family_name)::BYTEA;
+
+-- name: ListIdentityEmails :many
+SELECT id, tenant_id, email
+FROM user_identities
+WHERE id > @after_id
+ORDER BY id
+LIMIT @batch_size;
+
+-- name: ReencryptIdentityEmail :execrows
+UPDATE user_identities
+SET email = @email
+WHERE id = @id
+  AND email IS NOT DISTINCT FROM sqlc.narg(old_email)::BYTEA;
@@ -0,0 +1,181 @@
+package data
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	conf "connect-go-example/internal/conf/v1"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// data.redis.mode 的取值
+const (
+	RedisModeSingle   = "single"
+	RedisModeSentinel = "sentinel"
+	RedisModeCluster  = "cluster"
+)
+
+// newRedisClient 按部署模式创建客户端，客户端在首次使用时才建立连接
+func newRedisClient(redisCfg *conf.Data_Redis) (redis.UniversalClient, error) {
+	addrs := redisCfg.GetAddrs()
+	if len(addrs) == 0 {
+		addrs = []string{fmt.Sprintf("%s:%d", redisCfg.GetHost(), redisCfg.GetPort())}
+	}
+	tlsConfig, err := redisTLSConfig(redisCfg.GetTls())
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &redis.UniversalOptions{
+		Addrs:            addrs,
+		MasterName:       redisCfg.GetMasterName(),
+		Username:         redisCfg.GetUsername(),
+		Password:         redisCfg.GetPassword(),
+		SentinelPassword: redisCfg.GetSentinelPassword(),
+		DB:               int(redisCfg.GetDb()),
+		DialTimeout:      time.Duration(redisCfg.GetDialTimeout()) * time.Second,
+		ReadTimeout:      time.Duration(redisCfg.GetReadTimeout()) * time.Second,
+		WriteTimeout:     time.Duration(redisCfg.GetWriteTimeout()) * time.Second,
+		PoolSize:         int(redisCfg.GetPoolSize()),
+		MinIdleConns:     int(redisCfg.GetMinIdleConns()),
+		TLSConfig:        tlsConfig,
+	}
+
+	var rdb redis.UniversalClient
+	switch redisCfg.GetMode() {
+	case "", RedisModeSingle:
+		if len(addrs) > 1 {
+			return nil, errors.New("redis single mode accepts only one address")
+		}
+		rdb = redis.NewClient(opts.Simple())
+	case RedisModeSentinel:
+		if opts.MasterName == "" {
+			return nil, errors.New("redis master_name is required in sentinel mode")
+		}
+		rdb = redis.NewFailoverClient(opts.Failover())
+	case RedisModeCluster:
+		// 集群只有 0 号库
+		if opts.DB != 0 {
+			return nil, errors.New("redis db must be 0 in cluster mode")
+		}
+		rdb = redis.NewClusterClient(opts.Cluster())
+	default:
+		return nil, fmt.Errorf("unknown redis mode %q", redisCfg.GetMode())
+	}
+
+	if prefix := redisCfg.GetKeyPrefix(); prefix != "" {
+		rdb.AddHook(keyPrefixHook(prefix))
+	}
+	return rdb, nil
+}
+
+// redisTLSConfig 未启用 TLS 时返回 nil
+func redisTLSConfig(tlsCfg *conf.Data_RedisTLS) (*tls.Config, error) {
+	if !tlsCfg.GetEnabled() {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         tlsCfg.GetServerName(),
+		InsecureSkipVerify: tlsCfg.GetInsecureSkipVerify(),
+	}
+	if tlsCfg.GetCaFile() != "" {
+		pem, err := os.ReadFile(tlsCfg.GetCaFile())
+		if err != nil {
+			return nil, fmt.Errorf("read redis ca file failed: %v", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in redis ca file %s", tlsCfg.GetCaFile())
+		}
+	}
+	if tlsCfg.GetCertFile() != "" || tlsCfg.GetKeyFile() != "" {
+		cert, err := tls.LoadX509KeyPair(tlsCfg.GetCertFile(), tlsCfg.GetKeyFile())
+		if err != nil {
+			return nil, fmt.Errorf("load redis client certificate failed: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// keyPrefixHook 在命令发出前给键加上前缀，仓库中的键名不需要感知前缀。
+// pub/sub 频道不是键，Subscribe 也不经过 hook，因此频道不加前缀
+type keyPrefixHook string
+
+func (h keyPrefixHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h keyPrefixHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		h.prefix(cmd)
+		return next(ctx, cmd)
+	}
+}
+
+func (h keyPrefixHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			h.prefix(cmd)
+		}
+		return next(ctx, cmds)
+	}
+}
+
+func (h keyPrefixHook) prefix(cmd redis.Cmder) {
+	args := cmd.Args()
+	for _, i := range keyPositions(args) {
+		if key, ok := args[i].(string); ok {
+			args[i] = string(h) + key
+		}
+	}
+}
+
+// keyPositions 返回命令参数中键的位置，未列出的命令只有第一个参数是键
+func keyPositions(args []any) []int {
+	if len(args) < 2 {
+		return nil
+	}
+	name, _ := args[0].(string)
+	switch strings.ToLower(name) {
+	case "ping", "echo", "auth", "hello", "select", "client", "info", "command", "config", "time",
+		"multi", "exec", "discard", "unwatch", "script", "function", "cluster", "readonly", "readwrite",
+		"publish", "spublish", "subscribe", "ssubscribe", "psubscribe", "unsubscribe", "sunsubscribe", "punsubscribe", "pubsub":
+		return nil
+	case "del", "unlink", "exists", "touch", "mget", "watch", "sinter", "sunion", "sdiff":
+		return span(1, len(args), 1)
+	case "mset", "msetnx":
+		return span(1, len(args), 2)
+	case "rename", "renamenx", "copy", "smove", "lmove", "rpoplpush":
+		return span(1, min(3, len(args)), 1)
+	case "eval", "evalsha", "eval_ro", "evalsha_ro", "fcall", "fcall_ro":
+		// EVAL script numkeys key [key ...] arg [arg ...]
+		if len(args) < 3 {
+			return nil
+		}
+		numKeys, err := strconv.Atoi(fmt.Sprint(args[2]))
+		if err != nil {
+			return nil
+		}
+		return span(3, min(3+numKeys, len(args)), 1)
+	}
+	return []int{1}
+}
+
+func span(from, to, step int) []int {
+	var positions []int
+	for i := from; i < to; i += step {
+		positions = append(positions, i)
+	}
+	return positions
+}
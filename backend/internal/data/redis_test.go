@@ -0,0 +1,88 @@
+package data
+
+import (
+	"context"
+	"testing"
+
+	conf "connect-go-example/internal/conf/v1"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRedisClient_Mode(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *conf.Data_Redis
+		want any
+	}{
+		{"default", &conf.Data_Redis{Host: "localhost", Port: 6379}, &redis.Client{}},
+		{"single", &conf.Data_Redis{Mode: RedisModeSingle, Addrs: []string{"localhost:6379"}}, &redis.Client{}},
+		{"sentinel", &conf.Data_Redis{Mode: RedisModeSentinel, MasterName: "mymaster", Addrs: []string{"s1:26379", "s2:26379"}}, &redis.Client{}},
+		{"cluster", &conf.Data_Redis{Mode: RedisModeCluster, Addrs: []string{"n1:6379", "n2:6379"}}, &redis.ClusterClient{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rdb, err := newRedisClient(tt.cfg)
+			assert.NoError(t, err)
+			assert.IsType(t, tt.want, rdb)
+			assert.NoError(t, rdb.Close())
+		})
+	}
+}
+
+func TestNewRedisClient_InvalidConfig(t *testing.T) {
+	for name, cfg := range map[string]*conf.Data_Redis{
+		"unknown mode":        {Mode: "replica"},
+		"single many addrs":   {Addrs: []string{"n1:6379", "n2:6379"}},
+		"sentinel no master":  {Mode: RedisModeSentinel, Addrs: []string{"s1:26379"}},
+		"cluster db":          {Mode: RedisModeCluster, Addrs: []string{"n1:6379"}, Db: 1},
+		"missing ca file":     {Tls: &conf.Data_RedisTLS{Enabled: true, CaFile: "testdata/missing.pem"}},
+		"missing client cert": {Tls: &conf.Data_RedisTLS{Enabled: true, CertFile: "testdata/missing.pem"}},
+	} {
+		_, err := newRedisClient(cfg)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestRedisTLSConfig(t *testing.T) {
+	config, err := redisTLSConfig(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, config)
+
+	config, err = redisTLSConfig(&conf.Data_RedisTLS{Enabled: true, ServerName: "redis.example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "redis.example.com", config.ServerName)
+}
+
+func TestKeyPrefixHook(t *testing.T) {
+	hook := keyPrefixHook("staging:")
+	process := hook.ProcessHook(func(context.Context, redis.Cmder) error { return nil })
+	run := func(args ...any) []any {
+		cmd := redis.NewCmd(context.Background(), args...)
+		assert.NoError(t, process(context.Background(), cmd))
+		return cmd.Args()
+	}
+
+	assert.Equal(t, []any{"get", "staging:k"}, run("get", "k"))
+	assert.Equal(t, []any{"set", "staging:k", "v", "ex", 10}, run("set", "k", "v", "ex", 10))
+	assert.Equal(t, []any{"del", "staging:a", "staging:b"}, run("del", "a", "b"))
+	assert.Equal(t, []any{"mset", "staging:a", "1", "staging:b", "2"}, run("mset", "a", "1", "b", "2"))
+	assert.Equal(t, []any{"evalsha", "sha", 1, "staging:k", "arg"}, run("evalsha", "sha", 1, "k", "arg"))
+	// 频道和无键命令不加前缀
+	assert.Equal(t, []any{"publish", "events", "1"}, run("publish", "events", "1"))
+	assert.Equal(t, []any{"ping"}, run("ping"))
+
+	// 管道中的每个命令都加前缀
+	pipeline := hook.ProcessPipelineHook(func(context.Context, []redis.Cmder) error { return nil })
+	cmds := []redis.Cmder{
+		redis.NewCmd(context.Background(), "multi"),
+		redis.NewCmd(context.Background(), "incr", "k"),
+		redis.NewCmd(context.Background(), "expire", "k", 60, "nx"),
+		redis.NewCmd(context.Background(), "exec"),
+	}
+	assert.NoError(t, pipeline(context.Background(), cmds))
+	assert.Equal(t, []any{"incr", "staging:k"}, cmds[1].Args())
+	assert.Equal(t, []any{"expire", "staging:k", 60, "nx"}, cmds[2].Args())
+	assert.Equal(t, []any{"exec"}, cmds[3].Args())
+}
@@ -0,0 +1,215 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// defaultReplicaCheckInterval 副本健康检查的默认间隔
+const defaultReplicaCheckInterval = 10 * time.Second
+
+var (
+	_ models.DBTX = (*DBRouter)(nil)
+
+	lockingClause = regexp.MustCompile(`(?i)\bFOR\s+(NO\s+KEY\s+)?(UPDATE|SHARE|KEY\s+SHARE)\b`)
+)
+
+// DBRouter 把只读的 sqlc 查询发送到健康的只读副本，其余语句、没有可用副本以及 ctx 要求读主库时发送到主库
+type DBRouter struct {
+	primary  *pgxpool.Pool
+	replicas []*replica
+	next     atomic.Uint64
+	l        *zap.Logger
+}
+
+type replica struct {
+	name    string
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+}
+
+// NewDBRouter 连接配置的只读副本，副本不可用不影响启动，恢复后由健康检查重新启用
+func NewDBRouter(lc fx.Lifecycle, primary *pgxpool.Pool, cfg *conf.Bootstrap, logger *zap.Logger) (*DBRouter, error) {
+	dbCfg := cfg.Data.Database
+	r := &DBRouter{primary: primary, l: logger}
+	if driverOf(cfg) != DriverPostgres {
+		return r, nil
+	}
+	for i, replicaCfg := range dbCfg.Replicas {
+		pool, err := newPool(replicaConnString(dbCfg, replicaCfg), dbCfg.Pool)
+		if err != nil {
+			r.close()
+			return nil, fmt.Errorf("replica %d: %v", i, err)
+		}
+		connCfg := pool.Config().ConnConfig
+		r.replicas = append(r.replicas, &replica{name: net.JoinHostPort(connCfg.Host, strconv.Itoa(int(connCfg.Port))), pool: pool})
+	}
+	if len(r.replicas) == 0 {
+		return r, nil
+	}
+
+	interval := defaultReplicaCheckInterval
+	if dbCfg.ReplicaCheckInterval > 0 {
+		interval = time.Duration(dbCfg.ReplicaCheckInterval) * time.Second
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			// 首次检查通过前只读查询也发送到主库，不可达的副本不会拖慢启动
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				r.checkReplicas(ctx, interval)
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						r.checkReplicas(ctx, interval)
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			wg.Wait()
+			logger.Info("Closing database replica connections...")
+			r.close()
+			return nil
+		},
+	})
+	return r, nil
+}
+
+func (r *DBRouter) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return r.primary.Exec(ctx, sql, args...)
+}
+
+// primaryKey 标记 ctx 中的只读查询也发送到主库
+type primaryKey struct{}
+
+// WithPrimary 返回的 ctx 中的查询都发送到主库。副本存在复制延迟，写入后立即读取（读己之写）时使用
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func (r *DBRouter) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	rep := r.route(ctx, sql)
+	if rep == nil {
+		return r.primary.Query(ctx, sql, args...)
+	}
+	rows, err := rep.pool.Query(ctx, sql, args...)
+	if err != nil && r.failover(rep, err) {
+		return r.primary.Query(ctx, sql, args...)
+	}
+	return rows, err
+}
+
+func (r *DBRouter) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	rep := r.route(ctx, sql)
+	if rep == nil {
+		return r.primary.QueryRow(ctx, sql, args...)
+	}
+	return &fallbackRow{row: rep.pool.QueryRow(ctx, sql, args...), fallback: func(err error) pgx.Row {
+		if !r.failover(rep, err) {
+			return nil
+		}
+		return r.primary.QueryRow(ctx, sql, args...)
+	}}
+}
+
+// route 返回执行只读查询的副本，返回 nil 时使用主库
+func (r *DBRouter) route(ctx context.Context, sql string) *replica {
+	if len(r.replicas) == 0 || ctx.Value(primaryKey{}) != nil || !isReadOnlyQuery(sql) {
+		return nil
+	}
+	start := r.next.Add(1)
+	for i := range r.replicas {
+		rep := r.replicas[(start+uint64(i))%uint64(len(r.replicas))]
+		if rep.healthy.Load() {
+			return rep
+		}
+	}
+	return nil
+}
+
+// failover 副本连接失败且语句未发送到服务器时标记副本不可用，改用主库重试
+func (r *DBRouter) failover(rep *replica, err error) bool {
+	if !pgconn.SafeToRetry(err) {
+		return false
+	}
+	if rep.healthy.CompareAndSwap(true, false) {
+		r.l.Warn("Database replica is unavailable, falling back to primary", zap.String("replica", rep.name), zap.Error(err))
+	}
+	return true
+}
+
+func (r *DBRouter) checkReplicas(ctx context.Context, timeout time.Duration) {
+	for _, rep := range r.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := rep.pool.Ping(pingCtx)
+		cancel()
+		healthy := err == nil
+		if rep.healthy.Swap(healthy) != healthy {
+			if healthy {
+				r.l.Info("Database replica is healthy", zap.String("replica", rep.name))
+			} else {
+				r.l.Warn("Database replica health check failed", zap.String("replica", rep.name), zap.Error(err))
+			}
+		}
+	}
+}
+
+func (r *DBRouter) close() {
+	for _, rep := range r.replicas {
+		rep.pool.Close()
+	}
+}
+
+// isReadOnlyQuery sqlc 生成的 SELECT 查询可以发送到副本，其他语句（包括带写操作的 WITH 和加锁的查询）都发送到主库
+func isReadOnlyQuery(sql string) bool {
+	if !strings.HasPrefix(sql, "-- name: ") {
+		return false
+	}
+	// 跳过 sqlc 添加的查询名注释
+	_, body, _ := strings.Cut(sql, "\n")
+	body = strings.TrimSpace(body)
+	return len(body) >= 6 && strings.EqualFold(body[:6], "SELECT") && !lockingClause.MatchString(body)
+}
+
+// fallbackRow 副本连接失败时在 Scan 中改用主库查询
+type fallbackRow struct {
+	row      pgx.Row
+	fallback func(err error) pgx.Row
+}
+
+func (f *fallbackRow) Scan(dest ...any) error {
+	err := f.row.Scan(dest...)
+	if err == nil {
+		return nil
+	}
+	if row := f.fallback(err); row != nil {
+		return row.Scan(dest...)
+	}
+	return err
+}
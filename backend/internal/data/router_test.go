@@ -0,0 +1,153 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// retryableErr 模拟语句发送前的连接错误
+type retryableErr struct{}
+
+func (retryableErr) Error() string     { return "dial failed" }
+func (retryableErr) SafeToRetry() bool { return true }
+
+func newTestRouter(t *testing.T, replicas int) *DBRouter {
+	dbCfg := &conf.Data_Database{User: "postgres", DbName: "connect_example", Host: "127.0.0.1", Port: 1}
+	primary, err := newPool(primaryConnString(dbCfg), nil)
+	require.NoError(t, err)
+	t.Cleanup(primary.Close)
+
+	r := &DBRouter{primary: primary, l: zap.NewNop()}
+	for i := 0; i < replicas; i++ {
+		// 连接池在首次使用时才建立连接
+		pool, err := newPool(connString(dbCfg, "127.0.0.1", int32(2+i)), nil)
+		require.NoError(t, err)
+		t.Cleanup(pool.Close)
+		r.replicas = append(r.replicas, &replica{name: "replica", pool: pool})
+	}
+	return r
+}
+
+func TestIsReadOnlyQuery(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want bool
+	}{
+		{models.GetUserByName, true},
+		{models.ListScimUsers, true},
+		{models.CreateUser, false},
+		{models.UpdateCredential, false},
+		// 带写操作的 WITH 查询
+		{models.CreateFederatedUser, false},
+		{"-- name: LockUser :one\nSELECT id FROM users\nFOR UPDATE", false},
+		{"-- name: LockUser :one\nselect id from users for no key update", false},
+		// 非 sqlc 生成的语句
+		{"SELECT 1", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, isReadOnlyQuery(tt.sql), tt.sql)
+	}
+}
+
+func TestConnString(t *testing.T) {
+	dbCfg := &conf.Data_Database{
+		Host:     "db.example.com",
+		Port:     5432,
+		User:     "app",
+		Password: "p@ss:w/rd?#",
+		DbName:   "connect_example",
+		SslMode:  "disable",
+		Timezone: "Asia/Shanghai",
+	}
+
+	cfg, err := pgxpool.ParseConfig(primaryConnString(dbCfg))
+
+	// 密码中的特殊字符经过转义
+	require.NoError(t, err)
+	assert.Equal(t, "db.example.com", cfg.ConnConfig.Host)
+	assert.Equal(t, uint16(5432), cfg.ConnConfig.Port)
+	assert.Equal(t, "app", cfg.ConnConfig.User)
+	assert.Equal(t, "p@ss:w/rd?#", cfg.ConnConfig.Password)
+	assert.Equal(t, "connect_example", cfg.ConnConfig.Database)
+	assert.Equal(t, "Asia/Shanghai", cfg.ConnConfig.RuntimeParams["timezone"])
+
+	// 副本沿用主库的账号，DSN 优先于字段
+	cfg, err = pgxpool.ParseConfig(replicaConnString(dbCfg, &conf.Data_Replica{Host: "replica.example.com", Port: 5433}))
+	require.NoError(t, err)
+	assert.Equal(t, "replica.example.com", cfg.ConnConfig.Host)
+	assert.Equal(t, "p@ss:w/rd?#", cfg.ConnConfig.Password)
+	dbCfg.Dsn = "postgres://other@dsn.example.com/other"
+	assert.Equal(t, dbCfg.Dsn, primaryConnString(dbCfg))
+}
+
+func TestNewPool_AppliesPoolSettings(t *testing.T) {
+	pool, err := newPool("postgres://app@127.0.0.1:1/connect_example", &conf.Data_DatabasePool{
+		MaxConns:        7,
+		MinConns:        0,
+		MaxConnLifetime: 3600,
+		MaxConnIdleTime: 300,
+	})
+	require.NoError(t, err)
+	defer pool.Close()
+
+	assert.Equal(t, int32(7), pool.Config().MaxConns)
+	assert.Equal(t, time.Hour, pool.Config().MaxConnLifetime)
+	assert.Equal(t, 5*time.Minute, pool.Config().MaxConnIdleTime)
+}
+
+func TestDBRouter_Route(t *testing.T) {
+	r := newTestRouter(t, 2)
+	ctx := context.Background()
+
+	// 副本健康检查通过前使用主库
+	assert.Nil(t, r.route(ctx, models.GetUserByName))
+
+	r.replicas[0].healthy.Store(true)
+	r.replicas[1].healthy.Store(true)
+	first, second := r.route(ctx, models.GetUserByName), r.route(ctx, models.GetUserByName)
+	assert.NotNil(t, first)
+	assert.NotSame(t, first, second, "轮询副本")
+	assert.Nil(t, r.route(ctx, models.CreateUser))
+
+	// 只剩一个健康副本
+	r.replicas[0].healthy.Store(false)
+	assert.Same(t, r.replicas[1], r.route(ctx, models.GetUserByName))
+	assert.Same(t, r.replicas[1], r.route(ctx, models.GetUserByName))
+
+	// 写入后立即读取时使用主库
+	assert.Nil(t, r.route(WithPrimary(ctx), models.GetUserByName))
+}
+
+func TestDBRouter_Failover(t *testing.T) {
+	r := newTestRouter(t, 1)
+	rep := r.replicas[0]
+	rep.healthy.Store(true)
+
+	// 语句已发送到副本的错误不重试
+	assert.False(t, r.failover(rep, errors.New("syntax error")))
+	assert.True(t, rep.healthy.Load())
+
+	assert.True(t, r.failover(rep, retryableErr{}))
+	assert.False(t, rep.healthy.Load())
+	assert.Nil(t, r.route(context.Background(), models.GetUserByName))
+}
+
+func TestDBRouter_CheckReplicas(t *testing.T) {
+	r := newTestRouter(t, 1)
+	r.replicas[0].healthy.Store(true)
+
+	r.checkReplicas(context.Background(), time.Second)
+
+	// 副本不可达
+	assert.False(t, r.replicas[0].healthy.Load())
+}
@@ -1,5 +1,4 @@
--- DROP TABLE users;
-CREATE DATABASE connect_example;
+-- 与最初的 schema.sql 相同，按旧 schema.sql 手工创建的数据库执行 migrate baseline 后从 000002 开始迁移
 CREATE TABLE users
 (
     id            SERIAL PRIMARY KEY,
@@ -0,0 +1,7 @@
+ALTER TABLE users
+    DROP CONSTRAINT users_tenant_id_username_key,
+    ADD CONSTRAINT users_username_key UNIQUE (username),
+    DROP COLUMN tenant_id;
+
+DROP TABLE IF EXISTS tenant_clients;
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,29 @@
+CREATE TABLE tenants
+(
+    id         SERIAL PRIMARY KEY,
+    slug       VARCHAR(63) UNIQUE        NOT NULL, -- 租户标识，用于请求头和子域名
+    name       VARCHAR(255)              NOT NULL,
+    created_at timestamptz DEFAULT now() NOT NULL
+);
+COMMENT
+    ON TABLE tenants IS '租户表';
+
+CREATE TABLE tenant_clients
+(
+    client_id VARCHAR(255) PRIMARY KEY,                          -- 客户端ID，请求头 X-Client-ID
+    tenant_id INTEGER      NOT NULL REFERENCES tenants (id) ON DELETE CASCADE
+);
+COMMENT
+    ON TABLE tenant_clients IS '客户端所属租户';
+
+-- 默认租户，单租户部署时使用
+INSERT INTO tenants (id, slug, name)
+VALUES (1, 'default', 'Default');
+
+-- 已有用户归入默认租户，用户名改为租户内唯一
+ALTER TABLE users
+    ADD COLUMN tenant_id INTEGER DEFAULT 1 NOT NULL REFERENCES tenants (id);
+ALTER TABLE users
+    ALTER COLUMN tenant_id DROP DEFAULT,
+    DROP CONSTRAINT users_username_key,
+    ADD CONSTRAINT users_tenant_id_username_key UNIQUE (tenant_id, username);
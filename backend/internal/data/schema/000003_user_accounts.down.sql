@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS invite_redemptions;
+DROP TABLE IF EXISTS invites;
+DROP TABLE IF EXISTS user_roles;
+
+ALTER TABLE users
+    DROP CONSTRAINT users_tenant_id_email_key,
+    DROP COLUMN credential_backend,
+    DROP COLUMN active,
+    DROP COLUMN family_name,
+    DROP COLUMN given_name,
+    DROP COLUMN display_name,
+    DROP COLUMN external_id,
+    DROP COLUMN email,
+    DROP COLUMN kdf_version;
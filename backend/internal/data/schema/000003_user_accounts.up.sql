@@ -0,0 +1,46 @@
+-- 账户信息：客户端 KDF 版本、邮箱、SCIM 属性、停用状态和凭证校验后端，以及角色和邀请码。
+-- password_hash 改为客户端凭证经 pepper + argon2id 哈希后的值（PHC 格式），旧数据为客户端凭证原文，登录成功后升级
+ALTER TABLE users
+    ADD COLUMN kdf_version        INTEGER      DEFAULT 0    NOT NULL, -- 客户端 KDF 参数版本，0 表示客户端自选的旧凭证
+    ADD COLUMN email              VARCHAR(255),                       -- 邮箱，用于免密登录
+    ADD COLUMN external_id        VARCHAR(255),                       -- SCIM 客户端（身份源）中的用户ID
+    ADD COLUMN display_name       VARCHAR(255) DEFAULT ''   NOT NULL,
+    ADD COLUMN given_name         VARCHAR(255) DEFAULT ''   NOT NULL,
+    ADD COLUMN family_name        VARCHAR(255) DEFAULT ''   NOT NULL,
+    ADD COLUMN active             BOOLEAN      DEFAULT true NOT NULL, -- 停用的用户不能登录，SCIM 下发的离职用户会被停用
+    ADD COLUMN credential_backend VARCHAR(63),                        -- 凭证校验后端，为空时使用租户默认后端
+    ADD CONSTRAINT users_tenant_id_email_key UNIQUE (tenant_id, email);
+
+CREATE TABLE user_roles
+(
+    user_id    INTEGER                   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role       VARCHAR(63)               NOT NULL, -- 角色，如 admin
+    created_at timestamptz DEFAULT now() NOT NULL,
+    PRIMARY KEY (user_id, role)
+);
+COMMENT
+    ON TABLE user_roles IS '用户角色表';
+-- 授予管理员：INSERT INTO user_roles (user_id, role) VALUES (<用户ID>, 'admin');
+
+CREATE TABLE invites
+(
+    id         SERIAL PRIMARY KEY,
+    tenant_id  INTEGER                   NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    inviter_id INTEGER                   NOT NULL REFERENCES users (id) ON DELETE CASCADE, -- 邀请人，删除用户时一并作废其邀请码
+    max_uses   INTEGER                   NOT NULL, -- 最多可注册次数
+    used_count INTEGER     DEFAULT 0     NOT NULL, -- 已使用次数
+    expires_at timestamptz               NOT NULL,
+    created_at timestamptz DEFAULT now() NOT NULL
+);
+COMMENT
+    ON TABLE invites IS '邀请码表，邀请码本身由服务端签名生成，不落库';
+
+CREATE TABLE invite_redemptions
+(
+    invite_id  INTEGER                   NOT NULL REFERENCES invites (id) ON DELETE CASCADE,
+    user_id    INTEGER                   NOT NULL REFERENCES users (id) ON DELETE CASCADE, -- 受邀注册的用户
+    created_at timestamptz DEFAULT now() NOT NULL,
+    PRIMARY KEY (invite_id, user_id)
+);
+COMMENT
+    ON TABLE invite_redemptions IS '邀请码使用记录';
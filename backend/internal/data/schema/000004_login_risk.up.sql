@@ -0,0 +1,33 @@
+-- 登录风险评估使用的设备和登录记录
+CREATE TABLE user_devices
+(
+    id            SERIAL PRIMARY KEY,
+    tenant_id     INTEGER                   NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    user_id       INTEGER                   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    fingerprint   VARCHAR(64)               NOT NULL, -- 设备ID、User-Agent（未提供设备ID时加上网段）的 sha256
+    device_id     VARCHAR(255) DEFAULT ''   NOT NULL, -- 客户端提供的设备标识
+    user_agent    TEXT         DEFAULT ''   NOT NULL,
+    ip_prefix     VARCHAR(64)  DEFAULT ''   NOT NULL, -- 最近一次登录的网段
+    location_hint VARCHAR(64)  DEFAULT ''   NOT NULL,
+    first_seen_at timestamptz  DEFAULT now() NOT NULL,
+    last_seen_at  timestamptz  DEFAULT now() NOT NULL,
+    UNIQUE (user_id, fingerprint)
+);
+COMMENT
+    ON TABLE user_devices IS '用户登录过的设备';
+
+CREATE TABLE login_events
+(
+    id          BIGSERIAL PRIMARY KEY,
+    tenant_id   INTEGER                   NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    user_id     INTEGER                   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    fingerprint VARCHAR(64)               NOT NULL,
+    ip_prefix   VARCHAR(64) DEFAULT ''    NOT NULL,
+    risk_score  INTEGER     DEFAULT 0     NOT NULL,
+    reasons     TEXT[]      DEFAULT '{}'  NOT NULL, -- new_device、new_network、unusual_hour
+    step_up     BOOLEAN     DEFAULT false NOT NULL, -- 是否经过二次验证
+    created_at  timestamptz DEFAULT now() NOT NULL
+);
+COMMENT
+    ON TABLE login_events IS '成功登录记录，用于统计常用时段和网段';
+CREATE INDEX login_events_user_created_at_idx ON login_events (user_id, created_at DESC);
@@ -0,0 +1,40 @@
+-- SCIM 下发的用户组和外部 OIDC 身份
+CREATE TABLE user_groups
+(
+    id           SERIAL PRIMARY KEY,
+    tenant_id    INTEGER                   NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    display_name VARCHAR(255)              NOT NULL,
+    external_id  VARCHAR(255),                       -- SCIM 客户端中的组ID
+    created_at   timestamptz DEFAULT now() NOT NULL,
+    updated_at   timestamptz DEFAULT now() NOT NULL,
+    UNIQUE (tenant_id, display_name)
+);
+COMMENT
+    ON TABLE user_groups IS '用户组，由 SCIM 客户端下发';
+
+CREATE TABLE group_members
+(
+    group_id   INTEGER                   NOT NULL REFERENCES user_groups (id) ON DELETE CASCADE,
+    user_id    INTEGER                   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at timestamptz DEFAULT now() NOT NULL,
+    PRIMARY KEY (group_id, user_id)
+);
+COMMENT
+    ON TABLE group_members IS '用户组成员';
+CREATE INDEX group_members_user_id_idx ON group_members (user_id);
+
+CREATE TABLE user_identities
+(
+    id            SERIAL PRIMARY KEY,
+    tenant_id     INTEGER                   NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    user_id       INTEGER                   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    provider      VARCHAR(63)               NOT NULL, -- 配置中的提供方标识
+    subject       VARCHAR(255)              NOT NULL, -- ID Token 中的 sub
+    email         VARCHAR(255) DEFAULT ''   NOT NULL, -- 最近一次登录时 IdP 返回的邮箱，仅供展示
+    created_at    timestamptz DEFAULT now() NOT NULL,
+    last_login_at timestamptz DEFAULT now() NOT NULL,
+    UNIQUE (tenant_id, provider, subject),
+    UNIQUE (user_id, provider)                        -- 每个提供方只能关联一个外部账号
+);
+COMMENT
+    ON TABLE user_identities IS '外部 OIDC 身份与本地用户的关联';
@@ -0,0 +1,10 @@
+-- Redis 不可用时保存登录挑战等短期状态。UNLOGGED 表不写 WAL，崩溃后清空，也不会复制到只读副本
+CREATE UNLOGGED TABLE state_entries
+(
+    key        VARCHAR(255) PRIMARY KEY,
+    value      TEXT        NOT NULL,
+    expires_at timestamptz NOT NULL
+);
+COMMENT
+    ON TABLE state_entries IS '短期状态，Redis 的备用存储';
+CREATE INDEX state_entries_expires_at_idx ON state_entries (expires_at);
@@ -0,0 +1,18 @@
+-- 事务性发件箱：领域事件与数据修改在同一事务中写入，由投递任务按 id 顺序发送到消息代理
+CREATE TABLE outbox
+(
+    id             BIGSERIAL PRIMARY KEY,
+    tenant_id      INTEGER      NOT NULL,
+    aggregate_type VARCHAR(50)  NOT NULL,
+    aggregate_id   VARCHAR(100) NOT NULL,
+    event_type     VARCHAR(100) NOT NULL,
+    payload        JSONB        NOT NULL,
+    created_at     timestamptz  NOT NULL DEFAULT now(),
+    published_at   timestamptz
+);
+COMMENT
+    ON TABLE outbox IS '待投递和已投递的领域事件';
+COMMENT
+    ON COLUMN outbox.published_at IS '投递成功的时间，为空表示待投递';
+CREATE INDEX outbox_pending_idx ON outbox (id) WHERE published_at IS NULL;
+CREATE INDEX outbox_published_at_idx ON outbox (published_at) WHERE published_at IS NOT NULL;
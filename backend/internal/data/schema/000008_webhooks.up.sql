@@ -0,0 +1,34 @@
+CREATE TABLE webhook_subscriptions
+(
+    id         SERIAL PRIMARY KEY,
+    tenant_id  INTEGER                   NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    url        TEXT                      NOT NULL,
+    events     TEXT[]                    NOT NULL, -- 订阅的事件类型
+    secret     TEXT                      NOT NULL, -- 计算 HMAC-SHA256 签名的密钥
+    created_at timestamptz DEFAULT now() NOT NULL
+);
+COMMENT
+    ON TABLE webhook_subscriptions IS 'Webhook 订阅';
+CREATE INDEX webhook_subscriptions_tenant_id_idx ON webhook_subscriptions (tenant_id);
+
+CREATE TABLE webhook_deliveries
+(
+    id              BIGSERIAL PRIMARY KEY,
+    tenant_id       INTEGER                         NOT NULL,
+    subscription_id INTEGER                         NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    event_id        BIGINT                          NOT NULL, -- outbox.id，outbox 中的事件清理后投递记录仍保留内容
+    event_type      VARCHAR(100)                    NOT NULL,
+    payload         JSONB                           NOT NULL,
+    status          VARCHAR(20) DEFAULT 'pending'   NOT NULL, -- pending、succeeded 或 dead（重试次数用完）
+    attempts        INTEGER     DEFAULT 0           NOT NULL,
+    next_attempt_at timestamptz DEFAULT now()       NOT NULL, -- 待投递时为下次尝试的时间，投递中为租约到期时间
+    last_attempt_at timestamptz,
+    response_status INTEGER,                                  -- 最近一次尝试的 HTTP 状态码
+    last_error      TEXT,
+    delivered_at    timestamptz,
+    created_at      timestamptz DEFAULT now()       NOT NULL,
+    UNIQUE (subscription_id, event_id)
+);
+COMMENT
+    ON TABLE webhook_deliveries IS 'Webhook 投递记录';
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
@@ -0,0 +1,22 @@
+-- 只能恢复尚未重新加密的数据，已加密的字段需要先用应用解密
+ALTER TABLE user_identities
+    ALTER COLUMN email TYPE VARCHAR(255) USING COALESCE(convert_from(substring(email FROM 2), 'UTF8'), ''),
+    ALTER COLUMN email SET DEFAULT '',
+    ALTER COLUMN email SET NOT NULL;
+
+ALTER TABLE users
+    DROP CONSTRAINT users_tenant_id_email_index_key,
+    DROP COLUMN email_index,
+    ALTER COLUMN email TYPE VARCHAR(255) USING convert_from(substring(email FROM 2), 'UTF8'),
+    ALTER COLUMN display_name TYPE VARCHAR(255) USING COALESCE(convert_from(substring(display_name FROM 2), 'UTF8'), ''),
+    ALTER COLUMN display_name SET DEFAULT '',
+    ALTER COLUMN display_name SET NOT NULL,
+    ALTER COLUMN given_name TYPE VARCHAR(255) USING COALESCE(convert_from(substring(given_name FROM 2), 'UTF8'), ''),
+    ALTER COLUMN given_name SET DEFAULT '',
+    ALTER COLUMN given_name SET NOT NULL,
+    ALTER COLUMN family_name TYPE VARCHAR(255) USING COALESCE(convert_from(substring(family_name FROM 2), 'UTF8'), ''),
+    ALTER COLUMN family_name SET DEFAULT '',
+    ALTER COLUMN family_name SET NOT NULL,
+    ADD CONSTRAINT users_tenant_id_email_key UNIQUE (tenant_id, email);
+
+DROP TABLE IF EXISTS data_keys;
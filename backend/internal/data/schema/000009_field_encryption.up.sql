@@ -0,0 +1,35 @@
+-- 个人信息字段加密：字段由数据密钥加密，数据密钥由 KEK 加密后保存在 data_keys
+CREATE TABLE data_keys
+(
+    id          SERIAL PRIMARY KEY,
+    purpose     VARCHAR(31)               NOT NULL, -- encryption 或 blind_index
+    kek_id      VARCHAR(255)              NOT NULL, -- 加密该数据密钥的 KEK
+    wrapped_key BYTEA                     NOT NULL,
+    created_at  timestamptz DEFAULT now() NOT NULL
+);
+COMMENT
+    ON TABLE data_keys IS '数据密钥，新数据使用最新的 encryption 密钥加密，旧密钥保留用于解密';
+
+-- 已有数据转换为版本0（明文）格式，由重新加密任务在后台加密并补齐盲索引
+ALTER TABLE users
+    DROP CONSTRAINT users_tenant_id_email_key;
+ALTER TABLE users
+    ALTER COLUMN email TYPE BYTEA USING '\x00'::BYTEA || convert_to(email, 'UTF8'),
+    ALTER COLUMN display_name DROP NOT NULL,
+    ALTER COLUMN display_name DROP DEFAULT,
+    ALTER COLUMN display_name TYPE BYTEA USING CASE WHEN display_name = '' THEN NULL ELSE '\x00'::BYTEA || convert_to(display_name, 'UTF8') END,
+    ALTER COLUMN given_name DROP NOT NULL,
+    ALTER COLUMN given_name DROP DEFAULT,
+    ALTER COLUMN given_name TYPE BYTEA USING CASE WHEN given_name = '' THEN NULL ELSE '\x00'::BYTEA || convert_to(given_name, 'UTF8') END,
+    ALTER COLUMN family_name DROP NOT NULL,
+    ALTER COLUMN family_name DROP DEFAULT,
+    ALTER COLUMN family_name TYPE BYTEA USING CASE WHEN family_name = '' THEN NULL ELSE '\x00'::BYTEA || convert_to(family_name, 'UTF8') END,
+    ADD COLUMN email_index BYTEA,
+    ADD CONSTRAINT users_tenant_id_email_index_key UNIQUE (tenant_id, email_index);
+COMMENT
+    ON COLUMN users.email_index IS '规范化邮箱的盲索引，用于按邮箱查找';
+
+ALTER TABLE user_identities
+    ALTER COLUMN email DROP NOT NULL,
+    ALTER COLUMN email DROP DEFAULT,
+    ALTER COLUMN email TYPE BYTEA USING CASE WHEN email = '' THEN NULL ELSE '\x00'::BYTEA || convert_to(email, 'UTF8') END;
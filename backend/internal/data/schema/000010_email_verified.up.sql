@@ -0,0 +1,6 @@
+-- 邮箱是否已证明归用户所有：免密登录、注册邮箱票据、SCIM 和目录服务写入的邮箱为已验证，
+-- 只有已验证的邮箱才能用于按邮箱关联外部身份
+ALTER TABLE users
+    ADD COLUMN email_verified BOOLEAN DEFAULT false NOT NULL;
+COMMENT
+    ON COLUMN users.email_verified IS '邮箱已验证';
@@ -0,0 +1 @@
+DROP INDEX users_email_index_missing_idx;
@@ -0,0 +1,3 @@
+-- 000009 去掉了明文邮箱上的唯一约束，盲索引补齐前邮箱唯一性无法保证，服务启动时先补齐再对外服务；
+-- 部分索引只包含还没有盲索引的邮箱，补齐后为空，启动时的检查不需要扫描全表
+CREATE INDEX users_email_index_missing_idx ON users (id) WHERE email IS NOT NULL AND email_index IS NULL;
@@ -0,0 +1,9 @@
+// Package schema 内嵌数据库迁移脚本，文件名格式为 <版本>_<名称>.up.sql 和 <版本>_<名称>.down.sql
+package schema
+
+import "embed"
+
+// Migrations 按版本号顺序执行的迁移脚本，sqlc 也从这里读取表结构
+//
+//go:embed *.sql
+var Migrations embed.FS
@@ -0,0 +1,510 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"connect-go-example/internal/biz/model"
+	"connect-go-example/internal/data/models"
+	"connect-go-example/internal/pkg/fieldcrypt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+// pgUniqueViolation 唯一约束冲突的错误码
+const pgUniqueViolation = "23505"
+
+// ScimRepo SCIM 用户和组的数据访问接口，所有操作都限定在 ctx 中的租户内
+type ScimRepo interface {
+	CreateUser(ctx context.Context, user *model.ScimUser) (*model.ScimUser, error)
+	GetUser(ctx context.Context, id int64) (*model.ScimUser, error)
+	UpdateUser(ctx context.Context, user *model.ScimUser) (*model.ScimUser, error)
+	DeleteUser(ctx context.Context, id int64) error
+	ListUsers(ctx context.Context, page *model.ScimPage) ([]*model.ScimUser, int64, error)
+
+	// CreateGroup 创建组并添加成员，不存在的用户会被忽略
+	CreateGroup(ctx context.Context, group *model.ScimGroup) (*model.ScimGroup, error)
+	GetGroup(ctx context.Context, id int64) (*model.ScimGroup, error)
+	// UpdateGroup 只更新组的属性，成员通过下面的方法修改
+	UpdateGroup(ctx context.Context, group *model.ScimGroup) (*model.ScimGroup, error)
+	DeleteGroup(ctx context.Context, id int64) error
+	ListGroups(ctx context.Context, page *model.ScimPage) ([]*model.ScimGroup, int64, error)
+	// 修改成员前需要先通过 GetGroup 确认组属于当前租户
+	AddGroupMembers(ctx context.Context, groupID int64, userIDs []int64) error
+	RemoveGroupMembers(ctx context.Context, groupID int64, userIDs []int64) error
+	ReplaceGroupMembers(ctx context.Context, groupID int64, userIDs []int64) error
+}
+
+type scimRepo struct {
+	queries *models.Queries
+	keyring *fieldcrypt.Keyring
+	// cache 用户被修改或删除后清除登录使用的用户缓存
+	cache *UserCache
+	l     *zap.Logger
+}
+
+func NewScimRepo(data *Data, cache *UserCache, keyring *fieldcrypt.Keyring, logger *zap.Logger) ScimRepo {
+	return &scimRepo{
+		queries: models.New(data.query),
+		keyring: keyring,
+		cache:   cache,
+		l:       logger,
+	}
+}
+
+func (r *scimRepo) CreateUser(ctx context.Context, user *model.ScimUser) (*model.ScimUser, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	codec := newFieldCodec(r.keyring, tenantID)
+	params := models.CreateScimUserParams{
+		TenantID:    int32(tenantID),
+		Username:    user.UserName,
+		Email:       codec.encrypt(fieldUserEmail, user.Email),
+		EmailIndex:  codec.emailIndex(user.Email),
+		ExternalID:  optional(user.ExternalID),
+		DisplayName: codec.encrypt(fieldUserDisplayName, user.DisplayName),
+		GivenName:   codec.encrypt(fieldUserGivenName, user.GivenName),
+		FamilyName:  codec.encrypt(fieldUserFamilyName, user.FamilyName),
+		Active:      user.Active,
+	}
+	if codec.err != nil {
+		return nil, codec.err
+	}
+	row, err := withTx(ctx, r.queries).CreateScimUser(ctx, params)
+	if err != nil {
+		return nil, scimError(err)
+	}
+	r.cache.Invalidate(ctx, tenantID, user.UserName)
+	return r.toScimUser(tenantID, models.GetScimUserRow(row))
+}
+
+func (r *scimRepo) GetUser(ctx context.Context, id int64) (*model.ScimUser, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := withTx(ctx, r.queries).GetScimUser(ctx, models.GetScimUserParams{
+		TenantID: int32(tenantID),
+		ID:       int32(id),
+	})
+	if err != nil {
+		return nil, scimError(err)
+	}
+	return r.toScimUser(tenantID, row)
+}
+
+func (r *scimRepo) UpdateUser(ctx context.Context, user *model.ScimUser) (*model.ScimUser, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := r.currentUsername(ctx, tenantID, user.ID)
+	codec := newFieldCodec(r.keyring, tenantID)
+	params := models.UpdateScimUserParams{
+		Username:    user.UserName,
+		Email:       codec.encrypt(fieldUserEmail, user.Email),
+		EmailIndex:  codec.emailIndex(user.Email),
+		ExternalID:  optional(user.ExternalID),
+		DisplayName: codec.encrypt(fieldUserDisplayName, user.DisplayName),
+		GivenName:   codec.encrypt(fieldUserGivenName, user.GivenName),
+		FamilyName:  codec.encrypt(fieldUserFamilyName, user.FamilyName),
+		Active:      user.Active,
+		TenantID:    int32(tenantID),
+		ID:          int32(user.ID),
+	}
+	if codec.err != nil {
+		return nil, codec.err
+	}
+	row, err := withTx(ctx, r.queries).UpdateScimUser(ctx, params)
+	if err != nil {
+		return nil, scimError(err)
+	}
+	// 改名后旧用户名的缓存也要清除，停用的用户才不能继续登录
+	r.cache.Invalidate(ctx, tenantID, previous, user.UserName)
+	return r.toScimUser(tenantID, models.GetScimUserRow(row))
+}
+
+func (r *scimRepo) DeleteUser(ctx context.Context, id int64) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	username := r.currentUsername(ctx, tenantID, id)
+	n, err := withTx(ctx, r.queries).DeleteUser(ctx, models.DeleteUserParams{
+		TenantID: int32(tenantID),
+		ID:       int32(id),
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return model.ErrScimResourceNotFound
+	}
+	if username != "" {
+		r.cache.Invalidate(ctx, tenantID, username)
+	}
+	return nil
+}
+
+// currentUsername 返回修改前的用户名，用于清除用户缓存；未启用缓存时不查询
+func (r *scimRepo) currentUsername(ctx context.Context, tenantID, id int64) string {
+	if r.cache == nil {
+		return ""
+	}
+	row, err := withTx(ctx, r.queries).GetScimUser(ctx, models.GetScimUserParams{
+		TenantID: int32(tenantID),
+		ID:       int32(id),
+	})
+	if err != nil {
+		return ""
+	}
+	return row.Username
+}
+
+func (r *scimRepo) ListUsers(ctx context.Context, page *model.ScimPage) ([]*model.ScimUser, int64, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	params := models.ListScimUsersParams{
+		TenantID:   int32(tenantID),
+		Skip:       page.Offset,
+		MaxResults: page.Limit,
+	}
+	for _, c := range page.Conditions {
+		pattern := likePattern(c)
+		switch c.Attribute {
+		case "userName":
+			params.UserName = &pattern
+		case "externalId":
+			params.ExternalID = &pattern
+		case "emails.value":
+			// 邮箱已加密，只支持 eq 和 pr
+			switch c.Operator {
+			case "eq":
+				params.EmailIndex = emailIndex(r.keyring, tenantID, c.Value)
+				params.LegacyEmail = fieldcrypt.Unencrypted(c.Value)
+			case "pr":
+				params.HasEmail = true
+			default:
+				return nil, 0, fmt.Errorf("unsupported operator %q for encrypted attribute", c.Operator)
+			}
+		default:
+			return nil, 0, fmt.Errorf("unsupported filter attribute %q", c.Attribute)
+		}
+	}
+
+	total, err := withTx(ctx, r.queries).CountScimUsers(ctx, models.CountScimUsersParams{
+		TenantID:    params.TenantID,
+		UserName:    params.UserName,
+		ExternalID:  params.ExternalID,
+		EmailIndex:  params.EmailIndex,
+		LegacyEmail: params.LegacyEmail,
+		HasEmail:    params.HasEmail,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	rows, err := withTx(ctx, r.queries).ListScimUsers(ctx, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	users := make([]*model.ScimUser, 0, len(rows))
+	for _, row := range rows {
+		user, err := r.toScimUser(tenantID, models.GetScimUserRow(row))
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+	return users, total, nil
+}
+
+func (r *scimRepo) CreateGroup(ctx context.Context, group *model.ScimGroup) (*model.ScimGroup, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := withTx(ctx, r.queries).CreateGroup(ctx, models.CreateGroupParams{
+		TenantID:    int32(tenantID),
+		DisplayName: group.DisplayName,
+		ExternalID:  optional(group.ExternalID),
+	})
+	if err != nil {
+		return nil, scimError(err)
+	}
+
+	created := toScimGroup(models.GetGroupRow(row))
+	if len(group.Members) > 0 {
+		if err := r.AddGroupMembers(ctx, created.ID, memberIDs(group.Members)); err != nil {
+			return nil, err
+		}
+		// 刚写入的成员从主库读取
+		if err := r.loadMembers(WithPrimary(ctx), tenantID, created); err != nil {
+			return nil, err
+		}
+	}
+	return created, nil
+}
+
+func (r *scimRepo) GetGroup(ctx context.Context, id int64) (*model.ScimGroup, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := withTx(ctx, r.queries).GetGroup(ctx, models.GetGroupParams{
+		TenantID: int32(tenantID),
+		ID:       int32(id),
+	})
+	if err != nil {
+		return nil, scimError(err)
+	}
+
+	group := toScimGroup(row)
+	if err := r.loadMembers(ctx, tenantID, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (r *scimRepo) UpdateGroup(ctx context.Context, group *model.ScimGroup) (*model.ScimGroup, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := withTx(ctx, r.queries).UpdateGroup(ctx, models.UpdateGroupParams{
+		DisplayName: group.DisplayName,
+		ExternalID:  optional(group.ExternalID),
+		TenantID:    int32(tenantID),
+		ID:          int32(group.ID),
+	})
+	if err != nil {
+		return nil, scimError(err)
+	}
+	return toScimGroup(models.GetGroupRow(row)), nil
+}
+
+func (r *scimRepo) DeleteGroup(ctx context.Context, id int64) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	n, err := withTx(ctx, r.queries).DeleteGroup(ctx, models.DeleteGroupParams{
+		TenantID: int32(tenantID),
+		ID:       int32(id),
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return model.ErrScimResourceNotFound
+	}
+	return nil
+}
+
+func (r *scimRepo) ListGroups(ctx context.Context, page *model.ScimPage) ([]*model.ScimGroup, int64, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	params := models.ListGroupsParams{
+		TenantID:   int32(tenantID),
+		Skip:       page.Offset,
+		MaxResults: page.Limit,
+	}
+	for _, c := range page.Conditions {
+		pattern := likePattern(c)
+		switch c.Attribute {
+		case "displayName":
+			params.DisplayName = &pattern
+		case "externalId":
+			params.ExternalID = &pattern
+		default:
+			return nil, 0, fmt.Errorf("unsupported filter attribute %q", c.Attribute)
+		}
+	}
+
+	total, err := withTx(ctx, r.queries).CountGroups(ctx, models.CountGroupsParams{
+		TenantID:    params.TenantID,
+		DisplayName: params.DisplayName,
+		ExternalID:  params.ExternalID,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	rows, err := withTx(ctx, r.queries).ListGroups(ctx, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	groups := make([]*model.ScimGroup, 0, len(rows))
+	for _, row := range rows {
+		groups = append(groups, toScimGroup(models.GetGroupRow(row)))
+	}
+	if err := r.loadMembers(ctx, tenantID, groups...); err != nil {
+		return nil, 0, err
+	}
+	return groups, total, nil
+}
+
+func (r *scimRepo) AddGroupMembers(ctx context.Context, groupID int64, userIDs []int64) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	return withTx(ctx, r.queries).AddGroupMembers(ctx, models.AddGroupMembersParams{
+		GroupID:  int32(groupID),
+		TenantID: int32(tenantID),
+		UserIds:  toInt32s(userIDs),
+	})
+}
+
+func (r *scimRepo) RemoveGroupMembers(ctx context.Context, groupID int64, userIDs []int64) error {
+	return withTx(ctx, r.queries).RemoveGroupMembers(ctx, models.RemoveGroupMembersParams{
+		GroupID: int32(groupID),
+		UserIds: toInt32s(userIDs),
+	})
+}
+
+func (r *scimRepo) ReplaceGroupMembers(ctx context.Context, groupID int64, userIDs []int64) error {
+	if err := withTx(ctx, r.queries).ClearGroupMembers(ctx, int32(groupID)); err != nil {
+		return err
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+	return r.AddGroupMembers(ctx, groupID, userIDs)
+}
+
+// loadMembers 一次查询所有组的成员
+func (r *scimRepo) loadMembers(ctx context.Context, tenantID int64, groups ...*model.ScimGroup) error {
+	if len(groups) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*model.ScimGroup, len(groups))
+	ids := make([]int32, 0, len(groups))
+	for _, g := range groups {
+		g.Members = []*model.ScimMember{}
+		byID[g.ID] = g
+		ids = append(ids, int32(g.ID))
+	}
+
+	rows, err := withTx(ctx, r.queries).ListGroupMembers(ctx, models.ListGroupMembersParams{
+		TenantID: int32(tenantID),
+		GroupIds: ids,
+	})
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		g := byID[int64(row.GroupID)]
+		g.Members = append(g.Members, &model.ScimMember{UserID: int64(row.UserID), Display: row.Username})
+	}
+	return nil
+}
+
+// likePattern 把过滤条件转换为 LIKE 模式，值中的通配符按字面匹配
+func likePattern(c *model.ScimCondition) string {
+	value := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(c.Value)
+	switch c.Operator {
+	case "co":
+		return "%" + value + "%"
+	case "sw":
+		return value + "%"
+	case "ew":
+		return "%" + value
+	case "pr":
+		return "%"
+	default:
+		return value
+	}
+}
+
+// scimError 转换未找到和唯一约束冲突
+func scimError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.ErrScimResourceNotFound
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return model.ErrScimConflict
+	}
+	return err
+}
+
+func (r *scimRepo) toScimUser(tenantID int64, row models.GetScimUserRow) (*model.ScimUser, error) {
+	codec := newFieldCodec(r.keyring, tenantID)
+	user := &model.ScimUser{
+		ID:          int64(row.ID),
+		UserName:    row.Username,
+		DisplayName: codec.decrypt(fieldUserDisplayName, row.DisplayName),
+		GivenName:   codec.decrypt(fieldUserGivenName, row.GivenName),
+		FamilyName:  codec.decrypt(fieldUserFamilyName, row.FamilyName),
+		Email:       codec.decrypt(fieldUserEmail, row.Email),
+		Active:      row.Active,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+	if codec.err != nil {
+		return nil, codec.err
+	}
+	if row.ExternalID != nil {
+		user.ExternalID = *row.ExternalID
+	}
+	return user, nil
+}
+
+func toScimGroup(row models.GetGroupRow) *model.ScimGroup {
+	group := &model.ScimGroup{
+		ID:          int64(row.ID),
+		DisplayName: row.DisplayName,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+	if row.ExternalID != nil {
+		group.ExternalID = *row.ExternalID
+	}
+	return group
+}
+
+func memberIDs(members []*model.ScimMember) []int64 {
+	ids := make([]int64, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.UserID)
+	}
+	return ids
+}
+
+func toInt32s(ids []int64) []int32 {
+	out := make([]int32, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, int32(id))
+	}
+	return out
+}
+
+// optional 空字符串保存为 NULL，避免唯一约束把多个空值视为冲突
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
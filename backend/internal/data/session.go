@@ -0,0 +1,42 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SessionRepo 登录会话数据访问接口
+type SessionRepo interface {
+	// RevokeSession 吊销会话，记录保留到会话中最后一个令牌过期
+	RevokeSession(ctx context.Context, sessionID string, ttl time.Duration) error
+	IsSessionRevoked(ctx context.Context, sessionID string) (bool, error)
+}
+
+// sessionRepo 吊销记录保存在 StateStore 中，Redis 不可用时写入备用存储；
+// 备用存储也不可用时 IsSessionRevoked 返回错误，调用方拒绝请求
+type sessionRepo struct {
+	states StateStore
+	l      *zap.Logger
+}
+
+func NewSessionRepo(states StateStore, logger *zap.Logger) SessionRepo {
+	return &sessionRepo{
+		states: states,
+		l:      logger,
+	}
+}
+
+func revokedSessionKey(sessionID string) string {
+	return fmt.Sprintf("revoked_session:%s", sessionID)
+}
+
+func (r *sessionRepo) RevokeSession(ctx context.Context, sessionID string, ttl time.Duration) error {
+	return r.states.Set(ctx, revokedSessionKey(sessionID), "1", ttl)
+}
+
+func (r *sessionRepo) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	return r.states.Exists(ctx, revokedSessionKey(sessionID))
+}
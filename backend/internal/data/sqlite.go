@@ -0,0 +1,574 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+
+	"go.uber.org/zap"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// defaultSQLitePath sqlite 驱动默认的数据库文件
+const defaultSQLitePath = "data/dev.db"
+
+//go:embed sqlite_schema.sql
+var sqliteSchema string
+
+// openSQLite 打开数据库文件并创建表，文件不存在时自动创建
+func openSQLite(path string) (*sql.DB, error) {
+	if path == "" {
+		path = defaultSQLitePath
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("create sqlite directory failed: %v", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite failed: %v", err)
+	}
+	// SQLite 同时只允许一个写入者，单连接避免 SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("create sqlite schema failed: %v", err)
+	}
+	return db, nil
+}
+
+// sqliteConn 由 *sql.DB 和 *sql.Tx 实现
+type sqliteConn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type sqliteTxKey struct{}
+
+// sqliteTx ctx 中的事务，depth 用于生成嵌套保存点的名称
+type sqliteTx struct {
+	tx    *sql.Tx
+	depth int
+}
+
+// sqliteConnFrom ctx 中有事务时在该事务中执行
+func sqliteConnFrom(ctx context.Context, db *sql.DB) sqliteConn {
+	if tx, ok := ctx.Value(sqliteTxKey{}).(*sqliteTx); ok {
+		return tx.tx
+	}
+	return db
+}
+
+type sqliteTransactor struct {
+	db *sql.DB
+}
+
+func (t *sqliteTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if outer, ok := ctx.Value(sqliteTxKey{}).(*sqliteTx); ok {
+		inner := &sqliteTx{tx: outer.tx, depth: outer.depth + 1}
+		savepoint := fmt.Sprintf("sp_%d", inner.depth)
+		if _, err := inner.tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+			return err
+		}
+		if err := fn(context.WithValue(ctx, sqliteTxKey{}, inner)); err != nil {
+			_, _ = inner.tx.ExecContext(ctx, "ROLLBACK TO "+savepoint)
+			_, _ = inner.tx.ExecContext(ctx, "RELEASE "+savepoint)
+			return err
+		}
+		_, err := inner.tx.ExecContext(ctx, "RELEASE "+savepoint)
+		return err
+	}
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(context.WithValue(ctx, sqliteTxKey{}, &sqliteTx{tx: tx})); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+type sqliteUserRepo struct {
+	db *sql.DB
+	authChallenges
+	l *zap.Logger
+}
+
+const sqliteUserColumns = "id, tenant_id, username, password_hash, salt, kdf_version, COALESCE(email, ''), email_verified, active, COALESCE(credential_backend, '')"
+
+func (r *sqliteUserRepo) GetUserByName(ctx context.Context, username string) (*model.User, error) {
+	return r.get(ctx, "username = ?", username)
+}
+
+func (r *sqliteUserRepo) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	return r.get(ctx, "email = ?", email)
+}
+
+func (r *sqliteUserRepo) get(ctx context.Context, where string, arg any) (*model.User, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var user model.User
+	var active bool
+	err = sqliteConnFrom(ctx, r.db).QueryRowContext(ctx,
+		"SELECT "+sqliteUserColumns+" FROM users WHERE tenant_id = ? AND "+where, tenantID, arg,
+	).Scan(&user.ID, &user.TenantID, &user.Username, &user.PasswordHash, &user.Salt, &user.KdfVersion, &user.Email, &user.EmailVerified, &active, &user.CredentialBackend)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrUserNotFound
+		}
+		return nil, err
+	}
+	user.Disabled = !active
+	return &user, nil
+}
+
+func (r *sqliteUserRepo) CreateUser(ctx context.Context, req *model.User) (int64, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = sqliteConnFrom(ctx, r.db).QueryRowContext(ctx,
+		`INSERT INTO users (tenant_id, username, password_hash, salt, kdf_version, email, email_verified)
+VALUES (?, ?, ?, ?, ?, NULLIF(?, ''), ?)
+RETURNING id`,
+		tenantID, req.Username, req.PasswordHash, req.Salt, req.KdfVersion, req.Email, req.EmailVerified && req.Email != "",
+	).Scan(&id)
+	if err != nil {
+		return 0, sqliteError(err)
+	}
+	return id, nil
+}
+
+func (r *sqliteUserRepo) MarkEmailVerified(ctx context.Context, user *model.User) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqliteConnFrom(ctx, r.db).ExecContext(ctx,
+		`UPDATE users
+SET email_verified = 1,
+    updated_at     = CURRENT_TIMESTAMP
+WHERE tenant_id = ?
+  AND id = ?
+  AND email = ?`,
+		tenantID, user.ID, user.Email)
+	return err
+}
+
+func (r *sqliteUserRepo) SyncDirectoryUser(ctx context.Context, user *model.DirectoryUser) (*model.User, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 与 Postgres 的 SyncDirectoryUser 一致：目录用户首次登录时创建，之后只同步属性，不覆盖 credential_backend；
+	// 同名用户属于其他后端时不更新
+	var id int64
+	var active bool
+	err = sqliteConnFrom(ctx, r.db).QueryRowContext(ctx,
+		`INSERT INTO users (tenant_id, username, password_hash, salt, email, display_name, given_name, family_name, credential_backend, email_verified)
+VALUES (?, ?, '', '', NULLIF(?, ''), ?, ?, ?, ?, ? != '')
+ON CONFLICT (tenant_id, username) DO UPDATE
+    SET email          = COALESCE(excluded.email, users.email),
+        email_verified = excluded.email_verified OR users.email_verified,
+        display_name   = excluded.display_name,
+        given_name     = excluded.given_name,
+        family_name    = excluded.family_name,
+        updated_at     = CURRENT_TIMESTAMP
+WHERE users.credential_backend = excluded.credential_backend
+   OR (users.credential_backend IS NULL AND users.password_hash = '')
+RETURNING id, active`,
+		tenantID, user.Username, user.Email, user.DisplayName, user.GivenName, user.FamilyName, user.Backend, user.Email,
+	).Scan(&id, &active)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrUserConflict
+		}
+		return nil, sqliteError(err)
+	}
+
+	return &model.User{
+		ID:            id,
+		TenantID:      tenantID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.Email != "",
+		Disabled:      !active,
+	}, nil
+}
+
+func (r *sqliteUserRepo) GetUserRoles(ctx context.Context, userID int64) ([]string, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := sqliteConnFrom(ctx, r.db).QueryContext(ctx,
+		`SELECT r.role
+FROM user_roles r
+         JOIN users u ON u.id = r.user_id
+WHERE u.tenant_id = ?
+  AND r.user_id = ?
+ORDER BY r.role`, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []string
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+func (r *sqliteUserRepo) UpdateCredential(ctx context.Context, user *model.User) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqliteConnFrom(ctx, r.db).ExecContext(ctx,
+		`UPDATE users
+SET password_hash = ?,
+    salt          = ?,
+    kdf_version   = ?,
+    updated_at    = CURRENT_TIMESTAMP
+WHERE tenant_id = ?
+  AND id = ?`,
+		user.PasswordHash, user.Salt, user.KdfVersion, tenantID, user.ID)
+	return err
+}
+
+func (r *sqliteUserRepo) SetCredentialBackend(ctx context.Context, username, backend string) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := sqliteConnFrom(ctx, r.db).ExecContext(ctx,
+		`UPDATE users
+SET credential_backend = NULLIF(?, ''),
+    updated_at         = CURRENT_TIMESTAMP
+WHERE tenant_id = ?
+  AND username = ?`,
+		backend, tenantID, username)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return model.ErrUserNotFound
+	}
+	return nil
+}
+
+// sqliteTimeLayout CURRENT_TIMESTAMP 的格式，时间按 UTC 保存，同一格式的字符串可以直接比较
+const sqliteTimeLayout = "2006-01-02 15:04:05"
+
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeLayout)
+}
+
+func parseSQLiteTime(s string) (time.Time, error) {
+	return time.ParseInLocation(sqliteTimeLayout, s, time.UTC)
+}
+
+type sqliteDeviceRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteDeviceRepo) ListDevices(ctx context.Context, userID int64) ([]*model.Device, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := sqliteConnFrom(ctx, r.db).QueryContext(ctx,
+		`SELECT id, fingerprint, device_id, user_agent, ip_prefix, location_hint, first_seen_at, last_seen_at
+FROM user_devices
+WHERE tenant_id = ?
+  AND user_id = ?
+ORDER BY last_seen_at DESC, id DESC`, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var devices []*model.Device
+	for rows.Next() {
+		device := &model.Device{UserID: userID}
+		var firstSeenAt, lastSeenAt string
+		if err := rows.Scan(&device.ID, &device.Fingerprint, &device.DeviceID, &device.UserAgent,
+			&device.IPPrefix, &device.LocationHint, &firstSeenAt, &lastSeenAt); err != nil {
+			return nil, err
+		}
+		if device.FirstSeenAt, err = parseSQLiteTime(firstSeenAt); err != nil {
+			return nil, err
+		}
+		if device.LastSeenAt, err = parseSQLiteTime(lastSeenAt); err != nil {
+			return nil, err
+		}
+		devices = append(devices, device)
+	}
+	return devices, rows.Err()
+}
+
+func (r *sqliteDeviceRepo) UpsertDevice(ctx context.Context, device *model.Device) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	var firstSeenAt, lastSeenAt string
+	err = sqliteConnFrom(ctx, r.db).QueryRowContext(ctx,
+		`INSERT INTO user_devices (tenant_id, user_id, fingerprint, device_id, user_agent, ip_prefix, location_hint)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (user_id, fingerprint) DO UPDATE
+    SET user_agent    = excluded.user_agent,
+        ip_prefix     = excluded.ip_prefix,
+        location_hint = excluded.location_hint,
+        last_seen_at  = CURRENT_TIMESTAMP
+RETURNING id, first_seen_at, last_seen_at`,
+		tenantID, device.UserID, device.Fingerprint, device.DeviceID, device.UserAgent, device.IPPrefix, device.LocationHint,
+	).Scan(&device.ID, &firstSeenAt, &lastSeenAt)
+	if err != nil {
+		return err
+	}
+	if device.FirstSeenAt, err = parseSQLiteTime(firstSeenAt); err != nil {
+		return err
+	}
+	device.LastSeenAt, err = parseSQLiteTime(lastSeenAt)
+	return err
+}
+
+func (r *sqliteDeviceRepo) CreateLoginEvent(ctx context.Context, event *model.LoginEvent) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	reasons := event.Reasons
+	if reasons == nil {
+		reasons = []string{}
+	}
+	encoded, err := json.Marshal(reasons)
+	if err != nil {
+		return err
+	}
+	_, err = sqliteConnFrom(ctx, r.db).ExecContext(ctx,
+		`INSERT INTO login_events (tenant_id, user_id, fingerprint, ip_prefix, risk_score, reasons, step_up)
+VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		tenantID, event.UserID, event.Fingerprint, event.IPPrefix, event.RiskScore, string(encoded), event.StepUp)
+	return err
+}
+
+func (r *sqliteDeviceRepo) ListLoginEvents(ctx context.Context, userID int64, since time.Time) ([]*model.LoginEvent, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := sqliteConnFrom(ctx, r.db).QueryContext(ctx,
+		`SELECT fingerprint, ip_prefix, risk_score, reasons, step_up, created_at
+FROM login_events
+WHERE tenant_id = ?
+  AND user_id = ?
+  AND created_at > ?
+ORDER BY created_at DESC, id DESC
+LIMIT 500`, tenantID, userID, sqliteTime(since))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*model.LoginEvent
+	for rows.Next() {
+		event := &model.LoginEvent{UserID: userID}
+		var reasons, createdAt string
+		if err := rows.Scan(&event.Fingerprint, &event.IPPrefix, &event.RiskScore, &reasons, &event.StepUp, &createdAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(reasons), &event.Reasons); err != nil {
+			return nil, err
+		}
+		if event.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+type sqliteInviteRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteInviteRepo) CreateInvite(ctx context.Context, invite *model.Invite) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	var createdAt string
+	err = sqliteConnFrom(ctx, r.db).QueryRowContext(ctx,
+		`INSERT INTO invites (tenant_id, inviter_id, max_uses, expires_at)
+VALUES (?, ?, ?, ?)
+RETURNING id, created_at`,
+		tenantID, invite.InviterID, invite.MaxUses, sqliteTime(invite.ExpiresAt),
+	).Scan(&invite.ID, &createdAt)
+	if err != nil {
+		return err
+	}
+	invite.TenantID = tenantID
+	invite.CreatedAt, err = parseSQLiteTime(createdAt)
+	return err
+}
+
+func (r *sqliteInviteRepo) RedeemInvite(ctx context.Context, id int64) (*model.Invite, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 条件更新保证并发注册时不会超出可用次数
+	row := sqliteConnFrom(ctx, r.db).QueryRowContext(ctx,
+		`UPDATE invites
+SET used_count = used_count + 1
+WHERE id = ?
+  AND tenant_id = ?
+  AND used_count < max_uses
+  AND expires_at > CURRENT_TIMESTAMP
+RETURNING id, inviter_id, max_uses, used_count, expires_at, created_at`, id, tenantID)
+	invite, err := scanSQLiteInvite(row.Scan, tenantID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrInviteUnavailable
+	}
+	return invite, err
+}
+
+func (r *sqliteInviteRepo) CreateInviteRedemption(ctx context.Context, inviteID, userID int64) error {
+	_, err := sqliteConnFrom(ctx, r.db).ExecContext(ctx,
+		"INSERT INTO invite_redemptions (invite_id, user_id) VALUES (?, ?)", inviteID, userID)
+	return err
+}
+
+func (r *sqliteInviteRepo) ListInvites(ctx context.Context) ([]*model.Invite, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := sqliteConnFrom(ctx, r.db).QueryContext(ctx,
+		`SELECT i.id,
+       i.inviter_id,
+       i.max_uses,
+       i.used_count,
+       i.expires_at,
+       i.created_at,
+       (SELECT json_group_array(user_id)
+        FROM (SELECT r.user_id FROM invite_redemptions r WHERE r.invite_id = i.id ORDER BY r.created_at, r.rowid))
+FROM invites i
+WHERE i.tenant_id = ?
+ORDER BY i.id DESC`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invites := make([]*model.Invite, 0)
+	for rows.Next() {
+		var invitees string
+		invite, err := scanSQLiteInvite(func(dest ...any) error {
+			return rows.Scan(append(dest, &invitees)...)
+		}, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(invitees), &invite.InviteeIDs); err != nil {
+			return nil, err
+		}
+		invites = append(invites, invite)
+	}
+	return invites, rows.Err()
+}
+
+// scanSQLiteInvite 读取 id, inviter_id, max_uses, used_count, expires_at, created_at
+func scanSQLiteInvite(scan func(dest ...any) error, tenantID int64) (*model.Invite, error) {
+	invite := &model.Invite{TenantID: tenantID}
+	var expiresAt, createdAt string
+	if err := scan(&invite.ID, &invite.InviterID, &invite.MaxUses, &invite.UsedCount, &expiresAt, &createdAt); err != nil {
+		return nil, err
+	}
+	var err error
+	if invite.ExpiresAt, err = parseSQLiteTime(expiresAt); err != nil {
+		return nil, err
+	}
+	if invite.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return nil, err
+	}
+	return invite, nil
+}
+
+type sqliteTenantRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteTenantRepo) GetTenantBySlug(ctx context.Context, slug string) (*model.Tenant, error) {
+	return r.get(ctx, "SELECT id, slug, name FROM tenants WHERE slug = ?", slug)
+}
+
+func (r *sqliteTenantRepo) GetTenantByClientID(ctx context.Context, clientID string) (*model.Tenant, error) {
+	return r.get(ctx, `SELECT t.id, t.slug, t.name
+FROM tenants t
+         JOIN tenant_clients c ON c.tenant_id = t.id
+WHERE c.client_id = ?`, clientID)
+}
+
+func (r *sqliteTenantRepo) get(ctx context.Context, query string, arg any) (*model.Tenant, error) {
+	var t model.Tenant
+	err := sqliteConnFrom(ctx, r.db).QueryRowContext(ctx, query, arg).Scan(&t.ID, &t.Slug, &t.Name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrTenantNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// sqliteError 唯一约束冲突转换为 ErrUserConflict
+func sqliteError(err error) error {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE {
+		return model.ErrUserConflict
+	}
+	return err
+}
@@ -0,0 +1,196 @@
+-- sqlite 驱动使用的表结构，与 schema 目录中全部迁移执行后的表和列一致，由 TestSQLiteSchema 检查。
+-- 类型按 SQLite 的习惯转换：SERIAL 为 INTEGER PRIMARY KEY，timestamptz 为 TEXT，数组和 JSONB 为 JSON 文本，BYTEA 为 BLOB。
+-- 本地开发驱动不加密个人信息字段，邮箱等以明文 TEXT 保存，邮箱唯一约束直接建在 email 上，email_index 始终为空。
+-- 短期状态保存在进程内存中，state_entries 只为保持表结构一致。
+-- 表结构变化后删除数据库文件即可重新创建
+CREATE TABLE IF NOT EXISTS tenants
+(
+    id         INTEGER PRIMARY KEY,
+    slug       TEXT UNIQUE                        NOT NULL,
+    name       TEXT                               NOT NULL,
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP     NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS tenant_clients
+(
+    client_id TEXT PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenants (id) ON DELETE CASCADE
+);
+
+INSERT OR IGNORE INTO tenants (id, slug, name)
+VALUES (1, 'default', 'Default');
+
+CREATE TABLE IF NOT EXISTS users
+(
+    id                 INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id          INTEGER                           NOT NULL REFERENCES tenants (id),
+    username           TEXT                              NOT NULL,
+    password_hash      TEXT                              NOT NULL,
+    salt               TEXT                              NOT NULL,
+    kdf_version        INTEGER DEFAULT 0                 NOT NULL,
+    email              TEXT,
+    email_index        BLOB,
+    email_verified     BOOLEAN DEFAULT 0                 NOT NULL,
+    external_id        TEXT,
+    display_name       TEXT    DEFAULT ''                NOT NULL,
+    given_name         TEXT    DEFAULT ''                NOT NULL,
+    family_name        TEXT    DEFAULT ''                NOT NULL,
+    active             BOOLEAN DEFAULT 1                 NOT NULL,
+    credential_backend TEXT,
+    created_at         TEXT    DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at         TEXT    DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (tenant_id, username),
+    UNIQUE (tenant_id, email)
+);
+
+CREATE TABLE IF NOT EXISTS user_roles
+(
+    user_id    INTEGER                        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role       TEXT                           NOT NULL,
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, role)
+);
+
+CREATE TABLE IF NOT EXISTS invites
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id  INTEGER                        NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    inviter_id INTEGER                        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    max_uses   INTEGER                        NOT NULL,
+    used_count INTEGER DEFAULT 0              NOT NULL,
+    expires_at TEXT                           NOT NULL,
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS invite_redemptions
+(
+    invite_id  INTEGER                        NOT NULL REFERENCES invites (id) ON DELETE CASCADE,
+    user_id    INTEGER                        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (invite_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS user_devices
+(
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id     INTEGER                        NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    user_id       INTEGER                        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    fingerprint   TEXT                           NOT NULL,
+    device_id     TEXT DEFAULT ''                NOT NULL,
+    user_agent    TEXT DEFAULT ''                NOT NULL,
+    ip_prefix     TEXT DEFAULT ''                NOT NULL,
+    location_hint TEXT DEFAULT ''                NOT NULL,
+    first_seen_at TEXT DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    last_seen_at  TEXT DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (user_id, fingerprint)
+);
+
+CREATE TABLE IF NOT EXISTS login_events
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id   INTEGER                           NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    user_id     INTEGER                           NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    fingerprint TEXT                              NOT NULL,
+    ip_prefix   TEXT    DEFAULT ''                NOT NULL,
+    risk_score  INTEGER DEFAULT 0                 NOT NULL,
+    reasons     TEXT    DEFAULT '[]'              NOT NULL,
+    step_up     BOOLEAN DEFAULT 0                 NOT NULL,
+    created_at  TEXT    DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS login_events_user_created_at_idx ON login_events (user_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS user_groups
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id    INTEGER                        NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    display_name TEXT                           NOT NULL,
+    external_id  TEXT,
+    created_at   TEXT DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at   TEXT DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (tenant_id, display_name)
+);
+
+CREATE TABLE IF NOT EXISTS group_members
+(
+    group_id   INTEGER                        NOT NULL REFERENCES user_groups (id) ON DELETE CASCADE,
+    user_id    INTEGER                        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (group_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS group_members_user_id_idx ON group_members (user_id);
+
+CREATE TABLE IF NOT EXISTS user_identities
+(
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id     INTEGER                        NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    user_id       INTEGER                        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    provider      TEXT                           NOT NULL,
+    subject       TEXT                           NOT NULL,
+    email         TEXT,
+    created_at    TEXT DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    last_login_at TEXT DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (tenant_id, provider, subject),
+    UNIQUE (user_id, provider)
+);
+
+CREATE TABLE IF NOT EXISTS state_entries
+(
+    key        TEXT PRIMARY KEY,
+    value      TEXT NOT NULL,
+    expires_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS state_entries_expires_at_idx ON state_entries (expires_at);
+
+CREATE TABLE IF NOT EXISTS outbox
+(
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id      INTEGER                        NOT NULL,
+    aggregate_type TEXT                           NOT NULL,
+    aggregate_id   TEXT                           NOT NULL,
+    event_type     TEXT                           NOT NULL,
+    payload        TEXT                           NOT NULL,
+    created_at     TEXT DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    published_at   TEXT
+);
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (id) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_published_at_idx ON outbox (published_at) WHERE published_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id  INTEGER                        NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    url        TEXT                           NOT NULL,
+    events     TEXT                           NOT NULL,
+    secret     TEXT                           NOT NULL,
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS webhook_subscriptions_tenant_id_idx ON webhook_subscriptions (tenant_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries
+(
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id       INTEGER                           NOT NULL,
+    subscription_id INTEGER                           NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    event_id        INTEGER                           NOT NULL,
+    event_type      TEXT                              NOT NULL,
+    payload         TEXT                              NOT NULL,
+    status          TEXT    DEFAULT 'pending'         NOT NULL,
+    attempts        INTEGER DEFAULT 0                 NOT NULL,
+    next_attempt_at TEXT    DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    last_attempt_at TEXT,
+    response_status INTEGER,
+    last_error      TEXT,
+    delivered_at    TEXT,
+    created_at      TEXT    DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (subscription_id, event_id)
+);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS data_keys
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    purpose     TEXT                           NOT NULL,
+    kek_id      TEXT                           NOT NULL,
+    wrapped_key BLOB                           NOT NULL,
+    created_at  TEXT DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
@@ -0,0 +1,334 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data/models"
+	"connect-go-example/internal/pkg/breaker"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// data.state_store.fallback 的取值
+const (
+	StateFallbackMemory   = "memory"
+	StateFallbackPostgres = "postgres"
+	StateFallbackNone     = "none"
+)
+
+// ErrStateNotFound 键不存在或已过期
+var ErrStateNotFound = errors.New("state not found")
+
+// StateStore 保存登录挑战等带过期时间的短期状态
+type StateStore interface {
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	Get(ctx context.Context, key string) (string, error)
+	// GetDel 取出并删除，保证值只被使用一次
+	GetDel(ctx context.Context, key string) (string, error)
+	Del(ctx context.Context, key string) error
+	// SetNX 仅当键不存在时写入，返回是否写入，用于标记一次性的值已使用
+	SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	// Incr 计数加一并返回累计次数，ttl 只在计数开始时设置
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+}
+
+// NewStateStore 本地开发驱动只使用内存；否则以 Redis 为主，按配置降级到备用存储
+func NewStateStore(data *Data, cfg *conf.Bootstrap, logger *zap.Logger) (StateStore, error) {
+	if data.driver != DriverPostgres {
+		return newMemoryStateStore(), nil
+	}
+
+	primary := &redisStateStore{rdb: data.rdb}
+	var fallback StateStore
+	fallbackName := stateFallbackOf(cfg)
+	switch fallbackName {
+	case StateFallbackNone:
+		return primary, nil
+	case StateFallbackMemory:
+		fallback = newMemoryStateStore()
+	case StateFallbackPostgres:
+		// UNLOGGED 表不复制到只读副本，不能经过 DBRouter
+		fallback = &postgresStateStore{queries: models.New(data.db)}
+	default:
+		return nil, fmt.Errorf("unknown state store fallback %q", fallbackName)
+	}
+
+	storeCfg := cfg.GetData().GetStateStore()
+	threshold := 5 // 默认连续失败5次
+	if storeCfg.GetFailureThreshold() > 0 {
+		threshold = int(storeCfg.GetFailureThreshold())
+	}
+	openTimeout := 30 * time.Second // 默认30秒后重试 Redis
+	if storeCfg.GetOpenSeconds() > 0 {
+		openTimeout = time.Duration(storeCfg.GetOpenSeconds()) * time.Second
+	}
+	return &failoverStateStore{
+		primary:  primary,
+		fallback: fallback,
+		breaker: breaker.New(threshold, openTimeout, func(from, to breaker.State) {
+			logger.Warn("State store circuit breaker changed",
+				zap.Stringer("from", from), zap.Stringer("to", to), zap.String("fallback", fallbackName))
+		}),
+		l: logger,
+	}, nil
+}
+
+// stateFallbackOf 返回配置的备用存储，未配置时为 memory
+func stateFallbackOf(cfg *conf.Bootstrap) string {
+	if fallback := cfg.GetData().GetStateStore().GetFallback(); fallback != "" {
+		return fallback
+	}
+	return StateFallbackMemory
+}
+
+// failoverStateStore 熔断器闭合时读写 Redis，断开时读写备用存储。
+// Redis 恢复后备用存储中未过期的值仍然可以取出，切换期间签发的挑战不会失效
+type failoverStateStore struct {
+	primary  StateStore
+	fallback StateStore
+	breaker  *breaker.Breaker
+	l        *zap.Logger
+}
+
+func (s *failoverStateStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	if s.breaker.Allow() && s.record(ctx, s.primary.Set(ctx, key, value, ttl)) {
+		return nil
+	}
+	return s.fallback.Set(ctx, key, value, ttl)
+}
+
+func (s *failoverStateStore) Get(ctx context.Context, key string) (string, error) {
+	return s.read(ctx, key, StateStore.Get)
+}
+
+func (s *failoverStateStore) GetDel(ctx context.Context, key string) (string, error) {
+	return s.read(ctx, key, StateStore.GetDel)
+}
+
+func (s *failoverStateStore) read(ctx context.Context, key string, get func(StateStore, context.Context, string) (string, error)) (string, error) {
+	if s.breaker.Allow() {
+		value, err := get(s.primary, ctx, key)
+		if s.record(ctx, err) {
+			return value, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+	}
+	return get(s.fallback, ctx, key)
+}
+
+func (s *failoverStateStore) Del(ctx context.Context, key string) error {
+	if s.breaker.Allow() {
+		s.record(ctx, s.primary.Del(ctx, key))
+	}
+	return s.fallback.Del(ctx, key)
+}
+
+// SetNX 备用存储中可能有故障期间写入的同名键，Redis 写入成功后还要确认备用存储中没有，
+// 备用存储不可用时返回错误，不会把一次性的值当作未使用
+func (s *failoverStateStore) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	if s.breaker.Allow() {
+		ok, err := s.primary.SetNX(ctx, key, value, ttl)
+		if s.record(ctx, err) {
+			if !ok {
+				return false, nil
+			}
+			exists, err := s.fallback.Exists(ctx, key)
+			if err != nil {
+				return false, err
+			}
+			return !exists, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+	}
+	return s.fallback.SetNX(ctx, key, value, ttl)
+}
+
+// Exists Redis 中不存在时再查备用存储，故障期间吊销的会话在恢复后仍然有效
+func (s *failoverStateStore) Exists(ctx context.Context, key string) (bool, error) {
+	if s.breaker.Allow() {
+		ok, err := s.primary.Exists(ctx, key)
+		if s.record(ctx, err) && ok {
+			return true, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+	}
+	return s.fallback.Exists(ctx, key)
+}
+
+// Incr Redis 可用时加上备用存储中故障期间的计数，切换期间的计数不会丢失；
+// 故障期间只在备用存储中计数，此前写在 Redis 中的计数暂时读不到
+func (s *failoverStateStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	if s.breaker.Allow() {
+		n, err := s.primary.Incr(ctx, key, ttl)
+		if s.record(ctx, err) {
+			value, err := s.fallback.Get(ctx, key)
+			if errors.Is(err, ErrStateNotFound) {
+				return n, nil
+			}
+			if err != nil {
+				return 0, err
+			}
+			m, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return 0, err
+			}
+			return n + m, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+	}
+	return s.fallback.Incr(ctx, key, ttl)
+}
+
+// record 按 Redis 的返回结果更新熔断器，返回是否已从 Redis 得到结果；
+// 键不存在说明 Redis 可用，但值可能写在备用存储中；请求被取消不计为 Redis 故障
+func (s *failoverStateStore) record(ctx context.Context, err error) bool {
+	switch {
+	case err == nil:
+		s.breaker.Success()
+		return true
+	case errors.Is(err, ErrStateNotFound):
+		s.breaker.Success()
+		return false
+	case ctx.Err() != nil:
+		return false
+	}
+	s.l.Warn("Redis state store failed, using fallback", zap.Error(err))
+	s.breaker.Failure()
+	return false
+}
+
+// Degraded 返回是否正在使用备用存储
+func (s *failoverStateStore) Degraded() bool {
+	return s.breaker.State() != breaker.Closed
+}
+
+type redisStateStore struct {
+	rdb redis.UniversalClient
+}
+
+func (s *redisStateStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return s.rdb.Set(ctx, key, value, ttl).Err()
+}
+
+func (s *redisStateStore) Get(ctx context.Context, key string) (string, error) {
+	return redisStateValue(s.rdb.Get(ctx, key).Result())
+}
+
+func (s *redisStateStore) GetDel(ctx context.Context, key string) (string, error) {
+	return redisStateValue(s.rdb.GetDel(ctx, key).Result())
+}
+
+func (s *redisStateStore) Del(ctx context.Context, key string) error {
+	return s.rdb.Del(ctx, key).Err()
+}
+
+func (s *redisStateStore) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	return s.rdb.SetNX(ctx, key, value, ttl).Result()
+}
+
+func (s *redisStateStore) Exists(ctx context.Context, key string) (bool, error) {
+	n, err := s.rdb.Exists(ctx, key).Result()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (s *redisStateStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	var incr *redis.IntCmd
+	_, err := s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		// 只在计数开始时设置过期时间
+		pipe.ExpireNX(ctx, key, ttl)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+func redisStateValue(value string, err error) (string, error) {
+	if errors.Is(err, redis.Nil) {
+		return "", ErrStateNotFound
+	}
+	return value, err
+}
+
+type postgresStateStore struct {
+	queries *models.Queries
+}
+
+func (s *postgresStateStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	// 只在降级期间写入，顺带清理过期的状态
+	if err := s.queries.PurgeStateEntries(ctx); err != nil {
+		return err
+	}
+	return s.queries.SetStateEntry(ctx, models.SetStateEntryParams{
+		Key:       key,
+		Value:     value,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+}
+
+func (s *postgresStateStore) Get(ctx context.Context, key string) (string, error) {
+	return postgresStateValue(s.queries.GetStateEntry(ctx, key))
+}
+
+func (s *postgresStateStore) GetDel(ctx context.Context, key string) (string, error) {
+	return postgresStateValue(s.queries.TakeStateEntry(ctx, key))
+}
+
+func (s *postgresStateStore) Del(ctx context.Context, key string) error {
+	return s.queries.DeleteStateEntry(ctx, key)
+}
+
+func (s *postgresStateStore) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	n, err := s.queries.SetStateEntryNX(ctx, models.SetStateEntryNXParams{
+		Key:       key,
+		Value:     value,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (s *postgresStateStore) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := s.Get(ctx, key)
+	if errors.Is(err, ErrStateNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *postgresStateStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	return s.queries.IncrStateEntry(ctx, models.IncrStateEntryParams{
+		Key:       key,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+}
+
+func postgresStateValue(value string, err error) (string, error) {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrStateNotFound
+	}
+	return value, err
+}
@@ -0,0 +1,220 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/pkg/breaker"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// flakyStateStore 在 down 为 true 时模拟 Redis 不可用
+type flakyStateStore struct {
+	*memoryStateStore
+	down  bool
+	calls int
+}
+
+var errRedisDown = errors.New("dial tcp: connection refused")
+
+func (s *flakyStateStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	s.calls++
+	if s.down {
+		return errRedisDown
+	}
+	return s.memoryStateStore.Set(ctx, key, value, ttl)
+}
+
+func (s *flakyStateStore) GetDel(ctx context.Context, key string) (string, error) {
+	s.calls++
+	if s.down {
+		return "", errRedisDown
+	}
+	return s.memoryStateStore.GetDel(ctx, key)
+}
+
+func (s *flakyStateStore) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	s.calls++
+	if s.down {
+		return false, errRedisDown
+	}
+	return s.memoryStateStore.SetNX(ctx, key, value, ttl)
+}
+
+func (s *flakyStateStore) Exists(ctx context.Context, key string) (bool, error) {
+	s.calls++
+	if s.down {
+		return false, errRedisDown
+	}
+	return s.memoryStateStore.Exists(ctx, key)
+}
+
+func (s *flakyStateStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	s.calls++
+	if s.down {
+		return 0, errRedisDown
+	}
+	return s.memoryStateStore.Incr(ctx, key, ttl)
+}
+
+func newTestFailoverStore() (*failoverStateStore, *flakyStateStore) {
+	primary := &flakyStateStore{memoryStateStore: newMemoryStateStore()}
+	return &failoverStateStore{
+		primary:  primary,
+		fallback: newMemoryStateStore(),
+		breaker:  breaker.New(2, time.Hour, nil),
+		l:        zap.NewNop(),
+	}, primary
+}
+
+func TestFailoverStateStore(t *testing.T) {
+	ctx := context.Background()
+	store, primary := newTestFailoverStore()
+
+	assert.NoError(t, store.Set(ctx, "a", "1", time.Minute))
+	value, err := store.GetDel(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", value)
+	_, err = store.GetDel(ctx, "a")
+	assert.ErrorIs(t, err, ErrStateNotFound)
+	assert.False(t, store.Degraded())
+
+	// Redis 故障时写入备用存储，连续失败达到阈值后不再请求 Redis
+	primary.down = true
+	assert.NoError(t, store.Set(ctx, "b", "2", time.Minute))
+	assert.NoError(t, store.Set(ctx, "c", "3", time.Minute))
+	assert.True(t, store.Degraded())
+	calls := primary.calls
+	value, err = store.GetDel(ctx, "b")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", value)
+	assert.Equal(t, calls, primary.calls)
+}
+
+func TestFailoverStateStore_Recovery(t *testing.T) {
+	ctx := context.Background()
+	store, primary := newTestFailoverStore()
+	store.breaker = breaker.New(1, 0, nil)
+
+	primary.down = true
+	assert.NoError(t, store.Set(ctx, "a", "1", time.Minute))
+	assert.True(t, store.Degraded())
+
+	// Redis 恢复后，故障期间写入备用存储的值仍然可以取出一次
+	primary.down = false
+	value, err := store.GetDel(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", value)
+	assert.False(t, store.Degraded())
+	_, err = store.GetDel(ctx, "a")
+	assert.ErrorIs(t, err, ErrStateNotFound)
+
+	assert.NoError(t, store.Set(ctx, "b", "2", time.Minute))
+	_, err = store.fallback.Get(ctx, "b")
+	assert.ErrorIs(t, err, ErrStateNotFound)
+}
+
+func TestFailoverStateStore_OneTimeValues(t *testing.T) {
+	ctx := context.Background()
+	store, primary := newTestFailoverStore()
+	store.breaker = breaker.New(1, 0, nil)
+
+	// 故障期间写入备用存储的吊销记录、使用记录和计数在 Redis 恢复后仍然有效
+	primary.down = true
+	assert.NoError(t, store.Set(ctx, "revoked", "1", time.Minute))
+	first, err := store.SetNX(ctx, "jti", "1", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, first)
+	n, err := store.Incr(ctx, "attempts", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	primary.down = false
+	revoked, err := store.Exists(ctx, "revoked")
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	first, err = store.SetNX(ctx, "jti", "1", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, first)
+	n, err = store.Incr(ctx, "attempts", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.False(t, store.Degraded())
+
+	first, err = store.SetNX(ctx, "other", "1", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, first)
+	first, err = store.SetNX(ctx, "other", "1", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, first)
+	revoked, err = store.Exists(ctx, "missing")
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestMemoryStateStore_Incr(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStateStore()
+
+	for i := range 3 {
+		n, err := store.Incr(ctx, "a", time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(i+1), n)
+	}
+
+	// 过期后从1重新计数
+	_, err := store.Incr(ctx, "b", time.Nanosecond)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	n, err := store.Incr(ctx, "b", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+func TestFailoverStateStore_CanceledNotCounted(t *testing.T) {
+	store, primary := newTestFailoverStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	primary.down = true
+	for range 3 {
+		_, err := store.GetDel(ctx, "a")
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+	assert.False(t, store.Degraded())
+}
+
+func TestNewStateStore(t *testing.T) {
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+	defer rdb.Close()
+	data := &Data{driver: DriverPostgres, rdb: rdb}
+	newStore := func(fallback string) (StateStore, error) {
+		return NewStateStore(data, &conf.Bootstrap{Data: &conf.Data{StateStore: &conf.Data_StateStore{Fallback: fallback}}}, zap.NewNop())
+	}
+
+	store, err := newStore("")
+	assert.NoError(t, err)
+	assert.IsType(t, &memoryStateStore{}, store.(*failoverStateStore).fallback)
+
+	store, err = newStore(StateFallbackPostgres)
+	assert.NoError(t, err)
+	assert.IsType(t, &postgresStateStore{}, store.(*failoverStateStore).fallback)
+
+	store, err = newStore(StateFallbackNone)
+	assert.NoError(t, err)
+	assert.IsType(t, &redisStateStore{}, store)
+
+	_, err = newStore("etcd")
+	assert.Error(t, err)
+
+	// 本地开发驱动不使用 Redis
+	store, err = NewStateStore(&Data{driver: DriverMemory}, &conf.Bootstrap{}, zap.NewNop())
+	assert.NoError(t, err)
+	assert.IsType(t, &memoryStateStore{}, store)
+}
@@ -0,0 +1,151 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	"connect-go-example/internal/pkg/fieldcrypt"
+
+	"go.uber.org/zap"
+)
+
+// StepUpRepo 二次验证数据访问接口
+type StepUpRepo interface {
+	CreateStepUp(ctx context.Context, stepUp *model.StepUp) error
+	GetStepUp(ctx context.Context, id string) (*model.StepUp, error)
+	// DeleteStepUp 删除记录，返回是否由本次调用删除，保证验证码只能使用一次
+	DeleteStepUp(ctx context.Context, id string) (bool, error)
+	// CountStepUpAttempt 记录一次验证尝试，并返回累计次数
+	CountStepUpAttempt(ctx context.Context, id string, ttl time.Duration) (int64, error)
+}
+
+// stepUpRepo 记录和尝试次数保存在 StateStore 中；存储不可用时返回错误，登录要求二次验证时失败
+type stepUpRepo struct {
+	states  StateStore
+	keyring *fieldcrypt.Keyring
+	l       *zap.Logger
+}
+
+// stepUpRecord StateStore 中保存的二次验证记录
+type stepUpRecord struct {
+	TenantID int64  `json:"tenant_id"`
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+	// Email 加密后保存，见 sealStateField
+	Email         fieldcrypt.Ciphertext `json:"email_enc,omitempty"`
+	CodeHash      string                `json:"code_hash"`
+	AuthRequestID string                `json:"auth_request_id"`
+	Fingerprint   string                `json:"fingerprint"`
+	DeviceID      string                `json:"device_id"`
+	UserAgent     string                `json:"user_agent"`
+	IPPrefix      string                `json:"ip_prefix"`
+	LocationHint  string                `json:"location_hint"`
+	RiskScore     int32                 `json:"risk_score"`
+	Reasons       []string              `json:"reasons"`
+	CreatedAt     int64                 `json:"created_at"`
+	ExpiresAt     int64                 `json:"expires_at"`
+}
+
+func NewStepUpRepo(states StateStore, keyring *fieldcrypt.Keyring, logger *zap.Logger) StepUpRepo {
+	return &stepUpRepo{
+		states:  states,
+		keyring: keyring,
+		l:       logger,
+	}
+}
+
+func stepUpKey(id string) string {
+	return fmt.Sprintf("step_up:{%s}", id)
+}
+
+func stepUpAttemptKey(id string) string {
+	return fmt.Sprintf("step_up_attempt:{%s}", id)
+}
+
+func (r *stepUpRepo) CreateStepUp(ctx context.Context, stepUp *model.StepUp) error {
+	email, err := sealStateField(r.keyring, fieldStepUpEmail, stepUp.TenantID, stepUp.ID, stepUp.Email)
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal(stepUpRecord{
+		TenantID:      stepUp.TenantID,
+		UserID:        stepUp.UserID,
+		Username:      stepUp.Username,
+		Email:         email,
+		CodeHash:      stepUp.CodeHash,
+		AuthRequestID: stepUp.AuthRequestID,
+		Fingerprint:   stepUp.Device.Fingerprint,
+		DeviceID:      stepUp.Device.DeviceID,
+		UserAgent:     stepUp.Device.UserAgent,
+		IPPrefix:      stepUp.Device.IPPrefix,
+		LocationHint:  stepUp.Device.LocationHint,
+		RiskScore:     stepUp.RiskScore,
+		Reasons:       stepUp.Reasons,
+		CreatedAt:     stepUp.CreatedAt.Unix(),
+		ExpiresAt:     stepUp.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	return r.states.Set(ctx, stepUpKey(stepUp.ID), string(value), time.Until(stepUp.ExpiresAt))
+}
+
+func (r *stepUpRepo) GetStepUp(ctx context.Context, id string) (*model.StepUp, error) {
+	value, err := r.states.Get(ctx, stepUpKey(id))
+	if err != nil {
+		if errors.Is(err, ErrStateNotFound) {
+			return nil, model.ErrStepUpNotFound
+		}
+		return nil, err
+	}
+
+	var record stepUpRecord
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return nil, err
+	}
+	email, err := openStateField(r.keyring, fieldStepUpEmail, record.TenantID, id, record.Email)
+	if err != nil {
+		return nil, err
+	}
+	return &model.StepUp{
+		ID:            id,
+		TenantID:      record.TenantID,
+		UserID:        record.UserID,
+		Username:      record.Username,
+		Email:         email,
+		CodeHash:      record.CodeHash,
+		AuthRequestID: record.AuthRequestID,
+		Device: model.Device{
+			UserID:       record.UserID,
+			Fingerprint:  record.Fingerprint,
+			DeviceID:     record.DeviceID,
+			UserAgent:    record.UserAgent,
+			IPPrefix:     record.IPPrefix,
+			LocationHint: record.LocationHint,
+		},
+		RiskScore: record.RiskScore,
+		Reasons:   record.Reasons,
+		CreatedAt: time.Unix(record.CreatedAt, 0),
+		ExpiresAt: time.Unix(record.ExpiresAt, 0),
+	}, nil
+}
+
+// DeleteStepUp 先取出记录，保证并发验证时只有一个调用返回 true
+func (r *stepUpRepo) DeleteStepUp(ctx context.Context, id string) (bool, error) {
+	_, err := r.states.GetDel(ctx, stepUpKey(id))
+	if err != nil && !errors.Is(err, ErrStateNotFound) {
+		return false, err
+	}
+	if err := r.states.Del(ctx, stepUpAttemptKey(id)); err != nil {
+		r.l.Warn("delete step-up attempts failed", zap.String("id", id), zap.Error(err))
+	}
+	return err == nil, nil
+}
+
+func (r *stepUpRepo) CountStepUpAttempt(ctx context.Context, id string, ttl time.Duration) (int64, error) {
+	return r.states.Incr(ctx, stepUpAttemptKey(id), ttl)
+}
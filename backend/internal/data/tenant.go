@@ -0,0 +1,68 @@
+package data
+
+import (
+	"context"
+	"errors"
+
+	"connect-go-example/internal/biz/model"
+	"connect-go-example/internal/data/models"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// TenantRepo 租户数据访问接口
+type TenantRepo interface {
+	GetTenantBySlug(ctx context.Context, slug string) (*model.Tenant, error)
+	GetTenantByClientID(ctx context.Context, clientID string) (*model.Tenant, error)
+}
+
+type tenantRepo struct {
+	queries *models.Queries
+	l       *zap.Logger
+}
+
+func NewTenantRepo(data *Data, logger *zap.Logger) TenantRepo {
+	switch data.driver {
+	case DriverMemory:
+		return memoryTenantRepo{}
+	case DriverSQLite:
+		return &sqliteTenantRepo{db: data.sqlite}
+	}
+	return &tenantRepo{
+		queries: models.New(data.query),
+		l:       logger,
+	}
+}
+
+func (r *tenantRepo) GetTenantBySlug(ctx context.Context, slug string) (*model.Tenant, error) {
+	t, err := withTx(ctx, r.queries).GetTenantBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrTenantNotFound
+		}
+		return nil, err
+	}
+
+	return &model.Tenant{
+		ID:   int64(t.ID),
+		Slug: t.Slug,
+		Name: t.Name,
+	}, nil
+}
+
+func (r *tenantRepo) GetTenantByClientID(ctx context.Context, clientID string) (*model.Tenant, error) {
+	t, err := withTx(ctx, r.queries).GetTenantByClientID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrTenantNotFound
+		}
+		return nil, err
+	}
+
+	return &model.Tenant{
+		ID:   int64(t.ID),
+		Slug: t.Slug,
+		Name: t.Name,
+	}, nil
+}
@@ -0,0 +1,108 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"connect-go-example/internal/data/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxTxAttempts 序列化失败或死锁时最多执行的次数
+	maxTxAttempts = 3
+	// txRetryBackoff 重试前等待的基准时间，每次重试递增并加上随机抖动
+	txRetryBackoff = 20 * time.Millisecond
+)
+
+// Transactor 在数据库事务中执行 fn，fn 内通过 ctx 调用的仓库方法都使用同一个事务
+type Transactor interface {
+	// WithinTx fn 返回错误时回滚；已在事务中时使用保存点，序列化失败时整个事务由最外层重试，fn 可能执行多次
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txKey struct{}
+
+// afterCommitKey 最外层事务提交后执行的回调
+type afterCommitKey struct{}
+
+// txBeginner 由 *pgxpool.Pool 实现
+type txBeginner interface {
+	BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error)
+}
+
+type transactor struct {
+	db txBeginner
+	l  *zap.Logger
+}
+
+func NewTransactor(data *Data, logger *zap.Logger) Transactor {
+	switch data.driver {
+	case DriverMemory:
+		return memoryTransactor{}
+	case DriverSQLite:
+		return &sqliteTransactor{db: data.sqlite}
+	}
+	return &transactor{db: data.db, l: logger}
+}
+
+func (t *transactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return pgx.BeginFunc(ctx, tx, func(savepoint pgx.Tx) error {
+			return fn(context.WithValue(ctx, txKey{}, savepoint))
+		})
+	}
+
+	for attempt := 1; ; attempt++ {
+		var hooks []func()
+		err := pgx.BeginTxFunc(ctx, t.db, pgx.TxOptions{}, func(tx pgx.Tx) error {
+			return fn(context.WithValue(context.WithValue(ctx, txKey{}, tx), afterCommitKey{}, &hooks))
+		})
+		if err == nil {
+			for _, hook := range hooks {
+				hook()
+			}
+			return nil
+		}
+		if attempt >= maxTxAttempts || !isRetryableTxError(err) {
+			return err
+		}
+
+		backoff := time.Duration(attempt)*txRetryBackoff + rand.N(txRetryBackoff)
+		t.l.Warn("Retrying transaction", zap.Int("attempt", attempt), zap.Duration("backoff", backoff), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// isRetryableTxError 序列化失败和死锁时重新执行整个事务可以成功
+func isRetryableTxError(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+}
+
+// afterCommit 在 ctx 中的事务提交后执行 fn，不在事务中时立即执行；事务回滚时不执行，
+// 保存点回滚时仍会执行，fn 应当可以多余地执行。用于清除缓存等必须在其他连接能读到新数据之后才做的操作
+func afterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(afterCommitKey{}).(*[]func()); ok {
+		*hooks = append(*hooks, fn)
+		return
+	}
+	fn()
+}
+
+// withTx ctx 中有事务时返回在该事务中执行的查询
+func withTx(ctx context.Context, q *models.Queries) *models.Queries {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return q.WithTx(tx)
+	}
+	return q
+}
@@ -0,0 +1,176 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"connect-go-example/internal/data/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fakeTx 记录提交、回滚和保存点，其余方法不会被调用
+type fakeTx struct {
+	pgx.Tx
+	committed  bool
+	rolledBack bool
+	savepoints int
+}
+
+func (tx *fakeTx) Begin(context.Context) (pgx.Tx, error) {
+	tx.savepoints++
+	return &fakeTx{}, nil
+}
+
+func (tx *fakeTx) Commit(context.Context) error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(context.Context) error {
+	if !tx.committed {
+		tx.rolledBack = true
+	}
+	return nil
+}
+
+type fakeBeginner struct {
+	txs []*fakeTx
+}
+
+func (b *fakeBeginner) BeginTx(context.Context, pgx.TxOptions) (pgx.Tx, error) {
+	tx := &fakeTx{}
+	b.txs = append(b.txs, tx)
+	return tx, nil
+}
+
+func newTestTransactor() (*transactor, *fakeBeginner) {
+	db := &fakeBeginner{}
+	return &transactor{db: db, l: zap.NewNop()}, db
+}
+
+func TestWithinTx_Commit(t *testing.T) {
+	tr, db := newTestTransactor()
+
+	err := tr.WithinTx(context.Background(), func(ctx context.Context) error {
+		// 仓库方法从 ctx 中取得事务
+		q := models.New(nil)
+		assert.NotSame(t, q, withTx(ctx, q))
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, db.txs, 1)
+	assert.True(t, db.txs[0].committed)
+}
+
+func TestWithinTx_Rollback(t *testing.T) {
+	tr, db := newTestTransactor()
+	errFailed := errors.New("failed")
+
+	err := tr.WithinTx(context.Background(), func(context.Context) error { return errFailed })
+
+	assert.ErrorIs(t, err, errFailed)
+	assert.Len(t, db.txs, 1)
+	assert.True(t, db.txs[0].rolledBack)
+}
+
+func TestWithinTx_NestedUsesSavepoint(t *testing.T) {
+	tr, db := newTestTransactor()
+	errInner := errors.New("inner failed")
+
+	err := tr.WithinTx(context.Background(), func(ctx context.Context) error {
+		outer := ctx.Value(txKey{})
+		err := tr.WithinTx(ctx, func(ctx context.Context) error {
+			assert.NotSame(t, outer, ctx.Value(txKey{}))
+			return errInner
+		})
+		// 内层失败只回滚到保存点，外层可以继续
+		assert.ErrorIs(t, err, errInner)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, db.txs, 1)
+	assert.Equal(t, 1, db.txs[0].savepoints)
+	assert.True(t, db.txs[0].committed)
+}
+
+func TestAfterCommit(t *testing.T) {
+	tr, _ := newTestTransactor()
+	var ran []string
+
+	afterCommit(context.Background(), func() { ran = append(ran, "no tx") })
+	assert.Equal(t, []string{"no tx"}, ran)
+
+	err := tr.WithinTx(context.Background(), func(ctx context.Context) error {
+		afterCommit(ctx, func() { ran = append(ran, "outer") })
+		_ = tr.WithinTx(ctx, func(ctx context.Context) error {
+			afterCommit(ctx, func() { ran = append(ran, "inner") })
+			return nil
+		})
+		// 提交前不执行
+		assert.Len(t, ran, 1)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"no tx", "outer", "inner"}, ran)
+
+	// 回滚时不执行
+	_ = tr.WithinTx(context.Background(), func(ctx context.Context) error {
+		afterCommit(ctx, func() { ran = append(ran, "rolled back") })
+		return errors.New("failed")
+	})
+	assert.Len(t, ran, 3)
+}
+
+func TestWithinTx_RetriesSerializationFailure(t *testing.T) {
+	tr, db := newTestTransactor()
+	attempts := 0
+
+	err := tr.WithinTx(context.Background(), func(context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return &pgconn.PgError{Code: "40001"}
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Len(t, db.txs, 3)
+	assert.True(t, db.txs[0].rolledBack)
+	assert.True(t, db.txs[2].committed)
+}
+
+func TestWithinTx_RetryLimit(t *testing.T) {
+	tr, _ := newTestTransactor()
+	attempts := 0
+
+	err := tr.WithinTx(context.Background(), func(context.Context) error {
+		attempts++
+		return &pgconn.PgError{Code: "40P01"}
+	})
+
+	assert.True(t, isRetryableTxError(err))
+	assert.Equal(t, maxTxAttempts, attempts)
+
+	// 其他错误不重试
+	attempts = 0
+	err = tr.WithinTx(context.Background(), func(context.Context) error {
+		attempts++
+		return &pgconn.PgError{Code: "23505"}
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestWithTx_NoTransaction(t *testing.T) {
+	q := models.New(nil)
+
+	assert.Same(t, q, withTx(context.Background(), q))
+}
@@ -2,63 +2,160 @@ package data
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"connect-go-example/internal/biz/model"
 	"connect-go-example/internal/data/models"
+	"connect-go-example/internal/pkg/fieldcrypt"
 
-	"github.com/redis/go-redis/v9"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"go.uber.org/zap"
 )
 
-// UserRepo 用户数据访问接口
+// UserRepo 用户数据访问接口，所有操作都限定在 ctx 中的租户内
 type UserRepo interface {
 	GetUserByName(ctx context.Context, username string) (*model.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
 	CreateUser(ctx context.Context, user *model.User) (int64, error)
+	// MarkEmailVerified 按 user.ID 在邮箱仍为 user.Email 时标记为已验证，user.Username 用于清除用户缓存
+	MarkEmailVerified(ctx context.Context, user *model.User) error
+	// SyncDirectoryUser 按用户名创建或更新目录服务中的用户，返回本地用户
+	SyncDirectoryUser(ctx context.Context, user *model.DirectoryUser) (*model.User, error)
+	GetUserRoles(ctx context.Context, userID int64) ([]string, error)
+	// UpdateCredential 按 user.ID 更新用户的凭证哈希、盐和 KDF 版本，user.Username 用于清除用户缓存
+	UpdateCredential(ctx context.Context, user *model.User) error
+	// SetCredentialBackend 指定用户的凭证校验后端，backend 为空时恢复为租户默认后端
+	SetCredentialBackend(ctx context.Context, username, backend string) error
 	StoreAuthChallenge(ctx context.Context, username, challenge string, timeout time.Duration) error
 	GetAuthChallenge(ctx context.Context, username string) (string, error)
 }
 
 type userRepo struct {
 	queries *models.Queries
-	rdb     *redis.Client
-	l       *zap.Logger
+	keyring *fieldcrypt.Keyring
+	authChallenges
+	l *zap.Logger
 }
 
-func NewUserRepo(data *Data, logger *zap.Logger) UserRepo {
-	return &userRepo{
-		queries: models.New(data.db),
-		rdb:     data.rdb,
-		l:       logger,
+func NewUserRepo(data *Data, states StateStore, cache *UserCache, keyring *fieldcrypt.Keyring, logger *zap.Logger) UserRepo {
+	switch data.driver {
+	case DriverMemory:
+		return &memoryUserRepo{store: data.memory, authChallenges: authChallenges{states}, l: logger}
+	case DriverSQLite:
+		return &sqliteUserRepo{db: data.sqlite, authChallenges: authChallenges{states}, l: logger}
 	}
+	var repo UserRepo = &userRepo{
+		queries:        models.New(data.query),
+		keyring:        keyring,
+		authChallenges: authChallenges{states},
+		l:              logger,
+	}
+	if cache != nil {
+		repo = &cachedUserRepo{UserRepo: repo, cache: cache}
+	}
+	return repo
 }
 
 func (r *userRepo) GetUserByName(ctx context.Context, username string) (*model.User, error) {
-	dbUser, err := r.queries.GetUserByName(ctx, username)
+	tenantID, err := model.TenantIDFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return &model.User{
-		ID:           int64(dbUser.ID),
-		Username:     dbUser.Username,
-		PasswordHash: dbUser.PasswordHash,
-		Salt:         dbUser.Salt,
-		// Email:        dbUser.Email,
+	dbUser, err := withTx(ctx, r.queries).GetUserByName(ctx, models.GetUserByNameParams{
+		TenantID: int32(tenantID),
+		Username: username,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	codec := newFieldCodec(r.keyring, tenantID)
+	user := &model.User{
+		ID:            int64(dbUser.ID),
+		TenantID:      tenantID,
+		Username:      dbUser.Username,
+		PasswordHash:  dbUser.PasswordHash,
+		Salt:          dbUser.Salt,
+		KdfVersion:    dbUser.KdfVersion,
+		Email:         codec.decrypt(fieldUserEmail, dbUser.Email),
+		EmailVerified: dbUser.EmailVerified,
+		Disabled:      !dbUser.Active,
 		// CreatedAt:    dbUser.CreatedAt.Time().Format(time.RFC3339),
-	}, nil
+	}
+	if codec.err != nil {
+		return nil, codec.err
+	}
+	if dbUser.CredentialBackend != nil {
+		user.CredentialBackend = *dbUser.CredentialBackend
+	}
+	return user, nil
+}
+
+func (r *userRepo) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	codec := newFieldCodec(r.keyring, tenantID)
+	dbUser, err := withTx(ctx, r.queries).GetUserByEmail(ctx, models.GetUserByEmailParams{
+		TenantID:    int32(tenantID),
+		EmailIndex:  codec.emailIndex(email),
+		LegacyEmail: fieldcrypt.Unencrypted(email),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	user := &model.User{
+		ID:            int64(dbUser.ID),
+		Username:      dbUser.Username,
+		PasswordHash:  dbUser.PasswordHash,
+		Salt:          dbUser.Salt,
+		KdfVersion:    dbUser.KdfVersion,
+		Email:         codec.decrypt(fieldUserEmail, dbUser.Email),
+		EmailVerified: dbUser.EmailVerified,
+		Disabled:      !dbUser.Active,
+		TenantID:      tenantID,
+	}
+	if codec.err != nil {
+		return nil, codec.err
+	}
+	return user, nil
 }
 
 func (r *userRepo) CreateUser(ctx context.Context, req *model.User) (int64, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	codec := newFieldCodec(r.keyring, tenantID)
 	params := models.CreateUserParams{
-		Username:     req.Username,
-		PasswordHash: req.PasswordHash,
-		Salt:         req.Salt,
-		// Email:        user.Email,
+		TenantID:      int32(tenantID),
+		Username:      req.Username,
+		PasswordHash:  req.PasswordHash,
+		Salt:          req.Salt,
+		Email:         codec.encrypt(fieldUserEmail, req.Email),
+		EmailIndex:    codec.emailIndex(req.Email),
+		KdfVersion:    req.KdfVersion,
+		EmailVerified: req.EmailVerified && req.Email != "",
+	}
+	if codec.err != nil {
+		return 0, codec.err
 	}
 
-	user, err := r.queries.CreateUser(ctx, params)
+	user, err := withTx(ctx, r.queries).CreateUser(ctx, params)
 	if err != nil {
 		return 0, err
 	}
@@ -66,16 +163,142 @@ func (r *userRepo) CreateUser(ctx context.Context, req *model.User) (int64, erro
 	return int64(user.ID), nil
 }
 
-func (r *userRepo) StoreAuthChallenge(ctx context.Context, username, challenge string, timeout time.Duration) error {
-	key := fmt.Sprintf("auth_challenge:%s", username)
-	return r.rdb.SetEx(ctx, key, challenge, timeout).Err()
+func (r *userRepo) MarkEmailVerified(ctx context.Context, user *model.User) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	codec := newFieldCodec(r.keyring, tenantID)
+	return withTx(ctx, r.queries).MarkEmailVerified(ctx, models.MarkEmailVerifiedParams{
+		TenantID:    int32(tenantID),
+		ID:          int32(user.ID),
+		EmailIndex:  codec.emailIndex(user.Email),
+		LegacyEmail: fieldcrypt.Unencrypted(user.Email),
+	})
+}
+
+func (r *userRepo) SyncDirectoryUser(ctx context.Context, user *model.DirectoryUser) (*model.User, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 目录服务没有返回邮箱时 Email 为 NULL，保留已有的邮箱
+	codec := newFieldCodec(r.keyring, tenantID)
+	params := models.SyncDirectoryUserParams{
+		TenantID:          int32(tenantID),
+		Username:          user.Username,
+		Email:             codec.encrypt(fieldUserEmail, user.Email),
+		EmailIndex:        codec.emailIndex(user.Email),
+		DisplayName:       codec.encrypt(fieldUserDisplayName, user.DisplayName),
+		GivenName:         codec.encrypt(fieldUserGivenName, user.GivenName),
+		FamilyName:        codec.encrypt(fieldUserFamilyName, user.FamilyName),
+		CredentialBackend: &user.Backend,
+	}
+	if codec.err != nil {
+		return nil, codec.err
+	}
+
+	row, err := withTx(ctx, r.queries).SyncDirectoryUser(ctx, params)
+	if err != nil {
+		// 同名用户属于其他后端
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrUserConflict
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return nil, model.ErrUserConflict
+		}
+		return nil, err
+	}
+
+	return &model.User{
+		ID:            int64(row.ID),
+		TenantID:      tenantID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.Email != "",
+		Disabled:      !row.Active,
+	}, nil
+}
+
+func (r *userRepo) GetUserRoles(ctx context.Context, userID int64) ([]string, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return withTx(ctx, r.queries).GetUserRoles(ctx, models.GetUserRolesParams{
+		TenantID: int32(tenantID),
+		UserID:   int32(userID),
+	})
+}
+
+func (r *userRepo) UpdateCredential(ctx context.Context, user *model.User) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	return withTx(ctx, r.queries).UpdateCredential(ctx, models.UpdateCredentialParams{
+		PasswordHash: user.PasswordHash,
+		Salt:         user.Salt,
+		KdfVersion:   user.KdfVersion,
+		TenantID:     int32(tenantID),
+		ID:           int32(user.ID),
+	})
+}
+
+func (r *userRepo) SetCredentialBackend(ctx context.Context, username, backend string) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	params := models.SetCredentialBackendParams{
+		TenantID: int32(tenantID),
+		Username: username,
+	}
+	if backend != "" {
+		params.CredentialBackend = &backend
+	}
+	n, err := withTx(ctx, r.queries).SetCredentialBackend(ctx, params)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return model.ErrUserNotFound
+	}
+	return nil
+}
+
+// authChallenges 实现 UserRepo 中登录挑战的部分，各驱动的 UserRepo 共用
+type authChallenges struct {
+	states StateStore
+}
+
+func (c authChallenges) StoreAuthChallenge(ctx context.Context, username, challenge string, timeout time.Duration) error {
+	key, err := authChallengeKey(ctx, username)
+	if err != nil {
+		return err
+	}
+	return c.states.Set(ctx, key, challenge, timeout)
+}
+
+func (c authChallenges) GetAuthChallenge(ctx context.Context, username string) (string, error) {
+	key, err := authChallengeKey(ctx, username)
+	if err != nil {
+		return "", err
+	}
+	return c.states.GetDel(ctx, key)
 }
 
-func (r *userRepo) GetAuthChallenge(ctx context.Context, username string) (string, error) {
-	key := fmt.Sprintf("auth_challenge:%s", username)
-	challenge, err := r.rdb.GetDel(ctx, key).Result()
+// authChallengeKey 不同租户可以有同名用户，挑战按租户区分
+func authChallengeKey(ctx context.Context, username string) (string, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
 	if err != nil {
 		return "", err
 	}
-	return challenge, nil
+	return fmt.Sprintf("auth_challenge:%d:%s", tenantID, username), nil
 }
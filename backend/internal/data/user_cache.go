@@ -0,0 +1,376 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/pkg/breaker"
+	"connect-go-example/internal/pkg/fieldcrypt"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// userCacheChannel 用户变更时广播清除通知的频道
+const userCacheChannel = "user_cache_invalidate"
+
+// UserCache 按租户和用户名缓存用户，先查进程内 LRU，再查 Redis。
+// 用户不存在的结果同样缓存，与存在的用户使用相同的层级和过期时间，两者的查询耗时没有差别
+type UserCache struct {
+	local *expirable.LRU[string, *model.User]
+	rdb   redis.UniversalClient
+	// keyring 加密 Redis 中的凭证哈希和邮箱
+	keyring *fieldcrypt.Keyring
+	ttl     time.Duration
+	// redisBreaker Redis 不可用时跳过 Redis 层，避免每次查找都等待超时
+	redisBreaker *breaker.Breaker
+	hits         metric.Int64Counter
+	misses       metric.Int64Counter
+	l            *zap.Logger
+}
+
+// userCacheInvalidation 清除通知的内容
+type userCacheInvalidation struct {
+	TenantID  int64    `json:"tenantId"`
+	Usernames []string `json:"usernames"`
+}
+
+// userCacheRecord Redis 中缓存的用户，只包含登录需要的字段。
+// 凭证哈希和邮箱用字段加密的数据密钥加密并绑定租户和用户名，Redis 中的数据泄露或被复制到其他键下都不可用
+type userCacheRecord struct {
+	ID                int64                 `json:"id"`
+	PasswordHash      fieldcrypt.Ciphertext `json:"password_hash"`
+	Salt              string                `json:"salt"`
+	KdfVersion        int32                 `json:"kdf_version"`
+	Email             fieldcrypt.Ciphertext `json:"email,omitempty"`
+	EmailVerified     bool                  `json:"email_verified,omitempty"`
+	Disabled          bool                  `json:"disabled,omitempty"`
+	CredentialBackend string                `json:"credential_backend,omitempty"`
+}
+
+// NewUserCache 未启用或使用本地开发驱动时返回 nil，UserCache 的方法可以在 nil 上调用
+func NewUserCache(lc fx.Lifecycle, cfg *conf.Bootstrap, data *Data, keyring *fieldcrypt.Keyring, logger *zap.Logger) (*UserCache, error) {
+	cacheCfg := cfg.GetData().GetUserCache()
+	if !cacheCfg.GetEnabled() || data.driver != DriverPostgres {
+		return nil, nil
+	}
+
+	size := 10000 // 默认10000
+	if cacheCfg.GetSize() > 0 {
+		size = int(cacheCfg.GetSize())
+	}
+	ttl := 60 * time.Second // 默认60秒
+	if cacheCfg.GetTtlSeconds() > 0 {
+		ttl = time.Duration(cacheCfg.GetTtlSeconds()) * time.Second
+	}
+
+	meter := otel.GetMeterProvider().Meter("connect-go-example")
+	hits, err := meter.Int64Counter(
+		"user_cache.hit.count",
+		metric.WithDescription("用户缓存命中次数"),
+		metric.WithUnit("{lookup}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user cache hit counter: %w", err)
+	}
+	misses, err := meter.Int64Counter(
+		"user_cache.miss.count",
+		metric.WithDescription("用户缓存未命中次数"),
+		metric.WithUnit("{lookup}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user cache miss counter: %w", err)
+	}
+
+	c := &UserCache{
+		local:        expirable.NewLRU[string, *model.User](size, nil, ttl),
+		rdb:          data.rdb,
+		keyring:      keyring,
+		ttl:          ttl,
+		redisBreaker: breaker.New(5, 30*time.Second, nil),
+		hits:         hits,
+		misses:       misses,
+		l:            logger,
+	}
+
+	// 订阅其他副本的清除通知，Redis 断开期间错过的通知由 ttl 兜底
+	var sub *redis.PubSub
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			sub = c.rdb.Subscribe(context.Background(), userCacheChannel)
+			go func() {
+				defer close(done)
+				for msg := range sub.Channel() {
+					c.handleInvalidation(msg.Payload)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			err := sub.Close()
+			<-done
+			return err
+		},
+	})
+	return c, nil
+}
+
+// userCacheKey v2 起 Redis 中只保存 userCacheRecord，与旧格式的键区分
+func userCacheKey(tenantID int64, username string) string {
+	return fmt.Sprintf("user_cache:v2:%d:%s", tenantID, username)
+}
+
+// get 返回缓存的查找结果，ok 为 false 时需要查询数据库；user 为 nil 表示用户不存在
+func (c *UserCache) get(ctx context.Context, tenantID int64, username string) (user *model.User, ok bool) {
+	key := userCacheKey(tenantID, username)
+	if user, ok := c.local.Get(key); ok {
+		c.hits.Add(ctx, 1, metric.WithAttributes(attribute.String("tier", "local")))
+		return cloneUser(user), true
+	}
+
+	if c.redisBreaker.Allow() {
+		value, err := c.rdb.Get(ctx, key).Bytes()
+		switch {
+		case err == nil:
+			c.redisBreaker.Success()
+			user, err := c.decode(tenantID, username, value)
+			if err == nil {
+				c.local.Add(key, cloneUser(user))
+				c.hits.Add(ctx, 1, metric.WithAttributes(attribute.String("tier", "redis")))
+				return user, true
+			}
+			// 数据密钥不可用或值被篡改时按未命中处理
+			c.l.Debug("Decode user cache from redis failed", zap.Error(err))
+		case errors.Is(err, redis.Nil):
+			c.redisBreaker.Success()
+		case ctx.Err() == nil:
+			c.redisBreaker.Failure()
+			c.l.Debug("Get user cache from redis failed", zap.Error(err))
+		}
+	}
+
+	c.misses.Add(ctx, 1)
+	return nil, false
+}
+
+// set 缓存查找结果，user 为 nil 表示用户不存在
+func (c *UserCache) set(ctx context.Context, tenantID int64, username string, user *model.User) {
+	key := userCacheKey(tenantID, username)
+	c.local.Add(key, cloneUser(user))
+
+	if !c.redisBreaker.Allow() {
+		return
+	}
+	value, err := c.encode(tenantID, username, user)
+	if err != nil {
+		c.l.Debug("Encode user cache failed", zap.Error(err))
+		return
+	}
+	if err := c.rdb.Set(ctx, key, value, c.ttl).Err(); err != nil {
+		if ctx.Err() == nil {
+			c.redisBreaker.Failure()
+		}
+		c.l.Debug("Set user cache to redis failed", zap.Error(err))
+		return
+	}
+	c.redisBreaker.Success()
+}
+
+// encode 把查找结果转换为 Redis 中保存的值，user 为 nil 表示用户不存在
+func (c *UserCache) encode(tenantID int64, username string, user *model.User) ([]byte, error) {
+	var cached struct{ User *userCacheRecord }
+	if user != nil {
+		passwordHash, err := sealStateField(c.keyring, fieldUserCachePasswordHash, tenantID, username, user.PasswordHash)
+		if err != nil {
+			return nil, err
+		}
+		email, err := sealStateField(c.keyring, fieldUserCacheEmail, tenantID, username, user.Email)
+		if err != nil {
+			return nil, err
+		}
+		cached.User = &userCacheRecord{
+			ID:                user.ID,
+			PasswordHash:      passwordHash,
+			Salt:              user.Salt,
+			KdfVersion:        user.KdfVersion,
+			Email:             email,
+			EmailVerified:     user.EmailVerified,
+			Disabled:          user.Disabled,
+			CredentialBackend: user.CredentialBackend,
+		}
+	}
+	return json.Marshal(cached)
+}
+
+// decode 还原 encode 保存的查找结果
+func (c *UserCache) decode(tenantID int64, username string, value []byte) (*model.User, error) {
+	var cached struct{ User *userCacheRecord }
+	if err := json.Unmarshal(value, &cached); err != nil {
+		return nil, err
+	}
+	record := cached.User
+	if record == nil {
+		return nil, nil
+	}
+	passwordHash, err := openStateField(c.keyring, fieldUserCachePasswordHash, tenantID, username, record.PasswordHash)
+	if err != nil {
+		return nil, err
+	}
+	email, err := openStateField(c.keyring, fieldUserCacheEmail, tenantID, username, record.Email)
+	if err != nil {
+		return nil, err
+	}
+	return &model.User{
+		ID:                record.ID,
+		TenantID:          tenantID,
+		Username:          username,
+		PasswordHash:      passwordHash,
+		Salt:              record.Salt,
+		KdfVersion:        record.KdfVersion,
+		Email:             email,
+		EmailVerified:     record.EmailVerified,
+		Disabled:          record.Disabled,
+		CredentialBackend: record.CredentialBackend,
+	}, nil
+}
+
+// Invalidate 清除用户的缓存并通知其他副本，在事务中调用时等到提交后执行
+func (c *UserCache) Invalidate(ctx context.Context, tenantID int64, usernames ...string) {
+	if c == nil || len(usernames) == 0 {
+		return
+	}
+	afterCommit(ctx, func() {
+		// 请求结束后也要完成清除，不受 ctx 取消影响
+		ctx := context.WithoutCancel(ctx)
+		keys := make([]string, 0, len(usernames))
+		for _, username := range usernames {
+			key := userCacheKey(tenantID, username)
+			c.local.Remove(key)
+			keys = append(keys, key)
+		}
+
+		// Redis 层的删除失败时只能等待过期；逐个删除，集群模式下不同键可能在不同槽
+		for _, key := range keys {
+			if err := c.rdb.Del(ctx, key).Err(); err != nil {
+				c.l.Warn("Delete user cache from redis failed", zap.String("key", key), zap.Error(err))
+			}
+		}
+		payload, _ := json.Marshal(userCacheInvalidation{TenantID: tenantID, Usernames: usernames})
+		if err := c.rdb.Publish(ctx, userCacheChannel, payload).Err(); err != nil {
+			c.l.Warn("Publish user cache invalidation failed", zap.Error(err))
+		}
+	})
+}
+
+// handleInvalidation 处理其他副本（以及自己）发出的清除通知
+func (c *UserCache) handleInvalidation(payload string) {
+	var msg userCacheInvalidation
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		c.l.Warn("Invalid user cache invalidation", zap.String("payload", payload), zap.Error(err))
+		return
+	}
+	for _, username := range msg.Usernames {
+		c.local.Remove(userCacheKey(msg.TenantID, username))
+	}
+}
+
+func cloneUser(user *model.User) *model.User {
+	if user == nil {
+		return nil
+	}
+	u := *user
+	return &u
+}
+
+// cachedUserRepo 在 UserRepo 外加一层用户缓存，写入用户的方法在提交后清除缓存
+type cachedUserRepo struct {
+	UserRepo
+	cache *UserCache
+}
+
+func (r *cachedUserRepo) GetUserByName(ctx context.Context, username string) (*model.User, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// 事务中读到的可能是未提交的数据，不读写缓存
+	if ctx.Value(txKey{}) != nil {
+		return r.UserRepo.GetUserByName(ctx, username)
+	}
+
+	if user, ok := r.cache.get(ctx, tenantID, username); ok {
+		if user == nil {
+			return nil, model.ErrUserNotFound
+		}
+		return user, nil
+	}
+
+	// 从主库读取，刚注册或修改的用户不会因为副本延迟被缓存为旧数据或“不存在”
+	user, err := r.UserRepo.GetUserByName(WithPrimary(ctx), username)
+	switch {
+	case err == nil:
+		r.cache.set(ctx, tenantID, username, user)
+	case errors.Is(err, model.ErrUserNotFound):
+		r.cache.set(ctx, tenantID, username, nil)
+	}
+	return user, err
+}
+
+func (r *cachedUserRepo) CreateUser(ctx context.Context, req *model.User) (int64, error) {
+	id, err := r.UserRepo.CreateUser(ctx, req)
+	if err == nil {
+		r.invalidate(ctx, req.Username)
+	}
+	return id, err
+}
+
+func (r *cachedUserRepo) SyncDirectoryUser(ctx context.Context, user *model.DirectoryUser) (*model.User, error) {
+	synced, err := r.UserRepo.SyncDirectoryUser(ctx, user)
+	if err == nil {
+		r.invalidate(ctx, user.Username)
+	}
+	return synced, err
+}
+
+func (r *cachedUserRepo) UpdateCredential(ctx context.Context, user *model.User) error {
+	err := r.UserRepo.UpdateCredential(ctx, user)
+	if err == nil {
+		r.invalidate(ctx, user.Username)
+	}
+	return err
+}
+
+func (r *cachedUserRepo) MarkEmailVerified(ctx context.Context, user *model.User) error {
+	err := r.UserRepo.MarkEmailVerified(ctx, user)
+	if err == nil {
+		r.invalidate(ctx, user.Username)
+	}
+	return err
+}
+
+func (r *cachedUserRepo) SetCredentialBackend(ctx context.Context, username, backend string) error {
+	err := r.UserRepo.SetCredentialBackend(ctx, username, backend)
+	if err == nil {
+		r.invalidate(ctx, username)
+	}
+	return err
+}
+
+func (r *cachedUserRepo) invalidate(ctx context.Context, username string) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return
+	}
+	r.cache.Invalidate(ctx, tenantID, username)
+}
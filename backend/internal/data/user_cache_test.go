@@ -0,0 +1,194 @@
+package data
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	"connect-go-example/internal/pkg/breaker"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.uber.org/zap"
+)
+
+// countingUserRepo 记录按用户名查询数据库的次数
+type countingUserRepo struct {
+	UserRepo
+	users   map[string]*model.User
+	lookups int
+	// primary 每次查询是否要求读主库
+	primary []bool
+}
+
+func (r *countingUserRepo) GetUserByName(ctx context.Context, username string) (*model.User, error) {
+	r.lookups++
+	r.primary = append(r.primary, ctx.Value(primaryKey{}) != nil)
+	if user, ok := r.users[username]; ok {
+		return cloneUser(user), nil
+	}
+	return nil, model.ErrUserNotFound
+}
+
+func (r *countingUserRepo) UpdateCredential(_ context.Context, user *model.User) error {
+	r.users[user.Username].PasswordHash = user.PasswordHash
+	return nil
+}
+
+func (r *countingUserRepo) MarkEmailVerified(_ context.Context, user *model.User) error {
+	r.users[user.Username].EmailVerified = true
+	return nil
+}
+
+func (r *countingUserRepo) CreateUser(_ context.Context, user *model.User) (int64, error) {
+	r.users[user.Username] = cloneUser(user)
+	return user.ID, nil
+}
+
+// newTestUserCache 的 Redis 不可达，熔断器第一次失败后只使用进程内缓存
+func newTestUserCache(t *testing.T) (*cachedUserRepo, *countingUserRepo) {
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+	t.Cleanup(func() { rdb.Close() })
+	meter := noop.NewMeterProvider().Meter("test")
+	hits, _ := meter.Int64Counter("hits")
+	misses, _ := meter.Int64Counter("misses")
+	cache := &UserCache{
+		local:        expirable.NewLRU[string, *model.User](100, nil, time.Minute),
+		rdb:          rdb,
+		ttl:          time.Minute,
+		redisBreaker: breaker.New(1, time.Hour, nil),
+		hits:         hits,
+		misses:       misses,
+		keyring:      newTestKeyring(t),
+		l:            zap.NewNop(),
+	}
+	base := &countingUserRepo{users: map[string]*model.User{
+		"alice": {ID: 1, TenantID: 1, Username: "alice", PasswordHash: "h1"},
+	}}
+	return &cachedUserRepo{UserRepo: base, cache: cache}, base
+}
+
+func TestCachedUserRepo(t *testing.T) {
+	repo, base := newTestUserCache(t)
+	ctx := model.NewTenantContext(context.Background(), &model.Tenant{ID: 1})
+
+	user, err := repo.GetUserByName(ctx, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, "h1", user.PasswordHash)
+	// 修改返回值不影响缓存
+	user.PasswordHash = "changed"
+	user, err = repo.GetUserByName(ctx, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, "h1", user.PasswordHash)
+	assert.Equal(t, 1, base.lookups)
+
+	// 不存在的用户同样缓存
+	for range 2 {
+		_, err = repo.GetUserByName(ctx, "bob")
+		assert.ErrorIs(t, err, model.ErrUserNotFound)
+	}
+	assert.Equal(t, 2, base.lookups)
+
+	// 其他租户不共享缓存
+	_, err = repo.GetUserByName(model.NewTenantContext(context.Background(), &model.Tenant{ID: 2}), "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, base.lookups)
+	// 未命中时从主库读取，不会缓存副本上的旧数据
+	assert.Equal(t, []bool{true, true, true}, base.primary)
+}
+
+func TestUserCache_Encode(t *testing.T) {
+	repo, _ := newTestUserCache(t)
+	cache := repo.cache
+	user := &model.User{ID: 1, TenantID: 1, Username: "alice", PasswordHash: "$argon2id$hash", Salt: "salt", KdfVersion: 2, Email: "alice@example.com", EmailVerified: true, CredentialBackend: "corp"}
+
+	value, err := cache.encode(1, "alice", user)
+	assert.NoError(t, err)
+	// Redis 中没有凭证哈希和邮箱的明文
+	assert.NotContains(t, string(value), "argon2id")
+	assert.NotContains(t, string(value), "alice@example.com")
+
+	decoded, err := cache.decode(1, "alice", value)
+	assert.NoError(t, err)
+	assert.Equal(t, user, decoded)
+
+	// 值被复制到其他用户或租户的键下不能解密
+	_, err = cache.decode(1, "mallory", value)
+	assert.Error(t, err)
+	_, err = cache.decode(2, "alice", value)
+	assert.Error(t, err)
+
+	// 用户不存在的结果
+	value, err = cache.encode(1, "bob", nil)
+	assert.NoError(t, err)
+	decoded, err = cache.decode(1, "bob", value)
+	assert.NoError(t, err)
+	assert.Nil(t, decoded)
+}
+
+func TestCachedUserRepo_Invalidate(t *testing.T) {
+	repo, base := newTestUserCache(t)
+	ctx := model.NewTenantContext(context.Background(), &model.Tenant{ID: 1})
+
+	_, _ = repo.GetUserByName(ctx, "alice")
+	_, _ = repo.GetUserByName(ctx, "bob")
+	assert.Equal(t, 2, base.lookups)
+
+	assert.NoError(t, repo.UpdateCredential(ctx, &model.User{ID: 1, Username: "alice", PasswordHash: "h2"}))
+	user, err := repo.GetUserByName(ctx, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, "h2", user.PasswordHash)
+
+	assert.NoError(t, repo.MarkEmailVerified(ctx, &model.User{ID: 1, Username: "alice", Email: "alice@example.com"}))
+	user, err = repo.GetUserByName(ctx, "alice")
+	assert.NoError(t, err)
+	assert.True(t, user.EmailVerified)
+
+	// 注册后不再返回缓存的“不存在”
+	_, err = repo.CreateUser(ctx, &model.User{ID: 2, Username: "bob"})
+	assert.NoError(t, err)
+	_, err = repo.GetUserByName(ctx, "bob")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, base.lookups)
+
+	// 其他副本的清除通知
+	repo.cache.handleInvalidation(`{"tenantId":1,"usernames":["alice"]}`)
+	_, _ = repo.GetUserByName(ctx, "alice")
+	assert.Equal(t, 6, base.lookups)
+	repo.cache.handleInvalidation(`not json`)
+}
+
+func TestCachedUserRepo_Transaction(t *testing.T) {
+	repo, base := newTestUserCache(t)
+	tr, _ := newTestTransactor()
+	ctx := model.NewTenantContext(context.Background(), &model.Tenant{ID: 1})
+	_, _ = repo.GetUserByName(ctx, "alice")
+
+	err := tr.WithinTx(ctx, func(txCtx context.Context) error {
+		assert.NoError(t, repo.UpdateCredential(txCtx, &model.User{ID: 1, Username: "alice", PasswordHash: "h2"}))
+		// 提交前其他请求仍读到缓存
+		user, err := repo.GetUserByName(ctx, "alice")
+		assert.NoError(t, err)
+		assert.Equal(t, "h1", user.PasswordHash)
+		// 事务中直接查询数据库
+		user, err = repo.GetUserByName(txCtx, "alice")
+		assert.NoError(t, err)
+		assert.Equal(t, "h2", user.PasswordHash)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, base.lookups)
+
+	user, err := repo.GetUserByName(ctx, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, "h2", user.PasswordHash)
+	assert.Equal(t, 3, base.lookups)
+}
+
+func TestUserCache_Nil(t *testing.T) {
+	var cache *UserCache
+	cache.Invalidate(context.Background(), 1, "alice")
+}
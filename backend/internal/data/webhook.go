@@ -0,0 +1,479 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/data/models"
+	"connect-go-example/internal/pkg/webhook"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// errWebhooksUnsupported 本地开发驱动不支持 Webhook
+var errWebhooksUnsupported = errors.New("webhooks require the postgres driver")
+
+// WebhookRepo Webhook 订阅和投递记录数据访问接口，管理操作限定在 ctx 中的租户内，投递任务使用的操作不限租户
+type WebhookRepo interface {
+	CreateSubscription(ctx context.Context, sub *model.WebhookSubscription) error
+	ListSubscriptions(ctx context.Context) ([]*model.WebhookSubscription, error)
+	// DeleteSubscription 订阅不存在时返回 ErrWebhookSubscriptionNotFound
+	DeleteSubscription(ctx context.Context, id int64) error
+	ListDeliveries(ctx context.Context, subscriptionID int64, limit int32) ([]*model.WebhookDelivery, error)
+	// Redeliver 投递不存在时返回 ErrWebhookDeliveryNotFound
+	Redeliver(ctx context.Context, id int64) (*model.WebhookDelivery, error)
+
+	// Enqueue 为订阅了该事件的订阅创建投递记录，body 为发送的请求体
+	Enqueue(ctx context.Context, event *model.DomainEvent, body []byte) error
+	// ClaimDue 取出到期的投递，lease 内其他副本不会再取出
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*WebhookTask, error)
+	RecordAttempt(ctx context.Context, attempt *WebhookAttempt) error
+}
+
+// WebhookTask 待发送的投递
+type WebhookTask struct {
+	DeliveryID int64
+	EventType  string
+	Attempts   int32 // 之前已尝试的次数
+	URL        string
+	Secret     string
+	Body       []byte
+}
+
+// WebhookAttempt 一次发送的结果
+type WebhookAttempt struct {
+	DeliveryID     int64
+	Status         string
+	NextAttemptAt  time.Time
+	ResponseStatus int32
+	Error          string
+}
+
+type webhookRepo struct {
+	queries *models.Queries
+	l       *zap.Logger
+}
+
+func NewWebhookRepo(data *Data, logger *zap.Logger) WebhookRepo {
+	if data.driver != DriverPostgres {
+		return localWebhookRepo{}
+	}
+	return &webhookRepo{
+		queries: models.New(data.query),
+		l:       logger,
+	}
+}
+
+func (r *webhookRepo) CreateSubscription(ctx context.Context, sub *model.WebhookSubscription) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	row, err := withTx(ctx, r.queries).CreateWebhookSubscription(ctx, models.CreateWebhookSubscriptionParams{
+		TenantID: int32(tenantID),
+		Url:      sub.URL,
+		Events:   sub.Events,
+		Secret:   sub.Secret,
+	})
+	if err != nil {
+		return err
+	}
+	sub.ID = int64(row.ID)
+	sub.TenantID = tenantID
+	sub.CreatedAt = row.CreatedAt
+	return nil
+}
+
+func (r *webhookRepo) ListSubscriptions(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := withTx(ctx, r.queries).ListWebhookSubscriptions(ctx, int32(tenantID))
+	if err != nil {
+		return nil, err
+	}
+	subs := make([]*model.WebhookSubscription, 0, len(rows))
+	for _, row := range rows {
+		subs = append(subs, &model.WebhookSubscription{
+			ID:        int64(row.ID),
+			TenantID:  tenantID,
+			URL:       row.Url,
+			Events:    row.Events,
+			CreatedAt: row.CreatedAt,
+		})
+	}
+	return subs, nil
+}
+
+func (r *webhookRepo) DeleteSubscription(ctx context.Context, id int64) error {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	n, err := withTx(ctx, r.queries).DeleteWebhookSubscription(ctx, models.DeleteWebhookSubscriptionParams{
+		ID:       int32(id),
+		TenantID: int32(tenantID),
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return model.ErrWebhookSubscriptionNotFound
+	}
+	return nil
+}
+
+func (r *webhookRepo) ListDeliveries(ctx context.Context, subscriptionID int64, limit int32) ([]*model.WebhookDelivery, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := withTx(ctx, r.queries).ListWebhookDeliveries(ctx, models.ListWebhookDeliveriesParams{
+		TenantID:       int32(tenantID),
+		SubscriptionID: int32(subscriptionID),
+		MaxResults:     limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	deliveries := make([]*model.WebhookDelivery, 0, len(rows))
+	for _, row := range rows {
+		deliveries = append(deliveries, toWebhookDelivery(models.RedeliverWebhookDeliveryRow(row)))
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepo) Redeliver(ctx context.Context, id int64) (*model.WebhookDelivery, error) {
+	tenantID, err := model.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := withTx(ctx, r.queries).RedeliverWebhookDelivery(ctx, models.RedeliverWebhookDeliveryParams{
+		ID:       id,
+		TenantID: int32(tenantID),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+	return toWebhookDelivery(row), nil
+}
+
+func (r *webhookRepo) Enqueue(ctx context.Context, event *model.DomainEvent, body []byte) error {
+	_, err := withTx(ctx, r.queries).EnqueueWebhookDeliveries(ctx, models.EnqueueWebhookDeliveriesParams{
+		EventID:   event.ID,
+		EventType: event.Type,
+		Payload:   body,
+		TenantID:  int32(event.TenantID),
+	})
+	return err
+}
+
+func (r *webhookRepo) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*WebhookTask, error) {
+	rows, err := withTx(ctx, r.queries).ClaimWebhookDeliveries(ctx, models.ClaimWebhookDeliveriesParams{
+		LeaseSeconds: lease.Seconds(),
+		BatchSize:    int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]*WebhookTask, 0, len(rows))
+	for _, row := range rows {
+		tasks = append(tasks, &WebhookTask{
+			DeliveryID: row.ID,
+			EventType:  row.EventType,
+			Attempts:   row.Attempts,
+			URL:        row.Url,
+			Secret:     row.Secret,
+			Body:       row.Payload,
+		})
+	}
+	return tasks, nil
+}
+
+func (r *webhookRepo) RecordAttempt(ctx context.Context, attempt *WebhookAttempt) error {
+	params := models.RecordWebhookAttemptParams{
+		Status:        attempt.Status,
+		NextAttemptAt: attempt.NextAttemptAt,
+		ID:            attempt.DeliveryID,
+	}
+	if attempt.ResponseStatus != 0 {
+		params.ResponseStatus = &attempt.ResponseStatus
+	}
+	if attempt.Error != "" {
+		params.LastError = &attempt.Error
+	}
+	return withTx(ctx, r.queries).RecordWebhookAttempt(ctx, params)
+}
+
+func toWebhookDelivery(row models.RedeliverWebhookDeliveryRow) *model.WebhookDelivery {
+	delivery := &model.WebhookDelivery{
+		ID:             row.ID,
+		SubscriptionID: int64(row.SubscriptionID),
+		EventID:        row.EventID,
+		EventType:      row.EventType,
+		Status:         row.Status,
+		Attempts:       row.Attempts,
+		NextAttemptAt:  row.NextAttemptAt,
+		LastAttemptAt:  timestamptz(row.LastAttemptAt),
+		DeliveredAt:    timestamptz(row.DeliveredAt),
+		CreatedAt:      row.CreatedAt,
+	}
+	if row.ResponseStatus != nil {
+		delivery.ResponseStatus = *row.ResponseStatus
+	}
+	if row.LastError != nil {
+		delivery.LastError = *row.LastError
+	}
+	return delivery
+}
+
+// timestamptz 可为空的时间，为空时返回零值
+func timestamptz(t pgtype.Timestamptz) time.Time {
+	if !t.Valid {
+		return time.Time{}
+	}
+	return t.Time
+}
+
+// localWebhookRepo 本地开发驱动不保存订阅，事件不会投递给 Webhook
+type localWebhookRepo struct{}
+
+func (localWebhookRepo) CreateSubscription(context.Context, *model.WebhookSubscription) error {
+	return errWebhooksUnsupported
+}
+
+func (localWebhookRepo) ListSubscriptions(context.Context) ([]*model.WebhookSubscription, error) {
+	return nil, errWebhooksUnsupported
+}
+
+func (localWebhookRepo) DeleteSubscription(context.Context, int64) error {
+	return errWebhooksUnsupported
+}
+
+func (localWebhookRepo) ListDeliveries(context.Context, int64, int32) ([]*model.WebhookDelivery, error) {
+	return nil, errWebhooksUnsupported
+}
+
+func (localWebhookRepo) Redeliver(context.Context, int64) (*model.WebhookDelivery, error) {
+	return nil, errWebhooksUnsupported
+}
+
+func (localWebhookRepo) Enqueue(context.Context, *model.DomainEvent, []byte) error {
+	return nil
+}
+
+func (localWebhookRepo) ClaimDue(context.Context, int, time.Duration) ([]*WebhookTask, error) {
+	return nil, nil
+}
+
+func (localWebhookRepo) RecordAttempt(context.Context, *WebhookAttempt) error {
+	return errWebhooksUnsupported
+}
+
+// WebhookDispatcher 发送到期的 Webhook 投递，失败后按指数退避重试，次数用完后进入 dead 状态
+type WebhookDispatcher struct {
+	repo           WebhookRepo
+	sender         *webhook.Sender
+	maxAttempts    int32
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	lease          time.Duration
+	batchSize      int
+	pollInterval   time.Duration
+	now            func() time.Time
+	l              *zap.Logger
+}
+
+// NewWebhookDispatcher 本地开发驱动不启动投递
+func NewWebhookDispatcher(lc fx.Lifecycle, cfg *conf.Bootstrap, data *Data, repo WebhookRepo, logger *zap.Logger) (*WebhookDispatcher, error) {
+	webhooksCfg := cfg.GetWebhooks()
+	timeout := 10 * time.Second // 默认10秒
+	if webhooksCfg.GetTimeoutSeconds() > 0 {
+		timeout = time.Duration(webhooksCfg.GetTimeoutSeconds()) * time.Second
+	}
+	policy, err := webhook.NewAddressPolicy(webhooksCfg.GetAllowedNetworks())
+	if err != nil {
+		return nil, err
+	}
+	d := &WebhookDispatcher{
+		repo:           repo,
+		sender:         webhook.NewSender(timeout, policy),
+		maxAttempts:    8,                // 默认8次
+		initialBackoff: 30 * time.Second, // 默认30秒
+		maxBackoff:     6 * time.Hour,    // 默认6小时
+		// 租约覆盖一批中最慢的请求，超过租约仍未记录结果的投递会被再次取出
+		lease:        timeout + 30*time.Second,
+		batchSize:    20,          // 默认20
+		pollInterval: time.Second, // 默认1秒
+		now:          time.Now,
+		l:            logger,
+	}
+	if webhooksCfg.GetMaxAttempts() > 0 {
+		d.maxAttempts = webhooksCfg.GetMaxAttempts()
+	}
+	if webhooksCfg.GetInitialBackoffSeconds() > 0 {
+		d.initialBackoff = time.Duration(webhooksCfg.GetInitialBackoffSeconds()) * time.Second
+	}
+	if webhooksCfg.GetMaxBackoffSeconds() > 0 {
+		d.maxBackoff = time.Duration(webhooksCfg.GetMaxBackoffSeconds()) * time.Second
+	}
+	if webhooksCfg.GetBatchSize() > 0 {
+		d.batchSize = int(webhooksCfg.GetBatchSize())
+	}
+	if webhooksCfg.GetPollIntervalMs() > 0 {
+		d.pollInterval = time.Duration(webhooksCfg.GetPollIntervalMs()) * time.Millisecond
+	}
+
+	if data.driver != DriverPostgres {
+		return d, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				d.run(ctx)
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	})
+	return d, nil
+}
+
+func (d *WebhookDispatcher) run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		n, err := d.dispatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			d.l.Warn("Dispatch webhooks failed", zap.Error(err))
+		}
+		if n == d.batchSize {
+			timer.Reset(0)
+		} else {
+			timer.Reset(d.pollInterval)
+		}
+	}
+}
+
+// dispatch 并发发送一批到期的投递，返回取出的数量
+func (d *WebhookDispatcher) dispatch(ctx context.Context) (int, error) {
+	tasks, err := d.repo.ClaimDue(ctx, d.batchSize, d.lease)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, task := range tasks {
+		wg.Go(func() {
+			d.deliver(ctx, task)
+		})
+	}
+	wg.Wait()
+	return len(tasks), nil
+}
+
+func (d *WebhookDispatcher) deliver(ctx context.Context, task *WebhookTask) {
+	status, err := d.sender.Send(ctx, &webhook.Request{
+		URL:    task.URL,
+		Secret: task.Secret,
+		ID:     strconv.FormatInt(task.DeliveryID, 10),
+		Event:  task.EventType,
+		Body:   task.Body,
+	})
+	if err != nil && ctx.Err() != nil {
+		// 停止时未完成的投递在租约到期后重试，不计入次数
+		return
+	}
+
+	attempt := &WebhookAttempt{
+		DeliveryID:     task.DeliveryID,
+		Status:         model.WebhookDeliverySucceeded,
+		NextAttemptAt:  d.now(),
+		ResponseStatus: int32(status),
+	}
+	if err != nil {
+		attempts := task.Attempts + 1
+		attempt.Error = truncate(err.Error(), 500)
+		if attempts >= d.maxAttempts {
+			attempt.Status = model.WebhookDeliveryDead
+			d.l.Warn("Webhook delivery exhausted retries",
+				zap.Int64("delivery_id", task.DeliveryID), zap.Int32("attempts", attempts), zap.Error(err))
+		} else {
+			attempt.Status = model.WebhookDeliveryPending
+			attempt.NextAttemptAt = d.now().Add(d.backoff(attempts))
+		}
+	}
+	if err := d.repo.RecordAttempt(context.WithoutCancel(ctx), attempt); err != nil {
+		d.l.Warn("Record webhook attempt failed", zap.Int64("delivery_id", task.DeliveryID), zap.Error(err))
+	}
+}
+
+// backoff 第 attempts 次失败后的等待时间：initialBackoff * 2^(attempts-1)，不超过 maxBackoff
+func (d *WebhookDispatcher) backoff(attempts int32) time.Duration {
+	wait := d.initialBackoff
+	for i := int32(1); i < attempts && wait < d.maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, d.maxBackoff)
+}
+
+// truncate 按字节截断，去掉截断产生的不完整字符
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
+
+// webhookPayload 发送给订阅方的请求体
+type webhookPayload struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	TenantID   int64           `json:"tenantId"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// webhookBody 事件ID作为 id，接收方可据此对重新投递去重
+func webhookBody(event *model.DomainEvent) ([]byte, error) {
+	return json.Marshal(webhookPayload{
+		ID:         strconv.FormatInt(event.ID, 10),
+		Type:       event.Type,
+		TenantID:   event.TenantID,
+		OccurredAt: event.CreatedAt.UTC(),
+		Data:       event.Payload,
+	})
+}
@@ -0,0 +1,158 @@
+package data
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+	"connect-go-example/internal/pkg/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fakeWebhookRepo 记录创建的投递和每次发送的结果，ClaimDue 返回 due 中的投递
+type fakeWebhookRepo struct {
+	localWebhookRepo
+	mu       sync.Mutex
+	err      error
+	enqueued [][]byte
+	due      []*WebhookTask
+	attempts []*WebhookAttempt
+}
+
+func (r *fakeWebhookRepo) Enqueue(_ context.Context, _ *model.DomainEvent, body []byte) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.enqueued = append(r.enqueued, body)
+	return nil
+}
+
+func (r *fakeWebhookRepo) ClaimDue(_ context.Context, limit int, _ time.Duration) ([]*WebhookTask, error) {
+	n := min(limit, len(r.due))
+	tasks := r.due[:n]
+	r.due = r.due[n:]
+	return tasks, nil
+}
+
+func (r *fakeWebhookRepo) RecordAttempt(_ context.Context, attempt *WebhookAttempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts = append(r.attempts, attempt)
+	return nil
+}
+
+// testWebhookPolicy 允许 httptest 服务器监听的回环地址
+var testWebhookPolicy, _ = webhook.NewAddressPolicy([]string{"127.0.0.0/8", "::1"})
+
+func newTestDispatcher(repo WebhookRepo, now time.Time) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		repo:           repo,
+		sender:         webhook.NewSender(time.Second, testWebhookPolicy),
+		maxAttempts:    3,
+		initialBackoff: time.Minute,
+		maxBackoff:     90 * time.Second,
+		batchSize:      10,
+		now:            func() time.Time { return now },
+		l:              zap.NewNop(),
+	}
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	const secret = "subscription-secret"
+	var received []string
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := webhook.Verify(secret, r.Header, body, time.Minute, time.Now()); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		received = append(received, r.Header.Get(webhook.HeaderID)+":"+r.Header.Get(webhook.HeaderEvent))
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	repo := &fakeWebhookRepo{due: []*WebhookTask{
+		{DeliveryID: 1, EventType: model.EventUserRegistered, URL: srv.URL, Secret: secret, Body: []byte(`{}`)},
+		{DeliveryID: 2, EventType: model.EventUserDisabled, URL: srv.URL, Secret: "wrong-secret", Body: []byte(`{}`)},
+	}}
+	d := newTestDispatcher(repo, now)
+
+	n, err := d.dispatch(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"1:UserRegistered"}, received)
+
+	attempts := make(map[int64]*WebhookAttempt)
+	for _, attempt := range repo.attempts {
+		attempts[attempt.DeliveryID] = attempt
+	}
+	assert.Equal(t, model.WebhookDeliverySucceeded, attempts[1].Status)
+	assert.Equal(t, int32(http.StatusNoContent), attempts[1].ResponseStatus)
+	assert.Empty(t, attempts[1].Error)
+
+	// 签名错误被拒绝，第一次失败后等待 initialBackoff
+	assert.Equal(t, model.WebhookDeliveryPending, attempts[2].Status)
+	assert.Equal(t, int32(http.StatusUnauthorized), attempts[2].ResponseStatus)
+	assert.Equal(t, "unexpected status 401", attempts[2].Error)
+	assert.Equal(t, now.Add(time.Minute), attempts[2].NextAttemptAt)
+}
+
+func TestWebhookDispatcher_DeadLetter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	repo := &fakeWebhookRepo{}
+	d := newTestDispatcher(repo, now)
+	task := &WebhookTask{DeliveryID: 1, EventType: model.EventUserRegistered, URL: srv.URL, Secret: "s", Body: []byte(`{}`)}
+
+	// 第二次失败后的等待翻倍，但不超过 maxBackoff
+	task.Attempts = 1
+	d.deliver(context.Background(), task)
+	assert.Equal(t, model.WebhookDeliveryPending, repo.attempts[0].Status)
+	assert.Equal(t, now.Add(90*time.Second), repo.attempts[0].NextAttemptAt)
+	// 响应内容不保存到投递记录
+	assert.Equal(t, "unexpected status 503", repo.attempts[0].Error)
+
+	// 次数用完后进入 dead 状态
+	task.Attempts = 2
+	d.deliver(context.Background(), task)
+	assert.Equal(t, model.WebhookDeliveryDead, repo.attempts[1].Status)
+	assert.Equal(t, int32(http.StatusServiceUnavailable), repo.attempts[1].ResponseStatus)
+}
+
+func TestWebhookDispatcher_Unreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	repo := &fakeWebhookRepo{}
+	d := newTestDispatcher(repo, time.Now())
+	d.deliver(context.Background(), &WebhookTask{DeliveryID: 1, URL: url, Secret: "s", Body: []byte(`{}`)})
+
+	assert.Equal(t, model.WebhookDeliveryPending, repo.attempts[0].Status)
+	assert.Zero(t, repo.attempts[0].ResponseStatus)
+	assert.NotEmpty(t, repo.attempts[0].Error)
+}
+
+func TestWebhookDispatcher_Backoff(t *testing.T) {
+	d := &WebhookDispatcher{initialBackoff: 30 * time.Second, maxBackoff: 6 * time.Hour}
+
+	assert.Equal(t, 30*time.Second, d.backoff(1))
+	assert.Equal(t, time.Minute, d.backoff(2))
+	assert.Equal(t, 4*time.Minute, d.backoff(4))
+	assert.Equal(t, 6*time.Hour, d.backoff(20))
+}
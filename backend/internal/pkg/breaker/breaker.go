@@ -0,0 +1,101 @@
+// Package breaker 实现熔断器：依赖连续失败达到阈值后断开，一段时间后放行请求探测是否恢复
+package breaker
+
+import (
+	"sync"
+	"time"
+)
+
+// State 熔断器状态
+type State int
+
+const (
+	// Closed 依赖正常，请求全部放行
+	Closed State = iota
+	// Open 依赖不可用，请求直接走降级逻辑
+	Open
+	// HalfOpen 断开时间已到，放行请求探测依赖是否恢复
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// Breaker 并发安全的熔断器
+type Breaker struct {
+	threshold int
+	timeout   time.Duration
+	onChange  func(from, to State)
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+}
+
+// New 连续失败 threshold 次后断开 timeout，状态变化时调用 onChange（可以为 nil），onChange 中不能再调用 Breaker 的方法
+func New(threshold int, timeout time.Duration, onChange func(from, to State)) *Breaker {
+	return &Breaker{
+		threshold: max(threshold, 1),
+		timeout:   timeout,
+		onChange:  onChange,
+		now:       time.Now,
+	}
+}
+
+// Allow 返回是否应该请求依赖，断开时间已到时进入半开状态
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == Open && b.now().Sub(b.openedAt) >= b.timeout {
+		b.setState(HalfOpen)
+	}
+	return b.state != Open
+}
+
+// Success 记录一次成功，熔断器闭合
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.setState(Closed)
+}
+
+// Failure 记录一次失败，连续失败达到阈值或半开状态下探测失败时断开
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.state == HalfOpen || b.failures >= b.threshold {
+		b.openedAt = b.now()
+		b.setState(Open)
+	}
+}
+
+// State 返回当前状态
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *Breaker) setState(state State) {
+	if b.state == state {
+		return
+	}
+	from := b.state
+	b.state = state
+	if b.onChange != nil {
+		b.onChange(from, state)
+	}
+}
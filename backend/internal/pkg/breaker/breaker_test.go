@@ -0,0 +1,42 @@
+package breaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+	var changes []State
+	b := New(3, 30*time.Second, func(_, to State) { changes = append(changes, to) })
+	b.now = func() time.Time { return now }
+
+	// 未达到阈值时保持闭合，成功后重新计数
+	b.Failure()
+	b.Failure()
+	b.Success()
+	b.Failure()
+	b.Failure()
+	assert.Equal(t, Closed, b.State())
+	assert.True(t, b.Allow())
+
+	b.Failure()
+	assert.Equal(t, Open, b.State())
+	assert.False(t, b.Allow())
+
+	// 断开时间到后放行探测，探测失败重新断开
+	now = now.Add(30 * time.Second)
+	assert.True(t, b.Allow())
+	assert.Equal(t, HalfOpen, b.State())
+	b.Failure()
+	assert.False(t, b.Allow())
+
+	now = now.Add(30 * time.Second)
+	assert.True(t, b.Allow())
+	b.Success()
+	assert.Equal(t, Closed, b.State())
+
+	assert.Equal(t, []State{Open, HalfOpen, Open, HalfOpen, Closed}, changes)
+}
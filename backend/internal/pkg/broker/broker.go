@@ -0,0 +1,219 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	confv1 "connect-go-example/internal/conf/v1"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/redis/go-redis/v9"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	DriverNats   = "nats"
+	DriverKafka  = "kafka"
+	DriverRedis  = "redis"
+	DriverMemory = "memory"
+)
+
+// defaultTopic 未配置 events.topic 时使用的主题
+const defaultTopic = "user.events"
+
+// Module 提供 Fx 模块
+var Module = fx.Module("broker",
+	fx.Provide(NewBroker),
+)
+
+// Message 发送到消息代理的事件，消费方按 ID 去重
+type Message struct {
+	ID      string
+	Key     string // 同一键的消息按发送顺序到达
+	Type    string
+	Payload []byte
+	Headers map[string]string
+}
+
+// Broker 消息代理接口
+type Broker interface {
+	// Publish 返回 nil 表示消息已被代理确认
+	Publish(ctx context.Context, msg *Message) error
+}
+
+// NewBroker 根据配置创建消息代理，未配置时返回 nil，事件只写入发件箱
+func NewBroker(lc fx.Lifecycle, conf *confv1.Bootstrap, rdb redis.UniversalClient, logger *zap.Logger) (Broker, error) {
+	cfg := conf.GetEvents()
+	topic := cfg.GetTopic()
+	if topic == "" {
+		topic = defaultTopic
+	}
+
+	switch cfg.GetBroker() {
+	case "":
+		return nil, nil
+	case DriverNats:
+		b, err := NewNatsBroker(cfg.GetNats(), topic)
+		if err != nil {
+			return nil, err
+		}
+		lc.Append(fx.StopHook(b.Close))
+		return b, nil
+	case DriverKafka:
+		if len(cfg.GetKafka().GetBrokers()) == 0 {
+			return nil, fmt.Errorf("events.kafka.brokers is required for kafka broker")
+		}
+		b := NewKafkaBroker(cfg.GetKafka().GetBrokers(), topic)
+		lc.Append(fx.StopHook(b.Close))
+		return b, nil
+	case DriverRedis:
+		maxLen := int64(100000) // 默认100000
+		if cfg.GetRedis() != nil && cfg.GetRedis().GetMaxLen() != 0 {
+			maxLen = max(cfg.GetRedis().GetMaxLen(), 0)
+		}
+		return NewRedisBroker(rdb, topic, maxLen), nil
+	case DriverMemory:
+		logger.Warn("Event broker keeps messages in memory, use only for tests")
+		return NewMemoryBroker(), nil
+	default:
+		return nil, fmt.Errorf("unknown event broker: %s", cfg.GetBroker())
+	}
+}
+
+// NatsBroker 通过 JetStream 发送，消息ID用于 JetStream 的去重窗口
+type NatsBroker struct {
+	nc      *nats.Conn
+	js      jetstream.JetStream
+	subject string
+}
+
+func NewNatsBroker(cfg *confv1.Events_Nats, subject string) (*NatsBroker, error) {
+	url := cfg.GetUrl()
+	if url == "" {
+		url = nats.DefaultURL
+	}
+	opts := []nats.Option{nats.Name("connect-go-example"), nats.MaxReconnects(-1)}
+	if cfg.GetCredentialsFile() != "" {
+		opts = append(opts, nats.UserCredentials(cfg.GetCredentialsFile()))
+	}
+	// 启动时 NATS 不可用也不影响服务，投递任务会重试
+	opts = append(opts, nats.RetryOnFailedConnect(true))
+
+	nc, err := nats.Connect(url, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+	js, err := jetstream.New(nc)
+	if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("failed to create jetstream context: %w", err)
+	}
+	return &NatsBroker{nc: nc, js: js, subject: subject}, nil
+}
+
+func (b *NatsBroker) Publish(ctx context.Context, msg *Message) error {
+	m := nats.NewMsg(b.subject)
+	m.Data = msg.Payload
+	m.Header.Set("event_id", msg.ID)
+	m.Header.Set("event_type", msg.Type)
+	m.Header.Set("event_key", msg.Key)
+	for k, v := range msg.Headers {
+		m.Header.Set(k, v)
+	}
+	_, err := b.js.PublishMsg(ctx, m, jetstream.WithMsgID(msg.ID))
+	return err
+}
+
+func (b *NatsBroker) Close() {
+	b.nc.Close()
+}
+
+// KafkaBroker 按键哈希选择分区，同一键的消息在同一分区内保持顺序
+type KafkaBroker struct {
+	w *kafka.Writer
+}
+
+func NewKafkaBroker(brokers []string, topic string) *KafkaBroker {
+	return &KafkaBroker{w: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		// 投递任务逐条同步发送，不等待凑批
+		BatchTimeout: 5 * time.Millisecond,
+	}}
+}
+
+func (b *KafkaBroker) Publish(ctx context.Context, msg *Message) error {
+	headers := []kafka.Header{
+		{Key: "event_id", Value: []byte(msg.ID)},
+		{Key: "event_type", Value: []byte(msg.Type)},
+	}
+	for k, v := range msg.Headers {
+		headers = append(headers, kafka.Header{Key: k, Value: []byte(v)})
+	}
+	return b.w.WriteMessages(ctx, kafka.Message{
+		Key:     []byte(msg.Key),
+		Value:   msg.Payload,
+		Headers: headers,
+	})
+}
+
+func (b *KafkaBroker) Close() error {
+	return b.w.Close()
+}
+
+// RedisBroker 追加到 Redis Stream，所有消息在同一个流中保持发送顺序
+type RedisBroker struct {
+	rdb    redis.UniversalClient
+	stream string
+	maxLen int64
+}
+
+func NewRedisBroker(rdb redis.UniversalClient, stream string, maxLen int64) *RedisBroker {
+	return &RedisBroker{rdb: rdb, stream: stream, maxLen: maxLen}
+}
+
+func (b *RedisBroker) Publish(ctx context.Context, msg *Message) error {
+	values := []any{"event_id", msg.ID, "event_type", msg.Type, "event_key", msg.Key}
+	for k, v := range msg.Headers {
+		values = append(values, k, v)
+	}
+	values = append(values, "payload", msg.Payload)
+	return b.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: b.stream,
+		MaxLen: b.maxLen,
+		Approx: true,
+		Values: values,
+	}).Err()
+}
+
+// MemoryBroker 把消息保存在内存中，用于测试
+type MemoryBroker struct {
+	mu       sync.Mutex
+	messages []*Message
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{}
+}
+
+func (b *MemoryBroker) Publish(_ context.Context, msg *Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.messages = append(b.messages, msg)
+	return nil
+}
+
+// Messages 按发送顺序返回收到的消息
+func (b *MemoryBroker) Messages() []*Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return slices.Clone(b.messages)
+}
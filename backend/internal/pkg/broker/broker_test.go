@@ -0,0 +1,56 @@
+package broker
+
+import (
+	"context"
+	"testing"
+
+	confv1 "connect-go-example/internal/conf/v1"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+)
+
+func TestNewBroker(t *testing.T) {
+	lc := fxtest.NewLifecycle(t)
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+	defer rdb.Close()
+	newBroker := func(events *confv1.Events) (Broker, error) {
+		return NewBroker(lc, &confv1.Bootstrap{Events: events}, rdb, zap.NewNop())
+	}
+
+	// 未配置时不投递
+	b, err := newBroker(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, b)
+
+	b, err = newBroker(&confv1.Events{Broker: DriverMemory})
+	assert.NoError(t, err)
+	assert.IsType(t, &MemoryBroker{}, b)
+
+	b, err = newBroker(&confv1.Events{Broker: DriverRedis, Redis: &confv1.Events_RedisStream{MaxLen: -1}})
+	assert.NoError(t, err)
+	assert.Equal(t, &RedisBroker{rdb: rdb, stream: defaultTopic, maxLen: 0}, b)
+
+	b, err = newBroker(&confv1.Events{Broker: DriverKafka, Topic: "auth", Kafka: &confv1.Events_Kafka{Brokers: []string{"127.0.0.1:9092"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, "auth", b.(*KafkaBroker).w.Topic)
+
+	_, err = newBroker(&confv1.Events{Broker: DriverKafka})
+	assert.Error(t, err)
+
+	_, err = newBroker(&confv1.Events{Broker: "carrier-pigeon"})
+	assert.Error(t, err)
+}
+
+func TestMemoryBroker(t *testing.T) {
+	b := NewMemoryBroker()
+	assert.NoError(t, b.Publish(context.Background(), &Message{ID: "1", Key: "7"}))
+	assert.NoError(t, b.Publish(context.Background(), &Message{ID: "2", Key: "7"}))
+
+	messages := b.Messages()
+	assert.Len(t, messages, 2)
+	assert.Equal(t, "1", messages[0].ID)
+	assert.Equal(t, "2", messages[1].ID)
+}
@@ -139,9 +139,15 @@ func ValidateConfig(conf *confv1.Bootstrap) error {
 		return fmt.Errorf("server configuration is required")
 	}
 
-	// 验证数据库配置
-	if conf.Data == nil || conf.Data.Database == nil {
-		return fmt.Errorf("database configuration is required")
+	// 验证数据库配置，本地开发驱动不需要 Postgres
+	switch conf.GetData().GetDriver() {
+	case "", "postgres":
+		if conf.Data == nil || conf.Data.Database == nil {
+			return fmt.Errorf("database configuration is required")
+		}
+	case "memory", "sqlite":
+	default:
+		return fmt.Errorf("unknown data driver %q", conf.Data.Driver)
 	}
 
 	return nil
@@ -146,6 +146,17 @@ func (suite *ConfigTestSuite) TestValidateConfig_MissingDatabase() {
 	assert.Equal(suite.T(), "database configuration is required", err.Error())
 }
 
+func (suite *ConfigTestSuite) TestValidateConfig_LocalDriver() {
+	server := &confv1.Server{Http: &confv1.Server_HTTP{Addr: ":8080"}}
+
+	// 本地开发驱动不需要数据库配置
+	err := ValidateConfig(&confv1.Bootstrap{Server: server, Data: &confv1.Data{Driver: "memory"}})
+	assert.NoError(suite.T(), err)
+
+	err = ValidateConfig(&confv1.Bootstrap{Server: server, Data: &confv1.Data{Driver: "mysql"}})
+	assert.EqualError(suite.T(), err, `unknown data driver "mysql"`)
+}
+
 func (suite *ConfigTestSuite) TestContains() {
 	// 测试包含子字符串
 	assert.True(suite.T(), contains("hello world", "hello"))
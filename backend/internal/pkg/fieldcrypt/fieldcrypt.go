@@ -0,0 +1,221 @@
+// Package fieldcrypt 对数据库中的个人信息字段做信封加密：字段由数据密钥（AES-256-GCM）加密，
+// 数据密钥由 KeyProvider 管理的 KEK 加密后保存；需要按值查找的字段另存 HMAC 盲索引
+package fieldcrypt
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	ErrMalformed  = errors.New("malformed ciphertext")
+	ErrDecrypt    = errors.New("decrypt field failed")
+	ErrUnknownKey = errors.New("data key not found")
+)
+
+// 密文的版本，保存在第一个字节
+const (
+	// versionPlaintext 启用加密前的明文数据，由迁移转换而来，等待重新加密
+	versionPlaintext byte = 0
+	// versionAESGCM 版本(1) | 数据密钥ID(4，大端) | nonce(12) | 密文和认证标签
+	versionAESGCM byte = 1
+)
+
+// blindIndexSize 盲索引截断后的长度，缩短长度可以减少泄露，16字节时碰撞的概率仍可以忽略
+const blindIndexSize = 16
+
+// Ciphertext 加密字段在数据库中的值，对应 bytea 列，nil 对应 NULL（空字符串）。
+// 实现了 sql.Scanner 和 driver.Valuer，可以在 sqlc 的 overrides 中作为列类型
+type Ciphertext []byte
+
+// Unencrypted 启用加密前的数据在迁移时按该格式转换，只用于查找尚未重新加密的旧数据
+func Unencrypted(value string) Ciphertext {
+	if value == "" {
+		return nil
+	}
+	return append(Ciphertext{versionPlaintext}, value...)
+}
+
+// KeyID 加密使用的数据密钥，旧明文数据返回 0
+func (c Ciphertext) KeyID() uint32 {
+	if len(c) < 5 || c[0] != versionAESGCM {
+		return 0
+	}
+	return binary.BigEndian.Uint32(c[1:5])
+}
+
+func (c *Ciphertext) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*c = nil
+	case []byte:
+		*c = append(Ciphertext(nil), v...)
+	case string:
+		*c = Ciphertext(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Ciphertext", src)
+	}
+	return nil
+}
+
+func (c Ciphertext) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return []byte(c), nil
+}
+
+// BlindIndex 字段的盲索引，相同的值和上下文得到相同的索引，对应 bytea 列
+type BlindIndex []byte
+
+func (b *BlindIndex) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*b = nil
+	case []byte:
+		*b = append(BlindIndex(nil), v...)
+	default:
+		return fmt.Errorf("cannot scan %T into BlindIndex", src)
+	}
+	return nil
+}
+
+func (b BlindIndex) Value() (driver.Value, error) {
+	if b == nil {
+		return nil, nil
+	}
+	return []byte(b), nil
+}
+
+// DataKey 解密后的数据密钥
+type DataKey struct {
+	ID  uint32
+	Key []byte
+}
+
+// Keyring 保存所有可用的数据密钥，新数据使用 active 加密，旧密钥只用于解密。
+// 盲索引密钥不随数据密钥轮换，轮换它需要重建所有索引
+type Keyring struct {
+	mu     sync.RWMutex
+	keys   map[uint32]cipher.AEAD
+	active uint32
+	index  []byte
+}
+
+func NewKeyring(indexKey []byte) (*Keyring, error) {
+	if len(indexKey) != 32 {
+		return nil, errors.New("blind index key must be 32 bytes")
+	}
+	return &Keyring{keys: make(map[uint32]cipher.AEAD), index: indexKey}, nil
+}
+
+// Add 添加数据密钥，已存在的密钥不会被替换
+func (k *Keyring) Add(key DataKey) error {
+	aead, err := newAEAD(key.Key)
+	if err != nil {
+		return fmt.Errorf("data key %d: %w", key.ID, err)
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.keys[key.ID]; !ok {
+		k.keys[key.ID] = aead
+	}
+	return nil
+}
+
+func (k *Keyring) Has(id uint32) bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	_, ok := k.keys[id]
+	return ok
+}
+
+// SetActive 切换新数据使用的数据密钥，密钥必须已经添加
+func (k *Keyring) SetActive(id uint32) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.keys[id]; !ok {
+		return fmt.Errorf("%w: %d", ErrUnknownKey, id)
+	}
+	k.active = id
+	return nil
+}
+
+func (k *Keyring) ActiveKeyID() uint32 {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.active
+}
+
+// Encrypt 用当前数据密钥加密，aad 把密文绑定到所在的列和租户，空字符串返回 nil
+func (k *Keyring) Encrypt(plaintext string, aad []byte) (Ciphertext, error) {
+	if plaintext == "" {
+		return nil, nil
+	}
+	k.mu.RLock()
+	id, aead := k.active, k.keys[k.active]
+	k.mu.RUnlock()
+	if aead == nil {
+		return nil, ErrUnknownKey
+	}
+
+	header := make([]byte, 5)
+	header[0] = versionAESGCM
+	binary.BigEndian.PutUint32(header[1:], id)
+	return append(header, seal(aead, []byte(plaintext), aad)...), nil
+}
+
+// Decrypt aad 必须与加密时相同，旧明文数据直接返回
+func (k *Keyring) Decrypt(c Ciphertext, aad []byte) (string, error) {
+	if len(c) == 0 {
+		return "", nil
+	}
+	switch c[0] {
+	case versionPlaintext:
+		return string(c[1:]), nil
+	case versionAESGCM:
+		if len(c) < 5 {
+			return "", ErrMalformed
+		}
+		id := c.KeyID()
+		k.mu.RLock()
+		aead := k.keys[id]
+		k.mu.RUnlock()
+		if aead == nil {
+			return "", fmt.Errorf("%w: %d", ErrUnknownKey, id)
+		}
+		plaintext, err := open(aead, c[5:], aad)
+		if err != nil {
+			return "", err
+		}
+		return string(plaintext), nil
+	default:
+		return "", ErrMalformed
+	}
+}
+
+// Stale 密文是旧明文数据或不是由当前数据密钥加密时需要重新加密
+func (k *Keyring) Stale(c Ciphertext) bool {
+	if len(c) == 0 {
+		return false
+	}
+	return c[0] != versionAESGCM || c.KeyID() != k.ActiveKeyID()
+}
+
+// BlindIndex 计算 HMAC-SHA256(indexKey, context | value) 的前16字节，调用方负责规范化 value，空字符串返回 nil
+func (k *Keyring) BlindIndex(value string, context []byte) BlindIndex {
+	if value == "" {
+		return nil
+	}
+	mac := hmac.New(sha256.New, k.index)
+	mac.Write(context)
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return mac.Sum(nil)[:blindIndexSize]
+}
@@ -0,0 +1,196 @@
+package fieldcrypt
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	conf "connect-go-example/internal/conf/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKeyring(t *testing.T) *Keyring {
+	k, err := NewKeyring(bytes.Repeat([]byte{9}, 32))
+	require.NoError(t, err)
+	require.NoError(t, k.Add(DataKey{ID: 1, Key: bytes.Repeat([]byte{1}, 32)}))
+	require.NoError(t, k.SetActive(1))
+	return k
+}
+
+func TestKeyring_EncryptDecrypt(t *testing.T) {
+	k := testKeyring(t)
+	aad := []byte("users.email:1")
+
+	c, err := k.Encrypt("alice@example.com", aad)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1), c.KeyID())
+	assert.NotContains(t, string(c), "alice")
+
+	plaintext, err := k.Decrypt(c, aad)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice@example.com", plaintext)
+
+	// 密文不能移到其他列或租户
+	_, err = k.Decrypt(c, []byte("users.email:2"))
+	assert.ErrorIs(t, err, ErrDecrypt)
+
+	// 同一个值每次加密的结果不同
+	again, err := k.Encrypt("alice@example.com", aad)
+	assert.NoError(t, err)
+	assert.NotEqual(t, c, again)
+
+	empty, err := k.Encrypt("", aad)
+	assert.NoError(t, err)
+	assert.Nil(t, empty)
+	plaintext, err = k.Decrypt(nil, aad)
+	assert.NoError(t, err)
+	assert.Empty(t, plaintext)
+
+	_, err = k.Decrypt(Ciphertext{7, 1}, aad)
+	assert.ErrorIs(t, err, ErrMalformed)
+	_, err = k.Decrypt(c[:10], aad)
+	assert.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	k := testKeyring(t)
+	aad := []byte("users.display_name:1")
+	old, err := k.Encrypt("Alice", aad)
+	require.NoError(t, err)
+	assert.False(t, k.Stale(old))
+
+	assert.ErrorIs(t, k.SetActive(2), ErrUnknownKey)
+	require.NoError(t, k.Add(DataKey{ID: 2, Key: bytes.Repeat([]byte{2}, 32)}))
+	require.NoError(t, k.SetActive(2))
+
+	// 旧密钥加密的数据仍然可以解密，但需要重新加密
+	assert.True(t, k.Stale(old))
+	plaintext, err := k.Decrypt(old, aad)
+	assert.NoError(t, err)
+	assert.Equal(t, "Alice", plaintext)
+
+	fresh, err := k.Encrypt(plaintext, aad)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), fresh.KeyID())
+	assert.False(t, k.Stale(fresh))
+	assert.False(t, k.Stale(nil))
+
+	other := testKeyring(t)
+	_, err = other.Decrypt(fresh, aad)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestKeyring_Unencrypted(t *testing.T) {
+	k := testKeyring(t)
+	legacy := Unencrypted("bob@example.com")
+
+	assert.Equal(t, uint32(0), legacy.KeyID())
+	assert.True(t, k.Stale(legacy))
+	plaintext, err := k.Decrypt(legacy, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "bob@example.com", plaintext)
+	assert.Nil(t, Unencrypted(""))
+}
+
+func TestKeyring_BlindIndex(t *testing.T) {
+	k := testKeyring(t)
+
+	index := k.BlindIndex("alice@example.com", []byte("1"))
+	assert.Len(t, index, blindIndexSize)
+	assert.Equal(t, index, k.BlindIndex("alice@example.com", []byte("1")))
+	assert.NotEqual(t, index, k.BlindIndex("alice@example.com", []byte("2")))
+	assert.NotEqual(t, index, k.BlindIndex("bob@example.com", []byte("1")))
+	assert.Nil(t, k.BlindIndex("", []byte("1")))
+
+	// 盲索引不随数据密钥轮换
+	require.NoError(t, k.Add(DataKey{ID: 2, Key: bytes.Repeat([]byte{2}, 32)}))
+	require.NoError(t, k.SetActive(2))
+	assert.Equal(t, index, k.BlindIndex("alice@example.com", []byte("1")))
+
+	_, err := NewKeyring([]byte("short"))
+	assert.Error(t, err)
+}
+
+func TestCiphertext_ScanValue(t *testing.T) {
+	var c Ciphertext
+	assert.NoError(t, c.Scan([]byte{1, 2, 3}))
+	assert.Equal(t, Ciphertext{1, 2, 3}, c)
+	v, err := c.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3}, v)
+
+	assert.NoError(t, c.Scan(nil))
+	assert.Nil(t, c)
+	v, err = c.Value()
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+	assert.Error(t, c.Scan(42))
+
+	var b BlindIndex
+	assert.NoError(t, b.Scan([]byte{4, 5}))
+	assert.Equal(t, BlindIndex{4, 5}, b)
+	v, err = BlindIndex(nil).Value()
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+}
+
+func writeKeyfile(t *testing.T, primary string, keys map[string][]byte) string {
+	file := keyfile{Primary: primary, Keys: make(map[string]string)}
+	for id, key := range keys {
+		file.Keys[id] = base64.StdEncoding.EncodeToString(key)
+	}
+	raw, err := json.Marshal(file)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "keys.json")
+	require.NoError(t, os.WriteFile(path, raw, 0o600))
+	return path
+}
+
+func TestKeyfileProvider(t *testing.T) {
+	ctx := context.Background()
+	oldKEK, newKEK := bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 32)
+	dataKey := bytes.Repeat([]byte{3}, 32)
+
+	p, err := NewKeyProvider(&conf.Encryption{
+		Provider: ProviderKeyfile,
+		Keyfile:  writeKeyfile(t, "k1", map[string][]byte{"k1": oldKEK}),
+	})
+	require.NoError(t, err)
+	kekID, wrapped, err := p.Wrap(ctx, dataKey)
+	assert.NoError(t, err)
+	assert.Equal(t, "k1", kekID)
+	assert.NotContains(t, string(wrapped), string(dataKey))
+
+	// 更换主 KEK 后旧 KEK 仍然可以解密，新数据密钥使用新 KEK
+	rotated, err := NewKeyfileProvider(writeKeyfile(t, "k2", map[string][]byte{"k1": oldKEK, "k2": newKEK}))
+	require.NoError(t, err)
+	assert.Equal(t, "k2", rotated.PrimaryKeyID())
+	unwrapped, err := rotated.Unwrap(ctx, kekID, wrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, dataKey, unwrapped)
+
+	_, err = rotated.Unwrap(ctx, "k2", wrapped)
+	assert.ErrorIs(t, err, ErrDecrypt)
+	_, err = rotated.Unwrap(ctx, "k3", wrapped)
+	assert.Error(t, err)
+}
+
+func TestNewKeyProvider_Invalid(t *testing.T) {
+	for _, cfg := range []*conf.Encryption{
+		nil,
+		{Provider: "kms"},
+		{Provider: ProviderKeyfile},
+		{Provider: ProviderKeyfile, Keyfile: filepath.Join(t.TempDir(), "missing.json")},
+		{Provider: ProviderKeyfile, Keyfile: writeKeyfile(t, "k2", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)})},
+		{Provider: ProviderKeyfile, Keyfile: writeKeyfile(t, "k1", map[string][]byte{"k1": []byte("short")})},
+	} {
+		_, err := NewKeyProvider(cfg)
+		assert.Error(t, err, cfg.GetKeyfile())
+	}
+}
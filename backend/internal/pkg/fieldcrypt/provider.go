@@ -0,0 +1,130 @@
+package fieldcrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	conf "connect-go-example/internal/conf/v1"
+)
+
+// KeyProvider 管理密钥加密密钥（KEK），数据密钥只以 KEK 加密后的形式保存。
+// 本地使用 KeyfileProvider，接入 KMS 时实现该接口，KEK 不离开 KMS
+type KeyProvider interface {
+	// PrimaryKeyID 新数据密钥使用的 KEK，数据密钥由其他 KEK 加密时会被重新加密
+	PrimaryKeyID() string
+	// Wrap 用主 KEK 加密数据密钥，返回使用的 KEK 标识
+	Wrap(ctx context.Context, dataKey []byte) (kekID string, wrapped []byte, err error)
+	Unwrap(ctx context.Context, kekID string, wrapped []byte) ([]byte, error)
+}
+
+// KEK 来源
+const ProviderKeyfile = "keyfile"
+
+// NewKeyProvider 按配置创建 KEK 来源
+func NewKeyProvider(cfg *conf.Encryption) (KeyProvider, error) {
+	switch cfg.GetProvider() {
+	case ProviderKeyfile:
+		if cfg.GetKeyfile() == "" {
+			return nil, errors.New("encryption.keyfile is required")
+		}
+		return NewKeyfileProvider(cfg.GetKeyfile())
+	case "":
+		return nil, errors.New("encryption.provider is required for the postgres driver")
+	default:
+		return nil, fmt.Errorf("unknown encryption provider %q", cfg.GetProvider())
+	}
+}
+
+// keyfile 密钥文件格式，keys 的值为 base64 编码的32字节密钥
+type keyfile struct {
+	Primary string            `json:"primary"`
+	Keys    map[string]string `json:"keys"`
+}
+
+// KeyfileProvider 从本地 JSON 文件读取 KEK，文件中可以保留旧 KEK 用于解密尚未重新加密的数据密钥
+type KeyfileProvider struct {
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+func NewKeyfileProvider(path string) (*KeyfileProvider, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read keyfile failed: %w", err)
+	}
+	var file keyfile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("parse keyfile failed: %w", err)
+	}
+
+	p := &KeyfileProvider{primary: file.Primary, keys: make(map[string]cipher.AEAD, len(file.Keys))}
+	for id, encoded := range file.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("decode key %q failed: %w", id, err)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		p.keys[id] = aead
+	}
+	if _, ok := p.keys[p.primary]; !ok {
+		return nil, fmt.Errorf("primary key %q not found in keyfile", p.primary)
+	}
+	return p, nil
+}
+
+func (p *KeyfileProvider) PrimaryKeyID() string {
+	return p.primary
+}
+
+func (p *KeyfileProvider) Wrap(_ context.Context, dataKey []byte) (string, []byte, error) {
+	return p.primary, seal(p.keys[p.primary], dataKey, []byte(p.primary)), nil
+}
+
+func (p *KeyfileProvider) Unwrap(_ context.Context, kekID string, wrapped []byte) ([]byte, error) {
+	aead, ok := p.keys[kekID]
+	if !ok {
+		return nil, fmt.Errorf("key encryption key %q not found in keyfile", kekID)
+	}
+	return open(aead, wrapped, []byte(kekID))
+}
+
+// newAEAD 创建 AES-256-GCM
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, errors.New("key must be 32 bytes")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal 返回 nonce 和密文
+func seal(aead cipher.AEAD, plaintext, aad []byte) []byte {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	_, _ = rand.Read(nonce) // crypto/rand.Read 不会返回错误
+	return aead.Seal(nonce, nonce, plaintext, aad)
+}
+
+func open(aead cipher.AEAD, sealed, aad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrMalformed
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
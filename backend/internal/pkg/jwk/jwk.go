@@ -0,0 +1,81 @@
+// Package jwk 解析 JSON Web Key（RFC 7517）公钥
+package jwk
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Parse 解析 JWK 公钥并计算 RFC 7638 指纹，拒绝包含私钥的 JWK
+func Parse(jwk map[string]any) (crypto.PublicKey, string, error) {
+	if _, ok := jwk["d"]; ok {
+		return nil, "", errors.New("jwk must not contain private key")
+	}
+	member := func(name string) string {
+		value, _ := jwk[name].(string)
+		return value
+	}
+
+	// 指纹只包含必需成员，json.Marshal 按键名排序，与 RFC 7638 的规范形式一致
+	var (
+		key     crypto.PublicKey
+		members map[string]string
+	)
+	switch kty := member("kty"); kty {
+	case "EC":
+		if member("crv") != "P-256" {
+			return nil, "", errors.New("unsupported ec curve")
+		}
+		x, errX := base64.RawURLEncoding.DecodeString(member("x"))
+		y, errY := base64.RawURLEncoding.DecodeString(member("y"))
+		if errX != nil || errY != nil || len(x) != 32 || len(y) != 32 {
+			return nil, "", errors.New("invalid ec key")
+		}
+		ecKey, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), append(append([]byte{4}, x...), y...))
+		if err != nil {
+			return nil, "", err
+		}
+		key = ecKey
+		members = map[string]string{"crv": "P-256", "kty": kty, "x": member("x"), "y": member("y")}
+	case "OKP":
+		if member("crv") != "Ed25519" {
+			return nil, "", errors.New("unsupported okp curve")
+		}
+		x, err := base64.RawURLEncoding.DecodeString(member("x"))
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, "", errors.New("invalid ed25519 key")
+		}
+		key = ed25519.PublicKey(x)
+		members = map[string]string{"crv": "Ed25519", "kty": kty, "x": member("x")}
+	case "RSA":
+		n, errN := base64.RawURLEncoding.DecodeString(member("n"))
+		e, errE := base64.RawURLEncoding.DecodeString(member("e"))
+		if errN != nil || errE != nil || len(e) == 0 || len(e) > 4 {
+			return nil, "", errors.New("invalid rsa key")
+		}
+		rsaKey := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if rsaKey.N.BitLen() < 2048 {
+			return nil, "", errors.New("rsa key too short")
+		}
+		key = rsaKey
+		members = map[string]string{"e": member("e"), "kty": kty, "n": member("n")}
+	default:
+		return nil, "", fmt.Errorf("unsupported jwk kty %q", kty)
+	}
+
+	canonical, err := json.Marshal(members)
+	if err != nil {
+		return nil, "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return key, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
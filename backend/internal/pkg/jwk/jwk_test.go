@@ -0,0 +1,72 @@
+package jwk
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ecJWK(t *testing.T) map[string]any {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	raw, err := key.PublicKey.Bytes()
+	assert.NoError(t, err)
+	return map[string]any{
+		"kty": "EC",
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(raw[1:33]),
+		"y":   base64.RawURLEncoding.EncodeToString(raw[33:]),
+	}
+}
+
+func TestParse(t *testing.T) {
+	jwk := ecJWK(t)
+
+	key, thumbprint, err := Parse(jwk)
+	assert.NoError(t, err)
+	assert.IsType(t, &ecdsa.PublicKey{}, key)
+	assert.NotEmpty(t, thumbprint)
+
+	// kid、use 等可选成员不影响指纹
+	jwk["kid"], jwk["use"] = "key-1", "sig"
+	_, other, err := Parse(jwk)
+	assert.NoError(t, err)
+	assert.Equal(t, thumbprint, other)
+}
+
+func TestParse_RFC7638Example(t *testing.T) {
+	// RFC 7638 3.1 的示例密钥
+	n := "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw"
+	key, thumbprint, err := Parse(map[string]any{"kty": "RSA", "e": "AQAB", "alg": "RS256", "kid": "2011-04-29", "n": n})
+	assert.NoError(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", thumbprint)
+	assert.Equal(t, 65537, key.(*rsa.PublicKey).E)
+}
+
+func TestParse_Rejected(t *testing.T) {
+	withPrivate := ecJWK(t)
+	withPrivate["d"] = "private"
+
+	short, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(t, err)
+
+	for name, jwk := range map[string]map[string]any{
+		"private key":   withPrivate,
+		"symmetric":     {"kty": "oct", "k": "c2VjcmV0"},
+		"unknown curve": {"kty": "EC", "crv": "P-384", "x": "AA", "y": "AA"},
+		"short rsa": {
+			"kty": "RSA",
+			"n":   base64.RawURLEncoding.EncodeToString(short.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(short.E)).Bytes()),
+		},
+	} {
+		_, _, err := Parse(jwk)
+		assert.Error(t, err, name)
+	}
+}
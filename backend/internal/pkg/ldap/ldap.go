@@ -0,0 +1,129 @@
+// Package ldap 通过 LDAP 查找并绑定用户，校验目录服务中的密码
+package ldap
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	confv1 "connect-go-example/internal/conf/v1"
+
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+var (
+	// ErrInvalidCredentials 用户不存在、不唯一或密码错误
+	ErrInvalidCredentials = errors.New("invalid directory credentials")
+)
+
+// Entry 目录中的用户属性
+type Entry struct {
+	DN          string
+	Email       string
+	DisplayName string
+	GivenName   string
+	FamilyName  string
+}
+
+// Directory 一个 LDAP 目录，每次认证使用新连接
+type Directory struct {
+	cfg        *confv1.Credential_Ldap
+	filter     string
+	attributes [4]string // 邮箱、显示名、名、姓
+	timeout    time.Duration
+	tlsConfig  *tls.Config
+}
+
+func NewDirectory(cfg *confv1.Credential_Ldap) (*Directory, error) {
+	u, err := url.Parse(cfg.Url)
+	if err != nil || (u.Scheme != "ldap" && u.Scheme != "ldaps") || u.Host == "" {
+		return nil, fmt.Errorf("invalid ldap url %q", cfg.Url)
+	}
+	if cfg.BaseDn == "" {
+		return nil, errors.New("ldap base_dn is required")
+	}
+
+	d := &Directory{
+		cfg:        cfg,
+		filter:     withDefault(cfg.UserFilter, "(uid={username})"),
+		attributes: [4]string{withDefault(cfg.EmailAttribute, "mail"), withDefault(cfg.DisplayNameAttribute, "displayName"), withDefault(cfg.GivenNameAttribute, "givenName"), withDefault(cfg.FamilyNameAttribute, "sn")},
+		timeout:    5 * time.Second, // 默认5秒
+		tlsConfig:  &tls.Config{ServerName: u.Hostname(), InsecureSkipVerify: cfg.InsecureSkipVerify},
+	}
+	if !strings.Contains(d.filter, "{username}") {
+		return nil, fmt.Errorf("ldap user_filter %q must contain {username}", d.filter)
+	}
+	if cfg.TimeoutSeconds > 0 {
+		d.timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	return d, nil
+}
+
+// Authenticate 先用服务账号按用户名查找唯一的条目，再以该条目的 DN 和密码绑定
+func (d *Directory) Authenticate(username, password string) (*Entry, error) {
+	// 空密码会被服务器当作匿名绑定而成功（RFC 4513 5.1.2）
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := goldap.DialURL(d.cfg.Url, goldap.DialWithDialer(&net.Dialer{Timeout: d.timeout}), goldap.DialWithTLSConfig(d.tlsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("connect to ldap failed: %v", err)
+	}
+	defer conn.Close()
+	conn.SetTimeout(d.timeout)
+
+	if d.cfg.StartTls {
+		if err := conn.StartTLS(d.tlsConfig); err != nil {
+			return nil, fmt.Errorf("ldap start tls failed: %v", err)
+		}
+	}
+	if d.cfg.BindDn != "" {
+		if err := conn.Bind(d.cfg.BindDn, d.cfg.BindPassword); err != nil {
+			return nil, fmt.Errorf("ldap service bind failed: %v", err)
+		}
+	}
+
+	result, err := conn.Search(goldap.NewSearchRequest(
+		d.cfg.BaseDn,
+		goldap.ScopeWholeSubtree, goldap.NeverDerefAliases,
+		2, int(d.timeout.Seconds()), false,
+		strings.ReplaceAll(d.filter, "{username}", goldap.EscapeFilter(username)),
+		d.attributes[:],
+		nil,
+	))
+	if err != nil && !goldap.IsErrorWithCode(err, goldap.LDAPResultSizeLimitExceeded) {
+		return nil, fmt.Errorf("ldap search failed: %v", err)
+	}
+	// 找不到或匹配到多个条目时都不能确定是哪个用户
+	if result == nil || len(result.Entries) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+	entry := result.Entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("ldap bind failed: %v", err)
+	}
+
+	return &Entry{
+		DN:          entry.DN,
+		Email:       entry.GetAttributeValue(d.attributes[0]),
+		DisplayName: entry.GetAttributeValue(d.attributes[1]),
+		GivenName:   entry.GetAttributeValue(d.attributes[2]),
+		FamilyName:  entry.GetAttributeValue(d.attributes[3]),
+	}, nil
+}
+
+func withDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,106 @@
+package ldap
+
+import (
+	"testing"
+
+	confv1 "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/pkg/ldap/ldaptest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const serviceDN = "cn=svc,dc=example,dc=com"
+
+func newTestDirectory(t *testing.T) (*ldaptest.Server, *Directory) {
+	server := ldaptest.NewServer(
+		&ldaptest.Entry{DN: serviceDN, Password: "svc-secret"},
+		&ldaptest.Entry{
+			DN:       "uid=alice,ou=people,dc=example,dc=com",
+			Password: "wonderland",
+			Attributes: map[string][]string{
+				"uid":         {"alice"},
+				"mail":        {"alice@example.com"},
+				"displayName": {"Alice Liddell"},
+				"givenName":   {"Alice"},
+				"sn":          {"Liddell"},
+			},
+		},
+	)
+	t.Cleanup(server.Close)
+
+	d, err := NewDirectory(&confv1.Credential_Ldap{
+		Url:          server.URL,
+		BindDn:       serviceDN,
+		BindPassword: "svc-secret",
+		BaseDn:       "dc=example,dc=com",
+	})
+	assert.NoError(t, err)
+	return server, d
+}
+
+func TestAuthenticate(t *testing.T) {
+	server, d := newTestDirectory(t)
+
+	entry, err := d.Authenticate("alice", "wonderland")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Entry{
+		DN:          "uid=alice,ou=people,dc=example,dc=com",
+		Email:       "alice@example.com",
+		DisplayName: "Alice Liddell",
+		GivenName:   "Alice",
+		FamilyName:  "Liddell",
+	}, entry)
+	// 先以服务账号查找，再以用户绑定
+	assert.Equal(t, []string{serviceDN, "uid=alice,ou=people,dc=example,dc=com"}, server.Binds())
+}
+
+func TestAuthenticate_Rejected(t *testing.T) {
+	server, d := newTestDirectory(t)
+	// 与 alice 同名的第二个条目，查找结果不唯一
+	server.Add(&ldaptest.Entry{DN: "uid=bob,ou=people,dc=example,dc=com", Password: "builder", Attributes: map[string][]string{"uid": {"bob"}}})
+	server.Add(&ldaptest.Entry{DN: "uid=bob,ou=contractors,dc=example,dc=com", Password: "builder", Attributes: map[string][]string{"uid": {"bob"}}})
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+	}{
+		{"wrong password", "alice", "queen-of-hearts"},
+		{"empty password", "alice", ""},
+		{"unknown user", "carol", "wonderland"},
+		{"ambiguous user", "bob", "builder"},
+		{"filter injection", "*)(uid=alice", "wonderland"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := d.Authenticate(tt.username, tt.password)
+
+			assert.ErrorIs(t, err, ErrInvalidCredentials)
+		})
+	}
+}
+
+func TestAuthenticate_ServiceBindFailed(t *testing.T) {
+	server := ldaptest.NewServer()
+	defer server.Close()
+	d, err := NewDirectory(&confv1.Credential_Ldap{Url: server.URL, BindDn: serviceDN, BindPassword: "wrong", BaseDn: "dc=example,dc=com"})
+	assert.NoError(t, err)
+
+	_, err = d.Authenticate("alice", "wonderland")
+
+	// 服务账号配置错误不是用户的凭证错误
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestNewDirectory_Invalid(t *testing.T) {
+	for _, cfg := range []*confv1.Credential_Ldap{
+		{Url: "http://ldap.example.com", BaseDn: "dc=example,dc=com"},
+		{Url: "ldap://ldap.example.com"},
+		{Url: "ldap://ldap.example.com", BaseDn: "dc=example,dc=com", UserFilter: "(uid=alice)"},
+	} {
+		_, err := NewDirectory(cfg)
+		assert.Error(t, err, cfg.Url)
+	}
+}
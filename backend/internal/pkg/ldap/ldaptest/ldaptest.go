@@ -0,0 +1,255 @@
+// Package ldaptest 提供测试用的进程内 LDAP 服务器，只支持简单绑定和查找
+package ldaptest
+
+import (
+	"net"
+	"strings"
+	"sync"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+// Entry 目录中的一个条目，Password 为空时不能绑定
+type Entry struct {
+	DN         string
+	Password   string
+	Attributes map[string][]string
+}
+
+// Server 监听本地端口的 LDAP 服务器，未绑定或匿名绑定时不允许查找
+type Server struct {
+	URL string
+	// AnonymousSearch 允许匿名查找
+	AnonymousSearch bool
+
+	ln      net.Listener
+	mu      sync.Mutex
+	entries []*Entry
+	conns   map[net.Conn]struct{}
+	binds   []string
+	wg      sync.WaitGroup
+}
+
+func NewServer(entries ...*Entry) *Server {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	s := &Server{
+		URL:     "ldap://" + ln.Addr().String(),
+		ln:      ln,
+		entries: entries,
+		conns:   make(map[net.Conn]struct{}),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+// Add 添加条目
+func (s *Server) Add(entry *Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+}
+
+// Binds 返回成功绑定的 DN，按时间顺序
+func (s *Server) Binds() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.binds...)
+}
+
+// Close 关闭监听和所有连接
+func (s *Server) Close() {
+	_ = s.ln.Close()
+	s.mu.Lock()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+			_ = conn.Close()
+		}()
+	}
+}
+
+// handle 处理一个连接上的请求，遇到不支持的操作时断开连接
+func (s *Server) handle(conn net.Conn) {
+	bound := ""
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		id, ok := packet.Children[0].Value.(int64)
+		if !ok {
+			return
+		}
+
+		op := packet.Children[1]
+		var responses []*ber.Packet
+		switch op.Tag {
+		case goldap.ApplicationBindRequest:
+			var code uint16
+			bound, code = s.bind(op)
+			responses = append(responses, result(goldap.ApplicationBindResponse, code))
+		case goldap.ApplicationSearchRequest:
+			if bound == "" && !s.AnonymousSearch {
+				responses = append(responses, result(goldap.ApplicationSearchResultDone, goldap.LDAPResultInsufficientAccessRights))
+				break
+			}
+			responses = append(s.search(op), result(goldap.ApplicationSearchResultDone, goldap.LDAPResultSuccess))
+		default:
+			// 包括 Unbind
+			return
+		}
+
+		for _, response := range responses {
+			message := ber.NewSequence("LDAP Response")
+			message.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "MessageID"))
+			message.AppendChild(response)
+			if _, err := conn.Write(message.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// bind 简单绑定，返回绑定后的 DN，匿名绑定时为空
+func (s *Server) bind(op *ber.Packet) (string, uint16) {
+	if len(op.Children) < 3 {
+		return "", goldap.LDAPResultProtocolError
+	}
+	dn, password := op.Children[1].Data.String(), op.Children[2].Data.String()
+	if dn == "" && password == "" {
+		return "", goldap.LDAPResultSuccess
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, entry := range s.entries {
+		if strings.EqualFold(entry.DN, dn) && entry.Password != "" && entry.Password == password {
+			s.binds = append(s.binds, entry.DN)
+			return entry.DN, goldap.LDAPResultSuccess
+		}
+	}
+	return "", goldap.LDAPResultInvalidCredentials
+}
+
+func (s *Server) search(op *ber.Packet) []*ber.Packet {
+	if len(op.Children) < 8 {
+		return nil
+	}
+	baseDN, filter := strings.ToLower(op.Children[0].Data.String()), op.Children[6]
+	var requested []string
+	for _, attr := range op.Children[7].Children {
+		requested = append(requested, attr.Data.String())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var found []*ber.Packet
+	for _, entry := range s.entries {
+		if !strings.HasSuffix(strings.ToLower(entry.DN), baseDN) || !matches(entry, filter) {
+			continue
+		}
+
+		packet := ber.Encode(ber.ClassApplication, ber.TypeConstructed, goldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+		packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, "Object Name"))
+		attributes := ber.NewSequence("Attributes")
+		for _, name := range requested {
+			values, ok := attribute(entry, name)
+			if !ok {
+				continue
+			}
+			attr := ber.NewSequence("Attribute")
+			attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+			set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+			for _, value := range values {
+				set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+			}
+			attr.AppendChild(set)
+			attributes.AppendChild(attr)
+		}
+		packet.AppendChild(attributes)
+		found = append(found, packet)
+	}
+	return found
+}
+
+// matches 支持 and、or、not、相等和存在过滤器，属性名和值都不区分大小写
+func matches(entry *Entry, filter *ber.Packet) bool {
+	switch filter.Tag {
+	case goldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !matches(entry, child) {
+				return false
+			}
+		}
+		return true
+	case goldap.FilterOr:
+		for _, child := range filter.Children {
+			if matches(entry, child) {
+				return true
+			}
+		}
+		return false
+	case goldap.FilterNot:
+		return len(filter.Children) == 1 && !matches(entry, filter.Children[0])
+	case goldap.FilterEqualityMatch:
+		if len(filter.Children) != 2 {
+			return false
+		}
+		values, _ := attribute(entry, filter.Children[0].Data.String())
+		for _, value := range values {
+			if strings.EqualFold(value, filter.Children[1].Data.String()) {
+				return true
+			}
+		}
+		return false
+	case goldap.FilterPresent:
+		_, ok := attribute(entry, filter.Data.String())
+		return ok
+	default:
+		return false
+	}
+}
+
+func attribute(entry *Entry, name string) ([]string, bool) {
+	for key, values := range entry.Attributes {
+		if strings.EqualFold(key, name) {
+			return values, true
+		}
+	}
+	return nil, false
+}
+
+func result(tag ber.Tag, code uint16) *ber.Packet {
+	packet := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "Result Code"))
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, goldap.LDAPResultCodeMap[code], "Diagnostic Message"))
+	return packet
+}
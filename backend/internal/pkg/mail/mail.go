@@ -0,0 +1,145 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	confv1 "connect-go-example/internal/conf/v1"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	DriverFile = "file"
+	DriverSMTP = "smtp"
+)
+
+// Module 提供 Fx 模块
+var Module = fx.Module("mail",
+	fx.Provide(NewMailer),
+)
+
+// Message 待发送的邮件
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer 邮件发送接口
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// NewMailer 根据配置创建邮件发送器，未配置时写入本地目录
+func NewMailer(conf *confv1.Bootstrap, logger *zap.Logger) (Mailer, error) {
+	cfg := conf.Mail
+	if cfg == nil {
+		cfg = &confv1.Mail{}
+	}
+
+	switch cfg.Driver {
+	case "", DriverFile:
+		dir := cfg.FileDir
+		if dir == "" {
+			dir = filepath.Join(os.TempDir(), "connect-example-mail")
+		}
+		logger.Info("Mail delivery uses local file sink", zap.String("dir", dir))
+		return NewFileMailer(dir, cfg.From), nil
+	case DriverSMTP:
+		if cfg.Smtp == nil || cfg.Smtp.Host == "" {
+			return nil, fmt.Errorf("mail.smtp.host is required for smtp driver")
+		}
+		return NewSMTPMailer(cfg.Smtp, cfg.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %s", cfg.Driver)
+	}
+}
+
+// FileMailer 把邮件写成 .eml 文件，用于本地开发
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir, from string) *FileMailer {
+	return &FileMailer{dir: dir, from: from}
+}
+
+func (m *FileMailer) Send(_ context.Context, msg *Message) error {
+	if err := os.MkdirAll(m.dir, 0o700); err != nil {
+		return fmt.Errorf("create mail dir failed: %w", err)
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405"), hex.EncodeToString(suffix))
+
+	body, err := buildMessage(m.from, msg)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(m.dir, name), body, 0o600)
+}
+
+// SMTPMailer 通过 SMTP 服务器发送邮件
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPMailer(cfg *confv1.Mail_SMTP, from string) *SMTPMailer {
+	port := int(cfg.Port)
+	if port == 0 {
+		port = 587
+	}
+
+	m := &SMTPMailer{
+		addr: net.JoinHostPort(cfg.Host, strconv.Itoa(port)),
+		from: from,
+	}
+	if cfg.Username != "" {
+		m.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return m
+}
+
+func (m *SMTPMailer) Send(_ context.Context, msg *Message) error {
+	body, err := buildMessage(m.from, msg)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, body)
+}
+
+func buildMessage(from string, msg *Message) ([]byte, error) {
+	// 防止通过收件人或主题注入额外的邮件头
+	if strings.ContainsAny(msg.To+msg.Subject, "\r\n") {
+		return nil, errors.New("mail header contains line break")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String()), nil
+}
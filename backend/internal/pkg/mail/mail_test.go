@@ -0,0 +1,88 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	confv1 "connect-go-example/internal/conf/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+// MailTestSuite 是 Mail 的测试套件
+type MailTestSuite struct {
+	suite.Suite
+	logger *zap.Logger
+}
+
+func (suite *MailTestSuite) SetupTest() {
+	suite.logger = zap.NewNop()
+}
+
+func (suite *MailTestSuite) TestNewMailer_DefaultFile() {
+	// 未配置时使用本地文件
+	mailer, err := NewMailer(&confv1.Bootstrap{}, suite.logger)
+
+	assert.NoError(suite.T(), err)
+	assert.IsType(suite.T(), &FileMailer{}, mailer)
+}
+
+func (suite *MailTestSuite) TestNewMailer_SMTP() {
+	mailer, err := NewMailer(&confv1.Bootstrap{Mail: &confv1.Mail{
+		Driver: DriverSMTP,
+		Smtp:   &confv1.Mail_SMTP{Host: "smtp.example.com"},
+	}}, suite.logger)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "smtp.example.com:587", mailer.(*SMTPMailer).addr)
+
+	// 缺少主机
+	_, err = NewMailer(&confv1.Bootstrap{Mail: &confv1.Mail{Driver: DriverSMTP}}, suite.logger)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *MailTestSuite) TestNewMailer_UnknownDriver() {
+	_, err := NewMailer(&confv1.Bootstrap{Mail: &confv1.Mail{Driver: "pigeon"}}, suite.logger)
+
+	assert.Error(suite.T(), err)
+}
+
+func (suite *MailTestSuite) TestFileMailer_Send() {
+	dir := suite.T().TempDir()
+	mailer := NewFileMailer(dir, "noreply@example.com")
+
+	err := mailer.Send(context.Background(), &Message{
+		To:      "alice@example.com",
+		Subject: "Hello",
+		Body:    "line1\nline2",
+	})
+	assert.NoError(suite.T(), err)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	assert.Len(suite.T(), files, 1)
+
+	content, err := os.ReadFile(files[0])
+	assert.NoError(suite.T(), err)
+	assert.Contains(suite.T(), string(content), "To: alice@example.com\r\n")
+	assert.Contains(suite.T(), string(content), "Subject: Hello\r\n")
+	assert.Contains(suite.T(), string(content), "line1\r\nline2")
+}
+
+func (suite *MailTestSuite) TestFileMailer_RejectsHeaderInjection() {
+	mailer := NewFileMailer(suite.T().TempDir(), "noreply@example.com")
+
+	err := mailer.Send(context.Background(), &Message{
+		To:      "alice@example.com\r\nBcc: mallory@example.com",
+		Subject: "Hello",
+	})
+
+	assert.Error(suite.T(), err)
+}
+
+func TestMailTestSuite(t *testing.T) {
+	suite.Run(t, new(MailTestSuite))
+}
@@ -0,0 +1,363 @@
+// Package migrate 执行内嵌的版本化 SQL 迁移，已应用的版本记录在 schema_migrations 表中
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// lockKey 迁移使用的 advisory lock，同一数据库同时只有一个进程执行迁移
+const lockKey int64 = 7_202_504_110_001
+
+const createTableSQL = `CREATE TABLE IF NOT EXISTS schema_migrations
+(
+    version    BIGINT PRIMARY KEY,
+    name       VARCHAR(255)              NOT NULL,
+    checksum   VARCHAR(64)               NOT NULL, -- up 脚本的 sha256
+    applied_at timestamptz DEFAULT now() NOT NULL
+)`
+
+// existingTablesSQL 当前 schema 中是否有 schema_migrations 以外的表
+const existingTablesSQL = `SELECT EXISTS (SELECT 1
+               FROM pg_tables
+               WHERE schemaname = current_schema()
+                 AND tablename <> 'schema_migrations')`
+
+var (
+	// ErrNoDownScript 迁移没有 down 脚本，不能回滚
+	ErrNoDownScript = errors.New("migration has no down script")
+	// ErrModified 已应用的迁移脚本被修改，数据库结构与脚本不一致
+	ErrModified = errors.New("migration was modified after it was applied")
+	// ErrUnversioned 数据库中已有表但没有迁移记录，需要先执行 baseline
+	ErrUnversioned = errors.New("database has tables but no migration history, run `migrate baseline` first")
+	// ErrBaselined 已有迁移记录的数据库不能再执行 baseline
+	ErrBaselined = errors.New("database already has migration history")
+
+	fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+)
+
+// Migration 一个版本的迁移脚本
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Status 迁移在数据库中的状态
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	// Modified 应用后 up 脚本被修改
+	Modified bool
+	// Missing 数据库中已应用，但当前版本没有该脚本
+	Missing bool
+}
+
+// Load 读取 fsys 根目录下的迁移脚本，按版本号升序返回，每个版本必须有 up 脚本
+func Load(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations failed: %v", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read migration %q failed: %v", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d has different names %q and %q", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+			sum := sha256.Sum256(content)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", m.Version, m.Name)
+		}
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator 在一个 PostgreSQL 数据库上执行迁移
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []*Migration
+	l          *zap.Logger
+}
+
+func New(pool *pgxpool.Pool, fsys fs.FS, logger *zap.Logger) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{pool: pool, migrations: migrations, l: logger}, nil
+}
+
+// applied schema_migrations 中的一条记录
+type applied struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// Up 按顺序执行所有未应用的迁移，返回执行的数量。
+// 已应用的脚本被修改，或数据库已有表却没有迁移记录（按旧的 schema.sql 手工建库）时不执行
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	count := 0
+	err := m.withLock(ctx, func(conn *pgxpool.Conn, done map[int64]applied) error {
+		if err := m.verify(done); err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			var exists bool
+			if err := conn.QueryRow(ctx, existingTablesSQL).Scan(&exists); err != nil {
+				return fmt.Errorf("inspect existing tables failed: %v", err)
+			}
+			if exists {
+				return ErrUnversioned
+			}
+		}
+		for _, migration := range m.migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			if err := m.up(ctx, conn, migration); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Baseline 把 version 及之前的迁移记录为已应用但不执行，用于接管按旧 schema.sql 手工创建的数据库，
+// 只能在没有迁移记录的数据库上执行，返回记录的数量
+func (m *Migrator) Baseline(ctx context.Context, version int64) (int, error) {
+	count := 0
+	err := m.withLock(ctx, func(conn *pgxpool.Conn, done map[int64]applied) error {
+		baseline, err := m.baseline(done, version)
+		if err != nil {
+			return err
+		}
+		return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			for _, migration := range baseline {
+				if _, err := tx.Exec(ctx, "INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)",
+					migration.Version, migration.Name, migration.Checksum); err != nil {
+					return fmt.Errorf("record migration %d_%s failed: %v", migration.Version, migration.Name, err)
+				}
+				m.l.Info("Migration baselined", zap.Int64("version", migration.Version), zap.String("name", migration.Name))
+				count++
+			}
+			return nil
+		})
+	})
+	return count, err
+}
+
+// baseline 返回需要记录的迁移
+func (m *Migrator) baseline(done map[int64]applied, version int64) ([]*Migration, error) {
+	if len(done) > 0 {
+		return nil, ErrBaselined
+	}
+	var baseline []*Migration
+	found := false
+	for _, migration := range m.migrations {
+		if migration.Version > version {
+			break
+		}
+		baseline = append(baseline, migration)
+		found = found || migration.Version == version
+	}
+	if !found {
+		return nil, fmt.Errorf("unknown migration version %d", version)
+	}
+	return baseline, nil
+}
+
+// verify 检查已应用的迁移脚本没有被修改
+func (m *Migrator) verify(done map[int64]applied) error {
+	for _, migration := range m.migrations {
+		if a, ok := done[migration.Version]; ok && a.checksum != migration.Checksum {
+			return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, ErrModified)
+		}
+	}
+	return nil
+}
+
+// Down 按版本号从大到小回滚 steps 个已应用的迁移，返回回滚的数量
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	count := 0
+	err := m.withLock(ctx, func(conn *pgxpool.Conn, done map[int64]applied) error {
+		for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			if err := m.down(ctx, conn, migration); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Redo 回滚并重新执行最后一个已应用的迁移
+func (m *Migrator) Redo(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *pgxpool.Conn, done map[int64]applied) error {
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			if err := m.down(ctx, conn, migration); err != nil {
+				return err
+			}
+			return m.up(ctx, conn, migration)
+		}
+		return errors.New("no applied migration to redo")
+	})
+}
+
+// Status 返回所有迁移的状态，按版本号升序
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.withLock(ctx, func(_ *pgxpool.Conn, done map[int64]applied) error {
+		for _, migration := range m.migrations {
+			s := Status{Version: migration.Version, Name: migration.Name}
+			if a, ok := done[migration.Version]; ok {
+				s.Applied, s.AppliedAt, s.Modified = true, a.appliedAt, a.checksum != migration.Checksum
+				delete(done, migration.Version)
+			}
+			statuses = append(statuses, s)
+		}
+		for version, a := range done {
+			statuses = append(statuses, Status{Version: version, Name: a.name, Applied: true, AppliedAt: a.appliedAt, Missing: true})
+		}
+		return nil
+	})
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, err
+}
+
+// withLock 持有 advisory lock 执行 fn，其他副本会等待当前副本执行完成
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn, done map[int64]applied) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection failed: %v", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("acquire migration lock failed: %v", err)
+	}
+	defer func() {
+		// 会话级锁随连接保留，解锁失败时关闭连接，避免连接回到池中后仍持有锁
+		if _, err := conn.Exec(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", lockKey); err != nil {
+			m.l.Warn("release migration lock failed", zap.Error(err))
+			_ = conn.Conn().Close(context.WithoutCancel(ctx))
+		}
+	}()
+
+	if _, err := conn.Exec(ctx, createTableSQL); err != nil {
+		return fmt.Errorf("create schema_migrations failed: %v", err)
+	}
+	rows, err := conn.Query(ctx, "SELECT version, name, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return fmt.Errorf("query schema_migrations failed: %v", err)
+	}
+	done := make(map[int64]applied)
+	var version int64
+	var a applied
+	if _, err := pgx.ForEachRow(rows, []any{&version, &a.name, &a.checksum, &a.appliedAt}, func() error {
+		done[version] = a
+		return nil
+	}); err != nil {
+		return fmt.Errorf("query schema_migrations failed: %v", err)
+	}
+
+	return fn(conn, done)
+}
+
+// up 在一个事务中执行 up 脚本并记录版本
+func (m *Migrator) up(ctx context.Context, conn *pgxpool.Conn, migration *Migration) error {
+	start := time.Now()
+	err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, migration.Up); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, "INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)",
+			migration.Version, migration.Name, migration.Checksum)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("apply migration %d_%s failed: %v", migration.Version, migration.Name, err)
+	}
+	m.l.Info("Migration applied", zap.Int64("version", migration.Version), zap.String("name", migration.Name), zap.Duration("took", time.Since(start)))
+	return nil
+}
+
+// down 在一个事务中执行 down 脚本并删除版本记录
+func (m *Migrator) down(ctx context.Context, conn *pgxpool.Conn, migration *Migration) error {
+	if migration.Down == "" {
+		return fmt.Errorf("revert migration %d_%s failed: %w", migration.Version, migration.Name, ErrNoDownScript)
+	}
+	start := time.Now()
+	err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, migration.Down); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, "DELETE FROM schema_migrations WHERE version = $1", migration.Version)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("revert migration %d_%s failed: %v", migration.Version, migration.Name, err)
+	}
+	m.l.Info("Migration reverted", zap.Int64("version", migration.Version), zap.String("name", migration.Name), zap.Duration("took", time.Since(start)))
+	return nil
+}
@@ -0,0 +1,110 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"connect-go-example/internal/data/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load(fstest.MapFS{
+		"000002_add_email.up.sql":   {Data: []byte("ALTER TABLE users ADD email TEXT;")},
+		"000002_add_email.down.sql": {Data: []byte("ALTER TABLE users DROP email;")},
+		"000001_init.up.sql":        {Data: []byte("CREATE TABLE users (id INT);")},
+		"000010_seed.up.sql":        {Data: []byte("INSERT INTO users VALUES (1);")},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, migrations, 3)
+	// 按版本号而不是文件名排序
+	assert.Equal(t, []int64{1, 2, 10}, []int64{migrations[0].Version, migrations[1].Version, migrations[2].Version})
+	assert.Equal(t, "add_email", migrations[1].Name)
+	assert.Equal(t, "ALTER TABLE users DROP email;", migrations[1].Down)
+	assert.Empty(t, migrations[2].Down)
+	assert.Len(t, migrations[0].Checksum, 64)
+	assert.NotEqual(t, migrations[0].Checksum, migrations[1].Checksum)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{"bad file name", fstest.MapFS{"init.sql": {Data: []byte("SELECT 1;")}}},
+		{"zero version", fstest.MapFS{"0_init.up.sql": {Data: []byte("SELECT 1;")}}},
+		{"missing up", fstest.MapFS{"000001_init.down.sql": {Data: []byte("SELECT 1;")}}},
+		{"empty up", fstest.MapFS{"000001_init.up.sql": {Data: []byte("")}}},
+		{"name mismatch", fstest.MapFS{
+			"000001_init.up.sql":     {Data: []byte("SELECT 1;")},
+			"000001_create.up.sql":   {Data: []byte("SELECT 1;")},
+			"000001_create.down.sql": {Data: []byte("SELECT 1;")},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.fsys)
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoad_EmbeddedSchema(t *testing.T) {
+	migrations, err := Load(schema.Migrations)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+	for _, m := range migrations {
+		// 迁移在已有的数据库中执行，不能包含建库语句
+		assert.NotContains(t, strings.ToUpper(m.Up), "CREATE DATABASE", m.Name)
+		assert.NotEmpty(t, m.Down, m.Name)
+	}
+}
+
+func testMigrator(t *testing.T) *Migrator {
+	migrations, err := Load(fstest.MapFS{
+		"000001_init.up.sql":      {Data: []byte("CREATE TABLE users (id INT);")},
+		"000002_add_email.up.sql": {Data: []byte("ALTER TABLE users ADD email TEXT;")},
+		"000003_seed.up.sql":      {Data: []byte("INSERT INTO users VALUES (1);")},
+	})
+	assert.NoError(t, err)
+	return &Migrator{migrations: migrations}
+}
+
+func TestMigrator_Verify(t *testing.T) {
+	m := testMigrator(t)
+
+	assert.NoError(t, m.verify(map[int64]applied{}))
+	assert.NoError(t, m.verify(map[int64]applied{
+		1: {checksum: m.migrations[0].Checksum},
+		2: {checksum: m.migrations[1].Checksum},
+		// 数据库比当前版本新时不影响
+		9: {checksum: "unknown"},
+	}))
+
+	err := m.verify(map[int64]applied{1: {checksum: m.migrations[0].Checksum}, 2: {checksum: "edited"}})
+	assert.ErrorIs(t, err, ErrModified)
+	assert.Contains(t, err.Error(), "2_add_email")
+}
+
+func TestMigrator_Baseline(t *testing.T) {
+	m := testMigrator(t)
+
+	baseline, err := m.baseline(map[int64]applied{}, 1)
+	assert.NoError(t, err)
+	assert.Len(t, baseline, 1)
+	assert.Equal(t, int64(1), baseline[0].Version)
+
+	baseline, err = m.baseline(map[int64]applied{}, 2)
+	assert.NoError(t, err)
+	assert.Len(t, baseline, 2)
+
+	_, err = m.baseline(map[int64]applied{}, 7)
+	assert.Error(t, err)
+	_, err = m.baseline(map[int64]applied{1: {checksum: m.migrations[0].Checksum}}, 1)
+	assert.ErrorIs(t, err, ErrBaselined)
+}
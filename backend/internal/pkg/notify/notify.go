@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	confv1 "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/pkg/mail"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	DriverLog  = "log"
+	DriverMail = "mail"
+)
+
+// 通知类型
+const (
+	KindNewSignIn = "new_sign_in"
+	KindStepUp    = "step_up"
+)
+
+// ErrNoRecipient 用户没有可用的联系方式
+var ErrNoRecipient = errors.New("notification has no recipient")
+
+// Module 提供 Fx 模块
+var Module = fx.Module("notify",
+	fx.Provide(NewNotifier),
+)
+
+// Notification 发给用户的安全通知
+type Notification struct {
+	Kind     string
+	TenantID int64
+	UserID   int64
+	Username string
+	Email    string
+	Subject  string
+	Body     string
+}
+
+// Notifier 用户通知接口
+type Notifier interface {
+	Notify(ctx context.Context, n *Notification) error
+	// Reachable 是否能把通知送达用户，不实际发送
+	Reachable(n *Notification) bool
+}
+
+// NewNotifier 根据配置创建通知发送器，未配置时只写日志
+func NewNotifier(conf *confv1.Bootstrap, mailer mail.Mailer, logger *zap.Logger) (Notifier, error) {
+	driver := ""
+	if conf.Notify != nil {
+		driver = conf.Notify.Driver
+	}
+
+	switch driver {
+	case "", DriverLog:
+		return NewLogNotifier(logger), nil
+	case DriverMail:
+		return NewMailNotifier(mailer), nil
+	default:
+		return nil, fmt.Errorf("unknown notify driver: %s", driver)
+	}
+}
+
+// LogNotifier 把通知写入日志，用于本地开发
+type LogNotifier struct {
+	l *zap.Logger
+}
+
+func NewLogNotifier(logger *zap.Logger) *LogNotifier {
+	return &LogNotifier{l: logger}
+}
+
+func (n *LogNotifier) Notify(_ context.Context, notification *Notification) error {
+	n.l.Info("user notification",
+		zap.String("kind", notification.Kind),
+		zap.Int64("tenant_id", notification.TenantID),
+		zap.Int64("user_id", notification.UserID),
+		zap.String("subject", notification.Subject),
+		zap.String("body", notification.Body),
+	)
+	return nil
+}
+
+func (n *LogNotifier) Reachable(*Notification) bool {
+	return true
+}
+
+// MailNotifier 把通知发送到用户邮箱
+type MailNotifier struct {
+	mailer mail.Mailer
+}
+
+func NewMailNotifier(mailer mail.Mailer) *MailNotifier {
+	return &MailNotifier{mailer: mailer}
+}
+
+func (n *MailNotifier) Notify(ctx context.Context, notification *Notification) error {
+	if notification.Email == "" {
+		return ErrNoRecipient
+	}
+	return n.mailer.Send(ctx, &mail.Message{
+		To:      notification.Email,
+		Subject: notification.Subject,
+		Body:    notification.Body,
+	})
+}
+
+func (n *MailNotifier) Reachable(notification *Notification) bool {
+	return notification.Email != ""
+}
@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"context"
+	"testing"
+
+	confv1 "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/pkg/mail"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+// MockMailer 是 mail.Mailer 的模拟实现
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Send(ctx context.Context, msg *mail.Message) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+// NotifyTestSuite 是 Notifier 的测试套件
+type NotifyTestSuite struct {
+	suite.Suite
+	logger *zap.Logger
+}
+
+func (suite *NotifyTestSuite) SetupTest() {
+	suite.logger = zap.NewNop()
+}
+
+func (suite *NotifyTestSuite) TestNewNotifier() {
+	notifier, err := NewNotifier(&confv1.Bootstrap{}, new(MockMailer), suite.logger)
+	assert.NoError(suite.T(), err)
+	assert.IsType(suite.T(), &LogNotifier{}, notifier)
+
+	notifier, err = NewNotifier(&confv1.Bootstrap{Notify: &confv1.Notify{Driver: DriverMail}}, new(MockMailer), suite.logger)
+	assert.NoError(suite.T(), err)
+	assert.IsType(suite.T(), &MailNotifier{}, notifier)
+
+	_, err = NewNotifier(&confv1.Bootstrap{Notify: &confv1.Notify{Driver: "pigeon"}}, new(MockMailer), suite.logger)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *NotifyTestSuite) TestMailNotifier() {
+	ctx := context.Background()
+	mailer := new(MockMailer)
+	mailer.On("Send", ctx, &mail.Message{To: "alice@example.com", Subject: "New sign-in", Body: "body"}).Return(nil)
+	notifier := NewMailNotifier(mailer)
+
+	err := notifier.Notify(ctx, &Notification{Kind: KindNewSignIn, Email: "alice@example.com", Subject: "New sign-in", Body: "body"})
+	assert.NoError(suite.T(), err)
+	mailer.AssertExpectations(suite.T())
+
+	// 没有邮箱的用户无法发送
+	err = notifier.Notify(ctx, &Notification{Kind: KindNewSignIn, Subject: "New sign-in"})
+	assert.ErrorIs(suite.T(), err, ErrNoRecipient)
+	assert.False(suite.T(), notifier.Reachable(&Notification{Kind: KindStepUp}))
+	assert.True(suite.T(), notifier.Reachable(&Notification{Kind: KindStepUp, Email: "alice@example.com"}))
+	assert.True(suite.T(), NewLogNotifier(suite.logger).Reachable(&Notification{Kind: KindStepUp}))
+}
+
+func TestNotifyTestSuite(t *testing.T) {
+	suite.Run(t, new(NotifyTestSuite))
+}
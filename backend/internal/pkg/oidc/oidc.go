@@ -0,0 +1,358 @@
+// Package oidc 实现 OpenID Connect 授权码流程的客户端（依赖方）部分
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	confv1 "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/pkg/jwk"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxResponseBytes IdP 响应的最大长度
+	maxResponseBytes = 1 << 20
+	// jwksRefreshInterval 遇到未知 kid 时重新获取 JWKS 的最小间隔，IdP 轮换密钥后能及时生效
+	jwksRefreshInterval = time.Minute
+)
+
+var (
+	// ErrUnknownProvider 未配置的提供方
+	ErrUnknownProvider = errors.New("unknown identity provider")
+	// ErrInvalidIDToken ID Token 校验失败
+	ErrInvalidIDToken = errors.New("invalid id token")
+)
+
+// idTokenAlgorithms 只接受非对称签名
+var idTokenAlgorithms = []string{"RS256", "ES256", "EdDSA"}
+
+// Module 提供 Fx 模块
+var Module = fx.Module("oidc",
+	fx.Provide(NewProviders),
+)
+
+// Claims ID Token 中用于关联和创建用户的声明
+type Claims struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+	Name              string
+}
+
+// metadata 发现文档中使用的字段
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// Provider 一个上游 OIDC 提供方，发现文档和签名公钥在首次使用时获取并缓存
+type Provider struct {
+	cfg    *confv1.Federation_Provider
+	scopes []string
+	client *http.Client
+
+	mu            sync.Mutex
+	meta          *metadata
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+func NewProvider(cfg *confv1.Federation_Provider, client *http.Client) (*Provider, error) {
+	if cfg.Id == "" || cfg.ClientId == "" {
+		return nil, errors.New("federation provider requires id and client_id")
+	}
+	for _, raw := range []string{cfg.Issuer, cfg.RedirectUrl} {
+		u, err := url.Parse(raw)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("federation provider %q has invalid url %q", cfg.Id, raw)
+		}
+	}
+
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+	return &Provider{cfg: cfg, scopes: scopes, client: client}, nil
+}
+
+func (p *Provider) ID() string {
+	return p.cfg.Id
+}
+
+func (p *Provider) DisplayName() string {
+	if p.cfg.DisplayName == "" {
+		return p.cfg.Id
+	}
+	return p.cfg.DisplayName
+}
+
+// LinkByEmail 首次登录时是否按已验证邮箱关联已有用户
+func (p *Provider) LinkByEmail() bool {
+	return p.cfg.LinkByEmail
+}
+
+// AutoProvision 首次登录时是否自动创建用户
+func (p *Provider) AutoProvision() bool {
+	return p.cfg.AutoProvision
+}
+
+// AuthCodeURL 返回授权端点地址，使用 PKCE（S256）
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(meta.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %v", err)
+	}
+	query := u.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.cfg.ClientId)
+	query.Set("redirect_uri", p.cfg.RedirectUrl)
+	query.Set("scope", strings.Join(p.scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// Exchange 用授权码换取 ID Token，校验签名、iss、aud、有效期和 nonce 后返回声明
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Claims, error) {
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectUrl},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	// client_secret_basic，凭据需要先做表单编码（RFC 6749 2.3.1）
+	req.SetBasicAuth(url.QueryEscape(p.cfg.ClientId), url.QueryEscape(p.cfg.ClientSecret))
+
+	var token struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	if err := p.do(req, &token); err != nil {
+		if token.Error != "" {
+			return nil, fmt.Errorf("token endpoint returned %s", token.Error)
+		}
+		return nil, err
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%w: token response has no id_token", ErrInvalidIDToken)
+	}
+	return p.verify(ctx, meta, token.IDToken, nonce)
+}
+
+func (p *Provider) verify(ctx context.Context, meta *metadata, idToken, nonce string) (*Claims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, meta, kid)
+	},
+		jwt.WithValidMethods(idTokenAlgorithms),
+		jwt.WithIssuer(meta.Issuer),
+		jwt.WithAudience(p.cfg.ClientId),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(30*time.Second),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	// 多个 aud 时 azp 必须是本客户端（OIDC Core 3.1.3.7）
+	if aud, _ := claims.GetAudience(); len(aud) > 1 {
+		if azp, _ := claims["azp"].(string); azp != p.cfg.ClientId {
+			return nil, fmt.Errorf("%w: azp mismatch", ErrInvalidIDToken)
+		}
+	}
+	if got, _ := claims["nonce"].(string); nonce == "" || subtle.ConstantTimeCompare([]byte(got), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+
+	result := &Claims{}
+	result.Subject, _ = claims["sub"].(string)
+	result.Email, _ = claims["email"].(string)
+	result.PreferredUsername, _ = claims["preferred_username"].(string)
+	result.Name, _ = claims["name"].(string)
+	// 部分 IdP 以字符串返回 email_verified
+	switch v := claims["email_verified"].(type) {
+	case bool:
+		result.EmailVerified = v
+	case string:
+		result.EmailVerified = v == "true"
+	}
+	if result.Subject == "" {
+		return nil, fmt.Errorf("%w: missing sub", ErrInvalidIDToken)
+	}
+	return result, nil
+}
+
+// metadata 获取发现文档，issuer 必须与配置一致
+func (p *Provider) metadata(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.meta != nil {
+		return p.meta, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.cfg.Issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var meta metadata
+	if err := p.do(req, &meta); err != nil {
+		return nil, fmt.Errorf("fetch openid configuration failed: %v", err)
+	}
+	if meta.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("issuer mismatch: configured %q, discovered %q", p.cfg.Issuer, meta.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JwksURI == "" {
+		return nil, errors.New("openid configuration is missing endpoints")
+	}
+	p.meta = &meta
+	return p.meta, nil
+}
+
+// key 按 kid 查找签名公钥，找不到时重新获取 JWKS
+func (p *Provider) key(ctx context.Context, meta *metadata, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookup(kid); ok {
+		return key, nil
+	}
+	if time.Since(p.keysFetchedAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, meta.JwksURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []map[string]any `json:"keys"`
+	}
+	if err := p.do(req, &set); err != nil {
+		return nil, fmt.Errorf("fetch jwks failed: %v", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if use, _ := k["use"].(string); use != "" && use != "sig" {
+			continue
+		}
+		key, _, err := jwk.Parse(k)
+		if err != nil {
+			// 忽略不支持的密钥类型
+			continue
+		}
+		id, _ := k["kid"].(string)
+		keys[id] = key
+	}
+	p.keys, p.keysFetchedAt = keys, time.Now()
+
+	if key, ok := p.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookup 没有 kid 时只有一个公钥才能确定使用哪个
+func (p *Provider) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+// do 发送请求并解码 JSON 响应，非 2xx 时仍然解码以便读取 error
+func (p *Provider) do(req *http.Request, out any) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	decodeErr := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(out)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s %s returned status %d", req.Method, req.URL.Redacted(), resp.StatusCode)
+	}
+	return decodeErr
+}
+
+// Providers 已配置的提供方
+type Providers struct {
+	list []*Provider
+	byID map[string]*Provider
+}
+
+// NewProviders 根据配置创建提供方，未配置时为空
+func NewProviders(conf *confv1.Bootstrap, logger *zap.Logger) (*Providers, error) {
+	ps := &Providers{byID: make(map[string]*Provider)}
+	if conf.Federation == nil {
+		return ps, nil
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	for _, cfg := range conf.Federation.Providers {
+		p, err := NewProvider(cfg, client)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := ps.byID[p.ID()]; ok {
+			return nil, fmt.Errorf("duplicate federation provider %q", p.ID())
+		}
+		ps.list = append(ps.list, p)
+		ps.byID[p.ID()] = p
+		logger.Info("Federation provider configured", zap.String("provider", p.ID()), zap.String("issuer", cfg.Issuer))
+	}
+	return ps, nil
+}
+
+func (ps *Providers) Get(id string) (*Provider, error) {
+	p, ok := ps.byID[id]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return p, nil
+}
+
+func (ps *Providers) List() []*Provider {
+	return ps.list
+}
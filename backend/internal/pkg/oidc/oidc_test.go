@@ -0,0 +1,130 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"testing"
+	"time"
+
+	confv1 "connect-go-example/internal/conf/v1"
+	"connect-go-example/internal/pkg/oidc/oidctest"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func stubProvider(t *testing.T, idp *oidctest.Server) *Provider {
+	p, err := NewProvider(&confv1.Federation_Provider{
+		Id:           "stub",
+		Issuer:       idp.Issuer(),
+		ClientId:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		RedirectUrl:  "https://app.example.com/callback",
+	}, http.DefaultClient)
+	assert.NoError(t, err)
+	return p
+}
+
+// login 在模拟 IdP 完成授权码流程
+func login(t *testing.T, idp *oidctest.Server, p *Provider, verifier, nonce string) (*Claims, error) {
+	challenge := sha256.Sum256([]byte(verifier))
+	authURL, err := p.AuthCodeURL(context.Background(), "state", nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	assert.NoError(t, err)
+	callback, err := idp.Login(authURL)
+	assert.NoError(t, err)
+	assert.Equal(t, "state", callback.Query().Get("state"))
+	return p.Exchange(context.Background(), callback.Query().Get("code"), verifier, nonce)
+}
+
+func TestExchange(t *testing.T) {
+	idp := oidctest.NewServer("app", "s3cret:with/special")
+	defer idp.Close()
+	idp.SetUser(oidctest.User{Subject: "u-1", Email: "alice@example.com", EmailVerified: true, PreferredUsername: "alice"})
+	p := stubProvider(t, idp)
+
+	claims, err := login(t, idp, p, "verifier", "nonce")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Claims{Subject: "u-1", Email: "alice@example.com", EmailVerified: true, PreferredUsername: "alice"}, claims)
+}
+
+func TestExchange_Rejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(jwt.MapClaims)
+	}{
+		{"expired", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{"wrong issuer", func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+		{"wrong audience", func(c jwt.MapClaims) { c["aud"] = "other" }},
+		{"missing azp", func(c jwt.MapClaims) { c["aud"] = []string{"app", "other"} }},
+		{"nonce mismatch", func(c jwt.MapClaims) { c["nonce"] = "other" }},
+		{"missing subject", func(c jwt.MapClaims) { delete(c, "sub") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := oidctest.NewServer("app", "secret")
+			defer idp.Close()
+			idp.SetUser(oidctest.User{Subject: "u-1"})
+			idp.Mutate = tt.mutate
+
+			_, err := login(t, idp, stubProvider(t, idp), "verifier", "nonce")
+
+			assert.ErrorIs(t, err, ErrInvalidIDToken)
+		})
+	}
+}
+
+func TestExchange_WrongSecret(t *testing.T) {
+	idp := oidctest.NewServer("app", "secret")
+	defer idp.Close()
+	idp.SetUser(oidctest.User{Subject: "u-1"})
+	p := stubProvider(t, idp)
+	idp.ClientSecret = "rotated"
+
+	_, err := login(t, idp, p, "verifier", "nonce")
+
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidIDToken)
+}
+
+func TestAuthCodeURL_IssuerMismatch(t *testing.T) {
+	idp := oidctest.NewServer("app", "secret")
+	defer idp.Close()
+	p, err := NewProvider(&confv1.Federation_Provider{
+		Id:          "stub",
+		Issuer:      idp.Issuer() + "/tenant",
+		ClientId:    "app",
+		RedirectUrl: "https://app.example.com/callback",
+	}, http.DefaultClient)
+	assert.NoError(t, err)
+
+	_, err = p.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+
+	assert.Error(t, err)
+}
+
+func TestNewProviders(t *testing.T) {
+	logger := zap.NewNop()
+	provider := &confv1.Federation_Provider{Id: "corp", Issuer: "https://idp.example.com", ClientId: "app", RedirectUrl: "https://app.example.com/callback"}
+
+	ps, err := NewProviders(&confv1.Bootstrap{}, logger)
+	assert.NoError(t, err)
+	assert.Empty(t, ps.List())
+
+	ps, err = NewProviders(&confv1.Bootstrap{Federation: &confv1.Federation{Providers: []*confv1.Federation_Provider{provider}}}, logger)
+	assert.NoError(t, err)
+	p, err := ps.Get("corp")
+	assert.NoError(t, err)
+	assert.Equal(t, "corp", p.DisplayName())
+	_, err = ps.Get("other")
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+
+	_, err = NewProviders(&confv1.Bootstrap{Federation: &confv1.Federation{Providers: []*confv1.Federation_Provider{provider, provider}}}, logger)
+	assert.Error(t, err)
+
+	_, err = NewProviders(&confv1.Bootstrap{Federation: &confv1.Federation{Providers: []*confv1.Federation_Provider{{Id: "bad", ClientId: "app", Issuer: "not a url"}}}}, logger)
+	assert.Error(t, err)
+}
@@ -0,0 +1,206 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// 投递请求携带的请求头
+const (
+	HeaderID        = "Webhook-Id"
+	HeaderEvent     = "Webhook-Event"
+	HeaderTimestamp = "Webhook-Timestamp"
+	HeaderSignature = "Webhook-Signature"
+)
+
+// signaturePrefix 签名的算法前缀
+const signaturePrefix = "sha256="
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrStaleTimestamp   = errors.New("webhook timestamp outside tolerance")
+	// ErrForbiddenAddress 目标不是公网地址，也不在允许的网段中
+	ErrForbiddenAddress = errors.New("webhook destination is not a public address")
+)
+
+// blockedPrefixes 除私有、回环、链路本地（含 169.254.169.254 元数据服务）等地址外，
+// 还拒绝共享地址、保留地址，以及可能转换到内网 IPv4 地址的 NAT64 和 6to4 前缀
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("2002::/16"),
+}
+
+// AddressPolicy 限制投递的目标地址，默认只允许公网地址，防止通过订阅地址访问内网服务（SSRF）。
+// 方法可以在 nil 上调用，此时不额外允许任何网段
+type AddressPolicy struct {
+	allowed []netip.Prefix
+}
+
+// NewAddressPolicy allowed 为额外允许的地址或网段（CIDR），仅用于开发环境
+func NewAddressPolicy(allowed []string) (*AddressPolicy, error) {
+	p := &AddressPolicy{}
+	for _, s := range allowed {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(s)
+			if addrErr != nil {
+				return nil, fmt.Errorf("invalid webhook allowed network %q: %w", s, err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		p.allowed = append(p.allowed, prefix.Masked())
+	}
+	return p, nil
+}
+
+// Check 地址在允许的网段中，或者是公网单播地址时返回 nil
+func (p *AddressPolicy) Check(addr netip.Addr) error {
+	addr = addr.Unmap()
+	if p != nil {
+		for _, prefix := range p.allowed {
+			if prefix.Contains(addr) {
+				return nil
+			}
+		}
+	}
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+		return ErrForbiddenAddress
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return ErrForbiddenAddress
+		}
+	}
+	return nil
+}
+
+// CheckHost 主机是 IP 地址时直接检查；域名在每次连接时检查解析结果，见 NewSender
+func (p *AddressPolicy) CheckHost(host string) error {
+	addr, err := netip.ParseAddr(strings.Trim(host, "[]"))
+	if err != nil {
+		return nil
+	}
+	return p.Check(addr)
+}
+
+// control 在建立连接前检查解析后的地址，域名在订阅后改为指向内网（DNS rebinding）也会被拒绝
+func (p *AddressPolicy) control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	return p.Check(addrPort.Addr())
+}
+
+// Sign 计算 HMAC-SHA256(secret, "<timestamp>.<body>")，时间戳参与签名，接收方可以拒绝重放的旧请求
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 校验请求头中的签名，时间戳与 now 相差超过 tolerance 时拒绝，供接收方使用
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration, now time.Time) error {
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if diff := now.Sub(time.Unix(timestamp, 0)); diff > tolerance || diff < -tolerance {
+		return ErrStaleTimestamp
+	}
+	if !hmac.Equal([]byte(header.Get(HeaderSignature)), []byte(Sign(secret, timestamp, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Request 一次投递
+type Request struct {
+	URL    string
+	Secret string
+	ID     string // 投递ID，重试时不变，接收方据此去重
+	Event  string
+	Body   []byte
+}
+
+// Sender 发送签名的 Webhook 请求
+type Sender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewSender 连接只允许 policy 接受的地址；不使用环境变量中的代理，否则检查的是代理的地址
+func NewSender(timeout time.Duration, policy *AddressPolicy) *Sender {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: policy.control,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &Sender{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			// 不跟随重定向，订阅地址变更时应由管理员更新
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		now: time.Now,
+	}
+}
+
+// Send 响应 2xx 时成功，返回响应状态码，没有收到响应时为 0。
+// 错误信息会保存在投递记录中，不包含响应内容，避免把目标服务的响应暴露给租户管理员
+func (s *Sender) Send(ctx context.Context, req *Request) (int, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := s.now().Unix()
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "connect-go-example-webhook/1")
+	httpReq.Header.Set(HeaderID, req.ID)
+	httpReq.Header.Set(HeaderEvent, req.Event)
+	httpReq.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, timestamp, req.Body))
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		if errors.Is(err, ErrForbiddenAddress) {
+			// 不返回解析出的内网地址
+			return 0, ErrForbiddenAddress
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// 丢弃响应内容以便复用连接
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,132 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSend(t *testing.T) {
+	const secret = "subscription-secret"
+	body := []byte(`{"id":"1","type":"UserRegistered"}`)
+	var verifyErr error
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ := io.ReadAll(r.Body)
+		header = r.Header
+		verifyErr = Verify(secret, r.Header, received, 5*time.Minute, time.Now())
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	status, err := NewSender(time.Second, testPolicy(t)).Send(context.Background(), &Request{
+		URL: srv.URL, Secret: secret, ID: "7", Event: "UserRegistered", Body: body,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, status)
+	assert.NoError(t, verifyErr)
+	assert.Equal(t, "7", header.Get(HeaderID))
+	assert.Equal(t, "UserRegistered", header.Get(HeaderEvent))
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+}
+
+func TestSend_Failures(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/error", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ok", http.StatusFound)
+	})
+	reachedOK := false
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, _ *http.Request) {
+		reachedOK = true
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	sender := NewSender(time.Second, testPolicy(t))
+
+	status, err := sender.Send(context.Background(), &Request{URL: srv.URL + "/error", Secret: "s", Body: []byte(`{}`)})
+	assert.Equal(t, http.StatusBadRequest, status)
+	// 响应内容不出现在错误信息中
+	assert.EqualError(t, err, "unexpected status 400")
+
+	// 不跟随重定向
+	status, err = sender.Send(context.Background(), &Request{URL: srv.URL + "/redirect", Secret: "s", Body: []byte(`{}`)})
+	assert.Equal(t, http.StatusFound, status)
+	assert.Error(t, err)
+	assert.False(t, reachedOK)
+}
+
+func TestSend_ForbiddenAddress(t *testing.T) {
+	reached := false
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		reached = true
+	}))
+	defer srv.Close()
+
+	// 默认不允许回环地址，域名解析到内网地址同样被拒绝
+	for _, url := range []string{srv.URL, strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)} {
+		status, err := NewSender(time.Second, nil).Send(context.Background(), &Request{URL: url, Secret: "s", Body: []byte(`{}`)})
+		assert.Equal(t, 0, status)
+		assert.ErrorIs(t, err, ErrForbiddenAddress)
+		assert.NotContains(t, err.Error(), "127.0.0.1")
+	}
+	assert.False(t, reached)
+}
+
+func TestAddressPolicy(t *testing.T) {
+	policy, err := NewAddressPolicy(nil)
+	require.NoError(t, err)
+	for _, host := range []string{
+		"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0",
+		"100.64.0.1", "::1", "fd00:ec2::254", "fe80::1", "::ffff:10.0.0.1", "64:ff9b::a00:1", "[::1]",
+	} {
+		assert.ErrorIs(t, policy.CheckHost(host), ErrForbiddenAddress, host)
+	}
+	for _, host := range []string{"93.184.216.34", "2606:2800:220:1::", "hooks.example.com"} {
+		assert.NoError(t, policy.CheckHost(host), host)
+	}
+
+	// 开发环境允许指定的网段
+	policy, err = NewAddressPolicy([]string{"127.0.0.1", "10.0.0.0/8"})
+	require.NoError(t, err)
+	assert.NoError(t, policy.CheckHost("127.0.0.1"))
+	assert.NoError(t, policy.CheckHost("10.1.2.3"))
+	assert.ErrorIs(t, policy.CheckHost("192.168.1.1"), ErrForbiddenAddress)
+
+	_, err = NewAddressPolicy([]string{"not-a-network"})
+	assert.Error(t, err)
+}
+
+// testPolicy 允许 httptest 服务器监听的回环地址
+func testPolicy(t *testing.T) *AddressPolicy {
+	policy, err := NewAddressPolicy([]string{"127.0.0.0/8", "::1"})
+	require.NoError(t, err)
+	return policy
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{}`)
+	header := http.Header{}
+	header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	header.Set(HeaderSignature, Sign("secret", now.Unix(), body))
+
+	assert.NoError(t, Verify("secret", header, body, time.Minute, now))
+	assert.ErrorIs(t, Verify("other", header, body, time.Minute, now), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", header, []byte(`{"a":1}`), time.Minute, now), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", header, body, time.Minute, now.Add(2*time.Minute)), ErrStaleTimestamp)
+
+	header.Del(HeaderTimestamp)
+	assert.ErrorIs(t, Verify("secret", header, body, time.Minute, now), ErrInvalidSignature)
+}
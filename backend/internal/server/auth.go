@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"connect-go-example/api/admin/v1/adminv1connect"
+	"connect-go-example/api/greet/v1/greetv1connect"
+	"connect-go-example/internal/biz/model"
+
+	"connectrpc.com/connect"
+	"go.uber.org/zap"
+)
+
+// authenticatedProcedures 需要携带 Bearer 令牌才能调用的接口
+var authenticatedProcedures = []string{
+	greetv1connect.GreetServiceGetCrossDeviceLoginProcedure,
+	greetv1connect.GreetServiceApproveCrossDeviceLoginProcedure,
+	greetv1connect.GreetServiceLogoutProcedure,
+	greetv1connect.GreetServiceRefreshTokenProcedure,
+	greetv1connect.GreetServiceLinkFederatedIdentityProcedure,
+	adminv1connect.AdminServiceCreateInviteProcedure,
+	adminv1connect.AdminServiceListInvitesProcedure,
+	adminv1connect.AdminServiceCreateWebhookSubscriptionProcedure,
+	adminv1connect.AdminServiceListWebhookSubscriptionsProcedure,
+	adminv1connect.AdminServiceDeleteWebhookSubscriptionProcedure,
+	adminv1connect.AdminServiceListWebhookDeliveriesProcedure,
+	adminv1connect.AdminServiceRedeliverWebhookProcedure,
+	adminv1connect.AdminServiceSetUserCredentialBackendProcedure,
+}
+
+// optionalAuthProcedures 携带令牌时校验并写入调用方，未携带时按匿名调用处理
+var optionalAuthProcedures = []string{
+	greetv1connect.GreetServiceDenyAuthRequestProcedure,
+}
+
+// AuthInterceptor 校验 Authorization 头中的访问令牌和 DPoP 证明，并把调用方写入 ctx
+type AuthInterceptor struct {
+	verifier   model.TokenVerifier
+	proofs     model.DPoPVerifier
+	l          *zap.Logger
+	procedures map[string]struct{}
+	optional   map[string]struct{}
+}
+
+var _ connect.Interceptor = (*AuthInterceptor)(nil)
+
+func NewAuthInterceptor(verifier model.TokenVerifier, proofs model.DPoPVerifier, logger *zap.Logger) *AuthInterceptor {
+	procedures := make(map[string]struct{}, len(authenticatedProcedures))
+	for _, procedure := range authenticatedProcedures {
+		procedures[procedure] = struct{}{}
+	}
+	optional := make(map[string]struct{}, len(optionalAuthProcedures))
+	for _, procedure := range optionalAuthProcedures {
+		optional[procedure] = struct{}{}
+	}
+
+	return &AuthInterceptor{
+		verifier:   verifier,
+		proofs:     proofs,
+		l:          logger,
+		procedures: procedures,
+		optional:   optional,
+	}
+}
+
+func (i *AuthInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+		ctx, err := i.authenticate(ctx, req.Spec().Procedure, req.HTTPMethod(), req.Header())
+		if err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+func (i *AuthInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *AuthInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		ctx, err := i.authenticate(ctx, conn.Spec().Procedure, http.MethodPost, conn.RequestHeader())
+		if err != nil {
+			return err
+		}
+		return next(ctx, conn)
+	}
+}
+
+func (i *AuthInterceptor) authenticate(ctx context.Context, procedure, method string, header http.Header) (context.Context, error) {
+	proofs := header.Values("DPoP")
+	if len(proofs) > 1 {
+		return nil, dpopError(errors.New("multiple dpop proofs"))
+	}
+	proof := &model.DPoPProof{Method: method, Path: procedure}
+	if len(proofs) == 1 {
+		proof.Proof = proofs[0]
+	}
+
+	_, required := i.procedures[procedure]
+	if _, ok := i.optional[procedure]; ok && header.Get("Authorization") != "" {
+		required = true
+	}
+	if !required {
+		// 获取令牌的接口携带证明时，签发的令牌绑定到证明密钥
+		if proof.Proof == "" {
+			return ctx, nil
+		}
+		return i.verifyProof(ctx, proof)
+	}
+
+	scheme, token, _ := strings.Cut(header.Get("Authorization"), " ")
+	if (scheme != model.TokenTypeBearer && scheme != model.TokenTypeDPoP) || token == "" {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("missing bearer token"))
+	}
+
+	principal, err := i.verifier.VerifyToken(ctx, token)
+	if err != nil {
+		i.l.Debug("verify token failed", zap.String("procedure", procedure), zap.Error(err))
+		return nil, connect.NewError(connect.CodeUnauthenticated, err)
+	}
+	ctx, err = checkPrincipalTenant(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+
+	// 绑定密钥的令牌必须使用 DPoP 方案并携带同一密钥的证明，未绑定的令牌只能使用 Bearer 方案
+	if scheme != model.TokenType(principal.ProofKey) {
+		return nil, dpopError(errors.New("authorization scheme does not match token binding"))
+	}
+	if proof.Proof != "" {
+		proof.AccessToken = token
+		if ctx, err = i.verifyProof(ctx, proof); err != nil {
+			return nil, err
+		}
+	}
+	if principal.ProofKey != "" && model.ProofKeyFromContext(ctx) != principal.ProofKey {
+		return nil, dpopError(errors.New("dpop proof does not match token binding"))
+	}
+	return model.NewPrincipalContext(ctx, principal), nil
+}
+
+// verifyProof 校验 DPoP 证明，并把密钥指纹写入 ctx
+func (i *AuthInterceptor) verifyProof(ctx context.Context, proof *model.DPoPProof) (context.Context, error) {
+	jkt, err := i.proofs.VerifyProof(ctx, proof)
+	if err != nil {
+		i.l.Debug("verify dpop proof failed", zap.String("procedure", proof.Path), zap.Error(err))
+		if errors.Is(err, model.ErrInvalidDPoPProof) {
+			return nil, dpopError(err)
+		}
+		return nil, connect.NewError(connect.CodeUnavailable, err)
+	}
+	return model.NewProofKeyContext(ctx, jkt), nil
+}
+
+// dpopError 返回 RFC 9449 要求的 WWW-Authenticate 挑战
+func dpopError(err error) error {
+	connectErr := connect.NewError(connect.CodeUnauthenticated, err)
+	connectErr.Meta().Set("WWW-Authenticate", `DPoP error="invalid_dpop_proof"`)
+	return connectErr
+}
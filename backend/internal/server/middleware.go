@@ -216,6 +216,19 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return rw.ResponseWriter.Write(b)
 }
 
+// Flush 流式响应需要逐条刷新
+func (rw *responseWriter) Flush() {
+	rw.written = true
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap 供 http.ResponseController 访问底层 ResponseWriter
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // MiddlewareModule 提供 Fx 模块
 var MiddlewareModule = fx.Module("server.middleware",
 	fx.Provide(
@@ -223,5 +236,7 @@ var MiddlewareModule = fx.Module("server.middleware",
 			return MonitoringMiddleware(logger)
 		},
 		ConnectMonitoringInterceptor,
+		NewTenantInterceptor,
+		NewAuthInterceptor,
 	),
 )
@@ -0,0 +1,211 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	oauthv1 "connect-go-example/api/oauth/v1"
+	"connect-go-example/api/oauth/v1/oauthv1connect"
+	"connect-go-example/internal/biz/model"
+
+	"connectrpc.com/connect"
+	"go.uber.org/zap"
+)
+
+const (
+	// introspectionPath RFC 7662 令牌内省端点
+	introspectionPath = "/oauth/introspect"
+	// tokenPath 令牌端点，目前只支持令牌交换
+	tokenPath = "/oauth/token"
+
+	grantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+)
+
+// introspectionResponse RFC 7662 的响应格式，令牌无效时只有 active
+type introspectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Iss       string `json:"iss,omitempty"`
+	Jti       string `json:"jti,omitempty"`
+	Sid       string `json:"sid,omitempty"`
+	Tid       int64  `json:"tid,omitempty"`
+	Aud       string `json:"aud,omitempty"`
+	Act       *actor `json:"act,omitempty"`
+	Cnf       *cnf   `json:"cnf,omitempty"`
+}
+
+// cnf RFC 9449 的密钥绑定声明
+type cnf struct {
+	Jkt string `json:"jkt"`
+}
+
+// actor RFC 8693 的 act 声明
+type actor struct {
+	Sub string `json:"sub"`
+	Act *actor `json:"act,omitempty"`
+}
+
+// tokenResponse RFC 8693 令牌交换的响应格式
+type tokenResponse struct {
+	AccessToken     string `json:"access_token"`
+	IssuedTokenType string `json:"issued_token_type"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int64  `json:"expires_in"`
+	Scope           string `json:"scope,omitempty"`
+}
+
+// introspectionHandler 把表单请求转换为 OAuthService.Introspect 调用，两者共用客户端认证和缓存
+func introspectionHandler(svc oauthv1connect.OAuthServiceHandler, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeOAuthError(w, http.StatusMethodNotAllowed, "invalid_request")
+			return
+		}
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("token") == "" {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request")
+			return
+		}
+
+		req := connect.NewRequest(&oauthv1.IntrospectRequest{
+			Token:         r.PostForm.Get("token"),
+			TokenTypeHint: r.PostForm.Get("token_type_hint"),
+		})
+		req.Header().Set("Authorization", r.Header.Get("Authorization"))
+
+		resp, err := svc.Introspect(r.Context(), req)
+		if err != nil {
+			writeServiceError(w, logger, "introspect token failed", err)
+			return
+		}
+
+		msg := resp.Msg
+		writeOAuthJSON(w, http.StatusOK, introspectionResponse{
+			Active:    msg.Active,
+			Scope:     msg.Scope,
+			Username:  msg.Username,
+			TokenType: msg.TokenType,
+			Exp:       msg.Exp,
+			Iat:       msg.Iat,
+			Sub:       msg.Sub,
+			Iss:       msg.Iss,
+			Jti:       msg.Jti,
+			Sid:       msg.Sid,
+			Tid:       msg.Tid,
+			Aud:       msg.Aud,
+			Act:       toActor(msg.Act),
+			Cnf:       toCnf(msg.Cnf),
+		})
+	})
+}
+
+// tokenHandler RFC 8693 令牌交换端点，转发给 OAuthService.ExchangeToken，携带的 DPoP 证明在这里校验
+func tokenHandler(svc oauthv1connect.OAuthServiceHandler, proofs model.DPoPVerifier, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeOAuthError(w, http.StatusMethodNotAllowed, "invalid_request")
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request")
+			return
+		}
+		if r.PostForm.Get("grant_type") != grantTypeTokenExchange {
+			writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type")
+			return
+		}
+
+		ctx := r.Context()
+		dpop := r.Header.Values("DPoP")
+		if len(dpop) > 1 {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_dpop_proof")
+			return
+		}
+		if len(dpop) == 1 {
+			jkt, err := proofs.VerifyProof(ctx, &model.DPoPProof{Proof: dpop[0], Method: r.Method, Path: r.URL.Path})
+			if err != nil {
+				writeServiceError(w, logger, "verify dpop proof failed", err)
+				return
+			}
+			ctx = model.NewProofKeyContext(ctx, jkt)
+		}
+
+		req := connect.NewRequest(&oauthv1.ExchangeTokenRequest{
+			SubjectToken:       r.PostForm.Get("subject_token"),
+			SubjectTokenType:   r.PostForm.Get("subject_token_type"),
+			Audience:           r.PostForm.Get("audience"),
+			Scope:              r.PostForm.Get("scope"),
+			RequestedTokenType: r.PostForm.Get("requested_token_type"),
+		})
+		req.Header().Set("Authorization", r.Header.Get("Authorization"))
+
+		resp, err := svc.ExchangeToken(ctx, req)
+		if err != nil {
+			writeServiceError(w, logger, "exchange token failed", err)
+			return
+		}
+
+		msg := resp.Msg
+		writeOAuthJSON(w, http.StatusOK, tokenResponse{
+			AccessToken:     msg.AccessToken,
+			IssuedTokenType: msg.IssuedTokenType,
+			TokenType:       msg.TokenType,
+			ExpiresIn:       msg.ExpiresIn,
+			Scope:           msg.Scope,
+		})
+	})
+}
+
+func toActor(act *oauthv1.Actor) *actor {
+	if act == nil {
+		return nil
+	}
+	return &actor{Sub: act.Sub, Act: toActor(act.Act)}
+}
+
+func toCnf(c *oauthv1.Confirmation) *cnf {
+	if c == nil {
+		return nil
+	}
+	return &cnf{Jkt: c.Jkt}
+}
+
+// writeServiceError 把服务返回的错误转换为 OAuth 错误码
+func writeServiceError(w http.ResponseWriter, logger *zap.Logger, msg string, err error) {
+	switch {
+	case connect.CodeOf(err) == connect.CodeUnauthenticated:
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_client")
+	case errors.Is(err, model.ErrInvalidTarget):
+		writeOAuthError(w, http.StatusBadRequest, "invalid_target")
+	case errors.Is(err, model.ErrInvalidScope):
+		writeOAuthError(w, http.StatusBadRequest, "invalid_scope")
+	case errors.Is(err, model.ErrInvalidDPoPProof):
+		writeOAuthError(w, http.StatusBadRequest, "invalid_dpop_proof")
+	case errors.Is(err, model.ErrInvalidGrant):
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant")
+	case connect.CodeOf(err) == connect.CodeInvalidArgument:
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request")
+	default:
+		logger.Warn(msg, zap.Error(err))
+		writeOAuthError(w, http.StatusServiceUnavailable, "temporarily_unavailable")
+	}
+}
+
+func writeOAuthError(w http.ResponseWriter, status int, code string) {
+	writeOAuthJSON(w, status, map[string]string{"error": code})
+}
+
+func writeOAuthJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
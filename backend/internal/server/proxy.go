@@ -0,0 +1,104 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// forwardedHeaders 反向代理写入的客户端地址和 Host，只在直接连接来自可信代理时使用，之后删除，后续处理不能读取
+var forwardedHeaders = []string{"X-Forwarded-For", "X-Real-IP", "X-Forwarded-Host"}
+
+// trustedProxies 可信的反向代理网段
+type trustedProxies []netip.Prefix
+
+// newTrustedProxies 解析 server.http.trusted_proxies，单个地址按 /32 或 /128 处理
+func newTrustedProxies(values []string) (trustedProxies, error) {
+	proxies := make(trustedProxies, 0, len(values))
+	for _, v := range values {
+		if !strings.Contains(v, "/") {
+			addr, err := netip.ParseAddr(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+			}
+			proxies = append(proxies, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+		}
+		proxies = append(proxies, prefix.Masked())
+	}
+	return proxies, nil
+}
+
+func (p trustedProxies) contains(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range p {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP 直接连接来自可信代理时，从 X-Forwarded-For 右侧开始跳过可信代理，取第一个不可信的地址；
+// 最左侧的地址由客户端填写，不能直接使用
+func (p trustedProxies) clientIP(remote netip.Addr, header http.Header) netip.Addr {
+	if !p.contains(remote) {
+		return remote
+	}
+	if forwarded := header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				break
+			}
+			if !p.contains(addr) {
+				return addr.Unmap()
+			}
+			remote = addr.Unmap()
+		}
+		return remote
+	}
+	if addr, err := netip.ParseAddr(strings.TrimSpace(header.Get("X-Real-IP"))); err == nil {
+		return addr.Unmap()
+	}
+	return remote
+}
+
+// withProxyHeaders 把 RemoteAddr 和 Host 替换为可信代理转发的值，并删除代理请求头，
+// Connect 的 Peer().Addr 即为客户端地址，租户识别使用的 Host 不能由客户端直接指定
+func withProxyHeaders(next http.Handler, proxies trustedProxies) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		if remote, err := netip.ParseAddr(host); err == nil && proxies.contains(remote) {
+			if client := proxies.clientIP(remote, r.Header); client != remote.Unmap() {
+				r.RemoteAddr = net.JoinHostPort(client.String(), "0")
+			}
+			if forwardedHost := lastHeaderValue(r.Header.Values("X-Forwarded-Host")); forwardedHost != "" {
+				r.Host = forwardedHost
+			}
+		}
+		for _, name := range forwardedHeaders {
+			r.Header.Del(name)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// lastHeaderValue 多个代理追加时取最后一个，即直接相连的可信代理写入的值
+func lastHeaderValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	parts := strings.Split(values[len(values)-1], ",")
+	return strings.TrimSpace(parts[len(parts)-1])
+}
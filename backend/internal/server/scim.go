@@ -0,0 +1,531 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"connect-go-example/internal/biz/model"
+
+	"connectrpc.com/connect"
+	"go.uber.org/zap"
+)
+
+const (
+	// scimPath SCIM 2.0（RFC 7644）端点前缀
+	scimPath = "/scim/v2/"
+
+	scimContentType     = "application/scim+json"
+	scimMaxBodyBytes    = 1 << 20
+	scimUserSchemaURN   = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimGroupSchemaURN  = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimListSchemaURN   = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimErrorSchemaURN  = "urn:ietf:params:scim:api:messages:2.0:Error"
+	scimPatchSchemaURN  = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	scimConfigSchemaURN = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	scimTypeSchemaURN   = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	scimSchemaSchemaURN = "urn:ietf:params:scim:schemas:core:2.0:Schema"
+)
+
+type scimMeta struct {
+	ResourceType string `json:"resourceType"`
+	Created      string `json:"created,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Location     string `json:"location,omitempty"`
+}
+
+type scimName struct {
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type scimEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// scimUser 用户资源，active 缺省时为 true
+type scimUser struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	ExternalID  string      `json:"externalId,omitempty"`
+	UserName    string      `json:"userName"`
+	DisplayName string      `json:"displayName,omitempty"`
+	Name        *scimName   `json:"name,omitempty"`
+	Emails      []scimEmail `json:"emails,omitempty"`
+	Active      *bool       `json:"active,omitempty"`
+	Meta        *scimMeta   `json:"meta,omitempty"`
+}
+
+type scimMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+type scimGroup struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []scimMember `json:"members"`
+	Meta        *scimMeta    `json:"meta,omitempty"`
+}
+
+type scimListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int64    `json:"totalResults"`
+	StartIndex   int32    `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    any      `json:"Resources"`
+}
+
+type scimPatchRequest struct {
+	Schemas    []string `json:"schemas"`
+	Operations []struct {
+		Op    string `json:"op"`
+		Path  string `json:"path"`
+		Value any    `json:"value"`
+	} `json:"Operations"`
+}
+
+type scimErrorResponse struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// scimHandler 提供 Users、Groups 资源和发现端点，所有请求都需要下发客户端的 Bearer 令牌
+func scimHandler(uc model.ScimUseCase, logger *zap.Logger) http.Handler {
+	h := &scimHTTP{uc: uc, l: logger}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+scimPath+"Users", h.listUsers)
+	mux.HandleFunc("POST "+scimPath+"Users", h.createUser)
+	mux.HandleFunc("GET "+scimPath+"Users/{id}", h.getUser)
+	mux.HandleFunc("PUT "+scimPath+"Users/{id}", h.replaceUser)
+	mux.HandleFunc("PATCH "+scimPath+"Users/{id}", h.patchUser)
+	mux.HandleFunc("DELETE "+scimPath+"Users/{id}", h.deleteUser)
+	mux.HandleFunc("GET "+scimPath+"Groups", h.listGroups)
+	mux.HandleFunc("POST "+scimPath+"Groups", h.createGroup)
+	mux.HandleFunc("GET "+scimPath+"Groups/{id}", h.getGroup)
+	mux.HandleFunc("PUT "+scimPath+"Groups/{id}", h.replaceGroup)
+	mux.HandleFunc("PATCH "+scimPath+"Groups/{id}", h.patchGroup)
+	mux.HandleFunc("DELETE "+scimPath+"Groups/{id}", h.deleteGroup)
+	mux.HandleFunc("GET "+scimPath+"ServiceProviderConfig", h.serviceProviderConfig)
+	mux.HandleFunc("GET "+scimPath+"ResourceTypes", h.resourceTypes)
+	mux.HandleFunc("GET "+scimPath+"ResourceTypes/{id}", h.resourceTypes)
+	mux.HandleFunc("GET "+scimPath+"Schemas", h.schemas)
+	mux.HandleFunc("GET "+scimPath+"Schemas/{id}", h.schemas)
+	mux.HandleFunc(scimPath, func(w http.ResponseWriter, r *http.Request) {
+		writeScimError(w, http.StatusNotFound, "", "endpoint not found")
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		tenant, err := uc.Authenticate(r.Context(), token)
+		if !ok || err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="scim"`)
+			writeScimError(w, http.StatusUnauthorized, "", "invalid bearer token")
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, scimMaxBodyBytes)
+		mux.ServeHTTP(w, r.WithContext(model.NewTenantContext(r.Context(), tenant)))
+	})
+}
+
+type scimHTTP struct {
+	uc model.ScimUseCase
+	l  *zap.Logger
+}
+
+func (h *scimHTTP) listUsers(w http.ResponseWriter, r *http.Request) {
+	query, ok := h.query(w, r)
+	if !ok {
+		return
+	}
+	list, err := h.uc.ListUsers(r.Context(), query)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	resources := make([]*scimUser, 0, len(list.Users))
+	for _, user := range list.Users {
+		resources = append(resources, toScimUser(r, user))
+	}
+	writeScimJSON(w, http.StatusOK, &scimListResponse{
+		Schemas:      []string{scimListSchemaURN},
+		TotalResults: list.TotalResults,
+		StartIndex:   list.StartIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+func (h *scimHTTP) createUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.decodeUser(w, r)
+	if !ok {
+		return
+	}
+	created, err := h.uc.CreateUser(r.Context(), user)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	resource := toScimUser(r, created)
+	w.Header().Set("Location", resource.Meta.Location)
+	writeScimJSON(w, http.StatusCreated, resource)
+}
+
+func (h *scimHTTP) getUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := scimID(w, r)
+	if !ok {
+		return
+	}
+	user, err := h.uc.GetUser(r.Context(), id)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	writeScimJSON(w, http.StatusOK, toScimUser(r, user))
+}
+
+func (h *scimHTTP) replaceUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := scimID(w, r)
+	if !ok {
+		return
+	}
+	user, ok := h.decodeUser(w, r)
+	if !ok {
+		return
+	}
+	user.ID = id
+	updated, err := h.uc.ReplaceUser(r.Context(), user)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	writeScimJSON(w, http.StatusOK, toScimUser(r, updated))
+}
+
+func (h *scimHTTP) patchUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := scimID(w, r)
+	if !ok {
+		return
+	}
+	ops, ok := decodeScimPatch(w, r)
+	if !ok {
+		return
+	}
+	user, err := h.uc.PatchUser(r.Context(), id, ops)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	writeScimJSON(w, http.StatusOK, toScimUser(r, user))
+}
+
+func (h *scimHTTP) deleteUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := scimID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.uc.DeleteUser(r.Context(), id); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *scimHTTP) listGroups(w http.ResponseWriter, r *http.Request) {
+	query, ok := h.query(w, r)
+	if !ok {
+		return
+	}
+	list, err := h.uc.ListGroups(r.Context(), query)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	resources := make([]*scimGroup, 0, len(list.Groups))
+	for _, group := range list.Groups {
+		resources = append(resources, toScimGroup(r, group))
+	}
+	writeScimJSON(w, http.StatusOK, &scimListResponse{
+		Schemas:      []string{scimListSchemaURN},
+		TotalResults: list.TotalResults,
+		StartIndex:   list.StartIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+func (h *scimHTTP) createGroup(w http.ResponseWriter, r *http.Request) {
+	group, ok := decodeScimGroup(w, r)
+	if !ok {
+		return
+	}
+	created, err := h.uc.CreateGroup(r.Context(), group)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	resource := toScimGroup(r, created)
+	w.Header().Set("Location", resource.Meta.Location)
+	writeScimJSON(w, http.StatusCreated, resource)
+}
+
+func (h *scimHTTP) getGroup(w http.ResponseWriter, r *http.Request) {
+	id, ok := scimID(w, r)
+	if !ok {
+		return
+	}
+	group, err := h.uc.GetGroup(r.Context(), id)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	writeScimJSON(w, http.StatusOK, toScimGroup(r, group))
+}
+
+func (h *scimHTTP) replaceGroup(w http.ResponseWriter, r *http.Request) {
+	id, ok := scimID(w, r)
+	if !ok {
+		return
+	}
+	group, ok := decodeScimGroup(w, r)
+	if !ok {
+		return
+	}
+	group.ID = id
+	updated, err := h.uc.ReplaceGroup(r.Context(), group)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	writeScimJSON(w, http.StatusOK, toScimGroup(r, updated))
+}
+
+func (h *scimHTTP) patchGroup(w http.ResponseWriter, r *http.Request) {
+	id, ok := scimID(w, r)
+	if !ok {
+		return
+	}
+	ops, ok := decodeScimPatch(w, r)
+	if !ok {
+		return
+	}
+	group, err := h.uc.PatchGroup(r.Context(), id, ops)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	writeScimJSON(w, http.StatusOK, toScimGroup(r, group))
+}
+
+func (h *scimHTTP) deleteGroup(w http.ResponseWriter, r *http.Request) {
+	id, ok := scimID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.uc.DeleteGroup(r.Context(), id); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// query 解析 filter、startIndex 和 count，未指定 count 时返回一整页
+func (h *scimHTTP) query(w http.ResponseWriter, r *http.Request) (*model.ScimQuery, bool) {
+	params := r.URL.Query()
+	query := &model.ScimQuery{Filter: params.Get("filter"), StartIndex: 1, Count: h.uc.MaxResults()}
+	for name, target := range map[string]*int32{"startIndex": &query.StartIndex, "count": &query.Count} {
+		if raw := params.Get(name); raw != "" {
+			n, err := strconv.ParseInt(raw, 10, 32)
+			if err != nil {
+				writeScimError(w, http.StatusBadRequest, model.ScimErrInvalidValue, name+" must be an integer")
+				return nil, false
+			}
+			*target = int32(n)
+		}
+	}
+	return query, true
+}
+
+func (h *scimHTTP) decodeUser(w http.ResponseWriter, r *http.Request) (*model.ScimUser, bool) {
+	var body scimUser
+	if !decodeScimBody(w, r, &body) {
+		return nil, false
+	}
+
+	user := &model.ScimUser{
+		ExternalID:  body.ExternalID,
+		UserName:    body.UserName,
+		DisplayName: body.DisplayName,
+		Active:      body.Active == nil || *body.Active,
+	}
+	if body.Name != nil {
+		user.GivenName, user.FamilyName = body.Name.GivenName, body.Name.FamilyName
+	}
+	// 只保存一个邮箱，优先使用 primary
+	for i, email := range body.Emails {
+		if i == 0 || email.Primary {
+			user.Email = email.Value
+		}
+		if email.Primary {
+			break
+		}
+	}
+	return user, true
+}
+
+// writeError 把用例返回的错误转换为 SCIM 错误响应
+func (h *scimHTTP) writeError(w http.ResponseWriter, err error) {
+	var scimErr *model.ScimError
+	errors.As(err, &scimErr)
+	scimType, detail := "", ""
+	if scimErr != nil {
+		scimType, detail = scimErr.Type, scimErr.Detail
+	}
+
+	switch connect.CodeOf(err) {
+	case connect.CodeInvalidArgument:
+		writeScimError(w, http.StatusBadRequest, scimType, detail)
+	case connect.CodeNotFound:
+		writeScimError(w, http.StatusNotFound, "", "resource not found")
+	case connect.CodeAlreadyExists:
+		writeScimError(w, http.StatusConflict, scimType, detail)
+	default:
+		h.l.Warn("scim request failed", zap.Error(err))
+		writeScimError(w, http.StatusInternalServerError, "", "internal error")
+	}
+}
+
+func decodeScimGroup(w http.ResponseWriter, r *http.Request) (*model.ScimGroup, bool) {
+	var body scimGroup
+	if !decodeScimBody(w, r, &body) {
+		return nil, false
+	}
+
+	group := &model.ScimGroup{ExternalID: body.ExternalID, DisplayName: body.DisplayName}
+	for _, m := range body.Members {
+		userID, err := strconv.ParseInt(m.Value, 10, 64)
+		if err != nil {
+			writeScimError(w, http.StatusBadRequest, model.ScimErrInvalidValue, fmt.Sprintf("invalid member %q", m.Value))
+			return nil, false
+		}
+		group.Members = append(group.Members, &model.ScimMember{UserID: userID})
+	}
+	return group, true
+}
+
+func decodeScimPatch(w http.ResponseWriter, r *http.Request) ([]*model.ScimPatchOperation, bool) {
+	var body scimPatchRequest
+	if !decodeScimBody(w, r, &body) {
+		return nil, false
+	}
+	if len(body.Schemas) != 1 || body.Schemas[0] != scimPatchSchemaURN || len(body.Operations) == 0 {
+		writeScimError(w, http.StatusBadRequest, model.ScimErrInvalidSyntax, "request must be a PatchOp with operations")
+		return nil, false
+	}
+
+	ops := make([]*model.ScimPatchOperation, 0, len(body.Operations))
+	for _, op := range body.Operations {
+		ops = append(ops, &model.ScimPatchOperation{Op: op.Op, Path: op.Path, Value: op.Value})
+	}
+	return ops, true
+}
+
+func decodeScimBody(w http.ResponseWriter, r *http.Request, body any) bool {
+	if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+		writeScimError(w, http.StatusBadRequest, model.ScimErrInvalidSyntax, "invalid JSON body")
+		return false
+	}
+	return true
+}
+
+// scimID 资源ID是数据库主键，非数字的ID不可能存在
+func scimID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeScimError(w, http.StatusNotFound, "", "resource not found")
+		return 0, false
+	}
+	return id, true
+}
+
+func toScimUser(r *http.Request, user *model.ScimUser) *scimUser {
+	active := user.Active
+	resource := &scimUser{
+		Schemas:     []string{scimUserSchemaURN},
+		ID:          strconv.FormatInt(user.ID, 10),
+		ExternalID:  user.ExternalID,
+		UserName:    user.UserName,
+		DisplayName: user.DisplayName,
+		Active:      &active,
+		Meta:        newScimMeta(r, "User", "Users", user.ID, user.CreatedAt, user.UpdatedAt),
+	}
+	if user.GivenName != "" || user.FamilyName != "" {
+		resource.Name = &scimName{GivenName: user.GivenName, FamilyName: user.FamilyName}
+	}
+	if user.Email != "" {
+		resource.Emails = []scimEmail{{Value: user.Email, Type: "work", Primary: true}}
+	}
+	return resource
+}
+
+func toScimGroup(r *http.Request, group *model.ScimGroup) *scimGroup {
+	resource := &scimGroup{
+		Schemas:     []string{scimGroupSchemaURN},
+		ID:          strconv.FormatInt(group.ID, 10),
+		ExternalID:  group.ExternalID,
+		DisplayName: group.DisplayName,
+		Members:     make([]scimMember, 0, len(group.Members)),
+		Meta:        newScimMeta(r, "Group", "Groups", group.ID, group.CreatedAt, group.UpdatedAt),
+	}
+	for _, m := range group.Members {
+		id := strconv.FormatInt(m.UserID, 10)
+		resource.Members = append(resource.Members, scimMember{Value: id, Display: m.Display, Ref: scimBaseURL(r) + "Users/" + id})
+	}
+	return resource
+}
+
+func newScimMeta(r *http.Request, resourceType, endpoint string, id int64, created, updated time.Time) *scimMeta {
+	return &scimMeta{
+		ResourceType: resourceType,
+		Created:      created.UTC().Format(time.RFC3339),
+		LastModified: updated.UTC().Format(time.RFC3339),
+		Location:     scimBaseURL(r) + endpoint + "/" + strconv.FormatInt(id, 10),
+	}
+}
+
+func scimBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + scimPath
+}
+
+func writeScimError(w http.ResponseWriter, status int, scimType, detail string) {
+	writeScimJSON(w, status, &scimErrorResponse{
+		Schemas:  []string{scimErrorSchemaURN},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+func writeScimJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", scimContentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"net/http"
+)
+
+// scimAttribute 发现端点中描述的属性（RFC 7643 7）
+type scimAttribute struct {
+	Name          string          `json:"name"`
+	Type          string          `json:"type"`
+	MultiValued   bool            `json:"multiValued"`
+	Required      bool            `json:"required"`
+	CaseExact     bool            `json:"caseExact"`
+	Mutability    string          `json:"mutability"`
+	Returned      string          `json:"returned"`
+	Uniqueness    string          `json:"uniqueness"`
+	SubAttributes []scimAttribute `json:"subAttributes,omitempty"`
+}
+
+type scimSchema struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Attributes  []scimAttribute `json:"attributes"`
+	Meta        *scimMeta       `json:"meta"`
+}
+
+type scimResourceType struct {
+	Schemas     []string  `json:"schemas"`
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Endpoint    string    `json:"endpoint"`
+	Description string    `json:"description"`
+	Schema      string    `json:"schema"`
+	Meta        *scimMeta `json:"meta"`
+}
+
+// attr 可读写、不区分大小写的单值属性
+func attr(name, typ string) scimAttribute {
+	return scimAttribute{Name: name, Type: typ, Mutability: "readWrite", Returned: "default", Uniqueness: "none"}
+}
+
+// unique 必填且在租户内唯一
+func (a scimAttribute) unique() scimAttribute {
+	a.Required, a.Uniqueness = true, "server"
+	return a
+}
+
+func (a scimAttribute) caseExact() scimAttribute {
+	a.CaseExact = true
+	return a
+}
+
+func (a scimAttribute) multiValued() scimAttribute {
+	a.MultiValued = true
+	return a
+}
+
+func (a scimAttribute) sub(attributes ...scimAttribute) scimAttribute {
+	a.SubAttributes = attributes
+	return a
+}
+
+// scimSchemas 只描述实际保存的属性
+var scimSchemas = []scimSchema{
+	{
+		Schemas:     []string{scimSchemaSchemaURN},
+		ID:          scimUserSchemaURN,
+		Name:        "User",
+		Description: "User Account",
+		Attributes: []scimAttribute{
+			attr("userName", "string").unique(),
+			attr("externalId", "string").caseExact(),
+			attr("displayName", "string"),
+			attr("name", "complex").sub(attr("givenName", "string"), attr("familyName", "string")),
+			attr("emails", "complex").multiValued().sub(attr("value", "string"), attr("type", "string"), attr("primary", "boolean")),
+			attr("active", "boolean"),
+		},
+	},
+	{
+		Schemas:     []string{scimSchemaSchemaURN},
+		ID:          scimGroupSchemaURN,
+		Name:        "Group",
+		Description: "Group",
+		Attributes: []scimAttribute{
+			attr("displayName", "string").unique(),
+			attr("externalId", "string").caseExact(),
+			attr("members", "complex").multiValued().sub(attr("value", "string"), attr("display", "string"), attr("$ref", "reference")),
+		},
+	},
+}
+
+var scimResourceTypes = []scimResourceType{
+	{Schemas: []string{scimTypeSchemaURN}, ID: "User", Name: "User", Endpoint: "/Users", Description: "User Account", Schema: scimUserSchemaURN},
+	{Schemas: []string{scimTypeSchemaURN}, ID: "Group", Name: "Group", Endpoint: "/Groups", Description: "Group", Schema: scimGroupSchemaURN},
+}
+
+func (h *scimHTTP) serviceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	supported := func(ok bool) map[string]bool { return map[string]bool{"supported": ok} }
+	writeScimJSON(w, http.StatusOK, map[string]any{
+		"schemas":        []string{scimConfigSchemaURN},
+		"patch":          supported(true),
+		"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]any{"supported": true, "maxResults": h.uc.MaxResults()},
+		"changePassword": supported(false),
+		"sort":           supported(false),
+		"etag":           supported(false),
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer Token",
+			"description": "Per-client bearer token configured on the server",
+			"primary":     true,
+		}},
+		"meta": &scimMeta{ResourceType: "ServiceProviderConfig", Location: scimBaseURL(r) + "ServiceProviderConfig"},
+	})
+}
+
+func (h *scimHTTP) resourceTypes(w http.ResponseWriter, r *http.Request) {
+	types := make([]scimResourceType, 0, len(scimResourceTypes))
+	for _, t := range scimResourceTypes {
+		t.Meta = &scimMeta{ResourceType: "ResourceType", Location: scimBaseURL(r) + "ResourceTypes/" + t.ID}
+		types = append(types, t)
+	}
+	writeScimDiscovery(w, r.PathValue("id"), types, func(t scimResourceType) string { return t.ID })
+}
+
+func (h *scimHTTP) schemas(w http.ResponseWriter, r *http.Request) {
+	schemas := make([]scimSchema, 0, len(scimSchemas))
+	for _, s := range scimSchemas {
+		s.Meta = &scimMeta{ResourceType: "Schema", Location: scimBaseURL(r) + "Schemas/" + s.ID}
+		schemas = append(schemas, s)
+	}
+	writeScimDiscovery(w, r.PathValue("id"), schemas, func(s scimSchema) string { return s.ID })
+}
+
+// writeScimDiscovery 未指定 id 时返回列表，否则返回单个资源
+func writeScimDiscovery[T any](w http.ResponseWriter, id string, resources []T, idOf func(T) string) {
+	if id == "" {
+		writeScimJSON(w, http.StatusOK, &scimListResponse{
+			Schemas:      []string{scimListSchemaURN},
+			TotalResults: int64(len(resources)),
+			StartIndex:   1,
+			ItemsPerPage: len(resources),
+			Resources:    resources,
+		})
+		return
+	}
+	for _, resource := range resources {
+		if idOf(resource) == id {
+			writeScimJSON(w, http.StatusOK, resource)
+			return
+		}
+	}
+	writeScimError(w, http.StatusNotFound, "", "resource not found")
+}
@@ -5,9 +5,12 @@ import (
 	"net/http"
 	"time"
 
+	"connect-go-example/api/admin/v1/adminv1connect"
 	"connect-go-example/api/check/v1/checkv1connect"
 
 	"connect-go-example/api/greet/v1/greetv1connect"
+	"connect-go-example/api/oauth/v1/oauthv1connect"
+	"connect-go-example/internal/biz/model"
 	conf "connect-go-example/internal/conf/v1"
 
 	"connectrpc.com/connect"
@@ -31,9 +34,15 @@ func NewHTTPServer(
 	cfg *conf.Bootstrap,
 	greetv1Service greetv1connect.GreetServiceHandler,
 	checkv1Service checkv1connect.CheckServiceHandler,
+	adminv1Service adminv1connect.AdminServiceHandler,
+	oauthv1Service oauthv1connect.OAuthServiceHandler,
+	scim model.ScimUseCase,
 	logger *zap.Logger,
 	monitoringMiddleware func(http.Handler) http.Handler,
 	connectInterceptor connect.UnaryInterceptorFunc,
+	tenantInterceptor *TenantInterceptor,
+	authInterceptor *AuthInterceptor,
+	proofs model.DPoPVerifier,
 ) *http.Server {
 	// 1. 创建 OTel Connect 拦截器实例
 	otelInterceptor, err := otelconnect.NewInterceptor(
@@ -43,8 +52,8 @@ func NewHTTPServer(
 		logger.Fatal("failed to create otel interceptor", zap.Error(err))
 	}
 
-	// 2. 将 OTel 拦截器和监控拦截器加入到 Connect 拦截器列表中
-	interceptors := connect.WithInterceptors(otelInterceptor, connectInterceptor)
+	// 2. 将 OTel 拦截器、监控拦截器、租户拦截器和认证拦截器加入到 Connect 拦截器列表中，认证依赖已识别的租户
+	interceptors := connect.WithInterceptors(otelInterceptor, connectInterceptor, tenantInterceptor, authInterceptor)
 
 	// 3. 将拦截器传递给 Service Handler
 	greetv1connectPath, greetv1connectHandler := greetv1connect.NewGreetServiceHandler(
@@ -55,23 +64,43 @@ func NewHTTPServer(
 		checkv1Service,
 		interceptors,
 	)
+	adminv1connectPath, adminv1connectHandler := adminv1connect.NewAdminServiceHandler(
+		adminv1Service,
+		interceptors,
+	)
+	oauthv1connectPath, oauthv1connectHandler := oauthv1connect.NewOAuthServiceHandler(
+		oauthv1Service,
+		interceptors,
+	)
 
 	mux := http.NewServeMux()
-	mux.Handle(greetv1connectPath, greetv1connectHandler)
+	mux.Handle(greetv1connectPath, withoutWriteDeadline(greetv1connectHandler, logger,
+		greetv1connect.GreetServiceWatchAuthRequestProcedure,
+	))
 	mux.Handle(checkv1connectPath, checkv1connectHandler)
+	mux.Handle(adminv1connectPath, adminv1connectHandler)
+	mux.Handle(oauthv1connectPath, oauthv1connectHandler)
+	mux.Handle(introspectionPath, introspectionHandler(oauthv1Service, logger))
+	mux.Handle(tokenPath, tokenHandler(oauthv1Service, proofs, logger))
+	mux.Handle(scimPath, scimHandler(scim, logger))
 
 	// CORS 配置
 	corsHandler := cors.New(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   connectcors.AllowedMethods(),
-		AllowedHeaders:   connectcors.AllowedHeaders(),
-		ExposedHeaders:   connectcors.ExposedHeaders(),
+		AllowedHeaders:   corsAllowedHeaders(cfg),
+		ExposedHeaders:   append(connectcors.ExposedHeaders(), "WWW-Authenticate"),
 		MaxAge:           7200,
 		AllowCredentials: false,
 	})
 
-	// 创建处理器链：监控中间件 -> CORS -> HTTP/2
-	handlerChain := monitoringMiddleware(corsHandler.Handler(mux))
+	proxies, err := newTrustedProxies(cfg.Server.Http.TrustedProxies)
+	if err != nil {
+		logger.Fatal("invalid server.http.trusted_proxies", zap.Error(err))
+	}
+
+	// 创建处理器链：代理请求头 -> 监控中间件 -> CORS -> HTTP/2
+	handlerChain := withProxyHeaders(monitoringMiddleware(corsHandler.Handler(withRequestHost(mux))), proxies)
 
 	server := &http.Server{
 		Addr:         cfg.Server.Http.Addr,
@@ -95,3 +124,32 @@ func NewHTTPServer(
 
 	return server
 }
+
+// corsAllowedHeaders 在 Connect 协议的请求头之外允许认证、DPoP 和租户识别使用的请求头
+func corsAllowedHeaders(cfg *conf.Bootstrap) []string {
+	tenantHeader, clientIDHeader := "X-Tenant-ID", "X-Client-ID"
+	if cfg.GetTenancy().GetHeader() != "" {
+		tenantHeader = cfg.GetTenancy().GetHeader()
+	}
+	if cfg.GetTenancy().GetClientIdHeader() != "" {
+		clientIDHeader = cfg.GetTenancy().GetClientIdHeader()
+	}
+	return append(connectcors.AllowedHeaders(), "Authorization", "DPoP", tenantHeader, clientIDHeader)
+}
+
+// withoutWriteDeadline 为服务端流式接口取消写超时，其余请求仍使用服务器的 WriteTimeout
+func withoutWriteDeadline(next http.Handler, logger *zap.Logger, procedures ...string) http.Handler {
+	streaming := make(map[string]struct{}, len(procedures))
+	for _, procedure := range procedures {
+		streaming[procedure] = struct{}{}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := streaming[r.URL.Path]; ok {
+			if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+				logger.Warn("failed to clear write deadline", zap.String("path", r.URL.Path), zap.Error(err))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -3,14 +3,24 @@ package server
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
+	v1admin "connect-go-example/api/admin/v1"
+	"connect-go-example/api/admin/v1/adminv1connect"
 	v1check "connect-go-example/api/check/v1"
 	"connect-go-example/api/check/v1/checkv1connect"
 	v1greet "connect-go-example/api/greet/v1"
 	"connect-go-example/api/greet/v1/greetv1connect"
+	v1oauth "connect-go-example/api/oauth/v1"
+	"connect-go-example/api/oauth/v1/oauthv1connect"
+	"connect-go-example/internal/biz"
+	"connect-go-example/internal/biz/model"
 	conf "connect-go-example/internal/conf/v1"
 
 	"connectrpc.com/connect"
@@ -31,8 +41,9 @@ var (
 	_ checkv1connect.CheckServiceHandler = (*MockCheckService)(nil)
 )
 
-// MockGreetService 是 GreetService 的模拟实现
+// MockGreetService 是 GreetService 的模拟实现，未覆盖的方法由 Unimplemented 兜底
 type MockGreetService struct {
+	greetv1connect.UnimplementedGreetServiceHandler
 	mock.Mock
 }
 
@@ -73,6 +84,42 @@ func (m *MockCheckService) Ready(ctx context.Context, req *connect.Request[v1che
 	return args.Get(0).(*connect.Response[v1check.ReadyCheckReply]), args.Error(1)
 }
 
+// MockTokenVerifier 是 TokenVerifier 的模拟实现
+type MockTokenVerifier struct {
+	mock.Mock
+}
+
+func (m *MockTokenVerifier) VerifyToken(ctx context.Context, token string) (*model.Principal, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Principal), args.Error(1)
+}
+
+// MockDPoPVerifier 是 DPoPVerifier 的模拟实现
+type MockDPoPVerifier struct {
+	mock.Mock
+}
+
+func (m *MockDPoPVerifier) VerifyProof(ctx context.Context, proof *model.DPoPProof) (string, error) {
+	args := m.Called(ctx, proof)
+	return args.String(0), args.Error(1)
+}
+
+// MockTenantUseCase 是 TenantUseCase 的模拟实现
+type MockTenantUseCase struct {
+	mock.Mock
+}
+
+func (m *MockTenantUseCase) ResolveTenant(ctx context.Context, hint model.TenantHint) (*model.Tenant, error) {
+	args := m.Called(ctx, hint)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Tenant), args.Error(1)
+}
+
 // testLifecycle 是用于测试的简单生命周期实现
 type testLifecycle struct {
 	hooks []fx.Hook
@@ -117,6 +164,10 @@ func (suite *ServerTestSuite) SetupTest() {
 	// 创建 Connect 监控拦截器
 	connectInterceptor := ConnectMonitoringInterceptor(suite.logger)
 
+	// 创建租户和认证拦截器
+	tenantInterceptor := NewTenantInterceptor(new(MockTenantUseCase), cfg, suite.logger)
+	authInterceptor := NewAuthInterceptor(new(MockTokenVerifier), new(MockDPoPVerifier), suite.logger)
+
 	// 创建一个简单的生命周期实现
 	lc := &testLifecycle{}
 
@@ -125,9 +176,15 @@ func (suite *ServerTestSuite) SetupTest() {
 		cfg,
 		suite.greetService,
 		suite.checkService,
+		adminv1connect.UnimplementedAdminServiceHandler{},
+		oauthv1connect.UnimplementedOAuthServiceHandler{},
+		stubScimUseCase{},
 		suite.logger,
 		monitoringMiddleware,
 		connectInterceptor,
+		tenantInterceptor,
+		authInterceptor,
+		new(MockDPoPVerifier),
 	)
 }
 
@@ -272,6 +329,8 @@ func TestNewHTTPServer(t *testing.T) {
 
 	monitoringMiddleware := MonitoringMiddleware(logger)
 	connectInterceptor := ConnectMonitoringInterceptor(logger)
+	tenantInterceptor := NewTenantInterceptor(new(MockTenantUseCase), cfg, logger)
+	authInterceptor := NewAuthInterceptor(new(MockTokenVerifier), new(MockDPoPVerifier), logger)
 
 	// 创建一个简单的生命周期
 	lc := &testLifecycle{}
@@ -281,14 +340,30 @@ func TestNewHTTPServer(t *testing.T) {
 		cfg,
 		greetService,
 		checkService,
+		adminv1connect.UnimplementedAdminServiceHandler{},
+		oauthv1connect.UnimplementedOAuthServiceHandler{},
+		stubScimUseCase{},
 		logger,
 		monitoringMiddleware,
 		connectInterceptor,
+		tenantInterceptor,
+		authInterceptor,
+		new(MockDPoPVerifier),
 	)
 
 	assert.NotNil(t, server)
 	assert.Equal(t, ":8080", server.Addr)
 	assert.NotNil(t, server.Handler)
+
+	// 浏览器的预检请求需要允许认证、DPoP 和租户请求头
+	req := httptest.NewRequest(http.MethodOptions, greetv1connect.GreetServiceRefreshTokenProcedure, nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	req.Header.Set("Access-Control-Request-Headers", "authorization,content-type,dpop,x-client-id,x-tenant-id")
+	w := httptest.NewRecorder()
+	server.Handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "authorization,content-type,dpop,x-client-id,x-tenant-id", w.Header().Get("Access-Control-Allow-Headers"))
 }
 
 func TestMonitoringMiddlewareIntegration(t *testing.T) {
@@ -364,3 +439,566 @@ func TestConnectMonitoringInterceptorIntegration(t *testing.T) {
 	assert.Error(t, err2)
 	assert.Nil(t, resp2)
 }
+
+func TestAuthInterceptor(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	verifier := new(MockTokenVerifier)
+	verifier.On("VerifyToken", mock.Anything, "good").Return(&model.Principal{UserID: 7, Username: "testuser"}, nil)
+	verifier.On("VerifyToken", mock.Anything, "bad").Return(nil, errors.New("invalid token"))
+
+	mux := http.NewServeMux()
+	mux.Handle(greetv1connect.NewGreetServiceHandler(&principalGreetService{},
+		connect.WithInterceptors(NewAuthInterceptor(verifier, new(MockDPoPVerifier), logger)),
+	))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	client := greetv1connect.NewGreetServiceClient(srv.Client(), srv.URL)
+
+	call := func(authorization string) (*connect.Response[v1greet.GetCrossDeviceLoginResponse], error) {
+		req := connect.NewRequest(&v1greet.GetCrossDeviceLoginRequest{Code: "ABCD2345"})
+		if authorization != "" {
+			req.Header().Set("Authorization", authorization)
+		}
+		return client.GetCrossDeviceLogin(context.Background(), req)
+	}
+
+	// 缺少令牌
+	_, err := call("")
+	assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+
+	// 令牌无效
+	_, err = call("Bearer bad")
+	assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+
+	// 令牌有效，调用方写入 ctx
+	resp, err := call("Bearer good")
+	assert.NoError(t, err)
+	assert.Equal(t, "testuser", resp.Msg.Requester.ClientName)
+
+	// 无需认证的接口不校验令牌
+	_, err = client.CreateCrossDeviceLogin(context.Background(), connect.NewRequest(&v1greet.CreateCrossDeviceLoginRequest{}))
+	assert.NoError(t, err)
+
+	// 可选认证的接口：未携带令牌按匿名处理，携带时必须有效
+	deny := func(authorization string) error {
+		req := connect.NewRequest(&v1greet.DenyAuthRequestRequest{AuthRequestId: "req-1"})
+		if authorization != "" {
+			req.Header().Set("Authorization", authorization)
+		}
+		_, err := client.DenyAuthRequest(context.Background(), req)
+		return err
+	}
+	assert.Equal(t, connect.CodeNotFound, connect.CodeOf(deny("")))
+	assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(deny("Bearer bad")))
+	assert.NoError(t, deny("Bearer good"))
+}
+
+func TestAuthInterceptor_DPoP(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	verifier := new(MockTokenVerifier)
+	verifier.On("VerifyToken", mock.Anything, "bound").Return(&model.Principal{UserID: 7, Username: "bound", ProofKey: "jkt-1"}, nil)
+	verifier.On("VerifyToken", mock.Anything, "unbound").Return(&model.Principal{UserID: 7, Username: "unbound"}, nil)
+	proofs := new(MockDPoPVerifier)
+	proofs.On("VerifyProof", mock.Anything, mock.MatchedBy(func(p *model.DPoPProof) bool { return p.Proof == "proof-1" })).Return("jkt-1", nil)
+	proofs.On("VerifyProof", mock.Anything, mock.MatchedBy(func(p *model.DPoPProof) bool { return p.Proof == "proof-2" })).Return("jkt-2", nil)
+	proofs.On("VerifyProof", mock.Anything, mock.MatchedBy(func(p *model.DPoPProof) bool { return p.Proof == "replayed" })).Return("", fmt.Errorf("%w: replayed", model.ErrInvalidDPoPProof))
+
+	mux := http.NewServeMux()
+	mux.Handle(greetv1connect.NewGreetServiceHandler(&principalGreetService{},
+		connect.WithInterceptors(NewAuthInterceptor(verifier, proofs, logger)),
+	))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	client := greetv1connect.NewGreetServiceClient(srv.Client(), srv.URL)
+
+	submit := func(proof string) (*connect.Response[v1greet.SubmitAuthResponse], error) {
+		req := connect.NewRequest(&v1greet.SubmitAuthRequest{})
+		req.Header().Set("DPoP", proof)
+		return client.SubmitAuth(context.Background(), req)
+	}
+	call := func(authorization, proof string) error {
+		req := connect.NewRequest(&v1greet.GetCrossDeviceLoginRequest{Code: "ABCD2345"})
+		req.Header().Set("Authorization", authorization)
+		if proof != "" {
+			req.Header().Set("DPoP", proof)
+		}
+		_, err := client.GetCrossDeviceLogin(context.Background(), req)
+		return err
+	}
+
+	// 获取令牌时证明密钥写入 ctx
+	resp, err := submit("proof-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "jkt-1", resp.Msg.AuthToken)
+	proofs.AssertCalled(t, "VerifyProof", mock.Anything, &model.DPoPProof{
+		Proof:  "proof-1",
+		Method: http.MethodPost,
+		Path:   greetv1connect.GreetServiceSubmitAuthProcedure,
+	})
+
+	_, err = submit("replayed")
+	assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+	var connectErr *connect.Error
+	assert.True(t, errors.As(err, &connectErr))
+	assert.Contains(t, connectErr.Meta().Get("WWW-Authenticate"), "DPoP")
+
+	// 绑定的令牌必须使用 DPoP 方案并携带同一密钥的证明
+	assert.NoError(t, call("DPoP bound", "proof-1"))
+	proofs.AssertCalled(t, "VerifyProof", mock.Anything, mock.MatchedBy(func(p *model.DPoPProof) bool {
+		return p.Proof == "proof-1" && p.AccessToken == "bound"
+	}))
+	assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(call("Bearer bound", "proof-1")))
+	assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(call("DPoP bound", "")))
+	assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(call("DPoP bound", "proof-2")))
+
+	// 未绑定的令牌不能使用 DPoP 方案，但可以携带证明以便刷新时绑定
+	assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(call("DPoP unbound", "proof-1")))
+	assert.NoError(t, call("Bearer unbound", "proof-2"))
+}
+
+func TestAuthInterceptor_AdminWebhooks(t *testing.T) {
+	logger := zap.NewNop()
+	tokens, err := biz.NewTokenManager(&conf.Bootstrap{Auth: &conf.Auth{JwtSecret: "test-secret"}}, logger)
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.Handle(adminv1connect.NewAdminServiceHandler(&principalAdminService{},
+		connect.WithInterceptors(NewAuthInterceptor(tokens, new(MockDPoPVerifier), logger)),
+	))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	client := adminv1connect.NewAdminServiceClient(srv.Client(), srv.URL)
+
+	// 携带有效令牌时调用方写入 ctx
+	token, err := tokens.Issue(1, 7, "admin")
+	assert.NoError(t, err)
+	req := connect.NewRequest(&v1admin.ListWebhookSubscriptionsRequest{})
+	req.Header().Set("Authorization", "Bearer "+token)
+	resp, err := client.ListWebhookSubscriptions(context.Background(), req)
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Msg.Subscriptions, 1) {
+		assert.Equal(t, int64(7), resp.Msg.Subscriptions[0].Id)
+	}
+
+	// 未携带令牌时所有 Webhook 管理接口都在进入处理函数之前被拒绝
+	calls := map[string]func() error{
+		"CreateWebhookSubscription": func() error {
+			_, err := client.CreateWebhookSubscription(context.Background(), connect.NewRequest(&v1admin.CreateWebhookSubscriptionRequest{}))
+			return err
+		},
+		"ListWebhookSubscriptions": func() error {
+			_, err := client.ListWebhookSubscriptions(context.Background(), connect.NewRequest(&v1admin.ListWebhookSubscriptionsRequest{}))
+			return err
+		},
+		"DeleteWebhookSubscription": func() error {
+			_, err := client.DeleteWebhookSubscription(context.Background(), connect.NewRequest(&v1admin.DeleteWebhookSubscriptionRequest{}))
+			return err
+		},
+		"ListWebhookDeliveries": func() error {
+			_, err := client.ListWebhookDeliveries(context.Background(), connect.NewRequest(&v1admin.ListWebhookDeliveriesRequest{}))
+			return err
+		},
+		"RedeliverWebhook": func() error {
+			_, err := client.RedeliverWebhook(context.Background(), connect.NewRequest(&v1admin.RedeliverWebhookRequest{}))
+			return err
+		},
+	}
+	for name, call := range calls {
+		assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(call()), name)
+	}
+}
+
+func TestWithProxyHeaders(t *testing.T) {
+	proxies, err := newTrustedProxies([]string{"10.0.0.0/8", "192.0.2.10"})
+	assert.NoError(t, err)
+	_, err = newTrustedProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+
+	var remoteAddr, host string
+	var forwarded []string
+	handler := withProxyHeaders(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		remoteAddr, host = r.RemoteAddr, r.Host
+		forwarded = append(r.Header.Values("X-Forwarded-For"), r.Header.Values("X-Forwarded-Host")...)
+	}), proxies)
+	serve := func(remote string, headers map[string]string) string {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.RemoteAddr = remote
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+		assert.Empty(t, forwarded, "forwarded headers must be removed")
+		return remoteAddr
+	}
+
+	// 直接连接的客户端不能通过请求头伪造地址
+	assert.Equal(t, "203.0.113.9:5555", serve("203.0.113.9:5555", map[string]string{"X-Forwarded-For": "198.51.100.1"}))
+	assert.Equal(t, "203.0.113.9:5555", serve("203.0.113.9:5555", map[string]string{"X-Real-IP": "198.51.100.1"}))
+
+	// 经过可信代理时跳过右侧的代理，客户端自己填写的最左侧地址被忽略
+	assert.Equal(t, "198.51.100.7:0", serve("10.1.2.3:443", map[string]string{"X-Forwarded-For": "1.1.1.1, 198.51.100.7, 10.9.9.9"}))
+	assert.Equal(t, "198.51.100.8:0", serve("192.0.2.10:443", map[string]string{"X-Real-IP": "198.51.100.8"}))
+	assert.Equal(t, "10.1.2.3:443", serve("10.1.2.3:443", nil))
+
+	// 只有可信代理转发的 X-Forwarded-Host 会替换 Host
+	serve("203.0.113.9:5555", map[string]string{"X-Forwarded-Host": "globex.example.com"})
+	assert.Equal(t, "example.com", host)
+	serve("10.1.2.3:443", map[string]string{"X-Forwarded-Host": "evil.example.com, acme.example.com"})
+	assert.Equal(t, "acme.example.com", host)
+}
+
+func TestTenantInterceptor(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	resolver := new(MockTenantUseCase)
+	resolver.On("ResolveTenant", mock.Anything, model.TenantHint{Slug: "acme", Host: "api.example.com"}).Return(&model.Tenant{ID: 2, Slug: "acme"}, nil)
+	resolver.On("ResolveTenant", mock.Anything, model.TenantHint{Slug: "globex", Host: "api.example.com"}).Return(&model.Tenant{ID: 3, Slug: "globex"}, nil)
+	resolver.On("ResolveTenant", mock.Anything, model.TenantHint{Host: "api.example.com"}).Return(nil, nil)
+	verifier := new(MockTokenVerifier)
+	verifier.On("VerifyToken", mock.Anything, "good").Return(&model.Principal{TenantID: 2, UserID: 7, Username: "testuser"}, nil)
+
+	mux := http.NewServeMux()
+	mux.Handle(greetv1connect.NewGreetServiceHandler(&principalGreetService{},
+		connect.WithInterceptors(
+			NewTenantInterceptor(resolver, &conf.Bootstrap{}, logger),
+			NewAuthInterceptor(verifier, new(MockDPoPVerifier), logger),
+		),
+	))
+	// 测试客户端经本机连接，视为可信代理
+	proxies, err := newTrustedProxies([]string{"127.0.0.0/8", "::1"})
+	assert.NoError(t, err)
+	srv := httptest.NewServer(withProxyHeaders(withRequestHost(mux), proxies))
+	defer srv.Close()
+	client := greetv1connect.NewGreetServiceClient(srv.Client(), srv.URL)
+
+	register := func(tenant string) (*connect.Response[v1greet.RegisterResponse], error) {
+		req := connect.NewRequest(&v1greet.RegisterRequest{})
+		req.Header().Set("X-Forwarded-Host", "api.example.com")
+		if tenant != "" {
+			req.Header().Set("X-Tenant-ID", tenant)
+		}
+		return client.Register(context.Background(), req)
+	}
+
+	// 识别出租户后写入 ctx
+	resp, err := register("acme")
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", resp.Msg.UserId)
+
+	// 需要租户的接口未识别出租户
+	_, err = register("")
+	assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+
+	// 令牌中的租户与请求不一致
+	req := connect.NewRequest(&v1greet.GetCrossDeviceLoginRequest{Code: "ABCD2345"})
+	req.Header().Set("X-Forwarded-Host", "api.example.com")
+	req.Header().Set("X-Tenant-ID", "globex")
+	req.Header().Set("Authorization", "Bearer good")
+	_, err = client.GetCrossDeviceLogin(context.Background(), req)
+	assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+
+	// 未指定租户时使用令牌中的租户
+	req.Header().Del("X-Tenant-ID")
+	_, err = client.GetCrossDeviceLogin(context.Background(), req)
+	assert.NoError(t, err)
+}
+
+// principalGreetService 把 ctx 中的调用方回显到响应中
+type principalGreetService struct {
+	greetv1connect.UnimplementedGreetServiceHandler
+}
+
+func (s *principalGreetService) GetCrossDeviceLogin(ctx context.Context, _ *connect.Request[v1greet.GetCrossDeviceLoginRequest]) (*connect.Response[v1greet.GetCrossDeviceLoginResponse], error) {
+	principal, ok := model.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, connect.NewError(connect.CodeInternal, errors.New("principal missing"))
+	}
+	return connect.NewResponse(&v1greet.GetCrossDeviceLoginResponse{
+		Requester: &v1greet.CrossDeviceLoginRequester{ClientName: principal.Username},
+	}), nil
+}
+
+// SubmitAuth 把 ctx 中的 DPoP 密钥指纹回显到令牌字段
+func (s *principalGreetService) SubmitAuth(ctx context.Context, _ *connect.Request[v1greet.SubmitAuthRequest]) (*connect.Response[v1greet.SubmitAuthResponse], error) {
+	return connect.NewResponse(&v1greet.SubmitAuthResponse{AuthToken: model.ProofKeyFromContext(ctx)}), nil
+}
+
+// DenyAuthRequest 已登录时返回成功，匿名调用返回 NotFound，用于区分两种情况
+func (s *principalGreetService) DenyAuthRequest(ctx context.Context, _ *connect.Request[v1greet.DenyAuthRequestRequest]) (*connect.Response[v1greet.DenyAuthRequestResponse], error) {
+	if _, ok := model.PrincipalFromContext(ctx); !ok {
+		return nil, connect.NewError(connect.CodeNotFound, errors.New("anonymous"))
+	}
+	return connect.NewResponse(&v1greet.DenyAuthRequestResponse{}), nil
+}
+
+func (s *principalGreetService) CreateCrossDeviceLogin(context.Context, *connect.Request[v1greet.CreateCrossDeviceLoginRequest]) (*connect.Response[v1greet.CreateCrossDeviceLoginResponse], error) {
+	return connect.NewResponse(&v1greet.CreateCrossDeviceLoginResponse{}), nil
+}
+
+func (s *principalGreetService) Register(ctx context.Context, _ *connect.Request[v1greet.RegisterRequest]) (*connect.Response[v1greet.RegisterResponse], error) {
+	tenant, ok := model.TenantFromContext(ctx)
+	if !ok {
+		return nil, connect.NewError(connect.CodeInternal, errors.New("tenant missing"))
+	}
+	return connect.NewResponse(&v1greet.RegisterResponse{UserId: tenant.Slug}), nil
+}
+
+// principalAdminService 把 ctx 中的用户 ID 回显到订阅 ID，其余接口未实现
+type principalAdminService struct {
+	adminv1connect.UnimplementedAdminServiceHandler
+}
+
+func (s *principalAdminService) ListWebhookSubscriptions(ctx context.Context, _ *connect.Request[v1admin.ListWebhookSubscriptionsRequest]) (*connect.Response[v1admin.ListWebhookSubscriptionsResponse], error) {
+	principal, ok := model.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, connect.NewError(connect.CodeInternal, errors.New("principal missing"))
+	}
+	return connect.NewResponse(&v1admin.ListWebhookSubscriptionsResponse{
+		Subscriptions: []*v1admin.WebhookSubscription{{Id: principal.UserID}},
+	}), nil
+}
+
+// stubOAuthService 校验转发的客户端凭据，只认可固定的令牌
+type stubOAuthService struct {
+	oauthv1connect.UnimplementedOAuthServiceHandler
+}
+
+func (stubOAuthService) Introspect(_ context.Context, req *connect.Request[v1oauth.IntrospectRequest]) (*connect.Response[v1oauth.IntrospectResponse], error) {
+	if id, secret, ok := (&http.Request{Header: req.Header()}).BasicAuth(); !ok || id != "orders" || secret != "secret" {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("invalid client credentials"))
+	}
+	if req.Msg.Token != "good" {
+		return connect.NewResponse(&v1oauth.IntrospectResponse{Active: false}), nil
+	}
+	return connect.NewResponse(&v1oauth.IntrospectResponse{Active: true, Sub: "7", Exp: 1700003600, TokenType: "Bearer", Aud: "orders", Act: &v1oauth.Actor{Sub: "gateway"}}), nil
+}
+
+func (stubOAuthService) ExchangeToken(ctx context.Context, req *connect.Request[v1oauth.ExchangeTokenRequest]) (*connect.Response[v1oauth.ExchangeTokenResponse], error) {
+	switch {
+	case req.Msg.SubjectToken == "bound-token" && model.ProofKeyFromContext(ctx) != "jkt-1":
+		return nil, connect.NewError(connect.CodeInvalidArgument, model.ErrInvalidDPoPProof)
+	case req.Msg.Audience != "orders":
+		return nil, connect.NewError(connect.CodeInvalidArgument, model.ErrInvalidTarget)
+	case req.Msg.Scope != "orders:read":
+		return nil, connect.NewError(connect.CodeInvalidArgument, model.ErrInvalidScope)
+	}
+	return connect.NewResponse(&v1oauth.ExchangeTokenResponse{
+		AccessToken:     "delegated",
+		IssuedTokenType: model.TokenTypeAccessToken,
+		TokenType:       "Bearer",
+		ExpiresIn:       300,
+		Scope:           req.Msg.Scope,
+	}), nil
+}
+
+func TestIntrospectionHandler(t *testing.T) {
+	handler := introspectionHandler(stubOAuthService{}, zap.NewNop())
+	introspect := func(method, token string, authorized bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, introspectionPath, strings.NewReader(url.Values{"token": {token}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if authorized {
+			req.SetBasicAuth("orders", "secret")
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := introspect(http.MethodPost, "good", true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"active":true,"sub":"7","exp":1700003600,"token_type":"Bearer","aud":"orders","act":{"sub":"gateway"}}`, w.Body.String())
+
+	// 无效令牌只返回 active
+	w = introspect(http.MethodPost, "bad", true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"active":false}`, w.Body.String())
+
+	w = introspect(http.MethodPost, "good", false)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+	assert.JSONEq(t, `{"error":"invalid_client"}`, w.Body.String())
+
+	w = introspect(http.MethodPost, "", true)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = introspect(http.MethodGet, "good", true)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestTokenHandler(t *testing.T) {
+	proofs := new(MockDPoPVerifier)
+	proofs.On("VerifyProof", mock.Anything, &model.DPoPProof{Proof: "proof-1", Method: http.MethodPost, Path: tokenPath}).Return("jkt-1", nil)
+	proofs.On("VerifyProof", mock.Anything, mock.MatchedBy(func(p *model.DPoPProof) bool { return p.Proof == "replayed" })).Return("", fmt.Errorf("%w: replayed", model.ErrInvalidDPoPProof))
+	handler := tokenHandler(stubOAuthService{}, proofs, zap.NewNop())
+	exchange := func(form url.Values, dpop ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, tokenPath, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, proof := range dpop {
+			req.Header.Add("DPoP", proof)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+	form := func(audience, scope string) url.Values {
+		return url.Values{
+			"grant_type":         {grantTypeTokenExchange},
+			"subject_token":      {"user-token"},
+			"subject_token_type": {model.TokenTypeAccessToken},
+			"audience":           {audience},
+			"scope":              {scope},
+		}
+	}
+
+	w := exchange(form("orders", "orders:read"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"access_token":"delegated","issued_token_type":"urn:ietf:params:oauth:token-type:access_token","token_type":"Bearer","expires_in":300,"scope":"orders:read"}`, w.Body.String())
+
+	w = exchange(form("payments", "orders:read"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"invalid_target"}`, w.Body.String())
+
+	w = exchange(form("orders", "orders:write"))
+	assert.JSONEq(t, `{"error":"invalid_scope"}`, w.Body.String())
+
+	w = exchange(url.Values{"grant_type": {"client_credentials"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"unsupported_grant_type"}`, w.Body.String())
+
+	// 绑定密钥的令牌需要同一密钥的证明
+	bound := form("orders", "orders:read")
+	bound.Set("subject_token", "bound-token")
+	w = exchange(bound)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"invalid_dpop_proof"}`, w.Body.String())
+	w = exchange(bound, "proof-1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = exchange(bound, "replayed")
+	assert.JSONEq(t, `{"error":"invalid_dpop_proof"}`, w.Body.String())
+	w = exchange(bound, "proof-1", "proof-1")
+	assert.JSONEq(t, `{"error":"invalid_dpop_proof"}`, w.Body.String())
+}
+
+// stubScimUseCase 只认可固定的令牌，未覆盖的方法不会被调用
+type stubScimUseCase struct {
+	model.ScimUseCase
+}
+
+func (stubScimUseCase) Authenticate(_ context.Context, token string) (*model.Tenant, error) {
+	if token != "scim-token" {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("invalid scim token"))
+	}
+	return &model.Tenant{ID: 2}, nil
+}
+
+func (stubScimUseCase) MaxResults() int32 {
+	return 50
+}
+
+func (stubScimUseCase) CreateUser(ctx context.Context, user *model.ScimUser) (*model.ScimUser, error) {
+	if user.UserName == "taken" {
+		return nil, connect.NewError(connect.CodeAlreadyExists, &model.ScimError{Type: model.ScimErrUniqueness, Detail: "resource already exists"})
+	}
+	created := *user
+	created.ID = 7
+	created.CreatedAt = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	created.UpdatedAt = created.CreatedAt
+	return &created, nil
+}
+
+func (stubScimUseCase) GetUser(_ context.Context, id int64) (*model.ScimUser, error) {
+	return nil, connect.NewError(connect.CodeNotFound, model.ErrScimResourceNotFound)
+}
+
+func (stubScimUseCase) ListUsers(ctx context.Context, query *model.ScimQuery) (*model.ScimUserList, error) {
+	if tenant, _ := model.TenantFromContext(ctx); tenant.ID != 2 {
+		return nil, errors.New("tenant missing")
+	}
+	if query.Filter == "bad" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, &model.ScimError{Type: model.ScimErrInvalidFilter, Detail: "bad filter"})
+	}
+	return &model.ScimUserList{
+		TotalResults: 3,
+		StartIndex:   query.StartIndex,
+		Users:        []*model.ScimUser{{ID: int64(query.Count), UserName: query.Filter, Active: true}},
+	}, nil
+}
+
+func (stubScimUseCase) PatchGroup(_ context.Context, id int64, ops []*model.ScimPatchOperation) (*model.ScimGroup, error) {
+	return &model.ScimGroup{
+		ID:          id,
+		DisplayName: ops[0].Path,
+		Members:     []*model.ScimMember{{UserID: 7, Display: "alice"}},
+	}, nil
+}
+
+func TestScimHandler(t *testing.T) {
+	handler := scimHandler(stubScimUseCase{}, zap.NewNop())
+	call := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "http://idp.example.com"+target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer scim-token")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	// 缺少令牌
+	req := httptest.NewRequest(http.MethodGet, scimPath+"Users", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `Bearer realm="scim"`, w.Header().Get("WWW-Authenticate"))
+
+	w = call(http.MethodPost, scimPath+"Users", `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"userName":"alice","name":{"givenName":"Alice"},"emails":[{"value":"home@example.com"},{"value":"alice@example.com","primary":true}]}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "application/scim+json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "http://idp.example.com/scim/v2/Users/7", w.Header().Get("Location"))
+	assert.JSONEq(t, `{
+		"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],
+		"id":"7","userName":"alice","name":{"givenName":"Alice"},
+		"emails":[{"value":"alice@example.com","type":"work","primary":true}],
+		"active":true,
+		"meta":{"resourceType":"User","created":"2026-01-02T03:04:05Z","lastModified":"2026-01-02T03:04:05Z","location":"http://idp.example.com/scim/v2/Users/7"}
+	}`, w.Body.String())
+
+	w = call(http.MethodPost, scimPath+"Users", `{"userName":"taken"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.JSONEq(t, `{"schemas":["urn:ietf:params:scim:api:messages:2.0:Error"],"status":"409","scimType":"uniqueness","detail":"resource already exists"}`, w.Body.String())
+
+	w = call(http.MethodPost, scimPath+"Users", `{`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = call(http.MethodGet, scimPath+"Users/8", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = call(http.MethodGet, scimPath+"Users/abc", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// 未指定 count 时使用 MaxResults
+	w = call(http.MethodGet, scimPath+"Users?filter=alice&startIndex=2", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"totalResults":3,"startIndex":2,"itemsPerPage":1`)
+	assert.Contains(t, w.Body.String(), `"id":"50","userName":"alice"`)
+
+	w = call(http.MethodGet, scimPath+"Users?filter=bad", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"scimType":"invalidFilter"`)
+	w = call(http.MethodGet, scimPath+"Users?count=many", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = call(http.MethodPatch, scimPath+"Groups/3", `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"Add","path":"members","value":[{"value":"7"}]}]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"members":[{"value":"7","display":"alice","$ref":"http://idp.example.com/scim/v2/Users/7"}]`)
+	w = call(http.MethodPatch, scimPath+"Groups/3", `{"Operations":[{"op":"add","path":"members"}]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = call(http.MethodGet, scimPath+"ServiceProviderConfig", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"maxResults":50`)
+	w = call(http.MethodGet, scimPath+"Schemas/urn:ietf:params:scim:schemas:core:2.0:Group", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"members"`)
+	w = call(http.MethodGet, scimPath+"ResourceTypes", "")
+	assert.Contains(t, w.Body.String(), `"totalResults":2`)
+	w = call(http.MethodGet, scimPath+"Bulk", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
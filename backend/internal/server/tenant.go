@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"connect-go-example/api/greet/v1/greetv1connect"
+	"connect-go-example/internal/biz/model"
+	conf "connect-go-example/internal/conf/v1"
+
+	"connectrpc.com/connect"
+	"go.uber.org/zap"
+)
+
+// tenantScopedProcedures 访问用户数据、必须识别出租户的接口
+var tenantScopedProcedures = []string{
+	greetv1connect.GreetServiceGetPowChallengeProcedure,
+	greetv1connect.GreetServiceGetRegistrationParamsProcedure,
+	greetv1connect.GreetServiceRegisterProcedure,
+	greetv1connect.GreetServiceGetAuthChallengeProcedure,
+	greetv1connect.GreetServiceSubmitAuthProcedure,
+	greetv1connect.GreetServiceRequestMagicLinkProcedure,
+	greetv1connect.GreetServiceExchangeMagicLinkProcedure,
+	greetv1connect.GreetServiceRequestRegistrationEmailProcedure,
+	greetv1connect.GreetServiceVerifyStepUpProcedure,
+}
+
+// TenantInterceptor 根据请求头、客户端ID或子域名识别租户，并把租户写入 ctx
+type TenantInterceptor struct {
+	resolver       model.TenantUseCase
+	header         string
+	clientIDHeader string
+	l              *zap.Logger
+	procedures     map[string]struct{}
+}
+
+var _ connect.Interceptor = (*TenantInterceptor)(nil)
+
+func NewTenantInterceptor(resolver model.TenantUseCase, cfg *conf.Bootstrap, logger *zap.Logger) *TenantInterceptor {
+	i := &TenantInterceptor{
+		resolver:       resolver,
+		header:         "X-Tenant-ID",
+		clientIDHeader: "X-Client-ID",
+		l:              logger,
+		procedures:     make(map[string]struct{}, len(tenantScopedProcedures)),
+	}
+	if cfg.Tenancy != nil {
+		if cfg.Tenancy.Header != "" {
+			i.header = cfg.Tenancy.Header
+		}
+		if cfg.Tenancy.ClientIdHeader != "" {
+			i.clientIDHeader = cfg.Tenancy.ClientIdHeader
+		}
+	}
+	for _, procedure := range tenantScopedProcedures {
+		i.procedures[procedure] = struct{}{}
+	}
+	return i
+}
+
+func (i *TenantInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+		ctx, err := i.resolve(ctx, req.Spec().Procedure, req.Header())
+		if err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+func (i *TenantInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *TenantInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		ctx, err := i.resolve(ctx, conn.Spec().Procedure, conn.RequestHeader())
+		if err != nil {
+			return err
+		}
+		return next(ctx, conn)
+	}
+}
+
+func (i *TenantInterceptor) resolve(ctx context.Context, procedure string, header http.Header) (context.Context, error) {
+	// 经过可信代理时 withProxyHeaders 已把 Host 替换为 X-Forwarded-Host
+	tenant, err := i.resolver.ResolveTenant(ctx, model.TenantHint{
+		Slug:     header.Get(i.header),
+		ClientID: header.Get(i.clientIDHeader),
+		Host:     requestHostFromContext(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tenant == nil {
+		if _, ok := i.procedures[procedure]; ok {
+			return nil, connect.NewError(connect.CodeInvalidArgument, model.ErrTenantRequired)
+		}
+		return ctx, nil
+	}
+	return model.NewTenantContext(ctx, tenant), nil
+}
+
+type requestHostKey struct{}
+
+// withRequestHost 把 Host 写入 ctx，Connect 拦截器中无法直接读取 Host
+func withRequestHost(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestHostKey{}, r.Host)))
+	})
+}
+
+func requestHostFromContext(ctx context.Context) string {
+	host, _ := ctx.Value(requestHostKey{}).(string)
+	return host
+}
+
+// checkPrincipalTenant 令牌中的租户必须与请求识别出的租户一致
+func checkPrincipalTenant(ctx context.Context, principal *model.Principal) (context.Context, error) {
+	tenant, ok := model.TenantFromContext(ctx)
+	if !ok {
+		return model.NewTenantContext(ctx, &model.Tenant{ID: principal.TenantID}), nil
+	}
+	if tenant.ID != principal.TenantID {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("token was issued for another tenant"))
+	}
+	return ctx, nil
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	v1 "connect-go-example/api/admin/v1"
+	"connect-go-example/api/admin/v1/adminv1connect"
+	"connect-go-example/internal/biz/model"
+
+	"connectrpc.com/connect"
+)
+
+var _ adminv1connect.AdminServiceHandler = (*AdminService)(nil)
+
+// AdminService 管理接口，认证由 AuthInterceptor 完成，角色校验在 biz 层
+type AdminService struct {
+	uc model.AdminUseCase
+}
+
+func NewAdminService(uc model.AdminUseCase) adminv1connect.AdminServiceHandler {
+	return &AdminService{
+		uc: uc,
+	}
+}
+
+func (s *AdminService) CreateInvite(ctx context.Context, req *connect.Request[v1.CreateInviteRequest]) (*connect.Response[v1.CreateInviteResponse], error) {
+	admin, ok := model.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("authentication required"))
+	}
+
+	ticket, err := s.uc.CreateInvite(ctx, admin, req.Msg.MaxUses, time.Duration(req.Msg.TtlSeconds)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &v1.CreateInviteResponse{
+		Code:   ticket.Code,
+		Invite: toInviteProto(ticket.Invite),
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+func (s *AdminService) ListInvites(ctx context.Context, _ *connect.Request[v1.ListInvitesRequest]) (*connect.Response[v1.ListInvitesResponse], error) {
+	admin, ok := model.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("authentication required"))
+	}
+
+	invites, err := s.uc.ListInvites(ctx, admin)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &v1.ListInvitesResponse{
+		Invites: make([]*v1.Invite, 0, len(invites)),
+	}
+	for _, invite := range invites {
+		response.Invites = append(response.Invites, toInviteProto(invite))
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+func (s *AdminService) CreateWebhookSubscription(ctx context.Context, req *connect.Request[v1.CreateWebhookSubscriptionRequest]) (*connect.Response[v1.CreateWebhookSubscriptionResponse], error) {
+	admin, ok := model.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("authentication required"))
+	}
+
+	sub, err := s.uc.CreateWebhookSubscription(ctx, admin, req.Msg.Url, req.Msg.Events, req.Msg.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &v1.CreateWebhookSubscriptionResponse{
+		Subscription: toWebhookSubscriptionProto(sub),
+		Secret:       sub.Secret,
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+func (s *AdminService) ListWebhookSubscriptions(ctx context.Context, _ *connect.Request[v1.ListWebhookSubscriptionsRequest]) (*connect.Response[v1.ListWebhookSubscriptionsResponse], error) {
+	admin, ok := model.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("authentication required"))
+	}
+
+	subs, err := s.uc.ListWebhookSubscriptions(ctx, admin)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &v1.ListWebhookSubscriptionsResponse{
+		Subscriptions: make([]*v1.WebhookSubscription, 0, len(subs)),
+	}
+	for _, sub := range subs {
+		response.Subscriptions = append(response.Subscriptions, toWebhookSubscriptionProto(sub))
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+func (s *AdminService) DeleteWebhookSubscription(ctx context.Context, req *connect.Request[v1.DeleteWebhookSubscriptionRequest]) (*connect.Response[v1.DeleteWebhookSubscriptionResponse], error) {
+	admin, ok := model.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("authentication required"))
+	}
+
+	if err := s.uc.DeleteWebhookSubscription(ctx, admin, req.Msg.Id); err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&v1.DeleteWebhookSubscriptionResponse{}), nil
+}
+
+func (s *AdminService) ListWebhookDeliveries(ctx context.Context, req *connect.Request[v1.ListWebhookDeliveriesRequest]) (*connect.Response[v1.ListWebhookDeliveriesResponse], error) {
+	admin, ok := model.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("authentication required"))
+	}
+
+	deliveries, err := s.uc.ListWebhookDeliveries(ctx, admin, req.Msg.SubscriptionId, req.Msg.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &v1.ListWebhookDeliveriesResponse{
+		Deliveries: make([]*v1.WebhookDelivery, 0, len(deliveries)),
+	}
+	for _, delivery := range deliveries {
+		response.Deliveries = append(response.Deliveries, toWebhookDeliveryProto(delivery))
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+func (s *AdminService) RedeliverWebhook(ctx context.Context, req *connect.Request[v1.RedeliverWebhookRequest]) (*connect.Response[v1.RedeliverWebhookResponse], error) {
+	admin, ok := model.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("authentication required"))
+	}
+
+	delivery, err := s.uc.RedeliverWebhook(ctx, admin, req.Msg.DeliveryId)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&v1.RedeliverWebhookResponse{Delivery: toWebhookDeliveryProto(delivery)}), nil
+}
+
+func (s *AdminService) SetUserCredentialBackend(ctx context.Context, req *connect.Request[v1.SetUserCredentialBackendRequest]) (*connect.Response[v1.SetUserCredentialBackendResponse], error) {
+	admin, ok := model.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("authentication required"))
+	}
+
+	if err := s.uc.SetUserCredentialBackend(ctx, admin, req.Msg.Username, req.Msg.Backend); err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&v1.SetUserCredentialBackendResponse{}), nil
+}
+
+func toInviteProto(invite *model.Invite) *v1.Invite {
+	return &v1.Invite{
+		Id:         invite.ID,
+		InviterId:  invite.InviterID,
+		MaxUses:    invite.MaxUses,
+		UsedCount:  invite.UsedCount,
+		InviteeIds: invite.InviteeIDs,
+		ExpiresAt:  invite.ExpiresAt.Unix(),
+		CreatedAt:  invite.CreatedAt.Unix(),
+	}
+}
+
+func toWebhookSubscriptionProto(sub *model.WebhookSubscription) *v1.WebhookSubscription {
+	return &v1.WebhookSubscription{
+		Id:        sub.ID,
+		Url:       sub.URL,
+		Events:    sub.Events,
+		CreatedAt: sub.CreatedAt.Unix(),
+	}
+}
+
+func toWebhookDeliveryProto(delivery *model.WebhookDelivery) *v1.WebhookDelivery {
+	return &v1.WebhookDelivery{
+		Id:             delivery.ID,
+		SubscriptionId: delivery.SubscriptionID,
+		EventId:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt.Unix(),
+		LastAttemptAt:  unixOrZero(delivery.LastAttemptAt),
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		DeliveredAt:    unixOrZero(delivery.DeliveredAt),
+		CreatedAt:      delivery.CreatedAt.Unix(),
+	}
+}
+
+// unixOrZero 零值时间转换为 0
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+
+	v1 "connect-go-example/api/greet/v1"
+	"connect-go-example/internal/biz/model"
+
+	"connectrpc.com/connect"
+)
+
+func (s *GreetService) CreateAuthRequest(ctx context.Context, req *connect.Request[v1.CreateAuthRequestRequest]) (*connect.Response[v1.CreateAuthRequestResponse], error) {
+	ticket, err := s.authRequestUseCase.CreateAuthRequest(ctx, req.Msg.ClientName)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &v1.CreateAuthRequestResponse{
+		AuthRequestId: ticket.ID,
+		WatchToken:    ticket.WatchToken,
+		ExpiresAt:     ticket.ExpiresAt.Unix(),
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+func (s *GreetService) WatchAuthRequest(ctx context.Context, req *connect.Request[v1.WatchAuthRequestRequest], stream *connect.ServerStream[v1.WatchAuthRequestResponse]) error {
+	return s.authRequestUseCase.WatchAuthRequest(ctx, req.Msg.AuthRequestId, req.Msg.WatchToken, func(authReq *model.AuthRequest) error {
+		return stream.Send(&v1.WatchAuthRequestResponse{
+			State:     v1.AuthRequestState(authReq.State),
+			AuthToken: authReq.AuthToken,
+			ExpiresAt: authReq.ExpiresAt.Unix(),
+		})
+	})
+}
+
+func (s *GreetService) DenyAuthRequest(ctx context.Context, req *connect.Request[v1.DenyAuthRequestRequest]) (*connect.Response[v1.DenyAuthRequestResponse], error) {
+	if err := s.authRequestUseCase.DenyAuthRequest(ctx, req.Msg.AuthRequestId, req.Msg.WatchToken); err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&v1.DenyAuthRequestResponse{}), nil
+}
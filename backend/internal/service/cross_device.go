@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	v1 "connect-go-example/api/greet/v1"
+	"connect-go-example/internal/biz/model"
+
+	"connectrpc.com/connect"
+)
+
+// locationHeaders CDN 或网关写入的地区请求头，按优先级排列
+var locationHeaders = []string{
+	"CF-IPCountry",
+	"CloudFront-Viewer-Country",
+	"X-Geo-Country",
+}
+
+func (s *GreetService) CreateCrossDeviceLogin(ctx context.Context, req *connect.Request[v1.CreateCrossDeviceLoginRequest]) (*connect.Response[v1.CreateCrossDeviceLoginResponse], error) {
+	ticket, err := s.crossDeviceLoginUseCase.CreateCrossDeviceLogin(ctx, req.Msg.ClientName, clientInfo(req.Peer(), req.Header()))
+	if err != nil {
+		return nil, err
+	}
+
+	response := &v1.CreateCrossDeviceLoginResponse{
+		Code:          ticket.Code,
+		ApproveUrl:    ticket.ApproveURL,
+		AuthRequestId: ticket.AuthRequest.ID,
+		WatchToken:    ticket.AuthRequest.WatchToken,
+		ExpiresAt:     ticket.AuthRequest.ExpiresAt.Unix(),
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+func (s *GreetService) GetCrossDeviceLogin(ctx context.Context, req *connect.Request[v1.GetCrossDeviceLoginRequest]) (*connect.Response[v1.GetCrossDeviceLoginResponse], error) {
+	if _, ok := model.PrincipalFromContext(ctx); !ok {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("authentication required"))
+	}
+
+	login, err := s.crossDeviceLoginUseCase.GetCrossDeviceLogin(ctx, req.Msg.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&v1.GetCrossDeviceLoginResponse{
+		Requester: crossDeviceLoginRequester(login),
+	}), nil
+}
+
+func (s *GreetService) ApproveCrossDeviceLogin(ctx context.Context, req *connect.Request[v1.ApproveCrossDeviceLoginRequest]) (*connect.Response[v1.ApproveCrossDeviceLoginResponse], error) {
+	approver, ok := model.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("authentication required"))
+	}
+
+	login, err := s.crossDeviceLoginUseCase.ApproveCrossDeviceLogin(ctx, approver, req.Msg.Code, req.Msg.Approve)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&v1.ApproveCrossDeviceLoginResponse{
+		Requester: crossDeviceLoginRequester(login),
+	}), nil
+}
+
+func crossDeviceLoginRequester(login *model.CrossDeviceLogin) *v1.CrossDeviceLoginRequester {
+	return &v1.CrossDeviceLoginRequester{
+		Ip:           login.Requester.IP,
+		UserAgent:    login.Requester.UserAgent,
+		LocationHint: login.Requester.LocationHint,
+		ClientName:   login.ClientName,
+		CreatedAt:    login.CreatedAt.Unix(),
+		ExpiresAt:    login.ExpiresAt.Unix(),
+	}
+}
+
+// clientInfo 从请求中提取客户端信息。经过可信代理时 server 层已把 Peer().Addr 替换为真实地址，
+// 这里不读取 X-Forwarded-For 等客户端可以伪造的请求头
+func clientInfo(peer connect.Peer, header http.Header) model.ClientInfo {
+	info := model.ClientInfo{
+		UserAgent: header.Get("User-Agent"),
+		IP:        peer.Addr,
+	}
+	if host, _, err := net.SplitHostPort(peer.Addr); err == nil {
+		info.IP = host
+	}
+
+	for _, name := range locationHeaders {
+		if v := header.Get(name); v != "" {
+			info.LocationHint = v
+			break
+		}
+	}
+
+	return info
+}
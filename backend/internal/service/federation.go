@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	v1 "connect-go-example/api/greet/v1"
+	"connect-go-example/internal/biz/model"
+
+	"connectrpc.com/connect"
+)
+
+func (s *GreetService) ListIdentityProviders(ctx context.Context, req *connect.Request[v1.ListIdentityProvidersRequest]) (*connect.Response[v1.ListIdentityProvidersResponse], error) {
+	providers := s.federationUseCase.ListIdentityProviders(ctx)
+
+	response := &v1.ListIdentityProvidersResponse{
+		Providers: make([]*v1.IdentityProvider, 0, len(providers)),
+	}
+	for _, p := range providers {
+		response.Providers = append(response.Providers, &v1.IdentityProvider{Id: p.ID, DisplayName: p.DisplayName})
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+func (s *GreetService) BeginFederatedLogin(ctx context.Context, req *connect.Request[v1.BeginFederatedLoginRequest]) (*connect.Response[v1.BeginFederatedLoginResponse], error) {
+	ticket, err := s.federationUseCase.BeginFederatedLogin(ctx, req.Msg.Provider, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(toFederatedLoginProto(ticket)), nil
+}
+
+func (s *GreetService) CompleteFederatedLogin(ctx context.Context, req *connect.Request[v1.CompleteFederatedLoginRequest]) (*connect.Response[v1.SubmitAuthResponse], error) {
+	result, err := s.federationUseCase.CompleteFederatedLogin(ctx, req.Msg.State, req.Msg.Code, req.Msg.Binding)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &v1.SubmitAuthResponse{
+		Code:      result.Code,
+		State:     result.State,
+		AuthToken: result.AuthToken,
+		TokenType: result.TokenType,
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+func (s *GreetService) LinkFederatedIdentity(ctx context.Context, req *connect.Request[v1.LinkFederatedIdentityRequest]) (*connect.Response[v1.BeginFederatedLoginResponse], error) {
+	principal, ok := model.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("authentication required"))
+	}
+
+	ticket, err := s.federationUseCase.BeginFederatedLogin(ctx, req.Msg.Provider, principal)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(toFederatedLoginProto(ticket)), nil
+}
+
+func toFederatedLoginProto(ticket *model.FederatedLoginTicket) *v1.BeginFederatedLoginResponse {
+	return &v1.BeginFederatedLoginResponse{
+		AuthorizationUrl: ticket.AuthorizationURL,
+		Binding:          ticket.Binding,
+		ExpiresAt:        ticket.ExpiresAt.Unix(),
+	}
+}
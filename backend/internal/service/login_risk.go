@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+
+	v1 "connect-go-example/api/greet/v1"
+
+	"connectrpc.com/connect"
+)
+
+func (s *GreetService) VerifyStepUp(ctx context.Context, req *connect.Request[v1.VerifyStepUpRequest]) (*connect.Response[v1.SubmitAuthResponse], error) {
+	result, err := s.loginRiskUseCase.VerifyStepUp(ctx, req.Msg.StepUpId, req.Msg.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &v1.SubmitAuthResponse{
+		Code:      result.Code,
+		State:     result.State,
+		AuthToken: result.AuthToken,
+		TokenType: result.TokenType,
+	}
+
+	return connect.NewResponse(response), nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+
+	v1 "connect-go-example/api/greet/v1"
+
+	"connectrpc.com/connect"
+)
+
+func (s *GreetService) RequestMagicLink(ctx context.Context, req *connect.Request[v1.RequestMagicLinkRequest]) (*connect.Response[v1.RequestMagicLinkResponse], error) {
+	ticket, err := s.magicLinkUseCase.RequestMagicLink(ctx, req.Msg.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &v1.RequestMagicLinkResponse{
+		Nonce:     ticket.Nonce,
+		ExpiresAt: ticket.ExpiresAt.Unix(),
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+func (s *GreetService) ExchangeMagicLink(ctx context.Context, req *connect.Request[v1.ExchangeMagicLinkRequest]) (*connect.Response[v1.SubmitAuthResponse], error) {
+	result, err := s.magicLinkUseCase.ExchangeMagicLink(ctx, req.Msg.Token, req.Msg.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &v1.SubmitAuthResponse{
+		Code:      result.Code,
+		State:     result.State,
+		AuthToken: result.AuthToken,
+		TokenType: result.TokenType,
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+func (s *GreetService) RequestRegistrationEmail(ctx context.Context, req *connect.Request[v1.RequestRegistrationEmailRequest]) (*connect.Response[v1.RequestRegistrationEmailResponse], error) {
+	expiresAt, err := s.magicLinkUseCase.RequestRegistrationEmail(ctx, req.Msg.Email)
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&v1.RequestRegistrationEmailResponse{ExpiresAt: expiresAt.Unix()}), nil
+}
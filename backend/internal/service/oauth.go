@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "connect-go-example/api/oauth/v1"
+	"connect-go-example/api/oauth/v1/oauthv1connect"
+	"connect-go-example/internal/biz/model"
+
+	"connectrpc.com/connect"
+)
+
+var _ oauthv1connect.OAuthServiceHandler = (*OAuthService)(nil)
+
+// OAuthService 供内部资源服务调用，调用方通过 HTTP Basic 认证
+type OAuthService struct {
+	sessionUseCase model.SessionUseCase
+}
+
+func NewOAuthService(sessionUseCase model.SessionUseCase) oauthv1connect.OAuthServiceHandler {
+	return &OAuthService{
+		sessionUseCase: sessionUseCase,
+	}
+}
+
+func (s *OAuthService) Introspect(ctx context.Context, req *connect.Request[v1.IntrospectRequest]) (*connect.Response[v1.IntrospectResponse], error) {
+	result, err := s.sessionUseCase.Introspect(ctx, clientCredentials(req.Header()), req.Msg.Token)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Active {
+		return connect.NewResponse(&v1.IntrospectResponse{Active: false}), nil
+	}
+
+	p := result.Principal
+	response := &v1.IntrospectResponse{
+		Active:    true,
+		Scope:     strings.Join(p.Scopes, " "),
+		Username:  p.Username,
+		TokenType: model.TokenType(p.ProofKey),
+		Exp:       p.ExpiresAt.Unix(),
+		Iat:       p.IssuedAt.Unix(),
+		Sub:       strconv.FormatInt(p.UserID, 10),
+		Iss:       result.Issuer,
+		Jti:       p.TokenID,
+		Sid:       p.SessionID,
+		Tid:       p.TenantID,
+		Aud:       p.Audience,
+		Act:       toActorProto(p.Actor),
+	}
+	if p.ProofKey != "" {
+		response.Cnf = &v1.Confirmation{Jkt: p.ProofKey}
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+func (s *OAuthService) ExchangeToken(ctx context.Context, req *connect.Request[v1.ExchangeTokenRequest]) (*connect.Response[v1.ExchangeTokenResponse], error) {
+	result, err := s.sessionUseCase.ExchangeToken(ctx, clientCredentials(req.Header()), &model.TokenExchangeRequest{
+		SubjectToken:       req.Msg.SubjectToken,
+		SubjectTokenType:   req.Msg.SubjectTokenType,
+		Audience:           req.Msg.Audience,
+		Scopes:             strings.Fields(req.Msg.Scope),
+		RequestedTokenType: req.Msg.RequestedTokenType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &v1.ExchangeTokenResponse{
+		AccessToken:     result.AccessToken,
+		IssuedTokenType: model.TokenTypeAccessToken,
+		TokenType:       model.TokenTypeBearer,
+		ExpiresIn:       int64(time.Until(result.ExpiresAt).Seconds()),
+		Scope:           strings.Join(result.Scopes, " "),
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+func toActorProto(actor *model.Actor) *v1.Actor {
+	if actor == nil {
+		return nil
+	}
+	return &v1.Actor{
+		Sub: actor.Subject,
+		Act: toActorProto(actor.Actor),
+	}
+}
+
+// clientCredentials 从 Authorization: Basic 头中读取客户端凭据
+func clientCredentials(header http.Header) model.ClientCredentials {
+	id, secret, _ := (&http.Request{Header: header}).BasicAuth()
+	return model.ClientCredentials{ID: id, Secret: secret}
+}
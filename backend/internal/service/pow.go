@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+
+	v1 "connect-go-example/api/greet/v1"
+	"connect-go-example/internal/biz/model"
+
+	"connectrpc.com/connect"
+)
+
+func (s *GreetService) GetPowChallenge(ctx context.Context, req *connect.Request[v1.GetPowChallengeRequest]) (*connect.Response[v1.GetPowChallengeResponse], error) {
+	var action string
+	switch req.Msg.Action {
+	case v1.PowAction_POW_ACTION_REGISTER:
+		action = model.PowActionRegister
+	case v1.PowAction_POW_ACTION_AUTH_CHALLENGE:
+		action = model.PowActionAuthChallenge
+	}
+
+	challenge, err := s.proofOfWorkUseCase.GetPowChallenge(ctx, action)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &v1.GetPowChallengeResponse{
+		Required: challenge.Required,
+	}
+	if challenge.Required {
+		response.Challenge = challenge.Challenge
+		response.Difficulty = challenge.Difficulty
+		response.ExpiresAt = challenge.ExpiresAt.Unix()
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+func powSolution(pow *v1.ProofOfWork) *model.PowSolution {
+	if pow == nil {
+		return nil
+	}
+	return &model.PowSolution{
+		Challenge: pow.Challenge,
+		Nonce:     pow.Nonce,
+	}
+}
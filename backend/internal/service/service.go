@@ -7,4 +7,6 @@ import (
 var Module = fx.Module("service",
 	fx.Provide(NewGreetService),
 	fx.Provide(NewCheckService),
+	fx.Provide(NewAdminService),
+	fx.Provide(NewOAuthService),
 )
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 
 	v1 "connect-go-example/api/greet/v1"
 	"connect-go-example/api/greet/v1/greetv1connect"
@@ -12,26 +13,56 @@ import (
 
 // GreetService 实现 Connect 服务
 type GreetService struct {
-	userUseCase model.UserUseCase
+	userUseCase             model.UserUseCase
+	authRequestUseCase      model.AuthRequestUseCase
+	crossDeviceLoginUseCase model.CrossDeviceLoginUseCase
+	magicLinkUseCase        model.MagicLinkUseCase
+	proofOfWorkUseCase      model.ProofOfWorkUseCase
+	loginRiskUseCase        model.LoginRiskUseCase
+	sessionUseCase          model.SessionUseCase
+	federationUseCase       model.FederationUseCase
 }
 
 // 显式接口检查
 var _ greetv1connect.GreetServiceHandler = (*GreetService)(nil)
 
-func NewGreetService(userUseCase model.UserUseCase) greetv1connect.GreetServiceHandler {
+func NewGreetService(userUseCase model.UserUseCase, authRequestUseCase model.AuthRequestUseCase, crossDeviceLoginUseCase model.CrossDeviceLoginUseCase, magicLinkUseCase model.MagicLinkUseCase, proofOfWorkUseCase model.ProofOfWorkUseCase, loginRiskUseCase model.LoginRiskUseCase, sessionUseCase model.SessionUseCase, federationUseCase model.FederationUseCase) greetv1connect.GreetServiceHandler {
 	return &GreetService{
-		userUseCase: userUseCase,
+		userUseCase:             userUseCase,
+		authRequestUseCase:      authRequestUseCase,
+		crossDeviceLoginUseCase: crossDeviceLoginUseCase,
+		magicLinkUseCase:        magicLinkUseCase,
+		proofOfWorkUseCase:      proofOfWorkUseCase,
+		loginRiskUseCase:        loginRiskUseCase,
+		sessionUseCase:          sessionUseCase,
+		federationUseCase:       federationUseCase,
 	}
 }
 
+func (s *GreetService) GetRegistrationParams(ctx context.Context, req *connect.Request[v1.GetRegistrationParamsRequest]) (*connect.Response[v1.GetRegistrationParamsResponse], error) {
+	ticket, err := s.userUseCase.GetRegistrationParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &v1.GetRegistrationParamsResponse{
+		Params: toKdfTicketProto(ticket),
+	}
+
+	return connect.NewResponse(response), nil
+}
+
 func (s *GreetService) Register(ctx context.Context, req *connect.Request[v1.RegisterRequest]) (*connect.Response[v1.RegisterResponse], error) {
-	userID, err := s.userUseCase.Register(
-		ctx,
-		req.Msg.Username,
-		req.Msg.PasswordHash,
-		req.Msg.Email,
-		req.Msg.Salt,
-	)
+	userID, err := s.userUseCase.Register(ctx, &model.RegisterRequest{
+		Username:     req.Msg.Username,
+		PasswordHash: req.Msg.PasswordHash,
+		Email:        req.Msg.Email,
+		Salt:         req.Msg.Salt,
+		InviteCode:   req.Msg.InviteCode,
+		KdfTicket:    req.Msg.KdfTicket,
+		EmailTicket:  req.Msg.EmailTicket,
+		Pow:          powSolution(req.Msg.Pow),
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -44,28 +75,49 @@ func (s *GreetService) Register(ctx context.Context, req *connect.Request[v1.Reg
 }
 
 func (s *GreetService) GetAuthChallenge(ctx context.Context, req *connect.Request[v1.AuthChallengeRequest]) (*connect.Response[v1.AuthChallengeResponse], error) {
-	challenge, err := s.userUseCase.GetAuthChallenge(ctx, req.Msg.Username)
+	challenge, err := s.userUseCase.GetAuthChallenge(ctx, req.Msg.Username, powSolution(req.Msg.Pow))
 	if err != nil {
+		// 工作量证明的错误已带有错误码，其余错误统一返回未认证
+		var connectErr *connect.Error
+		if errors.As(err, &connectErr) {
+			return nil, err
+		}
 		return nil, connect.NewError(connect.CodeUnauthenticated, err)
 	}
 
 	response := &v1.AuthChallengeResponse{
-		Challenge: challenge.Challenge,
-		Salt:      challenge.Salt,
+		Challenge:      challenge.Challenge,
+		CredentialType: v1.CredentialType_CREDENTIAL_TYPE_PASSWORD,
+	}
+	// 目录后端校验密码原文，不下发 KDF 参数
+	if challenge.CredentialType != model.CredentialTypePassword {
+		response.CredentialType = v1.CredentialType_CREDENTIAL_TYPE_DERIVED
+		response.Salt = challenge.Salt
+		response.Kdf = toKdfParamsProto(challenge.Kdf)
+		response.Upgrade = toKdfTicketProto(challenge.Upgrade)
 	}
 
 	return connect.NewResponse(response), nil
 }
 
 func (s *GreetService) SubmitAuth(ctx context.Context, req *connect.Request[v1.SubmitAuthRequest]) (*connect.Response[v1.SubmitAuthResponse], error) {
-	result, err := s.userUseCase.SubmitAuth(
-		ctx,
-		req.Msg.Username,
-		req.Msg.HashedCredential,
-		req.Msg.AuthRequestId,
-		req.Msg.ChallengeResponse,
-	)
+	result, err := s.userUseCase.SubmitAuth(ctx, &model.SubmitAuthRequest{
+		Username:          req.Msg.Username,
+		HashedCredential:  req.Msg.HashedCredential,
+		Password:          req.Msg.Password,
+		AuthRequestID:     req.Msg.AuthRequestId,
+		ChallengeResponse: req.Msg.ChallengeResponse,
+		UpgradeCredential: req.Msg.UpgradeCredential,
+		UpgradeTicket:     req.Msg.UpgradeTicket,
+		DeviceID:          req.Msg.DeviceId,
+		Client:            clientInfo(req.Peer(), req.Header()),
+	})
 	if err != nil {
+		// 二次验证的错误已带有错误码，其余错误统一返回未认证
+		var connectErr *connect.Error
+		if errors.As(err, &connectErr) {
+			return nil, err
+		}
 		return nil, connect.NewError(connect.CodeUnauthenticated, err)
 	}
 
@@ -73,7 +125,64 @@ func (s *GreetService) SubmitAuth(ctx context.Context, req *connect.Request[v1.S
 		Code:      result.Code,
 		State:     result.State,
 		AuthToken: result.AuthToken,
+		TokenType: result.TokenType,
+		StepUpId:  result.StepUpID,
 	}
 
 	return connect.NewResponse(response), nil
 }
+
+func (s *GreetService) Logout(ctx context.Context, req *connect.Request[v1.LogoutRequest]) (*connect.Response[v1.LogoutResponse], error) {
+	principal, ok := model.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("authentication required"))
+	}
+
+	if err := s.sessionUseCase.RevokeSession(ctx, principal); err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&v1.LogoutResponse{}), nil
+}
+
+func (s *GreetService) RefreshToken(ctx context.Context, req *connect.Request[v1.RefreshTokenRequest]) (*connect.Response[v1.RefreshTokenResponse], error) {
+	principal, ok := model.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("authentication required"))
+	}
+
+	token, err := s.sessionUseCase.RefreshToken(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &v1.RefreshTokenResponse{
+		AuthToken: token.AccessToken,
+		TokenType: token.TokenType,
+		ExpiresAt: token.ExpiresAt.Unix(),
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+func toKdfParamsProto(params model.KdfParams) *v1.KdfParams {
+	return &v1.KdfParams{
+		Algorithm:   params.Algorithm,
+		Version:     params.Version,
+		MemoryKib:   params.MemoryKiB,
+		Iterations:  params.Iterations,
+		Parallelism: params.Parallelism,
+		Salt:        params.Salt,
+	}
+}
+
+func toKdfTicketProto(ticket *model.KdfTicket) *v1.KdfTicket {
+	if ticket == nil {
+		return nil
+	}
+	return &v1.KdfTicket{
+		Kdf:       toKdfParamsProto(ticket.Params),
+		Ticket:    ticket.Ticket,
+		ExpiresAt: ticket.ExpiresAt.Unix(),
+	}
+}